@@ -18,11 +18,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"time"
 
+	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/apiserver-runtime/pkg/builder"
 
@@ -31,7 +37,11 @@ import (
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/application"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/event"
 	grpc_server "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/server"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/ingress"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/leaderelection"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/node"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/nodemonitor"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/pod"
@@ -39,6 +49,7 @@ import (
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/session"
 	"centaurusinfra.io/fornax-serverless/pkg/store"
 	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
 )
 
 var (
@@ -49,44 +60,284 @@ func init() {
 	fornaxv1.AddToScheme(scheme)
 }
 
+// leader election options are parsed before api server parse command line, as fornax stores are shared by replicas when leader election is enabled
+type leaderElectionOptions struct {
+	leaderElect      bool
+	lockFile         string
+	advertiseAddress string
+	peers            []string
+}
+
+func (o *leaderElectionOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.BoolVar(&o.leaderElect, "leader-elect", false, "Start a leader election among fornaxcore replicas, only primary fornaxcore run managers and handle node messages. Application status, sessions, ingress endpoints, secrets and nodes are shared by replicas in api server storage, or in store dir when lock file is used.")
+	fs.StringVar(&o.lockFile, "leader-elect-lock-file", "", "Use a local file as leader election lock instead of a lease in api server storage, it's meant for tests.")
+	fs.StringVar(&o.advertiseAddress, "fornaxcore-advertise-address", "", "Grpc endpoint which node agents use to connect this fornaxcore, it's also identity of this fornaxcore in leader election, required when leader election is enabled.")
+	fs.StringSliceVar(&o.peers, "fornaxcore-peers", []string{}, "Grpc endpoints of other fornaxcore replicas, they are advertised to node agents as standbys.")
+	return fs
+}
+
+// newLeaderLock create a file lock if lock file is provided, otherwise a lease lock in api server storage
+func (o *leaderElectionOptions) newLeaderLock(storageConfig *storagebackend.ConfigForResource, identity string) (resourcelock.Interface, error) {
+	if len(o.lockFile) > 0 {
+		return leaderelection.NewFileLeaseLock(o.lockFile, identity), nil
+	}
+	if storageConfig == nil {
+		return nil, errors.New("api server storage config is not available for leader election lease")
+	}
+	leaseStorage, _, err := leaderelection.NewLeaseStorage(storageConfig)
+	if err != nil {
+		return nil, err
+	}
+	return leaderelection.NewStoreLeaseLock(leaseStorage, leaderelection.DefaultLeaseName, identity), nil
+}
+
+// validate check this fornaxcore has a advertise address and fornax stores can be shared by replicas,
+// replicas using a lock file are on same host and share sqlite stores in store dir, replicas using a lease share stores in etcd of api server storage
+func (o *leaderElectionOptions) validate(stOptions *storeOptions) error {
+	if !o.leaderElect {
+		return nil
+	}
+	// nodes connect primary using its identity, a guessed address may not be reachable by nodes
+	if len(o.advertiseAddress) == 0 {
+		return errors.New("--fornaxcore-advertise-address is required when --leader-elect is set")
+	}
+	if len(o.lockFile) > 0 && len(stOptions.storeDir) == 0 {
+		return errors.New("--fornaxcore-store-dir shared by replicas is required when --leader-elect-lock-file is used")
+	}
+	if len(o.lockFile) == 0 && len(stOptions.storeDir) > 0 {
+		return errors.New("--fornaxcore-store-dir can not be used with leader election lease, stores are shared in api server storage")
+	}
+	return nil
+}
+
+// newSharedBackendStoreFunc return a func which create stores shared by replicas, sqlite stores in store dir if lock file is used, otherwise stores in api server storage
+func (o *leaderElectionOptions) newSharedBackendStoreFunc(storageConfig *storagebackend.ConfigForResource, storeDir string) (func(schema.GroupResource) (storage.Store, error), error) {
+	if len(o.lockFile) > 0 {
+		return factory.NewPersistentBackendStoreFunc(storeDir), nil
+	}
+	if storageConfig == nil {
+		return nil, errors.New("api server storage config is not available for shared stores")
+	}
+	return factory.NewEtcdBackendStoreFunc(storageConfig)
+}
+
+// store options are parsed before api server parse command line, as fornax stores are initialized before api server start
+type storeOptions struct {
+	storeDir string
+}
+
+func (o *storeOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.StringVar(&o.storeDir, "fornaxcore-store-dir", "", "Directory to persist application status and sessions, fornaxcore restore them from it when restart. If empty, they are only kept in memory. When leader election use a lock file, replicas share this directory.")
+	return fs
+}
+
+// node cidr options are parsed before api server parse command line, as node manager is created before api server start
+type nodeCidrOptions struct {
+	clusterCidrs         []string
+	nodeCidrMaskSizeIPv4 int
+	nodeCidrMaskSizeIPv6 int
+}
+
+func (o *nodeCidrOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.StringSliceVar(&o.clusterCidrs, "cluster-cidr", []string{node.DefaultClusterCidr}, "CIDR ranges carved into node pod cidrs, at most one ipv4 and one ipv6 cidr, comma separated.")
+	fs.IntVar(&o.nodeCidrMaskSizeIPv4, "node-cidr-mask-size-ipv4", node.DefaultNodeCidrMaskSizeIPv4, "Mask size of node pod cidr carved from ipv4 cluster cidr.")
+	fs.IntVar(&o.nodeCidrMaskSizeIPv6, "node-cidr-mask-size-ipv6", node.DefaultNodeCidrMaskSizeIPv6, "Mask size of node pod cidr carved from ipv6 cluster cidr.")
+	return fs
+}
+
+func (o *nodeCidrOptions) nodeCidrConfig() *node.NodeCidrConfig {
+	return &node.NodeCidrConfig{
+		ClusterCidrs:         o.clusterCidrs,
+		NodeCidrMaskSizeIPv4: o.nodeCidrMaskSizeIPv4,
+		NodeCidrMaskSizeIPv6: o.nodeCidrMaskSizeIPv6,
+	}
+}
+
+// node daemon options are parsed before api server parse command line, as node manager is created before api server start
+type nodeDaemonOptions struct {
+	configFile string
+}
+
+func (o *nodeDaemonOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.StringVar(&o.configFile, "node-daemon-config", "", "Yaml file of DaemonSets run on every node matching DaemonSet pod template node selector, it is reloaded and rolled out to nodes when it changes.")
+	return fs
+}
+
+// node tls options are parsed before api server parse command line, as fornaxcore grpc server is started before api server start
+type nodeTLSOptions struct {
+	certFile           string
+	keyFile            string
+	clientCAFile       string
+	clientCAKeyFile    string
+	bootstrapTokenFile string
+	certValidity       time.Duration
+}
+
+func (o *nodeTLSOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.StringVar(&o.certFile, "node-grpc-tls-cert-file", "", "Certificate served to node agents on fornaxcore grpc port, node channel is insecure if it's not provided.")
+	fs.StringVar(&o.keyFile, "node-grpc-tls-private-key-file", "", "Private key of node-grpc-tls-cert-file.")
+	fs.StringVar(&o.clientCAFile, "node-client-ca-file", "", "Certificate authority which signs node client certificates, node identifier must match certificate common name.")
+	fs.StringVar(&o.clientCAKeyFile, "node-client-ca-key-file", "", "Private key of node-client-ca-file, it's used to issue certificates to enrolling nodes, node enrollment is disabled if it's not provided.")
+	fs.StringVar(&o.bootstrapTokenFile, "node-bootstrap-token-file", "", "File of bootstrap tokens which nodes present to enroll, one token per line in format of token[,node[,expiration]], a token with a node name can only enroll that node, a token without a node name must have an RFC3339 expiration and can only enroll new nodes.")
+	fs.DurationVar(&o.certValidity, "node-certificate-validity", grpc_server.DefaultNodeCertificateValidity, "Validity of node client certificates issued by node enrollment.")
+	return fs
+}
+
+func (o *nodeTLSOptions) nodeTLSConfig() *grpc_server.NodeTLSConfig {
+	if len(o.certFile) == 0 {
+		return nil
+	}
+	return &grpc_server.NodeTLSConfig{
+		CertFile:           o.certFile,
+		KeyFile:            o.keyFile,
+		ClientCAFile:       o.clientCAFile,
+		ClientCAKeyFile:    o.clientCAKeyFile,
+		BootstrapTokenFile: o.bootstrapTokenFile,
+		CertValidity:       o.certValidity,
+	}
+}
+
+// ingress options are parsed before api server parse command line, as application manager is created before api server start
+type ingressOptions struct {
+	gatewayAddress string
+	portRange      string
+}
+
+func (o *ingressOptions) addFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	fs.StringVar(&o.gatewayAddress, "ingress-gateway-address", "", "Address of ingress gateway, sessions are accessed through ingress gateway endpoints instead of node host ports if it's provided.")
+	fs.StringVar(&o.portRange, "ingress-port-range", fmt.Sprintf("%d-%d", ingress.DefaultIngressPortRangeStart, ingress.DefaultIngressPortRangeEnd), "Ingress gateway port range which session ingress ports are allocated from, in format of start-end.")
+	return fs
+}
+
+func (o *ingressOptions) ingressConfig() (*ingress.IngressConfig, error) {
+	if len(o.gatewayAddress) == 0 {
+		return nil, nil
+	}
+	var start, end int
+	if _, err := fmt.Sscanf(o.portRange, "%d-%d", &start, &end); err != nil || start <= 0 || end > 65535 || start > end {
+		return nil, fmt.Errorf("invalid ingress port range %s", o.portRange)
+	}
+	return &ingress.IngressConfig{
+		GatewayAddress: o.gatewayAddress,
+		PortRangeStart: start,
+		PortRangeEnd:   end,
+	}, nil
+}
+
+// preParseFlags parse flags needed before api server start, other flags are ignored and parsed by api server
+func preParseFlags(args []string, addFlagsFns ...func(fs *pflag.FlagSet) *pflag.FlagSet) {
+	fs := pflag.NewFlagSet("fornaxcore", pflag.ContinueOnError)
+	fs.ParseErrorsWhitelist.UnknownFlags = true
+	fs.Usage = func() {}
+	for _, addFlags := range addFlagsFns {
+		addFlags(fs)
+	}
+	fs.Parse(args)
+}
+
 func main() {
 	// initialize fornax resource memory store
 	ctx := context.Background()
+	stOptions := &storeOptions{}
+	cidrOptions := &nodeCidrOptions{}
+	daemonOptions := &nodeDaemonOptions{}
+	tlsOptions := &nodeTLSOptions{}
+	igOptions := &ingressOptions{}
+	leOptions := &leaderElectionOptions{}
+	preParseFlags(os.Args[1:], leOptions.addFlags, stOptions.addFlags, cidrOptions.addFlags, daemonOptions.addFlags, tlsOptions.addFlags, igOptions.addFlags)
+	if err := leOptions.validate(stOptions); err != nil {
+		klog.Fatal(err)
+	}
+	// stores shared by replicas are synced when this fornaxcore become primary, as primary may be changing them
+	if !leOptions.leaderElect && len(stOptions.storeDir) > 0 {
+		if err := factory.InitFornaxPersistentStorage(ctx, stOptions.storeDir); err != nil {
+			klog.Fatal(err)
+		}
+	}
 	nodeStore := factory.NewFornaxNodeStorage(ctx)
 	podStore := factory.NewFornaxPodStorage(ctx)
 	appStatusStore := factory.NewFornaxApplicationStatusStorage(ctx)
 	appSessionStore := factory.NewFornaxApplicationSessionStorage(ctx)
+	secretStore := factory.NewFornaxSecretStorage(ctx)
+	ingressStore := factory.NewFornaxIngressEndpointStorage(ctx)
+	eventStore := factory.NewFornaxEventStorage(ctx)
+
+	// fornaxcore metrics are served on api server /metrics endpoint
+	metrics.Register()
+
+	// event manager save events recorded by managers and reported by nodes, they are served as Events by api server
+	eventManager := event.NewEventManager(ctx, eventStore)
+	eventManager.Run()
 
 	// new fornaxcore grpc grpcServer which implement node agent proxy
 	grpcServer := grpc_server.NewGrpcServer()
 
-	// start internal managers and pod scheduler
+	// new internal managers and pod scheduler, they are started when this fornaxcore is primary
 	podManager := pod.NewPodManager(ctx, podStore, grpcServer)
 	sessionManager := session.NewSessionManager(ctx, grpcServer, appSessionStore)
-	nodeManager := node.NewNodeManager(ctx, nodeStore, grpcServer, podManager, sessionManager)
+	nodePodCidrManager, err := node.NewPodCidrManager(cidrOptions.nodeCidrConfig())
+	if err != nil {
+		klog.Fatal(err)
+	}
+	nodeDaemonManager, err := node.NewNodeDaemonManager(daemonOptions.configFile)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	nodeManager := node.NewNodeManager(ctx, nodeStore, grpcServer, podManager, sessionManager, nodePodCidrManager, nodeDaemonManager, eventManager.NewRecorder("fornax-node-manager"))
 	podScheduler := podscheduler.NewPodScheduler(ctx, grpcServer, nodeManager, podManager,
 		&podscheduler.SchedulePolicy{
 			NumOfEvaluatedNodes: 100,
 			BackoffDuration:     10 * time.Second,
 			NodeSortingMethod:   podscheduler.NodeSortingMethodMoreMemory,
-		})
-	podScheduler.Run()
-	podManager.Run(podScheduler)
-	nodeManager.Run()
+		}, eventManager.NewRecorder("fornax-scheduler"))
+	appManager := application.NewApplicationManager(ctx, podManager, sessionManager, appStatusStore, secretStore, eventManager.NewRecorder("fornax-application-manager"))
+	grpcServer.SetPodConfigProvider(appManager)
+	podScheduler.SetPodSessionProvider(appManager)
+	nodeManager.SetPodSessionReconciler(appManager)
+	grpcServer.SetNodeStore(nodeStore)
+	ingressConfig, err := igOptions.ingressConfig()
+	if err != nil {
+		klog.Fatal(err)
+	}
+	runIngressManager := func() error { return nil }
+	if ingressConfig != nil {
+		ingressManager := ingress.NewIngressManager(ctx, ingressConfig, ingressStore, appSessionStore, sessionManager, podManager)
+		appManager.SetIngressManager(ingressManager)
+		runIngressManager = ingressManager.Run
+	}
+	var leaseStorageConfig *storagebackend.ConfigForResource
+	startPrimary := func(ctx context.Context) {
+		if leOptions.leaderElect {
+			// load state saved by previous primary before managers start
+			newBackend, err := leOptions.newSharedBackendStoreFunc(leaseStorageConfig, stOptions.storeDir)
+			if err != nil {
+				klog.Fatal(err)
+			}
+			if err := factory.SyncFornaxSharedStorage(ctx, nil, newBackend); err != nil {
+				klog.Fatal(err)
+			}
+		}
+		podScheduler.Run()
+		podManager.Run(podScheduler)
+		nodeManager.Run()
+		if err := runIngressManager(); err != nil {
+			klog.Fatal(err)
+		}
 
-	// start application manager at last as it require api server
-	klog.Info("starting application manager")
-	appManager := application.NewApplicationManager(ctx, podManager, sessionManager, appStatusStore)
-	appManager.Run(ctx)
+		// start application manager at last as it require api server
+		klog.Info("starting application manager")
+		appManager.Run(ctx)
+
+		// handle node messages and ask connected nodes to full sync
+		grpcServer.SetStandby(false)
+	}
 
 	// start fornaxcore grpc server to listen nodes
 	klog.Info("starting fornaxcore grpc node agent server")
 	port := 18001
-	// we are using k8s api server, command line flags are only parsed when apiserver started
-	// TODO, parse flags before start api server and get certificates from command line flags,
-	certFile := ""
-	keyFile := ""
-	err := grpcServer.RunGrpcServer(ctx, nodemonitor.NewNodeMonitor(nodeManager), port, certFile, keyFile)
+	// grpc server keep node connections but drop node messages until this fornaxcore become primary
+	grpcServer.SetStandby(true)
+	err = grpcServer.RunGrpcServer(ctx, nodemonitor.NewNodeMonitor(nodeManager, eventManager), port, tlsOptions.nodeTLSConfig())
 	if err != nil {
 		klog.Fatal(err)
 	}
@@ -94,13 +345,44 @@ func main() {
 
 	// TODO, wait for all known nodes are registered
 
+	// start managers when api server started, if leader election is enabled, wait for this fornaxcore become primary
+	startFornaxCore := func(hookContext server.PostStartHookContext) error {
+		if !leOptions.leaderElect {
+			startPrimary(ctx)
+			return nil
+		}
+
+		lock, err := leOptions.newLeaderLock(leaseStorageConfig, leOptions.advertiseAddress)
+		if err != nil {
+			return err
+		}
+		elector, err := leaderelection.NewFornaxCoreElector(lock, leOptions.peers, leaderelection.LeaderCallbacks{
+			OnStartedLeading: startPrimary,
+			OnStoppedLeading: func() {
+				// managers can not hand off their state, exit and restart as a standby
+				klog.Fatal("Fornaxcore lost primary ownership, exit")
+			},
+			OnNewLeader: grpcServer.SetFornaxCoreConfiguration,
+		})
+		if err != nil {
+			return err
+		}
+		go elector.Run(ctx)
+		return nil
+	}
+
 	// start api server to listen to clients
 	klog.Info("starting fornaxcore rest api server")
 	// +kubebuilder:scaffold:resource-register
 	apiserver := builder.APIServer.
 		WithLocalDebugExtension().
+		WithFlagFns(leOptions.addFlags, stOptions.addFlags, cidrOptions.addFlags, daemonOptions.addFlags, tlsOptions.addFlags, igOptions.addFlags).
+		WithPostStartHook("start-fornaxcore", startFornaxCore).
 		WithConfigFns(func(config *server.RecommendedConfig) *server.RecommendedConfig {
 			optionsGetter := config.RESTOptionsGetter
+			if options, err := optionsGetter.GetRESTOptions(fornaxv1.ApplicationGrv.GroupResource()); err == nil {
+				leaseStorageConfig = options.StorageConfig
+			}
 			config.RESTOptionsGetter = &factory.FornaxRestOptionsFactory{
 				OptionsGetter: optionsGetter,
 			}
@@ -110,12 +392,17 @@ func main() {
 			return options
 		}).
 		WithServerFns(func(server *builder.GenericAPIServer) *builder.GenericAPIServer {
+			// fornaxcore events are served under k8s.io group, also list them under core v1 path for kubectl describe
+			server.Handler.NonGoRestfulMux.HandlePrefix(event.CoreEventsPathPrefix, event.NewCoreEventsHandler(eventStore))
 			return server
 		}).
 		WithResource(&fornaxv1.Application{}).
 		WithResource(&fornaxv1.ApplicationSession{}).
-		WithResourceAndHandler(&fornaxk8sv1.FornaxPod{}, store.FornaxReadonlyResourceHandler(&fornaxk8sv1.FornaxPod{})).
-		WithResourceAndHandler(&fornaxk8sv1.FornaxNode{}, store.FornaxReadonlyResourceHandler(&fornaxk8sv1.FornaxNode{}))
+		WithResource(&fornaxv1.IngressEndpoint{}).
+		WithResourceAndHandler(&fornaxk8sv1.FornaxPod{}, store.FornaxAnnotatableResourceHandler(&fornaxk8sv1.FornaxPod{})).
+		WithResourceAndHandler(&fornaxk8sv1.FornaxNode{}, store.FornaxAnnotatableResourceHandler(&fornaxk8sv1.FornaxNode{})).
+		WithResourceAndHandler(&fornaxk8sv1.FornaxSecret{}, store.FornaxResourceHandler(&fornaxk8sv1.FornaxSecret{})).
+		WithResourceAndHandler(&fornaxk8sv1.FornaxEvent{}, store.FornaxResourceHandler(&fornaxk8sv1.FornaxEvent{}))
 	err = apiserver.Execute()
 	if err != nil {
 		klog.Fatal(err)
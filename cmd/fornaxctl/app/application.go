@@ -0,0 +1,297 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func newApplicationCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "app",
+		Aliases: []string{"application"},
+		Short:   "Manage applications",
+	}
+
+	var file string
+	createCmd := &cobra.Command{
+		Use:   "create -f FILE",
+		Short: "Create a application from a yaml or json file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.applyApplication(cmd.Context(), file, false)
+		},
+	}
+	createCmd.Flags().StringVarP(&file, "filename", "f", "", "Application yaml or json file, - for stdin")
+	createCmd.MarkFlagRequired("filename")
+
+	applyCmd := &cobra.Command{
+		Use:   "apply -f FILE",
+		Short: "Create a application or update spec of existing application from a yaml or json file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.applyApplication(cmd.Context(), file, true)
+		},
+	}
+	applyCmd.Flags().StringVarP(&file, "filename", "f", "", "Application yaml or json file, - for stdin")
+	applyCmd.MarkFlagRequired("filename")
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete NAME",
+		Short: "Delete a application, its instances are terminated",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.deleteApplication(cmd.Context(), args[0])
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List applications with instance summary",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.listApplications(cmd.Context())
+		},
+	}
+
+	describeCmd := &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show application spec, scaling policy and status",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.describeApplication(cmd.Context(), args[0])
+		},
+	}
+
+	cmd.AddCommand(createCmd, applyCmd, deleteCmd, listCmd, describeCmd)
+	return cmd
+}
+
+// readApplication decode a application from yaml or json file, namespace in file must be same as namespace flag if it's set
+func (o *options) readApplication(file string) (*fornaxv1.Application, error) {
+	var reader io.Reader
+	if file == "-" {
+		reader = os.Stdin
+	} else {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	application := &fornaxv1.Application{}
+	if err := yaml.NewYAMLOrJSONDecoder(reader, 4096).Decode(application); err != nil {
+		return nil, fmt.Errorf("failed to decode application from %s, %v", file, err)
+	}
+	if len(application.Name) == 0 {
+		return nil, errors.New("application name is required")
+	}
+	if len(application.Namespace) == 0 {
+		application.Namespace = o.namespace
+	} else if application.Namespace != o.namespace {
+		return nil, fmt.Errorf("application namespace %s does not match namespace %s", application.Namespace, o.namespace)
+	}
+	return application, nil
+}
+
+func (o *options) applyApplication(ctx context.Context, file string, update bool) error {
+	application, err := o.readApplication(file)
+	if err != nil {
+		return err
+	}
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	appClient := client.CoreV1().Applications(o.namespace)
+
+	existing, err := appClient.Get(ctx, application.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := appClient.Create(ctx, application, metav1.CreateOptions{}); err != nil {
+			return err
+		}
+		fmt.Fprintf(o.out, "application/%s created\n", application.Name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !update {
+		return fmt.Errorf("application %s/%s already exists", o.namespace, application.Name)
+	}
+
+	// only spec, labels and annotations are applied, status is maintained by fornaxcore
+	existing.Spec = application.Spec
+	existing.Labels = application.Labels
+	existing.Annotations = application.Annotations
+	if _, err := appClient.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.out, "application/%s configured\n", application.Name)
+	return nil
+}
+
+func (o *options) deleteApplication(ctx context.Context, name string) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	if err := client.CoreV1().Applications(o.namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	fmt.Fprintf(o.out, "application/%s deleted\n", name)
+	return nil
+}
+
+func (o *options) listApplications(ctx context.Context) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	apps, err := client.CoreV1().Applications(o.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	w := o.tabWriter()
+	fmt.Fprintln(w, "NAME\tDESIRED\tTOTAL\tIDLE\tALLOCATED\tPENDING\tDELETING\tREVISION\tAGE")
+	for _, v := range apps.Items {
+		status := v.Status
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", v.Name, status.DesiredInstances, status.TotalInstances, status.IdleInstances,
+			status.AllocatedInstances, status.PendingInstances, status.DeletingInstances, status.CurrentRevision, age(v.CreationTimestamp))
+	}
+	return w.Flush()
+}
+
+func (o *options) describeApplication(ctx context.Context, name string) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	application, err := client.CoreV1().Applications(o.namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	sessions, err := client.CoreV1().ApplicationSessions(o.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	sessionCounts := map[fornaxv1.SessionStatus]int{}
+	for _, v := range sessions.Items {
+		if v.Spec.ApplicationName == name {
+			sessionCounts[v.Status.SessionStatus] += 1
+		}
+	}
+
+	w := o.tabWriter()
+	spec, status := application.Spec, application.Status
+	fmt.Fprintf(w, "Name:\t%s\n", application.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", application.Namespace)
+	fmt.Fprintf(w, "Age:\t%s\n", age(application.CreationTimestamp))
+	if application.DeletionTimestamp != nil {
+		fmt.Fprintf(w, "Deleting:\tsince %s\n", age(*application.DeletionTimestamp))
+	}
+	for _, c := range spec.Containers {
+		fmt.Fprintf(w, "Container:\t%s %s\n", c.Name, c.Image)
+	}
+	fmt.Fprintf(w, "Sessions Per Instance:\t%d\n", spec.SessionsPerInstance)
+	fmt.Fprintf(w, "Scaling:\t%s\n", scalingPolicySummary(&spec.ScalingPolicy))
+	fmt.Fprintf(w, "Instances:\t%s\n", instanceSummary(&status))
+	fmt.Fprintf(w, "Revision:\t%d %s\n", status.CurrentRevision, status.CurrentRevisionHash)
+	for _, r := range status.Revisions {
+		fmt.Fprintf(w, "  Revision %d:\t%s created %s ago\n", r.Revision, r.Hash, age(r.CreationTimestamp))
+	}
+	fmt.Fprintf(w, "Sessions:\t%s\n", sessionCountSummary(sessionCounts))
+	if len(status.LatestHistory.Action) > 0 {
+		h := status.LatestHistory
+		fmt.Fprintf(w, "Last Deployment:\t%s %s %s ago, %s\n", h.Action, h.DeploymentStatus, age(h.UpdateTime), h.Message)
+	}
+	return w.Flush()
+}
+
+// scalingPolicySummary describe scaling policy in one line, e.g. idle_session_number min 0 max 10 burst 1, idle sessions 1-3
+func scalingPolicySummary(policy *fornaxv1.ScalingPolicy) string {
+	policyType := policy.ScalingPolicyType
+	if len(policyType) == 0 {
+		policyType = fornaxv1.ScalingPolicyTypeIdleSessionNum
+	}
+	summary := fmt.Sprintf("%s min %d max %d burst %d", policyType, policy.MinimumInstance, policy.MaximumInstance, policy.Burst)
+	if t := policy.IdleSessionNumThreshold; t != nil {
+		summary += fmt.Sprintf(", idle sessions %d-%d", t.LowWaterMark, t.HighWaterMark)
+	}
+	if t := policy.IdleSessionPercentThreshold; t != nil {
+		summary += fmt.Sprintf(", idle session percent %d-%d", t.LowWaterMark, t.HighWaterMark)
+	}
+	for _, s := range policy.Schedules {
+		summary += fmt.Sprintf(", min %d at \"%s\" for %ds", s.MinimumInstance, s.Schedule, s.DurationSeconds)
+	}
+	if r := policy.SessionRate; r != nil {
+		summary += fmt.Sprintf(", session rate window %ds cold start %ds %d%%", r.WindowSeconds, r.ColdStartSeconds, r.ColdStartProbabilityPercent)
+	}
+	return summary
+}
+
+// instanceSummary describe instances in one line, e.g. 3/4 (idle 2, allocated 1, pending 1, deleting 0), updated 4
+func instanceSummary(status *fornaxv1.ApplicationStatus) string {
+	return fmt.Sprintf("%d/%d (idle %d, allocated %d, pending %d, deleting %d), updated %d",
+		status.TotalInstances-status.PendingInstances, status.DesiredInstances, status.IdleInstances, status.AllocatedInstances,
+		status.PendingInstances, status.DeletingInstances, status.UpdatedInstances)
+}
+
+func sessionCountSummary(counts map[fornaxv1.SessionStatus]int) string {
+	if len(counts) == 0 {
+		return "<none>"
+	}
+	summary := []string{}
+	for _, s := range []fornaxv1.SessionStatus{
+		fornaxv1.SessionStatusUnspecified,
+		fornaxv1.SessionStatusPending,
+		fornaxv1.SessionStatusStarting,
+		fornaxv1.SessionStatusAvailable,
+		fornaxv1.SessionStatusInUse,
+		fornaxv1.SessionStatusClosing,
+		fornaxv1.SessionStatusEvacuating,
+		fornaxv1.SessionStatusEvacuated,
+		fornaxv1.SessionStatusClosed,
+		fornaxv1.SessionStatusTimeout,
+	} {
+		if n, found := counts[s]; found {
+			summary = append(summary, fmt.Sprintf("%d %s", n, sessionStatusString(s)))
+		}
+	}
+	return strings.Join(summary, ", ")
+}
+
+func sessionStatusString(status fornaxv1.SessionStatus) string {
+	if status == fornaxv1.SessionStatusUnspecified {
+		return string(fornaxv1.SessionStatusPending)
+	}
+	return string(status)
+}
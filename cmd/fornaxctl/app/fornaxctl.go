@@ -0,0 +1,130 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/client/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	FornaxCtl = "fornaxctl"
+
+	// fornaxcore api server write its kubeconfig in its working dir
+	DefaultKubeConfig = "kubeconfig"
+	DefaultNamespace  = "default"
+	PollInterval      = 500 * time.Millisecond
+)
+
+// options are shared by all commands, clients are created from kubeconfig when a command run,
+// tests set clients directly
+type options struct {
+	kubeConfig    string
+	namespace     string
+	client        versioned.Interface
+	dynamicClient dynamic.Interface
+	out           io.Writer
+	errOut        io.Writer
+}
+
+func (o *options) restConfig() (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeConfig
+	if len(rules.ExplicitPath) == 0 {
+		if _, err := os.Stat(DefaultKubeConfig); err == nil {
+			rules.ExplicitPath = DefaultKubeConfig
+		}
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{}).ClientConfig()
+}
+
+func (o *options) fornaxClient() (versioned.Interface, error) {
+	if o.client != nil {
+		return o.client, nil
+	}
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := versioned.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	o.client = client
+	return client, nil
+}
+
+// fornax nodes and pods are served as k8s.io/v1 resources, they are not in generated clientset
+func (o *options) fornaxDynamicClient() (dynamic.Interface, error) {
+	if o.dynamicClient != nil {
+		return o.dynamicClient, nil
+	}
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	o.dynamicClient = client
+	return client, nil
+}
+
+func (o *options) tabWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(o.out, 0, 8, 2, ' ', 0)
+}
+
+// age format how long ago a object was created, e.g. 5m, 2d3h
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+func NewCommand() *cobra.Command {
+	o := &options{out: os.Stdout, errOut: os.Stderr}
+	return newCommand(o)
+}
+
+func newCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:           FornaxCtl,
+		Short:         "fornaxctl manage fornax serverless applications, sessions and nodes",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	cmd.PersistentFlags().StringVar(&o.kubeConfig, "kubeconfig", o.kubeConfig, fmt.Sprintf("Path to fornaxcore kubeconfig, ./%s is used if it exists, otherwise KUBECONFIG or ~/.kube/config", DefaultKubeConfig))
+	cmd.PersistentFlags().StringVarP(&o.namespace, "namespace", "n", DefaultNamespace, "Namespace of applications and sessions")
+
+	cmd.AddCommand(newApplicationCommand(o))
+	cmd.AddCommand(newSessionCommand(o))
+	cmd.AddCommand(newNodeCommand(o))
+	return cmd
+}
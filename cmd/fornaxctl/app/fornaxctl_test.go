@@ -0,0 +1,121 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/test/harness"
+)
+
+const (
+	testTimeout     = 30 * time.Second
+	testApplication = `
+apiVersion: core.fornax-serverless.centaurusinfra.io/v1
+kind: Application
+metadata:
+  name: echo
+spec:
+  containers:
+  - name: echoserver
+    image: centaurusinfra.io/fornax-serverless/echoserver:v0.1.0
+    ports:
+    - name: echoserver
+      containerPort: 80
+    resources:
+      requests:
+        cpu: 10m
+        memory: 50Mi
+  usingNodeSessionService: true
+  scalingPolicy:
+    minimumInstance: 1
+    maximumInstance: 3
+    burst: 1
+    scalingPolicyType: idle_session_number
+    idleSessionNumThreshold:
+      highWaterMark: 1
+      lowWaterMark: 0
+`
+)
+
+func runCommand(t *testing.T, o *options, args ...string) string {
+	out := &bytes.Buffer{}
+	o.out, o.errOut = out, &bytes.Buffer{}
+	cmd := newCommand(o)
+	cmd.SetArgs(args)
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("fornaxctl %s failed, %v", strings.Join(args, " "), err)
+	}
+	return out.String()
+}
+
+func TestApplicationAndSessionCommands(t *testing.T) {
+	h := harness.NewHarness(harness.DefaultHarnessConfiguration())
+	if err := h.Start(testTimeout); err != nil {
+		h.Stop()
+		t.Fatalf("failed to start harness, %v", err)
+	}
+	defer h.Stop()
+	o := &options{client: h.Client()}
+
+	file := filepath.Join(t.TempDir(), "echo.yaml")
+	if err := os.WriteFile(file, []byte(testApplication), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out := runCommand(t, o, "app", "create", "-f", file); out != "application/echo created\n" {
+		t.Fatalf("unexpected app create output %q", out)
+	}
+	if out := runCommand(t, o, "app", "apply", "-f", file); out != "application/echo configured\n" {
+		t.Fatalf("unexpected app apply output %q", out)
+	}
+	if _, err := h.WaitForApplication(DefaultNamespace, "echo", testTimeout, func(app *fornaxv1.Application) bool {
+		return app.Status.IdleInstances == 1
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if out := runCommand(t, o, "app", "list"); !strings.Contains(out, "echo") {
+		t.Fatalf("expect echo in app list, got %q", out)
+	}
+	if out := runCommand(t, o, "app", "describe", "echo"); !strings.Contains(out, "idle_session_number min 1 max 3 burst 1, idle sessions 0-1") {
+		t.Fatalf("expect scaling policy summary in app describe, got %q", out)
+	}
+
+	runCommand(t, o, "session", "open", "echo", "--name", "echo-session", "--timeout", testTimeout.String())
+	out := runCommand(t, o, "session", "list", "echo")
+	if !strings.Contains(out, "echo-session") || !strings.Contains(out, string(fornaxv1.SessionStatusAvailable)) {
+		t.Fatalf("expect available echo-session in session list, got %q", out)
+	}
+	if out := runCommand(t, o, "session", "list", "other"); strings.Contains(out, "echo-session") {
+		t.Fatalf("expect session list filtered by application, got %q", out)
+	}
+	if out := runCommand(t, o, "app", "describe", "echo"); !strings.Contains(out, "1 Available") {
+		t.Fatalf("expect session count in app describe, got %q", out)
+	}
+	if out := runCommand(t, o, "session", "close", "echo-session", "--wait"); out != "session/echo-session closed\n" {
+		t.Fatalf("unexpected session close output %q", out)
+	}
+	if out := runCommand(t, o, "app", "delete", "echo"); out != "application/echo deleted\n" {
+		t.Fatalf("unexpected app delete output %q", out)
+	}
+}
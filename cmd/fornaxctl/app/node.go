@@ -0,0 +1,216 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	fornaxNodeResource = schema.GroupVersionResource{Group: "k8s.io", Version: "v1", Resource: "nodes"}
+	fornaxPodResource  = schema.GroupVersionResource{Group: "k8s.io", Version: "v1", Resource: "pods"}
+)
+
+func newNodeCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "node",
+		Short: "Inspect fornax nodes",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List nodes with status and resources",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.listNodes(cmd.Context())
+		},
+	}
+
+	describeCmd := &cobra.Command{
+		Use:   "describe NAME",
+		Short: "Show node status, its pods and sessions on pods",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.describeNode(cmd.Context(), args[0])
+		},
+	}
+
+	cmd.AddCommand(listCmd, describeCmd)
+	return cmd
+}
+
+func (o *options) getNodes(ctx context.Context) (*v1.NodeList, error) {
+	client, err := o.fornaxDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(fornaxNodeResource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes := &v1.NodeList{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.UnstructuredContent(), nodes); err != nil {
+		return nil, err
+	}
+	sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+	return nodes, nil
+}
+
+func (o *options) getPods(ctx context.Context) (*v1.PodList, error) {
+	client, err := o.fornaxDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(fornaxPodResource).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	pods := &v1.PodList{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(list.UnstructuredContent(), pods); err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return util.Name(&pods.Items[i]) < util.Name(&pods.Items[j]) })
+	return pods, nil
+}
+
+// nodePods return pods assigned to node, pod is labeled with node namespace/name by fornaxcore
+func nodePods(node *v1.Node, pods *v1.PodList) []*v1.Pod {
+	nodeName := util.Name(node)
+	nodePods := []*v1.Pod{}
+	for i := range pods.Items {
+		if label, found := pods.Items[i].GetLabels()[fornaxv1.LabelFornaxCoreNode]; found && label == nodeName {
+			nodePods = append(nodePods, &pods.Items[i])
+		}
+	}
+	return nodePods
+}
+
+func nodeStatus(node *v1.Node) string {
+	for _, c := range node.Status.Conditions {
+		if c.Type == v1.NodeReady {
+			if c.Status == v1.ConditionTrue {
+				return "Ready"
+			}
+			return "NotReady"
+		}
+	}
+	return "Unknown"
+}
+
+func (o *options) listNodes(ctx context.Context) error {
+	nodes, err := o.getNodes(ctx)
+	if err != nil {
+		return err
+	}
+	pods, err := o.getPods(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := o.tabWriter()
+	fmt.Fprintln(w, "NAME\tSTATUS\tCPU\tMEMORY\tPODS\tAGE")
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		allocatable := node.Status.Allocatable
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", node.Name, nodeStatus(node), allocatable.Cpu().String(), allocatable.Memory().String(),
+			len(nodePods(node, pods)), age(node.CreationTimestamp))
+	}
+	return w.Flush()
+}
+
+func (o *options) describeNode(ctx context.Context, name string) error {
+	nodes, err := o.getNodes(ctx)
+	if err != nil {
+		return err
+	}
+	var node *v1.Node
+	for i := range nodes.Items {
+		if nodes.Items[i].Name == name || util.Name(&nodes.Items[i]) == name {
+			node = &nodes.Items[i]
+			break
+		}
+	}
+	if node == nil {
+		return apierrors.NewNotFound(fornaxNodeResource.GroupResource(), name)
+	}
+	pods, err := o.getPods(ctx)
+	if err != nil {
+		return err
+	}
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	sessions, err := client.CoreV1().ApplicationSessions(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	podSessions := map[string][]*fornaxv1.ApplicationSession{}
+	for i := range sessions.Items {
+		if ref := sessions.Items[i].Status.PodReference; ref != nil {
+			podSessions[ref.Name] = append(podSessions[ref.Name], &sessions.Items[i])
+		}
+	}
+
+	w := o.tabWriter()
+	fmt.Fprintf(w, "Name:\t%s\n", node.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", node.Namespace)
+	fmt.Fprintf(w, "Status:\t%s\n", nodeStatus(node))
+	fmt.Fprintf(w, "Age:\t%s\n", age(node.CreationTimestamp))
+	for _, a := range node.Status.Addresses {
+		fmt.Fprintf(w, "Address:\t%s %s\n", a.Type, a.Address)
+	}
+	fmt.Fprintf(w, "Capacity:\tcpu %s, memory %s\n", node.Status.Capacity.Cpu().String(), node.Status.Capacity.Memory().String())
+	fmt.Fprintf(w, "Allocatable:\tcpu %s, memory %s\n", node.Status.Allocatable.Cpu().String(), node.Status.Allocatable.Memory().String())
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(o.out, "Pods:")
+	w = o.tabWriter()
+	fmt.Fprintln(w, "  NAME\tAPPLICATION\tPHASE\tIP\tSESSIONS\tAGE")
+	for _, pod := range nodePods(node, pods) {
+		podName := util.Name(pod)
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%d\t%s\n", podName, pod.GetLabels()[fornaxv1.LabelFornaxCoreApplication], pod.Status.Phase,
+			pod.Status.PodIP, len(podSessions[podName]), age(pod.CreationTimestamp))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(o.out, "Sessions:")
+	w = o.tabWriter()
+	fmt.Fprintln(w, "  NAME\tPOD\tSTATUS\tAGE")
+	for _, pod := range nodePods(node, pods) {
+		podName := util.Name(pod)
+		for _, session := range podSessions[podName] {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", util.Name(session), podName, sessionStatusString(session.Status.SessionStatus), age(session.CreationTimestamp))
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+type sessionOpenOptions struct {
+	name               string
+	data               string
+	openTimeoutSeconds uint16
+	idleTimeoutSeconds uint32
+	maxLifetimeSeconds uint32
+	timeout            time.Duration
+}
+
+func newSessionCommand(o *options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "session",
+		Short: "Manage application sessions",
+	}
+
+	openOptions := &sessionOpenOptions{}
+	openCmd := &cobra.Command{
+		Use:   "open APP",
+		Short: "Open a session of application, wait until it's available and print its access endpoints",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.openSession(cmd.Context(), args[0], openOptions)
+		},
+	}
+	openCmd.Flags().StringVar(&openOptions.name, "name", "", "Session name, generated from application name if not set")
+	openCmd.Flags().StringVar(&openOptions.data, "data", "{}", "Session data passed to application instance")
+	openCmd.Flags().Uint16Var(&openOptions.openTimeoutSeconds, "open-timeout", 0, "Seconds fornaxcore wait for session to be opened on a instance, 0 use fornaxcore default")
+	openCmd.Flags().Uint32Var(&openOptions.idleTimeoutSeconds, "idle-timeout", 0, "Seconds a session can stay idle before it's closed, 0 never timeout")
+	openCmd.Flags().Uint32Var(&openOptions.maxLifetimeSeconds, "max-lifetime", 0, "Seconds a session can live before it's closed, 0 no limit")
+	openCmd.Flags().DurationVar(&openOptions.timeout, "timeout", time.Minute, "How long to wait for session to be available")
+
+	var waitClosed bool
+	closeCmd := &cobra.Command{
+		Use:   "close NAME",
+		Short: "Close a session",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.closeSession(cmd.Context(), args[0], waitClosed)
+		},
+	}
+	closeCmd.Flags().BoolVar(&waitClosed, "wait", false, "Wait until session is closed and removed")
+
+	listCmd := &cobra.Command{
+		Use:   "list [APP]",
+		Short: "List sessions, only sessions of application if APP is set",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			appName := ""
+			if len(args) > 0 {
+				appName = args[0]
+			}
+			return o.listSessions(cmd.Context(), appName)
+		},
+	}
+
+	cmd.AddCommand(openCmd, closeCmd, listCmd)
+	return cmd
+}
+
+func (o *options) openSession(ctx context.Context, appName string, openOptions *sessionOpenOptions) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().Applications(o.namespace).Get(ctx, appName, metav1.GetOptions{}); err != nil {
+		return err
+	}
+
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.namespace,
+			Name:      openOptions.name,
+		},
+		Spec: fornaxv1.ApplicationSessionSpec{
+			ApplicationName:    appName,
+			SessionData:        openOptions.data,
+			OpenTimeoutSeconds: openOptions.openTimeoutSeconds,
+			IdleTimeoutSeconds: openOptions.idleTimeoutSeconds,
+			MaxLifetimeSeconds: openOptions.maxLifetimeSeconds,
+		},
+	}
+	if len(session.Name) == 0 {
+		session.GenerateName = appName + "-"
+	}
+	sessionClient := client.CoreV1().ApplicationSessions(o.namespace)
+	session, err = sessionClient.Create(ctx, session, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(o.errOut, "session/%s created, waiting for it to be available\n", session.Name)
+
+	name := session.Name
+	err = wait.PollImmediate(PollInterval, openOptions.timeout, func() (bool, error) {
+		session, err = sessionClient.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		switch session.Status.SessionStatus {
+		case fornaxv1.SessionStatusAvailable, fornaxv1.SessionStatusInUse:
+			return true, nil
+		case fornaxv1.SessionStatusClosed, fornaxv1.SessionStatusTimeout:
+			return false, fmt.Errorf("session %s is %s, %s", name, session.Status.SessionStatus, session.Status.CloseReason)
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("session %s is not available after %s, status %s", name, openOptions.timeout, sessionStatusString(session.Status.SessionStatus))
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, v := range session.Status.AccessEndPoints {
+		fmt.Fprintln(o.out, endpointString(v))
+	}
+	return nil
+}
+
+func (o *options) closeSession(ctx context.Context, name string, waitClosed bool) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	sessionClient := client.CoreV1().ApplicationSessions(o.namespace)
+	if err := sessionClient.Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	if waitClosed {
+		err := wait.PollImmediateUntil(PollInterval, func() (bool, error) {
+			_, err := sessionClient.Get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}, ctx.Done())
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(o.out, "session/%s closed\n", name)
+	return nil
+}
+
+func (o *options) listSessions(ctx context.Context, appName string) error {
+	client, err := o.fornaxClient()
+	if err != nil {
+		return err
+	}
+	sessions, err := client.CoreV1().ApplicationSessions(o.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	w := o.tabWriter()
+	fmt.Fprintln(w, "NAME\tAPPLICATION\tSTATUS\tPOD\tENDPOINTS\tAGE")
+	for _, v := range sessions.Items {
+		if len(appName) > 0 && v.Spec.ApplicationName != appName {
+			continue
+		}
+		pod := "<none>"
+		if v.Status.PodReference != nil {
+			pod = v.Status.PodReference.Name
+		}
+		endpoints := []string{}
+		for _, e := range v.Status.AccessEndPoints {
+			endpoints = append(endpoints, endpointString(e))
+		}
+		if len(endpoints) == 0 {
+			endpoints = append(endpoints, "<none>")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Name, v.Spec.ApplicationName, sessionStatusString(v.Status.SessionStatus), pod,
+			strings.Join(endpoints, ","), age(v.CreationTimestamp))
+	}
+	return w.Flush()
+}
+
+func endpointString(endpoint fornaxv1.AccessEndPoint) string {
+	return fmt.Sprintf("%s %s:%d", endpoint.Protocol, endpoint.IPAddress, endpoint.Port)
+}
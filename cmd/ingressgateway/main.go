@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"centaurusinfra.io/fornax-serverless/pkg/ingressgateway"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	"github.com/spf13/pflag"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	cliflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+	"k8s.io/klog/v2"
+)
+
+// ingress gateway watch IngressEndpoints created by fornaxcore for open sessions,
+// and forward ingress ports of this gateway address to session pods
+func main() {
+	var kubeconfigPath, gatewayAddress, bindAddress string
+	fs := pflag.NewFlagSet("ingressgateway", pflag.ExitOnError)
+	fs.SetNormalizeFunc(cliflag.WordSepNormalizeFunc)
+	fs.StringVar(&kubeconfigPath, "kubeconfig", "", "Kubeconfig of fornaxcore api server, default is kubeconfig in working dir.")
+	fs.StringVar(&gatewayAddress, "gateway-address", "", "Address of this ingress gateway, only IngressEndpoints with this ingress gateway address are programmed.")
+	fs.StringVar(&bindAddress, "bind-address", "0.0.0.0", "Address which ingress ports are listened on.")
+	logs.AddFlags(fs)
+	fs.Parse(os.Args[1:])
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	if len(gatewayAddress) == 0 {
+		fmt.Fprintln(os.Stderr, "gateway-address is required")
+		os.Exit(1)
+	}
+	var kubeconfig *rest.Config
+	if len(kubeconfigPath) > 0 {
+		var err error
+		if kubeconfig, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath); err != nil {
+			klog.ErrorS(err, "Failed to construct kube rest config", "kubeconfig", kubeconfigPath)
+			os.Exit(1)
+		}
+	} else {
+		kubeconfig = util.GetFornaxCoreKubeConfig()
+	}
+
+	ctx := genericapiserver.SetupSignalContext()
+	gateway := ingressgateway.NewIngressGateway(ctx, gatewayAddress, util.GetFornaxCoreApiClient(kubeconfig), ingressgateway.NewUserspaceProxier(bindAddress))
+	gateway.Run()
+	<-ctx.Done()
+	gateway.Stop()
+}
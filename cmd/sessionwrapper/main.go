@@ -32,16 +32,21 @@ import (
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/retry"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"k8s.io/klog/v2"
 )
 
 func main() {
 	endpoint := os.Getenv(fornaxv1.LabelFornaxCoreSessionService)
 	opensession_cmd := os.Getenv("SESSION_WRAPPER_OPEN_SESSION_CMD")
+	checkpoint_file := os.Getenv("SESSION_WRAPPER_CHECKPOINT_FILE")
+	token := os.Getenv(fornaxv1.LabelFornaxCoreSessionServiceToken)
 
 	config := &SessionConfig{
-		endpoint: fmt.Sprintf("%s:%d", endpoint, 1022),
-		openCmd:  opensession_cmd,
+		endpoint:       fmt.Sprintf("%s:%d", endpoint, 1022),
+		openCmd:        opensession_cmd,
+		checkpointFile: checkpoint_file,
+		token:          token,
 	}
 
 	instanceId := os.Getenv(fornaxv1.LabelFornaxCorePod)
@@ -78,6 +83,10 @@ const (
 type SessionConfig struct {
 	endpoint string
 	openCmd  string
+	// session process save its state in checkpoint file when it's evacuated, and restore state from it when it's reopened
+	checkpointFile string
+	// pod token issued by node agent, it's presented on every call to prove instance identity
+	token string
 }
 
 type Session struct {
@@ -88,6 +97,7 @@ type Session struct {
 	closingGracePeriodDuration time.Duration
 	closingTimestamp           *time.Time
 	clients                    []*sessiongrpc.ClientSession
+	checkpointData             []byte
 }
 
 type sessionServiceClient struct {
@@ -108,7 +118,7 @@ func (f *sessionServiceClient) PutMessage(message *sessiongrpc.SessionMessage) e
 		return errors.New("FornaxCore connection is not initialized yet")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), DefaultCallTimeout)
+	ctx, cancel := context.WithTimeout(f.withToken(context.Background()), DefaultCallTimeout)
 	defer cancel()
 	opts := grpc.EmptyCallOption{}
 	_, err := f.service.PutMessage(ctx, message, opts)
@@ -119,6 +129,14 @@ func (f *sessionServiceClient) PutMessage(message *sessiongrpc.SessionMessage) e
 	return nil
 }
 
+// withToken attach pod token to outgoing grpc metadata
+func (f *sessionServiceClient) withToken(ctx context.Context) context.Context {
+	if len(f.config.token) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, sessiongrpc.PodTokenMetadataKey, f.config.token)
+}
+
 func (f *sessionServiceClient) disconnect() error {
 	return f.conn.Close()
 }
@@ -164,7 +182,7 @@ func (f *sessionServiceClient) initGetMessageClient(ctx context.Context, identif
 		}
 	}
 	klog.InfoS("Init fornax core get message client", "endpoint", f.config.endpoint)
-	gclient, err := f.service.GetMessage(ctx, identifier)
+	gclient, err := f.service.GetMessage(f.withToken(ctx), identifier)
 	if err != nil {
 		return err
 	}
@@ -224,7 +242,7 @@ func (f *sessionServiceClient) handleSessionCommand(msg *sessiongrpc.SessionMess
 			klog.InfoS("Session NotFound", "sessionId", sessionId)
 			return nil
 		}
-		if session.pid != 0 && session.state != sessiongrpc.SessionState_STATE_CLOSED {
+		if session.pid != 0 && session.state != sessiongrpc.SessionState_STATE_CLOSED && session.state != sessiongrpc.SessionState_STATE_EVACUATED {
 			if _, err := os.FindProcess(session.pid); err != nil {
 				klog.InfoS("Session process NotFound", "sessionId", sessionId, "pid", session.pid)
 				session.state = sessiongrpc.SessionState_STATE_CLOSED
@@ -249,31 +267,25 @@ func (f *sessionServiceClient) handleSessionCommand(msg *sessiongrpc.SessionMess
 		}
 		closeSession := msg.GetCloseSession()
 		session.state = sessiongrpc.SessionState_STATE_CLOSING
-		session.closingGracePeriodDuration = time.Duration(closeSession.GracePeriodSeconds) * time.Second
-		now := time.Now()
-		session.closingTimestamp = &now
-		if proc, err := os.FindProcess(session.pid); err != nil {
-			return nil
-		} else {
-			err := proc.Signal(syscall.SIGTERM)
-			if err != nil {
-				errno, ok := err.(syscall.Errno)
-				if !ok {
-					return err
-				}
-				switch errno {
-				case syscall.ESRCH:
-					// now such process, treat it a closed
-					session.state = sessiongrpc.SessionState_STATE_CLOSED
-					return nil
-				case syscall.EPERM:
-					return nil
-				default:
-					return err
-				}
-			}
+		return f.terminateSessionProcess(session, time.Duration(closeSession.GracePeriodSeconds)*time.Second, sessiongrpc.SessionState_STATE_CLOSED)
+	case sessiongrpc.MessageType_EVACUATE_SESSION:
+		session, found := f.sessions[sessionId.GetIdentifier()]
+		if !found {
+			klog.InfoS("Session NotFound", "sessionId", sessionId)
 			return nil
 		}
+		if session.state == sessiongrpc.SessionState_STATE_CLOSED || session.state == sessiongrpc.SessionState_STATE_EVACUATED {
+			klog.InfoS("Session Already closed", "sessionId", sessionId)
+			return f.sendHeartbeat(session)
+		}
+		// session process is expected to save its state into checkpoint file when it receive sig_term,
+		// checkpoint is sent back to node agent when process exit
+		evacuateSession := msg.GetEvacuateSession()
+		session.state = sessiongrpc.SessionState_STATE_EVACUATING
+		if err := f.terminateSessionProcess(session, time.Duration(evacuateSession.GracePeriodSeconds)*time.Second, sessiongrpc.SessionState_STATE_EVACUATED); err != nil {
+			return err
+		}
+		return f.sendHeartbeat(session)
 	case sessiongrpc.MessageType_OPEN_SESSION:
 		if f.stopping {
 			return errors.New("instance is terminating")
@@ -288,6 +300,13 @@ func (f *sessionServiceClient) handleSessionCommand(msg *sessiongrpc.SessionMess
 			clients:                    []*sessiongrpc.ClientSession{},
 		}
 
+		checkpointData := msg.GetOpenSession().GetSessionConfiguration().GetCheckpointData()
+		if len(f.config.checkpointFile) > 0 && len(checkpointData) > 0 {
+			if err := os.WriteFile(f.config.checkpointFile, checkpointData, 0600); err != nil {
+				klog.ErrorS(err, "Failed to restore session checkpoint", "file", f.config.checkpointFile)
+			}
+		}
+
 		cmd := f.config.openCmd
 		procAttr := os.ProcAttr{}
 		procAttr.Files = []*os.File{os.Stdin, os.Stdout, os.Stderr}
@@ -302,7 +321,13 @@ func (f *sessionServiceClient) handleSessionCommand(msg *sessiongrpc.SessionMess
 				// wait for session process exit, session is closed or process exit itself
 				s, _ := proc.Wait()
 				klog.InfoS("Session process exit", "code", s.ExitCode())
-				session.state = sessiongrpc.SessionState_STATE_CLOSED
+				if session.state == sessiongrpc.SessionState_STATE_EVACUATING {
+					f.readCheckpoint(session)
+					session.state = sessiongrpc.SessionState_STATE_EVACUATED
+					f.sendHeartbeat(session)
+				} else {
+					session.state = sessiongrpc.SessionState_STATE_CLOSED
+				}
 			}()
 		}
 		f.sessions[sessionId.GetIdentifier()] = session
@@ -314,14 +339,58 @@ func (f *sessionServiceClient) handleSessionCommand(msg *sessiongrpc.SessionMess
 	return nil
 }
 
+// terminateSessionProcess send sig_term to session process, it's killed if it does not exit in grace period,
+// session is set to exitState if process does not exist
+func (f *sessionServiceClient) terminateSessionProcess(session *Session, gracePeriod time.Duration, exitState sessiongrpc.SessionState) error {
+	session.closingGracePeriodDuration = gracePeriod
+	now := time.Now()
+	session.closingTimestamp = &now
+	if proc, err := os.FindProcess(session.pid); err != nil {
+		return nil
+	} else {
+		err := proc.Signal(syscall.SIGTERM)
+		if err != nil {
+			errno, ok := err.(syscall.Errno)
+			if !ok {
+				return err
+			}
+			switch errno {
+			case syscall.ESRCH:
+				// now such process, treat it a closed
+				session.state = exitState
+				return nil
+			case syscall.EPERM:
+				return nil
+			default:
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// readCheckpoint read session state saved by session process from checkpoint file
+func (f *sessionServiceClient) readCheckpoint(session *Session) {
+	if len(f.config.checkpointFile) == 0 {
+		return
+	}
+	data, err := os.ReadFile(f.config.checkpointFile)
+	if err != nil {
+		klog.ErrorS(err, "Failed to read session checkpoint", "session", session.id, "file", f.config.checkpointFile)
+		return
+	}
+	session.checkpointData = data
+}
+
 func (f *sessionServiceClient) sendHeartbeat(session *Session) error {
 	msgId := fmt.Sprintf("%d", f.messageId)
 	msgType := sessiongrpc.MessageType_SESSION_STATE
 	sessionState := session.state
 	msgBody := &sessiongrpc.SessionMessage_SessionStatus{
 		SessionStatus: &sessiongrpc.SessionStatus{
-			SessionState:  sessionState,
-			ClientSession: []*sessiongrpc.ClientSession{},
+			SessionState:   sessionState,
+			ClientSession:  []*sessiongrpc.ClientSession{},
+			CheckpointData: session.checkpointData,
 		},
 	}
 	msg := &sessiongrpc.SessionMessage{
@@ -343,7 +412,7 @@ func (f *sessionServiceClient) sendHeartbeat(session *Session) error {
 func (f *sessionServiceClient) houseKeeping() {
 	klog.InfoS("Session house keeping")
 	for _, v := range f.sessions {
-		if v.state == sessiongrpc.SessionState_STATE_CLOSING {
+		if v.state == sessiongrpc.SessionState_STATE_CLOSING || v.state == sessiongrpc.SessionState_STATE_EVACUATING {
 			timeCutoff := time.Now().Add(-1 * v.closingGracePeriodDuration)
 			if v.closingTimestamp.Before(timeCutoff) {
 				syscall.Kill(v.pid, syscall.SIGKILL)
@@ -355,7 +424,7 @@ func (f *sessionServiceClient) houseKeeping() {
 	if f.stopping {
 		allSessionClosed := true
 		for _, v := range f.sessions {
-			if v.state != sessiongrpc.SessionState_STATE_CLOSED {
+			if v.state != sessiongrpc.SessionState_STATE_CLOSED && v.state != sessiongrpc.SessionState_STATE_EVACUATED {
 				allSessionClosed = false
 				break
 			}
@@ -371,7 +440,7 @@ func (f *sessionServiceClient) GracefullyStopSessions() {
 	klog.InfoS("Stopping session wrapper", "endpoint", f.config.endpoint)
 	f.stopping = true
 	for _, v := range f.sessions {
-		if v.pid != 0 && v.state != sessiongrpc.SessionState_STATE_CLOSED {
+		if v.pid != 0 && v.state != sessiongrpc.SessionState_STATE_CLOSED && v.state != sessiongrpc.SessionState_STATE_EVACUATED {
 			v.state = sessiongrpc.SessionState_STATE_CLOSING
 			now := time.Now()
 			v.closingTimestamp = &now
@@ -30,9 +30,11 @@ import (
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	fornaxgrpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
 	"centaurusinfra.io/fornax-serverless/pkg/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/dependency"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/fornaxcore"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/node"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/pod"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/session"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
 	nodetypes "centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
@@ -42,9 +44,13 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/klog/v2"
 )
 
+// SimulationNodeActor simulate a node agent with pods created in a fake container runtime,
+// it manage pods and sessions itself, pod actor and container actor of node agent are not exercised by simulation,
+// driving simulated nodes through FornaxNodeActor require fakes of other node dependencies and session service, it's not done yet
 type SimulationNodeActor struct {
 	podsConcurrency *semaphore.Weighted
 	nodeMutex       sync.RWMutex
@@ -53,27 +59,35 @@ type SimulationNodeActor struct {
 	state           node.NodeState
 	innerActor      message.Actor
 	fornoxCoreRef   message.ActorRef
+	fornaxCoreActor *fornaxcore.FornaxCoreActor
 }
 
 func (n *SimulationNodeActor) Stop() error {
-	n.stopCh <- struct{}{}
+	close(n.stopCh)
 	n.innerActor.Stop()
-	return nil
+	return n.fornaxCoreActor.Stop()
 }
 
 func (n *SimulationNodeActor) Start() error {
 	n.innerActor.Start()
 
-	n.state = node.NodeStateRegistering
+	n.setState(node.NodeStateRegistering)
 	var count int32
-	n.incrementNodeRevision()
+	func() {
+		n.nodeMutex.Lock()
+		defer n.nodeMutex.Unlock()
+		n.incrementNodeRevision()
+	}()
 	// register with fornax core
 	for {
-		if n.state != node.NodeStateRegistering {
+		if n.getState() != node.NodeStateRegistering {
 			// if node has received node configuration from fornaxcore
 			break
 		}
-		klog.InfoS("Start node registry", "node spec", n.node.V1Node)
+		n.nodeMutex.RLock()
+		v1node, revision := n.node.V1Node.DeepCopy(), n.node.Revision
+		n.nodeMutex.RUnlock()
+		klog.InfoS("Start node registry", "node spec", v1node)
 		messageType := fornaxgrpc.MessageType_NODE_REGISTER
 		n.notify(
 			n.fornoxCoreRef,
@@ -81,13 +95,17 @@ func (n *SimulationNodeActor) Start() error {
 				MessageType: messageType,
 				MessageBody: &fornaxgrpc.FornaxCoreMessage_NodeRegistry{
 					NodeRegistry: &fornaxgrpc.NodeRegistry{
-						NodeRevision: n.node.Revision,
-						Node:         n.node.V1Node,
+						NodeRevision: revision,
+						Node:         v1node,
 					},
 				},
 			},
 		)
-		time.Sleep(1 * time.Second)
+		select {
+		case <-n.stopCh:
+			return fmt.Errorf("node %s is stopped before it is registered", n.node.V1Node.Name)
+		case <-time.After(1 * time.Second):
+		}
 		count++
 	}
 
@@ -97,11 +115,36 @@ func (n *SimulationNodeActor) Start() error {
 func (n *SimulationNodeActor) startStateReport() {
 	// start go routine to report node status forever
 	go wait.Until(func() {
-		SetNodeStatus(n.node)
-		n.notify(n.fornoxCoreRef, node.BuildFornaxGrpcNodeState(n.node, n.node.Revision))
+		n.notify(n.fornoxCoreRef, n.buildNodeState(true))
 	}, 1*time.Minute, n.stopCh)
 }
 
+// buildNodeState build a node state message with a copy of node, node is changed by other go routines while message is being sent,
+// node status is refreshed before it's copied if updateStatus is true
+func (n *SimulationNodeActor) buildNodeState(updateStatus bool) *fornaxgrpc.FornaxCoreMessage {
+	n.nodeMutex.Lock()
+	defer n.nodeMutex.Unlock()
+	if updateStatus {
+		SetNodeStatus(n.node)
+	}
+	msg := node.BuildFornaxGrpcNodeState(n.node, n.node.Revision)
+	msg.GetNodeState().Node = n.node.V1Node.DeepCopy()
+	return msg
+}
+
+func (n *SimulationNodeActor) getState() node.NodeState {
+	n.nodeMutex.RLock()
+	defer n.nodeMutex.RUnlock()
+	return n.state
+}
+
+func (n *SimulationNodeActor) setState(state node.NodeState) {
+	n.nodeMutex.Lock()
+	defer n.nodeMutex.Unlock()
+	n.state = state
+}
+
+// incrementNodeRevision must be called with nodeMutex held
 func (n *SimulationNodeActor) incrementNodeRevision() int64 {
 	revision := atomic.AddInt64(&n.node.Revision, 1)
 	n.node.V1Node.ResourceVersion = fmt.Sprint(revision)
@@ -123,6 +166,10 @@ func (n *SimulationNodeActor) actorMessageProcess(msg message.ActorMessage) (int
 func (n *SimulationNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCoreMessage) (interface{}, error) {
 	var err error
 	msgType := msg.GetMessageType()
+	if fornaxgrpc.AckRequired(msgType) && len(msg.GetMessageIdentifier()) > 0 {
+		// simulated node ack every message, fornaxcore resend unacked messages after node reconnect
+		defer n.notify(n.fornoxCoreRef, fornaxgrpc.NewMessageAck(msg.GetMessageIdentifier()))
+	}
 	switch msgType {
 	case fornaxgrpc.MessageType_NODE_CONFIGURATION:
 		err = n.onNodeConfigurationCommand(msg.GetNodeConfiguration())
@@ -142,6 +189,12 @@ func (n *SimulationNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCor
 		}()
 	case fornaxgrpc.MessageType_POD_HIBERNATE:
 		err = n.onPodHibernateCommand(msg.GetPodHibernate())
+	case fornaxgrpc.MessageType_POD_EVACUATE:
+		go func() {
+			n.podsConcurrency.Acquire(context.Background(), 1)
+			defer n.podsConcurrency.Release(1)
+			n.onPodEvacuateCommand(msg.GetPodEvacuate())
+		}()
 	case fornaxgrpc.MessageType_SESSION_OPEN:
 		go func() {
 			n.onSessionOpenCommand(msg.GetSessionOpen())
@@ -163,18 +216,22 @@ func (n *SimulationNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCor
 
 // initialize node with node spec provided by fornaxcore, especially pod cidr
 func (n *SimulationNodeActor) onNodeFullSyncCommand(msg *fornaxgrpc.NodeFullSync) error {
-	n.notify(n.fornoxCoreRef, node.BuildFornaxGrpcNodeState(n.node, n.node.Revision))
+	n.notify(n.fornoxCoreRef, n.buildNodeState(false))
 	return nil
 }
 
 // initialize node with node spec provided by fornaxcore, especially pod cidr
 func (n *SimulationNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeConfiguration) error {
-	if n.state != node.NodeStateRegistering {
+	if n.getState() != node.NodeStateRegistering {
 		return fmt.Errorf("node is not in registering state, it does not expect configuration change after registering")
 	}
 
 	apiNode := msg.GetNode()
-	n.node.V1Node.Spec = *apiNode.Spec.DeepCopy()
+	func() {
+		n.nodeMutex.Lock()
+		defer n.nodeMutex.Unlock()
+		n.node.V1Node.Spec = *apiNode.Spec.DeepCopy()
+	}()
 
 	err := n.initializeNodeDaemons(msg.DaemonPods)
 	if err != nil {
@@ -182,18 +239,21 @@ func (n *SimulationNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeCon
 		return err
 	}
 
-	n.state = node.NodeStateRegistered
+	n.setState(node.NodeStateRegistered)
 	// start go routine to check node status until it is ready
 	go func() {
 		for {
 			// finish if node has initialized
-			if n.state != node.NodeStateRegistered {
+			if n.getState() != node.NodeStateRegistered {
 				break
 			}
 
 			// check node runtime dependencies, send node ready message if node is ready for new pod
+			n.nodeMutex.Lock()
 			SetNodeStatus(n.node)
-			if IsNodeStatusReady(n.node) {
+			ready := IsNodeStatusReady(n.node)
+			n.nodeMutex.Unlock()
+			if ready {
 				klog.InfoS("Node is ready, tell fornax core", "node", n.node.V1Node.Name)
 				// bump revision to let fornax core to update node status
 				func() {
@@ -201,9 +261,11 @@ func (n *SimulationNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeCon
 					defer n.nodeMutex.Unlock()
 					revision := n.incrementNodeRevision()
 					n.node.V1Node.Status.Phase = v1.NodeRunning
-					n.notify(n.fornoxCoreRef, node.BuildFornaxGrpcNodeReady(n.node, revision))
+					msg := node.BuildFornaxGrpcNodeReady(n.node, revision)
+					msg.GetNodeReady().Node = n.node.V1Node.DeepCopy()
+					n.notify(n.fornoxCoreRef, msg)
+					n.state = node.NodeStateReady
 				}()
-				n.state = node.NodeStateReady
 				n.startStateReport()
 			} else {
 				time.Sleep(1 * time.Second)
@@ -290,7 +352,7 @@ func (n *SimulationNodeActor) createPodAndActor(state nodetypes.PodState,
 // find pod actor and send a message to it, if pod actor does not exist, create one
 func (n *SimulationNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) error {
 	klog.InfoS("Creating Pod", "pod", msg.PodIdentifier, "node", n.node.V1Node.Name)
-	if n.state != node.NodeStateReady {
+	if n.getState() != node.NodeStateReady {
 		return fmt.Errorf("Node is not in ready state to create a new pod")
 	}
 	v := n.node.Pods.Get(msg.GetPodIdentifier())
@@ -336,7 +398,14 @@ func (n *SimulationNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) erro
 		conditions = append(conditions, podScheduledCondition)
 		fpod.Pod.Status.Conditions = conditions
 
-		time.Sleep(250 * time.Millisecond)
+		if err := n.createRuntimePod(fpod); err != nil {
+			klog.ErrorS(err, "Failed to create pod in simulated runtime", "pod", fpod.Identifier, "node", n.node.V1Node.Name)
+			n.node.Pods.Del(fpod.Identifier)
+			if fpod.RuntimePod != nil {
+				n.node.Dependencies.RuntimeService.TerminatePod(fpod.RuntimePod.Id, nil)
+			}
+			return err
+		}
 		fpod.FornaxPodState = nodetypes.PodStateRunning
 		func() {
 			n.nodeMutex.Lock()
@@ -353,6 +422,45 @@ func (n *SimulationNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) erro
 	return nil
 }
 
+// create pod sandbox and start containers in simulated container runtime, runtime latency simulate time to create a pod,
+// containers are started here directly instead of by container actors, only runtime latency and pod bookkeeping are simulated
+func (n *SimulationNodeActor) createRuntimePod(fpod *nodetypes.FornaxPod) error {
+	runtimeService := n.node.Dependencies.RuntimeService
+	sandboxConfig := &criv1.PodSandboxConfig{
+		Metadata: &criv1.PodSandboxMetadata{
+			Name:      fpod.Pod.Name,
+			Namespace: fpod.Pod.Namespace,
+			Uid:       string(fpod.Pod.UID),
+		},
+		Labels: fpod.Pod.Labels,
+	}
+	runtimePod, err := runtimeService.CreateSandbox(sandboxConfig, "")
+	if err != nil {
+		return err
+	}
+	fpod.RuntimePod = runtimePod
+
+	for _, v := range fpod.Pod.Spec.Containers {
+		containerConfig := &criv1.ContainerConfig{
+			Metadata: &criv1.ContainerMetadata{Name: v.Name},
+			Image:    &criv1.ImageSpec{Image: v.Image},
+		}
+		runtimeContainer, err := runtimeService.CreateContainer(runtimePod.Id, containerConfig, sandboxConfig)
+		if err != nil {
+			return err
+		}
+		if err := runtimeService.StartContainer(runtimeContainer.Id); err != nil {
+			return err
+		}
+		fpod.Containers[v.Name] = &nodetypes.FornaxContainer{
+			State:            nodetypes.ContainerStateRunning,
+			ContainerSpec:    v.DeepCopy(),
+			RuntimeContainer: runtimeContainer,
+		}
+	}
+	return nil
+}
+
 // find pod actor and send a message to it, if pod actor does not exist, return error
 func (n *SimulationNodeActor) onPodTerminateCommand(msg *fornaxgrpc.PodTerminate) error {
 	klog.InfoS("Terminating Pod", "pod", msg.PodIdentifier, "node", n.node.V1Node.Name)
@@ -360,7 +468,12 @@ func (n *SimulationNodeActor) onPodTerminateCommand(msg *fornaxgrpc.PodTerminate
 	if fpod == nil {
 		return fmt.Errorf("Pod: %s does not exist, fornax core is not in sync", msg.GetPodIdentifier())
 	} else {
-		time.Sleep(250 * time.Millisecond)
+		if fpod.RuntimePod != nil {
+			if err := n.node.Dependencies.RuntimeService.TerminatePod(fpod.RuntimePod.Id, nil); err != nil {
+				klog.ErrorS(err, "Failed to terminate pod in simulated runtime", "pod", fpod.Identifier, "node", n.node.V1Node.Name)
+				return err
+			}
+		}
 		fpod.Pod.Status.Phase = v1.PodSucceeded
 		fpod.FornaxPodState = nodetypes.PodStateTerminated
 		func() {
@@ -382,10 +495,35 @@ func (n *SimulationNodeActor) onPodHibernateCommand(msg *fornaxgrpc.PodHibernate
 	panic("not implemented")
 }
 
+// simulate sessions are evacuated without checkpoint and terminate pod
+func (n *SimulationNodeActor) onPodEvacuateCommand(msg *fornaxgrpc.PodEvacuate) error {
+	klog.InfoS("Evacuating Pod", "pod", msg.PodIdentifier, "node", n.node.V1Node.Name)
+	fpod := n.node.Pods.Get(msg.GetPodIdentifier())
+	if fpod == nil {
+		return fmt.Errorf("Pod: %s does not exist, fornax core is not in sync", msg.GetPodIdentifier())
+	}
+	for _, fsess := range fpod.Sessions {
+		if !util.SessionIsOpen(fsess.Session) {
+			continue
+		}
+		time.Sleep(3 * time.Millisecond)
+		func() {
+			n.nodeMutex.Lock()
+			defer n.nodeMutex.Unlock()
+			revision := n.incrementNodeRevision()
+			fsess.Session.ResourceVersion = fmt.Sprint(revision)
+			fsess.Session.Labels[fornaxv1.LabelFornaxCoreNodeRevision] = fmt.Sprint(revision)
+			fsess.Session.Status.SessionStatus = fornaxv1.SessionStatusEvacuated
+			n.notify(n.fornoxCoreRef, session.BuildFornaxcoreGrpcSessionState(revision, fsess))
+		}()
+	}
+	return n.onPodTerminateCommand(&fornaxgrpc.PodTerminate{PodIdentifier: msg.PodIdentifier})
+}
+
 // find pod actor to let it open a session, if pod actor does not exist, return failure
 func (n *SimulationNodeActor) onSessionOpenCommand(msg *fornaxgrpc.SessionOpen) error {
 	klog.InfoS("Opening session", "session", msg.SessionIdentifier, "pod", msg.PodIdentifier, "node", n.node.V1Node.Name)
-	if n.state != node.NodeStateReady {
+	if n.getState() != node.NodeStateReady {
 		return fmt.Errorf("node is not in ready state to open a session")
 	}
 	sess := &fornaxv1.ApplicationSession{}
@@ -461,11 +599,15 @@ func NewNodeActor(hostIp, hostName string, nodeConfig *config.SimulationNodeConf
 	if err != nil {
 		return nil, err
 	}
+	// pods are created in a in memory container runtime, its latencies simulate pod creation and termination time
+	runtimeService := runtime.NewFakeRuntimeService()
+	runtimeService.SetLatency("CreateSandbox", nodeConfig.PodCreateLatency)
+	runtimeService.SetLatency("TerminatePod", nodeConfig.PodTerminateLatency)
 	fpnode := &node.FornaxNode{
 		NodeConfig:   nodeConfig.NodeConfig,
 		V1Node:       v1node,
 		Pods:         node.NewPodPool(),
-		Dependencies: nil,
+		Dependencies: &dependency.Dependencies{RuntimeService: runtimeService},
 	}
 	SetNodeStatus(fpnode)
 
@@ -477,11 +619,13 @@ func NewNodeActor(hostIp, hostName string, nodeConfig *config.SimulationNodeConf
 		state:           node.NodeStateInitializing,
 		innerActor:      nil,
 		fornoxCoreRef:   nil,
+		fornaxCoreActor: nil,
 	}
 	actor.innerActor = message.NewLocalChannelActor(fpnode.V1Node.GetName(), actor.actorMessageProcess)
 
 	klog.InfoS("Starting FornaxCore actor", "node", hostName)
-	fornaxCoreActor := fornaxcore.NewFornaxCoreActor(fpnode.NodeConfig.NodeIP, util.Name(fpnode.V1Node), nodeConfig.FornaxCoreUrls)
+	fornaxCoreActor := fornaxcore.NewFornaxCoreActor(fpnode.NodeConfig.NodeIP, util.Name(fpnode.V1Node), nodeConfig.FornaxCoreUrls, nil, nodeConfig.FornaxCoreDialer)
+	actor.fornaxCoreActor = fornaxCoreActor
 	actor.fornoxCoreRef = fornaxCoreActor.Reference()
 	err = fornaxCoreActor.Start(actor.innerActor.Reference())
 	if err != nil {
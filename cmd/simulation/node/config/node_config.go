@@ -18,8 +18,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	nconfig "centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/fornaxcore"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/network"
 	"github.com/spf13/pflag"
 )
@@ -31,6 +33,11 @@ type SimulationNodeConfiguration struct {
 	NumOfNode      int
 	PodConcurrency int
 	NodeNamePrefix string
+	// latencies of simulated container runtime to create and terminate a pod
+	PodCreateLatency    time.Duration
+	PodTerminateLatency time.Duration
+	// dial fornaxcores with it instead of tcp if it's set, e.g. to connect an in process fornaxcore
+	FornaxCoreDialer fornaxcore.ContextDialer
 }
 
 func AddConfigFlags(flagSet *pflag.FlagSet, nodeConfig *SimulationNodeConfiguration) {
@@ -43,6 +50,10 @@ func AddConfigFlags(flagSet *pflag.FlagSet, nodeConfig *SimulationNodeConfigurat
 	flagSet.IntVar(&nodeConfig.NumOfNode, "num-of-node", nodeConfig.NumOfNode, "How many nodes are simulated")
 
 	flagSet.IntVar(&nodeConfig.PodConcurrency, "concurrency-of-pod-operation", nodeConfig.PodConcurrency, "How many pods are allowed to create or terminated in parallel")
+
+	flagSet.DurationVar(&nodeConfig.PodCreateLatency, "pod-create-latency", nodeConfig.PodCreateLatency, "How long simulated container runtime take to create a pod sandbox")
+
+	flagSet.DurationVar(&nodeConfig.PodTerminateLatency, "pod-terminate-latency", nodeConfig.PodTerminateLatency, "How long simulated container runtime take to terminate a pod")
 }
 
 func DefaultNodeConfiguration() (*SimulationNodeConfiguration, error) {
@@ -55,12 +66,14 @@ func DefaultNodeConfiguration() (*SimulationNodeConfiguration, error) {
 	namePrefix, _ := os.Hostname()
 
 	return &SimulationNodeConfiguration{
-		NodeConfig:     *nodeConfig,
-		NodeIP:         nodeIp,
-		FornaxCoreUrls: []string{fmt.Sprintf("%s:18001", nodeIp)},
-		NumOfNode:      1,
-		PodConcurrency: 5,
-		NodeNamePrefix: namePrefix,
+		NodeConfig:          *nodeConfig,
+		NodeIP:              nodeIp,
+		FornaxCoreUrls:      []string{fmt.Sprintf("%s:18001", nodeIp)},
+		NumOfNode:           1,
+		PodConcurrency:      5,
+		NodeNamePrefix:      namePrefix,
+		PodCreateLatency:    250 * time.Millisecond,
+		PodTerminateLatency: 250 * time.Millisecond,
 	}, nil
 }
 
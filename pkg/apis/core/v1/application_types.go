@@ -20,10 +20,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/apiserver-runtime/pkg/builder/resource"
 	"sigs.k8s.io/apiserver-runtime/pkg/builder/resource/resourcestrategy"
@@ -68,8 +70,106 @@ type ApplicationSpec struct {
 	// +optional
 	ConfigData map[string]string `json:"configData,omitempty"`
 
+	// Volumes can be mounted by application containers, only emptyDir, hostPath allowed by node,
+	// and configMap or projected volume referring application's own config data, configMap name must be application name,
+	// secret volume, projected secret and container env secret refer to FornaxSecrets in application namespace
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// ImagePullSecrets refer to FornaxSecrets in application namespace which are used to pull application container images,
+	// secrets of type kubernetes.io/dockerconfigjson and kubernetes.io/dockercfg are supported
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// restart policy of application containers, node agent restart exited containers with crash loop backoff if it's Always or OnFailure,
+	// instance is terminated when its container exit if it's Never
+	// +optional, default Never
+	RestartPolicy corev1.RestartPolicy `json:"restartPolicy,omitempty"`
+
+	// max number of concurrent sessions opened on one application instance, sessions are placed onto partially used instances firstly
+	// +optional, default 1
+	SessionsPerInstance int32 `json:"sessionsPerInstance,omitempty"`
+
 	// application scaling policy
 	ScalingPolicy ScalingPolicy `json:"scalingPolicy,omitempty"`
+
+	// NodeSelector is a selector which must match a node's labels for application instances to be scheduled on that node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// node affinity and pod anti affinity of application instances,
+	// a pod anti affinity term without label selector match instances of this application,
+	// only node level topology is supported, topology key is ignored
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// tolerations of application instances, instances are only scheduled on node whose taints are tolerated
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// scheduling priority of application instances, instances of higher priority application are scheduled ahead,
+	// +optional, default 0
+	Priority int32 `json:"priority,omitempty"`
+
+	// PreemptLowerPriority allow instances created for pending sessions to preempt idle instances of lower priority applications
+	// when there is no sufficient resource
+	// +optional, default Never
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+
+	// UpdateStrategy control how idle instances of old revision are replaced when application template changes
+	// +optional
+	UpdateStrategy ApplicationUpdateStrategy `json:"updateStrategy,omitempty"`
+
+	// number of old revisions kept in status for rollback
+	// +optional, default 10
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// RollbackTo roll application template back to a revision recorded in status, it's cleared when template is rolled back
+	// +optional
+	RollbackTo *RollbackConfig `json:"rollbackTo,omitempty"`
+}
+
+// ApplicationUpdateStrategy is rolling update policy of idle instances, allocated instances of old revision are not replaced,
+// they do not get new sessions and are deleted when their sessions are closed
+type ApplicationUpdateStrategy struct {
+	// max number or percent of desired idle instances which can be created above desired idle instances during update
+	// +optional, default 25%
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// max number or percent of desired idle instances which can be unavailable during update
+	// +optional, default 25%
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+type RollbackConfig struct {
+	// revision in Status.Revisions to roll back to
+	Revision int64 `json:"revision,omitempty"`
+}
+
+// ApplicationTemplate is part of application spec which application instances are created from,
+// application instances are rolling updated when it changes
+type ApplicationTemplate struct {
+	Containers              []corev1.Container            `json:"containers,omitempty"`
+	UsingNodeSessionService bool                          `json:"usingNodeSessionService,omitempty"`
+	ConfigData              map[string]string             `json:"configData,omitempty"`
+	Volumes                 []corev1.Volume               `json:"volumes,omitempty"`
+	ImagePullSecrets        []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	RestartPolicy           corev1.RestartPolicy          `json:"restartPolicy,omitempty"`
+	NodeSelector            map[string]string             `json:"nodeSelector,omitempty"`
+	Affinity                *corev1.Affinity              `json:"affinity,omitempty"`
+	Tolerations             []corev1.Toleration           `json:"tolerations,omitempty"`
+}
+
+// ApplicationRevision is a snapshot of application template
+type ApplicationRevision struct {
+	Revision int64 `json:"revision,omitempty"`
+
+	// hash of template, application instances are labeled with it
+	Hash string `json:"hash,omitempty"`
+
+	Template ApplicationTemplate `json:"template,omitempty"`
+
+	CreationTimestamp metav1.Time `json:"creationTimestamp,omitempty"`
 }
 
 type ScalingPolicyType string
@@ -80,6 +180,19 @@ const (
 
 	// scaling according idle session percent
 	ScalingPolicyTypeIdleSessionNum ScalingPolicyType = "idle_session_number"
+
+	// raise minimum instance in scheduled peak windows
+	ScalingPolicyTypeSchedule ScalingPolicyType = "schedule"
+
+	// scaling idle session buffer according observed session creation rate
+	ScalingPolicyTypeSessionRate ScalingPolicyType = "session_rate"
+)
+
+const (
+	DefaultSessionRateWindowSeconds               = 60
+	MaxSessionRateWindowSeconds                   = 3600
+	DefaultSessionRateColdStartSeconds            = 10
+	DefaultSessionRateColdStartProbabilityPercent = 5
 )
 
 type ScalingPolicy struct {
@@ -95,6 +208,44 @@ type ScalingPolicy struct {
 
 	// +optional, must set if ScalingPolicyType == "idle_session_percent"
 	IdleSessionPercentThreshold *IdelSessionPercentThreshold `json:"idleSessionPercentThreshold,omitempty"`
+
+	// +optional, must set if ScalingPolicyType == "schedule",
+	// IdleSessionNumThreshold is also used to scale idle buffer if it's set
+	Schedules []ScalingSchedule `json:"schedules,omitempty"`
+
+	// +optional, used if ScalingPolicyType == "session_rate", default values are used if not set
+	SessionRate *SessionRateScaling `json:"sessionRate,omitempty"`
+}
+
+// ScalingSchedule raise minimum instance in a peak window which start at each cron schedule time and last for duration,
+// if peak windows overlap, the biggest minimum instance is used
+type ScalingSchedule struct {
+	// standard five fields cron expression, e.g. "0 9 * * 1-5", it's evaluated in fornaxcore local time zone,
+	// a CRON_TZ= prefix can be used to specify time zone, e.g. "CRON_TZ=Asia/Tokyo 0 9 * * *"
+	Schedule string `json:"schedule"`
+
+	// how long peak window last after each schedule time
+	DurationSeconds uint32 `json:"durationSeconds"`
+
+	// minimum instance in peak window, must not greater than MaximumInstance
+	MinimumInstance uint32 `json:"minimumInstance"`
+}
+
+// SessionRateScaling size idle session buffer from session creation rate in a sliding window,
+// buffer is big enough that sessions created during a instance cold start exceed it with a probability less than target,
+// session creation is treated as a poisson process
+type SessionRateScaling struct {
+	// sliding window to observe session creation rate
+	// +optional, default 60, must not greater than 3600
+	WindowSeconds uint32 `json:"windowSeconds,omitempty"`
+
+	// how long it take a new instance to become idle
+	// +optional, default 10
+	ColdStartSeconds uint32 `json:"coldStartSeconds,omitempty"`
+
+	// target probability percent that a session has to wait for a cold start instance
+	// +optional, default 5, must less than 100
+	ColdStartProbabilityPercent uint32 `json:"coldStartProbabilityPercent,omitempty"`
 }
 
 // high watermark should > low watermark, if both are 0, then no auto scaling for idle buffer,
@@ -130,6 +281,9 @@ const (
 
 	// delete instance
 	DeploymentActionDeleteInstance DeploymentAction = "DeleteInstance"
+
+	// replace instances of old revision
+	DeploymentActionRollingUpdate DeploymentAction = "RollingUpdate"
 )
 
 type DeploymentStatus string
@@ -187,6 +341,22 @@ type ApplicationStatus struct {
 	// +optional
 	IdleInstances int32 `json:"idleInstances,omitempty"`
 
+	// Total number of instances created from current revision of application template
+	// +optional
+	UpdatedInstances int32 `json:"updatedInstances,omitempty"`
+
+	// revision of current application template
+	// +optional
+	CurrentRevision int64 `json:"currentRevision,omitempty"`
+
+	// hash of current application template
+	// +optional
+	CurrentRevisionHash string `json:"currentRevisionHash,omitempty"`
+
+	// current and old revisions of application template, newest last
+	// +optional
+	Revisions []ApplicationRevision `json:"revisions,omitempty"`
+
 	// DeploymentStatus of Last History
 	// +optional
 
@@ -203,6 +373,8 @@ type ApplicationStatus struct {
 
 var _ resource.Object = &Application{}
 var _ resourcestrategy.Validater = &Application{}
+var _ resourcestrategy.ValidateUpdater = &Application{}
+var _ resourcestrategy.PrepareForUpdater = &Application{}
 
 func (in *Application) GetObjectMeta() *metav1.ObjectMeta {
 	return &in.ObjectMeta
@@ -248,6 +420,49 @@ func (in *Application) Validate(ctx context.Context) field.ErrorList {
 		errorList = append(errorList, &err)
 	}
 
+	if in.Spec.SessionsPerInstance < 0 {
+		err := field.Error{
+			Type:   field.ErrorTypeInvalid,
+			Field:  "Spec.SessionsPerInstance",
+			Detail: "Value should not be less than 0",
+		}
+		errorList = append(errorList, &err)
+	}
+
+	if in.Spec.RevisionHistoryLimit != nil && *in.Spec.RevisionHistoryLimit < 0 {
+		err := field.Error{
+			Type:   field.ErrorTypeInvalid,
+			Field:  "Spec.RevisionHistoryLimit",
+			Detail: "Value should not be less than 0",
+		}
+		errorList = append(errorList, &err)
+	}
+
+	updateStrategy := []*intstr.IntOrString{in.Spec.UpdateStrategy.MaxSurge, in.Spec.UpdateStrategy.MaxUnavailable}
+	for i, name := range []string{"Spec.UpdateStrategy.MaxSurge", "Spec.UpdateStrategy.MaxUnavailable"} {
+		if updateStrategy[i] == nil {
+			continue
+		}
+		if n, err := intstr.GetScaledValueFromIntOrPercent(updateStrategy[i], 100, true); err != nil || n < 0 {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  name,
+				Detail: "Value should be a non negative number or percent",
+			}
+			errorList = append(errorList, &err)
+		}
+	}
+
+	// RollbackTo is cleared when application is rolled back, it's still set if revision is not found
+	if in.Spec.RollbackTo != nil {
+		err := field.Error{
+			Type:   field.ErrorTypeNotFound,
+			Field:  "Spec.RollbackTo.Revision",
+			Detail: fmt.Sprintf("Revision %d is not found in Status.Revisions", in.Spec.RollbackTo.Revision),
+		}
+		errorList = append(errorList, &err)
+	}
+
 	if in.Spec.ScalingPolicy.MaximumInstance == 0 {
 		err := field.Error{
 			Type:   field.ErrorTypeInvalid,
@@ -314,6 +529,62 @@ func (in *Application) Validate(ctx context.Context) field.ErrorList {
 		errorList = append(errorList, &err)
 	}
 
+	if in.Spec.ScalingPolicy.ScalingPolicyType == ScalingPolicyTypeSchedule && len(in.Spec.ScalingPolicy.Schedules) == 0 {
+		err := field.Error{
+			Type:   field.ErrorTypeNotFound,
+			Field:  "Spec.ScalingPolicy.Schedules",
+			Detail: "Spec.ScalingPolicy.ScalingPolicyType is schedule, but Spec.ScalingPolicy.Schedules not found",
+		}
+		errorList = append(errorList, &err)
+	}
+
+	for i, schedule := range in.Spec.ScalingPolicy.Schedules {
+		name := fmt.Sprintf("Spec.ScalingPolicy.Schedules[%d]", i)
+		if _, err := cron.ParseStandard(schedule.Schedule); err != nil {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  name + ".Schedule",
+				Detail: fmt.Sprintf("Invalid cron schedule, %v", err),
+			}
+			errorList = append(errorList, &err)
+		}
+		if schedule.DurationSeconds == 0 {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  name + ".DurationSeconds",
+				Detail: "Value should be greater than 0",
+			}
+			errorList = append(errorList, &err)
+		}
+		if schedule.MinimumInstance > in.Spec.ScalingPolicy.MaximumInstance {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  name + ".MinimumInstance",
+				Detail: "Value should not be greater than Spec.ScalingPolicy.MaximumInstance",
+			}
+			errorList = append(errorList, &err)
+		}
+	}
+
+	if in.Spec.ScalingPolicy.SessionRate != nil {
+		if in.Spec.ScalingPolicy.SessionRate.WindowSeconds > MaxSessionRateWindowSeconds {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  "Spec.ScalingPolicy.SessionRate.WindowSeconds",
+				Detail: fmt.Sprintf("Value should not be greater than %d", MaxSessionRateWindowSeconds),
+			}
+			errorList = append(errorList, &err)
+		}
+		if in.Spec.ScalingPolicy.SessionRate.ColdStartProbabilityPercent >= 100 {
+			err := field.Error{
+				Type:   field.ErrorTypeInvalid,
+				Field:  "Spec.ScalingPolicy.SessionRate.ColdStartProbabilityPercent",
+				Detail: "Value should be less than 100",
+			}
+			errorList = append(errorList, &err)
+		}
+	}
+
 	if len(errorList) > 0 {
 		return errorList
 	} else {
@@ -321,6 +592,55 @@ func (in *Application) Validate(ctx context.Context) field.ErrorList {
 	}
 }
 
+func (in *Application) ValidateUpdate(ctx context.Context, old runtime.Object) field.ErrorList {
+	return in.Validate(ctx)
+}
+
+// PrepareForUpdate roll application template back to a revision in status if Spec.RollbackTo is set,
+// status is copied from old application before it's called
+func (in *Application) PrepareForUpdate(ctx context.Context, old runtime.Object) {
+	if in.Spec.RollbackTo == nil {
+		return
+	}
+	for _, r := range in.Status.Revisions {
+		if r.Revision == in.Spec.RollbackTo.Revision {
+			in.Spec.SetTemplate(&r.Template)
+			in.Spec.RollbackTo = nil
+			return
+		}
+	}
+}
+
+// Template return a copy of application template in spec
+func (in *ApplicationSpec) Template() *ApplicationTemplate {
+	spec := in.DeepCopy()
+	return &ApplicationTemplate{
+		Containers:              spec.Containers,
+		UsingNodeSessionService: spec.UsingNodeSessionService,
+		ConfigData:              spec.ConfigData,
+		Volumes:                 spec.Volumes,
+		ImagePullSecrets:        spec.ImagePullSecrets,
+		RestartPolicy:           spec.RestartPolicy,
+		NodeSelector:            spec.NodeSelector,
+		Affinity:                spec.Affinity,
+		Tolerations:             spec.Tolerations,
+	}
+}
+
+// SetTemplate replace application template in spec with a copy of template
+func (in *ApplicationSpec) SetTemplate(template *ApplicationTemplate) {
+	t := template.DeepCopy()
+	in.Containers = t.Containers
+	in.UsingNodeSessionService = t.UsingNodeSessionService
+	in.ConfigData = t.ConfigData
+	in.Volumes = t.Volumes
+	in.ImagePullSecrets = t.ImagePullSecrets
+	in.RestartPolicy = t.RestartPolicy
+	in.NodeSelector = t.NodeSelector
+	in.Affinity = t.Affinity
+	in.Tolerations = t.Tolerations
+}
+
 var _ resource.ObjectList = &ApplicationList{}
 
 func (in *ApplicationList) GetListMeta() *metav1.ListMeta {
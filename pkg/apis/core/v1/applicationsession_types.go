@@ -70,8 +70,24 @@ type ApplicationSessionSpec struct {
 
 	// how long to wait for session status from Starting to Available
 	OpenTimeoutSeconds uint16 `json:"openTimeoutSeconds,omitempty"`
+
+	// how long a open session can stay without connected clients, session is closed by fornaxcore when it's idle longer than this,
+	// +optional, default 0, never close idle session
+	IdleTimeoutSeconds uint32 `json:"idleTimeoutSeconds,omitempty"`
+
+	// how long a session can stay open since it's available, session is closed by fornaxcore when it's open longer than this,
+	// +optional, default 0, no lifetime limit
+	MaxLifetimeSeconds uint32 `json:"maxLifetimeSeconds,omitempty"`
 }
 
+const (
+	// session was closed by fornaxcore as it did not have connected clients longer than idle timeout
+	SessionCloseReasonIdleTimeout = "IdleTimeout"
+
+	// session was closed by fornaxcore as it exceeded max lifetime
+	SessionCloseReasonLifetimeExceeded = "LifetimeExceeded"
+)
+
 // +enum
 type SessionStatus string
 
@@ -94,6 +110,12 @@ const (
 	// session is closing on instance, wait for session client exit
 	SessionStatusClosing SessionStatus = "Closing"
 
+	// session is being evacuated from instance, wait for instance checkpoint it
+	SessionStatusEvacuating SessionStatus = "Evacuating"
+
+	// session is checkpointed and left instance, it's reopened on another instance using session data and checkpoint
+	SessionStatusEvacuated SessionStatus = "Evacuated"
+
 	// session is closed on instance
 	SessionStatusClosed SessionStatus = "Closed"
 
@@ -137,8 +159,28 @@ type ApplicationSessionStatus struct {
 	// +optional
 	CloseTime *metav1.Time `json:"closeTime,omitempty"`
 
+	// since when a open session does not have connected clients, nil if session has clients or is not open
+	// +optional
+	IdleSince *metav1.Time `json:"idleSince,omitempty"`
+
+	// why session was closed by fornaxcore, it's empty if session was closed by client or instance
+	// +optional
+	CloseReason string `json:"closeReason,omitempty"`
+
 	// +optional, for metrics test
 	AvailableTimeMicro int64 `json:"availableTimeMicro,omitempty"`
+
+	// checkpoint reported by instance when session was evacuated, it's passed to new instance with session data to restore session
+	// +optional
+	CheckpointData []byte `json:"checkpointData,omitempty"`
+
+	// since when a evacuated session was requeued as pending, session open timeout is counted from it instead of creation time
+	// +optional
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+
+	// when session status last changed, it's set by fornaxcore when it save a different session status
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 var _ resource.Object = &ApplicationSession{}
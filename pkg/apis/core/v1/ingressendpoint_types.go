@@ -18,6 +18,7 @@ package v1
 
 import (
 	"context"
+	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -134,12 +135,17 @@ func (in *IngressEndpoint) NewList() runtime.Object {
 	return &IngressEndpointList{}
 }
 
+var IngressEndpointGrv = schema.GroupVersionResource{
+	Group:    "core.fornax-serverless.centaurusinfra.io",
+	Version:  "v1",
+	Resource: "ingressendpoints",
+}
+
+var IngressEndpointKind = SchemeGroupVersion.WithKind("IngressEndpoint")
+var IngressEndpointGrvKey = fmt.Sprintf("/%s/%s", IngressEndpointGrv.Group, IngressEndpointGrv.Resource)
+
 func (in *IngressEndpoint) GetGroupVersionResource() schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "core.fornax-serverless.centaurusinfra.io",
-		Version:  "v1",
-		Resource: "ingressendpoints",
-	}
+	return IngressEndpointGrv
 }
 
 func (in *IngressEndpoint) IsStorageVersion() bool {
@@ -21,10 +21,17 @@ const (
 	LabelFornaxCorePod                    = "pod.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreNodeDaemon             = "daemon.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreApplication            = "application.core.fornax-serverless.centaurusinfra.io"
+	LabelFornaxCoreApplicationRevision    = "revision.application.core.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreCreationUnixMicro      = "create.unixmicro.core.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreApplicationSession     = "applicationsession.core.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreSessionService         = "sessionservice.core.fornax-serverless.centaurusinfra.io"
+	LabelFornaxCoreSessionServiceToken    = "sessionservicetoken.core.fornax-serverless.centaurusinfra.io"
 	LabelFornaxCoreNodeRevision           = "noderevision.core.fornax-serverless.centaurusinfra.io"
+	LabelFornaxCoreNodeRuntimeHandler     = "runtimehandler.node.fornax-serverless.centaurusinfra.io"
 	AnnotationFornaxCoreHibernatePod      = "hibernatepod.core.fornax-serverless.centaurusinfra.io"
 	AnnotationFornaxCoreSessionServicePod = "sessionservicepod.core.fornax-serverless.centaurusinfra.io"
+	AnnotationFornaxCoreSessionPendingPod = "sessionpendingpod.core.fornax-serverless.centaurusinfra.io"
+	AnnotationFornaxCoreNodeDaemonHash    = "daemonhash.node.fornax-serverless.centaurusinfra.io"
+	AnnotationFornaxCoreEvacuate          = "evacuate.core.fornax-serverless.centaurusinfra.io"
+	AnnotationFornaxCoreNodeOutbox        = "outbox.node.fornax-serverless.centaurusinfra.io"
 )
@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// FornaxEvent serve k8s events of fornax resources, events of cluster scoped resource like node are in default namespace
+type FornaxEvent struct {
+	corev1.Event
+}
+
+func (in *FornaxEvent) NamespaceScoped() bool {
+	return true
+}
+
+func (in *FornaxEvent) New() runtime.Object {
+	return &corev1.Event{}
+}
+
+func (in *FornaxEvent) NewList() runtime.Object {
+	return &corev1.EventList{}
+}
+
+func (in *FornaxEvent) GetGroupVersionResource() schema.GroupVersionResource {
+	return FornaxEventGrv
+}
+
+func (in *FornaxEvent) IsStorageVersion() bool {
+	return true
+}
+
+func (in *FornaxEvent) GetObjectMeta() *metav1.ObjectMeta {
+	return &(in.ObjectMeta)
+}
+
+var FornaxEventGrv = schema.GroupVersionResource{
+	Group:    "k8s.io",
+	Version:  "v1",
+	Resource: "events",
+}
+
+var FornaxEventKind = K8sSchemeGroupVersion.WithKind("Event")
+var FornaxEventGrvKey = fmt.Sprintf("/%s/%s", FornaxEventGrv.Group, FornaxEventGrv.Resource)
@@ -1,6 +1,8 @@
 package v1
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -24,11 +26,7 @@ func (in *FornaxSecret) NewList() runtime.Object {
 }
 
 func (in *FornaxSecret) GetGroupVersionResource() schema.GroupVersionResource {
-	return schema.GroupVersionResource{
-		Group:    "k8s.io",
-		Version:  "v1",
-		Resource: "secrets",
-	}
+	return FornaxSecretGrv
 }
 
 func (in *FornaxSecret) IsStorageVersion() bool {
@@ -38,3 +36,12 @@ func (in *FornaxSecret) IsStorageVersion() bool {
 func (in *FornaxSecret) GetObjectMeta() *metav1.ObjectMeta {
 	return &(in.ObjectMeta)
 }
+
+var FornaxSecretGrv = schema.GroupVersionResource{
+	Group:    "k8s.io",
+	Version:  "v1",
+	Resource: "secrets",
+}
+
+var FornaxSecretKind = K8sSchemeGroupVersion.WithKind("Secret")
+var FornaxSecretGrvKey = fmt.Sprintf("/%s/%s", FornaxSecretGrv.Group, FornaxSecretGrv.Resource)
@@ -48,5 +48,15 @@ var AddToScheme = func(scheme *runtime.Scheme) error {
 	}, &FornaxPod{})
 	// }, &FornaxPod{}, &FornaxPodList{})
 
+	scheme.AddKnownTypes(schema.GroupVersion{
+		Group:   "k8s.io",
+		Version: "v1",
+	}, &FornaxSecret{})
+
+	scheme.AddKnownTypes(schema.GroupVersion{
+		Group:   "k8s.io",
+		Version: "v1",
+	}, &FornaxEvent{})
+
 	return nil
 }
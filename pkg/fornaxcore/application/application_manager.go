@@ -26,6 +26,7 @@ import (
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
 	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
@@ -37,6 +38,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 )
@@ -54,6 +56,8 @@ type ApplicationPool struct {
 	mu          sync.RWMutex
 	podsByState map[ApplicationPodState]map[string]*ApplicationPod
 	sessions    map[ApplicationSessionState]map[string]*ApplicationSession
+	// number of sessions created in each second, oldest first, it's used to estimate session creation rate
+	sessionCreations []sessionCreationCount
 }
 
 func NewApplicationPool(appName string) *ApplicationPool {
@@ -88,19 +92,22 @@ type ApplicationManager struct {
 	applicationPools map[string]*ApplicationPool
 
 	applicationStore fornaxstore.ApiStorageInterface
+	secretStore      fornaxstore.ApiStorageInterface
 	appUpdateChannel <-chan fornaxstore.WatchEventWithOldObj
 
 	podUpdateChannel     chan *ie.PodEvent
 	podManager           ie.PodManagerInterface
 	sessionManager       ie.SessionManagerInterface
 	sessionUpdateChannel <-chan fornaxstore.WatchEventWithOldObj
+	ingressManager       ie.IngressManagerInterface
+	eventRecorder        record.EventRecorder
 
 	applicationStatusManager *ApplicationStatusManager
 }
 
 // NewApplicationManager init ApplicationInformer and ApplicationSessionInformer,
 // and start to listen to pod event from node
-func NewApplicationManager(ctx context.Context, podManager ie.PodManagerInterface, sessionManager ie.SessionManagerInterface, appStore fornaxstore.ApiStorageInterface) *ApplicationManager {
+func NewApplicationManager(ctx context.Context, podManager ie.PodManagerInterface, sessionManager ie.SessionManagerInterface, appStore fornaxstore.ApiStorageInterface, secretStore fornaxstore.ApiStorageInterface, eventRecorder record.EventRecorder) *ApplicationManager {
 	am := &ApplicationManager{
 		ctx:              ctx,
 		applicationQueue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "fornaxv1.Application"),
@@ -109,16 +116,24 @@ func NewApplicationManager(ctx context.Context, podManager ie.PodManagerInterfac
 		podManager:       podManager,
 		sessionManager:   sessionManager,
 		applicationStore: appStore,
+		secretStore:      secretStore,
+		eventRecorder:    eventRecorder,
 	}
 	am.podManager.Watch(am.podUpdateChannel)
 
 	return am
 }
 
+// SetIngressManager let sessions be accessed through ingress gateway instead of node host ports
+func (am *ApplicationManager) SetIngressManager(ingressManager ie.IngressManagerInterface) {
+	am.ingressManager = ingressManager
+}
+
 func (am *ApplicationManager) deleteApplicationPool(applicationKey string) {
 	am.mu.Lock()
 	defer am.mu.Unlock()
 	delete(am.applicationPools, applicationKey)
+	deleteApplicationMetrics(applicationKey)
 }
 
 func (am *ApplicationManager) applicationList() map[string]*ApplicationPool {
@@ -191,7 +206,7 @@ func (am *ApplicationManager) Run(ctx context.Context) {
 		for {
 			select {
 			case <-ctx.Done():
-				break
+				return
 			case we := <-am.appUpdateChannel:
 				am.onApplicationEventFromStorage(we)
 			}
@@ -208,7 +223,7 @@ func (am *ApplicationManager) Run(ctx context.Context) {
 			for {
 				select {
 				case <-ctx.Done():
-					break
+					return
 				case update := <-am.podUpdateChannel:
 					am.onPodEventFromNode(update)
 				}
@@ -220,7 +235,7 @@ func (am *ApplicationManager) Run(ctx context.Context) {
 			for {
 				select {
 				case <-ctx.Done():
-					break
+					return
 				case we := <-am.sessionUpdateChannel:
 					am.onSessionEventFromStorage(we)
 				}
@@ -236,7 +251,7 @@ func (am *ApplicationManager) Run(ctx context.Context) {
 		for {
 			select {
 			case <-ctx.Done():
-				break
+				return
 			case <-ticker.C:
 				am.HouseKeeping()
 			}
@@ -338,7 +353,7 @@ func (am *ApplicationManager) syncApplication(ctx context.Context, applicationKe
 	defer func() {
 		et := time.Now().UnixMicro()
 		klog.InfoS("Done syncing application", "application", applicationKey, "took-micro", et-st)
-		// TODO post metrics
+		metrics.ApplicationSyncDuration.WithLabelValues(metrics.Result(syncErr)).Observe(float64(et-st) / float64(time.Second/time.Microsecond))
 	}()
 	pool := am.getApplicationPool(applicationKey)
 	if pool == nil {
@@ -360,17 +375,28 @@ func (am *ApplicationManager) syncApplication(ctx context.Context, applicationKe
 			}
 		}
 	} else if application != nil {
+		// pods are created from current revision of application template, sessions are only assigned to pods of current revision
+		revision := util.ApplicationTemplateHash(application)
 		if application.DeletionTimestamp == nil {
 			// 1, assign pending session to idle pods firstly and cleanup timedout and deleting sessions
-			syncErr = am.deployApplicationSessions(pool, application)
+			syncErr = am.deployApplicationSessions(pool, application, revision)
 
-			// 2, find how many more pods required for remaining pending sessions
+			// 2, find how many more pods required for remaining pending sessions,
+			// allocated pods of all revisions are counted as instances, but only pending and idle pods of current revision are counted as idle buffer
 			if syncErr == nil {
 				sessionSummary := pool.summarySession()
-				numOfAllocatedPod, numOfPendingPod, numOfIdlePod := pool.activePodNums()
+				numOfAllocatedPod, _, _ := pool.activePodNums()
+				_, numOfPendingPod, numOfIdlePod := pool.activePodNumsOfRevision(revision)
 				numOfUnAllocatedPod := numOfPendingPod + numOfIdlePod
 				numOfPendingSession := sessionSummary.pendingCount
-				numOfDesiredUnAllocatedPod := am.calculateDesiredIdlePods(application, numOfAllocatedPod, numOfUnAllocatedPod, numOfPendingSession)
+				sessionsPerInstance := util.ApplicationSessionsPerInstance(application)
+				numOfFreeAllocatedSlot := pool.freeSessionSlotsOfAllocatedPods(sessionsPerInstance, revision)
+				scalingTarget := calculateScalingTarget(pool, application, time.Now())
+				if !scalingTarget.nextChange.IsZero() {
+					// sync again when scheduled window start or end, or observed sessions fall out of rate window
+					am.applicationQueue.AddAfter(applicationKey, time.Until(scalingTarget.nextChange))
+				}
+				numOfDesiredUnAllocatedPod := am.calculateDesiredIdlePods(application, scalingTarget, numOfAllocatedPod, numOfUnAllocatedPod, numOfFreeAllocatedSlot, numOfPendingSession)
 				numOfDesiredPod = numOfAllocatedPod + numOfDesiredUnAllocatedPod
 				klog.InfoS("Syncing application pod", "application", applicationKey, "pending-sessions", numOfPendingSession, "active-pods", numOfAllocatedPod+numOfUnAllocatedPod, "pending-pods", numOfPendingPod, "idle-pods", numOfIdlePod, "desired-pending+idle-pods", numOfDesiredUnAllocatedPod)
 				if numOfDesiredUnAllocatedPod > numOfUnAllocatedPod {
@@ -380,7 +406,14 @@ func (am *ApplicationManager) syncApplication(ctx context.Context, applicationKe
 				}
 				// pending session will need pods immediately, the rest of pods can be created as a standby pod
 				desiredAddition := numOfDesiredUnAllocatedPod - numOfUnAllocatedPod
-				syncErr = am.deployApplicationPods(pool, application, desiredAddition)
+				// pending sessions not covered by pending pods are waiting for new pods
+				numOfSessionPendingPods := int(math.Ceil(float64(numOfPendingSession)/float64(sessionsPerInstance))) - numOfPendingPod
+
+				// 3, replace pending and idle pods of old revisions gradually, it limit pods created during rolling update
+				desiredAddition, syncErr = am.rollingUpdateApplicationPods(pool, application, revision, numOfDesiredUnAllocatedPod, desiredAddition, numOfSessionPendingPods)
+				if syncErr == nil {
+					syncErr = am.deployApplicationPods(pool, application, revision, desiredAddition, numOfSessionPendingPods)
+				}
 			}
 		} else {
 			numOfDesiredPod = 0
@@ -393,7 +426,7 @@ func (am *ApplicationManager) syncApplication(ctx context.Context, applicationKe
 
 		// take care of timeout and deleting pods
 		am.pruneDeadPods(pool)
-		newStatus := am.calculateStatus(pool, application, numOfDesiredPod, action, syncErr)
+		newStatus := am.calculateStatus(pool, application, revision, numOfDesiredPod, action, syncErr)
 		am.applicationStatusManager.UpdateApplicationStatus(application, newStatus)
 	}
 
@@ -438,41 +471,50 @@ func (am *ApplicationManager) pruneDeadPods(pool *ApplicationPool) {
 	}
 }
 
-func (am *ApplicationManager) calculateDesiredIdlePods(application *fornaxv1.Application, occupiedPodNum, idlePodNum int, sessionNum int) int {
+// calculateDesiredIdlePods return desired number of pending and idle pods, idle sessions are counted as free session slots,
+// which include free slots of occupied pods and all slots of pending and idle pods, slot shortage or surplus are converted to pods,
+// minimum instance and idle session watermarks come from scaling target evaluated from scaling policy
+func (am *ApplicationManager) calculateDesiredIdlePods(application *fornaxv1.Application, target applicationScalingTarget, occupiedPodNum, idlePodNum, freeOccupiedSlotNum int, sessionNum int) int {
+	sessionsPerInstance := util.ApplicationSessionsPerInstance(application)
 	desiredCount := idlePodNum
-	sessionSupported := idlePodNum
+	sessionSupported := idlePodNum*sessionsPerInstance + freeOccupiedSlotNum
 	idleSessionNum := int(sessionSupported) - sessionNum
 
-	if application.Spec.ScalingPolicy.ScalingPolicyType == fornaxv1.ScalingPolicyTypeIdleSessionNum {
-		lowThresholdNum := int(application.Spec.ScalingPolicy.IdleSessionNumThreshold.LowWaterMark)
+	if target.byIdleSessionNum {
+		lowThresholdNum := target.idleSessionLowWaterMark
 		if idleSessionNum < lowThresholdNum {
-			desiredCount = idlePodNum + int(math.Ceil(float64(lowThresholdNum-idleSessionNum)))
+			desiredCount = idlePodNum + int(math.Ceil(float64(lowThresholdNum-idleSessionNum)/float64(sessionsPerInstance)))
 		}
 
-		highThresholdNum := int(application.Spec.ScalingPolicy.IdleSessionNumThreshold.HighWaterMark)
+		highThresholdNum := target.idleSessionHighWaterMark
 		if idleSessionNum > highThresholdNum {
-			desiredCount = idlePodNum - int(math.Floor(float64(idleSessionNum-highThresholdNum)))
+			desiredCount = idlePodNum - int(math.Floor(float64(idleSessionNum-highThresholdNum)/float64(sessionsPerInstance)))
 		}
 	}
 
 	if application.Spec.ScalingPolicy.ScalingPolicyType == fornaxv1.ScalingPolicyTypeIdleSessionPercent {
 		lowThreshold := int(application.Spec.ScalingPolicy.IdleSessionPercentThreshold.LowWaterMark)
 		lowThresholdNum := sessionSupported * lowThreshold / 100
-		if idleSessionNum < lowThreshold {
-			desiredCount = idlePodNum + int(math.Ceil(float64(lowThresholdNum-idleSessionNum)))
+		if idleSessionNum < lowThresholdNum {
+			desiredCount = idlePodNum + int(math.Ceil(float64(lowThresholdNum-idleSessionNum)/float64(sessionsPerInstance)))
 		}
 
 		highThreshold := int(application.Spec.ScalingPolicy.IdleSessionPercentThreshold.HighWaterMark)
 		highThresholdNum := sessionSupported * highThreshold / 100
-		if idleSessionNum > highThreshold {
-			desiredCount = idlePodNum - int(math.Floor(float64(idleSessionNum-highThresholdNum)))
+		if idleSessionNum > highThresholdNum {
+			desiredCount = idlePodNum - int(math.Floor(float64(idleSessionNum-highThresholdNum)/float64(sessionsPerInstance)))
 		}
 	}
 
+	// free slots of occupied pods could cover more than idle pods, can not delete more than idle pods
+	if desiredCount < 0 {
+		desiredCount = 0
+	}
+
 	numOfDesiredPod := desiredCount + occupiedPodNum
 	// total number must between maximum and minmum instances
-	if numOfDesiredPod <= int(application.Spec.ScalingPolicy.MinimumInstance) {
-		desiredCount = int(application.Spec.ScalingPolicy.MinimumInstance) - occupiedPodNum
+	if numOfDesiredPod <= target.minimumInstance {
+		desiredCount = target.minimumInstance - occupiedPodNum
 	} else if numOfDesiredPod >= int(application.Spec.ScalingPolicy.MaximumInstance) {
 		desiredCount = int(application.Spec.ScalingPolicy.MaximumInstance) - occupiedPodNum
 		// not able to add more, as already reach maxinum instances
@@ -483,11 +525,23 @@ func (am *ApplicationManager) calculateDesiredIdlePods(application *fornaxv1.App
 	return desiredCount
 }
 
-func (am *ApplicationManager) calculateStatus(pool *ApplicationPool, application *fornaxv1.Application, desiredCount int, action fornaxv1.DeploymentAction, deploymentErr error) *fornaxv1.ApplicationStatus {
+func (am *ApplicationManager) calculateStatus(pool *ApplicationPool, application *fornaxv1.Application, revision string, desiredCount int, action fornaxv1.DeploymentAction, deploymentErr error) *fornaxv1.ApplicationStatus {
 	newStatus := application.Status.DeepCopy()
 	poolSummary := pool.summaryPod(am.podManager)
+	if am.getApplicationPool(pool.appName) != nil {
+		// pool of a deleted application is removed already, do not export it again
+		recordApplicationMetrics(pool, poolSummary)
+	}
+	numOfUpdatedAllocatedPod, numOfUpdatedPendingPod, numOfUpdatedIdlePod := pool.activePodNumsOfRevision(revision)
+	numOfUpdatedPod := int32(numOfUpdatedAllocatedPod + numOfUpdatedPendingPod + numOfUpdatedIdlePod)
+	numOfOldPod := poolSummary.totalCount - poolSummary.deletingCount - numOfUpdatedPod
+	if numOfOldPod > 0 && action == "" && application.DeletionTimestamp == nil {
+		action = fornaxv1.DeploymentActionRollingUpdate
+	}
 
 	if application.Status.DesiredInstances == int32(desiredCount) &&
+		application.Status.CurrentRevisionHash == revision &&
+		application.Status.UpdatedInstances == numOfUpdatedPod &&
 		application.Status.TotalInstances == poolSummary.totalCount &&
 		application.Status.IdleInstances == poolSummary.idleCount &&
 		application.Status.DeletingInstances == poolSummary.deletingCount &&
@@ -502,8 +556,11 @@ func (am *ApplicationManager) calculateStatus(pool *ApplicationPool, application
 	newStatus.DeletingInstances = poolSummary.deletingCount
 	newStatus.IdleInstances = poolSummary.idleCount
 	newStatus.AllocatedInstances = poolSummary.occupiedCount
+	newStatus.UpdatedInstances = numOfUpdatedPod
+	newStatus.Revisions, newStatus.CurrentRevision = am.calculateRevisions(application, revision)
+	newStatus.CurrentRevisionHash = revision
 
-	if action == fornaxv1.DeploymentActionCreateInstance || action == fornaxv1.DeploymentActionDeleteInstance {
+	if action == fornaxv1.DeploymentActionCreateInstance || action == fornaxv1.DeploymentActionDeleteInstance || action == fornaxv1.DeploymentActionRollingUpdate {
 		message := fmt.Sprintf("deploy application instance, total: %d, desired: %d, pending: %d, deleting: %d, allocated: %d, idle: %d",
 			newStatus.TotalInstances,
 			newStatus.DesiredInstances,
@@ -511,6 +568,9 @@ func (am *ApplicationManager) calculateStatus(pool *ApplicationPool, application
 			newStatus.DeletingInstances,
 			newStatus.AllocatedInstances,
 			newStatus.IdleInstances)
+		if numOfOldPod > 0 {
+			message = fmt.Sprintf("%s, rolling update to revision %d, updated: %d, old: %d", message, newStatus.CurrentRevision, newStatus.UpdatedInstances, numOfOldPod)
+		}
 
 		deploymentHistory := fornaxv1.DeploymentHistory{
 			Action: action,
@@ -535,6 +595,29 @@ func (am *ApplicationManager) calculateStatus(pool *ApplicationPool, application
 	return newStatus
 }
 
+// recordApplicationMetrics export pod and session numbers of application
+func recordApplicationMetrics(pool *ApplicationPool, poolSummary ApplicationPodSummary) {
+	metrics.ApplicationPods.WithLabelValues(pool.appName, "pending").Set(float64(poolSummary.pendingCount))
+	metrics.ApplicationPods.WithLabelValues(pool.appName, "deleting").Set(float64(poolSummary.deletingCount))
+	metrics.ApplicationPods.WithLabelValues(pool.appName, "idle").Set(float64(poolSummary.idleCount))
+	metrics.ApplicationPods.WithLabelValues(pool.appName, "allocated").Set(float64(poolSummary.occupiedCount))
+	sessionSummary := pool.summarySession()
+	metrics.ApplicationSessions.WithLabelValues(pool.appName, "pending").Set(float64(sessionSummary.pendingCount))
+	metrics.ApplicationSessions.WithLabelValues(pool.appName, "starting").Set(float64(sessionSummary.startingCount))
+	metrics.ApplicationSessions.WithLabelValues(pool.appName, "running").Set(float64(sessionSummary.runningCount))
+	metrics.ApplicationSessions.WithLabelValues(pool.appName, "deleting").Set(float64(sessionSummary.deletingCount))
+}
+
+// deleteApplicationMetrics remove pod and session numbers of a removed application
+func deleteApplicationMetrics(applicationKey string) {
+	for _, state := range []string{"pending", "deleting", "idle", "allocated"} {
+		metrics.ApplicationPods.Delete(map[string]string{"application": applicationKey, "state": state})
+	}
+	for _, state := range []string{"pending", "starting", "running", "deleting"} {
+		metrics.ApplicationSessions.Delete(map[string]string{"application": applicationKey, "state": state})
+	}
+}
+
 func (am *ApplicationManager) HouseKeeping() error {
 	appPools := am.applicationList()
 	klog.Info("Application house keeping")
@@ -54,6 +54,8 @@ type ApplicationPod struct {
 	podName  string
 	state    ApplicationPodState
 	sessions map[string]bool
+	// template hash of application revision which pod is created from
+	revision string
 }
 
 func NewApplicationPod(podName string, state ApplicationPodState) *ApplicationPod {
@@ -100,6 +102,13 @@ func (am *ApplicationManager) handlePodAddUpdateFromNode(pod *v1.Pod) {
 		return
 	} else {
 		pool := am.getOrCreateApplicationPool(applicationKey)
+		if util.PodHasEvacuateAnnotation(pod) && util.PodNotTerminated(pod) {
+			// pod is being evacuated, do not assign new session to it, node terminate it after its sessions are evacuated
+			pool.addOrUpdatePod(podName, PodStateDeleting, util.GetPodSessionNames(pod))
+			am.enqueueApplication(applicationKey)
+			return
+		}
+		pool.setPodRevision(podName, util.PodApplicationRevision(pod))
 		ap := pool.getPod(podName)
 		if ap != nil && ap.state == PodStateDeleting {
 			am.deleteApplicationPod(pool, ap.podName)
@@ -123,6 +132,7 @@ func (am *ApplicationManager) handlePodAddUpdateFromNode(pod *v1.Pod) {
 		} else {
 			// do not add terminated pod
 		}
+		pool.setPodRevision(podName, util.PodApplicationRevision(pod))
 	}
 	am.enqueueApplication(applicationKey)
 }
@@ -191,10 +201,10 @@ func (am *ApplicationManager) deleteApplicationPod(pool *ApplicationPool, podNam
 	return nil
 }
 
-func (am *ApplicationManager) createApplicationPod(application *fornaxv1.Application, standby bool) (*v1.Pod, error) {
+func (am *ApplicationManager) createApplicationPod(application *fornaxv1.Application, standby, sessionPending bool) (*v1.Pod, error) {
 	uid := uuid.New()
 	name := fmt.Sprintf("%s-%s-%d", application.Name, rand.String(16), uid.ClockSequence())
-	podTemplate := am.getPodApplicationPodTemplate(uid, name, application, standby)
+	podTemplate := am.getPodApplicationPodTemplate(uid, name, application, standby, sessionPending)
 	pod, err := am.podManager.AddOrUpdatePod(podTemplate)
 	if err != nil {
 		return nil, err
@@ -205,13 +215,22 @@ func (am *ApplicationManager) createApplicationPod(application *fornaxv1.Applica
 
 // getPodApplicationPodTemplate will translate application container spec to a pod spec,
 // it add application specific environment variables
-// to enable container to setup session connection with node and client
-func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name string, application *fornaxv1.Application, standby bool) *v1.Pod {
+// to enable container to setup session connection with node and client,
+// pod created for pending sessions is annotated to be scheduled ahead of pods created for idle buffer
+func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name string, application *fornaxv1.Application, standby, sessionPending bool) *v1.Pod {
 	enableServiceLinks := false
 	setHostnameAsFQDN := false
 	mountServiceAccount := false
 	shareProcessNamespace := false
 	preemptionPolicy := v1.PreemptNever
+	if application.Spec.PreemptionPolicy != nil {
+		preemptionPolicy = *application.Spec.PreemptionPolicy
+	}
+	priority := application.Spec.Priority
+	restartPolicy := v1.RestartPolicyNever
+	if len(application.Spec.RestartPolicy) > 0 {
+		restartPolicy = application.Spec.RestartPolicy
+	}
 	pod := &v1.Pod{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Pod",
@@ -230,7 +249,8 @@ func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name s
 			DeletionTimestamp:          nil,
 			DeletionGracePeriodSeconds: application.DeletionGracePeriodSeconds,
 			Labels: map[string]string{
-				fornaxv1.LabelFornaxCoreApplication: util.Name(application),
+				fornaxv1.LabelFornaxCoreApplication:         util.Name(application),
+				fornaxv1.LabelFornaxCoreApplicationRevision: util.ApplicationTemplateHash(application),
 			},
 			Annotations: map[string]string{},
 			OwnerReferences: []metav1.OwnerReference{
@@ -249,7 +269,7 @@ func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name s
 			Volumes:                       []v1.Volume{},
 			InitContainers:                []v1.Container{},
 			EphemeralContainers:           []v1.EphemeralContainer{},
-			RestartPolicy:                 v1.RestartPolicyNever,
+			RestartPolicy:                 restartPolicy,
 			TerminationGracePeriodSeconds: nil,
 			ActiveDeadlineSeconds:         nil,
 			DNSPolicy:                     v1.DNSNone,
@@ -269,8 +289,8 @@ func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name s
 			Affinity:                      &v1.Affinity{},
 			Tolerations:                   []v1.Toleration{},
 			HostAliases:                   []v1.HostAlias{},
+			Priority:                      &priority,
 			// PriorityClassName:             "",
-			// Priority:                      nil,
 			// DNSConfig:                     nil,
 			ReadinessGates: []v1.PodReadinessGate{
 				{
@@ -312,6 +332,19 @@ func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name s
 		containers = append(containers, *cont)
 	}
 	pod.Spec.Containers = containers
+	for _, v := range application.Spec.Volumes {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, *v.DeepCopy())
+	}
+	pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, application.Spec.ImagePullSecrets...)
+	for k, v := range application.Spec.NodeSelector {
+		pod.Spec.NodeSelector[k] = v
+	}
+	if application.Spec.Affinity != nil {
+		pod.Spec.Affinity = application.Spec.Affinity.DeepCopy()
+	}
+	for _, v := range application.Spec.Tolerations {
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, *v.DeepCopy())
+	}
 	if standby {
 		pod.Annotations[fornaxv1.AnnotationFornaxCoreHibernatePod] = "hibernate"
 	}
@@ -320,18 +353,27 @@ func (am *ApplicationManager) getPodApplicationPodTemplate(uid uuid.UUID, name s
 		pod.Annotations[fornaxv1.AnnotationFornaxCoreSessionServicePod] = "sessionservicepod"
 	}
 
+	if sessionPending {
+		pod.Annotations[fornaxv1.AnnotationFornaxCoreSessionPendingPod] = "sessionpendingpod"
+	}
+
 	return pod
 }
 
-// given a list pods, pick up which can be deleted with less cost, priority is
+// given a list pods of a revision, pick up which can be deleted with less cost, priority is
 // 1, pods not find in podManager
 // 2, pods still in pending state
 // 3, idle pods
-func (am *ApplicationManager) getPodsToBeDelete(pool *ApplicationPool, numOfDesiredDelete int) []*ApplicationPod {
+func (am *ApplicationManager) getPodsToBeDelete(pool *ApplicationPool, numOfDesiredDelete int, revision string) []*ApplicationPod {
 	podsToDelete := []*ApplicationPod{}
 	candidates := 0
 
-	pendingPods := pool.podListOfState(PodStatePending)
+	pendingPods := []*ApplicationPod{}
+	for _, p := range pool.podListOfState(PodStatePending) {
+		if p.revision == revision {
+			pendingPods = append(pendingPods, p)
+		}
+	}
 	// add pod not yet scheduled
 	for _, p := range pendingPods {
 		pod := am.podManager.FindPod(p.podName)
@@ -369,7 +411,12 @@ func (am *ApplicationManager) getPodsToBeDelete(pool *ApplicationPool, numOfDesi
 	}
 
 	// add pod status is unknown from running idle pods
-	idlePods := pool.podListOfState(PodStateIdle)
+	idlePods := []*ApplicationPod{}
+	for _, p := range pool.podListOfState(PodStateIdle) {
+		if p.revision == revision {
+			idlePods = append(idlePods, p)
+		}
+	}
 	for _, p := range idlePods {
 		pod := am.podManager.FindPod(p.podName)
 		if pod == nil || pod.Status.Phase == v1.PodUnknown {
@@ -396,10 +443,11 @@ func (am *ApplicationManager) getPodsToBeDelete(pool *ApplicationPool, numOfDesi
 }
 
 // deployApplicationPods create pods when desiredAddition > 0, and delete pods when desiredAddition < 0
-// when create pods, it create active pods or hibernate pods according application spec's usingNodeSessionService attr
-// when delete pods, it pickup pending pods and running pods which does not have session yet
+// when create pods, it create active pods or hibernate pods according application spec's usingNodeSessionService attr,
+// first numOfSessionPendingPods pods are created for pending sessions, they are scheduled ahead of idle buffer pods
+// when delete pods, it pickup pending pods and running pods of current revision which does not have session yet
 // keep standby pods during deletion to reduce memory usage on node
-func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, application *fornaxv1.Application, desiredAddition int) error {
+func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, application *fornaxv1.Application, revision string, desiredAddition, numOfSessionPendingPods int) error {
 	var err error
 
 	applicationBurst := util.ApplicationScalingBurst(application)
@@ -413,7 +461,7 @@ func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, appli
 		createErrors := []error{}
 		standby := !application.Spec.UsingNodeSessionService
 		for i := 0; i < desiredAddition; i++ {
-			pod, err := am.createApplicationPod(application, standby)
+			pod, err := am.createApplicationPod(application, standby, i < numOfSessionPendingPods)
 			if err != nil {
 				klog.ErrorS(err, "Create pod failed", "application", pool.appName)
 				if apierrors.HasStatusCause(err, v1.NamespaceTerminatingCause) {
@@ -423,6 +471,7 @@ func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, appli
 				continue
 			}
 			pool.addOrUpdatePod(util.Name(pod), PodStatePending, []string{})
+			pool.setPodRevision(util.Name(pod), revision)
 			createdPods = append(createdPods, pod)
 		}
 
@@ -439,7 +488,7 @@ func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, appli
 
 		// Choose which Pods to delete, preferring those in earlier phases of startup.
 		deleteErrors := []error{}
-		podsToDelete := am.getPodsToBeDelete(pool, desiredSubstraction)
+		podsToDelete := am.getPodsToBeDelete(pool, desiredSubstraction, revision)
 		for _, ap := range podsToDelete {
 			err := am.deleteApplicationPod(pool, ap.podName)
 			if err != nil {
@@ -456,6 +505,135 @@ func (am *ApplicationManager) deployApplicationPods(pool *ApplicationPool, appli
 	return nil
 }
 
+// GetPodConfig return application config data of a pod as a ConfigMap named after application and FornaxSecrets referred by pod,
+// node agent use them to populate configMap and secret volumes and environment variables of pod containers
+func (am *ApplicationManager) GetPodConfig(pod *v1.Pod) (*v1.ConfigMap, []*v1.Secret, error) {
+	applicationLabel, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreApplication]
+	if !found {
+		return nil, nil, nil
+	}
+	application, err := factory.GetApplicationCache(am.applicationStore, applicationLabel)
+	if err != nil {
+		return nil, nil, err
+	}
+	if application == nil {
+		return nil, nil, fmt.Errorf("application %s of pod %s not found", applicationLabel, util.Name(pod))
+	}
+
+	configMap := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      application.Name,
+			Namespace: application.Namespace,
+			UID:       application.UID,
+		},
+		Data: map[string]string{},
+	}
+	for k, v := range application.Spec.ConfigData {
+		configMap.Data[k] = v
+	}
+	secrets, err := am.getPodSecrets(pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	return configMap, secrets, nil
+}
+
+// getPodSecrets return FornaxSecrets referred by pod secret volumes and container env, secret which does not exist is skipped,
+// node agent fail pod if a secret is not optional
+func (am *ApplicationManager) getPodSecrets(pod *v1.Pod) ([]*v1.Secret, error) {
+	secrets := []*v1.Secret{}
+	names := map[string]bool{}
+	for _, name := range podSecretNames(pod) {
+		if len(name) == 0 || names[name] {
+			continue
+		}
+		names[name] = true
+		secretName := fmt.Sprintf("%s/%s", pod.Namespace, name)
+		secret, err := factory.GetFornaxSecretCache(am.secretStore, secretName)
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil {
+			klog.Warningf("Secret %s of pod %s does not exist", secretName, util.Name(pod))
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// podSecretNames return names of secrets referred by pod secret volumes, projected secrets and container env
+func podSecretNames(pod *v1.Pod) []string {
+	names := []string{}
+	for _, volume := range pod.Spec.Volumes {
+		switch {
+		case volume.Secret != nil:
+			names = append(names, volume.Secret.SecretName)
+		case volume.Projected != nil:
+			for _, source := range volume.Projected.Sources {
+				if source.Secret != nil {
+					names = append(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+	containers := append(append([]v1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, container := range containers {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				names = append(names, envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				names = append(names, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	return names
+}
+
+// GetPodImagePullSecrets return FornaxSecrets referred by pod image pull secrets, secret which does not exist is skipped,
+// image pull will fail later if image registry require it
+func (am *ApplicationManager) GetPodImagePullSecrets(pod *v1.Pod) ([]*v1.Secret, error) {
+	secrets := []*v1.Secret{}
+	for _, ref := range pod.Spec.ImagePullSecrets {
+		if len(ref.Name) == 0 {
+			continue
+		}
+		secretName := fmt.Sprintf("%s/%s", pod.Namespace, ref.Name)
+		secret, err := factory.GetFornaxSecretCache(am.secretStore, secretName)
+		if err != nil {
+			return nil, err
+		}
+		if secret == nil {
+			klog.Warningf("Image pull secret %s of pod %s does not exist, image pull may fail", secretName, util.Name(pod))
+			continue
+		}
+		if secret.Type != v1.SecretTypeDockerConfigJson && secret.Type != v1.SecretTypeDockercfg {
+			klog.Warningf("Image pull secret %s of pod %s has unsupported type %s, skip it", secretName, util.Name(pod), secret.Type)
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets, nil
+}
+
+// PodHasAssignedSessions return true if any session is assigned to pod in application pool,
+// including starting session which node has not reported yet, pod scheduler use it to avoid preempting such pod
+func (am *ApplicationManager) PodHasAssignedSessions(pod *v1.Pod) bool {
+	applicationLabel, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreApplication]
+	if !found {
+		return false
+	}
+	pool := am.getApplicationPool(applicationLabel)
+	if pool == nil {
+		return false
+	}
+	return len(pool.getPodSessions(util.Name(pod))) > 0
+}
+
 // getPodApplicationKey returns Application Key of pod using LabelFornaxCoreApplication
 func (am *ApplicationManager) getPodApplicationKey(pod *v1.Pod) (string, error) {
 	if applicationLabel, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreApplication]; !found {
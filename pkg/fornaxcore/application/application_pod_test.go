@@ -0,0 +1,103 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPodSecrets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretStore := factory.NewFornaxSecretStorage(ctx)
+	for _, name := range []string{"db", "api", "env"} {
+		secret := &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+			Data:       map[string][]byte{"key": []byte(name)},
+		}
+		key := fmt.Sprintf("%s/test/%s", fornaxk8sv1.FornaxSecretGrvKey, name)
+		if err := secretStore.Create(ctx, key, secret, &v1.Secret{}, 0); err != nil {
+			t.Fatalf("failed to create secret %s, %v", name, err)
+		}
+	}
+	am := &ApplicationManager{secretStore: secretStore}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod1"},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{Name: "db", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "db"}}},
+				{Name: "all", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+					{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "api"}}},
+					{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "db"}}},
+				}}}},
+				{Name: "missing", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "missing"}}},
+			},
+			Containers: []v1.Container{{
+				Name: "app",
+				Env: []v1.EnvVar{{Name: "KEY", ValueFrom: &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: "env"},
+					Key:                  "key",
+				}}}},
+			}},
+		},
+	}
+	secrets, err := am.getPodSecrets(pod)
+	if err != nil {
+		t.Fatalf("failed to get pod secrets, %v", err)
+	}
+	names := []string{}
+	for _, secret := range secrets {
+		names = append(names, secret.Name)
+	}
+	if fmt.Sprint(names) != "[db api env]" {
+		t.Errorf("expect referred secrets delivered once and missing one skipped, got %v", names)
+	}
+
+	// secret in other namespace is not visible to pod
+	pod.Namespace = "other"
+	if secrets, err = am.getPodSecrets(pod); err != nil || len(secrets) != 0 {
+		t.Errorf("expect no secret from other namespace, got %v, %v", secrets, err)
+	}
+}
+
+func TestPodHasAssignedSessions(t *testing.T) {
+	am := &ApplicationManager{applicationPools: map[string]*ApplicationPool{}}
+	pool := am.getOrCreateApplicationPool("test/app")
+	pool.addOrUpdatePod("test/pod", PodStateIdle, []string{})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod", Labels: map[string]string{fornaxv1.LabelFornaxCoreApplication: "test/app"}}}
+	if am.PodHasAssignedSessions(pod) {
+		t.Errorf("expect idle pod does not have sessions")
+	}
+
+	// session is starting on pod, node does not report it yet
+	session := newTestOpenSession("session", 0, 0, 0, nil)
+	session.Status = fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusStarting, PodReference: &v1.LocalObjectReference{Name: "test/pod"}}
+	pool.addSession("test/session", session)
+	if !am.PodHasAssignedSessions(pod) {
+		t.Errorf("expect pod has starting session")
+	}
+}
@@ -62,10 +62,24 @@ func (pool *ApplicationPool) getPodSessions(podName string) []*ApplicationSessio
 	return sessions
 }
 
+// getPod return a copy of pod, pods are updated in place with pool lock held
 func (pool *ApplicationPool) getPod(podName string) *ApplicationPod {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
-	return pool._getPodNoLock(podName)
+	p := pool._getPodNoLock(podName)
+	if p == nil {
+		return nil
+	}
+	sessions := make(map[string]bool, len(p.sessions))
+	for k, v := range p.sessions {
+		sessions[k] = v
+	}
+	return &ApplicationPod{
+		podName:  p.podName,
+		state:    p.state,
+		sessions: sessions,
+		revision: p.revision,
+	}
 }
 
 func (pool *ApplicationPool) _getPodNoLock(podName string) *ApplicationPod {
@@ -131,6 +145,15 @@ func (pool *ApplicationPool) _addOrUpdatePodNoLock(podName string, podNewState A
 	return p
 }
 
+// setPodRevision record application revision of a pod in pool, revision is unknown for a pod added from its session
+func (pool *ApplicationPool) setPodRevision(podName string, revision string) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if p := pool._getPodNoLock(podName); p != nil && len(revision) > 0 {
+		p.revision = revision
+	}
+}
+
 func (pool *ApplicationPool) deletePod(podName string) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
@@ -139,17 +162,39 @@ func (pool *ApplicationPool) deletePod(podName string) {
 	}
 }
 
-func (pool *ApplicationPool) getSomeIdlePods(num int) []*ApplicationPod {
+// getSessionSlots return pods of a revision which can accept more sessions, a pod is repeated once per free session slot,
+// partially used allocated pods are returned before idle pods to pack sessions onto fewer instances
+func (pool *ApplicationPool) getSessionSlots(sessionsPerInstance, num int, revision string) []*ApplicationPod {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
-	pods := []*ApplicationPod{}
-	for _, v := range pool.podsByState[PodStateIdle] {
-		if len(pods) == num {
-			break
+	slots := []*ApplicationPod{}
+	for _, state := range []ApplicationPodState{PodStateAllocated, PodStateIdle} {
+		for _, v := range pool.podsByState[state] {
+			if v.revision != revision {
+				continue
+			}
+			for i := len(v.sessions); i < sessionsPerInstance; i++ {
+				if len(slots) == num {
+					return slots
+				}
+				slots = append(slots, v)
+			}
 		}
-		pods = append(pods, v)
 	}
-	return pods
+	return slots
+}
+
+// freeSessionSlotsOfAllocatedPods return how many more sessions can be assigned to allocated pods of a revision
+func (pool *ApplicationPool) freeSessionSlotsOfAllocatedPods(sessionsPerInstance int, revision string) int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	slots := 0
+	for _, v := range pool.podsByState[PodStateAllocated] {
+		if v.revision == revision && len(v.sessions) < sessionsPerInstance {
+			slots += sessionsPerInstance - len(v.sessions)
+		}
+	}
+	return slots
 }
 
 func (pool *ApplicationPool) activePodNums() (occupiedPods, pendingPods, idlePods int) {
@@ -161,6 +206,34 @@ func (pool *ApplicationPool) activePodNums() (occupiedPods, pendingPods, idlePod
 	return occupiedPods, pendingPods, idlePods
 }
 
+func (pool *ApplicationPool) activePodNumsOfRevision(revision string) (occupiedPods, pendingPods, idlePods int) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	count := func(state ApplicationPodState) int {
+		num := 0
+		for _, v := range pool.podsByState[state] {
+			if v.revision == revision {
+				num += 1
+			}
+		}
+		return num
+	}
+	return count(PodStateAllocated), count(PodStatePending), count(PodStateIdle)
+}
+
+// podListOfOldRevision return pods of a state which are not created from current revision
+func (pool *ApplicationPool) podListOfOldRevision(state ApplicationPodState, revision string) []*ApplicationPod {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	pods := []*ApplicationPod{}
+	for _, p := range pool.podsByState[state] {
+		if p.revision != revision {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
+
 func (pool *ApplicationPool) podLength() int {
 	pool.mu.RLock()
 	defer pool.mu.RUnlock()
@@ -284,7 +357,12 @@ func (pool *ApplicationPool) addSession(sessionName string, session *fornaxv1.Ap
 	}
 	if session.Status.PodReference != nil {
 		podName := session.Status.PodReference.Name
-		pool._addOrUpdatePodNoLock(podName, PodStateAllocated, []string{sessionName})
+		podState := PodStateAllocated
+		if p := pool._getPodNoLock(podName); p != nil && p.state == PodStateDeleting {
+			// a deleting pod, e.g. being evacuated, still track its sessions, but should not be used again
+			podState = PodStateDeleting
+		}
+		pool._addOrUpdatePodNoLock(podName, podState, []string{sessionName})
 	}
 }
 
@@ -341,12 +419,7 @@ func (pool *ApplicationPool) getNonRunningSessions() (pendingSessions, deletingS
 	defer pool.mu.RUnlock()
 
 	for _, s := range pool.sessions[SessionStatePending] {
-		timeoutDuration := DefaultSessionOpenTimeoutDuration
-		if s.session.Spec.OpenTimeoutSeconds > 0 {
-			timeoutDuration = time.Duration(s.session.Spec.OpenTimeoutSeconds) * time.Second
-		}
-		pendingTimeoutTimeStamp := time.Now().Add(-1 * timeoutDuration)
-		if s.session.CreationTimestamp.Time.Before(pendingTimeoutTimeStamp) {
+		if sessionOpenTimedOut(s.session) {
 			timeoutSessions = append(timeoutSessions, s)
 		} else {
 			pendingSessions = append(pendingSessions, s)
@@ -354,12 +427,7 @@ func (pool *ApplicationPool) getNonRunningSessions() (pendingSessions, deletingS
 	}
 
 	for _, s := range pool.sessions[SessionStateStarting] {
-		timeoutDuration := DefaultSessionOpenTimeoutDuration
-		if s.session.Spec.OpenTimeoutSeconds > 0 {
-			timeoutDuration = time.Duration(s.session.Spec.OpenTimeoutSeconds) * time.Second
-		}
-		pendingTimeoutTimeStamp := time.Now().Add(-1 * timeoutDuration)
-		if s.session.CreationTimestamp.Time.Before(pendingTimeoutTimeStamp) {
+		if sessionOpenTimedOut(s.session) {
 			timeoutSessions = append(timeoutSessions, s)
 		}
 	}
@@ -371,6 +439,92 @@ func (pool *ApplicationPool) getNonRunningSessions() (pendingSessions, deletingS
 	return pendingSessions, deletingSessions, timeoutSessions
 }
 
+// getExpiredSessions return running sessions which are idle longer than idle timeout or open longer than max lifetime,
+// and the earliest time when a running session will expire, zero time if no running session will expire
+func (pool *ApplicationPool) getExpiredSessions() (expiredSessions []*ApplicationSession, nextExpiration time.Time) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	now := time.Now()
+	for _, s := range pool.sessions[SessionStateRunning] {
+		if s.session.DeletionTimestamp != nil {
+			continue
+		}
+		expiration, _ := sessionExpiration(s.session)
+		if expiration.IsZero() {
+			continue
+		}
+		if !expiration.After(now) {
+			expiredSessions = append(expiredSessions, s)
+		} else if nextExpiration.IsZero() || expiration.Before(nextExpiration) {
+			nextExpiration = expiration
+		}
+	}
+	return expiredSessions, nextExpiration
+}
+
+type sessionCreationCount struct {
+	second int64
+	count  int
+}
+
+// recordSessionCreation count a session created at a time, sessions created before max session rate window are not counted,
+// sessions reloaded after fornaxcore restart are counted using their creation timestamp
+func (pool *ApplicationPool) recordSessionCreation(creationTime time.Time) {
+	now := time.Now()
+	if creationTime.IsZero() || creationTime.After(now) {
+		creationTime = now
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool._pruneSessionCreationsNoLock(now, fornaxv1.MaxSessionRateWindowSeconds*time.Second)
+	second := creationTime.Unix()
+	if second <= now.Unix()-fornaxv1.MaxSessionRateWindowSeconds {
+		return
+	}
+
+	// creations usually come in time order, find bucket from newest
+	i := len(pool.sessionCreations)
+	for i > 0 && pool.sessionCreations[i-1].second > second {
+		i--
+	}
+	if i > 0 && pool.sessionCreations[i-1].second == second {
+		pool.sessionCreations[i-1].count++
+		return
+	}
+	pool.sessionCreations = append(pool.sessionCreations, sessionCreationCount{})
+	copy(pool.sessionCreations[i+1:], pool.sessionCreations[i:])
+	pool.sessionCreations[i] = sessionCreationCount{second: second, count: 1}
+}
+
+func (pool *ApplicationPool) _pruneSessionCreationsNoLock(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window).Unix()
+	i := 0
+	for i < len(pool.sessionCreations) && pool.sessionCreations[i].second <= cutoff {
+		i++
+	}
+	pool.sessionCreations = pool.sessionCreations[i:]
+}
+
+// sessionCreationRate return sessions created per second in a sliding window before now,
+// and when oldest counted session fall out of window, it's zero if no session is counted
+func (pool *ApplicationPool) sessionCreationRate(now time.Time, window time.Duration) (rate float64, nextChange time.Time) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	cutoff := now.Add(-window).Unix()
+	count := 0
+	for _, c := range pool.sessionCreations {
+		if c.second <= cutoff || c.second > now.Unix() {
+			continue
+		}
+		if count == 0 {
+			nextChange = time.Unix(c.second, 0).Add(window)
+		}
+		count += c.count
+	}
+	return float64(count) / window.Seconds(), nextChange
+}
+
 // add active session into application's session pool and delete terminal session from pool
 // add session/delete session will update pod state according pod's session usage
 func updateSessionPool(pool *ApplicationPool, session *fornaxv1.ApplicationSession) {
@@ -389,3 +543,17 @@ func getSessionApplicationKey(session *fornaxv1.ApplicationSession) string {
 	namespace := session.Namespace
 	return fmt.Sprintf("%s/%s", namespace, applicationName)
 }
+
+// sessionOpenTimedOut check if a pending or starting session failed to open in its open timeout,
+// timeout is counted from creation time, or from when it was requeued as pending if session was evacuated
+func sessionOpenTimedOut(session *fornaxv1.ApplicationSession) bool {
+	timeoutDuration := DefaultSessionOpenTimeoutDuration
+	if session.Spec.OpenTimeoutSeconds > 0 {
+		timeoutDuration = time.Duration(session.Spec.OpenTimeoutSeconds) * time.Second
+	}
+	pendingSince := session.CreationTimestamp.Time
+	if session.Status.PendingSince != nil {
+		pendingSince = session.Status.PendingSince.Time
+	}
+	return pendingSince.Before(time.Now().Add(-1 * timeoutDuration))
+}
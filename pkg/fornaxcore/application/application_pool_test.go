@@ -0,0 +1,92 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGetSessionSlots(t *testing.T) {
+	pool := NewApplicationPool("test/app")
+	// a partially used allocated pod, an unused allocated pod, a full allocated pod and an idle pod of current revision
+	pool.addOrUpdatePod("allocated-partial", PodStateAllocated, []string{"test/s1", "test/s2"})
+	pool.addOrUpdatePod("allocated-empty", PodStateAllocated, []string{})
+	pool.addOrUpdatePod("allocated-full", PodStateAllocated, []string{"test/s3", "test/s4", "test/s5"})
+	pool.addOrUpdatePod("idle", PodStateIdle, []string{})
+	for _, name := range []string{"allocated-partial", "allocated-empty", "allocated-full", "idle"} {
+		pool.setPodRevision(name, "current")
+	}
+	pool.addOrUpdatePod("allocated-old", PodStateAllocated, []string{})
+	pool.setPodRevision("allocated-old", "old")
+
+	tests := []struct {
+		name                string
+		sessionsPerInstance int
+		num                 int
+		expect              map[string]int
+	}{
+		{
+			name:                "free slots of allocated pods are used first",
+			sessionsPerInstance: 3,
+			num:                 4,
+			expect:              map[string]int{"allocated-partial": 1, "allocated-empty": 3},
+		},
+		{
+			name:                "idle pod is used after allocated pods are packed",
+			sessionsPerInstance: 3,
+			num:                 5,
+			expect:              map[string]int{"allocated-partial": 1, "allocated-empty": 3, "idle": 1},
+		},
+		{
+			name:                "all free slots of current revision",
+			sessionsPerInstance: 3,
+			num:                 10,
+			expect:              map[string]int{"allocated-partial": 1, "allocated-empty": 3, "idle": 3},
+		},
+		{
+			name:                "more sessions per instance",
+			sessionsPerInstance: 4,
+			num:                 20,
+			expect:              map[string]int{"allocated-partial": 2, "allocated-empty": 4, "allocated-full": 1, "idle": 4},
+		},
+		{
+			name:                "single session per instance",
+			sessionsPerInstance: 1,
+			num:                 10,
+			expect:              map[string]int{"allocated-empty": 1, "idle": 1},
+		},
+	}
+	for _, test := range tests {
+		slots := pool.getSessionSlots(test.sessionsPerInstance, test.num, "current")
+		got := map[string]int{}
+		for _, v := range slots {
+			got[v.podName] += 1
+		}
+		if fmt.Sprint(got) != fmt.Sprint(test.expect) {
+			t.Errorf("%s: expect session slots %v, got %v", test.name, test.expect, got)
+		}
+	}
+
+	// only the number of slots is limited when allocated pods have more free slots
+	if slots := pool.getSessionSlots(3, 2, "current"); len(slots) != 2 || slots[0].state != PodStateAllocated || slots[1].state != PodStateAllocated {
+		t.Errorf("expect 2 session slots of allocated pods, got %d", len(slots))
+	}
+	if slots := pool.freeSessionSlotsOfAllocatedPods(3, "current"); slots != 4 {
+		t.Errorf("expect 4 free session slots of allocated pods, got %d", slots)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/klog/v2"
+)
+
+// rollingUpdateApplicationPods replace pending and idle pods of old revisions with pods of current revision,
+// allocated pods of old revisions do not get new sessions, they become idle and are replaced when their sessions are closed.
+// max surge limit how many pending and idle pods can exceed desired idle pods, pods needed by pending sessions are not limited,
+// max unavailable limit how many idle pods can be missing from desired idle pods when old idle pods are deleted.
+// it return how many pods of current revision are allowed to be created in this sync
+func (am *ApplicationManager) rollingUpdateApplicationPods(pool *ApplicationPool, application *fornaxv1.Application, revision string, numOfDesiredUnAllocatedPod, desiredAddition, numOfSessionPendingPods int) (int, error) {
+	oldPendingPods := pool.podListOfOldRevision(PodStatePending, revision)
+	oldIdlePods := pool.podListOfOldRevision(PodStateIdle, revision)
+	if len(oldPendingPods) == 0 && len(oldIdlePods) == 0 {
+		return desiredAddition, nil
+	}
+
+	_, numOfPendingPod, numOfIdlePod := pool.activePodNumsOfRevision(revision)
+	maxSurge, maxUnavailable := util.ApplicationMaxSurgeAndUnavailable(application, numOfDesiredUnAllocatedPod)
+	if desiredAddition > 0 {
+		allowedAddition := numOfDesiredUnAllocatedPod + maxSurge - numOfPendingPod - numOfIdlePod - len(oldPendingPods) - len(oldIdlePods)
+		if allowedAddition < numOfSessionPendingPods {
+			allowedAddition = numOfSessionPendingPods
+		}
+		if allowedAddition < 0 {
+			allowedAddition = 0
+		}
+		if desiredAddition > allowedAddition {
+			desiredAddition = allowedAddition
+		}
+	}
+
+	// old pending pods are not available yet, they are deleted firstly,
+	// old idle pods are deleted as long as idle pods are not less than desired idle pods minus max unavailable
+	podsToDelete := oldPendingPods
+	numOfDeletableIdlePod := numOfIdlePod + len(oldIdlePods) - (numOfDesiredUnAllocatedPod - maxUnavailable)
+	for i := 0; i < numOfDeletableIdlePod && i < len(oldIdlePods); i++ {
+		podsToDelete = append(podsToDelete, oldIdlePods[i])
+	}
+	if burst := util.ApplicationScalingBurst(application); len(podsToDelete) > burst {
+		podsToDelete = podsToDelete[:burst]
+	}
+
+	klog.InfoS("Rolling update application pods", "application", pool.appName, "revision", revision, "old-pending-pods", len(oldPendingPods), "old-idle-pods", len(oldIdlePods), "delete", len(podsToDelete), "addition", desiredAddition)
+	deleteErrors := []error{}
+	for _, ap := range podsToDelete {
+		if err := am.deleteApplicationPod(pool, ap.podName); err != nil {
+			deleteErrors = append(deleteErrors, err)
+		}
+	}
+
+	return desiredAddition, errors.NewAggregate(deleteErrors)
+}
+
+// calculateRevisions record current application template as newest revision, a revision rolled back to is moved to newest with a new number,
+// oldest revisions exceeding revision history limit are removed
+func (am *ApplicationManager) calculateRevisions(application *fornaxv1.Application, revision string) (revisions []fornaxv1.ApplicationRevision, currentRevision int64) {
+	if application.Status.CurrentRevisionHash == revision && len(application.Status.Revisions) > 0 {
+		return application.Status.Revisions, application.Status.CurrentRevision
+	}
+
+	lastRevision := int64(0)
+	for _, r := range application.Status.Revisions {
+		if r.Revision > lastRevision {
+			lastRevision = r.Revision
+		}
+		if r.Hash != revision {
+			revisions = append(revisions, *r.DeepCopy())
+		}
+	}
+	if limit := util.ApplicationRevisionHistoryLimit(application); len(revisions) > limit {
+		revisions = revisions[len(revisions)-limit:]
+	}
+	current := fornaxv1.ApplicationRevision{
+		Revision:          lastRevision + 1,
+		Hash:              revision,
+		Template:          *application.Spec.Template(),
+		CreationTimestamp: metav1.Now(),
+	}
+	if len(application.Status.CurrentRevisionHash) > 0 {
+		am.eventRecorder.Eventf(application, v1.EventTypeNormal, "RollingUpdate", "Rolling update application instances from revision %d to revision %d", application.Status.CurrentRevision, current.Revision)
+	}
+
+	return append(revisions, current), current.Revision
+}
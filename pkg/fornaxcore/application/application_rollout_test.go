@@ -0,0 +1,159 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type fakePodManager struct {
+	ie.PodManagerInterface
+	terminated []string
+}
+
+func (pm *fakePodManager) TerminatePod(podName string) error {
+	pm.terminated = append(pm.terminated, podName)
+	return nil
+}
+
+func (pm *fakePodManager) FindPod(podName string) *v1.Pod {
+	return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podName}}
+}
+
+func newTestApplication(image string) *fornaxv1.Application {
+	return &fornaxv1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+		Spec: fornaxv1.ApplicationSpec{
+			Containers: []v1.Container{{Name: "app", Image: image}},
+			ScalingPolicy: fornaxv1.ScalingPolicy{
+				MaximumInstance: 10,
+				Burst:           10,
+			},
+		},
+	}
+}
+
+func TestRollingUpdateApplicationPods(t *testing.T) {
+	pm := &fakePodManager{}
+	am := &ApplicationManager{podManager: pm, eventRecorder: record.NewFakeRecorder(10)}
+	application := newTestApplication("app:v2")
+	revision := util.ApplicationTemplateHash(application)
+	pool := NewApplicationPool("test/app")
+	for i := 0; i < 4; i++ {
+		pool.addOrUpdatePod(fmt.Sprintf("old-%d", i), PodStateIdle, []string{})
+		pool.setPodRevision(fmt.Sprintf("old-%d", i), "old")
+	}
+	pool.addOrUpdatePod("old-allocated", PodStateAllocated, []string{"test/session"})
+	pool.setPodRevision("old-allocated", "old")
+
+	// 4 desired idle pods, default 25% surge allow one more pod, default 25% unavailable allow one old idle pod deleted
+	addition, err := am.rollingUpdateApplicationPods(pool, application, revision, 4, 4, 0)
+	if err != nil || addition != 1 {
+		t.Fatalf("expect one pod of current revision created, got %d, %v", addition, err)
+	}
+	if len(pm.terminated) != 1 {
+		t.Fatalf("expect one old idle pod deleted, got %v", pm.terminated)
+	}
+
+	// pods needed by pending sessions are not limited by surge
+	pm.terminated = nil
+	addition, _ = am.rollingUpdateApplicationPods(pool, application, revision, 4, 4, 3)
+	if addition != 3 {
+		t.Errorf("expect pods for pending sessions created, got %d", addition)
+	}
+	if len(pm.terminated) != 0 {
+		t.Errorf("expect no more old idle pods deleted until new pods are idle, got %v", pm.terminated)
+	}
+
+	// new sessions only go to pods of current revision, allocated old pod is not replaced
+	if slots := pool.getSessionSlots(1, 10, revision); len(slots) != 0 {
+		t.Errorf("expect no session slots of old revision pods, got %d", len(slots))
+	}
+	for i := 0; i < 4; i++ {
+		pool.addOrUpdatePod(fmt.Sprintf("new-%d", i), PodStateIdle, []string{})
+		pool.setPodRevision(fmt.Sprintf("new-%d", i), revision)
+	}
+	if slots := pool.getSessionSlots(1, 10, revision); len(slots) != 4 {
+		t.Errorf("expect session slots of current revision pods, got %d", len(slots))
+	}
+	pm.terminated = nil
+	addition, _ = am.rollingUpdateApplicationPods(pool, application, revision, 4, 0, 0)
+	if addition != 0 || len(pm.terminated) != 3 {
+		t.Errorf("expect remaining old idle pods deleted, got %v", pm.terminated)
+	}
+	if p := pool.getPod("old-allocated"); p == nil || p.state != PodStateAllocated {
+		t.Errorf("expect allocated pod of old revision kept")
+	}
+}
+
+func TestApplicationRevisionAndRollback(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	am := &ApplicationManager{eventRecorder: recorder}
+
+	application := newTestApplication("app:v1")
+	v1Hash := util.ApplicationTemplateHash(application)
+	application.Status.Revisions, application.Status.CurrentRevision = am.calculateRevisions(application, v1Hash)
+	application.Status.CurrentRevisionHash = v1Hash
+	if application.Status.CurrentRevision != 1 || len(recorder.Events) != 0 {
+		t.Fatalf("expect first revision without rolling update, got %d", application.Status.CurrentRevision)
+	}
+
+	application.Spec.Containers[0].Image = "app:v2"
+	v2Hash := util.ApplicationTemplateHash(application)
+	if v2Hash == v1Hash {
+		t.Fatalf("expect template hash changed with container image")
+	}
+	application.Status.Revisions, application.Status.CurrentRevision = am.calculateRevisions(application, v2Hash)
+	application.Status.CurrentRevisionHash = v2Hash
+	if application.Status.CurrentRevision != 2 || len(application.Status.Revisions) != 2 || len(recorder.Events) != 1 {
+		t.Fatalf("expect second revision recorded with rolling update event, got %v", application.Status.Revisions)
+	}
+
+	// roll back to revision 1, it become revision 3
+	application.Spec.RollbackTo = &fornaxv1.RollbackConfig{Revision: 1}
+	application.PrepareForUpdate(context.Background(), application.DeepCopy())
+	if application.Spec.RollbackTo != nil || application.Spec.Containers[0].Image != "app:v1" {
+		t.Fatalf("expect template rolled back to revision 1, got %v", application.Spec.Containers)
+	}
+	if errs := application.ValidateUpdate(context.Background(), application.DeepCopy()); len(errs) != 0 {
+		t.Errorf("expect rolled back application valid, got %v", errs)
+	}
+	if util.ApplicationTemplateHash(application) != v1Hash {
+		t.Fatalf("expect template hash of revision 1")
+	}
+	application.Status.Revisions, application.Status.CurrentRevision = am.calculateRevisions(application, v1Hash)
+	if application.Status.CurrentRevision != 3 || len(application.Status.Revisions) != 2 || application.Status.Revisions[0].Hash != v2Hash {
+		t.Errorf("expect rolled back revision renumbered as newest, got %v", application.Status.Revisions)
+	}
+
+	// roll back to a unknown revision is rejected
+	application.Spec.RollbackTo = &fornaxv1.RollbackConfig{Revision: 10}
+	application.PrepareForUpdate(context.Background(), application.DeepCopy())
+	if errs := application.ValidateUpdate(context.Background(), application.DeepCopy()); len(errs) != 1 {
+		t.Errorf("expect rollback to unknown revision rejected, got %v", errs)
+	}
+}
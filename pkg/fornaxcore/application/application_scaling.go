@@ -0,0 +1,141 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"math"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/klog/v2"
+)
+
+// applicationScalingTarget is minimum instance and idle session watermarks of a application at a moment
+type applicationScalingTarget struct {
+	minimumInstance int
+
+	// scale idle pods by idle session watermarks, idle session percent policy use its own thresholds
+	byIdleSessionNum         bool
+	idleSessionLowWaterMark  int
+	idleSessionHighWaterMark int
+
+	// when target will change by time, e.g. a scheduled peak window start or end, zero if it does not change by time
+	nextChange time.Time
+}
+
+// calculateScalingTarget evaluate scaling policy of application at now,
+// schedule policy raise minimum instance in peak windows, session rate policy size idle session buffer from session creation rate
+func calculateScalingTarget(pool *ApplicationPool, application *fornaxv1.Application, now time.Time) applicationScalingTarget {
+	policy := application.Spec.ScalingPolicy
+	target := applicationScalingTarget{
+		minimumInstance: int(policy.MinimumInstance),
+	}
+
+	switch policy.ScalingPolicyType {
+	case fornaxv1.ScalingPolicyTypeSchedule:
+		minimumInstance, nextChange := scheduledMinimumInstance(policy.Schedules, policy.MinimumInstance, now)
+		target.minimumInstance = int(minimumInstance)
+		target.nextChange = nextChange
+		if policy.IdleSessionNumThreshold != nil {
+			target.byIdleSessionNum = true
+			target.idleSessionLowWaterMark = int(policy.IdleSessionNumThreshold.LowWaterMark)
+			target.idleSessionHighWaterMark = int(policy.IdleSessionNumThreshold.HighWaterMark)
+		}
+	case fornaxv1.ScalingPolicyTypeSessionRate:
+		window, coldStart, probability := sessionRateScalingParameters(policy.SessionRate)
+		rate, nextChange := pool.sessionCreationRate(now, window)
+		// idle buffer shrink only when idle sessions are twice of predicted number to avoid flapping
+		target.byIdleSessionNum = true
+		target.idleSessionLowWaterMark = predictIdleSessionNum(rate, coldStart, probability)
+		target.idleSessionHighWaterMark = 2 * target.idleSessionLowWaterMark
+		target.nextChange = nextChange
+	case fornaxv1.ScalingPolicyTypeIdleSessionNum:
+		target.byIdleSessionNum = true
+		target.idleSessionLowWaterMark = int(policy.IdleSessionNumThreshold.LowWaterMark)
+		target.idleSessionHighWaterMark = int(policy.IdleSessionNumThreshold.HighWaterMark)
+	}
+
+	return target
+}
+
+// scheduledMinimumInstance return biggest minimum instance of peak windows which now is in, a window start at a schedule time and last for its duration,
+// it also return when next window start or current window end
+func scheduledMinimumInstance(schedules []fornaxv1.ScalingSchedule, minimumInstance uint32, now time.Time) (uint32, time.Time) {
+	nextChange := time.Time{}
+	earlier := func(t time.Time) {
+		if !t.IsZero() && (nextChange.IsZero() || t.Before(nextChange)) {
+			nextChange = t
+		}
+	}
+	for _, s := range schedules {
+		schedule, err := cron.ParseStandard(s.Schedule)
+		if err != nil {
+			klog.ErrorS(err, "Invalid scaling schedule", "schedule", s.Schedule)
+			continue
+		}
+		duration := time.Duration(s.DurationSeconds) * time.Second
+		// last schedule time in (now - duration, now] means now is in window
+		if start := schedule.Next(now.Add(-duration)); !start.IsZero() && !start.After(now) {
+			if s.MinimumInstance > minimumInstance {
+				minimumInstance = s.MinimumInstance
+			}
+			earlier(start.Add(duration))
+		}
+		earlier(schedule.Next(now))
+	}
+	return minimumInstance, nextChange
+}
+
+func sessionRateScalingParameters(rateScaling *fornaxv1.SessionRateScaling) (window, coldStart time.Duration, probability float64) {
+	window = fornaxv1.DefaultSessionRateWindowSeconds * time.Second
+	coldStart = fornaxv1.DefaultSessionRateColdStartSeconds * time.Second
+	probability = fornaxv1.DefaultSessionRateColdStartProbabilityPercent / 100.0
+	if rateScaling != nil {
+		if rateScaling.WindowSeconds > 0 {
+			window = time.Duration(rateScaling.WindowSeconds) * time.Second
+		}
+		if rateScaling.ColdStartSeconds > 0 {
+			coldStart = time.Duration(rateScaling.ColdStartSeconds) * time.Second
+		}
+		if rateScaling.ColdStartProbabilityPercent > 0 {
+			probability = float64(rateScaling.ColdStartProbabilityPercent) / 100.0
+		}
+	}
+	return window, coldStart, probability
+}
+
+// predictIdleSessionNum return smallest idle session number n that sessions created during a cold start exceed n with a probability not greater than target,
+// sessions created during cold start follow a poisson distribution with mean of rate * cold start seconds
+func predictIdleSessionNum(rate float64, coldStart time.Duration, probability float64) int {
+	mean := rate * coldStart.Seconds()
+	if mean <= 0 {
+		return 0
+	}
+
+	// terms far below mean are negligible, sum pmf in log space to avoid underflow of a big mean
+	n := int(math.Max(0, math.Floor(mean-10*math.Sqrt(mean))))
+	cdf := 0.0
+	for ; ; n++ {
+		lgamma, _ := math.Lgamma(float64(n + 1))
+		cdf += math.Exp(float64(n)*math.Log(mean) - mean - lgamma)
+		if 1-cdf <= probability {
+			return n
+		}
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+)
+
+func TestPredictIdleSessionNum(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate        float64
+		coldStart   time.Duration
+		probability float64
+		expect      int
+	}{
+		{name: "no session", rate: 0, coldStart: 10 * time.Second, probability: 0.05, expect: 0},
+		// poisson mean 1, P(X > 3) = 0.019
+		{name: "one session per cold start", rate: 0.1, coldStart: 10 * time.Second, probability: 0.05, expect: 3},
+		// poisson mean 10, P(X > 15) = 0.049
+		{name: "ten sessions per cold start", rate: 1, coldStart: 10 * time.Second, probability: 0.05, expect: 15},
+		// poisson mean 10000, about mean + 1.645 * 100
+		{name: "big mean", rate: 1000, coldStart: 10 * time.Second, probability: 0.05, expect: 10165},
+	}
+	for _, test := range tests {
+		if n := predictIdleSessionNum(test.rate, test.coldStart, test.probability); n < test.expect-1 || n > test.expect+1 {
+			t.Errorf("%s: expect %d idle sessions, got %d", test.name, test.expect, n)
+		}
+	}
+}
+
+func TestScheduledMinimumInstance(t *testing.T) {
+	schedules := []fornaxv1.ScalingSchedule{
+		{Schedule: "0 9 * * *", DurationSeconds: 3600, MinimumInstance: 5},
+		{Schedule: "30 9 * * *", DurationSeconds: 600, MinimumInstance: 8},
+	}
+	day := time.Date(2022, 10, 1, 0, 0, 0, 0, time.Local)
+
+	minimum, next := scheduledMinimumInstance(schedules, 1, day.Add(8*time.Hour))
+	if minimum != 1 || !next.Equal(day.Add(9*time.Hour)) {
+		t.Errorf("expect minimum 1 until 09:00, got %d until %v", minimum, next)
+	}
+	minimum, next = scheduledMinimumInstance(schedules, 1, day.Add(9*time.Hour))
+	if minimum != 5 || !next.Equal(day.Add(9*time.Hour+30*time.Minute)) {
+		t.Errorf("expect minimum 5 until 09:30, got %d until %v", minimum, next)
+	}
+	minimum, next = scheduledMinimumInstance(schedules, 1, day.Add(9*time.Hour+35*time.Minute))
+	if minimum != 8 || !next.Equal(day.Add(9*time.Hour+40*time.Minute)) {
+		t.Errorf("expect minimum 8 until 09:40, got %d until %v", minimum, next)
+	}
+	minimum, next = scheduledMinimumInstance(schedules, 1, day.Add(10*time.Hour))
+	if minimum != 1 || !next.Equal(day.Add(33*time.Hour)) {
+		t.Errorf("expect minimum 1 until next day 09:00, got %d until %v", minimum, next)
+	}
+}
+
+func TestSessionRateScalingTarget(t *testing.T) {
+	now := time.Now()
+	pool := NewApplicationPool("test/app")
+	for i := 0; i < 60; i++ {
+		pool.recordSessionCreation(now.Add(-time.Duration(i) * time.Second))
+	}
+	// out of max window, not counted
+	pool.recordSessionCreation(now.Add(-2 * time.Hour))
+
+	application := newTestApplication("app:v1")
+	application.Spec.ScalingPolicy.ScalingPolicyType = fornaxv1.ScalingPolicyTypeSessionRate
+	application.Spec.ScalingPolicy.SessionRate = &fornaxv1.SessionRateScaling{WindowSeconds: 30}
+	target := calculateScalingTarget(pool, application, now)
+	if !target.byIdleSessionNum || target.idleSessionLowWaterMark != 15 || target.idleSessionHighWaterMark != 30 {
+		t.Errorf("expect idle session buffer of one session per second and 10 seconds cold start, got %+v", target)
+	}
+	if target.nextChange.IsZero() || target.nextChange.After(now.Add(2*time.Second)) {
+		t.Errorf("expect sync when oldest session in window fall out, got %v", target.nextChange)
+	}
+
+	am := &ApplicationManager{}
+	if desired := am.calculateDesiredIdlePods(application, target, 0, 0, 0, 0); desired != 10 {
+		t.Errorf("expect idle pods capped by maximum instance, got %d", desired)
+	}
+}
+
+func TestCalculateDesiredIdlePods(t *testing.T) {
+	am := &ApplicationManager{}
+	tests := []struct {
+		name                string
+		policy              fornaxv1.ScalingPolicy
+		target              applicationScalingTarget
+		occupiedPodNum      int
+		idlePodNum          int
+		freeOccupiedSlotNum int
+		sessionNum          int
+		expect              int
+	}{
+		{
+			name:                "free slots of occupied pods are counted as idle sessions",
+			target:              applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 4, idleSessionHighWaterMark: 8},
+			occupiedPodNum:      2,
+			freeOccupiedSlotNum: 5,
+			expect:              0,
+		},
+		{
+			name:                "slot shortage is converted to pods",
+			target:              applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 4, idleSessionHighWaterMark: 8},
+			occupiedPodNum:      2,
+			freeOccupiedSlotNum: 3,
+			expect:              1,
+		},
+		{
+			name:       "pending sessions use idle session slots",
+			target:     applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 2, idleSessionHighWaterMark: 8},
+			idlePodNum: 1,
+			sessionNum: 6,
+			expect:     2,
+		},
+		{
+			name:                "slot surplus is converted to pods",
+			target:              applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 4, idleSessionHighWaterMark: 8},
+			occupiedPodNum:      2,
+			idlePodNum:          3,
+			freeOccupiedSlotNum: 2,
+			expect:              2,
+		},
+		{
+			name:                "free slots of occupied pods can not delete more than idle pods",
+			target:              applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 0, idleSessionHighWaterMark: 1},
+			occupiedPodNum:      5,
+			idlePodNum:          1,
+			freeOccupiedSlotNum: 20,
+			expect:              0,
+		},
+		{
+			name:                "minimum instance",
+			target:              applicationScalingTarget{minimumInstance: 5, byIdleSessionNum: true, idleSessionLowWaterMark: 4, idleSessionHighWaterMark: 8},
+			occupiedPodNum:      2,
+			freeOccupiedSlotNum: 3,
+			expect:              3,
+		},
+		{
+			name:           "maximum instance",
+			policy:         fornaxv1.ScalingPolicy{MaximumInstance: 4},
+			target:         applicationScalingTarget{byIdleSessionNum: true, idleSessionLowWaterMark: 8, idleSessionHighWaterMark: 16},
+			occupiedPodNum: 3,
+			expect:         1,
+		},
+		{
+			name: "idle session percent",
+			policy: fornaxv1.ScalingPolicy{
+				MaximumInstance:             10,
+				ScalingPolicyType:           fornaxv1.ScalingPolicyTypeIdleSessionPercent,
+				IdleSessionPercentThreshold: &fornaxv1.IdelSessionPercentThreshold{LowWaterMark: 10, HighWaterMark: 50},
+			},
+			occupiedPodNum: 2,
+			idlePodNum:     2,
+			// 8 idle sessions of 8 supported sessions, more than 50%, 4 idle sessions are removed
+			expect: 1,
+		},
+	}
+	for _, test := range tests {
+		application := newTestApplication("app:v1")
+		application.Spec.SessionsPerInstance = 4
+		if test.policy.MaximumInstance != 0 {
+			application.Spec.ScalingPolicy = test.policy
+		}
+		desired := am.calculateDesiredIdlePods(application, test.target, test.occupiedPodNum, test.idlePodNum, test.freeOccupiedSlotNum, test.sessionNum)
+		if desired != test.expect {
+			t.Errorf("%s: expect %d desired idle pods, got %d", test.name, test.expect, desired)
+		}
+	}
+}
@@ -36,6 +36,7 @@ const (
 	DefaultSessionPendingTimeoutDuration = 5 * time.Second
 	DefaultSessionOpenTimeoutDuration    = 10 * time.Second
 	DefaultSessionCloseTimeoutDuration   = 60 * time.Second
+	DefaultSessionReopenGracePeriod      = 5 * time.Second
 	HouseKeepingDuration                 = 1 * time.Minute
 )
 
@@ -102,6 +103,7 @@ func (am *ApplicationManager) onApplicationSessionAddEvent(obj interface{}) {
 		return
 	} else {
 		if !util.SessionInTerminalState(session) {
+			pool.recordSessionCreation(session.CreationTimestamp.Time)
 			updateSessionPool(pool, session)
 		}
 	}
@@ -122,6 +124,10 @@ func (am *ApplicationManager) onApplicationSessionUpdateEvent(old, cur interface
 			// no meaningful change, skip
 			return
 		}
+		if v.session.Status.PodReference != nil && newCopy.Status.PodReference == nil && util.SessionIsPending(newCopy) {
+			// evacuated session is requeued, release it from old pod, and add it as a pending session again
+			pool.deleteSession(v.session)
+		}
 		updateSessionPool(pool, newCopy)
 	} else {
 		if !util.SessionInTerminalState(newCopy) {
@@ -172,7 +178,7 @@ func (ps PendingSessions) Swap(i, j int) {
 }
 
 // deployApplicationSessions group session into pending, timeout, deleting states, and
-// 1, assign pending session to idle pods and call OpenSession on choosen pod.
+// 1, assign pending session to idle pods of current revision and call OpenSession on choosen pod.
 // session status change in memory to SessionStatusStarting, session is store in node and report back,
 // if fornax core restart and lost these memory state, it rely on pod to report back.
 // 2, It cleanup timeout session which stuck in pending or starting session for more than a timeout duration.
@@ -180,21 +186,24 @@ func (ps PendingSessions) Swap(i, j int) {
 // session is changed to SessionStatusTimeout, session client need to create a new session.
 // 3, if a session is being deleted by client(aka, close session), it call node to close session session,
 // timedout and closed session are removed from application's session pool
-func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, application *fornaxv1.Application) error {
+func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, application *fornaxv1.Application, revision string) error {
 	pendingSessions, deletingSessions, timeoutSessions := pool.getNonRunningSessions()
-	// get 5 more in case some pods assigment failed
-	idlePods := pool.getSomeIdlePods(len(pendingSessions))
+	sessionSlots := pool.getSessionSlots(util.ApplicationSessionsPerInstance(application), len(pendingSessions), revision)
 	klog.InfoS("Syncing application pending session", "application", pool.appName, "#pending", len(pendingSessions), "#deleting", len(deletingSessions), "#timeout", len(timeoutSessions))
 
 	sort.Sort(PendingSessions(pendingSessions))
 	sessionErrors := []error{}
-	// 1/ assign pending sessions to idle pod
+	// 1/ assign pending sessions to free session slots of allocated pods and idle pods
 	si := 0
-	for _, ap := range idlePods {
+	failedPods := map[string]bool{}
+	for _, ap := range sessionSlots {
 		if si == len(pendingSessions) {
 			// has assigned all pending sesion to pod
 			break
 		}
+		if failedPods[ap.podName] {
+			continue
+		}
 		pod := am.podManager.FindPod(ap.podName)
 		if pod != nil {
 			// update as status and set access point of as
@@ -205,6 +214,7 @@ func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, a
 				// move to next pod, it could fail to accept other session also
 				klog.ErrorS(err, "Failed to open session on pod", "app", pool.appName, "session", as.session.Name, "pod", util.Name(pod))
 				sessionErrors = append(sessionErrors, err)
+				failedPods[ap.podName] = true
 				continue
 			} else {
 				pool.addOrUpdatePod(ap.podName, PodStateAllocated, []string{string(util.Name(as.session))})
@@ -212,11 +222,13 @@ func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, a
 			}
 		} else {
 			klog.InfoS("A idle Pod does not exist in Pod manager at all, should be deleted", "application", pool.appName, "pod", util.Name(ap.podName))
+			failedPods[ap.podName] = true
 		}
 	}
 
 	// 2, cleanup timeout session,
 	for _, v := range timeoutSessions {
+		am.recordSessionTimeoutEvent(v.session)
 		if err := am.deleteApplicationSession(pool, v); err != nil {
 			klog.ErrorS(err, "Failed to cleanup timeout session")
 			sessionErrors = append(sessionErrors, err)
@@ -232,6 +244,18 @@ func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, a
 		}
 	}
 
+	// 4, close sessions which are idle too long or exceed max lifetime, and sync again when next session expire
+	expiredSessions, nextExpiration := pool.getExpiredSessions()
+	for _, v := range expiredSessions {
+		if err := am.closeExpiredSession(pool, v); err != nil {
+			klog.ErrorS(err, "Failed to close expired session")
+			sessionErrors = append(sessionErrors, err)
+		}
+	}
+	if !nextExpiration.IsZero() {
+		am.applicationQueue.AddAfter(pool.appName, time.Until(nextExpiration))
+	}
+
 	if len(sessionErrors) > 0 {
 		return fmt.Errorf("Some sessions failed to be sync, errors=%v", sessionErrors)
 	}
@@ -239,6 +263,52 @@ func (am *ApplicationManager) deployApplicationSessions(pool *ApplicationPool, a
 	return nil
 }
 
+func (am *ApplicationManager) recordSessionTimeoutEvent(session *fornaxv1.ApplicationSession) {
+	timeoutDuration := DefaultSessionOpenTimeoutDuration
+	if session.Spec.OpenTimeoutSeconds > 0 {
+		timeoutDuration = time.Duration(session.Spec.OpenTimeoutSeconds) * time.Second
+	}
+	podName := ""
+	if session.Status.PodReference != nil {
+		podName = session.Status.PodReference.Name
+	}
+	if len(podName) == 0 {
+		am.eventRecorder.Eventf(session, v1.EventTypeWarning, "SessionTimeout", "Session was not assigned to a pod within %s", timeoutDuration)
+	} else {
+		am.eventRecorder.Eventf(session, v1.EventTypeWarning, "SessionTimeout", "Session was not open on pod %s within %s", podName, timeoutDuration)
+	}
+}
+
+// sessionExpiration return when a open session should be closed by fornaxcore and why, zero time if it never expire,
+// idle timeout is counted from when session lost its last client, max lifetime is counted from when session became available
+func sessionExpiration(session *fornaxv1.ApplicationSession) (expiration time.Time, reason string) {
+	if session.Spec.MaxLifetimeSeconds > 0 && session.Status.AvailableTime != nil {
+		expiration = session.Status.AvailableTime.Add(time.Duration(session.Spec.MaxLifetimeSeconds) * time.Second)
+		reason = fornaxv1.SessionCloseReasonLifetimeExceeded
+	}
+	if session.Spec.IdleTimeoutSeconds > 0 && session.Status.IdleSince != nil {
+		idleExpiration := session.Status.IdleSince.Add(time.Duration(session.Spec.IdleTimeoutSeconds) * time.Second)
+		if expiration.IsZero() || idleExpiration.Before(expiration) {
+			expiration = idleExpiration
+			reason = fornaxv1.SessionCloseReasonIdleTimeout
+		}
+	}
+	return expiration, reason
+}
+
+// closeExpiredSession record why session is closed in session status and close it on node
+func (am *ApplicationManager) closeExpiredSession(pool *ApplicationPool, s *ApplicationSession) error {
+	_, reason := sessionExpiration(s.session)
+	klog.InfoS("Close expired session", "session", util.Name(s.session), "reason", reason)
+	if reason == fornaxv1.SessionCloseReasonIdleTimeout {
+		am.eventRecorder.Eventf(s.session, v1.EventTypeNormal, reason, "Session did not have clients for %d seconds, close it", s.session.Spec.IdleTimeoutSeconds)
+	} else {
+		am.eventRecorder.Eventf(s.session, v1.EventTypeNormal, reason, "Session was open longer than %d seconds, close it", s.session.Spec.MaxLifetimeSeconds)
+	}
+	s.session.Status.CloseReason = reason
+	return am.deleteApplicationSession(pool, s)
+}
+
 // if session is open, close it and wait for node report back
 // if session is still in pending, change status to timeout
 // if session is not assigned or pending, just delete since it's already in a terminal state
@@ -293,14 +363,14 @@ func (am *ApplicationManager) deleteApplicationSession(pool *ApplicationPool, s
 func (am *ApplicationManager) assignSessionToPod(pool *ApplicationPool, pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
 	newSession := session.DeepCopy()
 	newSession.Status.SessionStatus = fornaxv1.SessionStatusStarting
-	for _, cont := range pod.Spec.Containers {
-		for _, port := range cont.Ports {
-			newSession.Status.AccessEndPoints = append(session.Status.AccessEndPoints, fornaxv1.AccessEndPoint{
-				Protocol:  port.Protocol,
-				IPAddress: port.HostIP,
-				Port:      port.HostPort,
-			})
+	newSession.Status.AccessEndPoints = util.PodHostPortEndPoints(pod)
+	if am.ingressManager != nil {
+		// session is accessed through ingress gateway endpoints which are forwarded to pod host ports
+		endpoints, err := am.ingressManager.AllocateIngressEndPoints(newSession, newSession.Status.AccessEndPoints)
+		if err != nil {
+			return err
 		}
+		newSession.Status.AccessEndPoints = endpoints
 	}
 	newSession.Status.PodReference = &v1.LocalObjectReference{
 		Name: util.Name(pod),
@@ -308,19 +378,58 @@ func (am *ApplicationManager) assignSessionToPod(pool *ApplicationPool, pod *v1.
 	if err := am.sessionManager.OpenSession(pod, newSession); err != nil {
 		return err
 	} else {
-		// change pool directly, no need to update storage for a transient state, and triger unnecessary sync
+		// change pool directly, no need to update storage for a transient state, and triger unnecessary sync,
+		// transition time is only kept in pool to tell if session open could be still on the way to node
+		newSession.Status.LastTransitionTime = util.NewCurrentMetaTime()
 		updateSessionPool(pool, newSession)
 		return nil
 	}
 }
 
+// ReconcilePodSessions open starting sessions of pod again if node does not report them in its full state,
+// session open could be lost when node disconnected or fornaxcore restarted before node received it,
+// sessions assigned within grace period are skipped as their session open could be still on the way to node
+func (am *ApplicationManager) ReconcilePodSessions(pod *v1.Pod, reportedSessions []*fornaxv1.ApplicationSession) {
+	applicationLabel, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreApplication]
+	if !found {
+		return
+	}
+	pool := am.getApplicationPool(applicationLabel)
+	if pool == nil {
+		return
+	}
+	reported := map[string]bool{}
+	for _, v := range reportedSessions {
+		reported[util.Name(v)] = true
+	}
+	for _, v := range pool.getPodSessions(util.Name(pod)) {
+		session := v.session
+		if session.Status.SessionStatus != fornaxv1.SessionStatusStarting || reported[util.Name(session)] {
+			continue
+		}
+		if session.Status.LastTransitionTime != nil && time.Since(session.Status.LastTransitionTime.Time) < DefaultSessionReopenGracePeriod {
+			continue
+		}
+		klog.InfoS("Open starting session again which node does not report", "application", pool.appName, "pod", util.Name(pod), "session", util.Name(session))
+		if err := am.sessionManager.OpenSession(pod, session); err != nil {
+			klog.ErrorS(err, "Failed to open session again", "application", pool.appName, "pod", util.Name(pod), "session", util.Name(session))
+		}
+	}
+}
+
 // cleanupSessionOnDeletedPod handle pod is terminated unexpectedly, e.g. node crash
 // in normal cases,session should be closed before pod is terminated and deleted.
 // It update open session to closed and pending session to timedout,
-// and does not try to call node to close session, as session does not exist at all on node when pod terminated on node
+// and does not try to call node to close session, as session does not exist at all on node when pod terminated on node,
+// session still evacuating is requeued without checkpoint to reopen it on another pod
 func (am *ApplicationManager) cleanupSessionOnDeletedPod(pool *ApplicationPool, podName string) {
 	podSessions := pool.getPodSessions(podName)
 	for _, sess := range podSessions {
+		if util.SessionIsEvacuating(sess.session) && sess.session.DeletionTimestamp == nil {
+			klog.Infof("Requeue evacuating session %s on deleted pod %s", util.Name(sess.session), podName)
+			am.sessionManager.UpdateSessionStatus(sess.session, &fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusPending, PendingSince: util.NewCurrentMetaTimeNormallized()})
+			continue
+		}
 		klog.Infof("Delete session %s on deleted pod %s", util.Name(sess.session), podName)
 		am.deleteApplicationSession(pool, sess)
 	}
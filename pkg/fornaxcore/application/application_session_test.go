@@ -0,0 +1,215 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestOpenSession(name string, idleTimeout, maxLifetime uint32, availableAgo time.Duration, idleAgo *time.Duration) *fornaxv1.ApplicationSession {
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: fornaxv1.ApplicationSessionSpec{
+			ApplicationName:    "app",
+			IdleTimeoutSeconds: idleTimeout,
+			MaxLifetimeSeconds: maxLifetime,
+		},
+		Status: fornaxv1.ApplicationSessionStatus{
+			SessionStatus: fornaxv1.SessionStatusAvailable,
+			PodReference:  &v1.LocalObjectReference{Name: "test/pod"},
+			AvailableTime: &metav1.Time{Time: time.Now().Add(-availableAgo)},
+		},
+	}
+	if idleAgo != nil {
+		session.Status.IdleSince = &metav1.Time{Time: time.Now().Add(-*idleAgo)}
+	} else {
+		session.Status.SessionStatus = fornaxv1.SessionStatusInUse
+		session.Status.ClientSessions = []v1.LocalObjectReference{{Name: "client"}}
+	}
+	return session
+}
+
+func TestGetExpiredSessions(t *testing.T) {
+	idleLong, idleShort := 2*time.Minute, 10*time.Second
+	pool := NewApplicationPool("test/app")
+	pool.addSession("test/idle", newTestOpenSession("idle", 60, 0, time.Hour, &idleLong))
+	pool.addSession("test/inuse", newTestOpenSession("inuse", 60, 0, time.Hour, nil))
+	pool.addSession("test/old", newTestOpenSession("old", 0, 1800, time.Hour, nil))
+	pool.addSession("test/new", newTestOpenSession("new", 60, 0, time.Hour, &idleShort))
+	pool.addSession("test/unlimited", newTestOpenSession("unlimited", 0, 0, time.Hour, &idleLong))
+
+	expired, nextExpiration := pool.getExpiredSessions()
+	reasons := map[string]string{}
+	for _, s := range expired {
+		_, reason := sessionExpiration(s.session)
+		reasons[s.session.Name] = reason
+	}
+	if len(reasons) != 2 || reasons["idle"] != fornaxv1.SessionCloseReasonIdleTimeout || reasons["old"] != fornaxv1.SessionCloseReasonLifetimeExceeded {
+		t.Errorf("expect idle and old sessions expired, got %v", reasons)
+	}
+	if nextExpiration.IsZero() || time.Until(nextExpiration) > 50*time.Second || time.Until(nextExpiration) < 40*time.Second {
+		t.Errorf("expect next expiration when new session idle for 60 seconds, got %v", time.Until(nextExpiration))
+	}
+}
+
+// fakeSessionManager remember session status updates and close requests
+type fakeSessionManager struct {
+	ie.SessionManagerInterface
+	statuses map[string]fornaxv1.SessionStatus
+	updated  map[string]*fornaxv1.ApplicationSessionStatus
+	closed   []string
+	opened   []string
+}
+
+func (sm *fakeSessionManager) UpdateSessionStatus(session *fornaxv1.ApplicationSession, newStatus *fornaxv1.ApplicationSessionStatus) error {
+	sm.statuses[util.Name(session)] = newStatus.SessionStatus
+	if sm.updated != nil {
+		sm.updated[util.Name(session)] = newStatus.DeepCopy()
+	}
+	return nil
+}
+
+func (sm *fakeSessionManager) OpenSession(pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
+	sm.opened = append(sm.opened, util.Name(session))
+	return nil
+}
+
+func (sm *fakeSessionManager) CloseSession(pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
+	sm.closed = append(sm.closed, util.Name(session))
+	return nil
+}
+
+func TestCleanupSessionOnDeletedPod(t *testing.T) {
+	sm := &fakeSessionManager{statuses: map[string]fornaxv1.SessionStatus{}}
+	am := &ApplicationManager{podManager: &fakePodManager{}, sessionManager: sm}
+	pool := NewApplicationPool("test/app")
+
+	evacuating := newTestOpenSession("evacuating", 0, 0, time.Hour, nil)
+	evacuating.Status.SessionStatus = fornaxv1.SessionStatusEvacuating
+	deleting := newTestOpenSession("deleting", 0, 0, time.Hour, nil)
+	deleting.Status.SessionStatus = fornaxv1.SessionStatusEvacuating
+	deleting.DeletionTimestamp = util.NewCurrentMetaTime()
+	available := newTestOpenSession("available", 0, 0, time.Hour, nil)
+	other := newTestOpenSession("other", 0, 0, time.Hour, nil)
+	other.Status.PodReference = &v1.LocalObjectReference{Name: "test/other"}
+	for _, s := range []*fornaxv1.ApplicationSession{evacuating, deleting, available, other} {
+		pool.addSession(util.Name(s), s)
+	}
+
+	am.cleanupSessionOnDeletedPod(pool, "test/pod")
+	// evacuating session lost its checkpoint with pod, it's requeued to reopen from session data
+	if sm.statuses["test/evacuating"] != fornaxv1.SessionStatusPending {
+		t.Errorf("expect evacuating session requeued as pending, got %v", sm.statuses["test/evacuating"])
+	}
+	if status, found := sm.statuses["test/deleting"]; found && status == fornaxv1.SessionStatusPending {
+		t.Errorf("expect deleting session not requeued")
+	}
+	if sm.statuses["test/available"] != fornaxv1.SessionStatusClosing || len(sm.closed) != 2 {
+		t.Errorf("expect sessions not evacuating closed, got %v, closed %v", sm.statuses, sm.closed)
+	}
+	if _, found := sm.statuses["test/other"]; found {
+		t.Errorf("expect session on other pod untouched")
+	}
+}
+
+func TestRequeuedSessionOpenTimeout(t *testing.T) {
+	sm := &fakeSessionManager{statuses: map[string]fornaxv1.SessionStatus{}, updated: map[string]*fornaxv1.ApplicationSessionStatus{}}
+	am := &ApplicationManager{podManager: &fakePodManager{}, sessionManager: sm}
+	pool := NewApplicationPool("test/app")
+
+	// session was created long before its open timeout, and evacuating when its pod is deleted
+	evacuating := newTestOpenSession("evacuating", 0, 0, time.Hour, nil)
+	evacuating.CreationTimestamp = metav1.Time{Time: time.Now().Add(-time.Hour)}
+	evacuating.Status.SessionStatus = fornaxv1.SessionStatusEvacuating
+	pool.addSession(util.Name(evacuating), evacuating)
+	am.cleanupSessionOnDeletedPod(pool, "test/pod")
+	requeued := evacuating.DeepCopy()
+	requeued.Status = *sm.updated["test/evacuating"]
+	if requeued.Status.SessionStatus != fornaxv1.SessionStatusPending || requeued.Status.PendingSince == nil {
+		t.Fatalf("expect evacuating session requeued as pending with pending since time, got %v", requeued.Status)
+	}
+
+	// requeued session is released from old pod and reopened, open timeout is counted from when it was requeued
+	pool.deleteSession(evacuating)
+	pool.addSession(util.Name(requeued), requeued)
+	old := newTestOpenSession("old", 0, 0, time.Hour, nil)
+	old.CreationTimestamp = metav1.Time{Time: time.Now().Add(-time.Hour)}
+	old.Status = fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusPending}
+	pool.addSession(util.Name(old), old)
+	pendingSessions, _, timeoutSessions := pool.getNonRunningSessions()
+	if len(pendingSessions) != 1 || pendingSessions[0].session.Name != "evacuating" {
+		t.Errorf("expect requeued session pending to reopen, got %d pending sessions", len(pendingSessions))
+	}
+	if len(timeoutSessions) != 1 || timeoutSessions[0].session.Name != "old" {
+		t.Errorf("expect never opened old session timeout, got %d timeout sessions", len(timeoutSessions))
+	}
+
+	// requeued session still time out if it can not be reopened in open timeout
+	requeued = requeued.DeepCopy()
+	requeued.Status.PendingSince = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	pool.addSession(util.Name(requeued), requeued)
+	_, _, timeoutSessions = pool.getNonRunningSessions()
+	if len(timeoutSessions) != 2 {
+		t.Errorf("expect requeued session timeout after open timeout, got %d timeout sessions", len(timeoutSessions))
+	}
+}
+
+func TestReconcilePodSessions(t *testing.T) {
+	sm := &fakeSessionManager{statuses: map[string]fornaxv1.SessionStatus{}}
+	am := &ApplicationManager{applicationPools: map[string]*ApplicationPool{}, sessionManager: sm}
+	pool := am.getOrCreateApplicationPool("test/app")
+	pool.addOrUpdatePod("test/pod", PodStateAllocated, []string{})
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod", Labels: map[string]string{fornaxv1.LabelFornaxCoreApplication: "test/app"}}}
+
+	starting := func(name string, assignedAgo time.Duration) *fornaxv1.ApplicationSession {
+		session := newTestOpenSession(name, 0, 0, 0, nil)
+		session.Status = fornaxv1.ApplicationSessionStatus{
+			SessionStatus:      fornaxv1.SessionStatusStarting,
+			PodReference:       &v1.LocalObjectReference{Name: "test/pod"},
+			LastTransitionTime: &metav1.Time{Time: time.Now().Add(-assignedAgo)},
+		}
+		pool.addSession(util.Name(session), session)
+		return session
+	}
+	lost := starting("lost", time.Minute)
+	reported := starting("reported", time.Minute)
+	starting("inflight", 0)
+	available := newTestOpenSession("available", 0, 0, time.Hour, nil)
+	pool.addSession(util.Name(available), available)
+
+	// only starting session which node does not report and was assigned before grace period is opened again
+	am.ReconcilePodSessions(pod, []*fornaxv1.ApplicationSession{reported})
+	if len(sm.opened) != 1 || sm.opened[0] != util.Name(lost) {
+		t.Errorf("expect lost session open sent again, got %v", sm.opened)
+	}
+
+	// pod of unknown application is ignored
+	other := pod.DeepCopy()
+	other.Labels[fornaxv1.LabelFornaxCoreApplication] = "test/other"
+	am.ReconcilePodSessions(other, nil)
+	if len(sm.opened) != 1 {
+		t.Errorf("expect no session opened for pod of unknown application, got %v", sm.opened)
+	}
+}
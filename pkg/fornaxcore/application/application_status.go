@@ -27,7 +27,6 @@ import (
 	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 
-	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 )
 
@@ -76,7 +75,6 @@ type ApplicationStatusManager struct {
 	applicationStore fornaxstore.ApiStorageInterface
 	statusUpdateCh   chan string
 	statusChanges    *ApplicationStatusChangeMap
-	kubeConfig       *rest.Config
 }
 
 func NewApplicationStatusManager(appStore fornaxstore.ApiStorageInterface) *ApplicationStatusManager {
@@ -87,7 +85,6 @@ func NewApplicationStatusManager(appStore fornaxstore.ApiStorageInterface) *Appl
 			changes: map[string]*fornaxv1.ApplicationStatus{},
 			mu:      sync.Mutex{},
 		},
-		kubeConfig: util.GetFornaxCoreKubeConfig(),
 	}
 }
 
@@ -103,7 +100,7 @@ func (asm *ApplicationStatusManager) AsyncUpdateApplicationStatusRun(ctx context
 		for {
 			select {
 			case <-ctx.Done():
-				break
+				return
 			case <-asm.statusUpdateCh:
 				// consume all signal in channel
 				remainingLen := len(asm.statusUpdateCh)
@@ -0,0 +1,111 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/klog/v2"
+)
+
+// CoreEventsPathPrefix is path prefix of core v1 events api, kubectl describe search events of a object using core v1 events api,
+// fornaxcore serve its events under k8s.io group, events are also listed under core v1 path to let kubectl describe show them
+const CoreEventsPathPrefix = "/api/v1/"
+
+// NewCoreEventsHandler return a read only handler which list events in event store using core v1 events api paths,
+// /api/v1/events and /api/v1/namespaces/{namespace}/events, events are filtered by field selector like kube api server does
+func NewCoreEventsHandler(eventStore fornaxstore.ApiStorageInterface) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace, ok := parseCoreEventsPath(r.URL.Path)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, fmt.Sprintf("method %s is not allowed, events are read only", r.Method), http.StatusMethodNotAllowed)
+			return
+		}
+		selector := fields.Everything()
+		if fieldSelector := r.URL.Query().Get("fieldSelector"); len(fieldSelector) > 0 {
+			var err error
+			if selector, err = fields.ParseSelector(fieldSelector); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		events, err := storefactory.ListFornaxEvents(r.Context(), eventStore)
+		if err != nil {
+			klog.ErrorS(err, "Failed to list events")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		list := &v1.EventList{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "EventList"},
+			Items:    []v1.Event{},
+		}
+		for _, v := range events {
+			if (len(namespace) == 0 || v.Namespace == namespace) && selector.Matches(eventFields(&v)) {
+				list.Items = append(list.Items, v)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(list); err != nil {
+			klog.ErrorS(err, "Failed to write events")
+		}
+	})
+}
+
+// parseCoreEventsPath return namespace of events path, empty namespace means events of all namespaces
+func parseCoreEventsPath(path string) (string, bool) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, CoreEventsPathPrefix), "/"), "/")
+	switch {
+	case len(segments) == 1 && segments[0] == "events":
+		return "", true
+	case len(segments) == 3 && segments[0] == "namespaces" && len(segments[1]) > 0 && segments[2] == "events":
+		return segments[1], true
+	}
+	return "", false
+}
+
+// eventFields return fields of event which can be used in field selector, same as kube api server event fields
+func eventFields(event *v1.Event) fields.Set {
+	return fields.Set{
+		"metadata.name":                  event.Name,
+		"metadata.namespace":             event.Namespace,
+		"involvedObject.kind":            event.InvolvedObject.Kind,
+		"involvedObject.namespace":       event.InvolvedObject.Namespace,
+		"involvedObject.name":            event.InvolvedObject.Name,
+		"involvedObject.uid":             string(event.InvolvedObject.UID),
+		"involvedObject.apiVersion":      event.InvolvedObject.APIVersion,
+		"involvedObject.resourceVersion": event.InvolvedObject.ResourceVersion,
+		"involvedObject.fieldPath":       event.InvolvedObject.FieldPath,
+		"reason":                         event.Reason,
+		"reportingComponent":             event.ReportingController,
+		"source":                         event.Source.Component,
+		"type":                           event.Type,
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"context"
+	"time"
+
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// events are kept for one hour after they were last seen, same as default event ttl of kube api server
+	DefaultEventTTL           = 1 * time.Hour
+	DefaultEventPruneInterval = 5 * time.Minute
+	MaxlengthOfEventQueue     = 1000
+)
+
+var _ ie.EventManagerInterface = &eventManager{}
+
+// eventManager save events recorded by fornaxcore managers and reported by node agents into event store,
+// events with same name are aggregated by increasing count, events not seen for a ttl are pruned
+type eventManager struct {
+	ctx        context.Context
+	eventStore fornaxstore.ApiStorageInterface
+	events     chan *v1.Event
+	eventTTL   time.Duration
+}
+
+func NewEventManager(ctx context.Context, eventStore fornaxstore.ApiStorageInterface) *eventManager {
+	return &eventManager{
+		ctx:        ctx,
+		eventStore: eventStore,
+		events:     make(chan *v1.Event, MaxlengthOfEventQueue),
+		eventTTL:   DefaultEventTTL,
+	}
+}
+
+// NewRecorder return a event recorder of a fornaxcore component which save events using this event manager
+func (em *eventManager) NewRecorder(component string) record.EventRecorder {
+	return util.NewEventRecorder(v1.EventSource{Component: component}, em.RecordEvent)
+}
+
+// RecordEvent put event into queue, event is dropped if queue is full, recorder should never block callers
+func (em *eventManager) RecordEvent(event *v1.Event) {
+	select {
+	case em.events <- event:
+	default:
+		klog.Warningf("Event queue is full, drop event %s, reason %s, message %s", util.Name(event), event.Reason, event.Message)
+	}
+}
+
+func (em *eventManager) Run() {
+	klog.Info("starting event manager")
+	go func() {
+		ticker := time.NewTicker(DefaultEventPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-em.ctx.Done():
+				return
+			case event := <-em.events:
+				if err := em.saveEvent(event); err != nil {
+					klog.ErrorS(err, "Failed to save event", "event", util.Name(event), "reason", event.Reason)
+				}
+			case <-ticker.C:
+				em.pruneEvents()
+			}
+		}
+	}()
+}
+
+// saveEvent create event in store, or increase count and last timestamp of same event in store
+func (em *eventManager) saveEvent(event *v1.Event) error {
+	existing, err := storefactory.GetFornaxEventCache(em.eventStore, util.Name(event))
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		_, err = storefactory.CreateFornaxEvent(em.ctx, em.eventStore, event)
+		return err
+	}
+	updated := existing.DeepCopy()
+	updated.Count += event.Count
+	updated.LastTimestamp = event.LastTimestamp
+	_, err = storefactory.UpdateFornaxEvent(em.ctx, em.eventStore, updated)
+	return err
+}
+
+func (em *eventManager) pruneEvents() {
+	events, err := storefactory.ListFornaxEvents(em.ctx, em.eventStore)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list events")
+		return
+	}
+	for _, v := range events {
+		if time.Since(v.LastTimestamp.Time) > em.eventTTL {
+			if _, err := storefactory.DeleteFornaxEvent(em.ctx, em.eventStore, util.Name(&v)); err != nil {
+				klog.ErrorS(err, "Failed to delete expired event", "event", util.Name(&v))
+			}
+		}
+	}
+}
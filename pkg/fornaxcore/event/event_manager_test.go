@@ -0,0 +1,165 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestSession(name string) *fornaxv1.ApplicationSession {
+	return &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name, UID: types.UID(name + "-uid")},
+	}
+}
+
+func newTestEvent(t *testing.T, object *fornaxv1.ApplicationSession, reason, message string) *v1.Event {
+	event, err := util.NewEvent(v1.EventSource{Component: "test"}, object, nil, v1.EventTypeWarning, reason, message)
+	if err != nil {
+		t.Fatalf("failed to build event, %v", err)
+	}
+	return event
+}
+
+func TestSaveEvent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	em := NewEventManager(ctx, storefactory.NewFornaxEventStorage(ctx))
+	session := newTestSession("save")
+
+	first := newTestEvent(t, session, "Timeout", "session open timeout")
+	if err := em.saveEvent(first); err != nil {
+		t.Fatalf("failed to save event, %v", err)
+	}
+	// same event repeated is aggregated into existing event
+	repeated := newTestEvent(t, session, "Timeout", "session open timeout")
+	repeated.LastTimestamp = metav1.NewTime(first.LastTimestamp.Add(time.Minute))
+	if err := em.saveEvent(repeated); err != nil {
+		t.Fatalf("failed to save repeated event, %v", err)
+	}
+	saved, err := storefactory.GetFornaxEventCache(em.eventStore, util.Name(first))
+	if err != nil || saved == nil {
+		t.Fatalf("expect event saved, got %v, %v", saved, err)
+	}
+	if saved.Count != 2 || !saved.LastTimestamp.Equal(&repeated.LastTimestamp) || !saved.FirstTimestamp.Equal(&first.FirstTimestamp) {
+		t.Errorf("expect event count 2 and last timestamp of repeated event, got %d, first %v, last %v", saved.Count, saved.FirstTimestamp, saved.LastTimestamp)
+	}
+
+	// event with different message is saved as a new event
+	other := newTestEvent(t, session, "Timeout", "session close timeout")
+	if err := em.saveEvent(other); err != nil {
+		t.Fatalf("failed to save event, %v", err)
+	}
+	if saved, _ := storefactory.GetFornaxEventCache(em.eventStore, util.Name(other)); saved == nil || saved.Count != 1 {
+		t.Errorf("expect a new event with count 1, got %v", saved)
+	}
+}
+
+func TestPruneEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	em := NewEventManager(ctx, storefactory.NewFornaxEventStorage(ctx))
+	session := newTestSession("prune")
+
+	expired := newTestEvent(t, session, "Failed", "expired event")
+	expired.LastTimestamp = metav1.NewTime(time.Now().Add(-DefaultEventTTL - time.Minute))
+	recent := newTestEvent(t, session, "Failed", "recent event")
+	// event first seen long ago but seen again recently is kept
+	repeated := newTestEvent(t, session, "Failed", "repeated event")
+	repeated.FirstTimestamp = metav1.NewTime(time.Now().Add(-2 * DefaultEventTTL))
+	for _, v := range []*v1.Event{expired, recent, repeated} {
+		if err := em.saveEvent(v); err != nil {
+			t.Fatalf("failed to save event, %v", err)
+		}
+	}
+
+	em.pruneEvents()
+	for _, v := range []*v1.Event{expired, recent, repeated} {
+		saved, _ := storefactory.GetFornaxEventCache(em.eventStore, util.Name(v))
+		if pruned := saved == nil; pruned != (v == expired) {
+			t.Errorf("event %s: expect pruned %v, got %v", v.Message, v == expired, pruned)
+		}
+	}
+}
+
+func TestCoreEventsHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	em := NewEventManager(ctx, storefactory.NewFornaxEventStorage(ctx))
+	for _, v := range []*v1.Event{
+		newTestEvent(t, newTestSession("describe"), "Timeout", "session open timeout"),
+		newTestEvent(t, newTestSession("describe"), "Closed", "session closed"),
+		newTestEvent(t, newTestSession("describe-other"), "Timeout", "session open timeout"),
+	} {
+		if err := em.saveEvent(v); err != nil {
+			t.Fatalf("failed to save event, %v", err)
+		}
+	}
+	handler := NewCoreEventsHandler(em.eventStore)
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		code   int
+		num    int
+	}{
+		// kubectl describe search events of a object by its name, namespace, kind and uid
+		{
+			name:   "describe session",
+			method: http.MethodGet,
+			url:    "/api/v1/namespaces/test/events?fieldSelector=involvedObject.name%3Ddescribe,involvedObject.namespace%3Dtest,involvedObject.kind%3DApplicationSession,involvedObject.uid%3Ddescribe-uid",
+			code:   http.StatusOK,
+			num:    2,
+		},
+		{name: "reason", method: http.MethodGet, url: "/api/v1/events?fieldSelector=reason%3DTimeout,involvedObject.name%3Ddescribe-other", code: http.StatusOK, num: 1},
+		{name: "other namespace", method: http.MethodGet, url: "/api/v1/namespaces/other/events", code: http.StatusOK, num: 0},
+		{name: "invalid field selector", method: http.MethodGet, url: "/api/v1/events?fieldSelector=reason", code: http.StatusBadRequest},
+		{name: "not events path", method: http.MethodGet, url: "/api/v1/namespaces/test/pods", code: http.StatusNotFound},
+		{name: "read only", method: http.MethodPost, url: "/api/v1/namespaces/test/events", code: http.StatusMethodNotAllowed},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(test.method, test.url, nil))
+		if w.Code != test.code {
+			t.Errorf("%s: expect status code %d, got %d, %s", test.name, test.code, w.Code, w.Body.String())
+			continue
+		}
+		if test.code != http.StatusOK {
+			continue
+		}
+		list := &v1.EventList{}
+		if err := json.Unmarshal(w.Body.Bytes(), list); err != nil {
+			t.Fatalf("%s: failed to decode event list, %v", test.name, err)
+		}
+		if list.Kind != "EventList" || len(list.Items) != test.num {
+			t.Errorf("%s: expect %d events, got %d of %s", test.name, test.num, len(list.Items), list.Kind)
+		}
+	}
+}
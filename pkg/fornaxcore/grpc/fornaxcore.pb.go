@@ -47,13 +47,16 @@ const (
 	MessageType_NODE_READY                MessageType = 202
 	MessageType_NODE_STATE                MessageType = 203
 	MessageType_NODE_FULL_SYNC            MessageType = 204
+	MessageType_NODE_EVENT                MessageType = 205
 	MessageType_POD_CREATE                MessageType = 300
 	MessageType_POD_TERMINATE             MessageType = 301
 	MessageType_POD_HIBERNATE             MessageType = 302
 	MessageType_POD_STATE                 MessageType = 303
+	MessageType_POD_EVACUATE              MessageType = 304
 	MessageType_SESSION_OPEN              MessageType = 400
 	MessageType_SESSION_CLOSE             MessageType = 401
 	MessageType_SESSION_STATE             MessageType = 402
+	MessageType_MESSAGE_ACK               MessageType = 500
 )
 
 // Enum value maps for MessageType.
@@ -66,13 +69,16 @@ var (
 		202: "NODE_READY",
 		203: "NODE_STATE",
 		204: "NODE_FULL_SYNC",
+		205: "NODE_EVENT",
 		300: "POD_CREATE",
 		301: "POD_TERMINATE",
 		302: "POD_HIBERNATE",
 		303: "POD_STATE",
+		304: "POD_EVACUATE",
 		400: "SESSION_OPEN",
 		401: "SESSION_CLOSE",
 		402: "SESSION_STATE",
+		500: "MESSAGE_ACK",
 	}
 	MessageType_value = map[string]int32{
 		"UNSPECIFIED":               0,
@@ -82,13 +88,16 @@ var (
 		"NODE_READY":                202,
 		"NODE_STATE":                203,
 		"NODE_FULL_SYNC":            204,
+		"NODE_EVENT":                205,
 		"POD_CREATE":                300,
 		"POD_TERMINATE":             301,
 		"POD_HIBERNATE":             302,
 		"POD_STATE":                 303,
+		"POD_EVACUATE":              304,
 		"SESSION_OPEN":              400,
 		"SESSION_CLOSE":             401,
 		"SESSION_STATE":             402,
+		"MESSAGE_ACK":               500,
 	}
 )
 
@@ -177,7 +186,7 @@ func (x PodState_State) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use PodState_State.Descriptor instead.
 func (PodState_State) EnumDescriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{9, 0}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{13, 0}
 }
 
 type FornaxCoreMessage struct {
@@ -195,13 +204,16 @@ type FornaxCoreMessage struct {
 	//	*FornaxCoreMessage_NodeReady
 	//	*FornaxCoreMessage_NodeState
 	//	*FornaxCoreMessage_NodeFullSync
+	//	*FornaxCoreMessage_NodeEvent
 	//	*FornaxCoreMessage_PodCreate
 	//	*FornaxCoreMessage_PodTerminate
 	//	*FornaxCoreMessage_PodHibernate
 	//	*FornaxCoreMessage_PodState
+	//	*FornaxCoreMessage_PodEvacuate
 	//	*FornaxCoreMessage_SessionOpen
 	//	*FornaxCoreMessage_SessionClose
 	//	*FornaxCoreMessage_SessionState
+	//	*FornaxCoreMessage_MessageAck
 	MessageBody isFornaxCoreMessage_MessageBody `protobuf_oneof:"MessageBody"`
 }
 
@@ -307,6 +319,13 @@ func (x *FornaxCoreMessage) GetNodeFullSync() *NodeFullSync {
 	return nil
 }
 
+func (x *FornaxCoreMessage) GetNodeEvent() *NodeEvent {
+	if x, ok := x.GetMessageBody().(*FornaxCoreMessage_NodeEvent); ok {
+		return x.NodeEvent
+	}
+	return nil
+}
+
 func (x *FornaxCoreMessage) GetPodCreate() *PodCreate {
 	if x, ok := x.GetMessageBody().(*FornaxCoreMessage_PodCreate); ok {
 		return x.PodCreate
@@ -335,6 +354,13 @@ func (x *FornaxCoreMessage) GetPodState() *PodState {
 	return nil
 }
 
+func (x *FornaxCoreMessage) GetPodEvacuate() *PodEvacuate {
+	if x, ok := x.GetMessageBody().(*FornaxCoreMessage_PodEvacuate); ok {
+		return x.PodEvacuate
+	}
+	return nil
+}
+
 func (x *FornaxCoreMessage) GetSessionOpen() *SessionOpen {
 	if x, ok := x.GetMessageBody().(*FornaxCoreMessage_SessionOpen); ok {
 		return x.SessionOpen
@@ -356,6 +382,13 @@ func (x *FornaxCoreMessage) GetSessionState() *SessionState {
 	return nil
 }
 
+func (x *FornaxCoreMessage) GetMessageAck() *MessageAck {
+	if x, ok := x.GetMessageBody().(*FornaxCoreMessage_MessageAck); ok {
+		return x.MessageAck
+	}
+	return nil
+}
+
 type isFornaxCoreMessage_MessageBody interface {
 	isFornaxCoreMessage_MessageBody()
 }
@@ -384,6 +417,10 @@ type FornaxCoreMessage_NodeFullSync struct {
 	NodeFullSync *NodeFullSync `protobuf:"bytes,204,opt,name=nodeFullSync,proto3,oneof"`
 }
 
+type FornaxCoreMessage_NodeEvent struct {
+	NodeEvent *NodeEvent `protobuf:"bytes,205,opt,name=nodeEvent,proto3,oneof"`
+}
+
 type FornaxCoreMessage_PodCreate struct {
 	PodCreate *PodCreate `protobuf:"bytes,300,opt,name=podCreate,proto3,oneof"`
 }
@@ -400,6 +437,10 @@ type FornaxCoreMessage_PodState struct {
 	PodState *PodState `protobuf:"bytes,303,opt,name=podState,proto3,oneof"`
 }
 
+type FornaxCoreMessage_PodEvacuate struct {
+	PodEvacuate *PodEvacuate `protobuf:"bytes,304,opt,name=podEvacuate,proto3,oneof"`
+}
+
 type FornaxCoreMessage_SessionOpen struct {
 	SessionOpen *SessionOpen `protobuf:"bytes,400,opt,name=sessionOpen,proto3,oneof"`
 }
@@ -412,6 +453,10 @@ type FornaxCoreMessage_SessionState struct {
 	SessionState *SessionState `protobuf:"bytes,402,opt,name=sessionState,proto3,oneof"`
 }
 
+type FornaxCoreMessage_MessageAck struct {
+	MessageAck *MessageAck `protobuf:"bytes,500,opt,name=messageAck,proto3,oneof"`
+}
+
 func (*FornaxCoreMessage_FornaxCoreConfiguration) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_NodeConfiguration) isFornaxCoreMessage_MessageBody() {}
@@ -424,6 +469,8 @@ func (*FornaxCoreMessage_NodeState) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_NodeFullSync) isFornaxCoreMessage_MessageBody() {}
 
+func (*FornaxCoreMessage_NodeEvent) isFornaxCoreMessage_MessageBody() {}
+
 func (*FornaxCoreMessage_PodCreate) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_PodTerminate) isFornaxCoreMessage_MessageBody() {}
@@ -432,12 +479,16 @@ func (*FornaxCoreMessage_PodHibernate) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_PodState) isFornaxCoreMessage_MessageBody() {}
 
+func (*FornaxCoreMessage_PodEvacuate) isFornaxCoreMessage_MessageBody() {}
+
 func (*FornaxCoreMessage_SessionOpen) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_SessionClose) isFornaxCoreMessage_MessageBody() {}
 
 func (*FornaxCoreMessage_SessionState) isFornaxCoreMessage_MessageBody() {}
 
+func (*FornaxCoreMessage_MessageAck) isFornaxCoreMessage_MessageBody() {}
+
 type FornaxCore struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -603,6 +654,126 @@ func (x *NodeIdentifier) GetIdentifier() string {
 	return ""
 }
 
+// node present a bootstrap token and a pem encoded certificate signing request to get a node client certificate
+type NodeEnrollment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeIdentifier            *NodeIdentifier `protobuf:"bytes,1,opt,name=nodeIdentifier,proto3" json:"nodeIdentifier,omitempty"`
+	BootstrapToken            string          `protobuf:"bytes,2,opt,name=bootstrapToken,proto3" json:"bootstrapToken,omitempty"`
+	CertificateSigningRequest []byte          `protobuf:"bytes,3,opt,name=certificateSigningRequest,proto3" json:"certificateSigningRequest,omitempty"`
+}
+
+func (x *NodeEnrollment) Reset() {
+	*x = NodeEnrollment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeEnrollment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeEnrollment) ProtoMessage() {}
+
+func (x *NodeEnrollment) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeEnrollment.ProtoReflect.Descriptor instead.
+func (*NodeEnrollment) Descriptor() ([]byte, []int) {
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NodeEnrollment) GetNodeIdentifier() *NodeIdentifier {
+	if x != nil {
+		return x.NodeIdentifier
+	}
+	return nil
+}
+
+func (x *NodeEnrollment) GetBootstrapToken() string {
+	if x != nil {
+		return x.BootstrapToken
+	}
+	return ""
+}
+
+func (x *NodeEnrollment) GetCertificateSigningRequest() []byte {
+	if x != nil {
+		return x.CertificateSigningRequest
+	}
+	return nil
+}
+
+// pem encoded node client certificate and certificate authority which signed it
+type NodeCertificate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Certificate   []byte `protobuf:"bytes,1,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	CaCertificate []byte `protobuf:"bytes,2,opt,name=caCertificate,proto3" json:"caCertificate,omitempty"`
+}
+
+func (x *NodeCertificate) Reset() {
+	*x = NodeCertificate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeCertificate) ProtoMessage() {}
+
+func (x *NodeCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeCertificate.ProtoReflect.Descriptor instead.
+func (*NodeCertificate) Descriptor() ([]byte, []int) {
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *NodeCertificate) GetCertificate() []byte {
+	if x != nil {
+		return x.Certificate
+	}
+	return nil
+}
+
+func (x *NodeCertificate) GetCaCertificate() []byte {
+	if x != nil {
+		return x.CaCertificate
+	}
+	return nil
+}
+
 // node register with fornax core, wait for a configuration message to initialize it
 type NodeRegistry struct {
 	state         protoimpl.MessageState
@@ -616,7 +787,7 @@ type NodeRegistry struct {
 func (x *NodeRegistry) Reset() {
 	*x = NodeRegistry{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[4]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -629,7 +800,7 @@ func (x *NodeRegistry) String() string {
 func (*NodeRegistry) ProtoMessage() {}
 
 func (x *NodeRegistry) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[4]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -642,7 +813,7 @@ func (x *NodeRegistry) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeRegistry.ProtoReflect.Descriptor instead.
 func (*NodeRegistry) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{4}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *NodeRegistry) GetNodeRevision() int64 {
@@ -673,7 +844,7 @@ type NodeConfiguration struct {
 func (x *NodeConfiguration) Reset() {
 	*x = NodeConfiguration{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[5]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -686,7 +857,7 @@ func (x *NodeConfiguration) String() string {
 func (*NodeConfiguration) ProtoMessage() {}
 
 func (x *NodeConfiguration) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[5]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -699,7 +870,7 @@ func (x *NodeConfiguration) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeConfiguration.ProtoReflect.Descriptor instead.
 func (*NodeConfiguration) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{5}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *NodeConfiguration) GetClusterDomain() string {
@@ -738,7 +909,7 @@ type NodeReady struct {
 func (x *NodeReady) Reset() {
 	*x = NodeReady{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[6]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -751,7 +922,7 @@ func (x *NodeReady) String() string {
 func (*NodeReady) ProtoMessage() {}
 
 func (x *NodeReady) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[6]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -764,7 +935,7 @@ func (x *NodeReady) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeReady.ProtoReflect.Descriptor instead.
 func (*NodeReady) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{6}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *NodeReady) GetNodeRevision() int64 {
@@ -809,7 +980,7 @@ type NodeState struct {
 func (x *NodeState) Reset() {
 	*x = NodeState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[7]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -822,7 +993,7 @@ func (x *NodeState) String() string {
 func (*NodeState) ProtoMessage() {}
 
 func (x *NodeState) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[7]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -835,7 +1006,7 @@ func (x *NodeState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeState.ProtoReflect.Descriptor instead.
 func (*NodeState) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{7}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *NodeState) GetNodeRevision() int64 {
@@ -869,7 +1040,7 @@ type NodeFullSync struct {
 func (x *NodeFullSync) Reset() {
 	*x = NodeFullSync{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[8]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -882,7 +1053,7 @@ func (x *NodeFullSync) String() string {
 func (*NodeFullSync) ProtoMessage() {}
 
 func (x *NodeFullSync) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[8]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -895,7 +1066,103 @@ func (x *NodeFullSync) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NodeFullSync.ProtoReflect.Descriptor instead.
 func (*NodeFullSync) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{8}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{10}
+}
+
+// node report k8s events of node, pods and sessions, fornax core save them as Events
+type NodeEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Event *v1.Event `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+}
+
+func (x *NodeEvent) Reset() {
+	*x = NodeEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NodeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeEvent) ProtoMessage() {}
+
+func (x *NodeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeEvent.ProtoReflect.Descriptor instead.
+func (*NodeEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NodeEvent) GetEvent() *v1.Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// node acknowledge fornax core messages which require ack after handled them, unacked messages are resent when node reconnect
+type MessageAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MessageIdentifiers []string `protobuf:"bytes,1,rep,name=messageIdentifiers,proto3" json:"messageIdentifiers,omitempty"`
+}
+
+func (x *MessageAck) Reset() {
+	*x = MessageAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MessageAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageAck) ProtoMessage() {}
+
+func (x *MessageAck) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageAck.ProtoReflect.Descriptor instead.
+func (*MessageAck) Descriptor() ([]byte, []int) {
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MessageAck) GetMessageIdentifiers() []string {
+	if x != nil {
+		return x.MessageIdentifiers
+	}
+	return nil
 }
 
 type PodState struct {
@@ -913,7 +1180,7 @@ type PodState struct {
 func (x *PodState) Reset() {
 	*x = PodState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[9]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -926,7 +1193,7 @@ func (x *PodState) String() string {
 func (*PodState) ProtoMessage() {}
 
 func (x *PodState) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[9]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -939,7 +1206,7 @@ func (x *PodState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodState.ProtoReflect.Descriptor instead.
 func (*PodState) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{9}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *PodState) GetNodeRevision() int64 {
@@ -989,7 +1256,7 @@ type PodResource struct {
 func (x *PodResource) Reset() {
 	*x = PodResource{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[10]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1002,7 +1269,7 @@ func (x *PodResource) String() string {
 func (*PodResource) ProtoMessage() {}
 
 func (x *PodResource) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[10]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1015,7 +1282,7 @@ func (x *PodResource) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodResource.ProtoReflect.Descriptor instead.
 func (*PodResource) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{10}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *PodResource) GetResourceQuotaStatus() *v1.ResourceQuotaStatus {
@@ -1037,15 +1304,17 @@ type PodCreate struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PodIdentifier string        `protobuf:"bytes,1,opt,name=podIdentifier,proto3" json:"podIdentifier,omitempty"`
-	Pod           *v1.Pod       `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
-	ConfigMap     *v1.ConfigMap `protobuf:"bytes,3,opt,name=configMap,proto3" json:"configMap,omitempty"`
+	PodIdentifier    string        `protobuf:"bytes,1,opt,name=podIdentifier,proto3" json:"podIdentifier,omitempty"`
+	Pod              *v1.Pod       `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	ConfigMap        *v1.ConfigMap `protobuf:"bytes,3,opt,name=configMap,proto3" json:"configMap,omitempty"`
+	Secrets          []*v1.Secret  `protobuf:"bytes,4,rep,name=secrets,proto3" json:"secrets,omitempty"`
+	ImagePullSecrets []*v1.Secret  `protobuf:"bytes,5,rep,name=imagePullSecrets,proto3" json:"imagePullSecrets,omitempty"`
 }
 
 func (x *PodCreate) Reset() {
 	*x = PodCreate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[11]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1058,7 +1327,7 @@ func (x *PodCreate) String() string {
 func (*PodCreate) ProtoMessage() {}
 
 func (x *PodCreate) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[11]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1071,7 +1340,7 @@ func (x *PodCreate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodCreate.ProtoReflect.Descriptor instead.
 func (*PodCreate) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{11}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *PodCreate) GetPodIdentifier() string {
@@ -1095,6 +1364,20 @@ func (x *PodCreate) GetConfigMap() *v1.ConfigMap {
 	return nil
 }
 
+func (x *PodCreate) GetSecrets() []*v1.Secret {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *PodCreate) GetImagePullSecrets() []*v1.Secret {
+	if x != nil {
+		return x.ImagePullSecrets
+	}
+	return nil
+}
+
 type PodTerminate struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1106,7 +1389,7 @@ type PodTerminate struct {
 func (x *PodTerminate) Reset() {
 	*x = PodTerminate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[12]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1119,7 +1402,7 @@ func (x *PodTerminate) String() string {
 func (*PodTerminate) ProtoMessage() {}
 
 func (x *PodTerminate) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[12]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1132,7 +1415,7 @@ func (x *PodTerminate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodTerminate.ProtoReflect.Descriptor instead.
 func (*PodTerminate) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{12}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *PodTerminate) GetPodIdentifier() string {
@@ -1153,7 +1436,7 @@ type PodHibernate struct {
 func (x *PodHibernate) Reset() {
 	*x = PodHibernate{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[13]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1166,7 +1449,7 @@ func (x *PodHibernate) String() string {
 func (*PodHibernate) ProtoMessage() {}
 
 func (x *PodHibernate) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[13]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1179,7 +1462,7 @@ func (x *PodHibernate) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PodHibernate.ProtoReflect.Descriptor instead.
 func (*PodHibernate) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{13}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *PodHibernate) GetPodIdentifier() string {
@@ -1189,6 +1472,54 @@ func (x *PodHibernate) GetPodIdentifier() string {
 	return ""
 }
 
+// evacuate sessions on pod to other pods, pod does not accept new session and is terminated after sessions are evacuated
+type PodEvacuate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PodIdentifier string `protobuf:"bytes,1,opt,name=podIdentifier,proto3" json:"podIdentifier,omitempty"`
+}
+
+func (x *PodEvacuate) Reset() {
+	*x = PodEvacuate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PodEvacuate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PodEvacuate) ProtoMessage() {}
+
+func (x *PodEvacuate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PodEvacuate.ProtoReflect.Descriptor instead.
+func (*PodEvacuate) Descriptor() ([]byte, []int) {
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PodEvacuate) GetPodIdentifier() string {
+	if x != nil {
+		return x.PodIdentifier
+	}
+	return ""
+}
+
 type SessionState struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -1202,7 +1533,7 @@ type SessionState struct {
 func (x *SessionState) Reset() {
 	*x = SessionState{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[14]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1215,7 +1546,7 @@ func (x *SessionState) String() string {
 func (*SessionState) ProtoMessage() {}
 
 func (x *SessionState) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[14]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1228,7 +1559,7 @@ func (x *SessionState) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SessionState.ProtoReflect.Descriptor instead.
 func (*SessionState) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{14}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *SessionState) GetNodeRevision() int64 {
@@ -1265,7 +1596,7 @@ type SessionOpen struct {
 func (x *SessionOpen) Reset() {
 	*x = SessionOpen{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[15]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1278,7 +1609,7 @@ func (x *SessionOpen) String() string {
 func (*SessionOpen) ProtoMessage() {}
 
 func (x *SessionOpen) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[15]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1291,7 +1622,7 @@ func (x *SessionOpen) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SessionOpen.ProtoReflect.Descriptor instead.
 func (*SessionOpen) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{15}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *SessionOpen) GetSessionIdentifier() string {
@@ -1327,7 +1658,7 @@ type SessionClose struct {
 func (x *SessionClose) Reset() {
 	*x = SessionClose{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[16]
+		mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1340,7 +1671,7 @@ func (x *SessionClose) String() string {
 func (*SessionClose) ProtoMessage() {}
 
 func (x *SessionClose) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[16]
+	mi := &file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1353,7 +1684,7 @@ func (x *SessionClose) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SessionClose.ProtoReflect.Descriptor instead.
 func (*SessionClose) Descriptor() ([]byte, []int) {
-	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{16}
+	return file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *SessionClose) GetSessionIdentifier() string {
@@ -1381,7 +1712,7 @@ var file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDesc = []byte{
 	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d,
 	0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x22, 0x6b, 0x38, 0x73, 0x2e, 0x69,
 	0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa7, 0x0b,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa6, 0x0d,
 	0x0a, 0x11, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65, 0x4d, 0x65, 0x73, 0x73,
 	0x61, 0x67, 0x65, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64,
 	0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11,
@@ -1433,163 +1764,217 @@ var file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDesc = []byte{
 	0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73,
 	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x46, 0x75, 0x6c, 0x6c, 0x53,
 	0x79, 0x6e, 0x63, 0x48, 0x00, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x46, 0x75, 0x6c, 0x6c, 0x53,
-	0x79, 0x6e, 0x63, 0x12, 0x50, 0x0a, 0x09, 0x70, 0x6f, 0x64, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x18, 0xac, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
-	0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e,
-	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50,
-	0x6f, 0x64, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x09, 0x70, 0x6f, 0x64, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x59, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d,
-	0x69, 0x6e, 0x61, 0x74, 0x65, 0x18, 0xad, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63,
-	0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f,
-	0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65,
-	0x48, 0x00, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65,
-	0x12, 0x59, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x65,
-	0x18, 0xae, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
+	0x79, 0x6e, 0x63, 0x12, 0x50, 0x0a, 0x09, 0x6e, 0x6f, 0x64, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x18, 0xcd, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
 	0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e,
-	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50,
-	0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x70,
-	0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x4d, 0x0a, 0x08, 0x70,
-	0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0xaf, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2e,
+	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e,
+	0x6f, 0x64, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x09, 0x6e, 0x6f, 0x64, 0x65,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x50, 0x0a, 0x09, 0x70, 0x6f, 0x64, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x18, 0xac, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x63, 0x65, 0x6e, 0x74,
+	0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f,
+	0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x50, 0x6f, 0x64, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x09, 0x70, 0x6f,
+	0x64, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x59, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x54, 0x65,
+	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x18, 0xad, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32,
 	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e,
 	0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65,
-	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x00,
-	0x52, 0x08, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x56, 0x0a, 0x0b, 0x73, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x18, 0x90, 0x03, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x31, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72,
-	0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f,
-	0x70, 0x65, 0x6e, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70,
-	0x65, 0x6e, 0x12, 0x59, 0x0a, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f,
-	0x73, 0x65, 0x18, 0x91, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x70, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61,
+	0x74, 0x65, 0x12, 0x59, 0x0a, 0x0c, 0x70, 0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61,
+	0x74, 0x65, 0x18, 0xae, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74,
 	0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f,
 	0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x48, 0x00, 0x52,
-	0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x59, 0x0a,
-	0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x92, 0x03,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73,
-	0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63,
-	0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x0d, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x3c, 0x0a, 0x0a, 0x46, 0x6f, 0x72, 0x6e, 0x61,
-	0x78, 0x43, 0x6f, 0x72, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66,
-	0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0xb3, 0x01, 0x0a, 0x17, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78,
-	0x43, 0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x12, 0x4a, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x30, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e,
+	0x2e, 0x50, 0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52,
+	0x0c, 0x70, 0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x4d, 0x0a,
+	0x08, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0xaf, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x2e, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72,
+	0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x48, 0x00, 0x52, 0x08, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x56, 0x0a, 0x0b,
+	0x70, 0x6f, 0x64, 0x45, 0x76, 0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x18, 0xb0, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x31, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e,
 	0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72,
-	0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78,
-	0x43, 0x6f, 0x72, 0x65, 0x52, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x4c, 0x0a,
-	0x08, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x30, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61,
-	0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73,
-	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72,
-	0x65, 0x52, 0x08, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x73, 0x22, 0x40, 0x0a, 0x0e, 0x4e,
-	0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x0e, 0x0a,
-	0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x1e, 0x0a,
-	0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x60, 0x0a,
-	0x0c, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x12, 0x22, 0x0a,
-	0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
-	0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72,
-	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x22,
-	0xa0, 0x01, 0x0a, 0x11, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
-	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
-	0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6c,
-	0x75, 0x73, 0x74, 0x65, 0x72, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e,
-	0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e,
-	0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e,
-	0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x64, 0x61, 0x65,
-	0x6d, 0x6f, 0x6e, 0x50, 0x6f, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e,
-	0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
-	0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x0a, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x50, 0x6f,
-	0x64, 0x73, 0x22, 0x85, 0x02, 0x0a, 0x09, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x61, 0x64, 0x79,
-	0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69,
-	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f,
-	0x64, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18,
-	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
-	0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73,
-	0x12, 0x58, 0x0a, 0x0d, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65,
-	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
+	0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x45, 0x76, 0x61,
+	0x63, 0x75, 0x61, 0x74, 0x65, 0x48, 0x00, 0x52, 0x0b, 0x70, 0x6f, 0x64, 0x45, 0x76, 0x61, 0x63,
+	0x75, 0x61, 0x74, 0x65, 0x12, 0x56, 0x0a, 0x0b, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f,
+	0x70, 0x65, 0x6e, 0x18, 0x90, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66,
+	0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x48, 0x00, 0x52,
+	0x0b, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x59, 0x0a, 0x0c,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x18, 0x91, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69,
+	0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x12, 0x59, 0x0a, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x92, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e,
+	0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x48, 0x00, 0x52, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x53, 0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x63, 0x6b,
+	0x18, 0xf4, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
+	0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e,
+	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x41, 0x63, 0x6b, 0x48, 0x00, 0x52, 0x0a, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x41, 0x63, 0x6b, 0x42, 0x0d, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x3c, 0x0a, 0x0a, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78,
+	0x43, 0x6f, 0x72, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x66, 0x69, 0x65, 0x72, 0x22, 0xb3, 0x01, 0x0a, 0x17, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43,
+	0x6f, 0x72, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x4a, 0x0a, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x30, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66,
+	0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43,
+	0x6f, 0x72, 0x65, 0x52, 0x07, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x4c, 0x0a, 0x08,
+	0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e,
+	0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65,
+	0x52, 0x08, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x73, 0x22, 0x40, 0x0a, 0x0e, 0x4e, 0x6f,
+	0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x1e, 0x0a, 0x0a,
+	0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0xd4, 0x01, 0x0a,
+	0x0e, 0x4e, 0x6f, 0x64, 0x65, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x5c, 0x0a, 0x0e, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x34, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
 	0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e,
-	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53,
-	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0d, 0x73, 0x65, 0x73,
-	0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0xab, 0x01, 0x0a, 0x09, 0x4e,
-	0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65,
-	0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c,
-	0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x04,
+	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e,
+	0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x52, 0x0e, 0x6e,
+	0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x26, 0x0a,
+	0x0e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x62, 0x6f, 0x6f, 0x74, 0x73, 0x74, 0x72, 0x61, 0x70,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x3c, 0x0a, 0x19, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69,
+	0x63, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x19, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0x59, 0x0a, 0x0f, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x65, 0x72, 0x74, 0x69,
+	0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x63, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x61, 0x43, 0x65,
+	0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0d, 0x63, 0x61, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x22, 0x60,
+	0x0a, 0x0c, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x12, 0x22,
+	0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65,
+	0x22, 0xa0, 0x01, 0x0a, 0x11, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65,
+	0x72, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63,
+	0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x2c, 0x0a, 0x04,
 	0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73,
 	0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e,
-	0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x70, 0x6f,
-	0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e,
-	0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69,
-	0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x70,
-	0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x4e, 0x6f, 0x64, 0x65,
-	0x46, 0x75, 0x6c, 0x6c, 0x53, 0x79, 0x6e, 0x63, 0x22, 0xc0, 0x03, 0x0a, 0x08, 0x50, 0x6f, 0x64,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76,
-	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64,
-	0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x4a, 0x0a, 0x05, 0x73, 0x74, 0x61,
-	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x34, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61,
+	0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x37, 0x0a, 0x0a, 0x64, 0x61,
+	0x65, 0x6d, 0x6f, 0x6e, 0x50, 0x6f, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17,
+	0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x0a, 0x64, 0x61, 0x65, 0x6d, 0x6f, 0x6e, 0x50,
+	0x6f, 0x64, 0x73, 0x22, 0x85, 0x02, 0x0a, 0x09, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x61, 0x64,
+	0x79, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76,
+	0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e,
+	0x6f, 0x64, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72,
+	0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61,
+	0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09, 0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x58, 0x0a, 0x0d, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61,
 	0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72,
 	0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e,
-	0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05,
-	0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x03, 0x70, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0d, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0xab, 0x01, 0x0a, 0x09,
+	0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64,
+	0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x0a,
+	0x04, 0x6e, 0x6f, 0x64, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x6b, 0x38,
+	0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6e, 0x6f, 0x64, 0x65, 0x12, 0x4c, 0x0a, 0x09, 0x70,
+	0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e,
+	0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x09,
+	0x70, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0x0e, 0x0a, 0x0c, 0x4e, 0x6f, 0x64,
+	0x65, 0x46, 0x75, 0x6c, 0x6c, 0x53, 0x79, 0x6e, 0x63, 0x22, 0x3c, 0x0a, 0x09, 0x4e, 0x6f, 0x64,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2f, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x3c, 0x0a, 0x0a, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x41, 0x63, 0x6b, 0x12, 0x2e, 0x0a, 0x12, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x12, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69,
+	0x66, 0x69, 0x65, 0x72, 0x73, 0x22, 0xc0, 0x03, 0x0a, 0x08, 0x50, 0x6f, 0x64, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x52, 0x65,
+	0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x4a, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x34, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
+	0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78,
+	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x29, 0x0a, 0x03, 0x70, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x17, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72,
+	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x03, 0x70, 0x6f, 0x64, 0x12, 0x4d, 0x0a,
+	0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x31, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61,
+	0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x58, 0x0a, 0x0d,
+	0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69,
+	0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0d, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0x70, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x0c, 0x0a, 0x08, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x00, 0x12, 0x0b, 0x0a,
+	0x07, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x10, 0x0a, 0x12, 0x0e, 0x0a, 0x0a, 0x41, 0x63,
+	0x74, 0x69, 0x76, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x14, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x75,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x1e, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x76, 0x61, 0x63, 0x75,
+	0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x28, 0x12, 0x0f, 0x0a, 0x0b, 0x54, 0x65, 0x72, 0x6d, 0x69,
+	0x6e, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x32, 0x12, 0x0e, 0x0a, 0x0a, 0x54, 0x65, 0x72, 0x6d,
+	0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x10, 0x3c, 0x22, 0xa6, 0x01, 0x0a, 0x0b, 0x50, 0x6f, 0x64,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x59, 0x0a, 0x13, 0x72, 0x65, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61,
+	0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x13,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x3c, 0x0a, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70,
+	0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x61, 0x63, 0x68,
+	0x65, 0x64, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x73, 0x22, 0x97, 0x02, 0x0a, 0x09, 0x50, 0x6f, 0x64, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12,
+	0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74,
+	0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x03, 0x70, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01,
 	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e,
 	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x03, 0x70, 0x6f, 0x64,
-	0x12, 0x4d, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x31, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e,
-	0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72,
-	0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x65, 0x73,
-	0x6f, 0x75, 0x72, 0x63, 0x65, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12,
-	0x58, 0x0a, 0x0d, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73,
-	0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72,
-	0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61,
-	0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x0d, 0x73, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x73, 0x22, 0x70, 0x0a, 0x05, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x43, 0x72, 0x65, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x00,
-	0x12, 0x0b, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x6e, 0x64, 0x62, 0x79, 0x10, 0x0a, 0x12, 0x0e, 0x0a,
-	0x0a, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x14, 0x12, 0x0b, 0x0a,
-	0x07, 0x52, 0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x10, 0x1e, 0x12, 0x0e, 0x0a, 0x0a, 0x45, 0x76,
-	0x61, 0x63, 0x75, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x28, 0x12, 0x0f, 0x0a, 0x0b, 0x54, 0x65,
-	0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x10, 0x32, 0x12, 0x0e, 0x0a, 0x0a, 0x54,
-	0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x64, 0x10, 0x3c, 0x22, 0xa6, 0x01, 0x0a, 0x0b,
-	0x50, 0x6f, 0x64, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x59, 0x0a, 0x13, 0x72,
-	0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69,
-	0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
-	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x13, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61,
-	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x3c, 0x0a, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
-	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f,
-	0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74,
-	0x61, 0x63, 0x68, 0x65, 0x64, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x07, 0x76, 0x6f, 0x6c,
-	0x75, 0x6d, 0x65, 0x73, 0x22, 0x99, 0x01, 0x0a, 0x09, 0x50, 0x6f, 0x64, 0x43, 0x72, 0x65, 0x61,
-	0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66,
-	0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64,
-	0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x03, 0x70, 0x6f, 0x64, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61,
-	0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x64, 0x52, 0x03,
-	0x70, 0x6f, 0x64, 0x12, 0x3b, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x61, 0x70,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e,
-	0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x4d, 0x61, 0x70, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x61, 0x70,
-	0x22, 0x34, 0x0a, 0x0c, 0x50, 0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65,
-	0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65,
-	0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e,
-	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x34, 0x0a, 0x0c, 0x50, 0x6f, 0x64, 0x48, 0x69, 0x62,
-	0x65, 0x72, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65,
+	0x12, 0x3b, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69,
+	0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d,
+	0x61, 0x70, 0x52, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4d, 0x61, 0x70, 0x12, 0x34, 0x0a,
+	0x07, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x52, 0x07, 0x73, 0x65, 0x63, 0x72,
+	0x65, 0x74, 0x73, 0x12, 0x46, 0x0a, 0x10, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x50, 0x75, 0x6c, 0x6c,
+	0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x6b, 0x38, 0x73, 0x2e, 0x69, 0x6f, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x63, 0x6f, 0x72, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x52, 0x10, 0x69, 0x6d, 0x61, 0x67, 0x65,
+	0x50, 0x75, 0x6c, 0x6c, 0x53, 0x65, 0x63, 0x72, 0x65, 0x74, 0x73, 0x22, 0x34, 0x0a, 0x0c, 0x50,
+	0x6f, 0x64, 0x54, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70,
+	0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x22, 0x34, 0x0a, 0x0c, 0x50, 0x6f, 0x64, 0x48, 0x69, 0x62, 0x65, 0x72, 0x6e, 0x61, 0x74,
+	0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x33, 0x0a, 0x0b, 0x50, 0x6f, 0x64, 0x45, 0x76,
+	0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65,
 	0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70,
 	0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x82, 0x01, 0x0a,
 	0x0c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x22, 0x0a,
@@ -1614,7 +1999,7 @@ var file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDesc = []byte{
 	0x28, 0x09, 0x52, 0x11, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x65, 0x6e, 0x74,
 	0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x0d, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e,
 	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x6f,
-	0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x2a, 0xa5, 0x02, 0x0a, 0x0b,
+	0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x2a, 0xdb, 0x02, 0x0a, 0x0b,
 	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x55,
 	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x1d, 0x0a, 0x19,
 	0x46, 0x4f, 0x52, 0x4e, 0x41, 0x58, 0x5f, 0x43, 0x4f, 0x52, 0x45, 0x5f, 0x43, 0x4f, 0x4e, 0x46,
@@ -1625,34 +2010,45 @@ var file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDesc = []byte{
 	0x5f, 0x52, 0x45, 0x41, 0x44, 0x59, 0x10, 0xca, 0x01, 0x12, 0x0f, 0x0a, 0x0a, 0x4e, 0x4f, 0x44,
 	0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0xcb, 0x01, 0x12, 0x13, 0x0a, 0x0e, 0x4e, 0x4f,
 	0x44, 0x45, 0x5f, 0x46, 0x55, 0x4c, 0x4c, 0x5f, 0x53, 0x59, 0x4e, 0x43, 0x10, 0xcc, 0x01, 0x12,
-	0x0f, 0x0a, 0x0a, 0x50, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0xac, 0x02,
-	0x12, 0x12, 0x0a, 0x0d, 0x50, 0x4f, 0x44, 0x5f, 0x54, 0x45, 0x52, 0x4d, 0x49, 0x4e, 0x41, 0x54,
-	0x45, 0x10, 0xad, 0x02, 0x12, 0x12, 0x0a, 0x0d, 0x50, 0x4f, 0x44, 0x5f, 0x48, 0x49, 0x42, 0x45,
-	0x52, 0x4e, 0x41, 0x54, 0x45, 0x10, 0xae, 0x02, 0x12, 0x0e, 0x0a, 0x09, 0x50, 0x4f, 0x44, 0x5f,
-	0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0xaf, 0x02, 0x12, 0x11, 0x0a, 0x0c, 0x53, 0x45, 0x53, 0x53,
-	0x49, 0x4f, 0x4e, 0x5f, 0x4f, 0x50, 0x45, 0x4e, 0x10, 0x90, 0x03, 0x12, 0x12, 0x0a, 0x0d, 0x53,
-	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x10, 0x91, 0x03, 0x12,
-	0x12, 0x0a, 0x0d, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45,
-	0x10, 0x92, 0x03, 0x32, 0xf1, 0x01, 0x0a, 0x11, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f,
-	0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x7d, 0x0a, 0x0a, 0x67, 0x65, 0x74,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x34, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75,
-	0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e,
-	0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e,
-	0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x1a, 0x37, 0x2e,
+	0x0f, 0x0a, 0x0a, 0x4e, 0x4f, 0x44, 0x45, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x10, 0xcd, 0x01,
+	0x12, 0x0f, 0x0a, 0x0a, 0x50, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x10, 0xac,
+	0x02, 0x12, 0x12, 0x0a, 0x0d, 0x50, 0x4f, 0x44, 0x5f, 0x54, 0x45, 0x52, 0x4d, 0x49, 0x4e, 0x41,
+	0x54, 0x45, 0x10, 0xad, 0x02, 0x12, 0x12, 0x0a, 0x0d, 0x50, 0x4f, 0x44, 0x5f, 0x48, 0x49, 0x42,
+	0x45, 0x52, 0x4e, 0x41, 0x54, 0x45, 0x10, 0xae, 0x02, 0x12, 0x0e, 0x0a, 0x09, 0x50, 0x4f, 0x44,
+	0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0xaf, 0x02, 0x12, 0x11, 0x0a, 0x0c, 0x50, 0x4f, 0x44,
+	0x5f, 0x45, 0x56, 0x41, 0x43, 0x55, 0x41, 0x54, 0x45, 0x10, 0xb0, 0x02, 0x12, 0x11, 0x0a, 0x0c,
+	0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x4f, 0x50, 0x45, 0x4e, 0x10, 0x90, 0x03, 0x12,
+	0x12, 0x0a, 0x0d, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45,
+	0x10, 0x91, 0x03, 0x12, 0x12, 0x0a, 0x0d, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53,
+	0x54, 0x41, 0x54, 0x45, 0x10, 0x92, 0x03, 0x12, 0x10, 0x0a, 0x0b, 0x4d, 0x45, 0x53, 0x53, 0x41,
+	0x47, 0x45, 0x5f, 0x41, 0x43, 0x4b, 0x10, 0xf4, 0x03, 0x32, 0xec, 0x02, 0x0a, 0x11, 0x46, 0x6f,
+	0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x7d, 0x0a, 0x0a, 0x67, 0x65, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x34, 0x2e,
 	0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69,
 	0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x5d, 0x0a, 0x0a, 0x70, 0x75, 0x74, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x37, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72,
-	0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61,
-	0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f,
-	0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x39, 0x5a, 0x37, 0x63, 0x65, 0x6e, 0x74, 0x61,
-	0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2f, 0x66, 0x6f, 0x72,
-	0x6e, 0x61, 0x78, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x6c, 0x65, 0x73, 0x73, 0x2f, 0x70,
-	0x6b, 0x67, 0x2f, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x67, 0x72,
-	0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66,
+	0x69, 0x65, 0x72, 0x1a, 0x37, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69,
+	0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f,
+	0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61,
+	0x78, 0x43, 0x6f, 0x72, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x5d,
+	0x0a, 0x0a, 0x70, 0x75, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x37, 0x2e, 0x63,
+	0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f,
+	0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x2e, 0x46, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x43, 0x6f, 0x72, 0x65, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x79, 0x0a,
+	0x0a, 0x65, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x34, 0x2e, 0x63, 0x65,
+	0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e,
+	0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x45, 0x6e, 0x72, 0x6f, 0x6c, 0x6c, 0x6d, 0x65, 0x6e,
+	0x74, 0x1a, 0x35, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66,
+	0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x43, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x42, 0x39, 0x5a, 0x37, 0x63, 0x65, 0x6e, 0x74,
+	0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2f, 0x66, 0x6f,
+	0x72, 0x6e, 0x61, 0x78, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x6c, 0x65, 0x73, 0x73, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2f, 0x67,
+	0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1668,7 +2064,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_fornaxcore_grpc_fornaxcore_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
 var file_pkg_fornaxcore_grpc_fornaxcore_proto_goTypes = []interface{}{
 	(MessageType)(0),                // 0: centaurusinfra.io.fornaxcore.service.MessageType
 	(PodState_State)(0),             // 1: centaurusinfra.io.fornaxcore.service.PodState.State
@@ -1676,69 +2072,85 @@ var file_pkg_fornaxcore_grpc_fornaxcore_proto_goTypes = []interface{}{
 	(*FornaxCore)(nil),              // 3: centaurusinfra.io.fornaxcore.service.FornaxCore
 	(*FornaxCoreConfiguration)(nil), // 4: centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration
 	(*NodeIdentifier)(nil),          // 5: centaurusinfra.io.fornaxcore.service.NodeIdentifier
-	(*NodeRegistry)(nil),            // 6: centaurusinfra.io.fornaxcore.service.NodeRegistry
-	(*NodeConfiguration)(nil),       // 7: centaurusinfra.io.fornaxcore.service.NodeConfiguration
-	(*NodeReady)(nil),               // 8: centaurusinfra.io.fornaxcore.service.NodeReady
-	(*NodeState)(nil),               // 9: centaurusinfra.io.fornaxcore.service.NodeState
-	(*NodeFullSync)(nil),            // 10: centaurusinfra.io.fornaxcore.service.NodeFullSync
-	(*PodState)(nil),                // 11: centaurusinfra.io.fornaxcore.service.PodState
-	(*PodResource)(nil),             // 12: centaurusinfra.io.fornaxcore.service.PodResource
-	(*PodCreate)(nil),               // 13: centaurusinfra.io.fornaxcore.service.PodCreate
-	(*PodTerminate)(nil),            // 14: centaurusinfra.io.fornaxcore.service.PodTerminate
-	(*PodHibernate)(nil),            // 15: centaurusinfra.io.fornaxcore.service.PodHibernate
-	(*SessionState)(nil),            // 16: centaurusinfra.io.fornaxcore.service.SessionState
-	(*SessionOpen)(nil),             // 17: centaurusinfra.io.fornaxcore.service.SessionOpen
-	(*SessionClose)(nil),            // 18: centaurusinfra.io.fornaxcore.service.SessionClose
-	(*v1.Node)(nil),                 // 19: k8s.io.api.core.v1.Node
-	(*v1.Pod)(nil),                  // 20: k8s.io.api.core.v1.Pod
-	(*v1.ResourceQuotaStatus)(nil),  // 21: k8s.io.api.core.v1.ResourceQuotaStatus
-	(*v1.AttachedVolume)(nil),       // 22: k8s.io.api.core.v1.AttachedVolume
-	(*v1.ConfigMap)(nil),            // 23: k8s.io.api.core.v1.ConfigMap
-	(*empty.Empty)(nil),             // 24: google.protobuf.Empty
+	(*NodeEnrollment)(nil),          // 6: centaurusinfra.io.fornaxcore.service.NodeEnrollment
+	(*NodeCertificate)(nil),         // 7: centaurusinfra.io.fornaxcore.service.NodeCertificate
+	(*NodeRegistry)(nil),            // 8: centaurusinfra.io.fornaxcore.service.NodeRegistry
+	(*NodeConfiguration)(nil),       // 9: centaurusinfra.io.fornaxcore.service.NodeConfiguration
+	(*NodeReady)(nil),               // 10: centaurusinfra.io.fornaxcore.service.NodeReady
+	(*NodeState)(nil),               // 11: centaurusinfra.io.fornaxcore.service.NodeState
+	(*NodeFullSync)(nil),            // 12: centaurusinfra.io.fornaxcore.service.NodeFullSync
+	(*NodeEvent)(nil),               // 13: centaurusinfra.io.fornaxcore.service.NodeEvent
+	(*MessageAck)(nil),              // 14: centaurusinfra.io.fornaxcore.service.MessageAck
+	(*PodState)(nil),                // 15: centaurusinfra.io.fornaxcore.service.PodState
+	(*PodResource)(nil),             // 16: centaurusinfra.io.fornaxcore.service.PodResource
+	(*PodCreate)(nil),               // 17: centaurusinfra.io.fornaxcore.service.PodCreate
+	(*PodTerminate)(nil),            // 18: centaurusinfra.io.fornaxcore.service.PodTerminate
+	(*PodHibernate)(nil),            // 19: centaurusinfra.io.fornaxcore.service.PodHibernate
+	(*PodEvacuate)(nil),             // 20: centaurusinfra.io.fornaxcore.service.PodEvacuate
+	(*SessionState)(nil),            // 21: centaurusinfra.io.fornaxcore.service.SessionState
+	(*SessionOpen)(nil),             // 22: centaurusinfra.io.fornaxcore.service.SessionOpen
+	(*SessionClose)(nil),            // 23: centaurusinfra.io.fornaxcore.service.SessionClose
+	(*v1.Node)(nil),                 // 24: k8s.io.api.core.v1.Node
+	(*v1.Pod)(nil),                  // 25: k8s.io.api.core.v1.Pod
+	(*v1.Event)(nil),                // 26: k8s.io.api.core.v1.Event
+	(*v1.ResourceQuotaStatus)(nil),  // 27: k8s.io.api.core.v1.ResourceQuotaStatus
+	(*v1.AttachedVolume)(nil),       // 28: k8s.io.api.core.v1.AttachedVolume
+	(*v1.ConfigMap)(nil),            // 29: k8s.io.api.core.v1.ConfigMap
+	(*v1.Secret)(nil),               // 30: k8s.io.api.core.v1.Secret
+	(*empty.Empty)(nil),             // 31: google.protobuf.Empty
 }
 var file_pkg_fornaxcore_grpc_fornaxcore_proto_depIdxs = []int32{
 	5,  // 0: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeIdentifier:type_name -> centaurusinfra.io.fornaxcore.service.NodeIdentifier
 	0,  // 1: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.messageType:type_name -> centaurusinfra.io.fornaxcore.service.MessageType
 	4,  // 2: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.fornaxCoreConfiguration:type_name -> centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration
-	7,  // 3: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeConfiguration:type_name -> centaurusinfra.io.fornaxcore.service.NodeConfiguration
-	6,  // 4: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeRegistry:type_name -> centaurusinfra.io.fornaxcore.service.NodeRegistry
-	8,  // 5: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeReady:type_name -> centaurusinfra.io.fornaxcore.service.NodeReady
-	9,  // 6: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeState:type_name -> centaurusinfra.io.fornaxcore.service.NodeState
-	10, // 7: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeFullSync:type_name -> centaurusinfra.io.fornaxcore.service.NodeFullSync
-	13, // 8: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podCreate:type_name -> centaurusinfra.io.fornaxcore.service.PodCreate
-	14, // 9: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podTerminate:type_name -> centaurusinfra.io.fornaxcore.service.PodTerminate
-	15, // 10: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podHibernate:type_name -> centaurusinfra.io.fornaxcore.service.PodHibernate
-	11, // 11: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podState:type_name -> centaurusinfra.io.fornaxcore.service.PodState
-	17, // 12: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionOpen:type_name -> centaurusinfra.io.fornaxcore.service.SessionOpen
-	18, // 13: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionClose:type_name -> centaurusinfra.io.fornaxcore.service.SessionClose
-	16, // 14: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionState:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
-	3,  // 15: centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration.primary:type_name -> centaurusinfra.io.fornaxcore.service.FornaxCore
-	3,  // 16: centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration.standbys:type_name -> centaurusinfra.io.fornaxcore.service.FornaxCore
-	19, // 17: centaurusinfra.io.fornaxcore.service.NodeRegistry.node:type_name -> k8s.io.api.core.v1.Node
-	19, // 18: centaurusinfra.io.fornaxcore.service.NodeConfiguration.node:type_name -> k8s.io.api.core.v1.Node
-	20, // 19: centaurusinfra.io.fornaxcore.service.NodeConfiguration.daemonPods:type_name -> k8s.io.api.core.v1.Pod
-	19, // 20: centaurusinfra.io.fornaxcore.service.NodeReady.node:type_name -> k8s.io.api.core.v1.Node
-	11, // 21: centaurusinfra.io.fornaxcore.service.NodeReady.podStates:type_name -> centaurusinfra.io.fornaxcore.service.PodState
-	16, // 22: centaurusinfra.io.fornaxcore.service.NodeReady.sessionStates:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
-	19, // 23: centaurusinfra.io.fornaxcore.service.NodeState.node:type_name -> k8s.io.api.core.v1.Node
-	11, // 24: centaurusinfra.io.fornaxcore.service.NodeState.podStates:type_name -> centaurusinfra.io.fornaxcore.service.PodState
-	1,  // 25: centaurusinfra.io.fornaxcore.service.PodState.state:type_name -> centaurusinfra.io.fornaxcore.service.PodState.State
-	20, // 26: centaurusinfra.io.fornaxcore.service.PodState.pod:type_name -> k8s.io.api.core.v1.Pod
-	12, // 27: centaurusinfra.io.fornaxcore.service.PodState.resource:type_name -> centaurusinfra.io.fornaxcore.service.PodResource
-	16, // 28: centaurusinfra.io.fornaxcore.service.PodState.sessionStates:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
-	21, // 29: centaurusinfra.io.fornaxcore.service.PodResource.resourceQuotaStatus:type_name -> k8s.io.api.core.v1.ResourceQuotaStatus
-	22, // 30: centaurusinfra.io.fornaxcore.service.PodResource.volumes:type_name -> k8s.io.api.core.v1.AttachedVolume
-	20, // 31: centaurusinfra.io.fornaxcore.service.PodCreate.pod:type_name -> k8s.io.api.core.v1.Pod
-	23, // 32: centaurusinfra.io.fornaxcore.service.PodCreate.configMap:type_name -> k8s.io.api.core.v1.ConfigMap
-	5,  // 33: centaurusinfra.io.fornaxcore.service.FornaxCoreService.getMessage:input_type -> centaurusinfra.io.fornaxcore.service.NodeIdentifier
-	2,  // 34: centaurusinfra.io.fornaxcore.service.FornaxCoreService.putMessage:input_type -> centaurusinfra.io.fornaxcore.service.FornaxCoreMessage
-	2,  // 35: centaurusinfra.io.fornaxcore.service.FornaxCoreService.getMessage:output_type -> centaurusinfra.io.fornaxcore.service.FornaxCoreMessage
-	24, // 36: centaurusinfra.io.fornaxcore.service.FornaxCoreService.putMessage:output_type -> google.protobuf.Empty
-	35, // [35:37] is the sub-list for method output_type
-	33, // [33:35] is the sub-list for method input_type
-	33, // [33:33] is the sub-list for extension type_name
-	33, // [33:33] is the sub-list for extension extendee
-	0,  // [0:33] is the sub-list for field type_name
+	9,  // 3: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeConfiguration:type_name -> centaurusinfra.io.fornaxcore.service.NodeConfiguration
+	8,  // 4: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeRegistry:type_name -> centaurusinfra.io.fornaxcore.service.NodeRegistry
+	10, // 5: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeReady:type_name -> centaurusinfra.io.fornaxcore.service.NodeReady
+	11, // 6: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeState:type_name -> centaurusinfra.io.fornaxcore.service.NodeState
+	12, // 7: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeFullSync:type_name -> centaurusinfra.io.fornaxcore.service.NodeFullSync
+	13, // 8: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.nodeEvent:type_name -> centaurusinfra.io.fornaxcore.service.NodeEvent
+	17, // 9: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podCreate:type_name -> centaurusinfra.io.fornaxcore.service.PodCreate
+	18, // 10: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podTerminate:type_name -> centaurusinfra.io.fornaxcore.service.PodTerminate
+	19, // 11: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podHibernate:type_name -> centaurusinfra.io.fornaxcore.service.PodHibernate
+	15, // 12: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podState:type_name -> centaurusinfra.io.fornaxcore.service.PodState
+	20, // 13: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.podEvacuate:type_name -> centaurusinfra.io.fornaxcore.service.PodEvacuate
+	22, // 14: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionOpen:type_name -> centaurusinfra.io.fornaxcore.service.SessionOpen
+	23, // 15: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionClose:type_name -> centaurusinfra.io.fornaxcore.service.SessionClose
+	21, // 16: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.sessionState:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
+	14, // 17: centaurusinfra.io.fornaxcore.service.FornaxCoreMessage.messageAck:type_name -> centaurusinfra.io.fornaxcore.service.MessageAck
+	3,  // 18: centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration.primary:type_name -> centaurusinfra.io.fornaxcore.service.FornaxCore
+	3,  // 19: centaurusinfra.io.fornaxcore.service.FornaxCoreConfiguration.standbys:type_name -> centaurusinfra.io.fornaxcore.service.FornaxCore
+	5,  // 20: centaurusinfra.io.fornaxcore.service.NodeEnrollment.nodeIdentifier:type_name -> centaurusinfra.io.fornaxcore.service.NodeIdentifier
+	24, // 21: centaurusinfra.io.fornaxcore.service.NodeRegistry.node:type_name -> k8s.io.api.core.v1.Node
+	24, // 22: centaurusinfra.io.fornaxcore.service.NodeConfiguration.node:type_name -> k8s.io.api.core.v1.Node
+	25, // 23: centaurusinfra.io.fornaxcore.service.NodeConfiguration.daemonPods:type_name -> k8s.io.api.core.v1.Pod
+	24, // 24: centaurusinfra.io.fornaxcore.service.NodeReady.node:type_name -> k8s.io.api.core.v1.Node
+	15, // 25: centaurusinfra.io.fornaxcore.service.NodeReady.podStates:type_name -> centaurusinfra.io.fornaxcore.service.PodState
+	21, // 26: centaurusinfra.io.fornaxcore.service.NodeReady.sessionStates:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
+	24, // 27: centaurusinfra.io.fornaxcore.service.NodeState.node:type_name -> k8s.io.api.core.v1.Node
+	15, // 28: centaurusinfra.io.fornaxcore.service.NodeState.podStates:type_name -> centaurusinfra.io.fornaxcore.service.PodState
+	26, // 29: centaurusinfra.io.fornaxcore.service.NodeEvent.event:type_name -> k8s.io.api.core.v1.Event
+	1,  // 30: centaurusinfra.io.fornaxcore.service.PodState.state:type_name -> centaurusinfra.io.fornaxcore.service.PodState.State
+	25, // 31: centaurusinfra.io.fornaxcore.service.PodState.pod:type_name -> k8s.io.api.core.v1.Pod
+	16, // 32: centaurusinfra.io.fornaxcore.service.PodState.resource:type_name -> centaurusinfra.io.fornaxcore.service.PodResource
+	21, // 33: centaurusinfra.io.fornaxcore.service.PodState.sessionStates:type_name -> centaurusinfra.io.fornaxcore.service.SessionState
+	27, // 34: centaurusinfra.io.fornaxcore.service.PodResource.resourceQuotaStatus:type_name -> k8s.io.api.core.v1.ResourceQuotaStatus
+	28, // 35: centaurusinfra.io.fornaxcore.service.PodResource.volumes:type_name -> k8s.io.api.core.v1.AttachedVolume
+	25, // 36: centaurusinfra.io.fornaxcore.service.PodCreate.pod:type_name -> k8s.io.api.core.v1.Pod
+	29, // 37: centaurusinfra.io.fornaxcore.service.PodCreate.configMap:type_name -> k8s.io.api.core.v1.ConfigMap
+	30, // 38: centaurusinfra.io.fornaxcore.service.PodCreate.secrets:type_name -> k8s.io.api.core.v1.Secret
+	30, // 39: centaurusinfra.io.fornaxcore.service.PodCreate.imagePullSecrets:type_name -> k8s.io.api.core.v1.Secret
+	5,  // 40: centaurusinfra.io.fornaxcore.service.FornaxCoreService.getMessage:input_type -> centaurusinfra.io.fornaxcore.service.NodeIdentifier
+	2,  // 41: centaurusinfra.io.fornaxcore.service.FornaxCoreService.putMessage:input_type -> centaurusinfra.io.fornaxcore.service.FornaxCoreMessage
+	6,  // 42: centaurusinfra.io.fornaxcore.service.FornaxCoreService.enrollNode:input_type -> centaurusinfra.io.fornaxcore.service.NodeEnrollment
+	2,  // 43: centaurusinfra.io.fornaxcore.service.FornaxCoreService.getMessage:output_type -> centaurusinfra.io.fornaxcore.service.FornaxCoreMessage
+	31, // 44: centaurusinfra.io.fornaxcore.service.FornaxCoreService.putMessage:output_type -> google.protobuf.Empty
+	7,  // 45: centaurusinfra.io.fornaxcore.service.FornaxCoreService.enrollNode:output_type -> centaurusinfra.io.fornaxcore.service.NodeCertificate
+	43, // [43:46] is the sub-list for method output_type
+	40, // [40:43] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_pkg_fornaxcore_grpc_fornaxcore_proto_init() }
@@ -1796,7 +2208,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeRegistry); i {
+			switch v := v.(*NodeEnrollment); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1808,7 +2220,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeConfiguration); i {
+			switch v := v.(*NodeCertificate); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1820,7 +2232,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeReady); i {
+			switch v := v.(*NodeRegistry); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1832,7 +2244,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeState); i {
+			switch v := v.(*NodeConfiguration); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1844,7 +2256,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*NodeFullSync); i {
+			switch v := v.(*NodeReady); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1856,7 +2268,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodState); i {
+			switch v := v.(*NodeState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1868,7 +2280,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodResource); i {
+			switch v := v.(*NodeFullSync); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1880,7 +2292,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodCreate); i {
+			switch v := v.(*NodeEvent); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1892,7 +2304,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodTerminate); i {
+			switch v := v.(*MessageAck); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1904,7 +2316,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PodHibernate); i {
+			switch v := v.(*PodState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1916,7 +2328,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionState); i {
+			switch v := v.(*PodResource); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1928,7 +2340,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SessionOpen); i {
+			switch v := v.(*PodCreate); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1940,6 +2352,66 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			}
 		}
 		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodTerminate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodHibernate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PodEvacuate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionState); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SessionOpen); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_fornaxcore_grpc_fornaxcore_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SessionClose); i {
 			case 0:
 				return &v.state
@@ -1959,13 +2431,16 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 		(*FornaxCoreMessage_NodeReady)(nil),
 		(*FornaxCoreMessage_NodeState)(nil),
 		(*FornaxCoreMessage_NodeFullSync)(nil),
+		(*FornaxCoreMessage_NodeEvent)(nil),
 		(*FornaxCoreMessage_PodCreate)(nil),
 		(*FornaxCoreMessage_PodTerminate)(nil),
 		(*FornaxCoreMessage_PodHibernate)(nil),
 		(*FornaxCoreMessage_PodState)(nil),
+		(*FornaxCoreMessage_PodEvacuate)(nil),
 		(*FornaxCoreMessage_SessionOpen)(nil),
 		(*FornaxCoreMessage_SessionClose)(nil),
 		(*FornaxCoreMessage_SessionState)(nil),
+		(*FornaxCoreMessage_MessageAck)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1973,7 +2448,7 @@ func file_pkg_fornaxcore_grpc_fornaxcore_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_fornaxcore_grpc_fornaxcore_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   17,
+			NumMessages:   22,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -25,6 +25,7 @@ const _ = grpc.SupportPackageIsVersion7
 type FornaxCoreServiceClient interface {
 	GetMessage(ctx context.Context, in *NodeIdentifier, opts ...grpc.CallOption) (FornaxCoreService_GetMessageClient, error)
 	PutMessage(ctx context.Context, in *FornaxCoreMessage, opts ...grpc.CallOption) (*empty.Empty, error)
+	EnrollNode(ctx context.Context, in *NodeEnrollment, opts ...grpc.CallOption) (*NodeCertificate, error)
 }
 
 type fornaxCoreServiceClient struct {
@@ -76,12 +77,22 @@ func (c *fornaxCoreServiceClient) PutMessage(ctx context.Context, in *FornaxCore
 	return out, nil
 }
 
+func (c *fornaxCoreServiceClient) EnrollNode(ctx context.Context, in *NodeEnrollment, opts ...grpc.CallOption) (*NodeCertificate, error) {
+	out := new(NodeCertificate)
+	err := c.cc.Invoke(ctx, "/centaurusinfra.io.fornaxcore.service.FornaxCoreService/enrollNode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FornaxCoreServiceServer is the server API for FornaxCoreService service.
 // All implementations must embed UnimplementedFornaxCoreServiceServer
 // for forward compatibility
 type FornaxCoreServiceServer interface {
 	GetMessage(*NodeIdentifier, FornaxCoreService_GetMessageServer) error
 	PutMessage(context.Context, *FornaxCoreMessage) (*empty.Empty, error)
+	EnrollNode(context.Context, *NodeEnrollment) (*NodeCertificate, error)
 	mustEmbedUnimplementedFornaxCoreServiceServer()
 }
 
@@ -95,6 +106,9 @@ func (UnimplementedFornaxCoreServiceServer) GetMessage(*NodeIdentifier, FornaxCo
 func (UnimplementedFornaxCoreServiceServer) PutMessage(context.Context, *FornaxCoreMessage) (*empty.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PutMessage not implemented")
 }
+func (UnimplementedFornaxCoreServiceServer) EnrollNode(context.Context, *NodeEnrollment) (*NodeCertificate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollNode not implemented")
+}
 func (UnimplementedFornaxCoreServiceServer) mustEmbedUnimplementedFornaxCoreServiceServer() {}
 
 // UnsafeFornaxCoreServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -147,6 +161,24 @@ func _FornaxCoreService_PutMessage_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _FornaxCoreService_EnrollNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeEnrollment)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FornaxCoreServiceServer).EnrollNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/centaurusinfra.io.fornaxcore.service.FornaxCoreService/enrollNode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FornaxCoreServiceServer).EnrollNode(ctx, req.(*NodeEnrollment))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // FornaxCoreService_ServiceDesc is the grpc.ServiceDesc for FornaxCoreService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -158,6 +190,10 @@ var FornaxCoreService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "putMessage",
 			Handler:    _FornaxCoreService_PutMessage_Handler,
 		},
+		{
+			MethodName: "enrollNode",
+			Handler:    _FornaxCoreService_EnrollNode_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
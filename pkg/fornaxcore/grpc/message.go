@@ -0,0 +1,40 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+// AckRequired tell if a fornaxcore message must be acknowledged by node,
+// fornaxcore keep these messages until node ack them and resend them when node reconnect, node handle them once by message identifier
+func AckRequired(messageType MessageType) bool {
+	switch messageType {
+	case MessageType_POD_CREATE, MessageType_POD_TERMINATE, MessageType_SESSION_OPEN, MessageType_SESSION_CLOSE:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewMessageAck build a message to acknowledge fornaxcore messages with these identifiers
+func NewMessageAck(messageIdentifiers ...string) *FornaxCoreMessage {
+	return &FornaxCoreMessage{
+		MessageType: MessageType_MESSAGE_ACK,
+		MessageBody: &FornaxCoreMessage_MessageAck{
+			MessageAck: &MessageAck{
+				MessageIdentifiers: messageIdentifiers,
+			},
+		},
+	}
+}
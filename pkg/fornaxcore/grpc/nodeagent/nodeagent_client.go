@@ -40,9 +40,12 @@ type MessageDispatcher interface {
 type NodeAgentClient interface {
 	MessageDispatcher
 	FullSyncNode(nodeId string) error
+	ConfigureNode(nodeId string, node *v1.Node, daemons []*v1.Pod) error
 	CreatePod(nodeId string, pod *v1.Pod) error
 	TerminatePod(nodeId string, pod *v1.Pod) error
 	HibernatePod(nodeId string, pod *v1.Pod) error
+	EvacuatePod(nodeId string, pod *v1.Pod) error
 	OpenSession(nodeId string, pod *v1.Pod, session *fornaxv1.ApplicationSession) error
 	CloseSession(nodeId string, pod *v1.Pod, session *fornaxv1.ApplicationSession) error
+	ForgetNode(nodeId string)
 }
@@ -19,16 +19,23 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
 	"sync"
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	default_config "centaurusinfra.io/fornax-serverless/pkg/config"
 	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
@@ -49,30 +56,68 @@ type FornaxCoreServer interface {
 
 var _ FornaxCoreServer = &grpcServer{}
 
+// nodeMessage is a message put by node, handler close done after message is processed
+type nodeMessage struct {
+	message *fornaxcore_grpc.FornaxCoreMessage
+	err     error
+	done    chan struct{}
+}
+
 type grpcServer struct {
 	sync.RWMutex
 	fornaxcore_grpc.UnimplementedFornaxCoreServiceServer
-	nodeMonitor             ie.NodeMonitorInterface
-	nodeOutgoingChans       map[string]chan<- *fornaxcore_grpc.FornaxCoreMessage
-	nodeIncommingChans      map[string]chan *fornaxcore_grpc.FornaxCoreMessage
+	nodeMonitor       ie.NodeMonitorInterface
+	nodeOutgoingChans map[string]chan<- *fornaxcore_grpc.FornaxCoreMessage
+	// closed when node stream is gone, outgoing channels are never closed as other goroutines could still send on them
+	nodeStreamDones map[string]chan struct{}
+	// per node message sequence and unacked messages, kept across node reconnects
+	nodeOutboxes            map[string]*nodeOutbox
+	nodeIncommingChans      map[string]chan *nodeMessage
 	nodeIncommingChansMutex sync.Mutex
-	nodeMessageHandlerChans []chan *fornaxcore_grpc.FornaxCoreMessage
+	nodeMessageHandlerChans []chan *nodeMessage
+	// standby fornaxcore keep node connections but do not handle node messages until it become primary
+	standby                 bool
+	fornaxCoreConfiguration *fornaxcore_grpc.FornaxCoreMessage
+	podConfigProvider       ie.PodConfigProviderInterface
+	// verify node identity and issue node certificates, it's nil if node grpc channel is not secured
+	nodeCA *nodeCertificateAuthority
+	// nodes known by fornaxcore, a token not bound to a node can not enroll a known node, node outboxes are saved on nodes
+	nodeStore fornaxstore.ApiStorageInterface
 }
 
-func (g *grpcServer) RunGrpcServer(ctx context.Context, nodeMonitor ie.NodeMonitorInterface, port int, certFile, keyFile string) error {
+// RunGrpcServer start node agent grpc server, node channel is secured with mutual tls if tlsConfig is provided
+func (g *grpcServer) RunGrpcServer(ctx context.Context, nodeMonitor ie.NodeMonitorInterface, port int, tlsConfig *NodeTLSConfig) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", port))
 	if err != nil {
 		klog.ErrorS(err, "Fornaxcore grpc server failed to listen on port:", port)
 		return err
 	}
+	if err := g.ServeGrpcServer(ctx, nodeMonitor, lis, tlsConfig); err != nil {
+		lis.Close()
+		return err
+	}
+	return nil
+}
+
+// ServeGrpcServer start node agent grpc server on given listener, server is stopped when ctx is done
+func (g *grpcServer) ServeGrpcServer(ctx context.Context, nodeMonitor ie.NodeMonitorInterface, lis net.Listener, tlsConfig *NodeTLSConfig) error {
 	var opts []grpc.ServerOption
-	if certFile != "" && keyFile != "" {
-		creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	if tlsConfig != nil && tlsConfig.CertFile != "" && tlsConfig.KeyFile != "" {
+		if tlsConfig.ClientCAFile == "" {
+			return errors.New("node client ca file is required to verify node certificates")
+		}
+		ca, err := newNodeCertificateAuthority(tlsConfig)
 		if err != nil {
-			klog.ErrorS(err, "Fornaxcore grpc server failed to generate credentials", "certFile", certFile, "keyFile", keyFile)
+			klog.ErrorS(err, "Fornaxcore grpc server failed to load node certificate authority", "caFile", tlsConfig.ClientCAFile)
 			return err
 		}
-		opts = []grpc.ServerOption{grpc.Creds(creds)}
+		serverTLSConfig, err := newNodeServerTLSConfig(tlsConfig, ca)
+		if err != nil {
+			klog.ErrorS(err, "Fornaxcore grpc server failed to generate credentials", "certFile", tlsConfig.CertFile, "keyFile", tlsConfig.KeyFile)
+			return err
+		}
+		g.nodeCA = ca
+		opts = []grpc.ServerOption{grpc.Creds(credentials.NewTLS(serverTLSConfig))}
 	}
 
 	// start node agent grpc server
@@ -80,20 +125,25 @@ func (g *grpcServer) RunGrpcServer(ctx context.Context, nodeMonitor ie.NodeMonit
 	grpcServer := grpc.NewServer(opts...)
 	fornaxcore_grpc.RegisterFornaxCoreServiceServer(grpcServer, g)
 	go func() {
-		err = grpcServer.Serve(lis)
+		err := grpcServer.Serve(lis)
 		if err != nil {
 			klog.ErrorS(err, "Fornaxcore grpc server stopped to serve")
 		}
 	}()
+	go func() {
+		<-ctx.Done()
+		grpcServer.Stop()
+	}()
 
 	for _, v := range g.nodeMessageHandlerChans {
-		go func(ch chan *fornaxcore_grpc.FornaxCoreMessage) {
+		go func(ch chan *nodeMessage) {
 			for {
 				select {
 				case <-ctx.Done():
 					return
 				case msg := <-ch:
-					g.handleMessages(msg)
+					msg.err = g.handleMessages(msg.message)
+					close(msg.done)
 				}
 			}
 		}(v)
@@ -107,27 +157,137 @@ func (g *grpcServer) enlistNode(node string, ch chan<- *fornaxcore_grpc.FornaxCo
 	if _, ok := g.nodeOutgoingChans[node]; ok {
 		return fmt.Errorf("node %s already has channel", node)
 	}
-	g.nodeMonitor.OnNodeConnect(node)
 	g.nodeOutgoingChans[node] = ch
+	g.nodeStreamDones[node] = make(chan struct{})
+	// tell node which fornaxcore is primary and which are standbys
+	if g.fornaxCoreConfiguration != nil {
+		ch <- proto.Clone(g.fornaxCoreConfiguration).(*fornaxcore_grpc.FornaxCoreMessage)
+	}
+	if !g.standby {
+		if err := g.nodeMonitor.OnNodeConnect(node); err == nodeagent.NodeRevisionOutOfOrderError {
+			ch <- NewFullSyncRequest()
+		}
+	}
 	return nil
 }
 
 func (g *grpcServer) delistNode(node string) {
 	g.Lock()
 	defer g.Unlock()
-	g.nodeMonitor.OnNodeDisconnect(node)
-	close(g.nodeOutgoingChans[node])
+	if !g.standby {
+		g.nodeMonitor.OnNodeDisconnect(node)
+	}
+	// tell goroutines waiting to send to node that stream is gone, do not close outgoing channel they send on
+	if done, found := g.nodeStreamDones[node]; found {
+		close(done)
+	}
+	delete(g.nodeStreamDones, node)
 	delete(g.nodeOutgoingChans, node)
 }
 
+// SetStandby make fornaxcore grpc server work as standby which keep node connections but drop node messages,
+// when standby become primary, it ask all connected nodes to full sync to rebuild node, pod and session state,
+// node outboxes are restored from node store again, messages not acked by nodes before failover are reconciled by full sync
+func (g *grpcServer) SetStandby(standby bool) {
+	g.Lock()
+	defer g.Unlock()
+	if g.standby == standby {
+		return
+	}
+	g.standby = standby
+	if !standby {
+		g.nodeOutboxes = make(map[string]*nodeOutbox)
+		for node, ch := range g.nodeOutgoingChans {
+			klog.InfoS("Request node full sync after became primary", "node", node)
+			g.nodeMonitor.OnNodeConnect(node)
+			trySendNodeMessage(node, ch, NewFullSyncRequest())
+		}
+	}
+}
+
+// SetFornaxCoreConfiguration save primary and standby fornaxcore endpoints and advertise them to all connected nodes,
+// node agents use it to connect standbys and reconnect to new primary
+func (g *grpcServer) SetFornaxCoreConfiguration(primary string, standbys []string) {
+	standbyCores := []*fornaxcore_grpc.FornaxCore{}
+	for _, v := range standbys {
+		standbyCores = append(standbyCores, &fornaxcore_grpc.FornaxCore{Ip: v, Identifier: v})
+	}
+	msg := &fornaxcore_grpc.FornaxCoreMessage{
+		MessageType: fornaxcore_grpc.MessageType_FORNAX_CORE_CONFIGURATION,
+		MessageBody: &fornaxcore_grpc.FornaxCoreMessage_FornaxCoreConfiguration{
+			FornaxCoreConfiguration: &fornaxcore_grpc.FornaxCoreConfiguration{
+				Primary:  &fornaxcore_grpc.FornaxCore{Ip: primary, Identifier: primary},
+				Standbys: standbyCores,
+			},
+		},
+	}
+
+	g.Lock()
+	defer g.Unlock()
+	g.fornaxCoreConfiguration = msg
+	for node, ch := range g.nodeOutgoingChans {
+		klog.InfoS("Advertise fornaxcore configuration to node", "node", node, "primary", primary, "standbys", standbys)
+		trySendNodeMessage(node, ch, proto.Clone(msg).(*fornaxcore_grpc.FornaxCoreMessage))
+	}
+}
+
+// trySendNodeMessage send message without blocking, it's called with server lock held,
+// a node whose outgoing channel is full miss this message, it still report state periodically and get configuration when it reconnect
+func trySendNodeMessage(node string, ch chan<- *fornaxcore_grpc.FornaxCoreMessage, msg *fornaxcore_grpc.FornaxCoreMessage) {
+	select {
+	case ch <- msg:
+	default:
+		klog.Warningf("Node %s outgoing channel is full, skip message %s", node, msg.GetMessageType())
+	}
+}
+
+// SetPodConfigProvider set provider of config data and secret data sent to node with pod
+func (g *grpcServer) SetPodConfigProvider(provider ie.PodConfigProviderInterface) {
+	g.Lock()
+	defer g.Unlock()
+	g.podConfigProvider = provider
+}
+
+// SetNodeStore set store of nodes, it's used to check if an enrolling node is a known node, and to save node outboxes,
+// it should be called before grpc server start
+func (g *grpcServer) SetNodeStore(nodeStore fornaxstore.ApiStorageInterface) {
+	g.Lock()
+	defer g.Unlock()
+	g.nodeStore = nodeStore
+}
+
+func (g *grpcServer) isStandby() bool {
+	g.RLock()
+	defer g.RUnlock()
+	return g.standby
+}
+
 func (g *grpcServer) GetMessage(identifier *fornaxcore_grpc.NodeIdentifier, server fornaxcore_grpc.FornaxCoreService_GetMessageServer) error {
-	var messageSeq int64 = 0
+	if err := g.authenticateNode(server.Context(), identifier); err != nil {
+		klog.ErrorS(err, "Rejected GetMessage stream connection from unauthenticated node", "node", identifier)
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
 	ch := make(chan *fornaxcore_grpc.FornaxCoreMessage, NodeOutgoingChanBufferSize)
 	if err := g.enlistNode(identifier.GetIdentifier(), ch); err != nil {
 		close(ch)
 		return fmt.Errorf("Fornax core has established channel with this node: %s", identifier)
 	}
 
+	// resend messages node did not ack before it disconnected, node skip messages it has handled,
+	// standby does not resend messages, they are resent when it become primary
+	outbox := g.getNodeOutbox(identifier.GetIdentifier())
+	if !g.isStandby() {
+		for _, msg := range outbox.unackedMessages() {
+			klog.V(5).InfoS("Resend unacked message to node", "node", identifier, "msgId", msg.GetMessageIdentifier(), "msgType", msg.GetMessageType())
+			msg.NodeIdentifier = identifier
+			if err := server.Send(msg); err != nil {
+				klog.ErrorS(err, "Failed to resend message via GetMessage stream connection", "node", identifier)
+				g.delistNode(identifier.GetIdentifier())
+				return err
+			}
+		}
+	}
+
 	chDone := server.Context().Done()
 	for {
 		select {
@@ -135,9 +295,10 @@ func (g *grpcServer) GetMessage(identifier *fornaxcore_grpc.NodeIdentifier, serv
 			g.delistNode(identifier.GetIdentifier())
 			return nil
 		case msg := <-ch:
-			messageSeq += 1
-			seq := fmt.Sprintf("%d", messageSeq)
-			msg.MessageIdentifier = seq
+			// messages sent without dispatcher, e.g. fornaxcore configuration, are numbered here
+			if len(msg.GetMessageIdentifier()) == 0 {
+				outbox.put(msg)
+			}
 			msg.NodeIdentifier = identifier
 			if err := server.Send(msg); err != nil {
 				klog.ErrorS(err, "Failed to send message via GetMessage stream connection", "node", identifier)
@@ -150,7 +311,7 @@ func (g *grpcServer) GetMessage(identifier *fornaxcore_grpc.NodeIdentifier, serv
 
 // get handeller channel to handle incomming message from this node, if not found,
 // randomly assign one channel in nodeMessageHandlerChans to this node and save it for following incomming messages from this node
-func (g *grpcServer) getNodeMessageHandlerChannel(nodeId string) chan *fornaxcore_grpc.FornaxCoreMessage {
+func (g *grpcServer) getNodeMessageHandlerChannel(nodeId string) chan *nodeMessage {
 	g.nodeIncommingChansMutex.Lock()
 	defer g.nodeIncommingChansMutex.Unlock()
 	if messageCh, found := g.nodeIncommingChans[nodeId]; found {
@@ -163,14 +324,90 @@ func (g *grpcServer) getNodeMessageHandlerChannel(nodeId string) chan *fornaxcor
 	}
 }
 
-// PutMessage send node's message to handler to process message and return
+// PutMessage send node's message to handler and return after handler processed it, messages of a node are processed in order,
+// node get an error if message failed to be processed or call is cancelled before message is processed,
+// standby drop node messages without error, node send same messages to primary
 func (g *grpcServer) PutMessage(ctx context.Context, message *fornaxcore_grpc.FornaxCoreMessage) (*empty.Empty, error) {
+	if err := g.authenticateNode(ctx, message.GetNodeIdentifier()); err != nil {
+		klog.ErrorS(err, "Rejected message from unauthenticated node", "node", message.GetNodeIdentifier(), "msgType", message.GetMessageType())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
 	messageCh := g.getNodeMessageHandlerChannel(message.GetNodeIdentifier().GetIdentifier())
-	messageCh <- message
+	msg := &nodeMessage{message: message, done: make(chan struct{})}
+	select {
+	case messageCh <- msg:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	select {
+	case <-msg.done:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if msg.err != nil {
+		return nil, status.Error(codes.Internal, msg.err.Error())
+	}
 	return &emptypb.Empty{}, nil
 }
 
-func (g *grpcServer) handleMessages(message *fornaxcore_grpc.FornaxCoreMessage) {
+// EnrollNode issue a node client certificate to node which present a valid bootstrap token,
+// node use this certificate to connect fornaxcore afterwards
+func (g *grpcServer) EnrollNode(ctx context.Context, enrollment *fornaxcore_grpc.NodeEnrollment) (*fornaxcore_grpc.NodeCertificate, error) {
+	nodeName := enrollment.GetNodeIdentifier().GetIdentifier()
+	if g.nodeCA == nil {
+		return nil, status.Error(codes.FailedPrecondition, "fornaxcore node grpc server is not secured, node enrollment is not needed")
+	}
+	if len(nodeName) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "node identifier is required")
+	}
+	bound, err := g.nodeCA.verifyBootstrapToken(enrollment.GetBootstrapToken(), nodeName)
+	if err != nil {
+		klog.ErrorS(err, "Rejected node enrollment with invalid bootstrap token", "node", enrollment.GetNodeIdentifier())
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	// a connected node has a valid certificate already, do not issue another identity of it
+	g.RLock()
+	_, connected := g.nodeOutgoingChans[nodeName]
+	nodeStore := g.nodeStore
+	g.RUnlock()
+	if connected {
+		klog.InfoS("Rejected enrollment of a connected node", "node", enrollment.GetNodeIdentifier())
+		return nil, status.Error(codes.AlreadyExists, fmt.Sprintf("node %s is connected", nodeName))
+	}
+	// a token shared by nodes can only enroll new nodes, a known node re-enroll with a token bound to it
+	if !bound {
+		if nodeStore == nil {
+			return nil, status.Error(codes.FailedPrecondition, "node store is not available to check enrolling node")
+		}
+		node, err := factory.GetFornaxNodeCache(nodeStore, nodeName)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get enrolling node from store", "node", enrollment.GetNodeIdentifier())
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if node != nil {
+			klog.InfoS("Rejected enrollment of a known node with a token not bound to it", "node", enrollment.GetNodeIdentifier())
+			return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("node %s exists, it can only enroll with a token bound to it", nodeName))
+		}
+	}
+
+	cert, err := g.nodeCA.signNodeCertificate(nodeName, enrollment.GetCertificateSigningRequest())
+	if err != nil {
+		klog.ErrorS(err, "Failed to sign node certificate", "node", enrollment.GetNodeIdentifier())
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	klog.InfoS("Enrolled node", "node", enrollment.GetNodeIdentifier())
+	return &fornaxcore_grpc.NodeCertificate{
+		Certificate:   cert,
+		CaCertificate: g.nodeCA.caCertPEM,
+	}, nil
+}
+
+// handleMessages process a node message and dispatch reply to node, it return error if node monitor failed to process message
+func (g *grpcServer) handleMessages(message *fornaxcore_grpc.FornaxCoreMessage) error {
+	if g.isStandby() {
+		klog.V(5).InfoS("Fornaxcore is standby, drop node message", "node", message.GetNodeIdentifier(), "msgType", message.GetMessageType())
+		return nil
+	}
 	var err error
 	var msg *fornaxcore_grpc.FornaxCoreMessage
 	switch message.GetMessageType() {
@@ -184,8 +421,13 @@ func (g *grpcServer) handleMessages(message *fornaxcore_grpc.FornaxCoreMessage)
 		msg, err = g.nodeMonitor.OnPodStateUpdate(message)
 	case fornaxcore_grpc.MessageType_SESSION_STATE:
 		msg, err = g.nodeMonitor.OnSessionUpdate(message)
+	case fornaxcore_grpc.MessageType_NODE_EVENT:
+		msg, err = g.nodeMonitor.OnNodeEvent(message)
+	case fornaxcore_grpc.MessageType_MESSAGE_ACK:
+		g.getNodeOutbox(message.GetNodeIdentifier().GetIdentifier()).ack(message.GetMessageAck().GetMessageIdentifiers())
 	default:
 		klog.Errorf(fmt.Sprintf("not supported message type %s, message %v", message.GetMessageType(), message))
+		err = fmt.Errorf("not supported message type %s", message.GetMessageType())
 	}
 	if err != nil {
 		klog.ErrorS(err, "Failed to process a node message", "node", message.GetNodeIdentifier(), "msgType", message.GetMessageType())
@@ -196,21 +438,24 @@ func (g *grpcServer) handleMessages(message *fornaxcore_grpc.FornaxCoreMessage)
 	if msg != nil {
 		g.DispatchNodeMessage(message.GetNodeIdentifier().GetIdentifier(), msg)
 	}
+	return err
 }
 
 func (g *grpcServer) mustEmbedUnimplementedFornaxCoreServiceServer() {
 }
 
 func NewGrpcServer() *grpcServer {
-	handlerChans := []chan *fornaxcore_grpc.FornaxCoreMessage{}
+	handlerChans := []chan *nodeMessage{}
 	for i := 0; i < DefaultNodeIncomingHandlerNum; i++ {
-		handlerChans = append(handlerChans, make(chan *fornaxcore_grpc.FornaxCoreMessage, 1000))
+		handlerChans = append(handlerChans, make(chan *nodeMessage, 1000))
 	}
 
 	return &grpcServer{
 		RWMutex:                              sync.RWMutex{},
 		nodeOutgoingChans:                    make(map[string]chan<- *fornaxcore_grpc.FornaxCoreMessage),
-		nodeIncommingChans:                   make(map[string]chan *fornaxcore_grpc.FornaxCoreMessage),
+		nodeStreamDones:                      make(map[string]chan struct{}),
+		nodeOutboxes:                         make(map[string]*nodeOutbox),
+		nodeIncommingChans:                   make(map[string]chan *nodeMessage),
 		nodeMonitor:                          nil,
 		UnimplementedFornaxCoreServiceServer: fornaxcore_grpc.UnimplementedFornaxCoreServiceServer{},
 		nodeMessageHandlerChans:              handlerChans,
@@ -220,14 +465,20 @@ func NewGrpcServer() *grpcServer {
 // CreatePod dispatch a PodCreate grpc message to node agent
 func (g *grpcServer) CreatePod(nodeIdentifier string, pod *v1.Pod) error {
 	podIdentifier := util.Name(pod)
+	g.RLock()
+	provider := g.podConfigProvider
+	g.RUnlock()
 	messageType := fornaxcore_grpc.MessageType_POD_CREATE
 	podCreate := fornaxcore_grpc.FornaxCoreMessage_PodCreate{
 		PodCreate: &fornaxcore_grpc.PodCreate{
 			PodIdentifier: podIdentifier,
 			Pod:           pod.DeepCopy(),
-			ConfigMap:     &v1.ConfigMap{},
 		},
 	}
+	if err := setPodCreateConfig(provider, podCreate.PodCreate); err != nil {
+		klog.ErrorS(err, "Failed to get config of pod", "pod", util.Name(pod))
+		return err
+	}
 	m := &fornaxcore_grpc.FornaxCoreMessage{
 		MessageType: messageType,
 		MessageBody: &podCreate,
@@ -241,6 +492,30 @@ func (g *grpcServer) CreatePod(nodeIdentifier string, pod *v1.Pod) error {
 	return nil
 }
 
+// setPodCreateConfig set config data, secrets and image pull secrets of pod into pod create message
+func setPodCreateConfig(provider ie.PodConfigProviderInterface, podCreate *fornaxcore_grpc.PodCreate) error {
+	podCreate.ConfigMap, podCreate.Secrets, podCreate.ImagePullSecrets = &v1.ConfigMap{}, []*v1.Secret{}, []*v1.Secret{}
+	if provider == nil {
+		return nil
+	}
+	configMap, secrets, err := provider.GetPodConfig(podCreate.GetPod())
+	if err != nil {
+		return err
+	}
+	if configMap != nil {
+		podCreate.ConfigMap = configMap
+	}
+	if secrets != nil {
+		podCreate.Secrets = secrets
+	}
+	pullSecrets, err := provider.GetPodImagePullSecrets(podCreate.GetPod())
+	if err != nil {
+		return err
+	}
+	podCreate.ImagePullSecrets = pullSecrets
+	return nil
+}
+
 // TerminatePod dispatch a PodTerminate grpc message to node agent
 func (g *grpcServer) TerminatePod(nodeIdentifier string, pod *v1.Pod) error {
 	podIdentifier := util.Name(pod)
@@ -285,6 +560,28 @@ func (g *grpcServer) HibernatePod(nodeIdentifier string, pod *v1.Pod) error {
 	return nil
 }
 
+// EvacuatePod dispatch a PodEvacuate grpc message to node agent
+func (g *grpcServer) EvacuatePod(nodeIdentifier string, pod *v1.Pod) error {
+	podIdentifier := util.Name(pod)
+	messageType := fornaxcore_grpc.MessageType_POD_EVACUATE
+	podEvacuate := fornaxcore_grpc.FornaxCoreMessage_PodEvacuate{
+		PodEvacuate: &fornaxcore_grpc.PodEvacuate{
+			PodIdentifier: podIdentifier,
+		},
+	}
+	m := &fornaxcore_grpc.FornaxCoreMessage{
+		MessageType: messageType,
+		MessageBody: &podEvacuate,
+	}
+
+	err := g.DispatchNodeMessage(nodeIdentifier, m)
+	if err != nil {
+		klog.ErrorS(err, "Failed to dispatch pod evacuate message to node", "node", nodeIdentifier, "pod", util.Name(pod))
+		return err
+	}
+	return nil
+}
+
 // CloseSession dispatch a SessionClose event to node agent
 func (g *grpcServer) CloseSession(nodeIdentifier string, pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
 	sessionIdentifier := util.Name(session)
@@ -341,6 +638,11 @@ func (g *grpcServer) OpenSession(nodeIdentifier string, pod *v1.Pod, session *fo
 
 }
 
+// ForgetNode release sequence and unacked messages kept for a node removed from fornaxcore
+func (g *grpcServer) ForgetNode(nodeIdentifier string) {
+	g.deleteNodeOutbox(nodeIdentifier)
+}
+
 // FullSyncNode dispatch a NodeFullSync request grpc message to node agent
 func (g *grpcServer) FullSyncNode(nodeIdentifier string) error {
 
@@ -354,6 +656,39 @@ func (g *grpcServer) FullSyncNode(nodeIdentifier string) error {
 	return nil
 }
 
+// ConfigureNode dispatch a NodeConfiguration grpc message to node agent, node agent reconcile its daemon pods with provided daemons
+func (g *grpcServer) ConfigureNode(nodeIdentifier string, node *v1.Node, daemons []*v1.Pod) error {
+	// node outbox saved on node is fornaxcore internal state, do not send it to node
+	node = node.DeepCopy()
+	if _, found := node.Annotations[fornaxv1.AnnotationFornaxCoreNodeOutbox]; found {
+		annotations := map[string]string{}
+		for k, v := range node.Annotations {
+			annotations[k] = v
+		}
+		delete(annotations, fornaxv1.AnnotationFornaxCoreNodeOutbox)
+		node.Annotations = annotations
+	}
+	messageType := fornaxcore_grpc.MessageType_NODE_CONFIGURATION
+	nodeConfig := fornaxcore_grpc.FornaxCoreMessage_NodeConfiguration{
+		NodeConfiguration: &fornaxcore_grpc.NodeConfiguration{
+			ClusterDomain: default_config.DefaultDomainName,
+			Node:          node,
+			DaemonPods:    daemons,
+		},
+	}
+	m := &fornaxcore_grpc.FornaxCoreMessage{
+		MessageType: messageType,
+		MessageBody: &nodeConfig,
+	}
+
+	err := g.DispatchNodeMessage(nodeIdentifier, m)
+	if err != nil {
+		klog.ErrorS(err, "Failed to dispatch node configuration message to node", "node", nodeIdentifier)
+		return err
+	}
+	return nil
+}
+
 func NewFullSyncRequest() *fornaxcore_grpc.FornaxCoreMessage {
 	msg := fornaxcore_grpc.FornaxCoreMessage_NodeFullSync{
 		NodeFullSync: &fornaxcore_grpc.NodeFullSync{},
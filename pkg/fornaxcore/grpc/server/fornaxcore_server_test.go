@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// blockingNodeMonitor block node events until it's released, and fail node state update
+type blockingNodeMonitor struct {
+	fakeNodeMonitor
+	release chan struct{}
+}
+
+func (m *blockingNodeMonitor) OnNodeEvent(message *fornaxcore_grpc.FornaxCoreMessage) (*fornaxcore_grpc.FornaxCoreMessage, error) {
+	<-m.release
+	return nil, nil
+}
+
+func (m *blockingNodeMonitor) OnNodeStateUpdate(message *fornaxcore_grpc.FornaxCoreMessage) (*fornaxcore_grpc.FornaxCoreMessage, error) {
+	return nil, errors.New("bad node state")
+}
+
+func newTestNodeMessage(messageType fornaxcore_grpc.MessageType) *fornaxcore_grpc.FornaxCoreMessage {
+	return &fornaxcore_grpc.FornaxCoreMessage{
+		MessageType:    messageType,
+		NodeIdentifier: &fornaxcore_grpc.NodeIdentifier{Identifier: "node1"},
+	}
+}
+
+func TestPutMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	monitor := &blockingNodeMonitor{release: make(chan struct{})}
+	g := NewGrpcServer()
+	if err := g.ServeGrpcServer(ctx, monitor, bufconn.Listen(1024), nil); err != nil {
+		t.Fatalf("failed to start grpc server, %v", err)
+	}
+
+	// node does not get response before message is processed
+	put := make(chan error)
+	go func() {
+		_, err := g.PutMessage(ctx, newTestNodeMessage(fornaxcore_grpc.MessageType_NODE_EVENT))
+		put <- err
+	}()
+	select {
+	case err := <-put:
+		t.Fatalf("expect node message not returned before processed, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(monitor.release)
+	select {
+	case err := <-put:
+		if err != nil {
+			t.Errorf("expect processed node message succeeded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expect node message returned after processed")
+	}
+
+	// node get error of message failed to be processed
+	if _, err := g.PutMessage(ctx, newTestNodeMessage(fornaxcore_grpc.MessageType_NODE_STATE)); status.Code(err) != codes.Internal {
+		t.Errorf("expect internal error of failed node message, got %v", err)
+	}
+
+	// node call is cancelled before message is processed
+	callCtx, callCancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer callCancel()
+	monitor.release = make(chan struct{})
+	defer close(monitor.release)
+	if _, err := g.PutMessage(callCtx, newTestNodeMessage(fornaxcore_grpc.MessageType_NODE_EVENT)); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expect deadline exceeded error of cancelled node message, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// organization of node client certificate, only certificates issued by node enrollment are accepted as node identity
+	NodeCertificateOrganization    = "fornax:nodes"
+	DefaultNodeCertificateValidity = 365 * 24 * time.Hour
+)
+
+var (
+	NodeNotAuthenticatedError  = errors.New("node is not authenticated with a node client certificate")
+	InvalidBootstrapTokenError = errors.New("bootstrap token is not valid")
+	ExpiredBootstrapTokenError = errors.New("bootstrap token is expired")
+)
+
+// NodeTLSConfig is certificate files used to secure node grpc channel,
+// fornaxcore serve with CertFile/KeyFile, verify node client certificates with ClientCAFile,
+// and sign node certificates with ClientCAFile/ClientCAKeyFile when node enroll with a token in BootstrapTokenFile
+type NodeTLSConfig struct {
+	CertFile           string
+	KeyFile            string
+	ClientCAFile       string
+	ClientCAKeyFile    string
+	BootstrapTokenFile string
+	CertValidity       time.Duration
+}
+
+// nodeCertificateAuthority verify bootstrap tokens and issue node client certificates
+type nodeCertificateAuthority struct {
+	caCert             *x509.Certificate
+	caCertPEM          []byte
+	caKey              crypto.Signer
+	bootstrapTokenFile string
+	certValidity       time.Duration
+}
+
+func newNodeCertificateAuthority(config *NodeTLSConfig) (*nodeCertificateAuthority, error) {
+	caCertPEM, err := os.ReadFile(config.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(caCertPEM)
+	if block == nil {
+		return nil, fmt.Errorf("no certificate found in %s", config.ClientCAFile)
+	}
+	caCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ca := &nodeCertificateAuthority{
+		caCert:             caCert,
+		caCertPEM:          caCertPEM,
+		bootstrapTokenFile: config.BootstrapTokenFile,
+		certValidity:       config.CertValidity,
+	}
+	if ca.certValidity == 0 {
+		ca.certValidity = DefaultNodeCertificateValidity
+	}
+
+	// ca key is only required if node enrollment is enabled
+	if len(config.ClientCAKeyFile) > 0 {
+		key, err := keyutil.PrivateKeyFromFile(config.ClientCAKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("private key in %s can not sign certificate", config.ClientCAKeyFile)
+		}
+		ca.caKey = signer
+	}
+	return ca, nil
+}
+
+// verifyBootstrapToken check token is in bootstrap token file, file is read on every enrollment so tokens can be rotated without restart,
+// each line is a token optionally followed by a node name which is the only node allowed to enroll with this token and an expiration time in RFC3339,
+// format is token[,node[,expiration]], a token not bound to a node must have an expiration, it return whether token is bound to node
+func (ca *nodeCertificateAuthority) verifyBootstrapToken(token, nodeName string) (bool, error) {
+	if len(ca.bootstrapTokenFile) == 0 || len(token) == 0 {
+		return false, InvalidBootstrapTokenError
+	}
+	f, err := os.Open(ca.bootstrapTokenFile)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ",", 3)
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(parts[0])), []byte(token)) != 1 {
+			continue
+		}
+		boundNode := ""
+		if len(parts) > 1 {
+			boundNode = strings.TrimSpace(parts[1])
+		}
+		if len(parts) > 2 {
+			expiration, err := time.Parse(time.RFC3339, strings.TrimSpace(parts[2]))
+			if err != nil {
+				return false, fmt.Errorf("bootstrap token has invalid expiration %s", strings.TrimSpace(parts[2]))
+			}
+			if time.Now().After(expiration) {
+				return false, ExpiredBootstrapTokenError
+			}
+		} else if len(boundNode) == 0 {
+			return false, errors.New("bootstrap token not bound to a node must have an expiration")
+		}
+		if len(boundNode) > 0 && boundNode != nodeName {
+			return false, fmt.Errorf("bootstrap token is not allowed to enroll node %s", nodeName)
+		}
+		return len(boundNode) > 0, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return false, err
+	}
+	return false, InvalidBootstrapTokenError
+}
+
+// signNodeCertificate issue a client certificate whose common name is node name,
+// public key is taken from csr, other subject fields in csr are ignored
+func (ca *nodeCertificateAuthority) signNodeCertificate(nodeName string, csrPEM []byte) ([]byte, error) {
+	if ca.caKey == nil {
+		return nil, errors.New("node enrollment is not enabled, client ca key is not provided")
+	}
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, errors.New("no certificate signing request found")
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, err
+	}
+	if csr.Subject.CommonName != nodeName {
+		return nil, fmt.Errorf("certificate signing request common name %s does not match node %s", csr.Subject.CommonName, nodeName)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   nodeName,
+			Organization: []string{NodeCertificateOrganization},
+		},
+		NotBefore:   now.Add(-5 * time.Minute),
+		NotAfter:    now.Add(ca.certValidity),
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, csr.PublicKey, ca.caKey)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
+
+// newNodeServerTLSConfig serve with fornaxcore certificate, and verify node client certificate if node present one,
+// node without a certificate can only call EnrollNode, other calls are rejected by checking peer identity
+func newNodeServerTLSConfig(config *NodeTLSConfig, ca *nodeCertificateAuthority) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.caCert)
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// peerNodeName return common name of verified node client certificate of grpc peer
+func peerNodeName(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return "", NodeNotAuthenticatedError
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", NodeNotAuthenticatedError
+	}
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	for _, o := range cert.Subject.Organization {
+		if o == NodeCertificateOrganization {
+			return cert.Subject.CommonName, nil
+		}
+	}
+	return "", NodeNotAuthenticatedError
+}
+
+// authenticateNode check node identifier is same as node name in verified client certificate,
+// node identity is not checked if fornaxcore is not serving tls
+func (g *grpcServer) authenticateNode(ctx context.Context, identifier *fornaxcore_grpc.NodeIdentifier) error {
+	if g.nodeCA == nil {
+		return nil
+	}
+	nodeName, err := peerNodeName(ctx)
+	if err != nil {
+		return err
+	}
+	if nodeName != identifier.GetIdentifier() {
+		return fmt.Errorf("node identifier %s does not match certificate subject %s", identifier.GetIdentifier(), nodeName)
+	}
+	return nil
+}
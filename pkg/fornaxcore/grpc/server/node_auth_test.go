@@ -0,0 +1,210 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestNodeCA(t *testing.T, tokens string) *nodeCertificateAuthority {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fornax-node-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &NodeTLSConfig{
+		ClientCAFile:       filepath.Join(dir, "ca.crt"),
+		ClientCAKeyFile:    filepath.Join(dir, "ca.key"),
+		BootstrapTokenFile: filepath.Join(dir, "tokens"),
+	}
+	os.WriteFile(config.ClientCAFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(config.ClientCAKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	os.WriteFile(config.BootstrapTokenFile, []byte(tokens), 0600)
+	ca, err := newNodeCertificateAuthority(config)
+	if err != nil {
+		t.Fatalf("failed to load node ca, %v", err)
+	}
+	return ca
+}
+
+func newTestCSR(t *testing.T, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: commonName}}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})
+}
+
+func TestNodeBootstrapToken(t *testing.T) {
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	ca := newTestNodeCA(t, fmt.Sprintf("# tokens\nany-node-token,,%s\nnode1-token,node1\nnode2-token,node2,%s\nexpired-token,,%s\nexpired-node1-token,node1,%s\nnever-expire-token\ninvalid-expiration-token,,tomorrow\n", future, future, past, past))
+	tests := []struct {
+		token string
+		node  string
+		valid bool
+		bound bool
+	}{
+		{token: "any-node-token", node: "node1", valid: true},
+		{token: "any-node-token", node: "node2", valid: true},
+		{token: "node1-token", node: "node1", valid: true, bound: true},
+		{token: "node1-token", node: "node2", valid: false},
+		{token: "node2-token", node: "node2", valid: true, bound: true},
+		{token: "expired-token", node: "node1", valid: false},
+		{token: "expired-node1-token", node: "node1", valid: false},
+		{token: "never-expire-token", node: "node1", valid: false},
+		{token: "invalid-expiration-token", node: "node1", valid: false},
+		{token: "unknown-token", node: "node1", valid: false},
+		{token: "", node: "node1", valid: false},
+		{token: "# tokens", node: "node1", valid: false},
+	}
+	for _, test := range tests {
+		bound, err := ca.verifyBootstrapToken(test.token, test.node)
+		if (err == nil) != test.valid {
+			t.Errorf("expect token %q of node %s valid %v, got %v", test.token, test.node, test.valid, err)
+		}
+		if err == nil && bound != test.bound {
+			t.Errorf("expect token %q of node %s bound %v, got %v", test.token, test.node, test.bound, bound)
+		}
+	}
+}
+
+func TestEnrollNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodeStore := factory.NewFornaxNodeStorage(ctx)
+	if _, err := factory.CreateFornaxNode(ctx, nodeStore, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "enroll-known-node"}}); err != nil {
+		t.Fatalf("failed to create node, %v", err)
+	}
+
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	g := NewGrpcServer()
+	g.nodeCA = newTestNodeCA(t, fmt.Sprintf("shared-token,,%s\nknown-node-token,enroll-known-node\n", future))
+	g.nodeOutgoingChans["enroll-connected-node"] = make(chan *fornaxcore_grpc.FornaxCoreMessage)
+
+	enroll := func(node, token string) error {
+		_, err := g.EnrollNode(ctx, &fornaxcore_grpc.NodeEnrollment{
+			NodeIdentifier:            &fornaxcore_grpc.NodeIdentifier{Identifier: node},
+			BootstrapToken:            token,
+			CertificateSigningRequest: newTestCSR(t, node),
+		})
+		return err
+	}
+	if err := enroll("enroll-new-node", "shared-token"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expect shared token rejected without node store, got %v", err)
+	}
+
+	g.SetNodeStore(nodeStore)
+	tests := []struct {
+		name  string
+		node  string
+		token string
+		code  codes.Code
+	}{
+		{name: "new node with shared token", node: "enroll-new-node", token: "shared-token", code: codes.OK},
+		{name: "new node enroll again with shared token", node: "enroll-new-node", token: "shared-token", code: codes.OK},
+		{name: "known node with shared token", node: "enroll-known-node", token: "shared-token", code: codes.PermissionDenied},
+		{name: "known node with bound token", node: "enroll-known-node", token: "known-node-token", code: codes.OK},
+		{name: "new node with token bound to other node", node: "enroll-new-node", token: "known-node-token", code: codes.Unauthenticated},
+		{name: "connected node", node: "enroll-connected-node", token: "shared-token", code: codes.AlreadyExists},
+		{name: "invalid token", node: "enroll-new-node", token: "invalid-token", code: codes.Unauthenticated},
+	}
+	for _, test := range tests {
+		if err := enroll(test.node, test.token); status.Code(err) != test.code {
+			t.Errorf("%s: expect %s, got %v", test.name, test.code, err)
+		}
+	}
+}
+
+func TestSignNodeCertificate(t *testing.T) {
+	ca := newTestNodeCA(t, "")
+	if _, err := ca.signNodeCertificate("node1", newTestCSR(t, "node2")); err == nil {
+		t.Errorf("expect csr of other node is rejected")
+	}
+
+	certPEM, err := ca.signNodeCertificate("node1", newTestCSR(t, "node1"))
+	if err != nil {
+		t.Fatalf("failed to sign node certificate, %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse node certificate, %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.caCert)
+	chains, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	if err != nil {
+		t.Fatalf("expect node certificate verified by node ca, %v", err)
+	}
+
+	// node identifier must match certificate subject
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: chains}}})
+	g := &grpcServer{nodeCA: ca}
+	if err := g.authenticateNode(ctx, &fornaxcore_grpc.NodeIdentifier{Identifier: "node1"}); err != nil {
+		t.Errorf("expect node authenticated, %v", err)
+	}
+	if err := g.authenticateNode(ctx, &fornaxcore_grpc.NodeIdentifier{Identifier: "node2"}); err == nil {
+		t.Errorf("expect node with other identifier is rejected")
+	}
+	if err := g.authenticateNode(context.Background(), &fornaxcore_grpc.NodeIdentifier{Identifier: "node1"}); err == nil {
+		t.Errorf("expect node without certificate is rejected")
+	}
+}
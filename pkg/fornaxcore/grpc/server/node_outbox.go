@@ -0,0 +1,223 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"google.golang.org/protobuf/proto"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/klog/v2"
+)
+
+// max number of unacked messages kept for a node, more messages which require ack are rejected until node ack some of them
+const NodeOutboxSize = 1000
+
+// number of message sequences reserved in node store at once, a new reservation is saved when half of it is used
+const NodeOutboxSequenceBlock = 1000
+
+var NodeOutboxFullError = errors.New("node has too many unacked messages")
+
+// nodeOutbox number messages sent to a node with a per node sequence, and keep messages which require ack until node ack them,
+// it outlive node stream connections, unacked messages are resent when node reconnect.
+// message identifier is prefixed with a random epoch of outbox, epoch and a reserved sequence are saved on node in node store,
+// a restarted fornaxcore or a standby which become primary continue after reserved sequence, so, identifiers handled by node are never reused.
+// reservation is saved in background, if it's used up before a new one is saved, outbox continue with a new epoch.
+// unacked messages are only kept in memory, fornaxcore ask node to full sync after restart or failover to reconcile pods and sessions,
+// starting sessions which node does not report in full sync are opened again
+type nodeOutbox struct {
+	mu        sync.Mutex
+	node      string
+	nodeStore fornaxstore.ApiStorageInterface
+	epoch     string
+	seq       int64
+	// epoch and sequence saved in node store, sequences of saved epoch beyond reserved are not used
+	savedEpoch string
+	reserved   int64
+	saving     bool
+	unacked    []*fornaxcore_grpc.FornaxCoreMessage
+}
+
+// savedNodeOutbox is epoch and reserved sequence of node outbox saved in node annotation
+type savedNodeOutbox struct {
+	Epoch    string `json:"epoch"`
+	Sequence int64  `json:"sequence"`
+}
+
+// newNodeOutbox restore outbox saved on node in node store, or create a new one if node does not have a saved outbox,
+// a new reservation is saved before outbox is used, outbox is only kept in memory if node store is nil
+func newNodeOutbox(node string, nodeStore fornaxstore.ApiStorageInterface) *nodeOutbox {
+	outbox := &nodeOutbox{
+		node:      node,
+		nodeStore: nodeStore,
+		epoch:     rand.String(8),
+		unacked:   []*fornaxcore_grpc.FornaxCoreMessage{},
+	}
+	if nodeStore == nil {
+		return outbox
+	}
+	saved, err := loadSavedNodeOutbox(nodeStore, node)
+	if err != nil {
+		klog.ErrorS(err, "Failed to load saved node outbox, start a new one", "node", node)
+	}
+	if saved != nil {
+		outbox.epoch, outbox.seq = saved.Epoch, saved.Sequence
+		outbox.savedEpoch, outbox.reserved = saved.Epoch, saved.Sequence
+		klog.InfoS("Restored node outbox", "node", node, "epoch", outbox.epoch, "sequence", outbox.seq)
+	}
+	reserved := outbox.seq + NodeOutboxSequenceBlock
+	if err := outbox.save(outbox.epoch, reserved); err == nil {
+		outbox.savedEpoch, outbox.reserved = outbox.epoch, reserved
+	}
+	return outbox
+}
+
+func loadSavedNodeOutbox(nodeStore fornaxstore.ApiStorageInterface, node string) (*savedNodeOutbox, error) {
+	nodeInStore, err := factory.GetFornaxNodeCache(nodeStore, node)
+	if err != nil {
+		return nil, err
+	}
+	if nodeInStore == nil {
+		return nil, nil
+	}
+	value, found := nodeInStore.GetAnnotations()[fornaxv1.AnnotationFornaxCoreNodeOutbox]
+	if !found {
+		return nil, nil
+	}
+	saved := &savedNodeOutbox{}
+	if err := json.Unmarshal([]byte(value), saved); err != nil {
+		return nil, err
+	}
+	if len(saved.Epoch) == 0 {
+		return nil, fmt.Errorf("saved outbox of node %s does not have an epoch", node)
+	}
+	return saved, nil
+}
+
+// save epoch and reserved sequence on node in node store, it's called without outbox lock
+func (o *nodeOutbox) save(epoch string, reserved int64) error {
+	value, err := json.Marshal(&savedNodeOutbox{Epoch: epoch, Sequence: reserved})
+	if err != nil {
+		return err
+	}
+	_, err = factory.UpdateFornaxNodeWithFunc(context.Background(), o.nodeStore, o.node, func(node *v1.Node) error {
+		annotations := map[string]string{}
+		for k, v := range node.GetAnnotations() {
+			annotations[k] = v
+		}
+		annotations[fornaxv1.AnnotationFornaxCoreNodeOutbox] = string(value)
+		node.Annotations = annotations
+		return nil
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to save node outbox", "node", o.node, "epoch", epoch, "reserved", reserved)
+	}
+	return err
+}
+
+// reservationLowNoLock return true if current epoch is not saved or less than half of its reservation is left
+func (o *nodeOutbox) reservationLowNoLock() bool {
+	return o.epoch != o.savedEpoch || o.reserved-o.seq < NodeOutboxSequenceBlock/2
+}
+
+// saveReservationNoLock start a background save of a new reservation if there is not one being saved
+func (o *nodeOutbox) saveReservationNoLock() {
+	if o.nodeStore == nil || o.saving || !o.reservationLowNoLock() {
+		return
+	}
+	o.saving = true
+	go o.saveReservations()
+}
+
+// saveReservations save new reservations until current one is sufficient, it stop when a save fails,
+// next message put into outbox try again
+func (o *nodeOutbox) saveReservations() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for o.reservationLowNoLock() {
+		epoch, reserved := o.epoch, o.seq+NodeOutboxSequenceBlock
+		o.mu.Unlock()
+		err := o.save(epoch, reserved)
+		o.mu.Lock()
+		if err != nil {
+			break
+		}
+		o.savedEpoch, o.reserved = epoch, reserved
+	}
+	o.saving = false
+}
+
+// put assign a message identifier to message, and keep a copy of it if it require ack,
+// message which require ack is rejected with NodeOutboxFullError if outbox is full, unacked messages are never dropped,
+// other messages are not deduplicated by node, their identifiers could be reused
+func (o *nodeOutbox) put(msg *fornaxcore_grpc.FornaxCoreMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	ackRequired := fornaxcore_grpc.AckRequired(msg.GetMessageType())
+	if ackRequired && len(o.unacked) >= NodeOutboxSize {
+		return NodeOutboxFullError
+	}
+	if o.nodeStore != nil && o.epoch == o.savedEpoch && o.seq >= o.reserved {
+		// saved reservation is used up, sequences beyond it could be reused after restart
+		o.epoch, o.seq = rand.String(8), 0
+		klog.InfoS("Node outbox reservation is used up, continue with a new epoch", "node", o.node, "epoch", o.epoch)
+	}
+	o.seq += 1
+	msg.MessageIdentifier = fmt.Sprintf("%s-%d", o.epoch, o.seq)
+	if ackRequired {
+		o.unacked = append(o.unacked, proto.Clone(msg).(*fornaxcore_grpc.FornaxCoreMessage))
+	}
+	o.saveReservationNoLock()
+	return nil
+}
+
+// ack remove acknowledged messages, unknown identifiers are ignored as message could be acked more than once
+func (o *nodeOutbox) ack(messageIdentifiers []string) {
+	acked := map[string]bool{}
+	for _, v := range messageIdentifiers {
+		acked[v] = true
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	remaining := make([]*fornaxcore_grpc.FornaxCoreMessage, 0, len(o.unacked))
+	for _, v := range o.unacked {
+		if !acked[v.GetMessageIdentifier()] {
+			remaining = append(remaining, v)
+		}
+	}
+	o.unacked = remaining
+}
+
+// unackedMessages return copies of unacked messages in sequence order
+func (o *nodeOutbox) unackedMessages() []*fornaxcore_grpc.FornaxCoreMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msgs := make([]*fornaxcore_grpc.FornaxCoreMessage, 0, len(o.unacked))
+	for _, v := range o.unacked {
+		msgs = append(msgs, proto.Clone(v).(*fornaxcore_grpc.FornaxCoreMessage))
+	}
+	return msgs
+}
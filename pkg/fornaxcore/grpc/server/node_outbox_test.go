@@ -0,0 +1,329 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxcore_grpc "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeNodeMonitor struct {
+	ie.NodeMonitorInterface
+}
+
+func (m *fakeNodeMonitor) OnNodeConnect(nodeId string) error {
+	return nil
+}
+
+func newTestStoreNode(t *testing.T, nodeStore fornaxstore.ApiStorageInterface, name string) {
+	if _, err := factory.CreateFornaxNode(context.Background(), nodeStore, &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}); err != nil {
+		t.Fatalf("failed to create node, %v", err)
+	}
+}
+
+func messageSequence(outbox *nodeOutbox, msg *fornaxcore_grpc.FornaxCoreMessage) int64 {
+	seq, _ := strconv.ParseInt(strings.TrimPrefix(msg.GetMessageIdentifier(), outbox.epoch+"-"), 10, 64)
+	return seq
+}
+
+func newTestSessionOpen(session string) *fornaxcore_grpc.FornaxCoreMessage {
+	return &fornaxcore_grpc.FornaxCoreMessage{
+		MessageType: fornaxcore_grpc.MessageType_SESSION_OPEN,
+		MessageBody: &fornaxcore_grpc.FornaxCoreMessage_SessionOpen{
+			SessionOpen: &fornaxcore_grpc.SessionOpen{SessionIdentifier: session, PodIdentifier: "test/pod"},
+		},
+	}
+}
+
+func TestNodeOutboxAck(t *testing.T) {
+	outbox := newNodeOutbox("node1", nil)
+	open1, open2 := newTestSessionOpen("test/session1"), newTestSessionOpen("test/session2")
+	fullSync := NewFullSyncRequest()
+	outbox.put(open1)
+	outbox.put(fullSync)
+	outbox.put(open2)
+
+	seq1, seq2, seq3 := messageSequence(outbox, open1), messageSequence(outbox, fullSync), messageSequence(outbox, open2)
+	if seq1 == 0 || seq2 != seq1+1 || seq3 != seq2+1 {
+		t.Fatalf("expect messages numbered in sequence, got %d, %d, %d", seq1, seq2, seq3)
+	}
+
+	// full sync does not require ack
+	unacked := outbox.unackedMessages()
+	if len(unacked) != 2 || unacked[0].GetMessageIdentifier() != open1.GetMessageIdentifier() || unacked[1].GetMessageIdentifier() != open2.GetMessageIdentifier() {
+		t.Fatalf("expect session open messages unacked in order, got %v", unacked)
+	}
+
+	outbox.ack([]string{open1.GetMessageIdentifier(), "unknown"})
+	outbox.ack([]string{open1.GetMessageIdentifier()})
+	unacked = outbox.unackedMessages()
+	if len(unacked) != 1 || unacked[0].GetSessionOpen().GetSessionIdentifier() != "test/session2" {
+		t.Errorf("expect only second session open unacked, got %v", unacked)
+	}
+}
+
+func TestNodeOutboxSize(t *testing.T) {
+	outbox := newNodeOutbox("node1", nil)
+	first := newTestSessionOpen("test/first")
+	outbox.put(first)
+	for i := 1; i < NodeOutboxSize; i++ {
+		if err := outbox.put(newTestSessionOpen("test/session")); err != nil {
+			t.Fatalf("expect message accepted before outbox is full, got %v", err)
+		}
+	}
+	if err := outbox.put(newTestSessionOpen("test/last")); err != NodeOutboxFullError {
+		t.Errorf("expect message rejected when outbox is full, got %v", err)
+	}
+	if err := outbox.put(NewFullSyncRequest()); err != nil {
+		t.Errorf("expect message not requiring ack accepted when outbox is full, got %v", err)
+	}
+	unacked := outbox.unackedMessages()
+	if len(unacked) != NodeOutboxSize || unacked[0].GetMessageIdentifier() != first.GetMessageIdentifier() {
+		t.Errorf("expect oldest message kept when outbox is full, got %d messages", len(unacked))
+	}
+}
+
+func TestDispatchToFullNodeOutbox(t *testing.T) {
+	g := NewGrpcServer()
+	ch := make(chan *fornaxcore_grpc.FornaxCoreMessage, 1)
+	g.nodeOutgoingChans["node1"] = ch
+	outbox := g.getNodeOutbox("node1")
+	for i := 0; i < NodeOutboxSize; i++ {
+		outbox.put(newTestSessionOpen("test/session"))
+	}
+
+	if err := g.DispatchNodeMessage("node1", newTestSessionOpen("test/rejected")); err != NodeOutboxFullError {
+		t.Fatalf("expect dispatch fail when node outbox is full, got %v", err)
+	}
+	select {
+	case msg := <-ch:
+		if msg.GetMessageType() != fornaxcore_grpc.MessageType_NODE_FULL_SYNC {
+			t.Errorf("expect node asked to full sync, got %s", msg.GetMessageType())
+		}
+	default:
+		t.Errorf("expect full sync request sent to node")
+	}
+
+	// removed node's outbox is forgotten
+	g.ForgetNode("node1")
+	if len(g.getNodeOutbox("node1").unackedMessages()) != 0 {
+		t.Errorf("expect a new empty outbox after node is forgotten")
+	}
+}
+
+func TestDispatchToDisconnectedNode(t *testing.T) {
+	g := NewGrpcServer()
+	g.SetStandby(true)
+	ch := make(chan *fornaxcore_grpc.FornaxCoreMessage, 1)
+	if err := g.enlistNode("node1", ch); err != nil {
+		t.Fatalf("failed to enlist node, %v", err)
+	}
+	g.DispatchNodeMessage("node1", newTestSessionOpen("test/session1"))
+
+	// dispatcher wait for full outgoing channel, node stream is gone meanwhile
+	dispatched := make(chan error)
+	go func() {
+		dispatched <- g.DispatchNodeMessage("node1", newTestSessionOpen("test/session2"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	g.delistNode("node1")
+	select {
+	case err := <-dispatched:
+		if err != nil {
+			t.Errorf("expect message kept in outbox for node reconnect, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expect dispatcher stop waiting after node disconnected")
+	}
+	if len(g.getNodeOutbox("node1").unackedMessages()) != 2 {
+		t.Errorf("expect both messages unacked in outbox")
+	}
+	if _, _, err := g.getNodeChan("node1"); err == nil {
+		t.Errorf("expect disconnected node does not have outgoing channel")
+	}
+}
+
+// waitNodeOutboxSaved wait until background save of outbox reservation finish
+func waitNodeOutboxSaved(t *testing.T, outbox *nodeOutbox) {
+	for i := 0; i < 100; i++ {
+		outbox.mu.Lock()
+		saving := outbox.saving
+		outbox.mu.Unlock()
+		if !saving {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("node outbox reservation is not saved")
+}
+
+func TestNodeOutboxRestore(t *testing.T) {
+	nodeStore := factory.NewFornaxNodeStorage(context.Background())
+	newTestStoreNode(t, nodeStore, "outbox-restored-node")
+
+	// a reservation is saved when outbox is created, it is not saved again for every message
+	outbox := newNodeOutbox("outbox-restored-node", nodeStore)
+	saved, err := loadSavedNodeOutbox(nodeStore, "outbox-restored-node")
+	if err != nil || saved == nil || saved.Epoch != outbox.epoch || saved.Sequence != NodeOutboxSequenceBlock {
+		t.Fatalf("expect node outbox reservation saved, got %v, %v", saved, err)
+	}
+	open1 := newTestSessionOpen("test/session1")
+	open1.GetSessionOpen().SessionData = []byte("session data")
+	outbox.put(open1)
+	outbox.put(newTestSessionOpen("test/session2"))
+	waitNodeOutboxSaved(t, outbox)
+	nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, "outbox-restored-node")
+	if value := nodeInStore.Annotations[fornaxv1.AnnotationFornaxCoreNodeOutbox]; strings.Contains(value, "session") {
+		t.Errorf("expect unacked messages not saved on node, got %s", value)
+	}
+
+	// restarted fornaxcore continue after reserved sequence, and save a new reservation
+	restored := newNodeOutbox("outbox-restored-node", nodeStore)
+	if restored.epoch != outbox.epoch || restored.seq != NodeOutboxSequenceBlock || len(restored.unackedMessages()) != 0 {
+		t.Fatalf("expect outbox sequence %s-%d restored without unacked messages, got %s-%d", outbox.epoch, NodeOutboxSequenceBlock, restored.epoch, restored.seq)
+	}
+	open3 := newTestSessionOpen("test/session3")
+	restored.put(open3)
+	if open3.GetMessageIdentifier() != fmt.Sprintf("%s-%d", outbox.epoch, NodeOutboxSequenceBlock+1) {
+		t.Errorf("expect restored outbox continue after reserved sequence, got %s", open3.GetMessageIdentifier())
+	}
+	saved, _ = loadSavedNodeOutbox(nodeStore, "outbox-restored-node")
+	if saved.Sequence != 2*NodeOutboxSequenceBlock {
+		t.Errorf("expect a new reservation saved by restored outbox, got %d", saved.Sequence)
+	}
+
+	// a node without saved outbox get a new epoch, so, identifiers of a forgotten outbox are not reused
+	newOutbox := newNodeOutbox("outbox-new-node", nodeStore)
+	if newOutbox.epoch == outbox.epoch || newOutbox.seq != 0 {
+		t.Errorf("expect a new outbox epoch, got %s-%d", newOutbox.epoch, newOutbox.seq)
+	}
+}
+
+func TestNodeOutboxReservation(t *testing.T) {
+	ctx := context.Background()
+	nodeStore := factory.NewFornaxNodeStorage(ctx)
+	newTestStoreNode(t, nodeStore, "outbox-reserved-node")
+	outbox := newNodeOutbox("outbox-reserved-node", nodeStore)
+	epoch := outbox.epoch
+
+	// node manager update node status while reservations are saved, none of them is lost
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < NodeOutboxSequenceBlock; i++ {
+			outbox.put(NewFullSyncRequest())
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		_, err := factory.UpdateFornaxNodeWithFunc(ctx, nodeStore, "outbox-reserved-node", func(node *v1.Node) error {
+			node.Status.Phase = v1.NodePhase(fmt.Sprintf("phase%d", i))
+			return nil
+		})
+		if err != nil {
+			t.Errorf("expect node updated, got %v", err)
+		}
+	}
+	wg.Wait()
+	waitNodeOutboxSaved(t, outbox)
+
+	nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, "outbox-reserved-node")
+	if nodeInStore.Status.Phase != "phase99" {
+		t.Errorf("expect last node status saved, got %s", nodeInStore.Status.Phase)
+	}
+	saved, err := loadSavedNodeOutbox(nodeStore, "outbox-reserved-node")
+	if err != nil || saved == nil {
+		t.Fatalf("expect node outbox saved, got %v", err)
+	}
+	// every used sequence is covered by saved reservation of its epoch
+	outbox.mu.Lock()
+	defer outbox.mu.Unlock()
+	if saved.Epoch != outbox.epoch || saved.Sequence < outbox.seq+NodeOutboxSequenceBlock/2 {
+		t.Errorf("expect reservation ahead of sequence %s-%d, got %v", outbox.epoch, outbox.seq, saved)
+	}
+	if outbox.epoch == epoch && outbox.seq != NodeOutboxSequenceBlock {
+		t.Errorf("expect all messages numbered, got sequence %d", outbox.seq)
+	}
+
+	// reservation used up before a new one is saved, outbox continue with a new epoch
+	outbox.seq = outbox.reserved
+	outbox.saving = true
+	msg := NewFullSyncRequest()
+	outbox.mu.Unlock()
+	outbox.put(msg)
+	outbox.mu.Lock()
+	outbox.saving = false
+	if outbox.epoch == saved.Epoch || msg.GetMessageIdentifier() != outbox.epoch+"-1" {
+		t.Errorf("expect a new epoch after reservation is used up, got %s", msg.GetMessageIdentifier())
+	}
+}
+
+func TestNodeOutboxAfterBecomePrimary(t *testing.T) {
+	nodeStore := factory.NewFornaxNodeStorage(context.Background())
+	newTestStoreNode(t, nodeStore, "outbox-failover-node")
+
+	// previous primary dispatched a pod create which node did not ack
+	primary := NewGrpcServer()
+	primary.SetNodeStore(nodeStore)
+	primaryCh := make(chan *fornaxcore_grpc.FornaxCoreMessage, 1)
+	primary.nodeOutgoingChans["outbox-failover-node"] = primaryCh
+	if err := primary.CreatePod("outbox-failover-node", &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}}); err != nil {
+		t.Fatalf("failed to dispatch pod create, %v", err)
+	}
+	sent := <-primaryCh
+
+	// standby which become primary ask node to full sync, and continue outbox sequence after previous primary
+	g := NewGrpcServer()
+	g.SetNodeStore(nodeStore)
+	g.nodeMonitor = &fakeNodeMonitor{}
+	g.SetStandby(true)
+	ch := make(chan *fornaxcore_grpc.FornaxCoreMessage, 10)
+	g.nodeOutgoingChans["outbox-failover-node"] = ch
+	g.SetStandby(false)
+	if msg := <-ch; msg.GetMessageType() != fornaxcore_grpc.MessageType_NODE_FULL_SYNC {
+		t.Errorf("expect full sync requested, got %s", msg.GetMessageType())
+	}
+	g.FullSyncNode("outbox-failover-node")
+	msg := <-ch
+	if msg.GetMessageIdentifier() == sent.GetMessageIdentifier() || !strings.HasPrefix(msg.GetMessageIdentifier(), strings.Split(sent.GetMessageIdentifier(), "-")[0]) {
+		t.Errorf("expect new primary continue epoch %s without reusing identifiers, got %s", sent.GetMessageIdentifier(), msg.GetMessageIdentifier())
+	}
+
+	// node outbox is not sent to node with node configuration
+	nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, "outbox-failover-node")
+	if _, found := nodeInStore.Annotations[fornaxv1.AnnotationFornaxCoreNodeOutbox]; !found {
+		t.Fatalf("expect node outbox saved on node")
+	}
+	g.ConfigureNode("outbox-failover-node", nodeInStore, nil)
+	msg = <-ch
+	if _, found := msg.GetNodeConfiguration().GetNode().Annotations[fornaxv1.AnnotationFornaxCoreNodeOutbox]; found {
+		t.Errorf("expect node outbox removed from node configuration")
+	}
+}
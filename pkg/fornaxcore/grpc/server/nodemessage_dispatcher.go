@@ -21,26 +21,73 @@ import (
 	"fmt"
 
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"k8s.io/klog/v2"
 )
 
-func (g *grpcServer) getNodeChan(nodeIdentifer string) (chan<- *grpc.FornaxCoreMessage, error) {
+// getNodeChan return outgoing channel of a connected node and a channel which is closed when node stream is gone
+func (g *grpcServer) getNodeChan(nodeIdentifer string) (chan<- *grpc.FornaxCoreMessage, <-chan struct{}, error) {
 	g.RLock()
 	defer g.RUnlock()
 
 	ch, ok := g.nodeOutgoingChans[nodeIdentifer]
 	if !ok {
-		return nil, fmt.Errorf("unknown destination")
+		return nil, nil, fmt.Errorf("unknown destination")
 	}
 
-	return ch, nil
+	return ch, g.nodeStreamDones[nodeIdentifer], nil
 }
 
+// getNodeOutbox return outbox of node, outbox is restored from node store or created when fornaxcore send first message to node,
+// and kept after node disconnect
+func (g *grpcServer) getNodeOutbox(nodeIdentifier string) *nodeOutbox {
+	g.RLock()
+	outbox, ok := g.nodeOutboxes[nodeIdentifier]
+	nodeStore := g.nodeStore
+	g.RUnlock()
+	if ok {
+		return outbox
+	}
+
+	// restore outbox without server lock, it read and update node store
+	restored := newNodeOutbox(nodeIdentifier, nodeStore)
+	g.Lock()
+	defer g.Unlock()
+	if outbox, ok := g.nodeOutboxes[nodeIdentifier]; ok {
+		return outbox
+	}
+	g.nodeOutboxes[nodeIdentifier] = restored
+	return restored
+}
+
+// deleteNodeOutbox forget sequence and unacked messages of a node
+func (g *grpcServer) deleteNodeOutbox(nodeIdentifier string) {
+	g.Lock()
+	defer g.Unlock()
+	delete(g.nodeOutboxes, nodeIdentifier)
+}
+
+// DispatchNodeMessage send message to a connected node, message is numbered by node outbox,
+// messages which require ack are kept in outbox and resent if node reconnect before ack them,
+// if node does not ack too many messages, message is rejected and node is asked to full sync to reconcile its state,
+// if node stream is gone while waiting for outgoing channel, message is not sent, it's resent from outbox if node reconnect
 func (g *grpcServer) DispatchNodeMessage(nodeIdentifier string, message *grpc.FornaxCoreMessage) error {
-	ch, err := g.getNodeChan(nodeIdentifier)
+	ch, done, err := g.getNodeChan(nodeIdentifier)
 	if err != nil {
 		return err
 	}
 
-	ch <- message
+	outbox := g.getNodeOutbox(nodeIdentifier)
+	if err := outbox.put(message); err != nil {
+		klog.ErrorS(err, "Reject message to node, request node full sync", "node", nodeIdentifier, "msgType", message.GetMessageType())
+		fullSync := NewFullSyncRequest()
+		outbox.put(fullSync)
+		trySendNodeMessage(nodeIdentifier, ch, fullSync)
+		return err
+	}
+	select {
+	case ch <- message:
+	case <-done:
+		klog.InfoS("Node disconnected before message is sent", "node", nodeIdentifier, "msgId", message.GetMessageIdentifier(), "msgType", message.GetMessageType())
+	}
 	return nil
 }
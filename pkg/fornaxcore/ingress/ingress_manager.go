@@ -0,0 +1,419 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultIngressPortRangeStart = 30000
+	DefaultIngressPortRangeEnd   = 32767
+)
+
+var (
+	IngressPortExhaustedError = errors.New("no ingress port is available")
+)
+
+// IngressConfig is ingress gateway address and port range which session ingress ports are allocated from
+type IngressConfig struct {
+	GatewayAddress string
+	PortRangeStart int
+	PortRangeEnd   int
+}
+
+// ingressPort is a gateway port allocated to a session access endpoint
+type ingressPort struct {
+	protocol    v1.Protocol
+	port        int
+	destination fornaxv1.AccessEndPoint
+}
+
+var _ ie.IngressManagerInterface = &ingressManager{}
+
+// ingressManager allocate gateway ports for sessions when they are assigned to pods,
+// create IngressEndpoints when sessions become available and delete them when sessions are closed,
+// ingress gateways watch IngressEndpoints to forward gateway ports to session pods
+type ingressManager struct {
+	ctx            context.Context
+	mu             sync.Mutex
+	config         *IngressConfig
+	ingressStore   fornaxstore.ApiStorageInterface
+	sessionStore   fornaxstore.ApiStorageInterface
+	sessionManager ie.SessionManagerInterface
+	podManager     ie.PodManagerInterface
+	// session name of allocated ports
+	allocatedPorts map[int]string
+	// allocated ports of session, in order of session access endpoints
+	sessionPorts map[string][]*ingressPort
+	nextPort     int
+}
+
+func NewIngressManager(ctx context.Context, config *IngressConfig, ingressStore, sessionStore fornaxstore.ApiStorageInterface, sessionManager ie.SessionManagerInterface, podManager ie.PodManagerInterface) *ingressManager {
+	if config.PortRangeStart == 0 {
+		config.PortRangeStart = DefaultIngressPortRangeStart
+	}
+	if config.PortRangeEnd == 0 {
+		config.PortRangeEnd = DefaultIngressPortRangeEnd
+	}
+	return &ingressManager{
+		ctx:            ctx,
+		config:         config,
+		ingressStore:   ingressStore,
+		sessionStore:   sessionStore,
+		sessionManager: sessionManager,
+		podManager:     podManager,
+		allocatedPorts: map[int]string{},
+		sessionPorts:   map[string][]*ingressPort{},
+		nextPort:       config.PortRangeStart,
+	}
+}
+
+// Run restore allocated ports from existing IngressEndpoints and open sessions, and start to watch session status
+func (im *ingressManager) Run() error {
+	if err := im.restoreIngressPorts(); err != nil {
+		return err
+	}
+	channel, err := im.sessionManager.Watch(im.ctx)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for {
+			select {
+			case <-im.ctx.Done():
+				return
+			case we, ok := <-channel:
+				if !ok {
+					klog.Warning("Session watch channel closed, stop ingress manager")
+					return
+				}
+				im.onSessionEvent(we)
+			}
+		}
+	}()
+	return nil
+}
+
+// restoreIngressPorts rebuild allocated ports from IngressEndpoints, and from gateway access endpoints of open sessions
+// whose IngressEndpoints were not created yet, e.g. fornaxcore restarted before session became available
+func (im *ingressManager) restoreIngressPorts() error {
+	endpoints, err := storefactory.ListIngressEndpoints(im.ctx, im.ingressStore)
+	if err != nil {
+		return err
+	}
+	sessions, err := storefactory.ListApplicationSessions(im.ctx, im.sessionStore)
+	if err != nil {
+		return err
+	}
+	sort.Slice(endpoints, func(i, j int) bool {
+		return endpointIndex(endpoints[i].Name) < endpointIndex(endpoints[j].Name)
+	})
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	for _, ep := range endpoints {
+		sessionName, found := ep.Labels[fornaxv1.LabelFornaxCoreApplicationSession]
+		if !found || len(ep.Spec.Destinations) == 0 {
+			continue
+		}
+		sessionKey := fmt.Sprintf("%s/%s", ep.Namespace, sessionName)
+		destination := ep.Spec.Destinations[0]
+		im.allocatedPorts[ep.Spec.IngressPort] = sessionKey
+		im.sessionPorts[sessionKey] = append(im.sessionPorts[sessionKey], &ingressPort{
+			protocol: v1.Protocol(ep.Spec.Protocol),
+			port:     ep.Spec.IngressPort,
+			destination: fornaxv1.AccessEndPoint{
+				Protocol:  v1.Protocol(ep.Spec.Protocol),
+				IPAddress: destination.IpAddress,
+				Port:      int32(destination.Port),
+			},
+		})
+	}
+	for i := range sessions {
+		session := &sessions[i]
+		if _, found := im.sessionPorts[util.Name(session)]; found || util.SessionInTerminalState(session) {
+			continue
+		}
+		im.adoptSessionPorts(session)
+	}
+	klog.InfoS("Restored session ingress ports", "#ports", len(im.allocatedPorts), "#sessions", len(im.sessionPorts))
+	return nil
+}
+
+// adoptSessionPorts take gateway ports in session access endpoints as allocated ports of session,
+// destinations of these ports are unknown until they are resolved from session pod
+func (im *ingressManager) adoptSessionPorts(session *fornaxv1.ApplicationSession) []*ingressPort {
+	sessionKey := util.Name(session)
+	ports := []*ingressPort{}
+	for _, ep := range session.Status.AccessEndPoints {
+		port := int(ep.Port)
+		if ep.IPAddress != im.config.GatewayAddress || port < im.config.PortRangeStart || port > im.config.PortRangeEnd {
+			return nil
+		}
+		if owner, found := im.allocatedPorts[port]; found && owner != sessionKey {
+			klog.Warningf("Ingress port %d of session %s is allocated to session %s", port, sessionKey, owner)
+			return nil
+		}
+		protocol := ep.Protocol
+		if len(protocol) == 0 {
+			protocol = v1.ProtocolTCP
+		}
+		ports = append(ports, &ingressPort{protocol: protocol, port: port})
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	for _, port := range ports {
+		im.allocatedPorts[port.port] = sessionKey
+	}
+	im.sessionPorts[sessionKey] = ports
+	return ports
+}
+
+// resolvePortDestinations set destinations of ports which are not known from session pod host ports
+func (im *ingressManager) resolvePortDestinations(session *fornaxv1.ApplicationSession, ports []*ingressPort) error {
+	resolved := true
+	for _, port := range ports {
+		resolved = resolved && len(port.destination.IPAddress) > 0
+	}
+	if resolved {
+		return nil
+	}
+	if session.Status.PodReference == nil || im.podManager == nil {
+		return fmt.Errorf("session %s does not have a pod to forward ingress ports to", util.Name(session))
+	}
+	pod := im.podManager.FindPod(session.Status.PodReference.Name)
+	if pod == nil {
+		return fmt.Errorf("pod %s of session %s is not found", session.Status.PodReference.Name, util.Name(session))
+	}
+	destinations := util.PodHostPortEndPoints(pod)
+	if len(destinations) != len(ports) {
+		return fmt.Errorf("pod %s of session %s has %d host ports, session has %d ingress ports", util.Name(pod), util.Name(session), len(destinations), len(ports))
+	}
+	for i, port := range ports {
+		port.destination = destinations[i]
+	}
+	return nil
+}
+
+// AllocateIngressEndPoints allocate a gateway port for each session access endpoint,
+// ports already allocated to session are reused, so session keep its ingress endpoints when it's reopened on another pod
+func (im *ingressManager) AllocateIngressEndPoints(session *fornaxv1.ApplicationSession, destinations []fornaxv1.AccessEndPoint) ([]fornaxv1.AccessEndPoint, error) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	sessionKey := util.Name(session)
+	existing := im.sessionPorts[sessionKey]
+	ports := []*ingressPort{}
+	endpoints := []fornaxv1.AccessEndPoint{}
+	for i, dest := range destinations {
+		protocol := dest.Protocol
+		if len(protocol) == 0 {
+			protocol = v1.ProtocolTCP
+		}
+		var port *ingressPort
+		if i < len(existing) && existing[i].protocol == protocol {
+			port = existing[i]
+		} else {
+			p, err := im.allocatePort(sessionKey)
+			if err != nil {
+				im.releasePorts(ports)
+				return nil, err
+			}
+			port = &ingressPort{protocol: protocol, port: p}
+		}
+		port.destination = dest
+		ports = append(ports, port)
+		endpoints = append(endpoints, fornaxv1.AccessEndPoint{
+			Protocol:  protocol,
+			IPAddress: im.config.GatewayAddress,
+			Port:      int32(port.port),
+		})
+	}
+
+	// release ports of session not used any more
+	for i, port := range existing {
+		if i >= len(ports) || ports[i] != port {
+			delete(im.allocatedPorts, port.port)
+		}
+	}
+	im.sessionPorts[sessionKey] = ports
+	klog.InfoS("Allocated session ingress endpoints", "session", sessionKey, "endpoints", endpoints)
+	return endpoints, nil
+}
+
+func (im *ingressManager) allocatePort(sessionKey string) (int, error) {
+	size := im.config.PortRangeEnd - im.config.PortRangeStart + 1
+	for i := 0; i < size; i++ {
+		port := im.nextPort
+		im.nextPort += 1
+		if im.nextPort > im.config.PortRangeEnd {
+			im.nextPort = im.config.PortRangeStart
+		}
+		if _, found := im.allocatedPorts[port]; !found {
+			im.allocatedPorts[port] = sessionKey
+			return port, nil
+		}
+	}
+	return 0, IngressPortExhaustedError
+}
+
+func (im *ingressManager) releasePorts(ports []*ingressPort) {
+	for _, port := range ports {
+		delete(im.allocatedPorts, port.port)
+	}
+}
+
+// onSessionEvent setup ingress endpoints when session is available, and teardown them when session is closed or deleted,
+// evacuated session is requeued as pending and keeps its ports until it is reopened on another pod
+func (im *ingressManager) onSessionEvent(we fornaxstore.WatchEventWithOldObj) {
+	session, ok := we.Object.(*fornaxv1.ApplicationSession)
+	if !ok {
+		return
+	}
+	switch {
+	case we.Type == watch.Deleted || util.SessionInTerminalState(session):
+		if err := im.teardownSessionIngress(session); err != nil {
+			klog.ErrorS(err, "Failed to delete session ingress endpoints", "session", util.Name(session))
+		}
+	case session.Status.SessionStatus == fornaxv1.SessionStatusAvailable || session.Status.SessionStatus == fornaxv1.SessionStatusInUse:
+		if err := im.setupSessionIngress(session); err != nil {
+			klog.ErrorS(err, "Failed to create session ingress endpoints", "session", util.Name(session))
+		}
+	}
+}
+
+func (im *ingressManager) setupSessionIngress(session *fornaxv1.ApplicationSession) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	ports, found := im.sessionPorts[util.Name(session)]
+	if !found {
+		// session was assigned to pod by a previous fornaxcore which did not create its endpoints
+		if ports = im.adoptSessionPorts(session); len(ports) == 0 {
+			return nil
+		}
+	}
+	if err := im.resolvePortDestinations(session, ports); err != nil {
+		return err
+	}
+	for i, port := range ports {
+		endpoint := im.newIngressEndpoint(session, i, port)
+		existing, err := storefactory.GetIngressEndpointCache(im.ingressStore, util.Name(endpoint))
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			klog.InfoS("Create session ingress endpoint", "session", util.Name(session), "endpoint", util.Name(endpoint), "ingressPort", port.port, "destination", port.destination)
+			if _, err := storefactory.CreateIngressEndpoint(im.ctx, im.ingressStore, endpoint); err != nil {
+				return err
+			}
+		} else if !reflect.DeepEqual(existing.Spec, endpoint.Spec) {
+			klog.InfoS("Update session ingress endpoint", "session", util.Name(session), "endpoint", util.Name(endpoint), "ingressPort", port.port, "destination", port.destination)
+			updated := existing.DeepCopy()
+			updated.Spec = endpoint.Spec
+			if _, err := storefactory.UpdateIngressEndpoint(im.ctx, im.ingressStore, updated); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (im *ingressManager) teardownSessionIngress(session *fornaxv1.ApplicationSession) error {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	sessionKey := util.Name(session)
+	ports, found := im.sessionPorts[sessionKey]
+	if !found {
+		return nil
+	}
+	for i := range ports {
+		name := fmt.Sprintf("%s/%s", session.Namespace, ingressEndpointName(session, i))
+		klog.InfoS("Delete session ingress endpoint", "session", sessionKey, "endpoint", name)
+		if _, err := storefactory.DeleteIngressEndpoint(im.ctx, im.ingressStore, name); err != nil {
+			return err
+		}
+	}
+	im.releasePorts(ports)
+	delete(im.sessionPorts, sessionKey)
+	return nil
+}
+
+func (im *ingressManager) newIngressEndpoint(session *fornaxv1.ApplicationSession, index int, port *ingressPort) *fornaxv1.IngressEndpoint {
+	labels := map[string]string{
+		fornaxv1.LabelFornaxCoreApplicationSession: session.Name,
+	}
+	if app, found := session.Labels[fornaxv1.LabelFornaxCoreApplication]; found {
+		labels[fornaxv1.LabelFornaxCoreApplication] = app
+	}
+	return &fornaxv1.IngressEndpoint{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fornaxv1.IngressEndpointKind.GroupVersion().String(),
+			Kind:       fornaxv1.IngressEndpointKind.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ingressEndpointName(session, index),
+			Namespace: session.Namespace,
+			Labels:    labels,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: fornaxv1.ApplicationSessionKind.GroupVersion().String(),
+				Kind:       fornaxv1.ApplicationSessionKind.Kind,
+				Name:       session.Name,
+				UID:        session.UID,
+			}},
+		},
+		Spec: fornaxv1.IngressEndpointSpec{
+			Protocol:           string(port.protocol),
+			IngressGWIPAddress: im.config.GatewayAddress,
+			IngressPort:        port.port,
+			Destinations: []fornaxv1.Destination{{
+				IpAddress: port.destination.IPAddress,
+				Port:      int(port.destination.Port),
+			}},
+		},
+	}
+}
+
+// ingressEndpointName is session name with index of session access endpoint
+func ingressEndpointName(session *fornaxv1.ApplicationSession, index int) string {
+	return fmt.Sprintf("%s-%d", session.Name, index)
+}
+
+func endpointIndex(name string) int {
+	index, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return index
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/store/inmemory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newTestSession(name string) *fornaxv1.ApplicationSession {
+	return &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name, UID: types.UID("uid-" + name)},
+	}
+}
+
+func podEndpoints(ip string, ports ...int32) []fornaxv1.AccessEndPoint {
+	endpoints := []fornaxv1.AccessEndPoint{}
+	for _, p := range ports {
+		endpoints = append(endpoints, fornaxv1.AccessEndPoint{Protocol: v1.ProtocolTCP, IPAddress: ip, Port: p})
+	}
+	return endpoints
+}
+
+// fakePodManager find pods from a map
+type fakePodManager struct {
+	ie.PodManagerInterface
+	pods map[string]*v1.Pod
+}
+
+func (pm *fakePodManager) FindPod(podName string) *v1.Pod {
+	return pm.pods[podName]
+}
+
+func newTestIngressStore(ctx context.Context) *inmemory.MemoryStore {
+	return inmemory.NewMemoryStore(ctx, fornaxv1.IngressEndpointGrv.GroupResource(), fornaxv1.IngressEndpointGrvKey,
+		func() runtime.Object { return &fornaxv1.IngressEndpoint{} },
+		func() runtime.Object { return &fornaxv1.IngressEndpointList{} })
+}
+
+func TestAllocateIngressEndPoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newTestIngressStore(ctx)
+	im := NewIngressManager(ctx, &IngressConfig{GatewayAddress: "10.0.0.1", PortRangeStart: 40000, PortRangeEnd: 40002}, store, storefactory.NewFornaxApplicationSessionStorage(ctx), nil, nil)
+
+	a := newTestSession("a")
+	endpoints, err := im.AllocateIngressEndPoints(a, podEndpoints("192.168.0.1", 1024, 1025))
+	if err != nil || len(endpoints) != 2 || endpoints[0].Port != 40000 || endpoints[1].Port != 40001 || endpoints[0].IPAddress != "10.0.0.1" {
+		t.Fatalf("expect two gateway endpoints allocated, got %v, %v", endpoints, err)
+	}
+
+	// session reopened on another pod keep its ingress ports
+	reopened, err := im.AllocateIngressEndPoints(a, podEndpoints("192.168.0.2", 1030, 1031))
+	if err != nil || reopened[0].Port != 40000 || reopened[1].Port != 40001 {
+		t.Fatalf("expect session keep its ingress ports, got %v, %v", reopened, err)
+	}
+
+	b := newTestSession("b")
+	if _, err := im.AllocateIngressEndPoints(b, podEndpoints("192.168.0.3", 1024, 1025)); err != IngressPortExhaustedError {
+		t.Fatalf("expect ingress ports exhausted, got %v", err)
+	}
+	if len(im.allocatedPorts) != 2 {
+		t.Errorf("expect ports allocated by failed allocation are released, got %v", im.allocatedPorts)
+	}
+
+	// ingress endpoint is created when session is available, and deleted when session is closed
+	if err := im.setupSessionIngress(a); err != nil {
+		t.Fatalf("failed to setup session ingress, %v", err)
+	}
+	ep, err := storefactory.GetIngressEndpointCache(store, "test/a-1")
+	if err != nil || ep == nil {
+		t.Fatalf("expect ingress endpoint created, got %v, %v", ep, err)
+	}
+	if ep.Spec.IngressPort != 40001 || ep.Spec.Destinations[0].IpAddress != "192.168.0.2" || ep.Spec.Destinations[0].Port != 1031 {
+		t.Errorf("expect ingress endpoint forward to reopened pod, got %v", ep.Spec)
+	}
+	if err := im.teardownSessionIngress(a); err != nil {
+		t.Fatalf("failed to teardown session ingress, %v", err)
+	}
+	if ep, _ := storefactory.GetIngressEndpointCache(store, "test/a-0"); ep != nil {
+		t.Errorf("expect ingress endpoint deleted, got %v", util.Name(ep))
+	}
+	if _, err := im.AllocateIngressEndPoints(b, podEndpoints("192.168.0.3", 1024, 1025)); err != nil {
+		t.Errorf("expect ports released by closed session are reused, %v", err)
+	}
+}
+
+func TestRestoreIngressPortsFromSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := newTestIngressStore(ctx)
+	sessionStore := storefactory.NewFornaxApplicationSessionStorage(ctx)
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:  "app",
+			Ports: []v1.ContainerPort{{Protocol: v1.ProtocolTCP, HostIP: "192.168.0.5", HostPort: 1024, ContainerPort: 80}},
+		}}},
+	}
+	podManager := &fakePodManager{pods: map[string]*v1.Pod{"test/pod": pod}}
+	im := NewIngressManager(ctx, &IngressConfig{GatewayAddress: "10.0.0.1", PortRangeStart: 40000, PortRangeEnd: 40002}, store, sessionStore, nil, podManager)
+
+	// session was assigned to pod with ingress port by a previous fornaxcore, but its endpoint was not created
+	session := newTestSession("s")
+	session.Status = fornaxv1.ApplicationSessionStatus{
+		SessionStatus:   fornaxv1.SessionStatusAvailable,
+		PodReference:    &v1.LocalObjectReference{Name: "test/pod"},
+		AccessEndPoints: podEndpoints("10.0.0.1", 40001),
+	}
+	closed := newTestSession("closed")
+	closed.Status = fornaxv1.ApplicationSessionStatus{
+		SessionStatus:   fornaxv1.SessionStatusClosed,
+		AccessEndPoints: podEndpoints("10.0.0.1", 40002),
+	}
+	for _, v := range []*fornaxv1.ApplicationSession{session, closed} {
+		if _, err := storefactory.CreateApplicationSession(ctx, sessionStore, v); err != nil {
+			t.Fatalf("failed to create session, %v", err)
+		}
+	}
+
+	if err := im.restoreIngressPorts(); err != nil {
+		t.Fatalf("failed to restore ingress ports, %v", err)
+	}
+	if im.allocatedPorts[40001] != "test/s" || len(im.allocatedPorts) != 1 {
+		t.Fatalf("expect only port of open session restored, got %v", im.allocatedPorts)
+	}
+	other, err := im.AllocateIngressEndPoints(newTestSession("other"), podEndpoints("192.168.0.6", 1024, 1025))
+	if err != nil || other[0].Port != 40000 || other[1].Port != 40002 {
+		t.Errorf("expect restored port not allocated to other session, got %v, %v", other, err)
+	}
+
+	// endpoint forward to session pod host port
+	if err := im.setupSessionIngress(session); err != nil {
+		t.Fatalf("failed to setup session ingress, %v", err)
+	}
+	ep, err := storefactory.GetIngressEndpointCache(store, "test/s-0")
+	if err != nil || ep == nil {
+		t.Fatalf("expect ingress endpoint created, got %v, %v", ep, err)
+	}
+	if ep.Spec.IngressPort != 40001 || ep.Spec.Destinations[0].IpAddress != "192.168.0.5" || ep.Spec.Destinations[0].Port != 1024 {
+		t.Errorf("expect ingress endpoint forward restored port to session pod, got %v", ep.Spec)
+	}
+}
@@ -33,6 +33,7 @@ type PodManagerInterface interface {
 	DeletePod(pod *v1.Pod) (*v1.Pod, error)
 	TerminatePod(podName string) error
 	HibernatePod(podName string) error
+	EvacuatePod(podName string) error
 	FindPod(podName string) *v1.Pod
 	Watch(watcher chan<- *PodEvent)
 }
@@ -62,6 +63,7 @@ type NodeManagerInterface interface {
 	UpdatePodState(nodeId string, pod *v1.Pod, sessions []*fornaxv1.ApplicationSession) error
 	SyncPodStates(nodeId string, podStates []*grpc.PodState)
 	DisconnectNode(nodeId string) error
+	EvacuateNode(nodeId string) error
 }
 
 // SessionManagerInterface work as a bridge between node agent and fornax core, it call nodeagent to open/close a session
@@ -74,6 +76,17 @@ type SessionManagerInterface interface {
 	Watch(ctx context.Context) (<-chan fornaxstore.WatchEventWithOldObj, error)
 }
 
+// IngressManagerInterface allocate ingress gateway endpoints for sessions, and maintain IngressEndpoints of open sessions,
+// session keep same ingress endpoints when it's reopened on another pod
+type IngressManagerInterface interface {
+	AllocateIngressEndPoints(session *fornaxv1.ApplicationSession, destinations []fornaxv1.AccessEndPoint) ([]fornaxv1.AccessEndPoint, error)
+}
+
+// EventManagerInterface save k8s events of fornax resources, e.g. events reported by node agents
+type EventManagerInterface interface {
+	RecordEvent(event *v1.Event)
+}
+
 // NodeInfoProviderInterface provide method to watch and list NodeEvent
 type NodeInfoProviderInterface interface {
 	List() []*NodeEvent
@@ -85,6 +98,23 @@ type PodInfoProviderInterface interface {
 	Watch(watcher chan<- *PodEvent)
 }
 
+// PodConfigProviderInterface provide config data, secrets and image pull secrets which are delivered to node with pod
+type PodConfigProviderInterface interface {
+	GetPodConfig(pod *v1.Pod) (*v1.ConfigMap, []*v1.Secret, error)
+	GetPodImagePullSecrets(pod *v1.Pod) ([]*v1.Secret, error)
+}
+
+// PodSessionProviderInterface tell if fornaxcore assigned sessions to a pod, including starting sessions which node has not reported yet
+type PodSessionProviderInterface interface {
+	PodHasAssignedSessions(pod *v1.Pod) bool
+}
+
+// PodSessionReconcilerInterface reconcile sessions fornaxcore assigned to a pod with sessions node reported in full sync,
+// session open which node did not receive is sent again
+type PodSessionReconcilerInterface interface {
+	ReconcilePodSessions(pod *v1.Pod, reportedSessions []*fornaxv1.ApplicationSession)
+}
+
 // NodeMonitorInterface handle message sent by node agent
 type NodeMonitorInterface interface {
 	OnNodeConnect(nodeId string) error
@@ -94,4 +124,5 @@ type NodeMonitorInterface interface {
 	OnNodeStateUpdate(message *grpc.FornaxCoreMessage) (*grpc.FornaxCoreMessage, error)
 	OnPodStateUpdate(message *grpc.FornaxCoreMessage) (*grpc.FornaxCoreMessage, error)
 	OnSessionUpdate(message *grpc.FornaxCoreMessage) (*grpc.FornaxCoreMessage, error)
+	OnNodeEvent(message *grpc.FornaxCoreMessage) (*grpc.FornaxCoreMessage, error)
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+var _ resourcelock.Interface = &FileLeaseLock{}
+
+// FileLeaseLock is a leader election resource lock which save leader election record in a local file,
+// it's a stand in of StoreLeaseLock for tests and fornaxcore replicas sharing same file system,
+// file is flocked when read and write, and record is only updated if it was not changed since last Get
+type FileLeaseLock struct {
+	mu       sync.Mutex
+	path     string
+	identity string
+	observed []byte
+}
+
+func (l *FileLeaseLock) withFileLock(how int, fn func(f *os.File) error) error {
+	f, err := os.OpenFile(l.path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), how); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return fn(f)
+}
+
+func (l *FileLeaseLock) readRecord(f *os.File) ([]byte, error) {
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	if _, err := buf.ReadFrom(f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (l *FileLeaseLock) writeRecord(f *os.File, ler resourcelock.LeaderElectionRecord) ([]byte, error) {
+	recordBytes, err := json.Marshal(ler)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.Truncate(0); err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteAt(recordBytes, 0); err != nil {
+		return nil, err
+	}
+	return recordBytes, f.Sync()
+}
+
+// Get returns leader election record saved in file
+func (l *FileLeaseLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	var recordBytes []byte
+	err := l.withFileLock(syscall.LOCK_SH, func(f *os.File) (err error) {
+		recordBytes, err = l.readRecord(f)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, apierrors.NewNotFound(LeaseGroupResource, l.path)
+		}
+		return nil, nil, err
+	}
+	// a empty file is left by a creator which failed to write record
+	if len(recordBytes) == 0 {
+		return nil, nil, apierrors.NewNotFound(LeaseGroupResource, l.path)
+	}
+
+	record := &resourcelock.LeaderElectionRecord{}
+	if err := json.Unmarshal(recordBytes, record); err != nil {
+		return nil, nil, err
+	}
+	l.mu.Lock()
+	l.observed = recordBytes
+	l.mu.Unlock()
+	return record, recordBytes, nil
+}
+
+// Create creates lease file and save leader election record,
+// it fails with conflict error if another fornaxcore has created file and saved a record
+func (l *FileLeaseLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	return l.withFileLock(syscall.LOCK_EX, func(f *os.File) error {
+		curr, err := l.readRecord(f)
+		if err != nil {
+			return err
+		}
+		if len(curr) > 0 {
+			return apierrors.NewAlreadyExists(LeaseGroupResource, l.path)
+		}
+		recordBytes, err := l.writeRecord(f, ler)
+		if err != nil {
+			return err
+		}
+		l.mu.Lock()
+		l.observed = recordBytes
+		l.mu.Unlock()
+		return nil
+	})
+}
+
+// Update saves leader election record if record in file was not changed since last Get
+func (l *FileLeaseLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.mu.Lock()
+	observed := l.observed
+	l.mu.Unlock()
+	if observed == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+
+	return l.withFileLock(syscall.LOCK_EX, func(f *os.File) error {
+		curr, err := l.readRecord(f)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(curr, observed) {
+			return apierrors.NewConflict(LeaseGroupResource, l.path, errors.New("lease has been changed by another fornaxcore"))
+		}
+		recordBytes, err := l.writeRecord(f, ler)
+		if err != nil {
+			return err
+		}
+		l.mu.Lock()
+		l.observed = recordBytes
+		l.mu.Unlock()
+		return nil
+	})
+}
+
+// RecordEvent implements resourcelock.Interface, fornaxcore does not have a event recorder, just log it
+func (l *FileLeaseLock) RecordEvent(s string) {
+	klog.InfoS("Leader election event", "lease", l.path, "event", s)
+}
+
+// Describe implements resourcelock.Interface
+func (l *FileLeaseLock) Describe() string {
+	return l.path
+}
+
+// Identity implements resourcelock.Interface
+func (l *FileLeaseLock) Identity() string {
+	return l.identity
+}
+
+func NewFileLeaseLock(path, identity string) *FileLeaseLock {
+	return &FileLeaseLock{
+		mu:       sync.Mutex{},
+		path:     path,
+		identity: identity,
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// LeaderCallbacks are called when this fornaxcore become primary or standby
+type LeaderCallbacks struct {
+	// OnStartedLeading is called when this fornaxcore become primary, ctx is cancelled when it lose lease
+	OnStartedLeading func(ctx context.Context)
+	// OnStoppedLeading is called when this fornaxcore lose primary ownership
+	OnStoppedLeading func()
+	// OnNewLeader is called when a new primary is observed, including this fornaxcore itself,
+	// standbys are fornaxcore peers other than primary
+	OnNewLeader func(primary string, standbys []string)
+}
+
+// FornaxCoreElector elect a primary fornaxcore among fornaxcore replicas,
+// primary fornaxcore run managers and handle node messages, the others work as hot standby,
+// fornaxcore identity is its grpc server endpoint which node agents connect to
+type FornaxCoreElector struct {
+	elector *leaderelection.LeaderElector
+	lock    resourcelock.Interface
+	peers   []string
+	leading int32
+}
+
+// Run elect primary until ctx is cancelled, lease is released when ctx is cancelled
+func (e *FornaxCoreElector) Run(ctx context.Context) {
+	klog.InfoS("Start fornaxcore leader election", "identity", e.lock.Identity(), "lease", e.lock.Describe(), "peers", e.peers)
+	for {
+		e.elector.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// IsLeader return true if this fornaxcore is primary
+func (e *FornaxCoreElector) IsLeader() bool {
+	return e.elector.IsLeader()
+}
+
+// GetLeader return identity of primary fornaxcore
+func (e *FornaxCoreElector) GetLeader() string {
+	return e.elector.GetLeader()
+}
+
+// Standbys return fornaxcore peers except primary
+func (e *FornaxCoreElector) Standbys(primary string) []string {
+	return standbys(primary, e.lock.Identity(), e.peers)
+}
+
+func standbys(primary, identity string, peers []string) []string {
+	standbys := []string{}
+	seen := map[string]bool{primary: true}
+	for _, v := range append([]string{identity}, peers...) {
+		if !seen[v] {
+			seen[v] = true
+			standbys = append(standbys, v)
+		}
+	}
+	return standbys
+}
+
+// NewFornaxCoreElector create a elector using provided resource lock, peers are endpoints of other fornaxcore replicas
+func NewFornaxCoreElector(lock resourcelock.Interface, peers []string, callbacks LeaderCallbacks) (*FornaxCoreElector, error) {
+	e := &FornaxCoreElector{
+		lock:  lock,
+		peers: peers,
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   DefaultLeaseDuration,
+		RenewDeadline:   DefaultRenewDeadline,
+		RetryPeriod:     DefaultRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            lock.Describe(),
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				klog.InfoS("Fornaxcore became primary", "identity", lock.Identity())
+				atomic.StoreInt32(&e.leading, 1)
+				if callbacks.OnStartedLeading != nil {
+					callbacks.OnStartedLeading(ctx)
+				}
+			},
+			OnStoppedLeading: func() {
+				// client-go call OnStoppedLeading when elector exit even it never acquired lease
+				if !atomic.CompareAndSwapInt32(&e.leading, 1, 0) {
+					return
+				}
+				klog.InfoS("Fornaxcore lost primary ownership", "identity", lock.Identity())
+				if callbacks.OnStoppedLeading != nil {
+					callbacks.OnStoppedLeading()
+				}
+			},
+			OnNewLeader: func(identity string) {
+				klog.InfoS("Observed a new fornaxcore primary", "primary", identity)
+				if callbacks.OnNewLeader != nil {
+					callbacks.OnNewLeader(identity, e.Standbys(identity))
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	e.elector = elector
+	return e, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+func TestFileLeaseLockConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	lock1 := NewFileLeaseLock(path, "core1:18001")
+	lock2 := NewFileLeaseLock(path, "core2:18001")
+	ctx := context.Background()
+
+	if _, _, err := lock1.Get(ctx); !apierrors.IsNotFound(err) {
+		t.Fatalf("expect not found error before lease created, got %v", err)
+	}
+	record := resourcelock.LeaderElectionRecord{HolderIdentity: lock1.Identity(), LeaseDurationSeconds: 15, RenewTime: metav1.Now()}
+	if err := lock1.Create(ctx, record); err != nil {
+		t.Fatalf("failed to create lease, %v", err)
+	}
+	if err := lock2.Create(ctx, record); !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("expect already exist error when lease created twice, got %v", err)
+	}
+
+	got, _, err := lock2.Get(ctx)
+	if err != nil {
+		t.Fatalf("failed to get lease, %v", err)
+	}
+	if got.HolderIdentity != lock1.Identity() {
+		t.Errorf("expect lease holder %s, got %s", lock1.Identity(), got.HolderIdentity)
+	}
+
+	// lock1 renew lease after lock2 observed it, lock2 update must fail
+	record.LeaderTransitions = 1
+	if err := lock1.Update(ctx, record); err != nil {
+		t.Fatalf("failed to renew lease, %v", err)
+	}
+	record.HolderIdentity = lock2.Identity()
+	if err := lock2.Update(ctx, record); !apierrors.IsConflict(err) {
+		t.Errorf("expect conflict error when lease changed since last get, got %v", err)
+	}
+}
+
+func TestFornaxCoreElectorFailover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lease")
+	peers := []string{"core1:18001", "core2:18001"}
+
+	type observed struct {
+		primary  string
+		standbys []string
+	}
+	started := make(chan string, 2)
+	newLeaders := make(chan observed, 10)
+	newElector := func(identity string) *FornaxCoreElector {
+		e, err := NewFornaxCoreElector(NewFileLeaseLock(path, identity), peers, LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) { started <- identity },
+			OnNewLeader: func(primary string, standbys []string) {
+				if identity == "core2:18001" {
+					newLeaders <- observed{primary, standbys}
+				}
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to create elector, %v", err)
+		}
+		return e
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	e1 := newElector("core1:18001")
+	go e1.Run(ctx1)
+	select {
+	case identity := <-started:
+		if identity != "core1:18001" {
+			t.Fatalf("expect core1 become primary, got %s", identity)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("core1 did not become primary")
+	}
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	e2 := newElector("core2:18001")
+	go e2.Run(ctx2)
+	select {
+	case o := <-newLeaders:
+		if o.primary != "core1:18001" || !reflect.DeepEqual(o.standbys, []string{"core2:18001"}) {
+			t.Errorf("expect core2 observe primary core1 and standby core2, got %v", o)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("core2 did not observe primary")
+	}
+	if e2.IsLeader() {
+		t.Error("core2 should be standby when core1 hold lease")
+	}
+
+	// core1 release lease when it stop, core2 take over
+	cancel1()
+	select {
+	case identity := <-started:
+		if identity != "core2:18001" {
+			t.Fatalf("expect core2 become primary, got %s", identity)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("core2 did not take over primary")
+	}
+	if !e2.IsLeader() || e2.GetLeader() != "core2:18001" {
+		t.Errorf("expect core2 is primary, got %s", e2.GetLeader())
+	}
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+	"k8s.io/apiserver/pkg/storage/storagebackend/factory"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultLeaseName   = "fornaxcore"
+	DefaultLeasePrefix = "/fornaxcore/leases"
+)
+
+var (
+	LeaseGroupResource = coordinationv1.Resource("leases")
+
+	leaseScheme = runtime.NewScheme()
+	leaseCodecs = serializer.NewCodecFactory(leaseScheme)
+)
+
+func init() {
+	coordinationv1.AddToScheme(leaseScheme)
+}
+
+var _ resourcelock.Interface = &StoreLeaseLock{}
+
+// StoreLeaseLock is a leader election resource lock which save a coordination Lease object in a shared apiserver storage,
+// it uses lease resource version to make sure only one fornaxcore update lease holder at a time
+type StoreLeaseLock struct {
+	mu       sync.Mutex
+	store    apistorage.Interface
+	key      string
+	name     string
+	identity string
+	lease    *coordinationv1.Lease
+}
+
+// Get returns leader election record from lease in store, and remember lease for following update
+func (l *StoreLeaseLock) Get(ctx context.Context) (*resourcelock.LeaderElectionRecord, []byte, error) {
+	lease := &coordinationv1.Lease{}
+	if err := l.store.Get(ctx, l.key, apistorage.GetOptions{}, lease); err != nil {
+		if apistorage.IsNotFound(err) {
+			return nil, nil, apierrors.NewNotFound(LeaseGroupResource, l.name)
+		}
+		return nil, nil, err
+	}
+	l.mu.Lock()
+	l.lease = lease
+	l.mu.Unlock()
+
+	record := resourcelock.LeaseSpecToLeaderElectionRecord(&lease.Spec)
+	recordBytes, err := runtime.Encode(leaseCodecs.LegacyCodec(coordinationv1.SchemeGroupVersion), lease)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, recordBytes, nil
+}
+
+// Create creates lease in store using leader election record
+func (l *StoreLeaseLock) Create(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	lease := &coordinationv1.Lease{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Lease",
+			APIVersion: coordinationv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: l.name,
+		},
+		Spec: resourcelock.LeaderElectionRecordToLeaseSpec(&ler),
+	}
+	out := &coordinationv1.Lease{}
+	if err := l.store.Create(ctx, l.key, lease, out, 0); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.lease = out
+	l.mu.Unlock()
+	return nil
+}
+
+// Update updates lease in store if lease was not changed by others since last Get
+func (l *StoreLeaseLock) Update(ctx context.Context, ler resourcelock.LeaderElectionRecord) error {
+	l.mu.Lock()
+	observed := l.lease
+	l.mu.Unlock()
+	if observed == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+
+	out := &coordinationv1.Lease{}
+	tryUpdate := func(input runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		curr, ok := input.(*coordinationv1.Lease)
+		if !ok {
+			return nil, nil, fmt.Errorf("object in %s is not a lease", l.key)
+		}
+		if curr.ResourceVersion != observed.ResourceVersion {
+			return nil, nil, apierrors.NewConflict(LeaseGroupResource, l.name, errors.New("lease has been changed by another fornaxcore"))
+		}
+		lease := curr.DeepCopy()
+		lease.Spec = resourcelock.LeaderElectionRecordToLeaseSpec(&ler)
+		return lease, nil, nil
+	}
+	if err := l.store.GuaranteedUpdate(ctx, l.key, out, false, nil, tryUpdate, nil); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.lease = out
+	l.mu.Unlock()
+	return nil
+}
+
+// RecordEvent implements resourcelock.Interface, fornaxcore does not have a event recorder, just log it
+func (l *StoreLeaseLock) RecordEvent(s string) {
+	klog.InfoS("Leader election event", "lease", l.key, "event", s)
+}
+
+// Describe implements resourcelock.Interface
+func (l *StoreLeaseLock) Describe() string {
+	return l.key
+}
+
+// Identity implements resourcelock.Interface
+func (l *StoreLeaseLock) Identity() string {
+	return l.identity
+}
+
+func NewStoreLeaseLock(store apistorage.Interface, name, identity string) *StoreLeaseLock {
+	return &StoreLeaseLock{
+		mu:       sync.Mutex{},
+		store:    store,
+		key:      path.Join(DefaultLeasePrefix, name),
+		name:     name,
+		identity: identity,
+	}
+}
+
+// NewLeaseStorage create a apiserver storage which save lease objects in same backend storage of apiserver,
+// storage transport and prefix are copied from apiserver resource storage config
+func NewLeaseStorage(storageConfig *storagebackend.ConfigForResource) (apistorage.Interface, factory.DestroyFunc, error) {
+	codec := leaseCodecs.LegacyCodec(coordinationv1.SchemeGroupVersion)
+	config := storagebackend.NewDefaultConfig(storageConfig.Prefix, codec)
+	config.Type = storageConfig.Type
+	config.Transport = storageConfig.Transport
+	return factory.Create(*config.ForResource(LeaseGroupResource), func() runtime.Object { return &coordinationv1.Lease{} })
+}
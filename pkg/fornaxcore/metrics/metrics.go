@@ -0,0 +1,154 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// fornaxcore metrics are registered into legacy registry, api server of fornaxcore serve them on /metrics
+const (
+	FornaxCoreNamespace = "fornaxcore"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+var (
+	ApplicationSyncDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "application",
+			Name:           "sync_duration_seconds",
+			Help:           "Latency of syncing an application, partitioned by result.",
+			Buckets:        metrics.ExponentialBuckets(0.0001, 2, 16),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+
+	ApplicationPods = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "application",
+			Name:           "pods",
+			Help:           "Number of pods of an application, partitioned by pod state.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"application", "state"},
+	)
+
+	ApplicationSessions = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "application",
+			Name:           "sessions",
+			Help:           "Number of sessions of an application, partitioned by session state.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"application", "state"},
+	)
+
+	ScheduleAttempts = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "podscheduler",
+			Name:           "schedule_attempts_total",
+			Help:           "Number of attempts to schedule pods, partitioned by result.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"result"},
+	)
+
+	ScheduleFailures = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "podscheduler",
+			Name:           "schedule_failures_total",
+			Help:           "Number of failed attempts to schedule pods, partitioned by reason.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"reason"},
+	)
+
+	ScheduleQueueLength = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "podscheduler",
+			Name:           "queue_length",
+			Help:           "Number of pods waiting in pod schedule queue, partitioned by active and backoff queue.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"queue"},
+	)
+
+	Nodes = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "node",
+			Name:           "nodes",
+			Help:           "Number of nodes known by fornaxcore, partitioned by node working state.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"state"},
+	)
+
+	SessionStatusTransitionDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      FornaxCoreNamespace,
+			Subsystem:      "session",
+			Name:           "status_transition_duration_seconds",
+			Help:           "Time session stayed in old status before it transitioned to new status, partitioned by old and new status.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 16),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"from", "to"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// Register register all fornaxcore metrics into legacy registry
+func Register() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(ApplicationSyncDuration)
+		legacyregistry.MustRegister(ApplicationPods)
+		legacyregistry.MustRegister(ApplicationSessions)
+		legacyregistry.MustRegister(ScheduleAttempts)
+		legacyregistry.MustRegister(ScheduleFailures)
+		legacyregistry.MustRegister(ScheduleQueueLength)
+		legacyregistry.MustRegister(Nodes)
+		legacyregistry.MustRegister(SessionStatusTransitionDuration)
+	})
+}
+
+// SinceInSeconds gets the time since the specified start in seconds
+func SinceInSeconds(start time.Time) float64 {
+	return time.Since(start).Seconds()
+}
+
+// Result return result label value of an error
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
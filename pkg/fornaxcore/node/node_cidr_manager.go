@@ -16,21 +16,259 @@ limitations under the License.
 
 package node
 
-import v1 "k8s.io/api/core/v1"
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultClusterCidr          = "192.168.0.0/16"
+	DefaultNodeCidrMaskSizeIPv4 = 24
+	DefaultNodeCidrMaskSizeIPv6 = 64
+
+	// a cluster cidr is carved into at most 2^16 node cidrs
+	maxNodeCidrBits = 16
+)
+
+var (
+	NodeCidrExhaustedError = errors.New("cluster cidr has no available node pod cidr")
+)
+
+// NodeCidrConfig describe how node pod cidrs are allocated, at most one ipv4 and one ipv6 cluster cidr are supported,
+// order of cluster cidrs determine order of node PodCIDRs, and first one is node PodCIDR
+type NodeCidrConfig struct {
+	ClusterCidrs         []string
+	NodeCidrMaskSizeIPv4 int
+	NodeCidrMaskSizeIPv6 int
+}
+
+func DefaultNodeCidrConfig() *NodeCidrConfig {
+	return &NodeCidrConfig{
+		ClusterCidrs:         []string{DefaultClusterCidr},
+		NodeCidrMaskSizeIPv4: DefaultNodeCidrMaskSizeIPv4,
+		NodeCidrMaskSizeIPv6: DefaultNodeCidrMaskSizeIPv6,
+	}
+}
 
 type NodeCidrManager interface {
-	GetCidr(node *v1.Node) []string
+	// GetCidr return pod cidrs assigned to node, node keep pod cidrs it already has if they are not used by other nodes,
+	// otherwise new cidrs are allocated from cluster cidrs
+	GetCidr(node *v1.Node) ([]string, error)
+
+	// ReleaseCidr return pod cidrs assigned to node back to cluster cidrs
+	ReleaseCidr(node *v1.Node)
 }
 
 var _ NodeCidrManager = &nodeCidrManager{}
 
+// cidrSet carve a cluster cidr into node cidrs with same mask size, node cidr is identified by its index in cluster cidr
+type cidrSet struct {
+	clusterCidr   *net.IPNet
+	clusterIP     *big.Int
+	ipLen         int
+	nodeMaskSize  int
+	maxCidrs      int
+	nextCandidate int
+	owners        map[int]string
+}
+
+func newCidrSet(clusterCidr *net.IPNet, nodeMaskSize int) (*cidrSet, error) {
+	clusterMaskSize, bits := clusterCidr.Mask.Size()
+	if nodeMaskSize < clusterMaskSize || nodeMaskSize > bits {
+		return nil, fmt.Errorf("node cidr mask size %d is invalid for cluster cidr %s", nodeMaskSize, clusterCidr.String())
+	}
+	if nodeMaskSize-clusterMaskSize > maxNodeCidrBits {
+		return nil, fmt.Errorf("node cidr mask size %d is too large for cluster cidr %s, at most %d bits larger than cluster cidr mask size", nodeMaskSize, clusterCidr.String(), maxNodeCidrBits)
+	}
+
+	ip := clusterCidr.IP
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return &cidrSet{
+		clusterCidr:   clusterCidr,
+		clusterIP:     new(big.Int).SetBytes(ip),
+		ipLen:         len(ip),
+		nodeMaskSize:  nodeMaskSize,
+		maxCidrs:      1 << (nodeMaskSize - clusterMaskSize),
+		nextCandidate: 0,
+		owners:        map[int]string{},
+	}, nil
+}
+
+func (s *cidrSet) isIPv6() bool {
+	return s.ipLen == net.IPv6len
+}
+
+func (s *cidrSet) hostBits() uint {
+	return uint(s.ipLen*8 - s.nodeMaskSize)
+}
+
+func (s *cidrSet) indexToCidr(index int) string {
+	ip := new(big.Int).Lsh(big.NewInt(int64(index)), s.hostBits())
+	ip.Add(ip, s.clusterIP)
+	cidr := &net.IPNet{
+		IP:   net.IP(ip.FillBytes(make([]byte, s.ipLen))),
+		Mask: net.CIDRMask(s.nodeMaskSize, s.ipLen*8),
+	}
+	return cidr.String()
+}
+
+// cidrToIndex return index of a cidr if it is a node cidr of this cluster cidr
+func (s *cidrSet) cidrToIndex(cidr string) (int, bool) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return 0, false
+	}
+	maskSize, bits := ipNet.Mask.Size()
+	if maskSize != s.nodeMaskSize || bits != s.ipLen*8 || !s.clusterCidr.Contains(ip) {
+		return 0, false
+	}
+	nodeIP := ipNet.IP
+	if ip4 := nodeIP.To4(); ip4 != nil {
+		nodeIP = ip4
+	}
+	offset := new(big.Int).Sub(new(big.Int).SetBytes(nodeIP), s.clusterIP)
+	return int(offset.Rsh(offset, s.hostBits()).Int64()), true
+}
+
+func (s *cidrSet) occupy(index int, nodeName string) {
+	s.owners[index] = nodeName
+}
+
+func (s *cidrSet) allocate(nodeName string) (int, error) {
+	for i := 0; i < s.maxCidrs; i++ {
+		index := (s.nextCandidate + i) % s.maxCidrs
+		if _, found := s.owners[index]; !found {
+			s.owners[index] = nodeName
+			s.nextCandidate = (index + 1) % s.maxCidrs
+			return index, nil
+		}
+	}
+	return 0, NodeCidrExhaustedError
+}
+
+func (s *cidrSet) release(index int, nodeName string) {
+	if owner, found := s.owners[index]; found && owner == nodeName {
+		delete(s.owners, index)
+	}
+}
+
 type nodeCidrManager struct {
+	mu        sync.Mutex
+	cidrSets  []*cidrSet
+	nodeCidrs map[string][]string
 }
 
-func (*nodeCidrManager) GetCidr(node *v1.Node) []string {
-	return []string{"192.168.0.1/24"}
+// GetCidr implements NodeCidrManager
+func (m *nodeCidrManager) GetCidr(node *v1.Node) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nodeName := util.Name(node)
+	if cidrs, found := m.nodeCidrs[nodeName]; found {
+		return append([]string{}, cidrs...), nil
+	}
+
+	existingCidrs := append([]string{}, node.Spec.PodCIDRs...)
+	if len(existingCidrs) == 0 && len(node.Spec.PodCIDR) > 0 {
+		existingCidrs = append(existingCidrs, node.Spec.PodCIDR)
+	}
+
+	cidrs := []string{}
+	indexes := []int{}
+	for _, set := range m.cidrSets {
+		index, reused := m.reuseExistingCidr(set, nodeName, existingCidrs)
+		if !reused {
+			var err error
+			if index, err = set.allocate(nodeName); err != nil {
+				for i, v := range indexes {
+					m.cidrSets[i].release(v, nodeName)
+				}
+				return nil, err
+			}
+		}
+		indexes = append(indexes, index)
+		cidrs = append(cidrs, set.indexToCidr(index))
+	}
+
+	m.nodeCidrs[nodeName] = cidrs
+	klog.InfoS("Assigned pod cidrs to node", "node", nodeName, "cidrs", cidrs)
+	return append([]string{}, cidrs...), nil
+}
+
+// reuseExistingCidr let a registering node keep pod cidr it already has, e.g. node reconnect after fornaxcore restart,
+// cidr is not reused if it does not belong to cluster cidr or it has been assigned to another node
+func (m *nodeCidrManager) reuseExistingCidr(set *cidrSet, nodeName string, existingCidrs []string) (int, bool) {
+	for _, cidr := range existingCidrs {
+		index, ok := set.cidrToIndex(cidr)
+		if !ok {
+			continue
+		}
+		if owner, found := set.owners[index]; found && owner != nodeName {
+			klog.Warningf("Node %s pod cidr %s conflicts with node %s, allocate a new pod cidr", nodeName, cidr, owner)
+			return 0, false
+		}
+		set.occupy(index, nodeName)
+		return index, true
+	}
+	return 0, false
+}
+
+// ReleaseCidr implements NodeCidrManager
+func (m *nodeCidrManager) ReleaseCidr(node *v1.Node) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nodeName := util.Name(node)
+	cidrs, found := m.nodeCidrs[nodeName]
+	if !found {
+		return
+	}
+	for i, set := range m.cidrSets {
+		if index, ok := set.cidrToIndex(cidrs[i]); ok {
+			set.release(index, nodeName)
+		}
+	}
+	delete(m.nodeCidrs, nodeName)
+	klog.InfoS("Released pod cidrs of node", "node", nodeName, "cidrs", cidrs)
 }
 
-func NewPodCidrManager() NodeCidrManager {
-	return &nodeCidrManager{}
+func NewPodCidrManager(config *NodeCidrConfig) (NodeCidrManager, error) {
+	m := &nodeCidrManager{
+		mu:        sync.Mutex{},
+		cidrSets:  []*cidrSet{},
+		nodeCidrs: map[string][]string{},
+	}
+	hasIPv4, hasIPv6 := false, false
+	for _, v := range config.ClusterCidrs {
+		_, clusterCidr, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("cluster cidr %s is invalid, %v", v, err)
+		}
+		maskSize := config.NodeCidrMaskSizeIPv4
+		if clusterCidr.IP.To4() == nil {
+			maskSize = config.NodeCidrMaskSizeIPv6
+		}
+		set, err := newCidrSet(clusterCidr, maskSize)
+		if err != nil {
+			return nil, err
+		}
+		if (set.isIPv6() && hasIPv6) || (!set.isIPv6() && hasIPv4) {
+			return nil, fmt.Errorf("at most one ipv4 and one ipv6 cluster cidr are allowed, got %v", config.ClusterCidrs)
+		}
+		hasIPv4, hasIPv6 = hasIPv4 || !set.isIPv6(), hasIPv6 || set.isIPv6()
+		m.cidrSets = append(m.cidrSets, set)
+	}
+	if len(m.cidrSets) == 0 {
+		return nil, errors.New("cluster cidr is not provided")
+	}
+	return m, nil
 }
@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestNode(name string, podCidrs ...string) *v1.Node {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if len(podCidrs) > 0 {
+		node.Spec.PodCIDR = podCidrs[0]
+		node.Spec.PodCIDRs = podCidrs
+	}
+	return node
+}
+
+func TestNodeCidrAllocation(t *testing.T) {
+	m, err := NewPodCidrManager(&NodeCidrConfig{
+		ClusterCidrs:         []string{"10.10.0.0/22", "fd00:10::/56"},
+		NodeCidrMaskSizeIPv4: 24,
+		NodeCidrMaskSizeIPv6: 64,
+	})
+	if err != nil {
+		t.Fatalf("failed to create cidr manager, %v", err)
+	}
+
+	cidrs, err := m.GetCidr(newTestNode("node1"))
+	if err != nil {
+		t.Fatalf("failed to allocate cidr, %v", err)
+	}
+	if !reflect.DeepEqual(cidrs, []string{"10.10.0.0/24", "fd00:10::/64"}) {
+		t.Errorf("unexpected node1 cidrs %v", cidrs)
+	}
+	if again, _ := m.GetCidr(newTestNode("node1")); !reflect.DeepEqual(again, cidrs) {
+		t.Errorf("expect node1 keep cidrs %v, got %v", cidrs, again)
+	}
+
+	// node2 register with cidrs it had before fornaxcore restart
+	cidrs, err = m.GetCidr(newTestNode("node2", "10.10.2.0/24", "fd00:10:0:5::/64"))
+	if err != nil {
+		t.Fatalf("failed to allocate cidr, %v", err)
+	}
+	if !reflect.DeepEqual(cidrs, []string{"10.10.2.0/24", "fd00:10:0:5::/64"}) {
+		t.Errorf("expect node2 keep existing cidrs, got %v", cidrs)
+	}
+
+	// node3 register with cidr owned by node1, a new cidr is allocated
+	cidrs, err = m.GetCidr(newTestNode("node3", "10.10.0.0/24"))
+	if err != nil {
+		t.Fatalf("failed to allocate cidr, %v", err)
+	}
+	if !reflect.DeepEqual(cidrs, []string{"10.10.1.0/24", "fd00:10:0:1::/64"}) {
+		t.Errorf("expect node3 get new cidrs, got %v", cidrs)
+	}
+
+	cidrs, err = m.GetCidr(newTestNode("node4"))
+	if err != nil {
+		t.Fatalf("failed to allocate cidr, %v", err)
+	}
+	if cidrs[0] != "10.10.3.0/24" {
+		t.Errorf("expect node4 get last ipv4 cidr, got %v", cidrs)
+	}
+	if _, err = m.GetCidr(newTestNode("node5")); err != NodeCidrExhaustedError {
+		t.Errorf("expect cidr exhausted error, got %v", err)
+	}
+
+	// cidr of removed node is reused
+	m.ReleaseCidr(newTestNode("node1"))
+	cidrs, err = m.GetCidr(newTestNode("node5"))
+	if err != nil {
+		t.Fatalf("failed to allocate cidr after release, %v", err)
+	}
+	if cidrs[0] != "10.10.0.0/24" {
+		t.Errorf("expect node5 reuse released cidr, got %v", cidrs)
+	}
+}
@@ -17,25 +17,259 @@ limitations under the License.
 package node
 
 import (
-	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultNodeDaemonConfigCheckPeriod = 10 * time.Second
 )
 
 type NodeDaemonManager interface {
-	GetDaemons(node *ie.FornaxNodeWithState) map[string]*v1.Pod
+	// GetDaemons return daemon pods which should run on node, key is daemon pod name
+	GetDaemons(node *v1.Node) map[string]*v1.Pod
+
+	// Watch add a watcher, watcher is notified when daemon declarations changed
+	Watch(watcher chan<- struct{})
+
+	// Run begin to check daemon declarations change until ctx is done
+	Run(ctx context.Context)
 }
 
 var _ NodeDaemonManager = &nodeDaemonManager{}
 
+// nodeDaemonManager load DaemonSets from a yaml config file, a daemon pod is created from DaemonSet template for every node
+// matching template node selector, config file is checked periodically, and watchers are notified when DaemonSets changed,
+// daemon pods are annotated with hash of DaemonSet template, so, node agent know when a running daemon need to be recreated
 type nodeDaemonManager struct {
+	mu          sync.RWMutex
+	configFile  string
+	modTime     time.Time
+	daemonSets  []*appsv1.DaemonSet
+	watchers    []chan<- struct{}
+	checkPeriod time.Duration
 }
 
 // GetDaemons implements NodeDaemonManager
-func (*nodeDaemonManager) GetDaemons(node *ie.FornaxNodeWithState) map[string]*v1.Pod {
-	// TODO manage real daemon pods
-	return map[string]*v1.Pod{}
+func (m *nodeDaemonManager) GetDaemons(node *v1.Node) map[string]*v1.Pod {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	daemons := map[string]*v1.Pod{}
+	if node == nil {
+		return daemons
+	}
+	nodeLabels := labels.Set(node.Labels)
+	for _, ds := range m.daemonSets {
+		selector := labels.SelectorFromSet(ds.Spec.Template.Spec.NodeSelector)
+		if !selector.Matches(nodeLabels) {
+			continue
+		}
+		pod := buildDaemonPod(ds, util.Name(node))
+		daemons[util.Name(pod)] = pod
+	}
+	return daemons
+}
+
+// Watch implements NodeDaemonManager
+func (m *nodeDaemonManager) Watch(watcher chan<- struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.watchers = append(m.watchers, watcher)
+}
+
+// Run implements NodeDaemonManager
+func (m *nodeDaemonManager) Run(ctx context.Context) {
+	if len(m.configFile) == 0 {
+		return
+	}
+	klog.InfoS("Starting node daemon manager", "config", m.configFile)
+	go func() {
+		ticker := time.NewTicker(m.checkPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				changed, err := m.loadConfigFile()
+				if err != nil {
+					klog.ErrorS(err, "Failed to load node daemon config, keep using current daemons", "config", m.configFile)
+					continue
+				}
+				if changed {
+					m.notifyWatchers()
+				}
+			}
+		}
+	}()
+}
+
+func (m *nodeDaemonManager) notifyWatchers() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, watcher := range m.watchers {
+		select {
+		case watcher <- struct{}{}:
+		default:
+			// a notification is already pending, watcher will read latest daemons
+		}
+	}
+}
+
+// loadConfigFile reload DaemonSets if config file is modified since last load, return true if DaemonSets are reloaded
+func (m *nodeDaemonManager) loadConfigFile() (bool, error) {
+	info, err := os.Stat(m.configFile)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(m.modTime) {
+		return false, nil
+	}
+
+	f, err := os.Open(m.configFile)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	daemonSets, err := decodeDaemonSets(f)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.daemonSets = daemonSets
+	m.modTime = info.ModTime()
+	klog.InfoS("Loaded node daemons", "config", m.configFile, "#daemonset", len(daemonSets))
+	return true, nil
+}
+
+// decodeDaemonSets decode a stream of yaml or json DaemonSet documents, and validate them as node daemons
+func decodeDaemonSets(r io.Reader) ([]*appsv1.DaemonSet, error) {
+	daemonSets := []*appsv1.DaemonSet{}
+	names := map[string]bool{}
+	decoder := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		ds := &appsv1.DaemonSet{}
+		if err := decoder.Decode(ds); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(ds.Name) == 0 && len(ds.Spec.Template.Spec.Containers) == 0 {
+			// empty document
+			continue
+		}
+		if err := validateDaemonSet(ds); err != nil {
+			return nil, err
+		}
+		if names[util.Name(ds)] {
+			return nil, fmt.Errorf("daemonset %s is declared more than once", util.Name(ds))
+		}
+		names[util.Name(ds)] = true
+		daemonSets = append(daemonSets, ds)
+	}
+	return daemonSets, nil
+}
+
+// validateDaemonSet check daemon pod spec requirements of node agent, and set default namespace
+func validateDaemonSet(ds *appsv1.DaemonSet) error {
+	if len(ds.Name) == 0 {
+		return errors.New("daemonset name is not provided")
+	}
+	if len(ds.Namespace) == 0 {
+		ds.Namespace = metav1.NamespaceDefault
+	}
+	if len(ds.Spec.Template.Spec.Containers) != 1 {
+		return fmt.Errorf("daemonset %s can only have one container, but it has %d container(s)", util.Name(ds), len(ds.Spec.Template.Spec.Containers))
+	}
+	if !ds.Spec.Template.Spec.HostNetwork {
+		return fmt.Errorf("daemonset %s must use host network", util.Name(ds))
+	}
+	return nil
+}
+
+// buildDaemonPod create a daemon pod of a node from DaemonSet template, pod name include node name to be unique in cluster
+func buildDaemonPod(ds *appsv1.DaemonSet, nodeName string) *v1.Pod {
+	template := ds.Spec.Template.DeepCopy()
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%s", ds.Name, nodeName),
+			Namespace:   ds.Namespace,
+			Labels:      template.Labels,
+			Annotations: template.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	if pod.Labels == nil {
+		pod.Labels = map[string]string{}
+	}
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Labels[fornaxv1.LabelFornaxCoreNodeDaemon] = "true"
+	pod.Annotations[fornaxv1.AnnotationFornaxCoreNodeDaemonHash] = daemonSetTemplateHash(ds)
+	return pod
+}
+
+func daemonSetTemplateHash(ds *appsv1.DaemonSet) string {
+	data, _ := json.Marshal(ds.Spec.Template)
+	hasher := fnv.New64a()
+	hasher.Write(data)
+	return fmt.Sprintf("%x", hasher.Sum64())
+}
+
+// DaemonsChanged return true if daemons are added, removed, or their templates changed,
+// nil current daemons means daemons on node are unknown, e.g. node is recovered from a full sync, they are treated as changed
+func DaemonsChanged(current, desired map[string]*v1.Pod) bool {
+	if current == nil || len(current) != len(desired) {
+		return true
+	}
+	for name, pod := range desired {
+		existing, found := current[name]
+		if !found || existing.Annotations[fornaxv1.AnnotationFornaxCoreNodeDaemonHash] != pod.Annotations[fornaxv1.AnnotationFornaxCoreNodeDaemonHash] {
+			return true
+		}
+	}
+	return false
 }
 
-func NewNodeDaemonManager() NodeDaemonManager {
-	return &nodeDaemonManager{}
+// NewNodeDaemonManager create a node daemon manager using DaemonSets declared in configFile,
+// if configFile is empty, node does not run any daemon
+func NewNodeDaemonManager(configFile string) (NodeDaemonManager, error) {
+	m := &nodeDaemonManager{
+		mu:          sync.RWMutex{},
+		configFile:  configFile,
+		daemonSets:  []*appsv1.DaemonSet{},
+		watchers:    []chan<- struct{}{},
+		checkPeriod: DefaultNodeDaemonConfigCheckPeriod,
+	}
+	if len(configFile) > 0 {
+		if _, err := m.loadConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+)
+
+func TestNodeDaemonManager(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "daemons.yaml")
+	data, err := os.ReadFile("../../../hack/test-data/node-daemons.yaml")
+	if err != nil {
+		t.Fatalf("failed to read test data, %v", err)
+	}
+	if err := os.WriteFile(config, data, 0644); err != nil {
+		t.Fatalf("failed to write config, %v", err)
+	}
+	m, err := NewNodeDaemonManager(config)
+	if err != nil {
+		t.Fatalf("failed to load daemons, %v", err)
+	}
+
+	node := newTestNode("node1")
+	daemons := m.GetDaemons(node)
+	if len(daemons) != 1 || daemons["fornax-system/log-shipper-node1"] == nil {
+		t.Fatalf("expect only log shipper on node without ingress label, got %v", daemons)
+	}
+	if daemons["fornax-system/log-shipper-node1"].Labels[fornaxv1.LabelFornaxCoreNodeDaemon] != "true" {
+		t.Errorf("expect daemon pod has daemon label")
+	}
+
+	node.Labels = map[string]string{"node.fornax-serverless.centaurusinfra.io/ingress": "true"}
+	labeled := m.GetDaemons(node)
+	if len(labeled) != 2 || !DaemonsChanged(daemons, labeled) {
+		t.Fatalf("expect ingress sidecar on labeled node, got %v", labeled)
+	}
+	if DaemonsChanged(labeled, m.GetDaemons(node)) {
+		t.Errorf("expect daemons unchanged when daemon sets unchanged")
+	}
+
+	// change log shipper image, daemon template hash change
+	watcher := make(chan struct{}, 1)
+	m.Watch(watcher)
+	updated := strings.Replace(string(data), "fluent/fluent-bit:1.9", "fluent/fluent-bit:2.0", 1)
+	if err := os.WriteFile(config, []byte(updated), 0644); err != nil {
+		t.Fatalf("failed to write config, %v", err)
+	}
+	os.Chtimes(config, time.Now(), time.Now().Add(time.Second))
+	changed, err := m.(*nodeDaemonManager).loadConfigFile()
+	if err != nil || !changed {
+		t.Fatalf("expect config reloaded, changed %v, err %v", changed, err)
+	}
+	m.(*nodeDaemonManager).notifyWatchers()
+	select {
+	case <-watcher:
+	default:
+		t.Errorf("expect watcher notified")
+	}
+	if !DaemonsChanged(labeled, m.GetDaemons(node)) {
+		t.Errorf("expect daemons changed when daemon template changed")
+	}
+
+	if _, err := decodeDaemonSets(strings.NewReader(strings.Replace(string(data), "hostNetwork: true", "hostNetwork: false", 1))); err == nil {
+		t.Errorf("expect daemon set without host network is rejected")
+	}
+}
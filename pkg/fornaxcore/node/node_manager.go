@@ -19,34 +19,45 @@ package node
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"sync"
 
 	// "sync"
 	"time"
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/collection"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	fornaxpod "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/pod"
 	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
 	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
 // todo: determine more proper timeout
 const (
 	DefaultStaleNodeTimeout = 120 * time.Second
-	MaxlengthOfNodeUpdates  = 5000
+	// stale nodes are checked more often than stale node timeout, so a node is removed soon after it become stale
+	DefaultNodeHouseKeepingPeriod = DefaultStaleNodeTimeout / 4
+	MaxlengthOfNodeUpdates        = 5000
 )
 
 var _ ie.NodeManagerInterface = &nodeManager{}
 
 type nodeManager struct {
-	ctx                context.Context
+	ctx context.Context
+	// stateMu guard Node, State, LastSeen and DaemonPods of nodes, they are changed by node messages, node connection and house keeping concurrently,
+	// it's only held when these fields are read or written, not when calling other components
+	stateMu            sync.RWMutex
 	nodeUpdates        chan *ie.NodeEvent
 	watchers           []chan<- *ie.NodeEvent
 	nodeStore          fornaxstore.ApiStorageInterface
@@ -56,7 +67,55 @@ type nodeManager struct {
 	sessionManager     ie.SessionManagerInterface
 	nodePodCidrManager NodeCidrManager
 	nodeDaemonManager  NodeDaemonManager
+	daemonUpdates      chan struct{}
+	eventRecorder      record.EventRecorder
 	houseKeepingTicker *time.Ticker
+	// reopen starting sessions which node does not report in full sync, it's nil if nobody reconcile sessions
+	podSessionReconciler ie.PodSessionReconcilerInterface
+}
+
+func (nm *nodeManager) getNode(fornaxNode *ie.FornaxNodeWithState) *v1.Node {
+	nm.stateMu.RLock()
+	defer nm.stateMu.RUnlock()
+	return fornaxNode.Node
+}
+
+// setNode replace node of a fornax node, node object is never changed in place after it's set, so, it can be used after lock is released
+func (nm *nodeManager) setNode(fornaxNode *ie.FornaxNodeWithState, node *v1.Node) {
+	nm.stateMu.Lock()
+	defer nm.stateMu.Unlock()
+	fornaxNode.Node = node
+}
+
+func (nm *nodeManager) getNodeState(fornaxNode *ie.FornaxNodeWithState) ie.NodeWorkingState {
+	nm.stateMu.RLock()
+	defer nm.stateMu.RUnlock()
+	return fornaxNode.State
+}
+
+func (nm *nodeManager) setNodeState(fornaxNode *ie.FornaxNodeWithState, state ie.NodeWorkingState) {
+	nm.stateMu.Lock()
+	defer nm.stateMu.Unlock()
+	fornaxNode.State = state
+}
+
+func (nm *nodeManager) getNodeDaemons(fornaxNode *ie.FornaxNodeWithState) map[string]*v1.Pod {
+	nm.stateMu.RLock()
+	defer nm.stateMu.RUnlock()
+	return fornaxNode.DaemonPods
+}
+
+func (nm *nodeManager) setNodeDaemons(fornaxNode *ie.FornaxNodeWithState, daemons map[string]*v1.Pod) {
+	nm.stateMu.Lock()
+	defer nm.stateMu.Unlock()
+	fornaxNode.DaemonPods = daemons
+}
+
+// touchNode update last seen time of a node when fornaxcore receive message from it
+func (nm *nodeManager) touchNode(fornaxNode *ie.FornaxNodeWithState) {
+	nm.stateMu.Lock()
+	defer nm.stateMu.Unlock()
+	fornaxNode.LastSeen = time.Now()
 }
 
 // Watch add a watcher, and beging to send NodeEvent to watcher
@@ -69,7 +128,7 @@ func (nm *nodeManager) List() []*ie.NodeEvent {
 	nodeEvents := []*ie.NodeEvent{}
 	for _, v := range nm.nodes.list() {
 		nodeEvents = append(nodeEvents, &ie.NodeEvent{
-			Node: v.Node.DeepCopy(),
+			Node: nm.getNode(v).DeepCopy(),
 			Type: ie.NodeEventTypeCreate,
 		})
 	}
@@ -95,7 +154,7 @@ func (nm *nodeManager) UpdateSessionState(nodeId string, session *fornaxv1.Appli
 func (nm *nodeManager) UpdatePodState(nodeId string, pod *v1.Pod, sessions []*fornaxv1.ApplicationSession) error {
 	podName := util.Name(pod)
 	if nodeWS := nm.nodes.get(nodeId); nodeWS != nil {
-		nodeWS.LastSeen = time.Now()
+		nm.touchNode(nodeWS)
 		if existingPod := nm.podManager.FindPod(podName); existingPod != nil {
 			if largerRv, _ := util.NodeRevisionLargerThan(pod, existingPod); !largerRv {
 				return nil
@@ -109,6 +168,12 @@ func (nm *nodeManager) UpdatePodState(nodeId string, pod *v1.Pod, sessions []*fo
 		for _, session := range sessions {
 			nm.sessionManager.OnSessionStatusFromNode(nodeId, updatedPod, session)
 		}
+		// pod reported after node was requested to evacuate, evacuate it also
+		if util.NodeHasEvacuateAnnotation(nm.getNode(nodeWS)) && podNeedEvacuation(updatedPod) {
+			if err := nm.podManager.EvacuatePod(podName); err != nil {
+				klog.ErrorS(err, "Failed to evacuate pod on evacuating node", "pod", podName, "node", nodeId)
+			}
+		}
 	} else {
 		// not supposed to happend node state is send when node register
 		klog.Warningf("Node %s does not exist, ask node to do full sync", nodeId)
@@ -125,7 +190,7 @@ func (nm *nodeManager) SyncPodStates(nodeId string, podStates []*grpc.PodState)
 		return
 	}
 
-	nodeWS.LastSeen = time.Now()
+	nm.touchNode(nodeWS)
 	existingPodNames := nodeWS.Pods.GetKeys()
 	reportedPods := map[string]bool{}
 	for _, podState := range podStates {
@@ -141,6 +206,12 @@ func (nm *nodeManager) SyncPodStates(nodeId string, podStates []*grpc.PodState)
 		err = nm.UpdatePodState(nodeId, podState.GetPod().DeepCopy(), sessions)
 		if err != nil {
 			klog.ErrorS(err, "Failed to update a pod state, wait for next sync", "pod", podName)
+			continue
+		}
+		if nm.podSessionReconciler != nil {
+			if pod := nm.podManager.FindPod(podName); pod != nil {
+				nm.podSessionReconciler.ReconcilePodSessions(pod, sessions)
+			}
 		}
 	}
 
@@ -176,11 +247,13 @@ func (nm *nodeManager) SyncPodStates(nodeId string, podStates []*grpc.PodState)
 // 2/ node daemons
 // it also return daemon pods node should initialize before taking service pods
 func (nm *nodeManager) UpdateNodeState(nodeId string, node *v1.Node) (fornaxNode *ie.FornaxNodeWithState, err error) {
-	cidrs := nm.nodePodCidrManager.GetCidr(node)
-	if node.Spec.PodCIDR != cidrs[0] {
-		node.Spec.PodCIDR = cidrs[0]
-		node.Spec.PodCIDRs = cidrs
+	cidrs, err := nm.nodePodCidrManager.GetCidr(node)
+	if err != nil {
+		klog.ErrorS(err, "Failed to assign pod cidr to node", "node", nodeId)
+		return nil, err
 	}
+	node.Spec.PodCIDR = cidrs[0]
+	node.Spec.PodCIDRs = cidrs
 	if fornaxNode = nm.nodes.get(nodeId); fornaxNode != nil {
 		if fornaxNode, err = nm.updateNode(nodeId, node); err != nil {
 			klog.ErrorS(err, "Failed to update a node", "node", node)
@@ -193,10 +266,45 @@ func (nm *nodeManager) UpdateNodeState(nodeId string, node *v1.Node) (fornaxNode
 		}
 	}
 
-	// recalculate daemon pods on node always to make sure node has correct setup
-	daemons := nm.nodeDaemonManager.GetDaemons(fornaxNode)
-	fornaxNode.DaemonPods = daemons
-	return fornaxNode, nil
+	// recalculate daemon pods on node always to make sure node has correct setup,
+	// a running node is asked to reconcile its daemons if they changed, e.g. node labels changed or node reconnected
+	daemons := nm.nodeDaemonManager.GetDaemons(nm.getNode(fornaxNode))
+	if nm.getNodeState(fornaxNode) == ie.NodeWorkingStateRunning && DaemonsChanged(nm.getNodeDaemons(fornaxNode), daemons) {
+		nm.configureNodeDaemons(fornaxNode, daemons)
+	}
+	nm.setNodeDaemons(fornaxNode, daemons)
+
+	// return a snapshot, node is changed by other messages after it's returned
+	nm.stateMu.RLock()
+	defer nm.stateMu.RUnlock()
+	snapshot := *fornaxNode
+	return &snapshot, nil
+}
+
+// configureNodeDaemons send node configuration with daemons to node, node agent create, recreate or terminate its daemons accordingly
+func (nm *nodeManager) configureNodeDaemons(fornaxNode *ie.FornaxNodeWithState, daemons map[string]*v1.Pod) {
+	nm.setNodeDaemons(fornaxNode, daemons)
+	pods := []*v1.Pod{}
+	for _, v := range daemons {
+		pods = append(pods, v.DeepCopy())
+	}
+	klog.InfoS("Roll out daemons to node", "node", fornaxNode.NodeId, "#daemon", len(pods))
+	if err := nm.nodeAgent.ConfigureNode(fornaxNode.NodeId, nm.getNode(fornaxNode), pods); err != nil {
+		klog.ErrorS(err, "Failed to roll out daemons to node", "node", fornaxNode.NodeId)
+	}
+}
+
+// rolloutDaemons push changed daemons to running nodes, disconnected nodes get their daemons when they connect again
+func (nm *nodeManager) rolloutDaemons() {
+	for _, v := range nm.nodes.list() {
+		if nm.getNodeState(v) != ie.NodeWorkingStateRunning {
+			continue
+		}
+		daemons := nm.nodeDaemonManager.GetDaemons(nm.getNode(v))
+		if DaemonsChanged(nm.getNodeDaemons(v), daemons) {
+			nm.configureNodeDaemons(v, daemons)
+		}
+	}
 }
 
 func (nm *nodeManager) createOrUpdateNodeInStore(node *v1.Node) (*v1.Node, error) {
@@ -211,8 +319,14 @@ func (nm *nodeManager) createOrUpdateNodeInStore(node *v1.Node) (*v1.Node, error
 			return nil, err
 		}
 	} else {
-		util.MergeNodeStatus(nodeInStore, node)
-		nodeInStore, err = factory.UpdateFornaxNode(nm.ctx, nm.nodeStore, nodeInStore)
+		// other fields of node in store, e.g. annotations, are changed by others concurrently, only update node status and pod cidrs
+		nodeInStore, err = factory.UpdateFornaxNodeWithFunc(nm.ctx, nm.nodeStore, util.Name(node), func(nodeInStore *v1.Node) error {
+			util.MergeNodeStatus(nodeInStore, node)
+			// pod cidrs are assigned by fornaxcore, keep them in store, so, they are sent back to node when it register again
+			nodeInStore.Spec.PodCIDR = node.Spec.PodCIDR
+			nodeInStore.Spec.PodCIDRs = node.Spec.PodCIDRs
+			return nil
+		})
 		if err != nil {
 			return nil, err
 		}
@@ -232,7 +346,7 @@ func (nm *nodeManager) createNode(nodeId string, node *v1.Node) (fornaxNode *ie.
 		Revision:   node.ResourceVersion,
 		State:      ie.NodeWorkingStateRegistering,
 		Pods:       collection.NewConcurrentSet(),
-		DaemonPods: map[string]*v1.Pod{},
+		DaemonPods: nil,
 		LastSeen:   time.Now(),
 	}
 
@@ -245,9 +359,8 @@ func (nm *nodeManager) createNode(nodeId string, node *v1.Node) (fornaxNode *ie.
 		return nil, err
 	}
 	fornaxNode.Node = nodeInStore
-	daemons := nm.nodeDaemonManager.GetDaemons(fornaxNode)
-	fornaxNode.DaemonPods = daemons
 	nm.nodes.add(util.Name(node), fornaxNode)
+	nm.recordNodeMetrics()
 	nm.nodeUpdates <- &ie.NodeEvent{
 		Node: nodeInStore.DeepCopy(),
 		Type: ie.NodeEventTypeCreate,
@@ -258,18 +371,19 @@ func (nm *nodeManager) createNode(nodeId string, node *v1.Node) (fornaxNode *ie.
 // updateNode implements NodeManager
 func (nm *nodeManager) updateNode(nodeId string, node *v1.Node) (*ie.FornaxNodeWithState, error) {
 	if fornaxNode := nm.nodes.get(nodeId); fornaxNode != nil {
-		if util.IsNodeCondtionReady(node) {
-			fornaxNode.State = ie.NodeWorkingStateRunning
+		if util.IsNodeCondtionReady(node) && nm.getNodeState(fornaxNode) != ie.NodeWorkingStateRunning {
+			nm.setNodeState(fornaxNode, ie.NodeWorkingStateRunning)
+			nm.recordNodeMetrics()
 		}
-		fornaxNode.LastSeen = time.Now()
+		nm.touchNode(fornaxNode)
 
 		nodeInStore, err := nm.createOrUpdateNodeInStore(node)
 		if err != nil {
 			return nil, err
 		}
-		fornaxNode.Node = nodeInStore
+		nm.setNode(fornaxNode, nodeInStore)
 		nm.nodeUpdates <- &ie.NodeEvent{
-			Node: fornaxNode.Node.DeepCopy(),
+			Node: nodeInStore.DeepCopy(),
 			Type: ie.NodeEventTypeUpdate,
 		}
 		return fornaxNode, nil
@@ -278,33 +392,250 @@ func (nm *nodeManager) updateNode(nodeId string, node *v1.Node) (*ie.FornaxNodeW
 	}
 }
 
-// DeleteNode send node event tell node not schedulable, it got removed from list after a graceful period
+// DisconnectNode send node event tell node not schedulable, it got removed by house keeping after DefaultStaleNodeTimeout
 func (nm *nodeManager) DisconnectNode(nodeId string) error {
 	if fornaxNode := nm.nodes.get(nodeId); fornaxNode != nil {
-		fornaxNode.State = ie.NodeWorkingStateDisconnected
-		node := fornaxNode.Node.DeepCopy()
+		nm.setNodeState(fornaxNode, ie.NodeWorkingStateDisconnected)
+		nm.recordNodeMetrics()
+		node := nm.getNode(fornaxNode).DeepCopy()
+		nm.eventRecorder.Eventf(node, v1.EventTypeWarning, "NodeDisconnected", "Node disconnected from fornaxcore, it is removed if it does not connect again in %s", DefaultStaleNodeTimeout)
 		node.Status.Phase = v1.NodePending
 		nodeInStore, err := nm.createOrUpdateNodeInStore(node)
 		if err != nil {
 			return err
 		}
-		fornaxNode.Node = nodeInStore
+		nm.setNode(fornaxNode, nodeInStore)
 		nm.nodeUpdates <- &ie.NodeEvent{
-			Node: fornaxNode.Node.DeepCopy(),
+			Node: nodeInStore.DeepCopy(),
 			Type: ie.NodeEventTypeUpdate,
 		}
 	}
 	return nil
 }
 
+// EvacuateNode add evacuate annotation on a node, node store watch pick it up and evacuate node,
+// if node already has evacuate annotation, evacuate it again
+func (nm *nodeManager) EvacuateNode(nodeId string) error {
+	fornaxNode := nm.nodes.get(nodeId)
+	if fornaxNode == nil {
+		return nodeagent.NodeNotFoundError
+	}
+	nodeInStore, err := factory.GetFornaxNodeCache(nm.nodeStore, util.Name(nm.getNode(fornaxNode)))
+	if err != nil {
+		return err
+	}
+	if nodeInStore == nil {
+		return nodeagent.NodeNotFoundError
+	}
+
+	if util.NodeHasEvacuateAnnotation(nodeInStore) {
+		return nm.evacuateNode(fornaxNode)
+	}
+
+	_, err = factory.UpdateFornaxNodeWithFunc(nm.ctx, nm.nodeStore, util.Name(nodeInStore), func(nodeInStore *v1.Node) error {
+		annotations := map[string]string{}
+		for k, v := range nodeInStore.GetAnnotations() {
+			annotations[k] = v
+		}
+		annotations[fornaxv1.AnnotationFornaxCoreEvacuate] = "true"
+		nodeInStore.Annotations = annotations
+		return nil
+	})
+	return err
+}
+
+// evacuateNode set node unschedulable and evacuate all pods on node except node daemons,
+// node is kept unschedulable until evacuate annotation is removed
+func (nm *nodeManager) evacuateNode(fornaxNode *ie.FornaxNodeWithState) error {
+	klog.InfoS("Evacuate node", "node", fornaxNode.NodeId, "#pod", fornaxNode.Pods.Len())
+	if err := nm.setNodeUnschedulable(fornaxNode, true); err != nil {
+		return err
+	}
+	for _, podName := range fornaxNode.Pods.GetKeys() {
+		pod := nm.podManager.FindPod(podName)
+		if pod == nil || !podNeedEvacuation(pod) {
+			continue
+		}
+		if err := nm.podManager.EvacuatePod(podName); err != nil {
+			klog.ErrorS(err, "Failed to evacuate pod on node", "pod", podName, "node", fornaxNode.NodeId)
+		}
+	}
+	return nil
+}
+
+func (nm *nodeManager) setNodeUnschedulable(fornaxNode *ie.FornaxNodeWithState, unschedulable bool) error {
+	nodeInStore, err := factory.GetFornaxNodeCache(nm.nodeStore, util.Name(nm.getNode(fornaxNode)))
+	if err != nil {
+		return err
+	}
+	if nodeInStore == nil {
+		return nodeagent.NodeNotFoundError
+	}
+	if nodeInStore.Spec.Unschedulable != unschedulable {
+		nodeInStore, err = factory.UpdateFornaxNodeWithFunc(nm.ctx, nm.nodeStore, util.Name(nodeInStore), func(nodeInStore *v1.Node) error {
+			nodeInStore.Spec.Unschedulable = unschedulable
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	nm.setNode(fornaxNode, nodeInStore)
+	nm.nodeUpdates <- &ie.NodeEvent{
+		Node: nodeInStore.DeepCopy(),
+		Type: ie.NodeEventTypeUpdate,
+	}
+	return nil
+}
+
+// onNodeEventFromStore evacuate a node when clients add evacuate annotation on it,
+// and make it schedulable again when annotation is removed
+func (nm *nodeManager) onNodeEventFromStore(we fornaxstore.WatchEventWithOldObj) {
+	if we.Type != watch.Modified {
+		return
+	}
+	oldNode, newNode := we.OldObject.(*v1.Node), we.Object.(*v1.Node)
+	fornaxNode := nm.nodes.get(util.Name(newNode))
+	if fornaxNode == nil {
+		return
+	}
+	var err error
+	switch {
+	case !util.NodeHasEvacuateAnnotation(oldNode) && util.NodeHasEvacuateAnnotation(newNode):
+		err = nm.evacuateNode(fornaxNode)
+	case util.NodeHasEvacuateAnnotation(oldNode) && !util.NodeHasEvacuateAnnotation(newNode):
+		klog.InfoS("Node evacuate annotation removed, make it schedulable", "node", fornaxNode.NodeId)
+		err = nm.setNodeUnschedulable(fornaxNode, false)
+	}
+	if err != nil {
+		klog.ErrorS(err, "Failed to handle node update", "node", fornaxNode.NodeId)
+	}
+}
+
+// podNeedEvacuation return true if a pod is a not terminated application pod, node daemons are not evacuated
+func podNeedEvacuation(pod *v1.Pod) bool {
+	if _, found := pod.Labels[fornaxv1.LabelFornaxCoreNodeDaemon]; found {
+		return false
+	}
+	return util.PodNotTerminated(pod) && !util.PodHasEvacuateAnnotation(pod)
+}
+
+// recordNodeMetrics export number of nodes of each working state, it's called when a node change its working state
+func (nm *nodeManager) recordNodeMetrics() {
+	counts := map[ie.NodeWorkingState]int{
+		ie.NodeWorkingStateRegistering:  0,
+		ie.NodeWorkingStateRunning:      0,
+		ie.NodeWorkingStateDisconnected: 0,
+	}
+	for _, v := range nm.nodes.list() {
+		counts[nm.getNodeState(v)] += 1
+	}
+	for state, count := range counts {
+		metrics.Nodes.WithLabelValues(string(state)).Set(float64(count))
+	}
+}
+
+// removeStaleNodes remove nodes which have been disconnected longer than DefaultStaleNodeTimeout,
+// pods on these nodes are deleted and pod cidrs of these nodes are released for new nodes
+func (nm *nodeManager) removeStaleNodes() {
+	for _, v := range nm.nodes.list() {
+		nm.stateMu.RLock()
+		state, lastSeen := v.State, v.LastSeen
+		nm.stateMu.RUnlock()
+		if state == ie.NodeWorkingStateDisconnected && time.Since(lastSeen) > DefaultStaleNodeTimeout {
+			klog.InfoS("Remove a stale node", "node", v.NodeId, "lastSeen", lastSeen)
+			if err := nm.removeNode(v); err != nil {
+				klog.ErrorS(err, "Failed to remove a stale node, retry in next house keeping", "node", v.NodeId)
+			}
+		}
+	}
+}
+
+func (nm *nodeManager) removeNode(fornaxNode *ie.FornaxNodeWithState) error {
+	for _, podName := range fornaxNode.Pods.GetKeys() {
+		pod := nm.podManager.FindPod(podName)
+		if pod == nil {
+			continue
+		}
+		if util.PodNotTerminated(pod) {
+			pod.Status.Phase = v1.PodFailed
+		}
+		if _, err := nm.podManager.DeletePod(pod); err != nil && err != fornaxpod.PodNotFoundError {
+			klog.ErrorS(err, "Failed to delete a pod of removed node", "pod", podName, "node", fornaxNode.NodeId)
+		}
+	}
+
+	node := nm.getNode(fornaxNode)
+	if _, err := factory.DeleteFornaxNode(nm.ctx, nm.nodeStore, util.Name(node)); err != nil {
+		return err
+	}
+	nm.nodePodCidrManager.ReleaseCidr(node)
+	nm.nodeAgent.ForgetNode(fornaxNode.NodeId)
+	nm.nodes.delete(fornaxNode.NodeId)
+	nm.recordNodeMetrics()
+	nm.nodeUpdates <- &ie.NodeEvent{
+		Node: node.DeepCopy(),
+		Type: ie.NodeEventTypeDelete,
+	}
+	return nil
+}
+
+// restoreNodes reserve pod cidrs of nodes in store before any node register, so, a new node does not get cidrs of a known node,
+// restored nodes are disconnected until they connect again, and removed as stale nodes if they do not come back
+func (nm *nodeManager) restoreNodes() error {
+	nodes, err := factory.ListFornaxNodes(nm.ctx, nm.nodeStore)
+	if err != nil {
+		return err
+	}
+	for i := range nodes {
+		node := nodes[i].DeepCopy()
+		nodeId := util.Name(node)
+		if nm.nodes.get(nodeId) != nil {
+			continue
+		}
+		cidrs, err := nm.nodePodCidrManager.GetCidr(node)
+		if err != nil {
+			klog.ErrorS(err, "Failed to reserve pod cidr of a restored node", "node", nodeId)
+		} else if len(node.Spec.PodCIDRs) > 0 && !reflect.DeepEqual(cidrs, node.Spec.PodCIDRs) {
+			klog.Warningf("Pod cidrs %v of restored node %s are used by other node, it get %v when it register again", node.Spec.PodCIDRs, nodeId, cidrs)
+		}
+		nm.nodes.add(nodeId, &ie.FornaxNodeWithState{
+			NodeId:   nodeId,
+			Revision: node.ResourceVersion,
+			Node:     node,
+			State:    ie.NodeWorkingStateDisconnected,
+			Pods:     collection.NewConcurrentSet(),
+			LastSeen: time.Now(),
+		})
+		klog.InfoS("Restored a node from store", "node", nodeId, "podCidrs", node.Spec.PodCIDRs)
+	}
+	nm.recordNodeMetrics()
+	return nil
+}
+
 func (nm *nodeManager) Run() error {
 	klog.Info("starting node manager")
+	if err := nm.restoreNodes(); err != nil {
+		return err
+	}
+	nm.nodeDaemonManager.Watch(nm.daemonUpdates)
+	nm.nodeDaemonManager.Run(nm.ctx)
+	wi, err := nm.nodeStore.WatchWithOldObj(nm.ctx, fornaxk8sv1.FornaxNodeGrvKey, apistorage.ListOptions{
+		ResourceVersion:      "0",
+		ResourceVersionMatch: "",
+		Predicate:            apistorage.Everything,
+		Recursive:            true,
+		ProgressNotify:       true,
+	})
+	if err != nil {
+		return err
+	}
 	go func() {
 		for {
 			select {
 			case <-nm.ctx.Done():
-				// TODO, shutdown more gracefully, handoff fornaxcore primary ownership
-				break
+				// primary ownership is handed off by leader election, standby ask nodes to full sync when it take over
+				return
 			case update := <-nm.nodeUpdates:
 				for _, watcher := range nm.watchers {
 					watcher <- update
@@ -313,28 +644,53 @@ func (nm *nodeManager) Run() error {
 		}
 	}()
 
+	go func() {
+		for {
+			select {
+			case <-nm.ctx.Done():
+				return
+			case <-nm.houseKeepingTicker.C:
+				nm.removeStaleNodes()
+			case <-nm.daemonUpdates:
+				nm.rolloutDaemons()
+			case we := <-wi.ResultChanWithPrevobj():
+				nm.onNodeEventFromStore(we)
+			}
+		}
+	}()
+
 	return nil
 }
 
 func (nm *nodeManager) PrintNodeSummary() {
 	klog.InfoS("node summary:", "#node", nm.nodes.length())
 	for _, v := range nm.nodes.list() {
+		nm.stateMu.RLock()
 		klog.InfoS("node", "node", v.Node.Name, "state", v.State, "#pod", v.Pods.Len(), "#daemon pod", len(v.DaemonPods))
+		nm.stateMu.RUnlock()
 	}
 }
 
-func NewNodeManager(ctx context.Context, nodeStore fornaxstore.ApiStorageInterface, nodeAgent nodeagent.NodeAgentClient, podManager ie.PodManagerInterface, sessionManager ie.SessionManagerInterface) *nodeManager {
+// SetPodSessionReconciler set reconciler which reopen starting sessions not reported by node in full sync,
+// it should be called before node manager run
+func (nm *nodeManager) SetPodSessionReconciler(reconciler ie.PodSessionReconcilerInterface) {
+	nm.podSessionReconciler = reconciler
+}
+
+func NewNodeManager(ctx context.Context, nodeStore fornaxstore.ApiStorageInterface, nodeAgent nodeagent.NodeAgentClient, podManager ie.PodManagerInterface, sessionManager ie.SessionManagerInterface, nodePodCidrManager NodeCidrManager, nodeDaemonManager NodeDaemonManager, eventRecorder record.EventRecorder) *nodeManager {
 	return &nodeManager{
 		ctx:                ctx,
 		nodeUpdates:        make(chan *ie.NodeEvent, 100),
 		watchers:           []chan<- *ie.NodeEvent{},
 		nodeStore:          nodeStore,
 		nodeAgent:          nodeAgent,
-		nodePodCidrManager: NewPodCidrManager(),
-		nodeDaemonManager:  NewNodeDaemonManager(),
-		houseKeepingTicker: time.NewTicker(DefaultStaleNodeTimeout),
+		nodePodCidrManager: nodePodCidrManager,
+		nodeDaemonManager:  nodeDaemonManager,
+		daemonUpdates:      make(chan struct{}, 1),
+		houseKeepingTicker: time.NewTicker(DefaultNodeHouseKeepingPeriod),
 		podManager:         podManager,
 		sessionManager:     sessionManager,
+		eventRecorder:      eventRecorder,
 		nodes: NodePool{
 			mu:    sync.RWMutex{},
 			nodes: map[string]*ie.FornaxNodeWithState{},
@@ -0,0 +1,306 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/collection"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// fakePodManager find pods from a map and remember evacuated pods
+type fakePodManager struct {
+	ie.PodManagerInterface
+	pods      map[string]*v1.Pod
+	evacuated []string
+}
+
+func (pm *fakePodManager) FindPod(podName string) *v1.Pod {
+	return pm.pods[podName]
+}
+
+func (pm *fakePodManager) AddOrUpdatePod(pod *v1.Pod) (*v1.Pod, error) {
+	pm.pods[util.Name(pod)] = pod
+	return pod, nil
+}
+
+func (pm *fakePodManager) EvacuatePod(podName string) error {
+	pm.evacuated = append(pm.evacuated, podName)
+	return nil
+}
+
+func newTestPod(name string, phase v1.PodPhase, labels map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name, Labels: labels},
+		Status:     v1.PodStatus{Phase: phase},
+	}
+}
+
+// nextModifiedNode wait for next node modification in store and return it
+func nextModifiedNode(t *testing.T, wi fornaxstore.WatchWithOldObjInterface) fornaxstore.WatchEventWithOldObj {
+	for {
+		select {
+		case we := <-wi.ResultChanWithPrevobj():
+			if we.Type == watch.Modified {
+				return we
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expect node modified in store")
+		}
+	}
+}
+
+func TestEvacuateNodeByAnnotation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodeStore := factory.NewFornaxNodeStorage(ctx)
+	pm := &fakePodManager{pods: map[string]*v1.Pod{
+		"test/running":    newTestPod("running", v1.PodRunning, map[string]string{}),
+		"test/pending":    newTestPod("pending", v1.PodPending, map[string]string{}),
+		"test/terminated": newTestPod("terminated", v1.PodSucceeded, map[string]string{}),
+		"test/daemon":     newTestPod("daemon", v1.PodRunning, map[string]string{fornaxv1.LabelFornaxCoreNodeDaemon: "true"}),
+	}}
+	nm := NewNodeManager(ctx, nodeStore, nil, pm, nil, nil, nil, record.NewFakeRecorder(10))
+
+	node := newTestNode("node1")
+	node.Namespace = "node"
+	node.Status.Phase = v1.NodeRunning
+	node, err := factory.CreateFornaxNode(ctx, nodeStore, node)
+	if err != nil {
+		t.Fatalf("failed to create node, %v", err)
+	}
+	pods := collection.NewConcurrentSet()
+	for name := range pm.pods {
+		pods.Add(name)
+	}
+	nodeId := util.Name(node)
+	nm.nodes.add(nodeId, &ie.FornaxNodeWithState{NodeId: nodeId, Node: node, Pods: pods, State: ie.NodeWorkingStateRunning})
+
+	wi, err := nodeStore.WatchWithOldObj(ctx, fornaxk8sv1.FornaxNodeGrvKey, apistorage.ListOptions{
+		ResourceVersion: "0",
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	})
+	if err != nil {
+		t.Fatalf("failed to watch node store, %v", err)
+	}
+	defer wi.Stop()
+
+	// evacuate annotation is added on node in store, node manager evacuate node when it see annotation from store watch
+	if err := nm.EvacuateNode(nodeId); err != nil {
+		t.Fatalf("failed to evacuate node, %v", err)
+	}
+	we := nextModifiedNode(t, wi)
+	if !util.NodeHasEvacuateAnnotation(we.Object.(*v1.Node)) {
+		t.Fatalf("expect evacuate annotation on node")
+	}
+	nm.onNodeEventFromStore(we)
+	sort.Strings(pm.evacuated)
+	if len(pm.evacuated) != 2 || pm.evacuated[0] != "test/pending" || pm.evacuated[1] != "test/running" {
+		t.Errorf("expect only not terminated application pods evacuated, got %v", pm.evacuated)
+	}
+	if nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, nodeId); nodeInStore == nil || !nodeInStore.Spec.Unschedulable {
+		t.Errorf("expect evacuating node unschedulable in store")
+	}
+	if update := <-nm.nodeUpdates; !update.Node.Spec.Unschedulable {
+		t.Errorf("expect unschedulable node update sent to watchers")
+	}
+
+	// unschedulable update from node manager itself does not evacuate node again
+	nm.onNodeEventFromStore(nextModifiedNode(t, wi))
+	if len(pm.evacuated) != 2 {
+		t.Errorf("expect node evacuated only once, got %v", pm.evacuated)
+	}
+
+	// node is schedulable again when annotation is removed
+	// objects from store cache share maps with store, copy annotations before change them
+	nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, nodeId)
+	annotations := map[string]string{}
+	for k, v := range nodeInStore.Annotations {
+		if k != fornaxv1.AnnotationFornaxCoreEvacuate {
+			annotations[k] = v
+		}
+	}
+	nodeInStore.Annotations = annotations
+	if _, err := factory.UpdateFornaxNode(ctx, nodeStore, nodeInStore); err != nil {
+		t.Fatalf("failed to update node, %v", err)
+	}
+	nm.onNodeEventFromStore(nextModifiedNode(t, wi))
+	if nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, nodeId); nodeInStore.Spec.Unschedulable {
+		t.Errorf("expect node schedulable after evacuate annotation removed")
+	}
+	if update := <-nm.nodeUpdates; update.Node.Spec.Unschedulable {
+		t.Errorf("expect schedulable node update sent to watchers")
+	}
+}
+
+// fakeNodeAgentClient remember nodes forgotten by node manager
+type fakeNodeAgentClient struct {
+	nodeagent.NodeAgentClient
+	forgotten []string
+}
+
+func (c *fakeNodeAgentClient) ForgetNode(nodeId string) {
+	c.forgotten = append(c.forgotten, nodeId)
+}
+
+func TestRestoreNodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nodeStore := factory.NewFornaxNodeStorage(ctx)
+	// node store is shared in process, clean up nodes of other tests
+	nodes, _ := factory.ListFornaxNodes(ctx, nodeStore)
+	for _, v := range nodes {
+		factory.DeleteFornaxNode(ctx, nodeStore, util.Name(&v))
+	}
+	cidrManager, err := NewPodCidrManager(&NodeCidrConfig{ClusterCidrs: []string{"10.10.0.0/22"}, NodeCidrMaskSizeIPv4: 24})
+	if err != nil {
+		t.Fatalf("failed to create cidr manager, %v", err)
+	}
+	client := &fakeNodeAgentClient{}
+	nm := NewNodeManager(ctx, nodeStore, client, &fakePodManager{}, nil, cidrManager, nil, record.NewFakeRecorder(10))
+
+	// node registered before fornaxcore restart, it is restored from persisted node store
+	node := newTestNode("restored", "10.10.0.0/24")
+	node.Namespace = "node"
+	if _, err := factory.CreateFornaxNode(ctx, nodeStore, node); err != nil {
+		t.Fatalf("failed to create node, %v", err)
+	}
+	if err := nm.restoreNodes(); err != nil {
+		t.Fatalf("failed to restore nodes, %v", err)
+	}
+	fornaxNode := nm.nodes.get("node/restored")
+	if fornaxNode == nil || fornaxNode.State != ie.NodeWorkingStateDisconnected {
+		t.Fatalf("expect restored node disconnected until it connect again, got %v", fornaxNode)
+	}
+
+	// a new node registering before restored node does not get cidr of restored node, restored node keep its cidr when it register again
+	if cidrs, _ := cidrManager.GetCidr(newTestNode("node/new", "10.10.0.0/24")); !reflect.DeepEqual(cidrs, []string{"10.10.1.0/24"}) {
+		t.Errorf("expect new node get a free cidr, got %v", cidrs)
+	}
+	if cidrs, _ := cidrManager.GetCidr(node); !reflect.DeepEqual(cidrs, []string{"10.10.0.0/24"}) {
+		t.Errorf("expect restored node keep its cidr, got %v", cidrs)
+	}
+
+	// restored node never come back is removed as stale node and its cidr is released
+	fornaxNode.LastSeen = time.Now().Add(-2 * DefaultStaleNodeTimeout)
+	nm.removeStaleNodes()
+	if nm.nodes.get("node/restored") != nil || len(client.forgotten) != 1 {
+		t.Errorf("expect stale restored node removed")
+	}
+	if nodeInStore, _ := factory.GetFornaxNodeCache(nodeStore, "node/restored"); nodeInStore != nil {
+		t.Errorf("expect stale restored node deleted from store")
+	}
+	if cidrs, _ := cidrManager.GetCidr(newTestNode("node/another", "10.10.0.0/24")); !reflect.DeepEqual(cidrs, []string{"10.10.0.0/24"}) {
+		t.Errorf("expect cidr of removed node reused, got %v", cidrs)
+	}
+}
+
+func TestRecordNodeMetrics(t *testing.T) {
+	metrics.Register()
+	nm := &nodeManager{nodes: NodePool{nodes: map[string]*ie.FornaxNodeWithState{}}}
+	for name, state := range map[string]ie.NodeWorkingState{
+		"node1": ie.NodeWorkingStateRunning,
+		"node2": ie.NodeWorkingStateRunning,
+		"node3": ie.NodeWorkingStateDisconnected,
+	} {
+		nm.nodes.add(name, &ie.FornaxNodeWithState{NodeId: name, State: state, Pods: collection.NewConcurrentSet()})
+	}
+	nm.recordNodeMetrics()
+	expect := map[ie.NodeWorkingState]float64{
+		ie.NodeWorkingStateRegistering:  0,
+		ie.NodeWorkingStateRunning:      2,
+		ie.NodeWorkingStateDisconnected: 1,
+	}
+	for state, e := range expect {
+		if v, err := testutil.GetGaugeMetricValue(metrics.Nodes.WithLabelValues(string(state))); err != nil || v != e {
+			t.Errorf("expect %v %s nodes, got %v, %v", e, state, v, err)
+		}
+	}
+
+	// gauge of a state drop to zero when no node is in this state
+	nm.setNodeState(nm.nodes.get("node3"), ie.NodeWorkingStateRunning)
+	nm.recordNodeMetrics()
+	if v, _ := testutil.GetGaugeMetricValue(metrics.Nodes.WithLabelValues(string(ie.NodeWorkingStateDisconnected))); v != 0 {
+		t.Errorf("expect no disconnected nodes, got %v", v)
+	}
+}
+
+// fakeSessionManager ignore session status reported by node
+type fakeSessionManager struct {
+	ie.SessionManagerInterface
+}
+
+func (sm *fakeSessionManager) OnSessionStatusFromNode(nodeId string, pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
+	return nil
+}
+
+// fakePodSessionReconciler remember sessions reported on pods
+type fakePodSessionReconciler struct {
+	reported map[string][]string
+}
+
+func (r *fakePodSessionReconciler) ReconcilePodSessions(pod *v1.Pod, reportedSessions []*fornaxv1.ApplicationSession) {
+	names := []string{}
+	for _, v := range reportedSessions {
+		names = append(names, util.Name(v))
+	}
+	r.reported[util.Name(pod)] = names
+}
+
+func TestSyncPodStatesReconcilePodSessions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pm := &fakePodManager{pods: map[string]*v1.Pod{}}
+	nm := NewNodeManager(ctx, factory.NewFornaxNodeStorage(ctx), nil, pm, &fakeSessionManager{}, nil, nil, record.NewFakeRecorder(10))
+	reconciler := &fakePodSessionReconciler{reported: map[string][]string{}}
+	nm.SetPodSessionReconciler(reconciler)
+	node := newTestNode("node1")
+	nm.nodes.add("node/node1", &ie.FornaxNodeWithState{NodeId: "node/node1", Node: node, Pods: collection.NewConcurrentSet(), State: ie.NodeWorkingStateRunning})
+
+	session := &fornaxv1.ApplicationSession{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session"}}
+	sessionData, _ := json.Marshal(session)
+	nm.SyncPodStates("node/node1", []*grpc.PodState{
+		{Pod: newTestPod("pod1", v1.PodRunning, map[string]string{}), SessionStates: []*grpc.SessionState{{SessionData: sessionData}}},
+		{Pod: newTestPod("pod2", v1.PodRunning, map[string]string{})},
+	})
+	expect := map[string][]string{"test/pod1": {"test/session"}, "test/pod2": {}}
+	if !reflect.DeepEqual(reconciler.reported, expect) {
+		t.Errorf("expect sessions of each reported pod reconciled %v, got %v", expect, reconciler.reported)
+	}
+}
@@ -69,10 +69,26 @@ func (pool *NodeRevisionMap) get(name string) *NodeWithRevision {
 }
 
 type nodeMonitor struct {
-	chQuit      chan interface{}
-	nodeManager ie.NodeManagerInterface
-	nodes       NodeRevisionMap
-	staleNodes  NodeRevisionMap
+	chQuit       chan interface{}
+	nodeManager  ie.NodeManagerInterface
+	eventManager ie.EventManagerInterface
+	nodes        NodeRevisionMap
+	staleNodes   NodeRevisionMap
+}
+
+// OnNodeEvent save event reported by node, events of a node not registered yet are dropped
+func (nm *nodeMonitor) OnNodeEvent(message *grpc.FornaxCoreMessage) (*grpc.FornaxCoreMessage, error) {
+	nodeId := message.GetNodeIdentifier().GetIdentifier()
+	event := message.GetNodeEvent().GetEvent()
+	if event == nil {
+		return nil, nil
+	}
+	if nm.nodes.get(nodeId) == nil {
+		klog.V(5).InfoS("Drop event of a unknown node", "node", nodeId, "reason", event.Reason)
+		return nil, nil
+	}
+	nm.eventManager.RecordEvent(event.DeepCopy())
+	return nil, nil
 }
 
 // OnSessionUpdate implements server.NodeMonitor
@@ -172,6 +188,11 @@ func (nm *nodeMonitor) OnNodeConnect(nodeId string) error {
 		// ask node to full sync since this node was disconnected before
 		return nodeagent.NodeRevisionOutOfOrderError
 	}
+	if nm.nodes.get(nodeId) == nil {
+		// node may have registered with previous primary fornaxcore, ask node to full sync to recover its pods and sessions,
+		// a registering node ignore full sync request and continue to register
+		return nodeagent.NodeRevisionOutOfOrderError
+	}
 	return nil
 }
 
@@ -304,10 +325,11 @@ func (nm *nodeMonitor) updateOrCreateNode(nodeId string, v1node *v1.Node, revisi
 	return nil
 }
 
-func NewNodeMonitor(nodeManager ie.NodeManagerInterface) *nodeMonitor {
+func NewNodeMonitor(nodeManager ie.NodeManagerInterface, eventManager ie.EventManagerInterface) *nodeMonitor {
 	nm := &nodeMonitor{
-		chQuit:      make(chan interface{}),
-		nodeManager: nodeManager,
+		chQuit:       make(chan interface{}),
+		nodeManager:  nodeManager,
+		eventManager: eventManager,
 		nodes: NodeRevisionMap{
 			mu:    sync.RWMutex{},
 			nodes: map[string]*NodeWithRevision{},
@@ -21,6 +21,8 @@ import (
 	"errors"
 	"time"
 
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/podscheduler"
@@ -29,6 +31,8 @@ import (
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	apistorage "k8s.io/apiserver/pkg/storage"
 	"k8s.io/klog/v2"
 )
 
@@ -72,13 +76,45 @@ func (pm *podManager) Run(podScheduler podscheduler.PodScheduler) error {
 	klog.Info("starting pod manager")
 	pm.podScheduler = podScheduler
 
+	klog.Info("starting pod store watch")
+	wi, err := pm.podStore.WatchWithOldObj(pm.ctx, fornaxk8sv1.FornaxPodGrvKey, apistorage.ListOptions{
+		ResourceVersion:      "0",
+		ResourceVersionMatch: "",
+		Predicate:            apistorage.Everything,
+		Recursive:            true,
+		ProgressNotify:       true,
+	})
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer wi.Stop()
+		for {
+			select {
+			case <-pm.ctx.Done():
+				return
+			case we := <-wi.ResultChanWithPrevobj():
+				if we.Type != watch.Modified {
+					continue
+				}
+				oldPod, newPod := we.OldObject.(*v1.Pod), we.Object.(*v1.Pod)
+				// clients add evacuate annotation on a pod to evacuate it
+				if !util.PodHasEvacuateAnnotation(oldPod) && util.PodHasEvacuateAnnotation(newPod) {
+					if err := pm.evacuatePod(newPod); err != nil {
+						klog.ErrorS(err, "Failed to evacuate pod", "pod", util.Name(newPod))
+					}
+				}
+			}
+		}
+	}()
+
 	klog.Info("starting pod updates notification")
 	go func() {
 		for {
 			select {
 			case <-pm.ctx.Done():
-				// TODO, shutdown more gracefully, handoff fornaxcore primary ownership
-				break
+				// primary ownership is handed off by leader election, standby ask nodes to full sync when it take over
+				return
 			case update := <-pm.podUpdates:
 				for _, watcher := range pm.watchers {
 					watcher <- update
@@ -201,6 +237,52 @@ func (pm *podManager) HibernatePod(podName string) error {
 	return nil
 }
 
+// EvacuatePod add evacuate annotation on a pod, pod store watch pick it up and ask node agent to evacuate pod sessions,
+// if pod already has evacuate annotation, evacuate it again
+func (pm *podManager) EvacuatePod(podName string) error {
+	podInStore, err := factory.GetFornaxPodCache(pm.podStore, podName)
+	if err != nil {
+		return err
+	} else {
+		if podInStore == nil {
+			return PodNotFoundError
+		}
+	}
+
+	if util.PodHasEvacuateAnnotation(podInStore) {
+		return pm.evacuatePod(podInStore)
+	}
+
+	annotations := map[string]string{}
+	for k, v := range podInStore.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[fornaxv1.AnnotationFornaxCoreEvacuate] = "true"
+	podInStore.Annotations = annotations
+	_, err = factory.UpdateFornaxPod(pm.ctx, pm.podStore, podInStore)
+	return err
+}
+
+// evacuatePod ask node agent to evacuate sessions on pod and terminate it, pod is not scheduled yet is just terminated,
+// pod owner is notified to stop using this pod and reopen its sessions on other pods
+func (pm *podManager) evacuatePod(pod *v1.Pod) error {
+	klog.InfoS("Evacuate pod", "pod", util.Name(pod))
+	pm.podScheduler.RemovePod(pod)
+	nodeId := util.GetPodFornaxNodeIdLabel(pod)
+	if len(nodeId) == 0 {
+		return pm.TerminatePod(util.Name(pod))
+	}
+
+	if util.PodNotTerminated(pod) {
+		err := pm.nodeAgentClient.EvacuatePod(nodeId, pod)
+		if err != nil {
+			return err
+		}
+	}
+	pm.podUpdates <- &ie.PodEvent{Pod: pod.DeepCopy(), Type: ie.PodEventTypeUpdate}
+	return nil
+}
+
 func (pm *podManager) createPodAndSendEvent(pod *v1.Pod) (*v1.Pod, error) {
 	var eType ie.PodEventType
 	switch {
@@ -257,7 +339,16 @@ func (pm *podManager) AddOrUpdatePod(pod *v1.Pod) (*v1.Pod, error) {
 				return nil, err
 			}
 		}
+		evacuating := util.PodHasEvacuateAnnotation(podInStore)
 		util.MergePod(pod, podInStore)
+		if evacuating && !util.PodHasEvacuateAnnotation(podInStore) {
+			// node has not seen evacuate annotation yet, keep it
+			annotations := map[string]string{fornaxv1.AnnotationFornaxCoreEvacuate: "true"}
+			for k, v := range podInStore.GetAnnotations() {
+				annotations[k] = v
+			}
+			podInStore.Annotations = annotations
+		}
 		if util.PodIsTerminated(pod) {
 			factory.DeleteFornaxPod(pm.ctx, pm.podStore, util.Name(pod))
 			pm.podUpdates <- &ie.PodEvent{Pod: podInStore.DeepCopy(), Type: ie.PodEventTypeTerminate}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeNodeAgentClient send pod evacuations into a channel
+type fakeNodeAgentClient struct {
+	nodeagent.NodeAgentClient
+	evacuated chan string
+}
+
+func (c *fakeNodeAgentClient) EvacuatePod(nodeId string, pod *v1.Pod) error {
+	c.evacuated <- nodeId + "," + util.Name(pod)
+	return nil
+}
+
+type fakePodScheduler struct{}
+
+func (*fakePodScheduler) AddPod(pod *v1.Pod, duration time.Duration) {}
+
+func (*fakePodScheduler) RemovePod(pod *v1.Pod) {}
+
+func TestEvacuatePodByAnnotation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &fakeNodeAgentClient{evacuated: make(chan string, 10)}
+	pm := NewPodManager(ctx, factory.NewFornaxPodStorage(ctx), client)
+	podUpdates := make(chan *ie.PodEvent, 10)
+	pm.Watch(podUpdates)
+	if err := pm.Run(&fakePodScheduler{}); err != nil {
+		t.Fatalf("failed to run pod manager, %v", err)
+	}
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "test",
+			Name:      "pod1",
+			Labels:    map[string]string{fornaxv1.LabelFornaxCoreNode: "node/node1"},
+		},
+		Status: v1.PodStatus{Phase: v1.PodRunning},
+	}
+	if _, err := factory.CreateFornaxPod(ctx, pm.podStore, pod); err != nil {
+		t.Fatalf("failed to create pod, %v", err)
+	}
+
+	// a update without evacuate annotation does not evacuate pod
+	podInStore, _ := factory.GetFornaxPodCache(pm.podStore, "test/pod1")
+	podInStore.Labels["other"] = "true"
+	if _, err := factory.UpdateFornaxPod(ctx, pm.podStore, podInStore); err != nil {
+		t.Fatalf("failed to update pod, %v", err)
+	}
+	select {
+	case evacuated := <-client.evacuated:
+		t.Fatalf("expect pod not evacuated without annotation, got %s", evacuated)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// evacuate annotation added by a client is picked up from store watch
+	if err := pm.EvacuatePod("test/pod1"); err != nil {
+		t.Fatalf("failed to evacuate pod, %v", err)
+	}
+	select {
+	case evacuated := <-client.evacuated:
+		if evacuated != "node/node1,test/pod1" {
+			t.Errorf("expect pod evacuated on its node, got %s", evacuated)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect pod evacuated")
+	}
+	select {
+	case update := <-podUpdates:
+		if !util.PodHasEvacuateAnnotation(update.Pod) || update.Type != ie.PodEventTypeUpdate {
+			t.Errorf("expect pod owner notified with evacuating pod, got %v", update)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect pod update event")
+	}
+
+	// evacuate a evacuating pod again ask node again, store is not changed
+	if err := pm.EvacuatePod("test/pod1"); err != nil {
+		t.Fatalf("failed to evacuate pod again, %v", err)
+	}
+	select {
+	case <-client.evacuated:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expect pod evacuated again")
+	}
+	select {
+	case evacuated := <-client.evacuated:
+		t.Errorf("expect pod evacuated only once more, got %s", evacuated)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -23,15 +23,18 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/collection"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
@@ -94,20 +97,26 @@ type SchedulePolicy struct {
 	NumOfEvaluatedNodes int
 	BackoffDuration     time.Duration
 	NodeSortingMethod   NodeSortingMethod
+	// conditions used to filter and score nodes, use DefaultScheduleConditions if it's empty
+	ScheduleConditions []ScheduleConditionName
 }
 
 type podScheduler struct {
-	stop                      bool
+	stop                      int32
 	ctx                       context.Context
 	nodeUpdateCh              chan *ie.NodeEvent
 	podUpdateCh               chan *ie.PodEvent
 	nodeInfoP                 ie.NodeInfoProviderInterface
+	podManager                ie.PodManagerInterface
+	podSessionProvider        ie.PodSessionProviderInterface
 	nodeAgentClient           nodeagent.NodeAgentClient
 	scheduleQueue             *PodScheduleQueue
 	nodePool                  *SchedulableNodePool
 	ScheduleConditionBuilders []ConditionBuildFunc
 	policy                    *SchedulePolicy
+	schedulersMu              sync.RWMutex
 	schedulers                []*nodeChunkScheduler
+	eventRecorder             record.EventRecorder
 }
 
 // RemovePod remove a pod from scheduling queue
@@ -143,6 +152,29 @@ func (ps *podScheduler) scoreNode(pod *v1.Pod, nodes []*SchedulableNode) *Schedu
 
 	return bestNode
 }
+
+// sortNodesByPreference stable sort nodes by score of non mandatory conditions,
+// nodes with same preference score keep order of node sorting method
+func (ps *podScheduler) sortNodesByPreference(nodes []*SchedulableNode, conditions []ScheduleCondition) {
+	preferences := []ScheduleCondition{}
+	for _, cond := range conditions {
+		if !cond.Mandatory() {
+			preferences = append(preferences, cond)
+		}
+	}
+	if len(preferences) == 0 {
+		return
+	}
+
+	scores := map[string]int{}
+	for _, node := range nodes {
+		scores[node.NodeName] = ps.calcScore(node, preferences)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return scores[nodes[i].NodeName] > scores[nodes[j].NodeName]
+	})
+}
+
 func (ps *podScheduler) selectNode(pod *v1.Pod, nodes []*SchedulableNode) *SchedulableNode {
 	// randomly pickup one
 	no := rand.Intn(len(nodes))
@@ -158,6 +190,7 @@ func (ps *podScheduler) bindNode(snode *SchedulableNode, pod *v1.Pod) error {
 
 	resourceList := util.GetPodResourceList(pod)
 	snode.AdmitPodOccupiedResourceList(resourceList)
+	snode.AddPod(pod)
 	// set pod status
 	pod.Status.StartTime = util.NewCurrentMetaTime()
 	// when pod is scheduled but not returned from node, use it's host ip to help release resource
@@ -181,6 +214,7 @@ func (ps *podScheduler) bindNode(snode *SchedulableNode, pod *v1.Pod) error {
 func (ps *podScheduler) unbindNode(node *SchedulableNode, pod *v1.Pod) {
 	resourceList := util.GetPodResourceList(pod)
 	node.ReleasePodOccupiedResourceList(resourceList)
+	node.RemovePod(pod)
 	pod.Status.StartTime = nil
 	pod.Status.HostIP = ""
 	pod.Status.Message = "Schedule failed"
@@ -202,9 +236,16 @@ func (ps *podScheduler) schedulePod(pod *v1.Pod, candidateNodes []*SchedulableNo
 	availableNodes := []*SchedulableNode{}
 	conditions := CalculateScheduleConditions(ps.ScheduleConditionBuilders, pod)
 	for _, node := range candidateNodes {
+		if !node.Schedulable() {
+			continue
+		}
 		allocatedResources := node.GetAllocatableResources()
 		goodNode := true
 		for _, cond := range conditions {
+			// only mandatory conditions filter nodes, others are used to rank nodes
+			if !cond.Mandatory() {
+				continue
+			}
 			goodNode = goodNode && cond.Apply(node, &allocatedResources)
 			if !goodNode {
 				break
@@ -229,6 +270,7 @@ func (ps *podScheduler) schedulePod(pod *v1.Pod, candidateNodes []*SchedulableNo
 			lessFunc: BuildNodeSortingFunc(NodeSortingMethodLessLastUse),
 		}
 		sort.Sort(sortedNodes)
+		ps.sortNodesByPreference(sortedNodes.nodes, conditions)
 
 		var bindError error
 		for _, node := range sortedNodes.nodes {
@@ -255,12 +297,14 @@ func (ps *podScheduler) updateNodePool(v1node *v1.Node, updateType ie.NodeEventT
 	} else {
 		if snode := ps.nodePool.GetNode(nodeName); snode != nil {
 			snode.LastSeen = time.Now()
+			snode.SetNode(v1node.DeepCopy())
 			if !util.IsNodeRunning(v1node) {
 				ps.nodePool.DeleteNode(nodeName)
 			}
 			return snode
 		} else {
-			// only add ready node into scheduleable node list
+			// only add running node into scheduleable node list, unschedulable node is kept in list to account its pods,
+			// but pods are not scheduled on it
 			if util.IsNodeRunning(v1node) {
 				snode := &SchedulableNode{
 					mu:                         sync.Mutex{},
@@ -271,6 +315,7 @@ func (ps *podScheduler) updateNodePool(v1node *v1.Node, updateType ie.NodeEventT
 					Stat:                       ScheduleStat{},
 					ResourceList:               GetNodeAllocatableResourceList(v1node),
 					PodPreOccupiedResourceList: v1.ResourceList{},
+					pods:                       map[string]scheduledPod{},
 				}
 				ps.nodePool.AddNode(nodeName, snode)
 			}
@@ -286,18 +331,42 @@ func (ps *podScheduler) updatePodOccupiedResourceList(snode *SchedulableNode, po
 	case ie.PodEventTypeDelete, ie.PodEventTypeTerminate:
 		resourceList := util.GetPodResourceList(pod)
 		snode.ReleasePodOccupiedResourceList(resourceList)
+		snode.RemovePod(pod)
 	case ie.PodEventTypeCreate:
 		resourceList := util.GetPodResourceList(pod)
 		snode.AdmitPodOccupiedResourceList(resourceList)
+		snode.AddPod(pod)
+	case ie.PodEventTypeUpdate:
+		// refresh pod state, pod could become idle or be allocated to a session
+		snode.AddPod(pod)
 	}
 }
 
 func (ps *podScheduler) printScheduleSummary() {
 	activeNum, retryNum := ps.scheduleQueue.Length()
-	klog.InfoS("Scheduler summary", "active queue length", activeNum, "backoff queue length", retryNum, "available nodes", ps.nodePool.size(), "schedulers", len(ps.schedulers))
+	klog.InfoS("Scheduler summary", "active queue length", activeNum, "backoff queue length", retryNum, "available nodes", ps.nodePool.size(), "schedulers", len(ps.chunkSchedulers()))
 	// ps.nodePool.printSummary()
 }
 
+// recordScheduleResult count schedule attempts, and failures by reason
+func recordScheduleResult(schedErr error) {
+	if schedErr == nil {
+		metrics.ScheduleAttempts.WithLabelValues("scheduled").Inc()
+		return
+	}
+	metrics.ScheduleAttempts.WithLabelValues("failed").Inc()
+	reason := "unknown"
+	switch schedErr {
+	case InsufficientResourceError:
+		reason = "insufficient_resource"
+	case PodBindToNodeError:
+		reason = "bind_error"
+	case PodIsDeletedError:
+		reason = "pod_deleted"
+	}
+	metrics.ScheduleFailures.WithLabelValues(reason).Inc()
+}
+
 type nodeChunkScheduler struct {
 	mu            sync.Mutex
 	nodes         []*SchedulableNode
@@ -312,6 +381,8 @@ type nodeChunkScheduler struct {
 // this list need to resort, but do not want to resort every time since cow is expensive,
 // scheduler control when a resort is needed
 func (cps *nodeChunkScheduler) sortNodes() {
+	cps.mu.Lock()
+	defer cps.mu.Unlock()
 	nodes := []*SchedulableNode{}
 	for _, v := range cps.nodes {
 		nodes = append(nodes, v)
@@ -331,6 +402,18 @@ func (cps *nodeChunkScheduler) schedulePod(pod *v1.Pod) error {
 	return cps.scheduler.schedulePod(pod, cps.nodes)
 }
 
+// preemptPod select victims under lock and terminate them after releasing lock, return true if victims are found
+func (cps *nodeChunkScheduler) preemptPod(pod *v1.Pod) bool {
+	cps.mu.Lock()
+	node, victims := cps.scheduler.selectPreemptionVictims(pod, cps.nodes)
+	cps.mu.Unlock()
+	if node == nil {
+		return false
+	}
+	cps.scheduler.terminatePreemptionVictims(pod, node, victims)
+	return true
+}
+
 func (ps *podScheduler) initializeChunkSchedulers() {
 	numOfNodesPerScheduler := ps.policy.NumOfEvaluatedNodes
 	chunkSchedulers := []*nodeChunkScheduler{}
@@ -347,21 +430,30 @@ func (ps *podScheduler) initializeChunkSchedulers() {
 		cs.sortNodes()
 		chunkSchedulers = append(chunkSchedulers, cs)
 	}
+	ps.schedulersMu.Lock()
+	defer ps.schedulersMu.Unlock()
 	ps.schedulers = chunkSchedulers
 }
 
+// chunkSchedulers return current chunk schedulers, they are replaced when node pool size changed
+func (ps *podScheduler) chunkSchedulers() []*nodeChunkScheduler {
+	ps.schedulersMu.RLock()
+	defer ps.schedulersMu.RUnlock()
+	return ps.schedulers
+}
+
 func (ps *podScheduler) Run() {
 	klog.Info("starting pod scheduler")
 	go func() {
 		for {
-			if ps.stop {
+			if atomic.LoadInt32(&ps.stop) == 1 {
 				break
 			} else {
-				if len(ps.schedulers) == 0 {
+				if len(ps.chunkSchedulers()) == 0 {
 					ps.initializeChunkSchedulers()
 				}
 
-				schedulers := ps.schedulers
+				schedulers := ps.chunkSchedulers()
 				if len(schedulers) == 0 {
 					// no scheduler, do not poll pods
 					time.Sleep(100 * time.Millisecond)
@@ -391,7 +483,19 @@ func (ps *podScheduler) Run() {
 								break
 							}
 						}
+						if schedErr == InsufficientResourceError {
+							// try to preempt lower priority idle pods, pod is scheduled again after backoff
+							for i := 0; i < numOfScheduler; i++ {
+								if schedulers[(index+i)%numOfScheduler].preemptPod(pod) {
+									break
+								}
+							}
+						}
+						recordScheduleResult(schedErr)
 						if schedErr != nil {
+							if schedErr != PodIsDeletedError {
+								ps.eventRecorder.Eventf(pod, v1.EventTypeWarning, "FailedScheduling", "Failed to schedule pod, %v, required resource %v", schedErr, util.GetPodResourceList(pod))
+							}
 							ps.scheduleQueue.BackoffPod(pod, ps.policy.BackoffDuration)
 						}
 						wg.Done()
@@ -405,10 +509,9 @@ func (ps *podScheduler) Run() {
 	// receive pod and node update to update scheduleable node resource and condition
 	go func() {
 		ticker := time.NewTicker(DefaultBackoffRetryDuration)
+		// update channels are written by node and pod manager, they are not closed here
 		defer func() {
-			ps.stop = true
-			close(ps.nodeUpdateCh)
-			close(ps.podUpdateCh)
+			atomic.StoreInt32(&ps.stop, 1)
 			ticker.Stop()
 		}()
 
@@ -422,7 +525,7 @@ func (ps *podScheduler) Run() {
 		for {
 			select {
 			case <-ps.ctx.Done():
-				ps.stop = true
+				atomic.StoreInt32(&ps.stop, 1)
 				return
 			case update := <-ps.podUpdateCh:
 				nodeId := util.GetPodFornaxNodeIdLabel(update.Pod)
@@ -448,7 +551,7 @@ func (ps *podScheduler) Run() {
 				ps.printScheduleSummary()
 				// sorting nodes using same node selection logic, move more likely nodes ahead,
 				// we may use different sorting interval
-				for _, v := range ps.schedulers {
+				for _, v := range ps.chunkSchedulers() {
 					v.sortNodes()
 				}
 
@@ -461,27 +564,32 @@ func (ps *podScheduler) Run() {
 	}()
 }
 
-func NewPodScheduler(ctx context.Context, nodeAgent nodeagent.NodeAgentClient, nodeInfoP ie.NodeInfoProviderInterface, podInfoP ie.PodInfoProviderInterface, policy *SchedulePolicy) *podScheduler {
+// SetPodSessionProvider set provider which tell if a idle pod is being assigned sessions, such pod is not preempted,
+// it should be called before scheduler run
+func (ps *podScheduler) SetPodSessionProvider(provider ie.PodSessionProviderInterface) {
+	ps.podSessionProvider = provider
+}
+
+func NewPodScheduler(ctx context.Context, nodeAgent nodeagent.NodeAgentClient, nodeInfoP ie.NodeInfoProviderInterface, podManager ie.PodManagerInterface, policy *SchedulePolicy, eventRecorder record.EventRecorder) *podScheduler {
 	ps := &podScheduler{
 		ctx:             ctx,
-		stop:            false,
+		stop:            0,
 		nodeUpdateCh:    make(chan *ie.NodeEvent, 100),
 		podUpdateCh:     make(chan *ie.PodEvent, 1000),
 		nodeInfoP:       nodeInfoP,
+		podManager:      podManager,
 		nodeAgentClient: nodeAgent,
 		scheduleQueue:   NewScheduleQueue(),
 		nodePool: &SchedulableNodePool{
 			mu:    sync.RWMutex{},
 			nodes: map[string]*SchedulableNode{},
 		},
-		ScheduleConditionBuilders: []ConditionBuildFunc{
-			NewPodCPUCondition,
-			NewPodMemoryCondition,
-		},
-		policy:     policy,
-		schedulers: []*nodeChunkScheduler{},
+		ScheduleConditionBuilders: BuildScheduleConditionBuilders(policy.ScheduleConditions),
+		policy:                    policy,
+		schedulers:                []*nodeChunkScheduler{},
+		eventRecorder:             eventRecorder,
 	}
 	nodeInfoP.Watch(ps.nodeUpdateCh)
-	podInfoP.Watch(ps.podUpdateCh)
+	podManager.Watch(ps.podUpdateCh)
 	return ps
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podscheduler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/component-base/metrics/testutil"
+)
+
+// fakeNodeAgentClient remember pods created on nodes, other calls are not expected in scheduler tests
+type fakeNodeAgentClient struct {
+	nodeagent.NodeAgentClient
+	mu         sync.Mutex
+	createdPod map[string]string
+}
+
+func (c *fakeNodeAgentClient) CreatePod(nodeId string, pod *v1.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.createdPod[util.Name(pod)] = nodeId
+	return nil
+}
+
+func newTestScheduler() (*podScheduler, *fakeNodeAgentClient) {
+	client := &fakeNodeAgentClient{createdPod: map[string]string{}}
+	ps := &podScheduler{
+		nodeAgentClient: client,
+		scheduleQueue:   NewScheduleQueue(),
+		nodePool: &SchedulableNodePool{
+			nodes: map[string]*SchedulableNode{},
+		},
+		ScheduleConditionBuilders: BuildScheduleConditionBuilders(nil),
+		policy:                    &SchedulePolicy{NumOfEvaluatedNodes: 10},
+	}
+	return ps, client
+}
+
+func newTestNode(name string, cpu, memory int64) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "node", Name: name, Labels: map[string]string{}},
+		Status: v1.NodeStatus{
+			Phase: v1.NodeRunning,
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+			},
+		},
+	}
+}
+
+func newTestPod(name string, cpu, memory int64) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name, Labels: map[string]string{}},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name: "test",
+				Resources: v1.ResourceRequirements{
+					Requests: v1.ResourceList{
+						v1.ResourceCPU:    *resource.NewMilliQuantity(cpu, resource.DecimalSI),
+						v1.ResourceMemory: *resource.NewQuantity(memory, resource.BinarySI),
+					},
+				},
+			}},
+		},
+	}
+}
+
+func TestScheduleSkipUnschedulableNode(t *testing.T) {
+	ps, client := newTestScheduler()
+	node1, node2 := newTestNode("node1", 1000, 1024), newTestNode("node2", 1000, 1024)
+	ps.updateNodePool(node1, ie.NodeEventTypeCreate)
+	ps.updateNodePool(node2, ie.NodeEventTypeCreate)
+
+	// node1 is being evacuated, it stay in pool to account its pods, but no pod is placed on it
+	evacuating := node1.DeepCopy()
+	evacuating.Spec.Unschedulable = true
+	ps.updateNodePool(evacuating, ie.NodeEventTypeUpdate)
+	if ps.nodePool.size() != 2 {
+		t.Fatalf("expect unschedulable node kept in pool, got %d nodes", ps.nodePool.size())
+	}
+
+	for _, name := range []string{"pod1", "pod2", "pod3"} {
+		if err := ps.schedulePod(newTestPod(name, 100, 128), ps.nodePool.GetNodes()); err != nil {
+			t.Fatalf("failed to schedule %s, %v", name, err)
+		}
+		if nodeId := client.createdPod["test/"+name]; nodeId != util.Name(node2) {
+			t.Errorf("expect %s scheduled on %s, got %s", name, util.Name(node2), nodeId)
+		}
+	}
+
+	// no pod is scheduled when only left node is unschedulable
+	ps.updateNodePool(node1, ie.NodeEventTypeUpdate)
+	unschedulable := node2.DeepCopy()
+	unschedulable.Spec.Unschedulable = true
+	ps.updateNodePool(unschedulable, ie.NodeEventTypeUpdate)
+	if err := ps.schedulePod(newTestPod("pod4", 100, 128), []*SchedulableNode{ps.nodePool.GetNode(util.Name(node2))}); err != InsufficientResourceError {
+		t.Errorf("expect no node for pod on unschedulable node, got %v", err)
+	}
+	if err := ps.schedulePod(newTestPod("pod4", 100, 128), ps.nodePool.GetNodes()); err != nil || client.createdPod["test/pod4"] != util.Name(node1) {
+		t.Errorf("expect pod4 scheduled on schedulable again %s, got %s, %v", util.Name(node1), client.createdPod["test/pod4"], err)
+	}
+
+	// node not running is removed from pool
+	disconnected := node1.DeepCopy()
+	disconnected.Status.Phase = v1.NodePending
+	ps.updateNodePool(disconnected, ie.NodeEventTypeUpdate)
+	if ps.nodePool.GetNode(util.Name(node1)) != nil {
+		t.Errorf("expect not running node removed from pool")
+	}
+}
+
+func TestRecordScheduleResult(t *testing.T) {
+	metrics.Register()
+	tests := []struct {
+		err    error
+		result string
+		reason string
+	}{
+		{err: nil, result: "scheduled"},
+		{err: InsufficientResourceError, result: "failed", reason: "insufficient_resource"},
+		{err: PodBindToNodeError, result: "failed", reason: "bind_error"},
+		{err: PodIsDeletedError, result: "failed", reason: "pod_deleted"},
+		{err: fmt.Errorf("other error"), result: "failed", reason: "unknown"},
+	}
+	for _, test := range tests {
+		attempts, _ := testutil.GetCounterMetricValue(metrics.ScheduleAttempts.WithLabelValues(test.result))
+		failures := float64(0)
+		if len(test.reason) > 0 {
+			failures, _ = testutil.GetCounterMetricValue(metrics.ScheduleFailures.WithLabelValues(test.reason))
+		}
+		recordScheduleResult(test.err)
+		if v, _ := testutil.GetCounterMetricValue(metrics.ScheduleAttempts.WithLabelValues(test.result)); v != attempts+1 {
+			t.Errorf("%v: expect %s attempts %v, got %v", test.err, test.result, attempts+1, v)
+		}
+		if len(test.reason) > 0 {
+			if v, _ := testutil.GetCounterMetricValue(metrics.ScheduleFailures.WithLabelValues(test.reason)); v != failures+1 {
+				t.Errorf("%v: expect %s failures %v, got %v", test.err, test.reason, failures+1, v)
+			}
+		}
+	}
+}
+
+func TestScheduleQueueLengthMetrics(t *testing.T) {
+	metrics.Register()
+	expectQueueLength := func(step string, active, backoff float64) {
+		a, _ := testutil.GetGaugeMetricValue(metrics.ScheduleQueueLength.WithLabelValues("active"))
+		b, _ := testutil.GetGaugeMetricValue(metrics.ScheduleQueueLength.WithLabelValues("backoff"))
+		if a != active || b != backoff {
+			t.Errorf("%s: expect active queue length %v and backoff queue length %v, got %v and %v", step, active, backoff, a, b)
+		}
+	}
+	q := NewScheduleQueue()
+	pod1, pod2 := newTestPod("pod1", 100, 128), newTestPod("pod2", 100, 128)
+	q.AddPod(pod1, 0)
+	q.AddPod(pod2, 0)
+	expectQueueLength("enqueue", 2, 0)
+	q.BackoffPod(pod1, time.Minute)
+	expectQueueLength("backoff", 1, 1)
+	if pod := q.NextPod(); pod == nil {
+		t.Fatalf("expect a pod in active queue")
+	}
+	expectQueueLength("dequeue", 0, 1)
+	q.AddPod(pod1, 0)
+	expectQueueLength("revive", 1, 0)
+	q.RemovePod(pod1)
+	expectQueueLength("remove", 0, 0)
+}
@@ -16,9 +16,16 @@ limitations under the License.
 package podscheduler
 
 import (
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	podutil "centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	v1helper "k8s.io/component-helpers/scheduling/corev1"
+	"k8s.io/component-helpers/scheduling/corev1/nodeaffinity"
+	"k8s.io/klog/v2"
 )
 
 var (
@@ -32,13 +39,70 @@ type ScheduleCondition interface {
 	Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64
 }
 
+// ConditionBuildFunc build a schedule condition of a pod, it return nil if pod does not require this condition
 type ConditionBuildFunc func(*v1.Pod) ScheduleCondition
 
+type ScheduleConditionName string
+
+const (
+	ScheduleConditionCPU                       ScheduleConditionName = "CPU"
+	ScheduleConditionMemory                    ScheduleConditionName = "Memory"
+	ScheduleConditionStorage                   ScheduleConditionName = "Storage"
+	ScheduleConditionNodeName                  ScheduleConditionName = "NodeName"
+	ScheduleConditionNodeSelector              ScheduleConditionName = "NodeSelector"
+	ScheduleConditionNodeAffinityPreference    ScheduleConditionName = "NodeAffinityPreference"
+	ScheduleConditionTaintToleration           ScheduleConditionName = "TaintToleration"
+	ScheduleConditionPodAntiAffinity           ScheduleConditionName = "PodAntiAffinity"
+	ScheduleConditionPodAntiAffinityPreference ScheduleConditionName = "PodAntiAffinityPreference"
+)
+
+var ScheduleConditionBuildFuncs = map[ScheduleConditionName]ConditionBuildFunc{
+	ScheduleConditionCPU:                       NewPodCPUCondition,
+	ScheduleConditionMemory:                    NewPodMemoryCondition,
+	ScheduleConditionStorage:                   NewStorageCondition,
+	ScheduleConditionNodeName:                  NewNodeNameCondition,
+	ScheduleConditionNodeSelector:              NewNodeSelectorCondition,
+	ScheduleConditionNodeAffinityPreference:    NewNodeAffinityPreferenceCondition,
+	ScheduleConditionTaintToleration:           NewTaintTolerationCondition,
+	ScheduleConditionPodAntiAffinity:           NewPodAntiAffinityCondition,
+	ScheduleConditionPodAntiAffinityPreference: NewPodAntiAffinityPreferenceCondition,
+}
+
+// DefaultScheduleConditions are used when schedule policy does not specify conditions
+var DefaultScheduleConditions = []ScheduleConditionName{
+	ScheduleConditionCPU,
+	ScheduleConditionMemory,
+	ScheduleConditionNodeName,
+	ScheduleConditionNodeSelector,
+	ScheduleConditionNodeAffinityPreference,
+	ScheduleConditionTaintToleration,
+	ScheduleConditionPodAntiAffinity,
+	ScheduleConditionPodAntiAffinityPreference,
+}
+
+// BuildScheduleConditionBuilders return condition build funcs of condition names, unknown names are ignored
+func BuildScheduleConditionBuilders(names []ScheduleConditionName) []ConditionBuildFunc {
+	if len(names) == 0 {
+		names = DefaultScheduleConditions
+	}
+	builders := []ConditionBuildFunc{}
+	for _, name := range names {
+		if f, found := ScheduleConditionBuildFuncs[name]; found {
+			builders = append(builders, f)
+		} else {
+			klog.Warningf("Unknown schedule condition %s, ignore it", name)
+		}
+	}
+	return builders
+}
+
 func CalculateScheduleConditions(condBuildFuncs []ConditionBuildFunc, pod *v1.Pod) []ScheduleCondition {
 	conditions := []ScheduleCondition{}
 	for _, v := range condBuildFuncs {
 		condition := v(pod)
-		conditions = append(conditions, condition)
+		if condition != nil {
+			conditions = append(conditions, condition)
+		}
 	}
 	return conditions
 }
@@ -157,32 +221,264 @@ func NewStorageCondition(pod *v1.Pod) ScheduleCondition {
 
 }
 
+var _ ScheduleCondition = &NodeNameCondition{}
+
 type NodeNameCondition struct {
-	Name             string
-	ResourceQuantity resource.Quantity
+	Name     string
+	NodeName string
 }
 
-func (*NodeNameCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
-	panic("unimplemented")
+// check if node is the node pod pinned to
+func (cond *NodeNameCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	return node.NodeName == cond.NodeName
 }
 
+// Mandatory of node name condition, true always
 func (*NodeNameCondition) Mandatory() bool {
-	panic("unimplemented")
+	return true
 }
 
 func (*NodeNameCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
-	panic("unimplemented")
+	return 0
 }
 
 func NewNodeNameCondition(pod *v1.Pod) ScheduleCondition {
-	resource := podutil.GetPodResourceList(pod).Cpu()
-	if resource.Sign() > 0 {
+	if len(pod.Spec.NodeName) > 0 {
 		return &NodeNameCondition{
-			Name:             "NodeName",
-			ResourceQuantity: *resource,
+			Name:     "NodeName",
+			NodeName: pod.Spec.NodeName,
 		}
 	} else {
 		return nil
 	}
+}
+
+var _ ScheduleCondition = &NodeSelectorCondition{}
+
+// NodeSelectorCondition check node labels against pod node selector and required node affinity
+type NodeSelectorCondition struct {
+	Name             string
+	RequiredAffinity nodeaffinity.RequiredNodeAffinity
+}
+
+// Mandatory of node selector condition, true always
+func (*NodeSelectorCondition) Mandatory() bool {
+	return true
+}
+
+// check if node labels match pod node selector and required node affinity
+func (cond *NodeSelectorCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	match, err := cond.RequiredAffinity.Match(node.GetNode())
+	if err != nil {
+		klog.ErrorS(err, "Failed to match node selector", "node", node.NodeName)
+		return false
+	}
+	return match
+}
+
+func (*NodeSelectorCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
+	return 0
+}
+
+func NewNodeSelectorCondition(pod *v1.Pod) ScheduleCondition {
+	if len(pod.Spec.NodeSelector) == 0 && (pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil) {
+		return nil
+	}
+	return &NodeSelectorCondition{
+		Name:             "NodeSelector",
+		RequiredAffinity: nodeaffinity.GetRequiredNodeAffinity(pod),
+	}
+}
+
+var _ ScheduleCondition = &NodeAffinityPreferenceCondition{}
+
+// NodeAffinityPreferenceCondition score node using weight of matched preferred node affinity terms
+type NodeAffinityPreferenceCondition struct {
+	Name           string
+	PreferredTerms *nodeaffinity.PreferredSchedulingTerms
+}
+
+// Mandatory of preference condition, false always
+func (*NodeAffinityPreferenceCondition) Mandatory() bool {
+	return false
+}
+
+func (*NodeAffinityPreferenceCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	return true
+}
+
+// sum weights of preferred node affinity terms matched by node
+func (cond *NodeAffinityPreferenceCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
+	return cond.PreferredTerms.Score(node.GetNode())
+}
+
+func NewNodeAffinityPreferenceCondition(pod *v1.Pod) ScheduleCondition {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil || len(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return nil
+	}
+	terms, err := nodeaffinity.NewPreferredSchedulingTerms(pod.Spec.Affinity.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution)
+	if err != nil {
+		klog.ErrorS(err, "Invalid preferred node affinity, ignore it", "pod", podutil.Name(pod))
+		return nil
+	}
+	return &NodeAffinityPreferenceCondition{
+		Name:           "NodeAffinityPreference",
+		PreferredTerms: terms,
+	}
+}
+
+var _ ScheduleCondition = &TaintTolerationCondition{}
+
+// TaintTolerationCondition check if pod tolerate node NoSchedule and NoExecute taints
+type TaintTolerationCondition struct {
+	Name        string
+	Tolerations []v1.Toleration
+}
 
+// Mandatory of taint toleration condition, true always
+func (*TaintTolerationCondition) Mandatory() bool {
+	return true
+}
+
+// check if all node NoSchedule and NoExecute taints are tolerated by pod
+func (cond *TaintTolerationCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	_, untolerated := v1helper.FindMatchingUntoleratedTaint(node.GetNode().Spec.Taints, cond.Tolerations, func(t *v1.Taint) bool {
+		return t.Effect == v1.TaintEffectNoSchedule || t.Effect == v1.TaintEffectNoExecute
+	})
+	return !untolerated
+}
+
+// PreferNoSchedule taints are not used to score node
+func (cond *TaintTolerationCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
+	return 0
+}
+
+// taint toleration condition is always built, node taints need to be checked even pod has no toleration
+func NewTaintTolerationCondition(pod *v1.Pod) ScheduleCondition {
+	return &TaintTolerationCondition{
+		Name:        "TaintToleration",
+		Tolerations: pod.Spec.Tolerations,
+	}
+}
+
+type podAntiAffinityTerm struct {
+	namespaces sets.String
+	selector   labels.Selector
+	weight     int64
+}
+
+func (term *podAntiAffinityTerm) matchedPods(node *SchedulableNode) int64 {
+	return int64(node.CountPods(func(namespace string, podLabels labels.Set) bool {
+		return term.namespaces.Has(namespace) && term.selector.Matches(podLabels)
+	}))
+}
+
+// buildPodAntiAffinityTerm translate a pod affinity term to a selector on pods of node,
+// term without label selector match pods of same application, term without namespaces match pod own namespace
+func buildPodAntiAffinityTerm(pod *v1.Pod, term *v1.PodAffinityTerm, weight int32) *podAntiAffinityTerm {
+	namespaces := sets.NewString(term.Namespaces...)
+	if namespaces.Len() == 0 {
+		namespaces.Insert(pod.Namespace)
+	}
+	var selector labels.Selector
+	if term.LabelSelector == nil {
+		selector = labels.SelectorFromSet(labels.Set{fornaxv1.LabelFornaxCoreApplication: pod.Labels[fornaxv1.LabelFornaxCoreApplication]})
+	} else {
+		var err error
+		selector, err = metav1.LabelSelectorAsSelector(term.LabelSelector)
+		if err != nil {
+			klog.ErrorS(err, "Invalid pod anti affinity label selector, ignore it", "pod", podutil.Name(pod))
+			return nil
+		}
+	}
+	return &podAntiAffinityTerm{
+		namespaces: namespaces,
+		selector:   selector,
+		weight:     int64(weight),
+	}
+}
+
+var _ ScheduleCondition = &PodAntiAffinityCondition{}
+
+// PodAntiAffinityCondition reject node which has pods matching required pod anti affinity terms
+type PodAntiAffinityCondition struct {
+	Name  string
+	Terms []*podAntiAffinityTerm
+}
+
+// Mandatory of pod anti affinity condition, true always
+func (*PodAntiAffinityCondition) Mandatory() bool {
+	return true
+}
+
+// check if node has no pod matching any anti affinity term
+func (cond *PodAntiAffinityCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	for _, term := range cond.Terms {
+		if term.matchedPods(node) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (*PodAntiAffinityCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
+	return 0
+}
+
+func NewPodAntiAffinityCondition(pod *v1.Pod) ScheduleCondition {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil || len(pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return nil
+	}
+	terms := []*podAntiAffinityTerm{}
+	for _, v := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		if term := buildPodAntiAffinityTerm(pod, &v, 0); term != nil {
+			terms = append(terms, term)
+		}
+	}
+	return &PodAntiAffinityCondition{
+		Name:  "PodAntiAffinity",
+		Terms: terms,
+	}
+}
+
+var _ ScheduleCondition = &PodAntiAffinityPreferenceCondition{}
+
+// PodAntiAffinityPreferenceCondition score node lower when it has more pods matching preferred pod anti affinity terms
+type PodAntiAffinityPreferenceCondition struct {
+	Name  string
+	Terms []*podAntiAffinityTerm
+}
+
+// Mandatory of preference condition, false always
+func (*PodAntiAffinityPreferenceCondition) Mandatory() bool {
+	return false
+}
+
+func (*PodAntiAffinityPreferenceCondition) Apply(node *SchedulableNode, allocatableResourceList *v1.ResourceList) bool {
+	return true
+}
+
+// every matched pod reduce score by term weight
+func (cond *PodAntiAffinityPreferenceCondition) Score(node *SchedulableNode, allocatableResourceList *v1.ResourceList) int64 {
+	score := int64(0)
+	for _, term := range cond.Terms {
+		score -= term.weight * term.matchedPods(node)
+	}
+	return score
+}
+
+func NewPodAntiAffinityPreferenceCondition(pod *v1.Pod) ScheduleCondition {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil || len(pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) == 0 {
+		return nil
+	}
+	terms := []*podAntiAffinityTerm{}
+	for _, v := range pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		if term := buildPodAntiAffinityTerm(pod, &v.PodAffinityTerm, v.Weight); term != nil {
+			terms = append(terms, term)
+		}
+	}
+	return &PodAntiAffinityPreferenceCondition{
+		Name:  "PodAntiAffinityPreference",
+		Terms: terms,
+	}
 }
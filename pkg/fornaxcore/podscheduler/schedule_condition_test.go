@@ -0,0 +1,238 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podscheduler
+
+import (
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTestSchedulableNode return a schedulable node with labels, taints and pods assigned to it
+func newTestSchedulableNode(name string, nodeLabels map[string]string, taints []v1.Taint, pods ...*v1.Pod) *SchedulableNode {
+	node := newTestNode(name, 1000, 1024)
+	node.Labels = nodeLabels
+	node.Spec.Taints = taints
+	snode := &SchedulableNode{
+		NodeName:                   util.Name(node),
+		Node:                       node,
+		ResourceList:               GetNodeAllocatableResourceList(node),
+		PodPreOccupiedResourceList: v1.ResourceList{},
+		pods:                       map[string]scheduledPod{},
+	}
+	for _, pod := range pods {
+		snode.AddPod(pod)
+	}
+	return snode
+}
+
+func newTestLabeledPod(namespace, name string, podLabels map[string]string) *v1.Pod {
+	pod := newTestPod(name, 100, 128)
+	pod.Namespace = namespace
+	pod.Labels = podLabels
+	return pod
+}
+
+type conditionTest struct {
+	name     string
+	node     *SchedulableNode
+	apply    bool
+	score    int64
+	noResult bool
+}
+
+func runConditionTests(t *testing.T, build ConditionBuildFunc, pod *v1.Pod, tests []conditionTest) {
+	for _, test := range tests {
+		cond := build(pod)
+		if test.noResult {
+			if cond != nil {
+				t.Errorf("%s: expect no condition built, got %v", test.name, cond)
+			}
+			continue
+		}
+		if cond == nil {
+			t.Fatalf("%s: expect condition built", test.name)
+		}
+		if apply := cond.Apply(test.node, &test.node.ResourceList); apply != test.apply {
+			t.Errorf("%s: expect condition applied %v, got %v", test.name, test.apply, apply)
+		}
+		if score := cond.Score(test.node, &test.node.ResourceList); score != test.score {
+			t.Errorf("%s: expect score %d, got %d", test.name, test.score, score)
+		}
+	}
+}
+
+func TestNodeNameCondition(t *testing.T) {
+	pod := newTestPod("pod", 100, 128)
+	runConditionTests(t, NewNodeNameCondition, pod, []conditionTest{
+		{name: "pod without node name", noResult: true},
+	})
+
+	pod.Spec.NodeName = "node/node1"
+	runConditionTests(t, NewNodeNameCondition, pod, []conditionTest{
+		{name: "pinned node", node: newTestSchedulableNode("node1", nil, nil), apply: true},
+		{name: "other node", node: newTestSchedulableNode("node2", nil, nil), apply: false},
+	})
+	if !NewNodeNameCondition(pod).Mandatory() {
+		t.Errorf("expect node name condition mandatory")
+	}
+}
+
+func TestNodeSelectorCondition(t *testing.T) {
+	pod := newTestPod("pod", 100, 128)
+	runConditionTests(t, NewNodeSelectorCondition, pod, []conditionTest{
+		{name: "pod without node selector", noResult: true},
+	})
+
+	pod.Spec.NodeSelector = map[string]string{"zone": "a"}
+	runConditionTests(t, NewNodeSelectorCondition, pod, []conditionTest{
+		{name: "selector matched", node: newTestSchedulableNode("node1", map[string]string{"zone": "a", "disk": "ssd"}, nil), apply: true},
+		{name: "selector not matched", node: newTestSchedulableNode("node2", map[string]string{"zone": "b"}, nil), apply: false},
+		{name: "node without labels", node: newTestSchedulableNode("node3", nil, nil), apply: false},
+	})
+
+	// node selector and required node affinity must be both matched
+	pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{NodeSelectorTerms: []v1.NodeSelectorTerm{{
+			MatchExpressions: []v1.NodeSelectorRequirement{{Key: "disk", Operator: v1.NodeSelectorOpIn, Values: []string{"ssd"}}},
+		}}},
+	}}
+	runConditionTests(t, NewNodeSelectorCondition, pod, []conditionTest{
+		{name: "selector and affinity matched", node: newTestSchedulableNode("node1", map[string]string{"zone": "a", "disk": "ssd"}, nil), apply: true},
+		{name: "affinity not matched", node: newTestSchedulableNode("node2", map[string]string{"zone": "a", "disk": "hdd"}, nil), apply: false},
+	})
+
+	pod.Spec.NodeSelector = nil
+	runConditionTests(t, NewNodeSelectorCondition, pod, []conditionTest{
+		{name: "affinity only matched", node: newTestSchedulableNode("node1", map[string]string{"disk": "ssd"}, nil), apply: true},
+		{name: "affinity only not matched", node: newTestSchedulableNode("node2", map[string]string{"zone": "a"}, nil), apply: false},
+	})
+}
+
+func TestNodeAffinityPreferenceCondition(t *testing.T) {
+	pod := newTestPod("pod", 100, 128)
+	runConditionTests(t, NewNodeAffinityPreferenceCondition, pod, []conditionTest{
+		{name: "pod without preferred node affinity", noResult: true},
+	})
+
+	pod.Spec.Affinity = &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []v1.PreferredSchedulingTerm{
+			{Weight: 10, Preference: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "zone", Operator: v1.NodeSelectorOpIn, Values: []string{"a"}}}}},
+			{Weight: 5, Preference: v1.NodeSelectorTerm{MatchExpressions: []v1.NodeSelectorRequirement{{Key: "disk", Operator: v1.NodeSelectorOpExists}}}},
+		},
+	}}
+	runConditionTests(t, NewNodeAffinityPreferenceCondition, pod, []conditionTest{
+		{name: "all terms matched", node: newTestSchedulableNode("node1", map[string]string{"zone": "a", "disk": "ssd"}, nil), apply: true, score: 15},
+		{name: "one term matched", node: newTestSchedulableNode("node2", map[string]string{"zone": "b", "disk": "ssd"}, nil), apply: true, score: 5},
+		{name: "no term matched", node: newTestSchedulableNode("node3", map[string]string{"zone": "b"}, nil), apply: true, score: 0},
+	})
+	if NewNodeAffinityPreferenceCondition(pod).Mandatory() {
+		t.Errorf("expect node affinity preference condition not mandatory")
+	}
+}
+
+func TestTaintTolerationCondition(t *testing.T) {
+	noSchedule := v1.Taint{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule}
+	noExecute := v1.Taint{Key: "maintenance", Effect: v1.TaintEffectNoExecute}
+	preferNoSchedule := v1.Taint{Key: "busy", Effect: v1.TaintEffectPreferNoSchedule}
+
+	pod := newTestPod("pod", 100, 128)
+	runConditionTests(t, NewTaintTolerationCondition, pod, []conditionTest{
+		{name: "node without taints", node: newTestSchedulableNode("node1", nil, nil), apply: true},
+		{name: "no schedule taint", node: newTestSchedulableNode("node2", nil, []v1.Taint{noSchedule}), apply: false},
+		{name: "no execute taint", node: newTestSchedulableNode("node3", nil, []v1.Taint{noExecute}), apply: false},
+		{name: "prefer no schedule taint", node: newTestSchedulableNode("node4", nil, []v1.Taint{preferNoSchedule}), apply: true},
+	})
+
+	pod.Spec.Tolerations = []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	runConditionTests(t, NewTaintTolerationCondition, pod, []conditionTest{
+		{name: "tolerated taint", node: newTestSchedulableNode("node2", nil, []v1.Taint{noSchedule}), apply: true},
+		{name: "one of taints not tolerated", node: newTestSchedulableNode("node5", nil, []v1.Taint{noSchedule, noExecute}), apply: false},
+	})
+
+	pod.Spec.Tolerations = []v1.Toleration{{Operator: v1.TolerationOpExists}}
+	runConditionTests(t, NewTaintTolerationCondition, pod, []conditionTest{
+		{name: "tolerate all taints", node: newTestSchedulableNode("node5", nil, []v1.Taint{noSchedule, noExecute}), apply: true},
+	})
+}
+
+func TestPodAntiAffinityCondition(t *testing.T) {
+	appLabels := map[string]string{fornaxv1.LabelFornaxCoreApplication: "test/app"}
+	pod := newTestLabeledPod("test", "pod", appLabels)
+	runConditionTests(t, NewPodAntiAffinityCondition, pod, []conditionTest{
+		{name: "pod without anti affinity", noResult: true},
+	})
+
+	// term without label selector match pods of same application in pod namespace
+	pod.Spec.Affinity = &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: []v1.PodAffinityTerm{{TopologyKey: v1.LabelHostname}},
+	}}
+	runConditionTests(t, NewPodAntiAffinityCondition, pod, []conditionTest{
+		{name: "empty node", node: newTestSchedulableNode("node1", nil, nil), apply: true},
+		{name: "pod of same application", node: newTestSchedulableNode("node2", nil, nil, newTestLabeledPod("test", "other", appLabels)), apply: false},
+		{name: "pod of other application", node: newTestSchedulableNode("node3", nil, nil, newTestLabeledPod("test", "other", map[string]string{fornaxv1.LabelFornaxCoreApplication: "test/other"})), apply: true},
+		{name: "pod of same application in other namespace", node: newTestSchedulableNode("node4", nil, nil, newTestLabeledPod("other", "other", appLabels)), apply: true},
+	})
+
+	pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = []v1.PodAffinityTerm{{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}},
+		Namespaces:    []string{"test", "other"},
+		TopologyKey:   v1.LabelHostname,
+	}}
+	runConditionTests(t, NewPodAntiAffinityCondition, pod, []conditionTest{
+		{name: "label selector matched", node: newTestSchedulableNode("node1", nil, nil, newTestLabeledPod("other", "db", map[string]string{"tier": "db"})), apply: false},
+		{name: "label selector not matched", node: newTestSchedulableNode("node2", nil, nil, newTestLabeledPod("test", "web", map[string]string{"tier": "web"})), apply: true},
+		{name: "namespace not matched", node: newTestSchedulableNode("node3", nil, nil, newTestLabeledPod("third", "db", map[string]string{"tier": "db"})), apply: true},
+	})
+}
+
+func TestPodAntiAffinityPreferenceCondition(t *testing.T) {
+	appLabels := map[string]string{fornaxv1.LabelFornaxCoreApplication: "test/app"}
+	dbLabels := map[string]string{"tier": "db"}
+	pod := newTestLabeledPod("test", "pod", appLabels)
+	runConditionTests(t, NewPodAntiAffinityPreferenceCondition, pod, []conditionTest{
+		{name: "pod without preferred anti affinity", noResult: true},
+	})
+
+	pod.Spec.Affinity = &v1.Affinity{PodAntiAffinity: &v1.PodAntiAffinity{
+		PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+			{Weight: 10, PodAffinityTerm: v1.PodAffinityTerm{TopologyKey: v1.LabelHostname}},
+			{Weight: 3, PodAffinityTerm: v1.PodAffinityTerm{LabelSelector: &metav1.LabelSelector{MatchLabels: dbLabels}, TopologyKey: v1.LabelHostname}},
+		},
+	}}
+	runConditionTests(t, NewPodAntiAffinityPreferenceCondition, pod, []conditionTest{
+		{name: "empty node", node: newTestSchedulableNode("node1", nil, nil), apply: true, score: 0},
+		{
+			name:  "two pods of same application",
+			node:  newTestSchedulableNode("node2", nil, nil, newTestLabeledPod("test", "app1", appLabels), newTestLabeledPod("test", "app2", appLabels)),
+			apply: true,
+			score: -20,
+		},
+		{
+			name:  "pods matching both terms",
+			node:  newTestSchedulableNode("node3", nil, nil, newTestLabeledPod("test", "app1", appLabels), newTestLabeledPod("test", "db", dbLabels)),
+			apply: true,
+			score: -13,
+		},
+	})
+	if NewPodAntiAffinityPreferenceCondition(pod).Mandatory() {
+		t.Errorf("expect pod anti affinity preference condition not mandatory")
+	}
+}
@@ -17,15 +17,28 @@ limitations under the License.
 package podscheduler
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"centaurusinfra.io/fornax-serverless/pkg/collection"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog/v2"
 )
 
+// scheduledPod is the minimal pod info kept on schedulable node to evaluate pod anti affinity and preemption
+type scheduledPod struct {
+	name         string
+	namespace    string
+	labels       labels.Set
+	priority     int32
+	idle         bool
+	preempting   bool
+	resourceList v1.ResourceList
+}
+
 type SchedulableNode struct {
 	mu                         sync.Mutex
 	NodeName                   string
@@ -35,6 +48,66 @@ type SchedulableNode struct {
 	Stat                       ScheduleStat
 	ResourceList               v1.ResourceList
 	PodPreOccupiedResourceList v1.ResourceList
+	pods                       map[string]scheduledPod
+}
+
+func (snode *SchedulableNode) GetNode() *v1.Node {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	return snode.Node
+}
+
+// SetNode replace node with latest revision to pick up labels and taints change
+// SetNode update node and its allocatable resources, allocatable resources change when node reserve more or less resources
+func (snode *SchedulableNode) SetNode(node *v1.Node) {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	snode.Node = node
+	snode.ResourceList = GetNodeAllocatableResourceList(node)
+}
+
+// Schedulable return false if node is marked unschedulable, e.g. node is being evacuated,
+// node is kept in pool to account resources of its existing pods, but no new pod is placed on it
+func (snode *SchedulableNode) Schedulable() bool {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	return util.IsNodeSchedulable(snode.Node)
+}
+
+// AddPod remember pod assigned to this node, replace it if there is a existing one with same name
+func (snode *SchedulableNode) AddPod(pod *v1.Pod) {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	name := util.Name(pod)
+	_, hasSession := util.PodHasSession(pod)
+	snode.pods[name] = scheduledPod{
+		name:         name,
+		namespace:    pod.Namespace,
+		labels:       labels.Merge(labels.Set{}, pod.Labels),
+		priority:     util.PodPriority(pod),
+		idle:         util.PodIsRunning(pod) && !hasSession && pod.DeletionTimestamp == nil,
+		preempting:   snode.pods[name].preempting,
+		resourceList: *util.GetPodResourceList(pod),
+	}
+}
+
+func (snode *SchedulableNode) RemovePod(pod *v1.Pod) {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	delete(snode.pods, util.Name(pod))
+}
+
+// CountPods return number of pods on this node matching filter
+func (snode *SchedulableNode) CountPods(filter func(namespace string, podLabels labels.Set) bool) int {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	num := 0
+	for _, v := range snode.pods {
+		if filter(v.namespace, v.labels) {
+			num += 1
+		}
+	}
+	return num
 }
 
 func (snode *SchedulableNode) AdmitPodOccupiedResourceList(resourceList *v1.ResourceList) {
@@ -125,6 +198,47 @@ func (snode *SchedulableNode) ReleasePodOccupiedResourceList(resourceList *v1.Re
 	snode.PodPreOccupiedResourceList[v1.ResourceStorage] = nodeStorage
 }
 
+// PreemptableIdlePods return idle pods with priority lower than specified priority and not being preempted yet,
+// pods are sorted by priority, lowest priority first
+func (snode *SchedulableNode) PreemptableIdlePods(priority int32) []scheduledPod {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	pods := []scheduledPod{}
+	for _, v := range snode.pods {
+		if v.idle && !v.preempting && v.priority < priority {
+			pods = append(pods, v)
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].priority < pods[j].priority
+	})
+	return pods
+}
+
+// MarkPodsPreempting mark pods to avoid preempting same pods again before they are terminated
+func (snode *SchedulableNode) MarkPodsPreempting(pods []scheduledPod) {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	for _, v := range pods {
+		if p, found := snode.pods[v.name]; found {
+			p.preempting = true
+			snode.pods[v.name] = p
+		}
+	}
+}
+
+// UnmarkPodsPreempting make pods preemptable again, e.g. pod got a session before it was terminated
+func (snode *SchedulableNode) UnmarkPodsPreempting(pods []scheduledPod) {
+	snode.mu.Lock()
+	defer snode.mu.Unlock()
+	for _, v := range pods {
+		if p, found := snode.pods[v.name]; found {
+			p.preempting = false
+			snode.pods[v.name] = p
+		}
+	}
+}
+
 type SortedNodes struct {
 	nodes    []*SchedulableNode
 	lessFunc collection.LessFunc
@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podscheduler
+
+import (
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// podCanPreempt return true if pod is created for a pending session and its preemption policy allow to preempt lower priority pods,
+// pods created for idle buffer never preempt other pods
+func podCanPreempt(pod *v1.Pod) bool {
+	return util.PodHasSessionPendingAnnotation(pod) &&
+		pod.Spec.PreemptionPolicy != nil &&
+		*pod.Spec.PreemptionPolicy == v1.PreemptLowerPriority
+}
+
+// addResourceList add cpu, memory and storage of resourceList into target
+func addResourceList(target v1.ResourceList, resourceList v1.ResourceList) {
+	for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourceStorage} {
+		quantity := target[name]
+		if v, found := resourceList[name]; found {
+			quantity.Add(v)
+		}
+		target[name] = quantity
+	}
+}
+
+// findPreemptionVictims find a node which meet pod's mandatory conditions after terminating some lower priority idle pods,
+// it prefer to terminate lowest priority pods, and stop when node has enough resource
+func (ps *podScheduler) findPreemptionVictims(pod *v1.Pod, candidateNodes []*SchedulableNode) (*SchedulableNode, []scheduledPod) {
+	priority := util.PodPriority(pod)
+	conditions := CalculateScheduleConditions(ps.ScheduleConditionBuilders, pod)
+	for _, node := range candidateNodes {
+		if !node.Schedulable() {
+			continue
+		}
+		allocatableResources := node.GetAllocatableResources()
+		victims := []scheduledPod{}
+		for _, v := range node.PreemptableIdlePods(priority) {
+			victims = append(victims, v)
+			addResourceList(allocatableResources, v.resourceList)
+			goodNode := true
+			for _, cond := range conditions {
+				if cond.Mandatory() && !cond.Apply(node, &allocatableResources) {
+					goodNode = false
+					break
+				}
+			}
+			if goodNode {
+				return node, victims
+			}
+		}
+	}
+	return nil, nil
+}
+
+// selectPreemptionVictims find lower priority idle pods to terminate to make room for a pod which can not find node with sufficient resource,
+// victims are marked preempting to avoid being selected again by other pods, return nil node if no victims are found,
+// it must be called with candidate nodes locked to avoid racing with pods being scheduled on these nodes
+func (ps *podScheduler) selectPreemptionVictims(pod *v1.Pod, candidateNodes []*SchedulableNode) (*SchedulableNode, []scheduledPod) {
+	if !podCanPreempt(pod) {
+		return nil, nil
+	}
+
+	node, victims := ps.findPreemptionVictims(pod, candidateNodes)
+	if node == nil {
+		return nil, nil
+	}
+	node.MarkPodsPreempting(victims)
+	return node, victims
+}
+
+// terminatePreemptionVictims terminate victims through pod manager, application manager will find these idle pods are gone and scale its application again,
+// pod itself is not bound to node here, it will be scheduled again after backoff when victims release resources,
+// it does not need nodes locked, it should not hold scheduler lock when calling pod manager,
+// a victim is skipped if sessions were assigned to it after it was selected, node has not labeled it with session yet
+func (ps *podScheduler) terminatePreemptionVictims(pod *v1.Pod, node *SchedulableNode, victims []scheduledPod) {
+	for _, v := range victims {
+		if ps.podHasAssignedSessions(v.name) {
+			klog.InfoS("Skip preempting pod which is assigned sessions", "pod", util.Name(pod), "victim", v.name, "node", node.NodeName)
+			node.UnmarkPodsPreempting([]scheduledPod{v})
+			continue
+		}
+		klog.InfoS("Preempting idle pod", "pod", util.Name(pod), "priority", util.PodPriority(pod), "victim", v.name, "victim priority", v.priority, "node", node.NodeName)
+		if err := ps.podManager.TerminatePod(v.name); err != nil {
+			klog.ErrorS(err, "Failed to terminate preempted pod", "pod", v.name, "node", node.NodeName)
+		}
+	}
+}
+
+// podHasAssignedSessions check pod sessions using latest pod and pod session provider
+func (ps *podScheduler) podHasAssignedSessions(podName string) bool {
+	pod := ps.podManager.FindPod(podName)
+	if pod == nil {
+		return false
+	}
+	if _, found := util.PodHasSession(pod); found {
+		return true
+	}
+	return ps.podSessionProvider != nil && ps.podSessionProvider.PodHasAssignedSessions(pod)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podscheduler
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	v1 "k8s.io/api/core/v1"
+)
+
+// fakePodManager remember terminated pods, and check chunk scheduler lock is not held when pod is terminated
+type fakePodManager struct {
+	ie.PodManagerInterface
+	cps        *nodeChunkScheduler
+	lockHeld   bool
+	terminated []string
+	pods       map[string]*v1.Pod
+}
+
+func (pm *fakePodManager) TerminatePod(podName string) error {
+	if pm.cps.mu.TryLock() {
+		pm.cps.mu.Unlock()
+	} else {
+		pm.lockHeld = true
+	}
+	pm.terminated = append(pm.terminated, podName)
+	return nil
+}
+
+func (pm *fakePodManager) FindPod(podName string) *v1.Pod {
+	return pm.pods[podName]
+}
+
+// fakePodSessionProvider tell pods which are assigned sessions by application manager
+type fakePodSessionProvider struct {
+	assigned map[string]bool
+}
+
+func (p *fakePodSessionProvider) PodHasAssignedSessions(pod *v1.Pod) bool {
+	return p.assigned[util.Name(pod)]
+}
+
+func newTestPriorityPod(name string, cpu int64, priority int32, sessionPending bool) *v1.Pod {
+	pod := newTestPod(name, cpu, 128)
+	pod.Annotations = map[string]string{}
+	pod.Spec.Priority = &priority
+	policy := v1.PreemptLowerPriority
+	pod.Spec.PreemptionPolicy = &policy
+	if sessionPending {
+		pod.Annotations[fornaxv1.AnnotationFornaxCoreSessionPendingPod] = "true"
+	}
+	return pod
+}
+
+func TestPodPriorityLess(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		itemi  *PodScheduleItem
+		itemj  *PodScheduleItem
+		expect bool
+	}{
+		{
+			name:   "session pending pod ahead of idle buffer pod with higher priority",
+			itemi:  &PodScheduleItem{sessionPending: true, priority: 0, requestTime: now},
+			itemj:  &PodScheduleItem{sessionPending: false, priority: 100, requestTime: now.Add(-time.Second)},
+			expect: true,
+		},
+		{
+			name:   "idle buffer pod behind session pending pod",
+			itemi:  &PodScheduleItem{sessionPending: false, priority: 100, requestTime: now.Add(-time.Second)},
+			itemj:  &PodScheduleItem{sessionPending: true, priority: 0, requestTime: now},
+			expect: false,
+		},
+		{
+			name:   "higher priority ahead",
+			itemi:  &PodScheduleItem{sessionPending: true, priority: 10, requestTime: now},
+			itemj:  &PodScheduleItem{sessionPending: true, priority: 1, requestTime: now.Add(-time.Second)},
+			expect: true,
+		},
+		{
+			name:   "lower priority behind",
+			itemi:  &PodScheduleItem{priority: 1, requestTime: now.Add(-time.Second)},
+			itemj:  &PodScheduleItem{priority: 10, requestTime: now},
+			expect: false,
+		},
+		{
+			name:   "earlier request ahead with same priority",
+			itemi:  &PodScheduleItem{priority: 1, requestTime: now.Add(-time.Second)},
+			itemj:  &PodScheduleItem{priority: 1, requestTime: now},
+			expect: true,
+		},
+		{
+			name:   "later request behind with same priority",
+			itemi:  &PodScheduleItem{priority: 1, requestTime: now},
+			itemj:  &PodScheduleItem{priority: 1, requestTime: now.Add(-time.Second)},
+			expect: false,
+		},
+	}
+	for _, test := range tests {
+		if less := PodPriorityLess(test.itemi, test.itemj); less != test.expect {
+			t.Errorf("%s: expect %v, got %v", test.name, test.expect, less)
+		}
+	}
+}
+
+func TestPreemptPod(t *testing.T) {
+	ps, _ := newTestScheduler()
+	// node has 300m cpu left, its pods use other resources
+	snode := newTestSchedulableNode("node1", nil, nil)
+	snode.SetNode(newTestNode("node1", 300, 1024))
+	for i, priority := range []int32{2, 1, 20} {
+		idle := newTestPriorityPod(fmt.Sprintf("idle-%d", priority), 200, priority, false)
+		idle.Status.Phase = v1.PodRunning
+		snode.AddPod(idle)
+		if i == 0 {
+			allocated := newTestPriorityPod("allocated", 200, 0, false)
+			allocated.Status.Phase = v1.PodRunning
+			allocated.Labels[fornaxv1.LabelFornaxCoreApplicationSession] = "test/session"
+			snode.AddPod(allocated)
+		}
+	}
+	cps := &nodeChunkScheduler{nodes: []*SchedulableNode{snode}, scheduler: ps}
+	pm := &fakePodManager{cps: cps}
+	ps.podManager = pm
+
+	// idle buffer pod and pod which never preempt can not preempt other pods
+	if cps.preemptPod(newTestPriorityPod("buffer", 400, 10, false)) {
+		t.Errorf("expect idle buffer pod not preempt other pods")
+	}
+	never := newTestPriorityPod("never", 400, 10, true)
+	policy := v1.PreemptNever
+	never.Spec.PreemptionPolicy = &policy
+	if cps.preemptPod(never) {
+		t.Errorf("expect pod with never preemption policy not preempt other pods")
+	}
+
+	// lowest priority idle pod is preempted first, pod preempted already is not preempted again,
+	// allocated pod and higher priority idle pod are never preempted
+	expect := [][]string{{"test/idle-1"}, {"test/idle-1", "test/idle-2"}, {"test/idle-1", "test/idle-2"}}
+	for i, e := range expect {
+		found := cps.preemptPod(newTestPriorityPod(fmt.Sprintf("pending-%d", i), 400, 10, true))
+		if found != (i < 2) {
+			t.Errorf("preemption %d: expect victims found %v, got %v", i, i < 2, found)
+		}
+		if !reflect.DeepEqual(pm.terminated, e) {
+			t.Errorf("preemption %d: expect victims %v, got %v", i, e, pm.terminated)
+		}
+	}
+	if pm.lockHeld {
+		t.Errorf("expect victims terminated after chunk scheduler lock released")
+	}
+
+	// more victims are selected until node has enough resource
+	pm.terminated = nil
+	snode = newTestSchedulableNode("node2", nil, nil)
+	snode.SetNode(newTestNode("node2", 0, 1024))
+	for _, name := range []string{"idle-a", "idle-b", "idle-c"} {
+		idle := newTestPriorityPod(name, 200, 1, false)
+		idle.Status.Phase = v1.PodRunning
+		snode.AddPod(idle)
+	}
+	cps.nodes = []*SchedulableNode{snode}
+	if !cps.preemptPod(newTestPriorityPod("pending-big", 300, 10, true)) || len(pm.terminated) != 2 {
+		t.Errorf("expect two victims to make room for pod, got %v", pm.terminated)
+	}
+}
+
+func TestPreemptPodSkipPodAssignedSessions(t *testing.T) {
+	ps, _ := newTestScheduler()
+	snode := newTestSchedulableNode("node1", nil, nil)
+	snode.SetNode(newTestNode("node1", 0, 1024))
+	idle := newTestPriorityPod("idle", 200, 1, false)
+	idle.Status.Phase = v1.PodRunning
+	snode.AddPod(idle)
+	cps := &nodeChunkScheduler{nodes: []*SchedulableNode{snode}, scheduler: ps}
+	pm := &fakePodManager{cps: cps, pods: map[string]*v1.Pod{"test/idle": idle}}
+	ps.podManager = pm
+	provider := &fakePodSessionProvider{assigned: map[string]bool{"test/idle": true}}
+	ps.SetPodSessionProvider(provider)
+
+	// pod looks idle on node, but application manager is opening a session on it
+	cps.preemptPod(newTestPriorityPod("pending-1", 100, 10, true))
+	if len(pm.terminated) != 0 {
+		t.Errorf("expect pod assigned session not preempted, got %v", pm.terminated)
+	}
+	if len(snode.PreemptableIdlePods(10)) != 1 {
+		t.Errorf("expect skipped victim preemptable again")
+	}
+
+	// pod labeled with session by node is not preempted either
+	labeled := idle.DeepCopy()
+	labeled.Labels[fornaxv1.LabelFornaxCoreApplicationSession] = "test/session"
+	pm.pods["test/idle"] = labeled
+	provider.assigned["test/idle"] = false
+	cps.preemptPod(newTestPriorityPod("pending-2", 100, 10, true))
+	if len(pm.terminated) != 0 {
+		t.Errorf("expect pod labeled with session not preempted, got %v", pm.terminated)
+	}
+
+	// pod is preempted after session is gone
+	pm.pods["test/idle"] = idle
+	if !cps.preemptPod(newTestPriorityPod("pending-3", 100, 10, true)) || !reflect.DeepEqual(pm.terminated, []string{"test/idle"}) {
+		t.Errorf("expect idle pod without sessions preempted, got %v", pm.terminated)
+	}
+}
@@ -24,20 +24,37 @@ import (
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/collection"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	podutil "centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
 )
 
 type PodScheduleItem struct {
-	pod         *v1.Pod
-	app         *fornaxv1.Application
-	requestTime time.Time
+	pod            *v1.Pod
+	app            *fornaxv1.Application
+	requestTime    time.Time
+	priority       int32
+	sessionPending bool
 }
 
 func PodRequestTimeLess(pi, pj interface{}) bool {
 	return pi.(*PodScheduleItem).requestTime.UnixMilli() < pj.(*PodScheduleItem).requestTime.UnixMilli()
 }
 
+// PodPriorityLess put pods created for pending sessions ahead of pods created for idle buffer,
+// then higher priority application pods ahead, and use request time to order pods with same priority
+func PodPriorityLess(pi, pj interface{}) bool {
+	itemi := pi.(*PodScheduleItem)
+	itemj := pj.(*PodScheduleItem)
+	if itemi.sessionPending != itemj.sessionPending {
+		return itemi.sessionPending
+	}
+	if itemi.priority != itemj.priority {
+		return itemi.priority > itemj.priority
+	}
+	return PodRequestTimeLess(pi, pj)
+}
+
 func PodName(pj interface{}) string {
 	return podutil.Name(pj.(*PodScheduleItem).pod)
 }
@@ -45,18 +62,27 @@ func PodName(pj interface{}) string {
 // A schedulePriorityQueue implements heap.Interface and holds Items.
 type schedulePriorityQueue struct {
 	mu    sync.RWMutex
+	name  string
 	queue *collection.PriorityQueue
 }
 
+// _recordLengthNoLock export queue length when pod is added into or removed from queue
+func (pq *schedulePriorityQueue) _recordLengthNoLock() {
+	metrics.ScheduleQueueLength.WithLabelValues(pq.name).Set(float64(pq.queue.Len()))
+}
+
 func (pq *schedulePriorityQueue) AddPod(v1pod *v1.Pod, duration time.Duration) {
 	pq.mu.Lock()
 	defer pq.mu.Unlock()
 	if _, item := pq.queue.Get(podutil.Name(v1pod)); item == nil {
 		item := &PodScheduleItem{
-			pod:         v1pod,
-			requestTime: time.Now().Add(duration),
+			pod:            v1pod,
+			requestTime:    time.Now().Add(duration),
+			priority:       podutil.PodPriority(v1pod),
+			sessionPending: podutil.PodHasSessionPendingAnnotation(v1pod),
 		}
 		heap.Push(pq.queue, item)
+		pq._recordLengthNoLock()
 	} else {
 		// TODO, need update?
 	}
@@ -68,6 +94,7 @@ func (pq *schedulePriorityQueue) RemovePod(v1pod *v1.Pod) *v1.Pod {
 	defer pq.mu.Unlock()
 	if index, item := pq.queue.Get(podutil.Name(v1pod)); item != nil {
 		heap.Remove(pq.queue, index)
+		pq._recordLengthNoLock()
 		return item.(*PodScheduleItem).pod
 	}
 	return nil
@@ -109,6 +136,7 @@ func (pq *schedulePriorityQueue) nextItem() *PodScheduleItem {
 		return nil
 	}
 	ob = heap.Pop(pq.queue)
+	pq._recordLengthNoLock()
 	if ob == nil {
 		return nil
 	}
@@ -213,18 +241,20 @@ func (q *PodScheduleQueue) RemovePod(v1pod *v1.Pod) *v1.Pod {
 	return nil
 }
 
-func newSchedulePriorityQueue() *schedulePriorityQueue {
+func newSchedulePriorityQueue(name string, lessFunc collection.LessFunc) *schedulePriorityQueue {
 	return &schedulePriorityQueue{
 		mu:    sync.RWMutex{},
-		queue: collection.NewPriorityQueue(PodRequestTimeLess, PodName),
+		name:  name,
+		queue: collection.NewPriorityQueue(lessFunc, PodName),
 	}
 }
 
 func NewScheduleQueue() *PodScheduleQueue {
 	return &PodScheduleQueue{
-		stop:              false,
-		c:                 sync.NewCond(&sync.Mutex{}),
-		activeQueue:       *newSchedulePriorityQueue(),
-		backoffRetryQueue: *newSchedulePriorityQueue(),
+		stop: false,
+		c:    sync.NewCond(&sync.Mutex{}),
+		// active queue is ordered by pod priority, backoff queue is ordered by retry time to revive pods in time
+		activeQueue:       *newSchedulePriorityQueue("active", PodPriorityLess),
+		backoffRetryQueue: *newSchedulePriorityQueue("backoff", PodRequestTimeLess),
 	}
 }
@@ -108,7 +108,7 @@ func (sm *sessionStatusUpdater) AsyncSessionStatusUpdateRun(ctx context.Context)
 		for {
 			select {
 			case <-ctx.Done():
-				break
+				return
 			case <-sm.statusUpdateCh:
 				// consume all signal in current channel
 				remainingLen := len(sm.statusUpdateCh)
@@ -24,12 +24,14 @@ import (
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
 	ie "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/internal"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/metrics"
 	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
 	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 	apistorage "k8s.io/apiserver/pkg/storage"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ ie.SessionManagerInterface = &sessionManager{}
@@ -59,7 +61,7 @@ func (sm *sessionManager) OnSessionStatusFromNode(nodeId string, pod *v1.Pod, se
 	}
 	if storeCopy == nil {
 		// it should not happen as session from node should be created in store already, unless store corruption
-		if !util.SessionIsClosed(session) {
+		if !util.SessionIsClosed(session) && !util.SessionIsEvacuated(session) {
 			storefactory.CreateApplicationSession(sm.ctx, sm.sessionStore, session)
 		}
 	} else {
@@ -67,15 +69,37 @@ func (sm *sessionManager) OnSessionStatusFromNode(nodeId string, pod *v1.Pod, se
 			// session was requested to delete, ask node to close session
 			session.DeletionTimestamp = storeCopy.DeletionTimestamp
 			sm.CloseSession(pod, session)
+		} else if util.SessionIsClosing(storeCopy) && util.SessionIsOpen(session) && !util.SessionIsClosing(session) {
+			// close command was not received by node, e.g. it was lost when fornaxcore restarted or failed over, send it again
+			sm.CloseSession(pod, session)
+			session.Status.SessionStatus = fornaxv1.SessionStatusClosing
 		}
-		// set available and close time received in fornax core, for perf benchmark
-		if session.Status.SessionStatus == fornaxv1.SessionStatusAvailable {
-			session.Status.AvailableTime = util.NewCurrentMetaTimeNormallized()
-			session.Status.AvailableTimeMicro = time.Now().UnixMicro()
+		// set available and close time received in fornax core, for perf benchmark,
+		// available time is kept until session is closed, session max lifetime is counted from it
+		if session.Status.SessionStatus == fornaxv1.SessionStatusAvailable || session.Status.SessionStatus == fornaxv1.SessionStatusInUse {
+			if storeCopy.Status.AvailableTime != nil && util.SessionIsOpen(storeCopy) {
+				session.Status.AvailableTime = storeCopy.Status.AvailableTime.DeepCopy()
+				session.Status.AvailableTimeMicro = storeCopy.Status.AvailableTimeMicro
+			} else {
+				session.Status.AvailableTime = util.NewCurrentMetaTimeNormallized()
+				session.Status.AvailableTimeMicro = time.Now().UnixMicro()
+			}
 		}
 		if session.Status.SessionStatus == fornaxv1.SessionStatusClosed {
 			session.Status.CloseTime = util.NewCurrentMetaTimeNormallized()
 		}
+		// node does not know since when session is idle and why fornaxcore closed it, keep them in store
+		session.Status.IdleSince = sessionIdleSince(storeCopy, session)
+		if len(session.Status.CloseReason) == 0 {
+			session.Status.CloseReason = storeCopy.Status.CloseReason
+		}
+		if util.SessionIsEvacuated(session) {
+			if storeCopy.Status.PodReference == nil || storeCopy.Status.PodReference.Name != util.Name(pod) {
+				// session was already requeued or reopened on another pod, it's a stale report from evacuated pod
+				return nil
+			}
+			session.Status = *evacuatedSessionStatus(storeCopy, session)
+		}
 
 		sm.UpdateSessionStatus(storeCopy.DeepCopy(), session.Status.DeepCopy())
 	}
@@ -83,6 +107,34 @@ func (sm *sessionManager) OnSessionStatusFromNode(nodeId string, pod *v1.Pod, se
 	return nil
 }
 
+// sessionIdleSince return since when a open session does not have connected clients,
+// idle time in store is kept if session did not have clients in last report also
+func sessionIdleSince(storeCopy, session *fornaxv1.ApplicationSession) *metav1.Time {
+	if !util.SessionIsOpen(session) || len(session.Status.ClientSessions) > 0 {
+		return nil
+	}
+	if storeCopy.Status.IdleSince != nil && util.SessionIsOpen(storeCopy) && len(storeCopy.Status.ClientSessions) == 0 {
+		return storeCopy.Status.IdleSince.DeepCopy()
+	}
+	return util.NewCurrentMetaTimeNormallized()
+}
+
+// evacuatedSessionStatus requeue a evacuated session as a pending session with its checkpoint,
+// application manager reopen it on another pod using session data and checkpoint, session requested to delete is closed
+func evacuatedSessionStatus(storeCopy, session *fornaxv1.ApplicationSession) *fornaxv1.ApplicationSessionStatus {
+	if storeCopy.DeletionTimestamp != nil {
+		status := session.Status.DeepCopy()
+		status.SessionStatus = fornaxv1.SessionStatusClosed
+		status.CloseTime = util.NewCurrentMetaTimeNormallized()
+		return status
+	}
+	return &fornaxv1.ApplicationSessionStatus{
+		SessionStatus:  fornaxv1.SessionStatusPending,
+		CheckpointData: session.Status.CheckpointData,
+		PendingSince:   util.NewCurrentMetaTimeNormallized(),
+	}
+}
+
 func (sm *sessionManager) CloseSession(pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
 	if nodeName, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreNode]; found {
 		return sm.nodeAgentClient.CloseSession(nodeName, pod, session)
@@ -134,6 +186,13 @@ func (sm *sessionManager) _updateSessionStatus(sessionName string, newStatus *fo
 
 		updatedSession := session.DeepCopy()
 		updatedSession.Status = *newStatus
+		statusChanged := session.Status.SessionStatus != newStatus.SessionStatus
+		if statusChanged {
+			updatedSession.Status.LastTransitionTime = util.NewCurrentMetaTimeNormallized()
+		} else if updatedSession.Status.LastTransitionTime == nil {
+			// status reported by node does not carry transition time
+			updatedSession.Status.LastTransitionTime = session.Status.LastTransitionTime
+		}
 		if util.SessionIsOpen(updatedSession) {
 			util.AddFinalizer(&updatedSession.ObjectMeta, fornaxv1.FinalizerOpenSession)
 		} else {
@@ -142,8 +201,19 @@ func (sm *sessionManager) _updateSessionStatus(sessionName string, newStatus *fo
 
 		_, updateErr = storefactory.UpdateApplicationSession(sm.ctx, sm.sessionStore, updatedSession)
 		if updateErr == nil {
+			if statusChanged {
+				metrics.SessionStatusTransitionDuration.WithLabelValues(string(session.Status.SessionStatus), string(newStatus.SessionStatus)).Observe(metrics.SinceInSeconds(sessionStatusSince(session)))
+			}
 			break
 		}
 	}
 	return updateErr
 }
+
+// sessionStatusSince return when session entered its current status, it's creation time if status never changed
+func sessionStatusSince(session *fornaxv1.ApplicationSession) time.Time {
+	if session.Status.LastTransitionTime != nil {
+		return session.Status.LastTransitionTime.Time
+	}
+	return session.CreationTimestamp.Time
+}
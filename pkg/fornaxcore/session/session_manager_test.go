@@ -0,0 +1,162 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/nodeagent"
+	storefactory "centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEvacuatedSessionStatus(t *testing.T) {
+	evacuated := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session"},
+		Status: fornaxv1.ApplicationSessionStatus{
+			SessionStatus:   fornaxv1.SessionStatusEvacuated,
+			PodReference:    &v1.LocalObjectReference{Name: "test/pod"},
+			AccessEndPoints: []fornaxv1.AccessEndPoint{{Protocol: v1.ProtocolTCP, IPAddress: "192.168.0.1", Port: 1024}},
+			ClientSessions:  []v1.LocalObjectReference{{Name: "client"}},
+			CheckpointData:  []byte("checkpoint"),
+		},
+	}
+
+	// session is requeued as pending with only its checkpoint, so it is reopened on another pod
+	storeCopy := evacuated.DeepCopy()
+	storeCopy.Status.SessionStatus = fornaxv1.SessionStatusEvacuating
+	status := evacuatedSessionStatus(storeCopy, evacuated)
+	if status.SessionStatus != fornaxv1.SessionStatusPending || string(status.CheckpointData) != "checkpoint" {
+		t.Errorf("expect evacuated session pending with checkpoint, got %v", status)
+	}
+	if status.PendingSince == nil {
+		t.Errorf("expect evacuated session record since when it is pending, got %v", status)
+	}
+	if status.PodReference != nil || len(status.AccessEndPoints) != 0 || len(status.ClientSessions) != 0 {
+		t.Errorf("expect evacuated session does not keep old pod, endpoints and clients, got %v", status)
+	}
+
+	// session requested to delete is closed instead of reopened
+	storeCopy.DeletionTimestamp = util.NewCurrentMetaTime()
+	status = evacuatedSessionStatus(storeCopy, evacuated)
+	if status.SessionStatus != fornaxv1.SessionStatusClosed || status.CloseTime == nil {
+		t.Errorf("expect deleting evacuated session closed, got %v", status)
+	}
+}
+
+// fakeNodeAgentClient remember sessions asked to close
+type fakeNodeAgentClient struct {
+	nodeagent.NodeAgentClient
+	closed []string
+}
+
+func (c *fakeNodeAgentClient) CloseSession(nodeId string, pod *v1.Pod, session *fornaxv1.ApplicationSession) error {
+	c.closed = append(c.closed, util.Name(session))
+	return nil
+}
+
+func TestResendCloseOfClosingSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &fakeNodeAgentClient{}
+	sm := NewSessionManager(ctx, client, storefactory.NewFornaxApplicationSessionStorage(ctx))
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "test",
+		Name:      "pod",
+		Labels:    map[string]string{fornaxv1.LabelFornaxCoreNode: "node/node1"},
+	}}
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session"},
+		Status: fornaxv1.ApplicationSessionStatus{
+			SessionStatus: fornaxv1.SessionStatusClosing,
+			PodReference:  &v1.LocalObjectReference{Name: "test/pod"},
+		},
+	}
+	if _, err := storefactory.CreateApplicationSession(ctx, sm.sessionStore, session); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+
+	// node still report session available after fornaxcore failed over, close is sent again and store keep closing status
+	reported := session.DeepCopy()
+	reported.Status.SessionStatus = fornaxv1.SessionStatusAvailable
+	if err := sm.OnSessionStatusFromNode("node/node1", pod, reported); err != nil {
+		t.Fatalf("failed to handle session status, %v", err)
+	}
+	if len(client.closed) != 1 || client.closed[0] != "test/session" {
+		t.Errorf("expect session close resent to node, got %v", client.closed)
+	}
+	storeCopy, _ := storefactory.GetApplicationSessionCache(sm.sessionStore, "test/session")
+	if !util.SessionIsClosing(storeCopy) {
+		t.Errorf("expect session still closing in store, got %s", storeCopy.Status.SessionStatus)
+	}
+
+	// node is closing session, no more close is sent
+	reported.Status.SessionStatus = fornaxv1.SessionStatusClosing
+	if err := sm.OnSessionStatusFromNode("node/node1", pod, reported); err != nil {
+		t.Fatalf("failed to handle session status, %v", err)
+	}
+	if len(client.closed) != 1 {
+		t.Errorf("expect no more close sent to a closing session, got %v", client.closed)
+	}
+}
+
+func TestSessionStatusTransitionTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sm := NewSessionManager(ctx, &fakeNodeAgentClient{}, storefactory.NewFornaxApplicationSessionStorage(ctx))
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "transition", CreationTimestamp: metav1.NewTime(time.Now().Add(-1 * time.Hour))},
+		Status:     fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusPending},
+	}
+	if _, err := storefactory.CreateApplicationSession(ctx, sm.sessionStore, session); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+
+	// session which never changed status is in its status since creation
+	storeCopy, _ := storefactory.GetApplicationSessionCache(sm.sessionStore, "test/transition")
+	if !sessionStatusSince(storeCopy).Equal(storeCopy.CreationTimestamp.Time) {
+		t.Errorf("expect pending session in status since creation, got %v", sessionStatusSince(storeCopy))
+	}
+
+	// status change record transition time
+	if err := sm.UpdateSessionStatus(session, &fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusAvailable}); err != nil {
+		t.Fatalf("failed to update session status, %v", err)
+	}
+	storeCopy, _ = storefactory.GetApplicationSessionCache(sm.sessionStore, "test/transition")
+	transitionTime := storeCopy.Status.LastTransitionTime
+	if transitionTime == nil || time.Since(transitionTime.Time) > time.Minute {
+		t.Fatalf("expect session record its transition time, got %v", transitionTime)
+	}
+
+	// status reported without transition time keep last transition time
+	if err := sm.UpdateSessionStatus(session, &fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusAvailable, ClientSessions: []v1.LocalObjectReference{{Name: "client"}}}); err != nil {
+		t.Fatalf("failed to update session status, %v", err)
+	}
+	storeCopy, _ = storefactory.GetApplicationSessionCache(sm.sessionStore, "test/transition")
+	if storeCopy.Status.LastTransitionTime == nil || !storeCopy.Status.LastTransitionTime.Equal(transitionTime) {
+		t.Errorf("expect session keep last transition time %v, got %v", transitionTime, storeCopy.Status.LastTransitionTime)
+	}
+	if !sessionStatusSince(storeCopy).Equal(transitionTime.Time) {
+		t.Errorf("expect available session in status since %v, got %v", transitionTime, sessionStatusSince(storeCopy))
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressgateway
+
+import (
+	"context"
+	"reflect"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxclient "centaurusinfra.io/fornax-serverless/pkg/client/clientset/versioned"
+	"centaurusinfra.io/fornax-serverless/pkg/client/informers/externalversions"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// only recent actions are kept in IngressEndpoint history
+	MaxIngressEndpointHistory = 10
+)
+
+// IngressGateway watch IngressEndpoints of this gateway address and program forwarding with a proxier
+type IngressGateway struct {
+	ctx            context.Context
+	gatewayAddress string
+	client         fornaxclient.Interface
+	proxier        Proxier
+}
+
+func NewIngressGateway(ctx context.Context, gatewayAddress string, client fornaxclient.Interface, proxier Proxier) *IngressGateway {
+	return &IngressGateway{
+		ctx:            ctx,
+		gatewayAddress: gatewayAddress,
+		client:         client,
+		proxier:        proxier,
+	}
+}
+
+// Run start IngressEndpoint informer and wait for it synced, forwarding is programmed in informer callbacks
+func (g *IngressGateway) Run() {
+	informerFactory := externalversions.NewSharedInformerFactory(g.client, 0)
+	ingressInformer := informerFactory.Core().V1().IngressEndpoints()
+	ingressInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    g.onIngressEndpointAddEvent,
+		UpdateFunc: g.onIngressEndpointUpdateEvent,
+		DeleteFunc: g.onIngressEndpointDeleteEvent,
+	})
+	informerFactory.Start(g.ctx.Done())
+	cache.WaitForNamedCacheSync(fornaxv1.IngressEndpointKind.Kind, g.ctx.Done(), ingressInformer.Informer().HasSynced)
+	klog.InfoS("Ingress gateway started", "gatewayAddress", g.gatewayAddress)
+}
+
+func (g *IngressGateway) Stop() {
+	g.proxier.Stop()
+}
+
+func (g *IngressGateway) isLocalEndpoint(endpoint *fornaxv1.IngressEndpoint) bool {
+	return endpoint.Spec.IngressGWIPAddress == g.gatewayAddress
+}
+
+func (g *IngressGateway) onIngressEndpointAddEvent(obj interface{}) {
+	endpoint := obj.(*fornaxv1.IngressEndpoint)
+	if !g.isLocalEndpoint(endpoint) {
+		return
+	}
+	g.setupIngress(endpoint)
+}
+
+func (g *IngressGateway) onIngressEndpointUpdateEvent(old, cur interface{}) {
+	oldCopy := old.(*fornaxv1.IngressEndpoint)
+	newCopy := cur.(*fornaxv1.IngressEndpoint)
+	if reflect.DeepEqual(oldCopy.Spec, newCopy.Spec) {
+		return
+	}
+	if g.isLocalEndpoint(oldCopy) && ingressKeyOf(oldCopy) != ingressKeyOf(newCopy) {
+		g.teardownIngress(oldCopy)
+	}
+	if g.isLocalEndpoint(newCopy) {
+		g.setupIngress(newCopy)
+	} else if g.isLocalEndpoint(oldCopy) {
+		g.teardownIngress(oldCopy)
+	}
+}
+
+func (g *IngressGateway) onIngressEndpointDeleteEvent(obj interface{}) {
+	endpoint, ok := obj.(*fornaxv1.IngressEndpoint)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			return
+		}
+		if endpoint, ok = tombstone.Obj.(*fornaxv1.IngressEndpoint); !ok {
+			return
+		}
+	}
+	if !g.isLocalEndpoint(endpoint) {
+		return
+	}
+	g.teardownIngress(endpoint)
+}
+
+func (g *IngressGateway) setupIngress(endpoint *fornaxv1.IngressEndpoint) {
+	if err := g.proxier.SetupIngress(endpoint); err != nil {
+		klog.ErrorS(err, "Failed to setup ingress", "endpoint", util.Name(endpoint))
+		g.recordAction(endpoint, fornaxv1.SetupIngressRule, fornaxv1.Unavailable, "SetupFailed", err.Error())
+		return
+	}
+	g.recordAction(endpoint, fornaxv1.SetupIngressRule, fornaxv1.InUse, "Setup", "")
+}
+
+func (g *IngressGateway) teardownIngress(endpoint *fornaxv1.IngressEndpoint) {
+	if err := g.proxier.TeardownIngress(endpoint); err != nil {
+		klog.ErrorS(err, "Failed to teardown ingress", "endpoint", util.Name(endpoint))
+	}
+}
+
+// recordAction append action into IngressEndpoint status history, endpoint deleted already is ignored
+func (g *IngressGateway) recordAction(endpoint *fornaxv1.IngressEndpoint, action fornaxv1.IngressEndpointAction, status fornaxv1.UsageStatus, reason, message string) {
+	updated := endpoint.DeepCopy()
+	updated.Status.ServiceStatus = status
+	updated.Status.History = append(updated.Status.History, fornaxv1.IngressEndpointHistory{
+		Action:     action,
+		UpdateTime: metav1.Now(),
+		Reason:     reason,
+		Message:    message,
+	})
+	if len(updated.Status.History) > MaxIngressEndpointHistory {
+		updated.Status.History = updated.Status.History[len(updated.Status.History)-MaxIngressEndpointHistory:]
+	}
+	_, err := g.client.CoreV1().IngressEndpoints(endpoint.Namespace).UpdateStatus(g.ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		klog.ErrorS(err, "Failed to update ingress endpoint status", "endpoint", util.Name(endpoint))
+	}
+}
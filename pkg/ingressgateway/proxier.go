@@ -0,0 +1,60 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressgateway
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+)
+
+// Proxier program forwarding from ingress port to destinations of a IngressEndpoint,
+// setup is called again when destinations of IngressEndpoint change
+type Proxier interface {
+	SetupIngress(endpoint *fornaxv1.IngressEndpoint) error
+	TeardownIngress(endpoint *fornaxv1.IngressEndpoint) error
+	Stop()
+}
+
+// ingressKey identify a forwarding rule, it's protocol and ingress port
+type ingressKey struct {
+	protocol string
+	port     int
+}
+
+func (k ingressKey) String() string {
+	return fmt.Sprintf("%s/%d", k.protocol, k.port)
+}
+
+func ingressKeyOf(endpoint *fornaxv1.IngressEndpoint) ingressKey {
+	protocol := strings.ToUpper(endpoint.Spec.Protocol)
+	if len(protocol) == 0 {
+		protocol = "TCP"
+	}
+	return ingressKey{protocol: protocol, port: endpoint.Spec.IngressPort}
+}
+
+func destinationAddresses(endpoint *fornaxv1.IngressEndpoint) []string {
+	addresses := []string{}
+	for _, d := range endpoint.Spec.Destinations {
+		addresses = append(addresses, net.JoinHostPort(d.IpAddress, strconv.Itoa(d.Port)))
+	}
+	return addresses
+}
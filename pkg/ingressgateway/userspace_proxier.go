@@ -0,0 +1,343 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressgateway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultDialTimeout       = 5 * time.Second
+	DefaultUDPIdleTimeout    = 60 * time.Second
+	DefaultUDPMaxPacketBytes = 64 * 1024
+)
+
+var _ Proxier = &userspaceProxier{}
+
+// userspaceProxier listen on ingress ports and copy traffic to destinations in user space,
+// tcp connections and udp clients are assigned to destinations in round robin
+type userspaceProxier struct {
+	mu          sync.Mutex
+	bindAddress string
+	sockets     map[ingressKey]proxySocket
+}
+
+func NewUserspaceProxier(bindAddress string) *userspaceProxier {
+	return &userspaceProxier{
+		bindAddress: bindAddress,
+		sockets:     map[ingressKey]proxySocket{},
+	}
+}
+
+type proxySocket interface {
+	setDestinations(destinations []string)
+	close()
+}
+
+// SetupIngress start to listen ingress port if it's not listened yet, and update its destinations
+func (p *userspaceProxier) SetupIngress(endpoint *fornaxv1.IngressEndpoint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := ingressKeyOf(endpoint)
+	destinations := destinationAddresses(endpoint)
+	if socket, found := p.sockets[key]; found {
+		socket.setDestinations(destinations)
+		return nil
+	}
+
+	address := net.JoinHostPort(p.bindAddress, strconv.Itoa(key.port))
+	var socket proxySocket
+	switch key.protocol {
+	case "TCP":
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		socket = newTCPProxySocket(listener, destinations)
+	case "UDP":
+		conn, err := net.ListenPacket("udp", address)
+		if err != nil {
+			return err
+		}
+		socket = newUDPProxySocket(conn, destinations)
+	default:
+		return fmt.Errorf("unsupported ingress protocol %s", key.protocol)
+	}
+	klog.InfoS("Setup ingress", "ingress", key, "destinations", destinations)
+	p.sockets[key] = socket
+	return nil
+}
+
+// TeardownIngress stop listening ingress port, established tcp connections are closed
+func (p *userspaceProxier) TeardownIngress(endpoint *fornaxv1.IngressEndpoint) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := ingressKeyOf(endpoint)
+	if socket, found := p.sockets[key]; found {
+		klog.InfoS("Teardown ingress", "ingress", key)
+		socket.close()
+		delete(p.sockets, key)
+	}
+	return nil
+}
+
+func (p *userspaceProxier) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, socket := range p.sockets {
+		socket.close()
+		delete(p.sockets, key)
+	}
+}
+
+// roundRobin pick next destination
+type roundRobin struct {
+	mu           sync.Mutex
+	destinations []string
+	next         int
+}
+
+func (r *roundRobin) setDestinations(destinations []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.destinations = destinations
+}
+
+func (r *roundRobin) pick() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.destinations) == 0 {
+		return "", errors.New("ingress has no destination")
+	}
+	destination := r.destinations[r.next%len(r.destinations)]
+	r.next += 1
+	return destination, nil
+}
+
+type tcpProxySocket struct {
+	roundRobin
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	closed   bool
+}
+
+func newTCPProxySocket(listener net.Listener, destinations []string) *tcpProxySocket {
+	s := &tcpProxySocket{
+		roundRobin: roundRobin{destinations: destinations},
+		listener:   listener,
+		conns:      map[net.Conn]bool{},
+	}
+	go s.serve()
+	return s
+}
+
+func (s *tcpProxySocket) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !s.isClosed() {
+				klog.ErrorS(err, "Failed to accept ingress connection", "address", s.listener.Addr())
+			}
+			return
+		}
+		go s.proxy(conn)
+	}
+}
+
+func (s *tcpProxySocket) proxy(conn net.Conn) {
+	defer conn.Close()
+	destination, err := s.pick()
+	if err != nil {
+		klog.ErrorS(err, "Drop ingress connection", "address", s.listener.Addr())
+		return
+	}
+	backend, err := net.DialTimeout("tcp", destination, DefaultDialTimeout)
+	if err != nil {
+		klog.ErrorS(err, "Failed to connect ingress destination", "destination", destination)
+		return
+	}
+	defer backend.Close()
+	if !s.track(conn, backend) {
+		return
+	}
+	defer s.untrack(conn, backend)
+
+	done := make(chan struct{}, 2)
+	copyStream := func(dst, src net.Conn) {
+		io.Copy(dst, src)
+		// half close so other direction can finish sending
+		if tcpConn, ok := dst.(*net.TCPConn); ok {
+			tcpConn.CloseWrite()
+		}
+		done <- struct{}{}
+	}
+	go copyStream(backend, conn)
+	go copyStream(conn, backend)
+	<-done
+	<-done
+}
+
+func (s *tcpProxySocket) track(conns ...net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	for _, c := range conns {
+		s.conns[c] = true
+	}
+	return true
+}
+
+func (s *tcpProxySocket) untrack(conns ...net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range conns {
+		delete(s.conns, c)
+	}
+}
+
+func (s *tcpProxySocket) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *tcpProxySocket) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.listener.Close()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.conns = map[net.Conn]bool{}
+}
+
+// udpProxySocket keep a backend connection per client address, and copy backend replies back to client,
+// backend connection is closed if client is idle longer than DefaultUDPIdleTimeout
+type udpProxySocket struct {
+	roundRobin
+	conn    net.PacketConn
+	mu      sync.Mutex
+	clients map[string]net.Conn
+	closed  bool
+}
+
+func newUDPProxySocket(conn net.PacketConn, destinations []string) *udpProxySocket {
+	s := &udpProxySocket{
+		roundRobin: roundRobin{destinations: destinations},
+		conn:       conn,
+		clients:    map[string]net.Conn{},
+	}
+	go s.serve()
+	return s
+}
+
+func (s *udpProxySocket) serve() {
+	buf := make([]byte, DefaultUDPMaxPacketBytes)
+	for {
+		n, clientAddr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if !s.isClosed() {
+				klog.ErrorS(err, "Failed to read ingress packet", "address", s.conn.LocalAddr())
+			}
+			return
+		}
+		backend, err := s.clientBackend(clientAddr)
+		if err != nil {
+			klog.ErrorS(err, "Drop ingress packet", "address", s.conn.LocalAddr(), "client", clientAddr)
+			continue
+		}
+		backend.SetDeadline(time.Now().Add(DefaultUDPIdleTimeout))
+		if _, err := backend.Write(buf[:n]); err != nil {
+			klog.ErrorS(err, "Failed to forward ingress packet", "destination", backend.RemoteAddr())
+		}
+	}
+}
+
+func (s *udpProxySocket) clientBackend(clientAddr net.Addr) (net.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, net.ErrClosed
+	}
+	if backend, found := s.clients[clientAddr.String()]; found {
+		return backend, nil
+	}
+	destination, err := s.pick()
+	if err != nil {
+		return nil, err
+	}
+	backend, err := net.DialTimeout("udp", destination, DefaultDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	s.clients[clientAddr.String()] = backend
+	go s.reply(clientAddr, backend)
+	return backend, nil
+}
+
+// reply copy packets from backend to client until backend is idle or closed
+func (s *udpProxySocket) reply(clientAddr net.Addr, backend net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		backend.Close()
+		if s.clients[clientAddr.String()] == backend {
+			delete(s.clients, clientAddr.String())
+		}
+	}()
+	buf := make([]byte, DefaultUDPMaxPacketBytes)
+	for {
+		backend.SetDeadline(time.Now().Add(DefaultUDPIdleTimeout))
+		n, err := backend.Read(buf)
+		if err != nil {
+			return
+		}
+		if _, err := s.conn.WriteTo(buf[:n], clientAddr); err != nil {
+			return
+		}
+	}
+}
+
+func (s *udpProxySocket) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *udpProxySocket) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.conn.Close()
+	for _, backend := range s.clients {
+		backend.Close()
+	}
+	s.clients = map[string]net.Conn{}
+}
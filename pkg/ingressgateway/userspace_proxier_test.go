@@ -0,0 +1,159 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingressgateway
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+)
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// startTCPServer reply each line with prefix
+func startTCPServer(t *testing.T, prefix string) *net.TCPAddr {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				line, _ := bufio.NewReader(conn).ReadString('\n')
+				conn.Write([]byte(prefix + line))
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr)
+}
+
+func testEndpoint(protocol string, port int, destinations ...*net.TCPAddr) *fornaxv1.IngressEndpoint {
+	endpoint := &fornaxv1.IngressEndpoint{
+		Spec: fornaxv1.IngressEndpointSpec{
+			Protocol:           protocol,
+			IngressGWIPAddress: "127.0.0.1",
+			IngressPort:        port,
+		},
+	}
+	for _, d := range destinations {
+		endpoint.Spec.Destinations = append(endpoint.Spec.Destinations, fornaxv1.Destination{IpAddress: d.IP.String(), Port: d.Port})
+	}
+	return endpoint
+}
+
+func tcpRequest(address string) (string, error) {
+	conn, err := net.DialTimeout("tcp", address, time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		return "", err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return strings.TrimSpace(reply), err
+}
+
+func TestUserspaceProxierTCP(t *testing.T) {
+	p := NewUserspaceProxier("127.0.0.1")
+	defer p.Stop()
+	port := freePort(t)
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	endpoint := testEndpoint("TCP", port, startTCPServer(t, "a:"))
+	if err := p.SetupIngress(endpoint); err != nil {
+		t.Fatalf("failed to setup ingress, %v", err)
+	}
+	if reply, err := tcpRequest(address); err != nil || reply != "a:hello" {
+		t.Errorf("expect reply from destination a, got %q, %v", reply, err)
+	}
+
+	// destination change when session is reopened on another pod
+	endpoint = testEndpoint("TCP", port, startTCPServer(t, "b:"))
+	if err := p.SetupIngress(endpoint); err != nil {
+		t.Fatalf("failed to update ingress, %v", err)
+	}
+	if reply, err := tcpRequest(address); err != nil || reply != "b:hello" {
+		t.Errorf("expect reply from destination b, got %q, %v", reply, err)
+	}
+
+	if err := p.TeardownIngress(endpoint); err != nil {
+		t.Fatalf("failed to teardown ingress, %v", err)
+	}
+	if _, err := tcpRequest(address); err == nil {
+		t.Errorf("expect ingress port is closed after teardown")
+	}
+}
+
+func TestUserspaceProxierUDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := server.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			server.WriteTo(append([]byte("echo:"), buf[:n]...), addr)
+		}
+	}()
+
+	p := NewUserspaceProxier("127.0.0.1")
+	defer p.Stop()
+	port := freePort(t)
+	serverAddr := server.LocalAddr().(*net.UDPAddr)
+	endpoint := testEndpoint("UDP", port, &net.TCPAddr{IP: serverAddr.IP, Port: serverAddr.Port})
+	if err := p.SetupIngress(endpoint); err != nil {
+		t.Fatalf("failed to setup ingress, %v", err)
+	}
+
+	conn, err := net.Dial("udp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	conn.Write([]byte("hello"))
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "echo:hello" {
+		t.Errorf("expect udp reply from destination, got %q, %v", string(buf[:n]), err)
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/network"
@@ -113,6 +115,11 @@ type NodeConfiguration struct {
 	NodePortStartingNo       int32
 	SessionServicePort       int32
 	PodConcurrency           int
+	NodeLabels               map[string]string
+	NodeTaints               []string // key=value:Effect, used by fornaxcore scheduler to pick node
+	AllowedHostPaths         []string // host path prefixes which pod hostPath volumes are allowed to mount
+	FornaxCoreCAFile         string   // ca verifying fornaxcore certificate, fornaxcore is connected with mutual tls if it's provided
+	BootstrapTokenFile       string   // token used to enroll node and get node certificate from fornaxcore
 }
 
 func DefaultNodeConfiguration() (*NodeConfiguration, error) {
@@ -166,6 +173,11 @@ func DefaultNodeConfiguration() (*NodeConfiguration, error) {
 		EnforceNodeAllocatable:   map[string]sets.Empty{},
 		NodeAgentReserved:        map[v1.ResourceName]resource.Quantity{},
 		SystemReserved:           map[v1.ResourceName]resource.Quantity{},
+		NodeLabels:               map[string]string{},
+		NodeTaints:               []string{},
+		AllowedHostPaths:         []string{},
+		FornaxCoreCAFile:         "",
+		BootstrapTokenFile:       "",
 	}, nil
 }
 
@@ -198,9 +210,47 @@ func ValidateNodeConfiguration(nodeConfig NodeConfiguration) []error {
 		}
 	}
 
+	if _, err = ParseNodeTaints(nodeConfig.NodeTaints); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(nodeConfig.BootstrapTokenFile) > 0 && len(nodeConfig.FornaxCoreCAFile) == 0 {
+		errs = append(errs, errors.New("fornaxcore ca file is required to enroll node with bootstrap token"))
+	}
+
+	for _, v := range nodeConfig.AllowedHostPaths {
+		if !filepath.IsAbs(v) {
+			errs = append(errs, fmt.Errorf("allowed host path %s is not a absolute path", v))
+		}
+	}
+
 	return errs
 }
 
+// ParseNodeTaints parse taints in format of key=value:Effect or key:Effect
+func ParseNodeTaints(taints []string) ([]v1.Taint, error) {
+	nodeTaints := []v1.Taint{}
+	for _, v := range taints {
+		parts := strings.Split(v, ":")
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, fmt.Errorf("invalid taint %s, expect format key=value:Effect", v)
+		}
+		effect := v1.TaintEffect(parts[1])
+		if effect != v1.TaintEffectNoSchedule && effect != v1.TaintEffectPreferNoSchedule && effect != v1.TaintEffectNoExecute {
+			return nil, fmt.Errorf("invalid taint effect %s in taint %s", parts[1], v)
+		}
+		taint := v1.Taint{Effect: effect}
+		if kv := strings.SplitN(parts[0], "=", 2); len(kv) == 2 {
+			taint.Key = kv[0]
+			taint.Value = kv[1]
+		} else {
+			taint.Key = kv[0]
+		}
+		nodeTaints = append(nodeTaints, taint)
+	}
+	return nodeTaints, nil
+}
+
 func AddConfigFlags(flagSet *pflag.FlagSet, nodeConfig *NodeConfiguration) {
 	flagSet.BoolVar(&nodeConfig.DisableSwap, "disable-swap", nodeConfig.DisableSwap, "should disable swap, fail when host swap is on")
 
@@ -211,4 +261,86 @@ func AddConfigFlags(flagSet *pflag.FlagSet, nodeConfig *NodeConfiguration) {
 	flagSet.StringArrayVar(&nodeConfig.FornaxCoreUrls, "fornaxcore-url", nodeConfig.FornaxCoreUrls, "addresses of the fornaxcores, format is ip:port. must provided")
 
 	flagSet.StringVar(&nodeConfig.RuntimeHandler, "runtime-handler", nodeConfig.RuntimeHandler, "container runtime handler name, check /etc/docker/daemon.json for valid name")
+
+	flagSet.StringToStringVar(&nodeConfig.NodeLabels, "node-labels", nodeConfig.NodeLabels, "labels to add when registering node, format is key1=value1,key2=value2")
+
+	flagSet.StringArrayVar(&nodeConfig.NodeTaints, "register-with-taints", nodeConfig.NodeTaints, "taints to add when registering node, format is key=value:Effect")
+
+	flagSet.StringVar(&nodeConfig.FornaxCoreCAFile, "fornaxcore-ca-file", nodeConfig.FornaxCoreCAFile, "ca file to verify fornaxcore certificate, node connect fornaxcore with mutual tls if it's provided, otherwise insecurely")
+
+	flagSet.StringVar(&nodeConfig.BootstrapTokenFile, "bootstrap-token-file", nodeConfig.BootstrapTokenFile, "file of bootstrap token used to enroll node and get a node certificate when node does not have a valid one")
+
+	flagSet.StringArrayVar(&nodeConfig.AllowedHostPaths, "allowed-host-paths", nodeConfig.AllowedHostPaths, "host path prefixes which pod hostPath volumes are allowed to mount, hostPath volume is rejected if it is not provided")
+
+	flagSet.Var(&resourceListValue{&nodeConfig.SystemReserved}, "system-reserved", "resources reserved for system and not allocatable to pods, format is cpu=1000,memory=1Gi,storage=10Gi, cpu is counted as milli cores")
+
+	flagSet.Var(&resourceListValue{&nodeConfig.NodeAgentReserved}, "node-agent-reserved", "resources reserved for node agent and not allocatable to pods, format is cpu=500,memory=500Mi, cpu is counted as milli cores")
+
+	flagSet.Var(&cpuSetValue{&nodeConfig.ReservedSystemCPUs}, "reserved-cpus", "cpus reserved for system and node agent, format is 0-1 or 0,2, they are not allocatable to pods if more than reserved cpu quantity")
+}
+
+// resourceListValue parse a resource list flag, format is name1=quantity1,name2=quantity2
+type resourceListValue struct {
+	resources *v1.ResourceList
+}
+
+func (v *resourceListValue) String() string {
+	if v.resources == nil {
+		return ""
+	}
+	pairs := []string{}
+	for name, quantity := range *v.resources {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *resourceListValue) Set(value string) error {
+	resources := v1.ResourceList{}
+	for _, pair := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(pair)) == 0 {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid resource %s, format is name=quantity", pair)
+		}
+		quantity, err := resource.ParseQuantity(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("invalid quantity of resource %s, %v", kv[0], err)
+		}
+		resources[v1.ResourceName(strings.TrimSpace(kv[0]))] = quantity
+	}
+	*v.resources = resources
+	return nil
+}
+
+func (v *resourceListValue) Type() string {
+	return "resourceList"
+}
+
+// cpuSetValue parse a cpu set flag, format is 0-3 or 0,2
+type cpuSetValue struct {
+	cpus *cpuset.CPUSet
+}
+
+func (v *cpuSetValue) String() string {
+	if v.cpus == nil {
+		return ""
+	}
+	return v.cpus.String()
+}
+
+func (v *cpuSetValue) Set(value string) error {
+	cpus, err := cpuset.Parse(value)
+	if err != nil {
+		return err
+	}
+	*v.cpus = cpus
+	return nil
+}
+
+func (v *cpuSetValue) Type() string {
+	return "cpuSet"
 }
@@ -17,6 +17,7 @@ package dependency
 
 import (
 	"context"
+	"path/filepath"
 
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/cadvisor"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
@@ -28,8 +29,11 @@ import (
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/sessionservice"
 	sessionserver "centaurusinfra.io/fornax-serverless/pkg/nodeagent/sessionservice/grpc"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/store"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/volume"
 	"centaurusinfra.io/fornax-serverless/pkg/store/storage/sqlite"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/klog/v2"
 	kubeletcm "k8s.io/kubernetes/pkg/kubelet/cm"
@@ -43,12 +47,15 @@ type Dependencies struct {
 	RuntimeService  runtime.RuntimeService
 	QosManager      qos.QoSManager
 	ImageManager    images.ImageManager
-	MemoryManager   resourcemanager.MemoryManager
-	CPUManager      resourcemanager.CPUManager
-	VolumeManager   resourcemanager.VolumeManager
+	MemoryManager   *resourcemanager.MemoryManager
+	CPUManager      *resourcemanager.CPUManager
+	VolumeManager   *resourcemanager.VolumeManager
+	PodVolumes      *volume.PodVolumeManager
 	NodeStore       *store.NodeStore
 	PodStore        *store.PodStore
 	SessionService  sessionservice.SessionService
+	PodTokens       *sessionservice.PodTokenAuthenticator
+	EventRecorder   record.EventRecorder
 }
 
 func InitBasicDependencies(ctx context.Context, nodeConfig config.NodeConfiguration) (*Dependencies, error) {
@@ -57,11 +64,12 @@ func InitBasicDependencies(ctx context.Context, nodeConfig config.NodeConfigurat
 		CAdvisor:        nil,
 		RuntimeService:  nil,
 		QosManager:      nil,
-		MemoryManager:   resourcemanager.MemoryManager{},
-		CPUManager:      resourcemanager.CPUManager{},
-		VolumeManager:   resourcemanager.VolumeManager{},
+		MemoryManager:   resourcemanager.NewMemoryManager(nodeConfig),
+		CPUManager:      resourcemanager.NewCpuManager(nodeConfig),
+		VolumeManager:   resourcemanager.NewVolumeManager(nodeConfig),
 		PodStore:        &store.PodStore{},
 		NodeStore:       &store.NodeStore{},
+		EventRecorder:   util.NewNoopEventRecorder(),
 	}
 
 	// SqliteStore
@@ -93,8 +101,23 @@ func InitBasicDependencies(ctx context.Context, nodeConfig config.NodeConfigurat
 		return nil, err
 	}
 
+	// PodVolumes
+	dependencies.PodVolumes = volume.NewPodVolumeManager(nodeConfig.RootPath, nodeConfig.AllowedHostPaths, mount.New(nodeConfig.MounterPath))
+
+	// PodTokens
+	dependencies.PodTokens, err = sessionservice.NewPodTokenAuthenticator(filepath.Join(nodeConfig.RootPath, "sessionservice", "token.key"))
+	if err != nil {
+		klog.ErrorS(err, "failed to init pod token authenticator")
+		return nil, err
+	}
+
 	// SessionService
-	sessionService := sessionserver.NewSessionService()
+	sessionService := sessionserver.NewSessionService(dependencies.PodTokens, func(podId string) string {
+		if pod, err := dependencies.PodStore.GetPod(podId); err == nil && pod != nil && pod.Pod != nil {
+			return string(pod.Pod.UID)
+		}
+		return ""
+	})
 	err = sessionService.Run(ctx, nodeConfig.SessionServicePort)
 	if err != nil {
 		return nil, err
@@ -204,9 +227,15 @@ func (n *Dependencies) Complete(node *v1.Node, nodeConfig config.NodeConfigurati
 		}
 	}
 
-	// TODO
-	// MemoryManager   resourcemanager.MemoryManager
-	// CPUManager      resourcemanager.CPUManager
-	// VolumeManager   resourcemanager.VolumeManager
+	// resource managers
+	if n.MemoryManager == nil {
+		n.MemoryManager = resourcemanager.NewMemoryManager(nodeConfig)
+	}
+	if n.CPUManager == nil {
+		n.CPUManager = resourcemanager.NewCpuManager(nodeConfig)
+	}
+	if n.VolumeManager == nil {
+		n.VolumeManager = resourcemanager.NewVolumeManager(nodeConfig)
+	}
 	return nil
 }
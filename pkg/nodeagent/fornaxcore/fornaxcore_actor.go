@@ -19,6 +19,8 @@ package fornaxcore
 import (
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	fornax "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
@@ -34,12 +36,17 @@ import (
 type FornaxCoreActor struct {
 	nodeIP        string
 	nodeName      string
-	stop          bool
+	stop          int32
 	innerActor    message.Actor
+	mu            sync.RWMutex
 	fornaxcores   map[string]FornaxCoreClient
 	fornaxChannel chan *fornax.FornaxCoreMessage
 	nodeActor     message.ActorRef
 	messageSeq    int64
+	// node client certificate to connect fornaxcores, it's nil if fornaxcores are connected insecurely
+	credentials *NodeCredentials
+	// dial fornaxcores with default tcp dialer if it's nil
+	dialer ContextDialer
 }
 
 func (n *FornaxCoreActor) Start(nodeActor message.ActorRef) error {
@@ -50,16 +57,19 @@ func (n *FornaxCoreActor) Start(nodeActor message.ActorRef) error {
 	n.innerActor.Start()
 
 	// listen to fornax grpc message
+	n.mu.RLock()
 	for _, v := range n.fornaxcores {
-		if err := v.GetMessage(fmt.Sprintf("FornaxCoreActor@%s", n.nodeName), n.fornaxChannel); err != nil {
+		if err := n.startFornaxCoreClient(v); err != nil {
+			n.mu.RUnlock()
 			return err
 		}
 	}
+	n.mu.RUnlock()
 
 	// process fornax grpc message in a go routine
 	go func() {
 		for {
-			if n.stop {
+			if atomic.LoadInt32(&n.stop) == 1 {
 				close(n.fornaxChannel)
 				klog.InfoS("Fornaxcore actor exit")
 				break
@@ -90,13 +100,23 @@ func (n *FornaxCoreActor) Start(nodeActor message.ActorRef) error {
 	return nil
 }
 
+func (n *FornaxCoreActor) startFornaxCoreClient(client FornaxCoreClient) error {
+	if err := client.GetMessage(fmt.Sprintf("FornaxCoreActor@%s", n.nodeName), n.fornaxChannel); err != nil {
+		return err
+	}
+	client.Start()
+	return nil
+}
+
 func (n *FornaxCoreActor) notify(receiver message.ActorRef, msg interface{}) error {
 	return message.Send(n.innerActor.Reference(), receiver, msg)
 }
 
 func (n *FornaxCoreActor) Stop() error {
-	n.stop = true
+	atomic.StoreInt32(&n.stop, 1)
 	n.innerActor.Stop()
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	for _, v := range n.fornaxcores {
 		v.Stop()
 	}
@@ -105,9 +125,12 @@ func (n *FornaxCoreActor) Stop() error {
 
 // when fornaxcore actor received another actor's message, it meant to send to fornaxcore a grpc message
 // do not return error as it works as proxy, node/pod/session actors are supposed to resend new state
+// message is sent to all fornaxcores, standby fornaxcores drop it until they become primary
 func (n *FornaxCoreActor) actorMessageProcess(msg message.ActorMessage) (interface{}, error) {
 	n.messageSeq += 1
 	messageSeq := fmt.Sprintf("%d", n.messageSeq)
+	n.mu.RLock()
+	defer n.mu.RUnlock()
 	for _, v := range n.fornaxcores {
 		msgBody := msg.Body.(*fornax.FornaxCoreMessage)
 		msgBody.MessageIdentifier = messageSeq
@@ -122,46 +145,51 @@ func (n *FornaxCoreActor) actorMessageProcess(msg message.ActorMessage) (interfa
 	return nil, nil
 }
 
-// fornaxcore configuration tell node if fornaxcore has any change, currently only handle fornaxcore join and leave
-// and setup connection with new fornaxcore and disconnect from old one
+// fornaxcore configuration tell node which fornaxcore is primary and which are standbys,
+// node setup connection with new fornaxcores and disconnect from fornaxcores which are not in configuration anymore,
+// node is asked to full sync by new primary after failover, so connection with standbys are kept to avoid reconnect
 func (n *FornaxCoreActor) onFornaxCoreConfigurationCommand(msg *fornax.FornaxCoreConfiguration) error {
-	// reinitialize fornaxcore clients according configuration
-	newips := []string{}
-	newipset := map[string]bool{}
 	primaryIp := msg.GetPrimary().GetIp()
 	if len(primaryIp) == 0 {
 		return errors.New("primary ip in fornax core configuration is nil")
 	}
-	_, found := n.fornaxcores[primaryIp]
-	if !found {
-		newips = append(newips, primaryIp)
-		newipset[primaryIp] = true
-	}
+	klog.InfoS("Received fornaxcore configuration", "primary", primaryIp, "standbys", msg.GetStandbys())
 
+	ipset := map[string]bool{primaryIp: true}
 	for _, v := range msg.GetStandbys() {
-		_, found := n.fornaxcores[v.GetIp()]
-		if !found {
-			newips = append(newips, v.GetIp())
-			newipset[v.GetIp()] = true
+		if len(v.GetIp()) > 0 {
+			ipset[v.GetIp()] = true
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	newips := []string{}
+	for ip := range ipset {
+		if _, found := n.fornaxcores[ip]; !found {
+			newips = append(newips, ip)
 		}
 	}
 
 	oldfornaxcores := map[string]FornaxCoreClient{}
 	for k, v := range n.fornaxcores {
-		_, found := newipset[k]
-		if !found {
-			// disappearing fornax core, mark it old and remove it from fornaxcores and close connection to it
+		if _, found := ipset[k]; !found {
+			// disappearing fornax core, remove it from fornaxcores and close connection to it
 			oldfornaxcores[k] = v
 		}
 	}
 
-	newfornaxcores := InitFornaxCoreClients(n.nodeIP, n.nodeName, newips)
+	newfornaxcores := InitFornaxCoreClients(n.nodeIP, n.nodeName, newips, n.credentials, n.dialer)
 	for k, v := range newfornaxcores {
-		v.Start()
+		klog.InfoS("Connect to a new fornaxcore", "endpoint", k)
+		if err := n.startFornaxCoreClient(v); err != nil {
+			return err
+		}
 		n.fornaxcores[k] = v
 	}
 
 	for k, v := range oldfornaxcores {
+		klog.InfoS("Disconnect from a fornaxcore which left", "endpoint", k)
 		delete(n.fornaxcores, k)
 		v.Stop()
 	}
@@ -172,10 +200,13 @@ func (n *FornaxCoreActor) Reference() message.ActorRef {
 	return n.innerActor.Reference()
 }
 
-func InitFornaxCoreClients(nodeIp, nodeName string, fornaxCoreIps []string) map[string]FornaxCoreClient {
+func InitFornaxCoreClients(nodeIp, nodeName string, fornaxCoreIps []string, credentials *NodeCredentials, dialer ContextDialer) map[string]FornaxCoreClient {
 	configs := []*FornaxCoreConfiguration{}
 	for _, v := range fornaxCoreIps {
-		configs = append(configs, NewFornaxCoreConfiguration(v))
+		config := NewFornaxCoreConfiguration(v)
+		config.credentials = credentials
+		config.dialer = dialer
+		configs = append(configs, config)
 	}
 	fornaxcores := map[string]FornaxCoreClient{}
 	for _, v := range configs {
@@ -183,22 +214,24 @@ func InitFornaxCoreClients(nodeIp, nodeName string, fornaxCoreIps []string) map[
 			Ip:         nodeIp,
 			Identifier: nodeName,
 		}, v)
-		f.Start()
 		fornaxcores[v.endpoint] = f
 	}
 
 	return fornaxcores
 }
 
-func NewFornaxCoreActor(nodeIP, nodeName string, fornaxCoreIps []string) *FornaxCoreActor {
-	fornaxcores := InitFornaxCoreClients(nodeIP, nodeName, fornaxCoreIps)
+func NewFornaxCoreActor(nodeIP, nodeName string, fornaxCoreIps []string, credentials *NodeCredentials, dialer ContextDialer) *FornaxCoreActor {
+	fornaxcores := InitFornaxCoreClients(nodeIP, nodeName, fornaxCoreIps, credentials, dialer)
 	actor := &FornaxCoreActor{
 		nodeIP:        nodeIP,
 		nodeName:      nodeName,
-		stop:          false,
+		stop:          0,
+		mu:            sync.RWMutex{},
 		fornaxcores:   fornaxcores,
 		fornaxChannel: make(chan *fornax.FornaxCoreMessage, 30),
 		messageSeq:    time.Now().Unix() + 1, // use current epeco for starting message seq, so, it will be different everytime when nodeagent start
+		credentials:   credentials,
+		dialer:        dialer,
 	}
 
 	actor.innerActor = message.NewLocalChannelActor(nodeName, actor.actorMessageProcess)
@@ -20,7 +20,9 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	fornax "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
@@ -36,8 +38,13 @@ type FornaxCoreConfiguration struct {
 	connTimeout    time.Duration
 	callTimeout    time.Duration
 	maxRecvMsgSize int
+	credentials    *NodeCredentials
+	dialer         ContextDialer
 }
 
+// ContextDialer dial a fornaxcore endpoint, it replace default tcp dialer, e.g. in process fornaxcore in tests
+type ContextDialer func(ctx context.Context, endpoint string) (net.Conn, error)
+
 const (
 	DefaultConnTimeout    = 5 * time.Second
 	DefaultCallTimeout    = 5 * time.Second
@@ -63,7 +70,7 @@ type FornaxCoreClient interface {
 type fornaxCoreClient struct {
 	mu               sync.Mutex
 	identifier       *fornax.NodeIdentifier
-	done             bool
+	done             int32
 	config           *FornaxCoreConfiguration
 	conn             *grpc.ClientConn
 	service          fornax.FornaxCoreServiceClient
@@ -98,11 +105,28 @@ func (f *fornaxCoreClient) PutMessage(message *fornax.FornaxCoreMessage) error {
 }
 
 func (f *fornaxCoreClient) disconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return nil
+	}
 	return f.conn.Close()
 }
 
 func (f *fornaxCoreClient) connect() error {
 	connect := func() error {
+		transportOption := grpc.WithInsecure()
+		if f.config.credentials != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), f.config.connTimeout+f.config.callTimeout)
+			creds, err := f.config.credentials.TransportCredentials(ctx, f.config.endpoint, f.identifier)
+			cancel()
+			if err != nil {
+				klog.ErrorS(err, "Failed to get node credentials", "endpoint", f.config.endpoint)
+				return err
+			}
+			transportOption = grpc.WithTransportCredentials(creds)
+		}
+
 		ctx, cancel := context.WithTimeout(context.Background(), f.config.connTimeout)
 		defer cancel()
 
@@ -110,20 +134,25 @@ func (f *fornaxCoreClient) connect() error {
 			grpc_retry.WithBackoff(grpc_retry.BackoffLinear(100 * time.Millisecond)),
 			grpc_retry.WithCodes(codes.NotFound, codes.Aborted, codes.Unavailable, codes.DataLoss, codes.Unknown),
 		}
-		conn, err := grpc.DialContext(
-			ctx,
-			f.config.endpoint,
+		dialOpts := []grpc.DialOption{
 			grpc.WithBlock(),
-			grpc.WithInsecure(),
+			transportOption,
 			grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(f.config.maxRecvMsgSize)),
 			grpc.WithStreamInterceptor(grpc_retry.StreamClientInterceptor(opts...)),
 			grpc.WithUnaryInterceptor(grpc_retry.UnaryClientInterceptor(opts...)),
-		)
+		}
+		if f.config.dialer != nil {
+			dialOpts = append(dialOpts, grpc.WithContextDialer(f.config.dialer))
+		}
+		conn, err := grpc.DialContext(ctx, f.config.endpoint, dialOpts...)
 		if err != nil {
 			klog.ErrorS(err, "Connect fornaxCore failed", "endpoint", f.config.endpoint)
 			return err
 		}
 
+		// connection is used by PutMessage in other go routines
+		f.mu.Lock()
+		defer f.mu.Unlock()
 		f.conn = conn
 		f.service = fornax.NewFornaxCoreServiceClient(conn)
 		return nil
@@ -134,7 +163,10 @@ func (f *fornaxCoreClient) connect() error {
 }
 
 func (f *fornaxCoreClient) initGetMessageClient(ctx context.Context, identifier *fornax.NodeIdentifier) error {
-	if f.conn == nil {
+	f.mu.Lock()
+	conn := f.conn
+	f.mu.Unlock()
+	if conn == nil {
 		klog.InfoS("Connecting to FornaxCore", "endpoint", f.config.endpoint)
 		err := f.connect()
 		if err != nil {
@@ -142,7 +174,10 @@ func (f *fornaxCoreClient) initGetMessageClient(ctx context.Context, identifier
 		}
 	}
 	klog.InfoS("Init fornax core get message client", "endpoint", f.config.endpoint)
-	gclient, err := f.service.GetMessage(ctx, identifier)
+	f.mu.Lock()
+	service := f.service
+	f.mu.Unlock()
+	gclient, err := service.GetMessage(ctx, identifier)
 	if err != nil {
 		return err
 	}
@@ -155,7 +190,7 @@ func (f *fornaxCoreClient) initGetMessageClient(ctx context.Context, identifier
 func (f *fornaxCoreClient) recvMessage() {
 	klog.InfoS("Receiving message from FornaxCore", "endpoint", f.config.endpoint)
 	for {
-		if f.done {
+		if atomic.LoadInt32(&f.done) == 1 {
 			break
 		}
 
@@ -205,7 +240,7 @@ func (f *fornaxCoreClient) recvMessage() {
 
 // Stop disconnect from fornac core
 func (f *fornaxCoreClient) Stop() {
-	f.done = true
+	atomic.StoreInt32(&f.done, 1)
 	f.disconnect()
 }
 
@@ -220,7 +255,7 @@ func NewFornaxCoreClient(identifier *fornax.NodeIdentifier, config *FornaxCoreCo
 	f := &fornaxCoreClient{
 		mu:               sync.Mutex{},
 		identifier:       identifier,
-		done:             false,
+		done:             0,
 		config:           config,
 		conn:             nil,
 		service:          nil,
@@ -0,0 +1,194 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fornaxcore
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	fornax "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/klog/v2"
+)
+
+const (
+	NodeCertFileName = "node.crt"
+	NodeKeyFileName  = "node.key"
+	// node certificate is renewed by enrolling again when it's about to expire
+	NodeCertRenewBefore = 24 * time.Hour
+)
+
+// NodeCredentials is node client certificate used to connect fornaxcore with mutual tls,
+// node enroll with bootstrap token to get a certificate if it does not have a valid one,
+// certificate is saved in cert dir and reused after node agent restart
+type NodeCredentials struct {
+	mu                 sync.Mutex
+	caFile             string
+	certDir            string
+	bootstrapTokenFile string
+	cert               *tls.Certificate
+}
+
+// NewNodeCredentials return nil if fornaxcore ca is not provided, node connect fornaxcore insecurely
+func NewNodeCredentials(caFile, certDir, bootstrapTokenFile string) *NodeCredentials {
+	if len(caFile) == 0 {
+		return nil
+	}
+	return &NodeCredentials{
+		caFile:             caFile,
+		certDir:            certDir,
+		bootstrapTokenFile: bootstrapTokenFile,
+	}
+}
+
+func (c *NodeCredentials) rootCAs() (*x509.CertPool, error) {
+	caPEM, err := os.ReadFile(c.caFile)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in %s", c.caFile)
+	}
+	return roots, nil
+}
+
+// TransportCredentials return mutual tls credentials to connect fornaxcore endpoint, node is enrolled via this endpoint if needed
+func (c *NodeCredentials) TransportCredentials(ctx context.Context, endpoint string, identifier *fornax.NodeIdentifier) (credentials.TransportCredentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	roots, err := c.rootCAs()
+	if err != nil {
+		return nil, err
+	}
+	if !certificateIsValid(c.cert, identifier.GetIdentifier()) {
+		c.cert = c.loadCertificate(identifier.GetIdentifier())
+	}
+	if c.cert == nil {
+		if c.cert, err = c.enroll(ctx, endpoint, roots, identifier); err != nil {
+			return nil, err
+		}
+	}
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{*c.cert},
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+// loadCertificate return saved node certificate, it return nil if certificate does not exist, expire soon or is not issued to this node
+func (c *NodeCredentials) loadCertificate(nodeName string) *tls.Certificate {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(c.certDir, NodeCertFileName), filepath.Join(c.certDir, NodeKeyFileName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.ErrorS(err, "Failed to load node certificate, enroll node again", "dir", c.certDir)
+		}
+		return nil
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil
+	}
+	if !certificateIsValid(&cert, nodeName) {
+		klog.InfoS("Node certificate is expiring or not issued to this node, enroll node again", "node", nodeName, "subject", cert.Leaf.Subject.CommonName, "notAfter", cert.Leaf.NotAfter)
+		return nil
+	}
+	return &cert
+}
+
+func certificateIsValid(cert *tls.Certificate, nodeName string) bool {
+	return cert != nil && cert.Leaf != nil && cert.Leaf.Subject.CommonName == nodeName && time.Now().Add(NodeCertRenewBefore).Before(cert.Leaf.NotAfter)
+}
+
+// enroll send a certificate signing request with bootstrap token to fornaxcore, and save issued certificate,
+// enrollment connection verify fornaxcore certificate but does not present a client certificate
+func (c *NodeCredentials) enroll(ctx context.Context, endpoint string, roots *x509.CertPool, identifier *fornax.NodeIdentifier) (*tls.Certificate, error) {
+	if len(c.bootstrapTokenFile) == 0 {
+		return nil, errors.New("node does not have a valid certificate and bootstrap token is not provided")
+	}
+	token, err := os.ReadFile(c.bootstrapTokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	csrDER, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: identifier.GetIdentifier()},
+	}, key)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithBlock(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs:    roots,
+		MinVersion: tls.VersionTLS12,
+	})))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	klog.InfoS("Enroll node with bootstrap token", "endpoint", endpoint, "node", identifier.GetIdentifier())
+	nodeCert, err := fornax.NewFornaxCoreServiceClient(conn).EnrollNode(ctx, &fornax.NodeEnrollment{
+		NodeIdentifier:            identifier,
+		BootstrapToken:            strings.TrimSpace(string(token)),
+		CertificateSigningRequest: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDER}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	cert, err := tls.X509KeyPair(nodeCert.GetCertificate(), keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(c.certDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(c.certDir, NodeKeyFileName), keyPEM, 0600); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(c.certDir, NodeCertFileName), nodeCert.GetCertificate(), 0600); err != nil {
+		return nil, err
+	}
+	klog.InfoS("Node enrolled", "node", identifier.GetIdentifier(), "notAfter", cert.Leaf.NotAfter)
+	return &cert, nil
+}
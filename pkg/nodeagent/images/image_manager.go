@@ -29,7 +29,7 @@ import (
 )
 
 type ImageManager interface {
-	PullImageForContainer(container *v1.Container, podSandboxConfig *criv1.PodSandboxConfig) (*criv1.Image, error)
+	PullImageForContainer(container *v1.Container, pullSecrets []*v1.Secret, podSandboxConfig *criv1.PodSandboxConfig) (*criv1.Image, error)
 }
 
 // imageManager provides the functionalities for image pulling.
@@ -62,7 +62,7 @@ func shouldPullImage(container *v1.Container, imagePresent bool) bool {
 	return false
 }
 
-func (m *imageManager) PullImageForContainer(container *v1.Container, podSandboxConfig *criv1.PodSandboxConfig) (*criv1.Image, error) {
+func (m *imageManager) PullImageForContainer(container *v1.Container, pullSecrets []*v1.Secret, podSandboxConfig *criv1.PodSandboxConfig) (*criv1.Image, error) {
 	imageWithTag, err := applyDefaultImageTag(container.Image)
 	if err != nil {
 		klog.ErrorS(err, "Failed to apply default image tag", container.Image)
@@ -103,7 +103,7 @@ func (m *imageManager) PullImageForContainer(container *v1.Container, podSandbox
 		return image, nil
 	}
 
-	_, err = m.imageService.PullImage(imageSpec, m.authConfig, podSandboxConfig)
+	err = m.pullImage(imageSpec, pullSecrets, podSandboxConfig)
 	if err != nil {
 		klog.ErrorS(err, "Failed to pull image", "image", imageWithTag)
 		return nil, ErrImagePull
@@ -132,6 +132,27 @@ func (m *imageManager) PullImageForContainer(container *v1.Container, podSandbox
 	return image, nil
 }
 
+// pullImage try credentials of pull secrets matching image registry one by one, then node default credential
+func (m *imageManager) pullImage(imageSpec *criv1.ImageSpec, pullSecrets []*v1.Secret, podSandboxConfig *criv1.PodSandboxConfig) error {
+	auths, err := imagePullAuthConfigs(imageSpec.Image, pullSecrets)
+	if err != nil {
+		return err
+	}
+	auths = append(auths, m.authConfig)
+
+	var pullErr error
+	for _, auth := range auths {
+		_, pullErr = m.imageService.PullImage(imageSpec, auth, podSandboxConfig)
+		if pullErr == nil {
+			return nil
+		}
+		if auth != nil {
+			klog.ErrorS(pullErr, "Failed to pull image using credential", "image", imageSpec.Image, "registry", auth.ServerAddress)
+		}
+	}
+	return pullErr
+}
+
 // applyDefaultImageTag parses a docker image string, if it doesn't contain any tag or digest,
 // a default tag will be applied.
 func applyDefaultImageTag(image string) (string, error) {
@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	dockerref "github.com/docker/distribution/reference"
+	v1 "k8s.io/api/core/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	dockerHubRegistry = "docker.io"
+)
+
+// dockerConfigEntry is a registry credential in docker config json
+type dockerConfigEntry struct {
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	Auth          string `json:"auth,omitempty"`
+	IdentityToken string `json:"identitytoken,omitempty"`
+	RegistryToken string `json:"registrytoken,omitempty"`
+}
+
+// dockerConfigJSON is content of a kubernetes.io/dockerconfigjson secret
+type dockerConfigJSON struct {
+	Auths map[string]dockerConfigEntry `json:"auths"`
+}
+
+// registryCredential is a credential of a registry location, location is a registry host with a optional repository path prefix
+type registryCredential struct {
+	location string
+	auth     *criv1.AuthConfig
+}
+
+// imagePullAuthConfigs return credentials in pull secrets which match image registry, more specific location is returned first,
+// malformed secrets are skipped
+func imagePullAuthConfigs(image string, pullSecrets []*v1.Secret) ([]*criv1.AuthConfig, error) {
+	named, err := dockerref.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, err
+	}
+	repository := fmt.Sprintf("%s/%s", dockerref.Domain(named), dockerref.Path(named))
+
+	matched := []registryCredential{}
+	for _, secret := range pullSecrets {
+		credentials, err := parsePullSecret(secret)
+		if err != nil {
+			klog.ErrorS(err, "Failed to parse image pull secret, skip it", "secret", secret.Name)
+			continue
+		}
+		for _, v := range credentials {
+			if repository == v.location || strings.HasPrefix(repository, v.location+"/") {
+				matched = append(matched, v)
+			}
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return len(matched[i].location) > len(matched[j].location)
+	})
+	auths := []*criv1.AuthConfig{}
+	for _, v := range matched {
+		auths = append(auths, v.auth)
+	}
+	return auths, nil
+}
+
+func parsePullSecret(secret *v1.Secret) ([]registryCredential, error) {
+	var auths map[string]dockerConfigEntry
+	switch {
+	case len(secret.Data[v1.DockerConfigJsonKey]) > 0:
+		config := dockerConfigJSON{}
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config); err != nil {
+			return nil, err
+		}
+		auths = config.Auths
+	case len(secret.Data[v1.DockerConfigKey]) > 0:
+		if err := json.Unmarshal(secret.Data[v1.DockerConfigKey], &auths); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("secret does not have %s or %s", v1.DockerConfigJsonKey, v1.DockerConfigKey)
+	}
+
+	credentials := []registryCredential{}
+	for registry, entry := range auths {
+		auth := &criv1.AuthConfig{
+			Username:      entry.Username,
+			Password:      entry.Password,
+			Auth:          entry.Auth,
+			IdentityToken: entry.IdentityToken,
+			RegistryToken: entry.RegistryToken,
+		}
+		if len(auth.Username) == 0 && len(entry.Auth) > 0 {
+			decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("auth of registry %s is not in username:password format", registry)
+			}
+			auth.Username, auth.Password = parts[0], parts[1]
+		}
+		auth.ServerAddress = registry
+		credentials = append(credentials, registryCredential{location: normalizeRegistryLocation(registry), auth: auth})
+	}
+	return credentials, nil
+}
+
+// normalizeRegistryLocation strip scheme and api version of a docker config registry key,
+// and use docker.io for all docker hub registry names
+func normalizeRegistryLocation(registry string) string {
+	location := strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	location = strings.TrimSuffix(location, "/")
+	location = strings.TrimSuffix(location, "/v1")
+	location = strings.TrimSuffix(location, "/v2")
+	host, path := location, ""
+	if i := strings.Index(location, "/"); i >= 0 {
+		host, path = location[:i], location[i:]
+	}
+	switch host {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		host = dockerHubRegistry
+	}
+	return host + path
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package images
+
+import (
+	"encoding/base64"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestPullSecret(name, key, config string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Type:       v1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{key: []byte(config)},
+	}
+}
+
+func TestImagePullAuthConfigs(t *testing.T) {
+	teamAuth := base64.StdEncoding.EncodeToString([]byte("team:team-pass"))
+	secrets := []*v1.Secret{
+		newTestPullSecret("registry", v1.DockerConfigJsonKey, `{"auths":{
+			"https://registry.example.com":{"username":"user","password":"pass"},
+			"registry.example.com/team":{"auth":"`+teamAuth+`"},
+			"https://index.docker.io/v1/":{"username":"hub","password":"hub-pass"}}}`),
+		newTestPullSecret("legacy", v1.DockerConfigKey, `{"quay.io":{"username":"quay","password":"quay-pass"}}`),
+		newTestPullSecret("broken", v1.DockerConfigJsonKey, `{"auths":`),
+	}
+
+	tests := []struct {
+		image     string
+		usernames []string
+	}{
+		{image: "registry.example.com/team/app:v1", usernames: []string{"team", "user"}},
+		{image: "registry.example.com/other/app", usernames: []string{"user"}},
+		{image: "registry.example.com:5000/team/app", usernames: []string{}},
+		{image: "nginx", usernames: []string{"hub"}},
+		{image: "quay.io/org/app@sha256:0000000000000000000000000000000000000000000000000000000000000000", usernames: []string{"quay"}},
+		{image: "gcr.io/app", usernames: []string{}},
+	}
+	for _, test := range tests {
+		auths, err := imagePullAuthConfigs(test.image, secrets)
+		if err != nil {
+			t.Fatalf("failed to get auth of image %s, %v", test.image, err)
+		}
+		usernames := []string{}
+		for _, v := range auths {
+			usernames = append(usernames, v.Username)
+		}
+		if len(usernames) != len(test.usernames) {
+			t.Errorf("expect image %s use credentials %v, got %v", test.image, test.usernames, usernames)
+			continue
+		}
+		for i := range usernames {
+			if usernames[i] != test.usernames[i] {
+				t.Errorf("expect image %s use credentials %v, got %v", test.image, test.usernames, usernames)
+				break
+			}
+		}
+	}
+}
@@ -95,6 +95,8 @@ type PodTerminate struct{}
 
 type PodHibernate struct{}
 
+type PodEvacuate struct{}
+
 type PodCreate struct {
 	Pod *types.FornaxPod
 }
@@ -125,6 +127,7 @@ type SessionState struct {
 	SessionId      string
 	SessionState   types.SessionState
 	ClientSessions []types.ClientSession
+	CheckpointData []byte
 }
 
 type SessionStatusChange struct {
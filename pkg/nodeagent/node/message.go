@@ -17,6 +17,8 @@ limitations under the License.
 package node
 
 import (
+	"errors"
+
 	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/pod"
 )
@@ -64,3 +66,60 @@ func BuildFornaxGrpcNodeReady(node *FornaxNode, revision int64) *grpc.FornaxCore
 		},
 	}
 }
+
+// max number of remembered fornaxcore message identifiers, it should be bigger than fornaxcore node outbox size
+const HandledMessageCacheSize = 4096
+
+// handledMessages remember identifiers of recently handled fornaxcore messages,
+// fornaxcore resend unacked messages when node reconnect, node skip them if they were handled already
+type handledMessages struct {
+	identifiers map[string]bool
+	order       []string
+}
+
+func newHandledMessages() *handledMessages {
+	return &handledMessages{
+		identifiers: map[string]bool{},
+		order:       []string{},
+	}
+}
+
+// has return true if message has been handled
+func (h *handledMessages) has(messageIdentifier string) bool {
+	return h.identifiers[messageIdentifier]
+}
+
+// add remember a message identifier, it return false if message has been handled
+func (h *handledMessages) add(messageIdentifier string) bool {
+	if h.identifiers[messageIdentifier] {
+		return false
+	}
+	if len(h.order) >= HandledMessageCacheSize {
+		delete(h.identifiers, h.order[0])
+		h.order = h.order[1:]
+	}
+	h.identifiers[messageIdentifier] = true
+	h.order = append(h.order, messageIdentifier)
+	return true
+}
+
+// retryableCommandError is a error of fornaxcore command which could succeed later, e.g. node is not ready yet,
+// command is not acked, fornaxcore keep it and resend it when node reconnect
+type retryableCommandError struct {
+	err error
+}
+
+func (e *retryableCommandError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableCommandError) Unwrap() error {
+	return e.err
+}
+
+// commandAckable return true if a command is handled successfully or failed with a permanent error,
+// a permanent error is reported back with pod or session state, resending command would fail again
+func commandAckable(err error) bool {
+	var retryable *retryableCommandError
+	return !errors.As(err, &retryable)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCommandAckable(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		ackable bool
+	}{
+		{name: "success", err: nil, ackable: true},
+		{name: "permanent error", err: errors.New("pod does not exist"), ackable: true},
+		{name: "retryable error", err: &retryableCommandError{err: errors.New("node not ready")}, ackable: false},
+		{name: "wrapped retryable error", err: fmt.Errorf("open session, %w", &retryableCommandError{err: errors.New("node not ready")}), ackable: false},
+	}
+	for _, test := range tests {
+		if ackable := commandAckable(test.err); ackable != test.ackable {
+			t.Errorf("%s: expect ackable %v, got %v", test.name, test.ackable, ackable)
+		}
+	}
+}
+
+func TestHandledMessages(t *testing.T) {
+	h := newHandledMessages()
+	if h.has("1") || !h.add("1") || !h.has("1") || h.add("1") {
+		t.Errorf("expect message remembered once handled")
+	}
+	for i := 2; i <= HandledMessageCacheSize+1; i++ {
+		h.add(fmt.Sprint(i))
+	}
+	if h.has("1") || !h.has(fmt.Sprint(HandledMessageCacheSize+1)) {
+		t.Errorf("expect oldest message identifier forgotten when cache is full")
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"sync"
 	"time"
 
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	default_config "centaurusinfra.io/fornax-serverless/pkg/config"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/dependency"
@@ -137,6 +138,16 @@ func (n *FornaxNode) initV1Node() (*v1.Node, error) {
 		},
 	}
 
+	// node labels and taints are used by fornaxcore scheduler to pin pods to node pool
+	for k, v := range n.NodeConfig.NodeLabels {
+		node.Labels[k] = v
+	}
+	node.Labels[fornaxv1.LabelFornaxCoreNodeRuntimeHandler] = n.NodeConfig.RuntimeHandler
+	node.Spec.Taints, err = config.ParseNodeTaints(n.NodeConfig.NodeTaints)
+	if err != nil {
+		return nil, err
+	}
+
 	node.Status.Conditions = append(node.Status.Conditions, v1.NodeCondition{
 		Type:               v1.NodeReady,
 		Status:             v1.ConditionFalse,
@@ -290,7 +301,7 @@ func ValidateNodeSpec(apiNode *v1.Node) []error {
 	}
 
 	for i, v := range apiNode.Spec.PodCIDRs {
-		if _, _, err := net.ParseCIDR(apiNode.Spec.PodCIDR); err != nil {
+		if _, _, err := net.ParseCIDR(v); err != nil {
 			errors = append(errors, fmt.Errorf("api node spec PodCIDRs[%d]: %s is invalid", i, v))
 		}
 	}
@@ -343,6 +354,9 @@ func NewFornaxNode(nodeConfig config.NodeConfiguration, dependencies *dependency
 			fornaxNode.V1Node.Generation = nodeWithRevision.Node.Generation
 			fornaxNode.V1Node.ResourceVersion = nodeWithRevision.Node.ResourceVersion
 			fornaxNode.V1Node.CreationTimestamp = nodeWithRevision.Node.CreationTimestamp
+			// register with pod cidr assigned before, fornaxcore keep it if it is not used by other nodes
+			fornaxNode.V1Node.Spec.PodCIDR = nodeWithRevision.Node.Spec.PodCIDR
+			fornaxNode.V1Node.Spec.PodCIDRs = nodeWithRevision.Node.Spec.PodCIDRs
 			fornaxNode.Revision = nodeWithRevision.Revision
 		}
 	}
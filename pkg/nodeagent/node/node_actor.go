@@ -19,6 +19,7 @@ package node
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -30,6 +31,7 @@ import (
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/fornaxcore"
 	internal "centaurusinfra.io/fornax-serverless/pkg/nodeagent/message"
 	podutil "centaurusinfra.io/fornax-serverless/pkg/nodeagent/pod"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/resource"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/session"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
@@ -61,6 +63,10 @@ type FornaxNodeActor struct {
 	fornoxCoreRef   message.ActorRef
 	podActors       *PodActorPool
 	nodePortManager *nodePortManager
+	// daemons waiting for old daemons with same name to be cleaned up, they are created after old daemons are cleaned up
+	pendingDaemons map[string]*v1.Pod
+	// fornaxcore messages handled recently, a resent message is acked again but not handled twice
+	handledMessages *handledMessages
 }
 
 func (n *FornaxNodeActor) Stop() error {
@@ -133,6 +139,7 @@ func (n *FornaxNodeActor) recreatePodStateFromRuntimeSummary(runtimeSummary Cont
 	for _, fpod := range runtimeSummary.runningPods {
 		klog.InfoS("Recover pod actor for a running pod", "pod", types.UniquePodName(fpod), "state", fpod.FornaxPodState)
 		n.nodePortManager.initNodePortRangeSlot(fpod.Pod)
+		n.allocatePodResource(fpod.Pod)
 		n.startPodActor(fpod)
 	}
 }
@@ -202,6 +209,9 @@ func (n *FornaxNodeActor) nodeHandler(msg message.ActorMessage) (interface{}, er
 			}
 		}
 		n.saveAndNotifyPodState(fppod)
+		if fppod.FornaxPodState == types.PodStateCleanup && fppod.Daemon {
+			n.createPendingDaemon(fppod.Identifier)
+		}
 	case internal.SessionStatusChange:
 		revision := n.incrementNodeRevision()
 		fpsession := msg.Body.(internal.SessionStatusChange).Session
@@ -229,6 +239,24 @@ func (n *FornaxNodeActor) nodeHandler(msg message.ActorMessage) (interface{}, er
 // TODO, notify Fornax core fatal error
 func (n *FornaxNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCoreMessage) (reply interface{}, err error) {
 	msgType := msg.GetMessageType()
+	msgId := msg.GetMessageIdentifier()
+	if fornaxgrpc.AckRequired(msgType) && len(msgId) > 0 {
+		if n.handledMessages.has(msgId) {
+			// ack again, previous ack could be lost when node disconnected
+			klog.InfoS("Skip fornaxcore message which has been handled", "msgId", msgId, "msgType", msgType)
+			n.notify(n.fornoxCoreRef, fornaxgrpc.NewMessageAck(msgId))
+			return nil, nil
+		}
+		// ack after handled successfully or failed permanently, fornaxcore resend unacked messages after node reconnect
+		defer func() {
+			if !commandAckable(err) {
+				klog.ErrorS(err, "Failed to handle fornaxcore message, wait for fornaxcore to resend it", "msgId", msgId, "msgType", msgType)
+				return
+			}
+			n.handledMessages.add(msgId)
+			n.notify(n.fornoxCoreRef, fornaxgrpc.NewMessageAck(msgId))
+		}()
+	}
 	switch msgType {
 	case fornaxgrpc.MessageType_NODE_CONFIGURATION:
 		err = n.onNodeConfigurationCommand(msg.GetNodeConfiguration())
@@ -240,6 +268,8 @@ func (n *FornaxNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCoreMes
 		err = n.onPodTerminateCommand(msg.GetPodTerminate())
 	case fornaxgrpc.MessageType_POD_HIBERNATE:
 		err = n.onPodHibernateCommand(msg.GetPodHibernate())
+	case fornaxgrpc.MessageType_POD_EVACUATE:
+		err = n.onPodEvacuateCommand(msg.GetPodEvacuate())
 	case fornaxgrpc.MessageType_SESSION_OPEN:
 		err = n.onSessionOpenCommand(msg.GetSessionOpen())
 	case fornaxgrpc.MessageType_SESSION_CLOSE:
@@ -255,16 +285,26 @@ func (n *FornaxNodeActor) processFornaxCoreMessage(msg *fornaxgrpc.FornaxCoreMes
 	return reply, err
 }
 
-// initialize node with node spec provided by fornaxcore, especially pod cidr
+// report node and all pods state to fornaxcore, fornaxcore ask a full sync when node revision is out of order,
+// or when a standby fornaxcore become primary and need to recover node state
 func (n *FornaxNodeActor) onNodeFullSyncCommand(msg *fornaxgrpc.NodeFullSync) error {
+	if n.state == NodeStateInitializing || n.state == NodeStateRegistering {
+		// node state will be reported when node is ready
+		return nil
+	}
 	n.notify(n.fornoxCoreRef, BuildFornaxGrpcNodeState(n.node, n.node.Revision))
 	return nil
 }
 
-// initialize node with node spec provided by fornaxcore, especially pod cidr
+// initialize node with node spec provided by fornaxcore, especially pod cidr,
+// after node registered, fornaxcore send configuration again when node daemons changed, only daemons are reconciled
 func (n *FornaxNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeConfiguration) error {
+	if n.state == NodeStateRegistered || n.state == NodeStateReady {
+		klog.InfoS("Received node daemons change from fornaxcore", "#daemon", len(msg.DaemonPods))
+		return n.reconcileNodeDaemons(msg.DaemonPods)
+	}
 	if n.state != NodeStateRegistering {
-		return fmt.Errorf("node is not in registering state, it does not expect configuration change after registering")
+		return fmt.Errorf("node is not in registering state, it does not expect configuration change before registering")
 	}
 
 	apiNode := msg.GetNode()
@@ -275,16 +315,22 @@ func (n *FornaxNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeConfigu
 		return fmt.Errorf("api node spec is invalid, %v", errs)
 	}
 
-	n.node.V1Node.Spec = *apiNode.Spec.DeepCopy()
-
 	if NodeSpecPodCidrChanged(n.node.V1Node, apiNode) {
 		if len(n.node.Pods.List()) > 0 {
 			return fmt.Errorf("change pod cidr when node has pods is not allowed, should not happen")
 		}
-		// TODO, set up pod cidr
+		podCidrs := apiNode.Spec.PodCIDRs
+		if len(podCidrs) == 0 {
+			podCidrs = []string{apiNode.Spec.PodCIDR}
+		}
+		if err := n.node.Dependencies.RuntimeService.UpdatePodCidr(podCidrs); err != nil {
+			klog.ErrorS(err, "Failed to set up pod cidr in runtime", "podCidrs", podCidrs)
+			return err
+		}
 	}
+	n.node.V1Node.Spec = *apiNode.Spec.DeepCopy()
 
-	err := n.initializeNodeDaemons(msg.DaemonPods)
+	err := n.reconcileNodeDaemons(msg.DaemonPods)
 	if err != nil {
 		klog.ErrorS(err, "Failed to initiaize daemons")
 		return err
@@ -318,7 +364,10 @@ func (n *FornaxNodeActor) onNodeConfigurationCommand(msg *fornaxgrpc.NodeConfigu
 	return nil
 }
 
-func (n *FornaxNodeActor) initializeNodeDaemons(pods []*v1.Pod) error {
+// reconcileNodeDaemons create daemons which do not exist on node, recreate daemons whose spec changed,
+// and terminate daemons which are not provided anymore
+func (n *FornaxNodeActor) reconcileNodeDaemons(pods []*v1.Pod) error {
+	desired := map[string]*v1.Pod{}
 	for _, p := range pods {
 		klog.Infof("Initialize daemon pod, %v", p)
 		errs := podutil.ValidatePodSpec(p)
@@ -333,25 +382,66 @@ func (n *FornaxNodeActor) initializeNodeDaemons(pods []*v1.Pod) error {
 		if !p.Spec.HostNetwork {
 			return errors.Errorf("Daemon pod must use host network")
 		}
+		desired[util.Name(p)] = p
+	}
 
-		v := n.node.Pods.Get(util.Name(p))
+	for name, p := range desired {
+		v := n.node.Pods.Get(name)
 		if v == nil {
-			_, actor, err := n.createPodAndActor(types.PodStateCreating, p.DeepCopy(), nil, true)
+			delete(n.pendingDaemons, name)
+			_, actor, err := n.createPodAndActor(types.PodStateCreating, p.DeepCopy(), nil, nil, nil, true)
 			if err != nil {
 				return err
 			} else {
 				n.notify(actor.Reference(), internal.PodCreate{})
 			}
+		} else if v.Pod.Annotations[fornaxv1.AnnotationFornaxCoreNodeDaemonHash] != p.Annotations[fornaxv1.AnnotationFornaxCoreNodeDaemonHash] {
+			// daemon spec changed, terminate old daemon, new daemon is created when old one is cleaned up
+			klog.InfoS("Daemon pod spec changed, recreate it", "pod", name)
+			n.pendingDaemons[name] = p.DeepCopy()
+			n.terminateDaemon(v)
 		} else {
-			// TODO, need to update daemon if spec changed
+			delete(n.pendingDaemons, name)
+		}
+	}
+
+	for _, v := range n.node.Pods.List() {
+		if _, found := desired[v.Identifier]; v.Daemon && !found {
+			klog.InfoS("Daemon pod is removed, terminate it", "pod", v.Identifier)
+			delete(n.pendingDaemons, v.Identifier)
+			n.terminateDaemon(v)
 		}
 	}
 	return nil
 }
 
+func (n *FornaxNodeActor) terminateDaemon(fpod *types.FornaxPod) {
+	if types.PodInTerminating(fpod) {
+		return
+	}
+	if podActor := n.podActors.Get(fpod.Identifier); podActor != nil {
+		n.notify(podActor.Reference(), internal.PodTerminate{})
+	}
+}
+
+// createPendingDaemon create a daemon which was waiting for old daemon with same name to be cleaned up
+func (n *FornaxNodeActor) createPendingDaemon(name string) {
+	p, found := n.pendingDaemons[name]
+	if !found {
+		return
+	}
+	delete(n.pendingDaemons, name)
+	_, actor, err := n.createPodAndActor(types.PodStateCreating, p, nil, nil, nil, true)
+	if err != nil {
+		klog.ErrorS(err, "Failed to recreate daemon pod", "pod", name)
+		return
+	}
+	n.notify(actor.Reference(), internal.PodCreate{})
+}
+
 // buildAFornaxPod validate pod spec, and allocate host port for pod container port, it also set pod lables,
 // modified pod spec will saved in store and return back to FornaxCore to make pod spec in sync
-func (n *FornaxNodeActor) buildAFornaxPod(state types.PodState, v1pod *v1.Pod, configMap *v1.ConfigMap, isDaemon bool) (*types.FornaxPod, error) {
+func (n *FornaxNodeActor) buildAFornaxPod(state types.PodState, v1pod *v1.Pod, configMap *v1.ConfigMap, secrets []*v1.Secret, pullSecrets []*v1.Secret, isDaemon bool) (*types.FornaxPod, error) {
 	errs := podutil.ValidatePodSpec(v1pod)
 	if len(errs) > 0 {
 		return nil, errors.New("Pod spec is invalid")
@@ -377,6 +467,22 @@ func (n *FornaxNodeActor) buildAFornaxPod(state types.PodState, v1pod *v1.Pod, c
 		fornaxPod.ConfigMap = configMap.DeepCopy()
 	}
 
+	for _, v := range secrets {
+		errs = podutil.ValidateSecretSpec(v)
+		if len(errs) > 0 {
+			return nil, errors.New("Secret spec is invalid")
+		}
+		fornaxPod.Secrets = append(fornaxPod.Secrets, v.DeepCopy())
+	}
+
+	for _, v := range pullSecrets {
+		errs = podutil.ValidateSecretSpec(v)
+		if len(errs) > 0 {
+			return nil, errors.New("Image pull secret spec is invalid")
+		}
+		fornaxPod.ImagePullSecrets = append(fornaxPod.ImagePullSecrets, v.DeepCopy())
+	}
+
 	// if fornax pod need to expose host port for containter port, there are chance port could be conflict between pods,
 	// to avoid port conflict on host of multiple pods, node allocate a unique host port number for each container port
 	// and overwrite pod spec's container port mapping, modified pod spec is returned back to FornaxCore,
@@ -406,9 +512,9 @@ func (n *FornaxNodeActor) startPodActor(fpod *types.FornaxPod) (*types.FornaxPod
 	return fpod, fpActor, nil
 }
 
-func (n *FornaxNodeActor) createPodAndActor(state types.PodState, v1Pod *v1.Pod, v1Config *v1.ConfigMap, isDaemon bool) (*types.FornaxPod, *podutil.PodActor, error) {
+func (n *FornaxNodeActor) createPodAndActor(state types.PodState, v1Pod *v1.Pod, v1Config *v1.ConfigMap, v1Secrets []*v1.Secret, v1PullSecrets []*v1.Secret, isDaemon bool) (*types.FornaxPod, *podutil.PodActor, error) {
 	// create fornax pod obj
-	fpod, err := n.buildAFornaxPod(state, v1Pod, v1Config, isDaemon)
+	fpod, err := n.buildAFornaxPod(state, v1Pod, v1Config, v1Secrets, v1PullSecrets, isDaemon)
 	if err != nil {
 		klog.ErrorS(err, "Failed to build a FornaxPod from pod spec", "namespace", v1Pod.Namespace, "name", v1Pod.Name)
 		return nil, nil, err
@@ -418,6 +524,7 @@ func (n *FornaxNodeActor) createPodAndActor(state types.PodState, v1Pod *v1.Pod,
 	if err != nil {
 		return nil, nil, err
 	}
+	n.allocatePodResource(fpod.Pod)
 
 	// new pod actor and start it
 	return n.startPodActor(fpod)
@@ -432,6 +539,7 @@ func (n *FornaxNodeActor) cleanupPodStoreAndActor(fppod *types.FornaxPod) error
 	}
 	n.node.Pods.Del(fppod.Identifier)
 	n.nodePortManager.DeallocatePodPortMapping(fppod.Pod)
+	n.deallocatePodResource(fppod.Pod)
 	return n.node.Dependencies.PodStore.DelObject(fppod.Identifier)
 }
 
@@ -454,7 +562,11 @@ func (n *FornaxNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) error {
 	}
 	v := n.node.Pods.Get(msg.GetPodIdentifier())
 	if v == nil {
-		fpod, actor, err := n.createPodAndActor(types.PodStateCreating, msg.GetPod().DeepCopy(), msg.GetConfigMap().DeepCopy(), false)
+		if err := n.admitPod(msg.GetPod()); err != nil {
+			n.rejectPod(msg.GetPod(), err)
+			return err
+		}
+		fpod, actor, err := n.createPodAndActor(types.PodStateCreating, msg.GetPod().DeepCopy(), msg.GetConfigMap().DeepCopy(), msg.GetSecrets(), msg.GetImagePullSecrets(), false)
 		if err != nil {
 			n.saveAndNotifyPodState(
 				&types.FornaxPod{
@@ -472,7 +584,6 @@ func (n *FornaxNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) error {
 		}
 		n.notify(actor.Reference(), internal.PodCreate{Pod: fpod})
 	} else {
-		// TODO, need to update daemon if spec changed
 		// not supposed to receive create command for a existing pod, ignore it and send back pod status
 		// pod should be terminate and recreated for update case
 		return fmt.Errorf("Pod: %s already exist", msg.GetPodIdentifier())
@@ -480,6 +591,59 @@ func (n *FornaxNodeActor) onPodCreateCommand(msg *fornaxgrpc.PodCreate) error {
 	return nil
 }
 
+func (n *FornaxNodeActor) resourceManagers() []resource.ResoureManager {
+	return []resource.ResoureManager{n.node.Dependencies.CPUManager, n.node.Dependencies.MemoryManager, n.node.Dependencies.VolumeManager}
+}
+
+// admitPod check pod requests fit into node resources not reserved and not allocated to other pods
+func (n *FornaxNodeActor) admitPod(pod *v1.Pod) error {
+	for _, m := range n.resourceManagers() {
+		if err := m.DryRunAdmit(*pod); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocatePodResource account pod requests as allocated, daemons and pods recovered from runtime are allocated without admission
+func (n *FornaxNodeActor) allocatePodResource(pod *v1.Pod) {
+	for _, m := range n.resourceManagers() {
+		m.Allocate(*pod)
+	}
+}
+
+func (n *FornaxNodeActor) deallocatePodResource(pod *v1.Pod) {
+	for _, m := range n.resourceManagers() {
+		m.Deallocate(*pod)
+	}
+}
+
+// rejectPod report a pod failed admission as terminated with reason, fornaxcore will create a new pod on other nodes
+func (n *FornaxNodeActor) rejectPod(pod *v1.Pod, err error) {
+	reason := "UnexpectedAdmissionError"
+	if resourceErr, ok := err.(*resource.InsufficientResourceError); ok {
+		reason = resourceErr.Reason()
+	}
+	klog.InfoS("Pod rejected by node", "pod", util.Name(pod), "reason", reason, "message", err.Error())
+	rejectedPod := pod.DeepCopy()
+	rejectedPod.Status.Phase = v1.PodFailed
+	rejectedPod.Status.Reason = reason
+	rejectedPod.Status.Message = err.Error()
+	n.node.Dependencies.EventRecorder.Eventf(rejectedPod, v1.EventTypeWarning, reason, "Pod rejected by node, %v", err)
+	n.saveAndNotifyPodState(
+		&types.FornaxPod{
+			Identifier:              util.Name(pod),
+			FornaxPodState:          types.PodStateCleanup,
+			Daemon:                  false,
+			Pod:                     rejectedPod,
+			RuntimePod:              nil,
+			Containers:              map[string]*types.FornaxContainer{},
+			Sessions:                map[string]*types.FornaxSession{},
+			LastStateTransitionTime: time.Now(),
+		},
+	)
+}
+
 // find pod actor and send a message to it, if pod actor does not exist, return error
 func (n *FornaxNodeActor) onPodTerminateCommand(msg *fornaxgrpc.PodTerminate) (err error) {
 	fpod := n.node.Pods.Get(msg.GetPodIdentifier())
@@ -495,7 +659,7 @@ func (n *FornaxNodeActor) onPodTerminateCommand(msg *fornaxgrpc.PodTerminate) (e
 			klog.Warningf("Pod actor %s already exit, create a new one", msg.GetPodIdentifier())
 			_, podActor, err = n.startPodActor(fpod)
 			if err != nil {
-				return err
+				return &retryableCommandError{err: err}
 			}
 		}
 		n.notify(podActor.Reference(), internal.PodTerminate{})
@@ -506,7 +670,7 @@ func (n *FornaxNodeActor) onPodTerminateCommand(msg *fornaxgrpc.PodTerminate) (e
 // find pod actor and send a message to it, if pod actor does not exist, return error
 func (n *FornaxNodeActor) onPodHibernateCommand(msg *fornaxgrpc.PodHibernate) error {
 	if n.state != NodeStateReady {
-		return fmt.Errorf("Node is not in ready state to active a standby pod")
+		return &retryableCommandError{err: fmt.Errorf("Node is not in ready state to active a standby pod")}
 	}
 	podActor := n.podActors.Get(msg.GetPodIdentifier())
 	if podActor == nil {
@@ -517,15 +681,32 @@ func (n *FornaxNodeActor) onPodHibernateCommand(msg *fornaxgrpc.PodHibernate) er
 	return nil
 }
 
+// find pod actor and let it evacuate sessions and terminate pod, if pod actor does not exist, return error
+func (n *FornaxNodeActor) onPodEvacuateCommand(msg *fornaxgrpc.PodEvacuate) error {
+	fpod := n.node.Pods.Get(msg.GetPodIdentifier())
+	podActor := n.podActors.Get(msg.GetPodIdentifier())
+	if fpod == nil || podActor == nil {
+		return fmt.Errorf("Pod: %s does not exist, Fornax core is not in sync, can not evacuate pod", msg.GetPodIdentifier())
+	}
+	if types.PodInTerminating(fpod) {
+		return nil
+	}
+	n.notify(podActor.Reference(), internal.PodEvacuate{})
+	return nil
+}
+
 // build a session actor to start session and monitor session state
 func (n *FornaxNodeActor) onSessionOpenCommand(msg *fornaxgrpc.SessionOpen) error {
 	s := &fornaxv1.ApplicationSession{}
 	if err := json.Unmarshal(msg.GetSessionData(), s); err != nil {
 		return err
 	}
+	if n.state != NodeStateReady {
+		return &retryableCommandError{err: fmt.Errorf("Node is not in ready state to open session")}
+	}
 	podActor := n.podActors.Get(msg.GetPodIdentifier())
-	if podActor == nil || n.state != NodeStateReady {
-		return fmt.Errorf("Pod: %s does not exist, can not open session, or node not ready", msg.GetPodIdentifier())
+	if podActor == nil {
+		return fmt.Errorf("Pod: %s does not exist, can not open session", msg.GetPodIdentifier())
 	} else {
 		n.notify(podActor.Reference(), internal.SessionOpen{SessionId: msg.GetSessionIdentifier(), Session: s})
 	}
@@ -547,6 +728,18 @@ func (n *FornaxNodeActor) notify(receiver message.ActorRef, msg interface{}) {
 	message.Send(n.innerActor.Reference(), receiver, msg)
 }
 
+// sendEvent report a event of node, pods or sessions to fornax core
+func (n *FornaxNodeActor) sendEvent(event *v1.Event) {
+	n.notify(n.fornoxCoreRef, &fornaxgrpc.FornaxCoreMessage{
+		MessageType: fornaxgrpc.MessageType_NODE_EVENT,
+		MessageBody: &fornaxgrpc.FornaxCoreMessage_NodeEvent{
+			NodeEvent: &fornaxgrpc.NodeEvent{
+				Event: event,
+			},
+		},
+	})
+}
+
 func NewNodeActor(node *FornaxNode) (*FornaxNodeActor, error) {
 	actor := &FornaxNodeActor{
 		nodeMutex:       sync.RWMutex{},
@@ -557,11 +750,15 @@ func NewNodeActor(node *FornaxNode) (*FornaxNodeActor, error) {
 		fornoxCoreRef:   nil,
 		podActors:       NewPodActorPool(),
 		nodePortManager: NewNodePortManager(&node.NodeConfig),
+		pendingDaemons:  map[string]*v1.Pod{},
+		handledMessages: newHandledMessages(),
 	}
 	actor.innerActor = message.NewLocalChannelActor(node.V1Node.GetName(), actor.nodeHandler)
+	node.Dependencies.EventRecorder = util.NewEventRecorder(v1.EventSource{Component: "fornax-nodeagent", Host: node.V1Node.GetName()}, actor.sendEvent)
 
 	klog.Info("Starting Fornax core actor")
-	fornaxCoreActor := fornaxcore.NewFornaxCoreActor(node.NodeConfig.NodeIP, util.Name(node.V1Node), node.NodeConfig.FornaxCoreUrls)
+	credentials := fornaxcore.NewNodeCredentials(node.NodeConfig.FornaxCoreCAFile, filepath.Join(node.NodeConfig.RootPath, "pki"), node.NodeConfig.BootstrapTokenFile)
+	fornaxCoreActor := fornaxcore.NewFornaxCoreActor(node.NodeConfig.NodeIP, util.Name(node.V1Node), node.NodeConfig.FornaxCoreUrls, credentials, nil)
 	actor.fornoxCoreRef = fornaxCoreActor.Reference()
 	err := fornaxCoreActor.Start(actor.innerActor.Reference())
 	if err != nil {
@@ -178,33 +178,36 @@ func UpdateNodeReadyStatus(criRuntime runtime.RuntimeService, node *v1.Node) (*v
 	return condition, nil
 }
 
-func UpdateNodeCPUStatus(cpuManager resource.CPUManager, node *v1.Node) (*v1.NodeCondition, error) {
+func UpdateNodeCPUStatus(cpuManager resource.ResoureManager, node *v1.Node) (*v1.NodeCondition, error) {
 	if node.Status.Allocatable == nil {
 		node.Status.Allocatable = make(v1.ResourceList)
 	}
 
+	cpuManager.SetCapacity(node.Status.Capacity)
 	UpdateAllocatableResourceQuantity(v1.ResourceCPU, node, cpuManager.GetReservedResource().Resources)
 
 	condition := &v1.NodeCondition{}
 	return condition, nil
 }
 
-func UpdateNodeMemoryStatus(memoryManager resource.MemoryManager, node *v1.Node) (*v1.NodeCondition, error) {
+func UpdateNodeMemoryStatus(memoryManager resource.ResoureManager, node *v1.Node) (*v1.NodeCondition, error) {
 	if node.Status.Allocatable == nil {
 		node.Status.Allocatable = make(v1.ResourceList)
 	}
 
+	memoryManager.SetCapacity(node.Status.Capacity)
 	UpdateAllocatableResourceQuantity(v1.ResourceMemory, node, memoryManager.GetReservedResource().Resources)
 	// TODO add condition
 	condition := &v1.NodeCondition{}
 	return condition, nil
 }
 
-func UpdateNodeVolumeStatus(volumeManager resource.VolumeManager, node *v1.Node) (*v1.NodeCondition, error) {
+func UpdateNodeVolumeStatus(volumeManager resource.ResoureManager, node *v1.Node) (*v1.NodeCondition, error) {
 	if node.Status.Allocatable == nil {
 		node.Status.Allocatable = make(v1.ResourceList)
 	}
 
+	volumeManager.SetCapacity(node.Status.Capacity)
 	UpdateAllocatableResourceQuantity(v1.ResourceStorage, node, volumeManager.GetReservedResource().Resources)
 
 	// TODO add condition
@@ -239,6 +242,9 @@ func UpdateNodeCapacity(cc cadvisor.CAdvisorInfoProvider, nodeConfig config.Node
 		for rName, rCap := range resource.ResourceListFromMachineInfo(info.MachineInfo) {
 			node.Status.Capacity[rName] = rCap
 		}
+		if info.RootFsInfo != nil {
+			node.Status.Capacity[v1.ResourceStorage] = util.ResourceQuantity(int64(info.RootFsInfo.Capacity), v1.ResourceStorage)
+		}
 
 		if nodeConfig.PodsPerCore > 0 {
 			node.Status.Capacity[v1.ResourcePods] =
@@ -260,7 +266,7 @@ func UpdateAllocatableResourceQuantity(resourceName v1.ResourceName, node *v1.No
 	if ok {
 		value := capacity.DeepCopy()
 		var resValue k8sresource.Quantity
-		resValue, ok = reservedQuantity[v1.ResourceCPU]
+		resValue, ok = reservedQuantity[resourceName]
 		if !ok {
 			resValue = zeroQuanity
 		}
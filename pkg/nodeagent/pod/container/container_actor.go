@@ -111,8 +111,8 @@ func (a *PodContainerActor) startStartupProber() {
 	klog.InfoS("Starting container startup probers", "pod", types.UniquePodName(a.pod), "container", a.container.ContainerSpec.Name)
 	if !a.container.InitContainer && a.container.ContainerSpec.StartupProbe != nil {
 		startupProber := NewContainerProber(a.onContainerProbeResult,
-			a.pod.Pod.DeepCopy(),
-			a.container.RuntimeContainer.Id,
+			a.pod,
+			a.container,
 			a.container.ContainerSpec.StartupProbe.DeepCopy(),
 			StartupProbe,
 			a.dependencies.RuntimeService,
@@ -128,8 +128,8 @@ func (a *PodContainerActor) startStartupProber() {
 // start container runtime status prober
 func (a *PodContainerActor) startRuntimeProber() {
 	runtimeStatusProber := NewContainerProber(a.onContainerProbeResult,
-		a.pod.Pod.DeepCopy(),
-		a.container.RuntimeContainer.Id,
+		a.pod,
+		a.container,
 		NewRuntimeStatusProbeSpec(),
 		RuntimeStatusProbe,
 		a.dependencies.RuntimeService,
@@ -153,18 +153,25 @@ func (a *PodContainerActor) onContainerProbeResult(result PodContainerProbeResul
 			a.probers[StartupProbe].Stop()
 		}
 	case LivenessProbe:
-		// liveness failure is treated as container failed, need pod to restart or terminate
-		if result.Result == ProbeResultFailed {
+		// liveness failure is treated as container failed, need pod to restart or terminate, stop probe
+		if result.Result == ProbeResultFailed && !a.inStoppingProcess() {
+			klog.InfoS("Container liveness probe failed", "pod", types.UniquePodName(a.pod), "container", a.container.ContainerSpec.Name, "result", probeStatus)
+			a.probers[LivenessProbe].Stop()
 			a.onContainerFailed()
 		}
 	case ReadinessProbe:
-		// readiness failure is treated as container unhealthy, stop probe
-		if result.Result == ProbeResultFailed {
+		// readiness success gates container into running state, readiness failure is treated as container unhealthy,
+		// keep probing until container stop, container could recover from unhealthy
+		prober := a.probers[ReadinessProbe]
+		if result.Result == ProbeResultFailed && prober.ProbeStat.ConsecutiveFailures == prober.Probe.FailureThreshold {
+			klog.InfoS("Container readiness probe failed", "pod", types.UniquePodName(a.pod), "container", a.container.ContainerSpec.Name, "result", probeStatus)
+			if a.container.State == types.ContainerStateRunning {
+				// container is not ready until readiness probe succeed again
+				a.container.State = types.ContainerStateStarted
+			}
 			a.notify(internal.PodContainerUnhealthy{Pod: a.pod, Container: a.container})
-			a.probers[ReadinessProbe].Stop()
-		} else if result.Result == ProbeResultSuccess {
+		} else if result.Result == ProbeResultSuccess && a.container.State == types.ContainerStateStarted {
 			a.onContainerReady()
-			a.probers[ReadinessProbe].Stop()
 		}
 	case RuntimeStatusProbe:
 		if result.Result == ProbeResultFailed || probeStatus == nil {
@@ -240,8 +247,8 @@ func (a *PodContainerActor) onContainerStarted() error {
 		klog.InfoS("Start pod liveness and readiness prober", "pod", pod.Identifier, "containerName", container.ContainerSpec.Name)
 		if a.container.ContainerSpec.LivenessProbe != nil {
 			prober := NewContainerProber(a.onContainerProbeResult,
-				a.pod.Pod.DeepCopy(),
-				a.container.RuntimeContainer.Id,
+				a.pod,
+				a.container,
 				a.container.ContainerSpec.LivenessProbe.DeepCopy(),
 				LivenessProbe,
 				a.dependencies.RuntimeService,
@@ -251,8 +258,8 @@ func (a *PodContainerActor) onContainerStarted() error {
 		}
 		if a.container.ContainerSpec.ReadinessProbe != nil {
 			prober := NewContainerProber(a.onContainerProbeResult,
-				a.pod.Pod.DeepCopy(),
-				a.container.RuntimeContainer.Id,
+				a.pod,
+				a.container,
 				a.container.ContainerSpec.ReadinessProbe.DeepCopy(),
 				ReadinessProbe,
 				a.dependencies.RuntimeService,
@@ -339,10 +346,10 @@ func (a *PodContainerActor) stopRuntimeContainer(timeout time.Duration) (err err
 		return err
 	} else {
 		if status == nil || runtime.ContainerExit(status) {
-			a.container.ContainerStatus = status
+			a.updateRuntimeStatus(status)
 			return nil
 		}
-		a.container.ContainerStatus = status
+		a.updateRuntimeStatus(status)
 	}
 
 	// call runtime to stop container
@@ -356,12 +363,21 @@ func (a *PodContainerActor) stopRuntimeContainer(timeout time.Duration) (err err
 	if err != nil {
 		return err
 	} else {
-		a.container.ContainerStatus = status
+		a.updateRuntimeStatus(status)
 	}
 
 	return nil
 }
 
+// updateRuntimeStatus replace runtime status, and keep restart count and last termination status of container
+func (a *PodContainerActor) updateRuntimeStatus(status *runtime.ContainerStatus) {
+	if status == nil || a.container.ContainerStatus == nil {
+		a.container.ContainerStatus = status
+	} else {
+		a.container.ContainerStatus.RuntimeStatus = status.RuntimeStatus
+	}
+}
+
 func NewPodContainerActor(supervisor message.ActorRef, pod *types.FornaxPod, container *types.FornaxContainer, dependencies *dependency.Dependencies) *PodContainerActor {
 	id := fmt.Sprintf("%s:%s", types.UniquePodName(pod), string(container.ContainerSpec.Name))
 	pca := &PodContainerActor{
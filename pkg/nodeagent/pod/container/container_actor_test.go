@@ -0,0 +1,90 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/dependency"
+	internal "centaurusinfra.io/fornax-serverless/pkg/nodeagent/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// newTestSupervisor return a actor which forward messages it received to a channel
+func newTestSupervisor() (*message.LocalChannelActor, chan interface{}) {
+	received := make(chan interface{}, 10)
+	supervisor := message.NewLocalChannelActor("supervisor", func(msg message.ActorMessage) (interface{}, error) {
+		received <- msg.Body
+		return nil, nil
+	})
+	supervisor.Start()
+	return supervisor, received
+}
+
+func expectMessage(t *testing.T, received chan interface{}, expect interface{}) {
+	select {
+	case msg := <-received:
+		if reflect.TypeOf(msg) != reflect.TypeOf(expect) {
+			t.Fatalf("expect %T, got %T", expect, msg)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("expect %T, got nothing", expect)
+	}
+}
+
+func TestContainerActorLifecycle(t *testing.T) {
+	runtimeService := runtime.NewFakeRuntimeService()
+	sandbox, _ := runtimeService.CreateSandbox(&criv1.PodSandboxConfig{Metadata: &criv1.PodSandboxMetadata{Name: "pod", Namespace: "test"}}, "")
+	runtimeContainer, _ := runtimeService.CreateContainer(sandbox.Id, &criv1.ContainerConfig{Metadata: &criv1.ContainerMetadata{Name: "app"}}, sandbox.SandboxConfig)
+
+	pod := &types.FornaxPod{
+		Identifier: "test/pod",
+		Pod:        &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}},
+		RuntimePod: sandbox,
+		Containers: map[string]*types.FornaxContainer{},
+	}
+	container := &types.FornaxContainer{
+		ContainerSpec:    &v1.Container{Name: "app"},
+		RuntimeContainer: runtimeContainer,
+		ContainerStatus:  &runtime.ContainerStatus{},
+	}
+	supervisor, received := newTestSupervisor()
+	defer supervisor.Stop()
+
+	actor := NewPodContainerActor(supervisor.Reference(), pod, container, &dependency.Dependencies{RuntimeService: runtimeService})
+	actor.Start()
+	defer actor.Stop()
+	expectMessage(t, received, internal.PodContainerCreated{})
+	expectMessage(t, received, internal.PodContainerStarted{})
+	expectMessage(t, received, internal.PodContainerReady{})
+
+	message.Send(supervisor.Reference(), actor.Reference(), internal.PodContainerStopping{Pod: pod, Container: container, GracePeriod: time.Second})
+	expectMessage(t, received, internal.PodContainerStopped{})
+	if !runtime.ContainerExit(container.ContainerStatus) {
+		t.Errorf("expect runtime container exited, got %v", container.ContainerStatus.RuntimeStatus)
+	}
+	if runtimeService.Calls("StopContainer") != 1 {
+		t.Errorf("expect container stopped by runtime once, got %d", runtimeService.Calls("StopContainer"))
+	}
+}
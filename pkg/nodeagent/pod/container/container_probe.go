@@ -17,6 +17,12 @@ limitations under the License.
 package container
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
@@ -49,6 +55,16 @@ const (
 	ProbeResultUnknown ProbeResult = "unknown"
 )
 
+const (
+	DefaultProbeTimeoutSeconds    = int32(1)
+	DefaultProbePeriodSeconds     = int32(10)
+	DefaultProbeSuccessThreshold  = int32(1)
+	DefaultProbeFailureThreshold  = int32(3)
+	DefaultProbeUserAgent         = "fornax-probe"
+	MinHTTPProbeSuccessStatusCode = http.StatusOK
+	MaxHTTPProbeSuccessStatusCode = http.StatusBadRequest
+)
+
 type ProbeStat struct {
 	ConsecutiveFailures int32
 	ConsecutiveSuccess  int32
@@ -56,9 +72,10 @@ type ProbeStat struct {
 
 type ContainerProber struct {
 	probeResultFunc ProbeResultFunc
-	stop            bool
+	stop            int32
 	containerId     string
 	podSpec         *v1.Pod
+	podIPs          []string
 	runtimeService  runtime.RuntimeService
 	Probe           *v1.Probe
 	Container       *types.FornaxContainer
@@ -69,14 +86,23 @@ type ContainerProber struct {
 }
 
 func (prober *ContainerProber) Stop() {
-	prober.stop = true
+	atomic.StoreInt32(&prober.stop, 1)
+}
+
+func (prober *ContainerProber) stopped() bool {
+	return atomic.LoadInt32(&prober.stop) == 1
 }
 
 func (prober *ContainerProber) Start() {
 	go func() {
+		defer prober.Ticker.Stop()
+		// container spec probe is not executed until initial delay passed, ticker fire first time after initial delay,
+		// runtime status probe run immediately to find container state asap
+		if prober.ProbeType != RuntimeStatusProbe && prober.Probe.InitialDelaySeconds > 0 {
+			<-prober.Ticker.C
+		}
 		for {
-			if prober.stop {
-				prober.Ticker.Stop()
+			if prober.stopped() {
 				break
 			}
 
@@ -119,6 +145,7 @@ func (prober *ContainerProber) Start() {
 				ProbeType: prober.ProbeType,
 			}
 
+			prober.LastProbeTime = time.Now()
 			prober.probeResultFunc(msg, obj)
 
 			select {
@@ -144,31 +171,148 @@ func (prober *ContainerProber) ExecProbe() (interface{}, error) {
 			prober.Ticker.Reset(time.Duration(RunningContainerProbeSeconds) * time.Second)
 		}
 		return status, nil
-	case LivenessProbe:
-		// TODO
-	case ReadinessProbe:
-		// TODO
-	case StartupProbe:
-		// TODO
+	case LivenessProbe, ReadinessProbe, StartupProbe:
+		return prober.execProbeHandler()
 	default:
 	}
 	return nil, nil
 }
 
+// execProbeHandler run container spec probe handler, a nil error means probe succeeded
+func (prober *ContainerProber) execProbeHandler() (interface{}, error) {
+	timeout := time.Duration(prober.Probe.TimeoutSeconds) * time.Second
+	handler := prober.Probe.ProbeHandler
+	switch {
+	case handler.Exec != nil:
+		stdout, stderr, err := prober.runtimeService.ExecCommand(prober.containerId, handler.Exec.Command, timeout)
+		if err != nil {
+			return string(stderr), err
+		}
+		return string(stdout), nil
+	case handler.HTTPGet != nil:
+		return prober.runHTTPGetProbe(handler.HTTPGet, timeout)
+	case handler.TCPSocket != nil:
+		return nil, prober.runTCPSocketProbe(handler.TCPSocket, timeout)
+	case handler.GRPC != nil:
+		return nil, fmt.Errorf("grpc probe is not supported")
+	default:
+		return nil, fmt.Errorf("unknown probe handler: %v", handler)
+	}
+}
+
+func (prober *ContainerProber) probeHost(host string) (string, error) {
+	if len(host) > 0 {
+		return host, nil
+	}
+	if len(prober.podIPs) == 0 {
+		return "", fmt.Errorf("failed to find container ip: %v", prober.podIPs)
+	}
+	return prober.podIPs[0], nil
+}
+
+func (prober *ContainerProber) runHTTPGetProbe(action *v1.HTTPGetAction, timeout time.Duration) (string, error) {
+	host, err := prober.probeHost(action.Host)
+	if err != nil {
+		return "", err
+	}
+	port, err := resolvePort(action.Port, prober.Container.ContainerSpec)
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if action.Scheme == v1.URISchemeHTTPS {
+		scheme = "https"
+	}
+	path := action.Path
+	if len(path) > 0 && path[0] == '/' {
+		path = path[1:]
+	}
+	url := fmt.Sprintf("%s://%s/%s", scheme, net.JoinHostPort(host, strconv.Itoa(port)), path)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", DefaultProbeUserAgent)
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	client := &http.Client{Timeout: timeout, Transport: probeTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	body := getHTTPRespBody(resp)
+	if resp.StatusCode < MinHTTPProbeSuccessStatusCode || resp.StatusCode >= MaxHTTPProbeSuccessStatusCode {
+		return body, fmt.Errorf("http probe %s failed with status code %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
+
+func (prober *ContainerProber) runTCPSocketProbe(action *v1.TCPSocketAction, timeout time.Duration) error {
+	host, err := prober.probeHost(action.Host)
+	if err != nil {
+		return err
+	}
+	port, err := resolvePort(action.Port, prober.Container.ContainerSpec)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// probeTransport skip tls verification and proxy, and do not keep connections like k8s http probe,
+// containers usually serve probe endpoints with self signed certificates
+var probeTransport = &http.Transport{
+	TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+	DisableKeepAlives: true,
+	Proxy:             nil,
+}
+
 type ProbeResultFunc func(PodContainerProbeResult, interface{})
 
-func NewContainerProber(probeResultFunc ProbeResultFunc, pod *v1.Pod, containerId string, probe *v1.Probe, probeType ProbeType, runtimeService runtime.RuntimeService) *ContainerProber {
+func NewContainerProber(probeResultFunc ProbeResultFunc, pod *types.FornaxPod, container *types.FornaxContainer, probe *v1.Probe, probeType ProbeType, runtimeService runtime.RuntimeService) *ContainerProber {
+	// apply k8s probe defaults, application spec is not defaulted by api server
+	if probe.TimeoutSeconds <= 0 {
+		probe.TimeoutSeconds = DefaultProbeTimeoutSeconds
+	}
+	if probe.PeriodSeconds <= 0 {
+		probe.PeriodSeconds = DefaultProbePeriodSeconds
+	}
+	if probe.SuccessThreshold <= 0 {
+		probe.SuccessThreshold = DefaultProbeSuccessThreshold
+	}
+	if probe.FailureThreshold <= 0 {
+		probe.FailureThreshold = DefaultProbeFailureThreshold
+	}
+	// ticker does not accept non positive interval
+	initialDelay := probe.InitialDelaySeconds
+	if initialDelay <= 0 {
+		initialDelay = InitialContainerProbeSeconds
+	}
+
+	podIPs := []string{}
+	if pod.RuntimePod != nil {
+		podIPs = append(podIPs, pod.RuntimePod.IPs...)
+	}
+
 	prober := &ContainerProber{
-		stop:            false,
-		podSpec:         pod,
-		containerId:     containerId,
+		stop:            0,
+		podSpec:         pod.Pod.DeepCopy(),
+		podIPs:          podIPs,
+		containerId:     container.RuntimeContainer.Id,
 		probeResultFunc: probeResultFunc,
 		ProbeType:       probeType,
 		Probe:           probe,
+		Container:       container,
 		runtimeService:  runtimeService,
 		LastProbeTime:   time.Unix(0, 0),
 		ProbeStat:       ProbeStat{ConsecutiveFailures: 0, ConsecutiveSuccess: 0},
-		Ticker:          time.NewTicker(time.Duration(probe.InitialDelaySeconds) * time.Second),
+		Ticker:          time.NewTicker(time.Duration(initialDelay) * time.Second),
 	}
 
 	return prober
@@ -0,0 +1,218 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package container
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// newTestProber return a prober of a running container of a pod listening on local host
+func newTestProber(t *testing.T, probe *v1.Probe, ports []v1.ContainerPort, resultFunc ProbeResultFunc) (*ContainerProber, *runtime.FakeRuntimeService) {
+	runtimeService := runtime.NewFakeRuntimeService()
+	sandbox, _ := runtimeService.CreateSandbox(&criv1.PodSandboxConfig{Metadata: &criv1.PodSandboxMetadata{Name: "pod", Namespace: "test"}}, "")
+	sandbox.IPs = []string{"127.0.0.1"}
+	runtimeContainer, _ := runtimeService.CreateContainer(sandbox.Id, &criv1.ContainerConfig{Metadata: &criv1.ContainerMetadata{Name: "app"}}, sandbox.SandboxConfig)
+	if err := runtimeService.StartContainer(runtimeContainer.Id); err != nil {
+		t.Fatalf("failed to start container, %v", err)
+	}
+	pod := &types.FornaxPod{
+		Identifier: "test/pod",
+		Pod:        &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}},
+		RuntimePod: sandbox,
+	}
+	container := &types.FornaxContainer{
+		ContainerSpec:    &v1.Container{Name: "app", Ports: ports},
+		RuntimeContainer: runtimeContainer,
+	}
+	if resultFunc == nil {
+		resultFunc = func(PodContainerProbeResult, interface{}) {}
+	}
+	return NewContainerProber(resultFunc, pod, container, probe, ReadinessProbe, runtimeService), runtimeService
+}
+
+func TestHTTPGetProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != DefaultProbeUserAgent || r.Header.Get("X-Probe") != "test" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/healthz":
+			w.Write([]byte("ok"))
+		case "/redirect":
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	tests := []struct {
+		name    string
+		path    string
+		port    intstr.IntOrString
+		success bool
+	}{
+		{name: "success", path: "/healthz", port: intstr.FromInt(port), success: true},
+		{name: "named port", path: "healthz", port: intstr.FromString("http"), success: true},
+		{name: "status code less than 400", path: "/redirect", port: intstr.FromInt(port), success: true},
+		{name: "server error", path: "/fail", port: intstr.FromInt(port), success: false},
+		{name: "unknown named port", path: "/healthz", port: intstr.FromString("unknown"), success: false},
+	}
+	for _, test := range tests {
+		probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{
+			Path:        test.path,
+			Port:        test.port,
+			HTTPHeaders: []v1.HTTPHeader{{Name: "X-Probe", Value: "test"}},
+		}}}
+		prober, _ := newTestProber(t, probe, []v1.ContainerPort{{Name: "http", ContainerPort: int32(port)}}, nil)
+		body, err := prober.ExecProbe()
+		if (err == nil) != test.success {
+			t.Errorf("%s: expect probe success %v, got %v", test.name, test.success, err)
+		}
+		if test.name == "success" && body != "ok" {
+			t.Errorf("%s: expect probe response body ok, got %v", test.name, body)
+		}
+	}
+}
+
+func TestHTTPSGetProbe(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(port), Scheme: v1.URISchemeHTTPS}}}
+	prober, _ := newTestProber(t, probe, nil, nil)
+	body, err := prober.ExecProbe()
+	if err != nil {
+		t.Fatalf("expect https probe of self signed certificate succeeded, got %v", err)
+	}
+	if body != "ok" {
+		t.Errorf("expect probe response body ok, got %v", body)
+	}
+}
+
+func TestTCPSocketProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen, %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(port)}}}
+	prober, _ := newTestProber(t, probe, nil, nil)
+	if _, err := prober.ExecProbe(); err != nil {
+		t.Errorf("expect tcp probe succeeded, got %v", err)
+	}
+
+	listener.Close()
+	if _, err := prober.ExecProbe(); err == nil {
+		t.Errorf("expect tcp probe failed after listener closed")
+	}
+}
+
+func TestExecProbe(t *testing.T) {
+	probe := &v1.Probe{ProbeHandler: v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"check"}}}}
+	prober, runtimeService := newTestProber(t, probe, nil, nil)
+	runtimeService.ExecFunc = func(containerID string, cmd []string) ([]byte, []byte, error) {
+		if len(cmd) == 1 && cmd[0] == "check" {
+			return []byte("ok"), []byte{}, nil
+		}
+		return []byte{}, []byte("unknown command"), fmt.Errorf("command exit with code 1")
+	}
+	if output, err := prober.ExecProbe(); err != nil || output != "ok" {
+		t.Errorf("expect exec probe succeeded with output ok, got %v, %v", output, err)
+	}
+
+	prober.Probe.ProbeHandler.Exec.Command = []string{"fail"}
+	if output, err := prober.ExecProbe(); err == nil || output != "unknown command" {
+		t.Errorf("expect exec probe failed with stderr output, got %v, %v", output, err)
+	}
+
+	// probe handler without action is not a valid probe
+	prober.Probe.ProbeHandler = v1.ProbeHandler{}
+	if _, err := prober.ExecProbe(); err == nil {
+		t.Errorf("expect probe without handler failed")
+	}
+}
+
+func TestProbeDefaults(t *testing.T) {
+	prober, _ := newTestProber(t, &v1.Probe{ProbeHandler: v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"check"}}}}, nil, nil)
+	expect := v1.Probe{
+		ProbeHandler:     prober.Probe.ProbeHandler,
+		TimeoutSeconds:   DefaultProbeTimeoutSeconds,
+		PeriodSeconds:    DefaultProbePeriodSeconds,
+		SuccessThreshold: DefaultProbeSuccessThreshold,
+		FailureThreshold: DefaultProbeFailureThreshold,
+	}
+	if !reflect.DeepEqual(*prober.Probe, expect) {
+		t.Errorf("expect probe defaulted to %v, got %v", expect, *prober.Probe)
+	}
+}
+
+func TestProbeInitialDelay(t *testing.T) {
+	results := make(chan ProbeResult, 10)
+	probe := &v1.Probe{
+		ProbeHandler:        v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"check"}}},
+		InitialDelaySeconds: 1,
+		PeriodSeconds:       1,
+		FailureThreshold:    2,
+	}
+	prober, runtimeService := newTestProber(t, probe, nil, func(result PodContainerProbeResult, _ interface{}) {
+		results <- result.Result
+	})
+	runtimeService.ExecFunc = func(containerID string, cmd []string) ([]byte, []byte, error) {
+		return []byte{}, []byte{}, fmt.Errorf("not ready")
+	}
+	start := time.Now()
+	prober.Start()
+	defer prober.Stop()
+
+	expect := []ProbeResult{ProbeResultUnknown, ProbeResultFailed}
+	for i, e := range expect {
+		select {
+		case result := <-results:
+			if i == 0 && time.Since(start) < time.Second {
+				t.Errorf("expect first probe after initial delay, got it after %s", time.Since(start))
+			}
+			if result != e {
+				t.Errorf("probe %d: expect result %s, got %s", i, e, result)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("probe %d: expect result %s, got nothing", i, e)
+		}
+	}
+}
@@ -96,6 +96,8 @@ func PodStateToFornaxState(pod *fornaxtypes.FornaxPod) grpc.PodState_State {
 		grpcState = grpc.PodState_Running
 	case types.PodStateHibernated:
 		grpcState = grpc.PodState_Running
+	case types.PodStateEvacuating:
+		grpcState = grpc.PodState_Evacuating
 	case types.PodStateTerminating:
 		grpcState = grpc.PodState_Terminating
 	case types.PodStateTerminated:
@@ -107,17 +107,15 @@ func (a *PodActor) CreatePod() (err error) {
 		return err
 	}
 
-	// TODO, Try to attach and mount volumes into pod, mounted vol will be mounted into container later, do not support volume for now
+	// prepare pod volumes on host, they are mounted into containers when containers are created
 	klog.InfoS("Prepare pod volumes", "pod", types.UniquePodName(a.pod))
-	if err := a.dependencies.VolumeManager.WaitForAttachAndMount(pod); err != nil {
-		klog.ErrorS(err, "Unable to attach or mount volumes for pod; skipping pod", "pod", types.UniquePodName(a.pod))
+	if err := a.dependencies.PodVolumes.SetupPodVolumes(pod, a.pod.ConfigMap, a.pod.Secrets); err != nil {
+		klog.ErrorS(err, "Unable to setup volumes for pod; skipping pod", "pod", types.UniquePodName(a.pod))
 		return err
 	}
 
-	// TODO, Fetch the pull secrets for the pod, for now assume no secrect required
-	// pullSecrets := GetPullSecretsForPod(pod)
-	klog.InfoS("Pull pod secret", "pod", types.UniquePodName(a.pod))
-	pullSecrets := []*v1.Secret{}
+	// image pull secrets are resolved by FornaxCore and delivered with pod
+	pullSecrets := a.pod.ImagePullSecrets
 
 	klog.InfoS("Create pod sandbox", "pod", types.UniquePodName(a.pod))
 	var runtimePod *runtime.Pod
@@ -138,7 +136,7 @@ func (a *PodActor) CreatePod() (err error) {
 	klog.InfoS("Start pod init containers", "pod", types.UniquePodName(a.pod))
 	var runtimeContainer *runtime.Container
 	for _, v1InitContainer := range pod.Spec.InitContainers {
-		runtimeContainer, err = a.createContainer(runtimePod.SandboxConfig, &v1InitContainer, pullSecrets)
+		runtimeContainer, err = a.createContainer(runtimePod.SandboxConfig, &v1InitContainer, pullSecrets, 0)
 		if err != nil {
 			klog.ErrorS(err, "Cannot create init container", "Pod", types.UniquePodName(a.pod), "Container", v1InitContainer.Name)
 			return err
@@ -162,7 +160,7 @@ func (a *PodActor) CreatePod() (err error) {
 
 	klog.InfoS("Start pod containers", "podName", types.UniquePodName(a.pod))
 	for _, v1Container := range pod.Spec.Containers {
-		runtimeContainer, err = a.createContainer(runtimePod.SandboxConfig, &v1Container, []*v1.Secret{})
+		runtimeContainer, err = a.createContainer(runtimePod.SandboxConfig, &v1Container, pullSecrets, 0)
 		if err != nil {
 			klog.ErrorS(err, "cannot create container", "Pod", types.UniquePodName(a.pod), "Container", v1Container.Name)
 			return err
@@ -196,7 +194,8 @@ func (a *PodActor) TerminatePod(gracefulPeriod time.Duration, force bool) (bool,
 
 	allContainerTerminated := true
 	for n, c := range pod.Containers {
-		if !runtime.ContainerExit(c.ContainerStatus) && !force {
+		// container waiting for restart has no running runtime container and container actor
+		if c.State != types.ContainerStateRestarting && !runtime.ContainerExit(c.ContainerStatus) && !force {
 			allContainerTerminated = false
 			klog.InfoS("Notify running container to stop", "pod", types.UniquePodName(pod), "container", n)
 			if gracefulPeriod == 0 {
@@ -234,9 +233,9 @@ func (a *PodActor) CleanupPod() (err error) {
 		}
 	}
 
-	// TODO, Try to unmount volumes into pod, mounted vol will be detached by volumemanager if volume not required anymore
-	klog.InfoS("Unmount Pod volume", "pod", types.UniquePodName(a.pod))
-	if err := a.dependencies.VolumeManager.UnmountPodVolume(pod); err != nil {
+	// unmount and remove pod volumes before pod data dirs are removed
+	klog.InfoS("Cleanup Pod volumes", "pod", types.UniquePodName(a.pod))
+	if err := a.dependencies.PodVolumes.CleanupPodVolumes(pod); err != nil {
 		klog.ErrorS(err, "Unable to unmount volumes for pod", "pod", types.UniquePodName(a.pod))
 		return err
 	}
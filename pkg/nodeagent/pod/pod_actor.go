@@ -53,6 +53,10 @@ type PodActor struct {
 	dependencies      *dependency.Dependencies
 	nodeConfig        *config.NodeConfiguration
 	houseKeepingError error
+	// evacuation deadline of evacuating sessions, sessions not evacuated before it are given up
+	evacuationDeadline time.Time
+	// report pod status to fornax core even pod state not changed, e.g. container restarted
+	reportPodStatus bool
 }
 
 func (n *PodActor) Reference() message.ActorRef {
@@ -86,7 +90,7 @@ func (a *PodActor) Start() {
 
 			select {
 			case _ = <-ticker.C:
-				if a.houseKeepingError != nil {
+				if a.houseKeepingError != nil || a.pod.FornaxPodState == types.PodStateEvacuating {
 					a.notify(a.Reference(), HouseKeeping{})
 				}
 			}
@@ -98,7 +102,10 @@ func (a *PodActor) Start() {
 // if container is terminated, skip it, if session is still pending, set it timeout
 func (a *PodActor) recoverContainerAndSessionActors() {
 	for k, cont := range a.pod.Containers {
-		if cont.State != types.ContainerStateTerminated {
+		if cont.State == types.ContainerStateRestarting {
+			klog.InfoS("Resume container restart on pod", "pod", types.UniquePodName(a.pod), "container", cont.ContainerSpec.Name)
+			a.startContainerRestartTimer(cont)
+		} else if cont.State != types.ContainerStateTerminated {
 			klog.InfoS("Recover container actor on pod", "pod", types.UniquePodName(a.pod), "container", cont.ContainerSpec.Name, "status", cont.State)
 			if _, found := a.containerActors[k]; !found {
 				actor := podcontainer.NewPodContainerActor(a.Reference(), a.pod, cont, a.dependencies)
@@ -109,7 +116,7 @@ func (a *PodActor) recoverContainerAndSessionActors() {
 	}
 
 	for _, sess := range a.pod.Sessions {
-		if !util.SessionIsClosed(sess.Session) {
+		if !util.SessionIsClosed(sess.Session) && !util.SessionIsEvacuated(sess.Session) {
 			klog.InfoS("Recover session actor on pod", "pod", types.UniquePodName(a.pod), "session", sess.Identifier, "status", sess.Session.Status)
 			var sessService sessionservice.SessionService
 			if util.PodHasSessionServiceAnnotation(a.pod.Pod) {
@@ -145,6 +152,8 @@ func (a *PodActor) podHandler(msg message.ActorMessage) (interface{}, error) {
 		err = a.create()
 	case internal.PodHibernate:
 		err = a.hibernate()
+	case internal.PodEvacuate:
+		err = a.evacuate()
 	case internal.PodTerminate:
 		err = a.terminate(false)
 	case internal.PodContainerCreated:
@@ -157,6 +166,10 @@ func (a *PodActor) podHandler(msg message.ActorMessage) (interface{}, error) {
 		err = a.onPodContainerStopped(msg.Body.(internal.PodContainerStopped))
 	case internal.PodContainerFailed:
 		err = a.onPodContainerFailed(msg.Body.(internal.PodContainerFailed))
+	case internal.PodContainerUnhealthy:
+		err = a.onPodContainerUnhealthy(msg.Body.(internal.PodContainerUnhealthy))
+	case ContainerRestart:
+		err = a.restartContainer(msg.Body.(ContainerRestart))
 	case internal.SessionOpen:
 		err = a.onSessionOpenCommand(msg.Body.(internal.SessionOpen))
 	case internal.SessionClose:
@@ -173,6 +186,9 @@ func (a *PodActor) podHandler(msg message.ActorMessage) (interface{}, error) {
 			a.houseKeepingError = nil
 			err = a.podHouseKeeping()
 		}
+		if err == nil && a.pod.FornaxPodState == types.PodStateEvacuating {
+			err = a.checkEvacuationDeadline()
+		}
 	default:
 	}
 
@@ -187,9 +203,10 @@ func (a *PodActor) podHandler(msg message.ActorMessage) (interface{}, error) {
 		}
 	}
 
-	// notify fornax core when state changed or pod cleaned
-	if oldPodState != a.pod.FornaxPodState || a.pod.FornaxPodState == types.PodStateCleanup {
+	// notify fornax core when state changed, container restarted or pod cleaned
+	if oldPodState != a.pod.FornaxPodState || a.pod.FornaxPodState == types.PodStateCleanup || a.reportPodStatus {
 		klog.InfoS("PodState changed", "pod", types.UniquePodName(a.pod), "old state", oldPodState, "new state", a.pod.FornaxPodState)
+		a.reportPodStatus = false
 		a.notify(a.supervisor, internal.PodStatusChange{Pod: a.pod})
 	}
 	return nil, a.houseKeepingError
@@ -209,6 +226,7 @@ func (a *PodActor) create() error {
 	a.pod.FornaxPodState = types.PodStateCreating
 	err := a.CreatePod()
 	if err != nil {
+		a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeWarning, "FailedCreate", "Failed to create pod, %v", err)
 		return err
 	}
 
@@ -293,10 +311,68 @@ func (a *PodActor) hibernateContainer(container *types.FornaxContainer) error {
 	err := a.dependencies.RuntimeService.HibernateContainer(container.ContainerStatus.RuntimeStatus.Id)
 	if err != nil {
 		klog.ErrorS(err, "Failed to hibernate Container", "Container", container.ContainerStatus.RuntimeStatus.Id)
+		a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeWarning, "FailedHibernate", "Failed to hibernate container %s, %v", container.ContainerSpec.Name, err)
 		return err
 	} else {
 		a.pod.FornaxPodState = types.PodStateHibernated
 		container.State = types.ContainerStateHibernated
+		a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeNormal, "Hibernated", "Hibernated container %s", container.ContainerSpec.Name)
+	}
+	return nil
+}
+
+// evacuate ask open sessions to checkpoint themselves and leave this pod, fornax core reopen them on other pods with checkpoint data,
+// pod is terminated after all sessions are evacuated or evacuation deadline passed
+func (a *PodActor) evacuate() error {
+	pod := a.pod
+	klog.InfoS("Evacuating pod", "pod", types.UniquePodName(pod), "state", pod.FornaxPodState)
+	if types.PodInTerminating(pod) || pod.FornaxPodState == types.PodStateEvacuating {
+		return nil
+	}
+	if pod.Pod.Annotations == nil {
+		pod.Pod.Annotations = map[string]string{}
+	}
+	pod.Pod.Annotations[fornaxv1.AnnotationFornaxCoreEvacuate] = "true"
+
+	if !types.PodHasOpenSessions(pod) {
+		return a.terminate(false)
+	}
+
+	pod.FornaxPodState = types.PodStateEvacuating
+	graceSeconds := uint16(0)
+	for _, v := range pod.Sessions {
+		if gs := session.GracePeriodSeconds(v); gs > graceSeconds {
+			graceSeconds = gs
+		}
+	}
+	a.evacuationDeadline = time.Now().Add(time.Duration(graceSeconds) * time.Second)
+	klog.InfoS("Evacuate open sessions before terminating pod", "pod", types.UniquePodName(pod), "#session", len(a.sessionActors), "deadline", a.evacuationDeadline)
+	for _, v := range a.sessionActors {
+		err := v.EvacuateSession()
+		if err != nil {
+			klog.ErrorS(err, "Failed to evacuate session, wait for session evacuated until deadline", "pod", types.UniquePodName(pod))
+		}
+	}
+	return nil
+}
+
+// checkEvacuationDeadline set sessions which are still not evacuated after deadline evacuated without checkpoint and terminate pod
+func (a *PodActor) checkEvacuationDeadline() error {
+	if time.Now().Before(a.evacuationDeadline) {
+		return nil
+	}
+	klog.InfoS("Pod evacuation deadline passed, give up left sessions", "pod", types.UniquePodName(a.pod), "#session", len(a.sessionActors))
+	for _, v := range a.pod.Sessions {
+		if util.SessionIsOpen(v.Session) {
+			a.handleSessionState(internal.SessionState{
+				SessionId:      v.Identifier,
+				SessionState:   types.SessionStateEvacuated,
+				ClientSessions: []types.ClientSession{},
+			})
+		}
+	}
+	if a.pod.FornaxPodState == types.PodStateEvacuating {
+		return a.terminate(false)
 	}
 	return nil
 }
@@ -386,14 +462,46 @@ func (a *PodActor) handlePodContainerExit(pod *types.FornaxPod, container *types
 			// init container is expected to run to end
 		} else if runtime.ContainerExitAbnormal(container.ContainerStatus) {
 			// init container failed, terminate pod
+			a.dependencies.EventRecorder.Eventf(pod.Pod, v1.EventTypeWarning, "Failed", "Init container %s exited abnormally, %s, terminate pod", container.ContainerSpec.Name, containerExitMessage(container))
 			return a.terminate(true)
 		}
+	} else if shouldRestartContainer(pod, container) {
+		return a.scheduleContainerRestart(container)
 	} else {
+		if pod.Pod.DeletionTimestamp == nil {
+			a.dependencies.EventRecorder.Eventf(pod.Pod, v1.EventTypeWarning, "Failed", "Container %s exited, %s, terminate pod", container.ContainerSpec.Name, containerExitMessage(container))
+		}
 		return a.terminate(true)
 	}
 	return nil
 }
 
+// when a container readiness probe failed, pod is not moved into running state until container report ready,
+// a running pod is kept running but reported not ready and refuse new sessions until container report ready again,
+// liveness probe is responsible for failing a wedged container
+func (a *PodActor) onPodContainerUnhealthy(msg internal.PodContainerUnhealthy) error {
+	pod := msg.Pod
+	container := msg.Container
+	klog.InfoS("Pod Container is unhealthy", "Pod", types.UniquePodName(pod), "Container", container.ContainerSpec.Name, "PodState", pod.FornaxPodState)
+	if types.PodInTerminating(pod) {
+		return nil
+	}
+	a.dependencies.EventRecorder.Eventf(pod.Pod, v1.EventTypeWarning, "Unhealthy", "Container %s readiness probe failed", container.ContainerSpec.Name)
+	// report pod ready condition change to fornaxcore
+	a.reportPodStatus = true
+	return nil
+}
+
+// podNotReadyContainer return name of first not ready container, empty if all containers are ready
+func podNotReadyContainer(pod *types.FornaxPod) string {
+	for _, v := range pod.Containers {
+		if !v.InitContainer && v.State != types.ContainerStateRunning && v.State != types.ContainerStateHibernated {
+			return v.ContainerSpec.Name
+		}
+	}
+	return ""
+}
+
 // when a container report it's ready, set pod to running state if all container are ready and init containers exit normally
 func (a *PodActor) onPodContainerReady(msg internal.PodContainerReady) error {
 	pod := a.pod
@@ -405,7 +513,8 @@ func (a *PodActor) onPodContainerReady(msg internal.PodContainerReady) error {
 		if v.InitContainer {
 			allContainerReady = allContainerReady && runtime.ContainerExit(v.ContainerStatus)
 		} else {
-			allContainerReady = allContainerReady && runtime.ContainerRunning(v.ContainerStatus)
+			// container with readiness probe is ready only after probe succeeded
+			allContainerReady = allContainerReady && runtime.ContainerRunning(v.ContainerStatus) && (v.State == types.ContainerStateRunning || v.State == types.ContainerStateHibernated)
 		}
 	}
 
@@ -439,14 +548,18 @@ func (a *PodActor) onSessionOpenCommand(msg internal.SessionOpen) (err error) {
 				err := a.dependencies.RuntimeService.WakeupContainer(v.RuntimeContainer.Id)
 				if err != nil {
 					// if a pod can not be wakeup, terminate it to get a new one
+					a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeWarning, "FailedWakeup", "Failed to wake up container %s to open session %s, terminate pod, %v", v.ContainerSpec.Name, msg.SessionId, err)
 					return a.terminate(true)
 				}
 				v.State = types.ContainerStateRunning
 			}
 		}
 		a.pod.FornaxPodState = types.PodStateRunning
+		a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeNormal, "WokeUp", "Woke up pod to open session %s", msg.SessionId)
 	} else if a.pod.FornaxPodState != types.PodStateRunning {
 		return fmt.Errorf("Pod: %s is not in running state, can not open session", msg.SessionId)
+	} else if name := podNotReadyContainer(a.pod); len(name) > 0 {
+		return fmt.Errorf("Pod: %s container %s is not ready, can not open session", a.pod.Identifier, name)
 	}
 	if v, found := a.pod.Sessions[msg.SessionId]; found {
 		if util.SessionIsOpen(v.Session) {
@@ -488,6 +601,7 @@ func (a *PodActor) onSessionCloseCommand(msg internal.SessionClose) error {
 
 // simply update application session status and copy client session
 // if a session timeout, terminate pod,it could close other sessions on it
+// when a session closed, pod is only terminated or hibernated after all sessions on it are closed
 func (a *PodActor) handleSessionState(s internal.SessionState) error {
 	session, found := a.pod.Sessions[s.SessionId]
 	if !found {
@@ -507,6 +621,12 @@ func (a *PodActor) handleSessionState(s internal.SessionState) error {
 	case types.SessionStateNoHeartbeat:
 		newStatus.SessionStatus = fornaxv1.SessionStatusClosed
 		newStatus.CloseTime = util.NewCurrentMetaTime()
+	case types.SessionStateEvacuating:
+		newStatus.SessionStatus = fornaxv1.SessionStatusEvacuating
+	case types.SessionStateEvacuated:
+		newStatus.SessionStatus = fornaxv1.SessionStatusEvacuated
+		newStatus.CheckpointData = s.CheckpointData
+		newStatus.CloseTime = util.NewCurrentMetaTime()
 	}
 
 	// just copy client sessions
@@ -515,7 +635,7 @@ func (a *PodActor) handleSessionState(s internal.SessionState) error {
 		clientSessions = append(clientSessions, v1.LocalObjectReference{Name: v.Identifier})
 	}
 	newStatus.ClientSessions = clientSessions
-	if len(newStatus.ClientSessions) > 0 {
+	if len(newStatus.ClientSessions) > 0 && newStatus.SessionStatus == fornaxv1.SessionStatusAvailable {
 		newStatus.SessionStatus = fornaxv1.SessionStatusInUse
 	}
 
@@ -531,8 +651,20 @@ func (a *PodActor) handleSessionState(s internal.SessionState) error {
 		util.RemoveFinalizer(&session.Session.ObjectMeta, fornaxv1.FinalizerOpenSession)
 	}
 
+	if util.SessionIsEvacuated(session.Session) {
+		delete(a.sessionActors, session.Identifier)
+		if a.pod.FornaxPodState == types.PodStateEvacuating && !types.PodHasOpenSessions(a.pod) {
+			// all sessions are evacuated, terminate pod
+			return a.terminate(false)
+		}
+	}
+
 	if util.SessionIsClosed(session.Session) {
 		delete(a.sessionActors, session.Identifier)
+		if types.PodHasOpenSessions(a.pod) {
+			// other sessions are still open on this pod, keep it running for them
+			return nil
+		}
 		if session.Session.Spec.KillInstanceWhenSessionClosed {
 			return a.terminate(false)
 		} else if util.PodHasHibernateAnnotation(a.pod.Pod) && a.nodeConfig.RuntimeHandler == runtime.QuarkRuntime {
@@ -0,0 +1,121 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/dependency"
+	internal "centaurusinfra.io/fornax-serverless/pkg/nodeagent/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPodContainerUnhealthy(t *testing.T) {
+	container := &types.FornaxContainer{ContainerSpec: &v1.Container{Name: "app"}, State: types.ContainerStateRunning}
+	pod := &types.FornaxPod{
+		Identifier:     "test/pod",
+		Pod:            &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}},
+		FornaxPodState: types.PodStateRunning,
+		Containers:     map[string]*types.FornaxContainer{"app": container},
+		Sessions:       map[string]*types.FornaxSession{},
+	}
+	recorder := record.NewFakeRecorder(10)
+	actor := &PodActor{pod: pod, dependencies: &dependency.Dependencies{EventRecorder: recorder}}
+
+	// container actor set container not ready when readiness probe failed
+	container.State = types.ContainerStateStarted
+	if err := actor.onPodContainerUnhealthy(internal.PodContainerUnhealthy{Pod: pod, Container: container}); err != nil {
+		t.Fatalf("expect unhealthy container handled, got %v", err)
+	}
+	if !actor.reportPodStatus {
+		t.Errorf("expect pod status reported to fornaxcore")
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expect a unhealthy event recorded, got %d", len(recorder.Events))
+	}
+	if pod.FornaxPodState != types.PodStateRunning {
+		t.Errorf("expect pod kept running, got %s", pod.FornaxPodState)
+	}
+	if err := actor.onSessionOpenCommand(internal.SessionOpen{SessionId: "test/session"}); err == nil {
+		t.Errorf("expect session not opened on a not ready pod")
+	}
+
+	// unhealthy container of terminating pod is ignored
+	actor.reportPodStatus = false
+	pod.FornaxPodState = types.PodStateTerminating
+	actor.onPodContainerUnhealthy(internal.PodContainerUnhealthy{Pod: pod, Container: container})
+	if actor.reportPodStatus || len(recorder.Events) != 1 {
+		t.Errorf("expect unhealthy container of terminating pod ignored")
+	}
+}
+
+func TestPodKeptRunningWhenOtherSessionsOpen(t *testing.T) {
+	newSession := func(name string) *types.FornaxSession {
+		return &types.FornaxSession{
+			Identifier:    "test/" + name,
+			PodIdentifier: "test/pod",
+			Session: &fornaxv1.ApplicationSession{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+				Spec:       fornaxv1.ApplicationSessionSpec{KillInstanceWhenSessionClosed: true},
+				Status:     fornaxv1.ApplicationSessionStatus{SessionStatus: fornaxv1.SessionStatusAvailable},
+			},
+			ClientSessions: map[string]*types.ClientSession{},
+		}
+	}
+	pod := &types.FornaxPod{
+		Identifier:     "test/pod",
+		Pod:            &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod"}},
+		FornaxPodState: types.PodStateRunning,
+		Containers:     map[string]*types.FornaxContainer{},
+		Sessions: map[string]*types.FornaxSession{
+			"test/session1": newSession("session1"),
+			"test/session2": newSession("session2"),
+		},
+	}
+	supervisorChannel := make(chan message.ActorMessage, 10)
+	supervisor := &message.LocalChannelActorRef{Identifier: "supervisor", Channel: &supervisorChannel}
+	recorder := record.NewFakeRecorder(10)
+	actor := NewPodActor(supervisor, pod, &config.NodeConfiguration{}, &dependency.Dependencies{EventRecorder: recorder}, nil)
+
+	// closing one of two sessions keep pod running
+	if err := actor.handleSessionState(internal.SessionState{SessionId: "test/session1", SessionState: types.SessionStateClosed}); err != nil {
+		t.Fatalf("expect session close handled, got %v", err)
+	}
+	if pod.Sessions["test/session1"].Session.Status.SessionStatus != fornaxv1.SessionStatusClosed {
+		t.Errorf("expect session1 closed, got %s", pod.Sessions["test/session1"].Session.Status.SessionStatus)
+	}
+	if pod.FornaxPodState != types.PodStateRunning || pod.Pod.DeletionTimestamp != nil {
+		t.Errorf("expect pod kept running while session2 is open, got %s", pod.FornaxPodState)
+	}
+	if len(supervisorChannel) != 1 {
+		t.Errorf("expect session1 status change reported, got %d messages", len(supervisorChannel))
+	}
+
+	// closing last session terminate pod
+	if err := actor.handleSessionState(internal.SessionState{SessionId: "test/session2", SessionState: types.SessionStateClosed}); err != nil {
+		t.Fatalf("expect session close handled, got %v", err)
+	}
+	if !types.PodInTerminating(pod) || pod.Pod.DeletionTimestamp == nil {
+		t.Errorf("expect pod terminated after last session closed, got %s", pod.FornaxPodState)
+	}
+}
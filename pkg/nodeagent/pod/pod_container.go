@@ -23,22 +23,24 @@ import (
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 	"k8s.io/klog/v2"
 
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
 	cruntime "centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
 )
 
-// createContainer starts a container and returns a message indicates why it is failed on error.
+// createContainer starts a container and returns a message indicates why it is failed on error,
+// restartCount is number of times this container was restarted, it's used as container attempt.
 // It starts the container through the following steps:
 // * pull the image
 // * create the container
 // * start the container
-func (a *PodActor) createContainer(podSandboxConfig *criv1.PodSandboxConfig, containerSpec *v1.Container, pullSecrets []*v1.Secret,
+func (a *PodActor) createContainer(podSandboxConfig *criv1.PodSandboxConfig, containerSpec *v1.Container, pullSecrets []*v1.Secret, restartCount int32,
 ) (*cruntime.Container, error) {
 
 	klog.InfoS("Pull image for container", "pod", types.UniquePodName(a.pod), "container", containerSpec.Name)
 	pod := a.pod.Pod
 	// pull the image.
-	imageRef, err := a.dependencies.ImageManager.PullImageForContainer(containerSpec, podSandboxConfig)
+	imageRef, err := a.dependencies.ImageManager.PullImageForContainer(containerSpec, pullSecrets, podSandboxConfig)
 	if err != nil {
 		klog.ErrorS(err, "Failed to pull image", "pod", types.UniquePodName(a.pod), "container", containerSpec.Name)
 		return nil, err
@@ -60,7 +62,7 @@ func (a *PodActor) createContainer(podSandboxConfig *criv1.PodSandboxConfig, con
 
 	// create the container runtime configuration
 	klog.InfoS("Generate container runtime config", "pod", types.UniquePodName(a.pod), "container", containerSpec.Name)
-	containerConfig, err := a.generateContainerConfig(containerSpec, imageRef)
+	containerConfig, err := a.generateContainerConfig(containerSpec, imageRef, restartCount)
 	if err != nil {
 		klog.ErrorS(err, "Failed to generate container runtime config", "pod", types.UniquePodName(a.pod), "container", containerSpec.Name)
 		return nil, ErrCreateContainerConfig
@@ -76,27 +78,40 @@ func (a *PodActor) createContainer(podSandboxConfig *criv1.PodSandboxConfig, con
 	return runtimeContainer, nil
 }
 
-func (m *PodActor) generateContainerConfig(container *v1.Container, imageRef *criv1.Image) (*criv1.ContainerConfig, error) {
+func (m *PodActor) generateContainerConfig(container *v1.Container, imageRef *criv1.Image, restartCount int32) (*criv1.ContainerConfig, error) {
 	pod := m.pod.Pod
-	// TODO, comment it out until we have volume supported
-	// opts, cleanupAction, err := runtime.GenerateRunContainerOptions(pod, container, m.pod.RuntimePod.IPs[0], m.pod.RuntimePod.IPs)
-	// if err != nil {
-	//  return nil, nil, err
-	// }
+	mounts, err := m.dependencies.PodVolumes.GetContainerMounts(pod, container)
+	if err != nil {
+		return nil, err
+	}
+	opts := &cruntime.RunContainerOptions{
+		Mounts: mounts,
+	}
 
-	_, err := BuildContainerLogsDirectory(pod, container.Name)
+	_, err = BuildContainerLogsDirectory(pod, container.Name)
 	if err != nil {
 		return nil, fmt.Errorf("create log directory for container %s failed: %v", container.Name, err)
 	}
-	containerLogsPath := ContainerLogFileName(container.Name, 0)
+	containerLogsPath := ContainerLogFileName(container.Name, int(restartCount))
 	podIP := ""
 	if len(m.pod.RuntimePod.IPs) > 0 {
 		podIP = m.pod.RuntimePod.IPs[0]
 	}
-	envs, err := cruntime.MakeEnvironmentVariables(pod, container, []*v1.ConfigMap{}, []*v1.Secret{}, podIP, m.pod.RuntimePod.IPs)
+	configMaps := []*v1.ConfigMap{}
+	if m.pod.ConfigMap != nil {
+		configMaps = append(configMaps, m.pod.ConfigMap)
+	}
+	envs, err := cruntime.MakeEnvironmentVariables(pod, container, configMaps, m.pod.Secrets, podIP, m.pod.RuntimePod.IPs)
 	if err != nil {
 		return nil, err
 	}
+	// pod token is only known by node agent and container, instance present it to session service to prove its identity
+	if m.dependencies.PodTokens != nil {
+		envs = append(envs, cruntime.EnvVar{
+			Name:  fornaxv1.LabelFornaxCoreSessionServiceToken,
+			Value: m.dependencies.PodTokens.IssueToken(m.pod.Identifier, string(pod.UID)),
+		})
+	}
 
 	commands := []string{}
 	for _, v := range container.Command {
@@ -122,16 +137,17 @@ func (m *PodActor) generateContainerConfig(container *v1.Container, imageRef *cr
 
 	config := &criv1.ContainerConfig{
 		Metadata: &criv1.ContainerMetadata{
-			Name: container.Name,
+			Name:    container.Name,
+			Attempt: uint32(restartCount),
 		},
 		Image:       &criv1.ImageSpec{Image: imageRef.Id},
 		Command:     commands,
 		Args:        args,
 		WorkingDir:  container.WorkingDir,
 		Labels:      newContainerLabels(container, pod),
-		Annotations: newContainerAnnotations(container, pod, 0, map[string]string{}),
+		Annotations: newContainerAnnotations(container, pod, int(restartCount), map[string]string{}),
 		// Devices:     makeDevices(opts),
-		// Mounts:      makeMounts(opts, container),
+		Mounts:    makeMounts(opts, container),
 		LogPath:   containerLogsPath,
 		Stdin:     container.Stdin,
 		StdinOnce: container.StdinOnce,
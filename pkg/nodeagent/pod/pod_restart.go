@@ -0,0 +1,163 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+	"time"
+
+	podcontainer "centaurusinfra.io/fornax-serverless/pkg/nodeagent/pod/container"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// crash loop backoff of container restart, backoff is doubled on every crash,
+	// and reset if container ran longer than DefaultContainerBackoffResetDuration before it exit
+	DefaultContainerRestartBackoff       = 10 * time.Second
+	DefaultContainerMaxRestartBackoff    = 5 * time.Minute
+	DefaultContainerBackoffResetDuration = 10 * time.Minute
+)
+
+// ContainerRestart is sent by pod actor to itself when restart backoff of a exited container expired
+type ContainerRestart struct {
+	ContainerName string
+}
+
+// shouldRestartContainer check pod restart policy, init containers and containers of a pod being terminated or evacuated are not restarted
+func shouldRestartContainer(pod *types.FornaxPod, container *types.FornaxContainer) bool {
+	if container.InitContainer || pod.Pod.DeletionTimestamp != nil {
+		return false
+	}
+	if pod.FornaxPodState != types.PodStateCreated && pod.FornaxPodState != types.PodStateRunning && pod.FornaxPodState != types.PodStateHibernated {
+		return false
+	}
+	switch pod.Pod.Spec.RestartPolicy {
+	case v1.RestartPolicyAlways:
+		return true
+	case v1.RestartPolicyOnFailure:
+		// container failed liveness or startup probe is still running, it's also treated as failure
+		return !runtime.ContainerExitNormal(container.ContainerStatus)
+	default:
+		return false
+	}
+}
+
+// nextRestartBackoff double previous backoff up to max backoff, backoff is reset if container ran long enough before exit
+func nextRestartBackoff(status *runtime.ContainerStatus) time.Duration {
+	backoff := status.RestartBackoff
+	if rs := status.RuntimeStatus; rs != nil && rs.StartedAt > 0 && rs.FinishedAt > rs.StartedAt {
+		if time.Duration(rs.FinishedAt-rs.StartedAt) > DefaultContainerBackoffResetDuration {
+			backoff = 0
+		}
+	}
+	if backoff == 0 {
+		return DefaultContainerRestartBackoff
+	}
+	backoff = backoff * 2
+	if backoff > DefaultContainerMaxRestartBackoff {
+		backoff = DefaultContainerMaxRestartBackoff
+	}
+	return backoff
+}
+
+// scheduleContainerRestart remove exited container and restart it after backoff, sessions on pod are kept,
+// session service client in container is expected to reconnect and continue sessions which it can recover
+func (a *PodActor) scheduleContainerRestart(container *types.FornaxContainer) error {
+	if container.ContainerStatus == nil {
+		container.ContainerStatus = &runtime.ContainerStatus{}
+	}
+	status := container.ContainerStatus
+	status.RestartBackoff = nextRestartBackoff(status)
+	if status.RuntimeStatus != nil {
+		status.LastTerminationStatus = status.RuntimeStatus
+	}
+	status.RuntimeStatus = nil
+	container.State = types.ContainerStateRestarting
+	klog.InfoS("Restart container after backoff", "pod", types.UniquePodName(a.pod), "container", container.ContainerSpec.Name, "restartCount", status.RestartCount, "backoff", status.RestartBackoff)
+
+	// container failed liveness probe could be still running, remove it now, it's removed again before restart if it failed
+	if err := a.terminateContainer(container); err != nil {
+		klog.ErrorS(err, "Failed to remove exited container, retry before restart", "pod", types.UniquePodName(a.pod), "container", container.ContainerSpec.Name)
+	}
+	a.dependencies.EventRecorder.Eventf(a.pod.Pod, v1.EventTypeWarning, "BackOff", "Back-off %s restarting exited container %s, %s", status.RestartBackoff, container.ContainerSpec.Name, containerExitMessage(container))
+	a.startContainerRestartTimer(container)
+	a.reportPodStatus = true
+	return nil
+}
+
+// containerExitMessage describe exit code and reason of a exited container, last termination status is used if container is restarting
+func containerExitMessage(container *types.FornaxContainer) string {
+	if container.ContainerStatus == nil {
+		return "unknown exit status"
+	}
+	status := container.ContainerStatus.RuntimeStatus
+	if status == nil {
+		status = container.ContainerStatus.LastTerminationStatus
+	}
+	if status == nil {
+		return "unknown exit status"
+	}
+	return fmt.Sprintf("exit code %d, reason %s", status.ExitCode, status.Reason)
+}
+
+func (a *PodActor) startContainerRestartTimer(container *types.FornaxContainer) {
+	name := container.ContainerSpec.Name
+	time.AfterFunc(container.ContainerStatus.RestartBackoff, func() {
+		if !a.stop {
+			a.notify(a.Reference(), ContainerRestart{ContainerName: name})
+		}
+	})
+}
+
+// restartContainer create a new runtime container with same spec in pod sandbox and start a new container actor for it,
+// restart is rescheduled with a longer backoff if container can not be created
+func (a *PodActor) restartContainer(msg ContainerRestart) error {
+	container, found := a.pod.Containers[msg.ContainerName]
+	if !found || container.State != types.ContainerStateRestarting {
+		return nil
+	}
+	if !shouldRestartContainer(a.pod, container) {
+		klog.InfoS("Pod is not running, skip container restart", "pod", types.UniquePodName(a.pod), "container", msg.ContainerName, "podState", a.pod.FornaxPodState)
+		return nil
+	}
+
+	status := container.ContainerStatus
+	if err := a.terminateContainer(container); err != nil {
+		klog.ErrorS(err, "Failed to remove exited container", "pod", types.UniquePodName(a.pod), "container", msg.ContainerName)
+		return a.scheduleContainerRestart(container)
+	}
+	runtimeContainer, err := a.createContainer(a.pod.RuntimePod.SandboxConfig, container.ContainerSpec, a.pod.ImagePullSecrets, status.RestartCount+1)
+	if err != nil {
+		klog.ErrorS(err, "Failed to recreate container", "pod", types.UniquePodName(a.pod), "container", msg.ContainerName)
+		return a.scheduleContainerRestart(container)
+	}
+
+	status.RestartCount += 1
+	container.RuntimeContainer = runtimeContainer
+	container.State = types.ContainerStateCreating
+	a.pod.RuntimePod.Containers[msg.ContainerName] = runtimeContainer.Container
+	klog.InfoS("Restarted container", "pod", types.UniquePodName(a.pod), "container", msg.ContainerName, "restartCount", status.RestartCount)
+
+	actor := podcontainer.NewPodContainerActor(a.Reference(), a.pod, container, a.dependencies)
+	a.containerActors[msg.ContainerName] = actor
+	actor.Start()
+	a.reportPodStatus = true
+	return nil
+}
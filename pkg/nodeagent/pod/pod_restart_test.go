@@ -0,0 +1,98 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"testing"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func exitedContainer(exitCode int32, runDuration time.Duration) *types.FornaxContainer {
+	startedAt := time.Now().Add(-runDuration)
+	return &types.FornaxContainer{
+		ContainerSpec: &v1.Container{Name: "test"},
+		ContainerStatus: &runtime.ContainerStatus{
+			RuntimeStatus: &criv1.ContainerStatus{
+				State:      criv1.ContainerState_CONTAINER_EXITED,
+				ExitCode:   exitCode,
+				StartedAt:  startedAt.UnixNano(),
+				FinishedAt: time.Now().UnixNano(),
+			},
+		},
+	}
+}
+
+func TestShouldRestartContainer(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   v1.RestartPolicy
+		state    types.PodState
+		deleted  bool
+		init     bool
+		exitCode int32
+		restart  bool
+	}{
+		{name: "never", policy: v1.RestartPolicyNever, state: types.PodStateRunning, exitCode: 1, restart: false},
+		{name: "always exit normally", policy: v1.RestartPolicyAlways, state: types.PodStateRunning, exitCode: 0, restart: true},
+		{name: "always exit abnormally", policy: v1.RestartPolicyAlways, state: types.PodStateRunning, exitCode: 1, restart: true},
+		{name: "on failure exit normally", policy: v1.RestartPolicyOnFailure, state: types.PodStateRunning, exitCode: 0, restart: false},
+		{name: "on failure exit abnormally", policy: v1.RestartPolicyOnFailure, state: types.PodStateHibernated, exitCode: 1, restart: true},
+		{name: "init container", policy: v1.RestartPolicyAlways, state: types.PodStateCreating, init: true, exitCode: 1, restart: false},
+		{name: "pod terminating", policy: v1.RestartPolicyAlways, state: types.PodStateTerminating, exitCode: 1, restart: false},
+		{name: "pod evacuating", policy: v1.RestartPolicyAlways, state: types.PodStateEvacuating, exitCode: 1, restart: false},
+		{name: "pod deleted", policy: v1.RestartPolicyAlways, state: types.PodStateRunning, deleted: true, exitCode: 1, restart: false},
+	}
+	for _, test := range tests {
+		pod := &types.FornaxPod{
+			Pod:            &v1.Pod{Spec: v1.PodSpec{RestartPolicy: test.policy}},
+			FornaxPodState: test.state,
+		}
+		if test.deleted {
+			now := metav1.Now()
+			pod.Pod.DeletionTimestamp = &now
+		}
+		container := exitedContainer(test.exitCode, time.Second)
+		container.InitContainer = test.init
+		if restart := shouldRestartContainer(pod, container); restart != test.restart {
+			t.Errorf("%s: expect restart %v, got %v", test.name, test.restart, restart)
+		}
+	}
+}
+
+func TestNextRestartBackoff(t *testing.T) {
+	container := exitedContainer(1, time.Second)
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 80 * time.Second, 160 * time.Second, 5 * time.Minute, 5 * time.Minute}
+	for i, e := range expected {
+		container.ContainerStatus.RestartBackoff = nextRestartBackoff(container.ContainerStatus)
+		if container.ContainerStatus.RestartBackoff != e {
+			t.Errorf("restart %d: expect backoff %s, got %s", i, e, container.ContainerStatus.RestartBackoff)
+		}
+	}
+
+	// container ran long enough reset backoff
+	longRun := exitedContainer(1, DefaultContainerBackoffResetDuration+time.Minute)
+	longRun.ContainerStatus.RestartBackoff = DefaultContainerMaxRestartBackoff
+	if backoff := nextRestartBackoff(longRun.ContainerStatus); backoff != DefaultContainerRestartBackoff {
+		t.Errorf("expect backoff reset to %s, got %s", DefaultContainerRestartBackoff, backoff)
+	}
+}
@@ -18,6 +18,7 @@ package pod
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
@@ -25,6 +26,7 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
 var (
@@ -76,6 +78,10 @@ func SetPodStatus(fppod *types.FornaxPod, node *v1.Node) {
 		// post metrics pod start time - pod create time
 	}
 
+	// container status, including restart count
+	podStatus.InitContainerStatuses = GetContainerStatuses(fppod, fppod.Pod.Spec.InitContainers)
+	podStatus.ContainerStatuses = GetContainerStatuses(fppod, fppod.Pod.Spec.Containers)
+
 	//TODO
 	// add resource status
 
@@ -94,6 +100,8 @@ func ToV1PodPhase(fppod *types.FornaxPod) v1.PodPhase {
 		podPhase = v1.PodRunning
 	case types.PodStateHibernated:
 		podPhase = v1.PodRunning
+	case types.PodStateEvacuating:
+		podPhase = v1.PodRunning
 	case types.PodStateTerminating:
 		podPhase = v1.PodUnknown
 	case types.PodStateCleanup:
@@ -125,6 +133,63 @@ func ToV1PodPhase(fppod *types.FornaxPod) v1.PodPhase {
 	return podPhase
 }
 
+func GetContainerStatuses(fppod *types.FornaxPod, containers []v1.Container) []v1.ContainerStatus {
+	statuses := []v1.ContainerStatus{}
+	for _, spec := range containers {
+		status := v1.ContainerStatus{
+			Name:  spec.Name,
+			Image: spec.Image,
+			State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+		}
+		container, found := fppod.Containers[spec.Name]
+		if !found {
+			statuses = append(statuses, status)
+			continue
+		}
+		if container.RuntimeContainer != nil {
+			status.ContainerID = container.RuntimeContainer.Id
+		}
+		if cs := container.ContainerStatus; cs != nil {
+			status.RestartCount = cs.RestartCount
+			if cs.RuntimeStatus != nil {
+				status.State = ToV1ContainerState(cs.RuntimeStatus)
+			}
+			if cs.LastTerminationStatus != nil {
+				status.LastTerminationState = ToV1ContainerState(cs.LastTerminationStatus)
+			}
+			if container.State == types.ContainerStateRestarting {
+				status.State = v1.ContainerState{Waiting: &v1.ContainerStateWaiting{
+					Reason:  "CrashLoopBackOff",
+					Message: fmt.Sprintf("back-off %s restarting failed container", cs.RestartBackoff),
+				}}
+			}
+		}
+		status.Ready = runtime.ContainerRunning(container.ContainerStatus) && (container.State == types.ContainerStateRunning || container.State == types.ContainerStateHibernated)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func ToV1ContainerState(status *criv1.ContainerStatus) v1.ContainerState {
+	switch status.State {
+	case criv1.ContainerState_CONTAINER_RUNNING:
+		return v1.ContainerState{Running: &v1.ContainerStateRunning{
+			StartedAt: metav1.NewTime(time.Unix(0, status.StartedAt)),
+		}}
+	case criv1.ContainerState_CONTAINER_EXITED:
+		return v1.ContainerState{Terminated: &v1.ContainerStateTerminated{
+			ExitCode:    status.ExitCode,
+			Reason:      status.Reason,
+			Message:     status.Message,
+			StartedAt:   metav1.NewTime(time.Unix(0, status.StartedAt)),
+			FinishedAt:  metav1.NewTime(time.Unix(0, status.FinishedAt)),
+			ContainerID: status.Id,
+		}}
+	default:
+		return v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "ContainerCreating"}}
+	}
+}
+
 func GetPodConditions(fppod *types.FornaxPod) []v1.PodCondition {
 	conditions := map[v1.PodConditionType]*v1.PodCondition{}
 
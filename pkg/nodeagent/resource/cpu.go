@@ -17,99 +17,75 @@ limitations under the License.
 package resource
 
 import (
-	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/cadvisor"
 	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
-	cadvisorinfov1 "github.com/google/cadvisor/info/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var _ ResoureManager = &CPUManager{}
 
+// CPUManager account cpu allocated to pods, cpu reserved for system and node agent are not allocatable,
+// if reserved system cpus are more than reserved cpu quantity, reserved system cpus are used
 type CPUManager struct {
-	channel               chan cadvisor.NodeCAdvisorInfo
-	advisor               cadvisor.CAdvisorInfoProvider
-	MachineInfo           cadvisorinfov1.MachineInfo
-	ReservedSystemCPUs    resource.Quantity
-	ReservedNodeAgentCPUs resource.Quantity
+	cpu *resourceAccounting
 }
 
-func NewCpuManager(nodeConfig config.NodeConfiguration, advisor cadvisor.CAdvisorInfoProvider) *CPUManager {
-	manager := &CPUManager{
-		channel: make(chan cadvisor.NodeCAdvisorInfo),
-		advisor: advisor,
+func NewCpuManager(nodeConfig config.NodeConfiguration) *CPUManager {
+	reserved := reservedQuantity(v1.ResourceCPU, nodeConfig.SystemReserved, nodeConfig.NodeAgentReserved)
+	// cpu capacity is counted as milli cores, see ResourceListFromMachineInfo
+	if reservedCPUs := util.ResourceQuantity(int64(nodeConfig.ReservedSystemCPUs.Size()*1000), v1.ResourceCPU); reservedCPUs.Cmp(reserved) > 0 {
+		reserved = reservedCPUs
 	}
-
-	nodeCAdvisorInfo, err := advisor.GetNodeCAdvisorInfo()
-	if err != nil {
-		return nil
-	} else {
-		manager.MachineInfo = *nodeCAdvisorInfo.MachineInfo.Clone()
+	return &CPUManager{
+		cpu: newResourceAccounting(v1.ResourceCPU, reserved),
 	}
-
-	advisor.ReceiveCAdvisorInfo("CPUManager", &manager.channel)
-	return manager
 }
 
-func (m *CPUManager) Start() error {
-	go func() {
-		// update memory information with received machineInfo
-
-	}()
-	return nil
+// SetCapacity implements ResoureManager
+func (m *CPUManager) SetCapacity(capacity v1.ResourceList) {
+	m.cpu.setCapacity(capacity)
 }
 
 // GetReservedResource implements ResoureManager
-func (*CPUManager) GetReservedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *CPUManager) GetReservedResource() NodeResource {
+	return m.cpu.reservedResource()
 }
 
 // GetAllocatedResource implements ResoureManager
-func (*CPUManager) GetAllocatedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *CPUManager) GetAllocatedResource() NodeResource {
+	return m.cpu.allocatedResource()
 }
 
 // GetAvailableResource implements ResoureManager
-func (*CPUManager) GetAvailableResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *CPUManager) GetAvailableResource() NodeResource {
+	return m.cpu.availableResource()
 }
 
 // GetPodResource implements ResoureManager
-func (*CPUManager) GetPodResource(v1.Pod) PodResource {
-	return PodResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
-}
-
-// ReserveResource implements ResoureManager
-func (*CPUManager) ReserveResource(NodeResource) bool {
-	panic("unimplemented")
+func (m *CPUManager) GetPodResource(pod v1.Pod) PodResource {
+	return m.cpu.podResource(&pod)
 }
 
-// Admit implements ResoureManager
-func (*CPUManager) DryRunAdmit(v1.Pod) error {
-	panic("unimplemented")
+// DryRunAdmit implements ResoureManager
+func (m *CPUManager) DryRunAdmit(pod v1.Pod) error {
+	return m.cpu.dryRunAdmit(&pod)
 }
 
 // Admit implements ResoureManager
-func (*CPUManager) Admit(v1.Pod) error {
-	panic("unimplemented")
+func (m *CPUManager) Admit(pod v1.Pod) error {
+	return m.cpu.admit(&pod)
 }
 
 // Allocate implements ResoureManager
-func (*CPUManager) Allocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *CPUManager) Allocate(pod v1.Pod) error {
+	m.cpu.allocate(&pod)
+	return nil
 }
 
 // Deallocate implements ResoureManager
-func (*CPUManager) Deallocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *CPUManager) Deallocate(pod v1.Pod) error {
+	m.cpu.deallocate(&pod)
+	return nil
 }
 
 // reference
@@ -17,66 +17,68 @@ limitations under the License.
 package resource
 
 import (
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var _ ResoureManager = &MemoryManager{}
 
+// MemoryManager account memory allocated to pods, memory reserved for system and node agent are not allocatable
 type MemoryManager struct {
+	memory *resourceAccounting
 }
 
-// GetReservedResource implements ResoureManager
-func (*MemoryManager) GetReservedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
+func NewMemoryManager(nodeConfig config.NodeConfiguration) *MemoryManager {
+	return &MemoryManager{
+		memory: newResourceAccounting(v1.ResourceMemory, reservedQuantity(v1.ResourceMemory, nodeConfig.SystemReserved, nodeConfig.NodeAgentReserved)),
 	}
 }
 
+// SetCapacity implements ResoureManager
+func (m *MemoryManager) SetCapacity(capacity v1.ResourceList) {
+	m.memory.setCapacity(capacity)
+}
+
+// GetReservedResource implements ResoureManager
+func (m *MemoryManager) GetReservedResource() NodeResource {
+	return m.memory.reservedResource()
+}
+
 // GetAllocatedResource implements ResoureManager
-func (*MemoryManager) GetAllocatedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *MemoryManager) GetAllocatedResource() NodeResource {
+	return m.memory.allocatedResource()
 }
 
 // GetAvailableResource implements ResoureManager
-func (*MemoryManager) GetAvailableResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *MemoryManager) GetAvailableResource() NodeResource {
+	return m.memory.availableResource()
 }
 
 // GetPodResource implements ResoureManager
-func (*MemoryManager) GetPodResource(v1.Pod) PodResource {
-	return PodResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
-}
-
-// ReserveResource implements ResoureManager
-func (*MemoryManager) ReserveResource(NodeResource) bool {
-	panic("unimplemented")
+func (m *MemoryManager) GetPodResource(pod v1.Pod) PodResource {
+	return m.memory.podResource(&pod)
 }
 
 // DryRunAdmit implements ResoureManager
-func (*MemoryManager) DryRunAdmit(v1.Pod) error {
-	panic("unimplemented")
+func (m *MemoryManager) DryRunAdmit(pod v1.Pod) error {
+	return m.memory.dryRunAdmit(&pod)
 }
 
 // Admit implements ResoureManager
-func (*MemoryManager) Admit(v1.Pod) error {
-	panic("unimplemented")
+func (m *MemoryManager) Admit(pod v1.Pod) error {
+	return m.memory.admit(&pod)
 }
 
 // Allocate implements ResoureManager
-func (*MemoryManager) Allocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *MemoryManager) Allocate(pod v1.Pod) error {
+	m.memory.allocate(&pod)
+	return nil
 }
 
 // Deallocate implements ResoureManager
-func (*MemoryManager) Deallocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *MemoryManager) Deallocate(pod v1.Pod) error {
+	m.memory.deallocate(&pod)
+	return nil
 }
 
 // reference
@@ -0,0 +1,105 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"testing"
+
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+)
+
+func newTestPod(name string, memory string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{
+				Name:      "init",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("100Mi")}},
+			}},
+			Containers: []v1.Container{{
+				Name:      "app",
+				Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse(memory)}},
+			}},
+		},
+	}
+}
+
+func TestMemoryManagerAdmission(t *testing.T) {
+	nodeConfig := config.NodeConfiguration{
+		SystemReserved:    v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+		NodeAgentReserved: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	m := NewMemoryManager(nodeConfig)
+	m.SetCapacity(v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")})
+
+	available := m.GetAvailableResource().Resources[v1.ResourceMemory]
+	if available.Cmp(resource.MustParse("2560Mi")) != 0 {
+		t.Fatalf("expect reserved memory not available, got %s", available.String())
+	}
+
+	// limit is used as request if request is not set
+	if err := m.Admit(newTestPod("pod1", "2Gi")); err != nil {
+		t.Fatalf("expect pod admitted, got %v", err)
+	}
+	err := m.DryRunAdmit(newTestPod("pod2", "1Gi"))
+	if resourceErr, ok := err.(*InsufficientResourceError); !ok || resourceErr.Reason() != "OutOfmemory" {
+		t.Fatalf("expect pod rejected for insufficient memory, got %v", err)
+	}
+	// a admitted pod is not rejected again
+	if err := m.DryRunAdmit(newTestPod("pod1", "2Gi")); err != nil {
+		t.Errorf("expect admitted pod fit, got %v", err)
+	}
+
+	m.Deallocate(newTestPod("pod1", "2Gi"))
+	if err := m.Admit(newTestPod("pod2", "1Gi")); err != nil {
+		t.Errorf("expect pod admitted after memory deallocated, got %v", err)
+	}
+	allocated := m.GetAllocatedResource().Resources[v1.ResourceMemory]
+	if allocated.Cmp(resource.MustParse("1Gi")) != 0 {
+		t.Errorf("expect 1Gi memory allocated, got %s", allocated.String())
+	}
+}
+
+func TestPodResourceRequest(t *testing.T) {
+	pod := newTestPod("pod", "50Mi")
+	// init container request is bigger than sum of container requests
+	if request := PodResourceRequest(&pod, v1.ResourceMemory); request.Cmp(resource.MustParse("100Mi")) != 0 {
+		t.Errorf("expect init container request used, got %s", request.String())
+	}
+	pod.Spec.Containers[0].Resources.Requests = v1.ResourceList{v1.ResourceEphemeralStorage: resource.MustParse("1Gi")}
+	pod.Spec.Containers[0].Resources.Limits = v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}
+	if request := PodResourceRequest(&pod, v1.ResourceStorage, v1.ResourceEphemeralStorage); request.Cmp(resource.MustParse("2Gi")) != 0 {
+		t.Errorf("expect storage and ephemeral storage counted together, got %s", request.String())
+	}
+}
+
+func TestCPUManagerReservedCPUs(t *testing.T) {
+	nodeConfig := config.NodeConfiguration{
+		SystemReserved:     v1.ResourceList{v1.ResourceCPU: resource.MustParse("500")},
+		ReservedSystemCPUs: cpuset.NewCPUSet(0, 1),
+	}
+	m := NewCpuManager(nodeConfig)
+	reserved := m.GetReservedResource().Resources[v1.ResourceCPU]
+	if reserved.Cmp(resource.MustParse("2000")) != 0 {
+		t.Errorf("expect reserved cpus used as reservation, got %s", reserved.String())
+	}
+}
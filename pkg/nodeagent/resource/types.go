@@ -18,14 +18,19 @@ package resource
 
 import (
 	"fmt"
+	"sync"
 
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 	cadvisorinfov1 "github.com/google/cadvisor/info/v1"
 	cadvisorinfov2 "github.com/google/cadvisor/info/v2"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
+// ResoureManager account node resource reserved for system and node agent and allocated to pods,
+// pods are admitted only when their requests fit into available resource
 type ResoureManager interface {
+	SetCapacity(v1.ResourceList)
 	GetAvailableResource() NodeResource
 	GetAllocatedResource() NodeResource
 	GetReservedResource() NodeResource
@@ -36,6 +41,22 @@ type ResoureManager interface {
 	Deallocate(v1.Pod) error
 }
 
+// InsufficientResourceError is returned when a pod request more resource than available on node
+type InsufficientResourceError struct {
+	ResourceName v1.ResourceName
+	Requested    resource.Quantity
+	Available    resource.Quantity
+}
+
+func (e *InsufficientResourceError) Error() string {
+	return fmt.Sprintf("Insufficient %s, requested %s, available %s", e.ResourceName, e.Requested.String(), e.Available.String())
+}
+
+// Reason return reason of a pod rejected by node, e.g. OutOfcpu
+func (e *InsufficientResourceError) Reason() string {
+	return fmt.Sprintf("OutOf%s", e.ResourceName)
+}
+
 type NodeResource struct {
 	Resources v1.ResourceList
 }
@@ -67,3 +88,165 @@ func EphemeralResourceListFromFsInfo(info *cadvisorinfov2.FsInfo) v1.ResourceLis
 	}
 	return resources
 }
+
+// PodResourceRequest return effective request of a pod for a resource, which is the bigger one of
+// sum of container requests and max of init container requests, limit is used if request is not set,
+// resources with alias names, e.g. storage and ephemeral storage, are counted together
+func PodResourceRequest(pod *v1.Pod, resourceName v1.ResourceName, aliasNames ...v1.ResourceName) resource.Quantity {
+	containerRequest := func(container *v1.Container) resource.Quantity {
+		quantity := util.ResourceQuantity(0, resourceName)
+		for _, name := range append([]v1.ResourceName{resourceName}, aliasNames...) {
+			if q, found := container.Resources.Requests[name]; found {
+				quantity.Add(q)
+			} else if q, found := container.Resources.Limits[name]; found {
+				quantity.Add(q)
+			}
+		}
+		return quantity
+	}
+
+	request := util.ResourceQuantity(0, resourceName)
+	for i := range pod.Spec.Containers {
+		request.Add(containerRequest(&pod.Spec.Containers[i]))
+	}
+	for i := range pod.Spec.InitContainers {
+		if q := containerRequest(&pod.Spec.InitContainers[i]); q.Cmp(request) > 0 {
+			request = q
+		}
+	}
+	return request
+}
+
+// resourceAccounting track capacity, reservation and pod allocations of one resource on node
+type resourceAccounting struct {
+	mu           sync.RWMutex
+	resourceName v1.ResourceName
+	aliasNames   []v1.ResourceName
+	capacity     resource.Quantity
+	reserved     resource.Quantity
+	allocations  map[string]resource.Quantity
+}
+
+func newResourceAccounting(resourceName v1.ResourceName, reserved resource.Quantity, aliasNames ...v1.ResourceName) *resourceAccounting {
+	return &resourceAccounting{
+		mu:           sync.RWMutex{},
+		resourceName: resourceName,
+		aliasNames:   aliasNames,
+		capacity:     util.ResourceQuantity(0, resourceName),
+		reserved:     reserved,
+		allocations:  map[string]resource.Quantity{},
+	}
+}
+
+func (a *resourceAccounting) setCapacity(resources v1.ResourceList) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if q, found := resources[a.resourceName]; found {
+		a.capacity = q.DeepCopy()
+	}
+}
+
+func (a *resourceAccounting) reservedResource() NodeResource {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return NodeResource{
+		Resources: v1.ResourceList{a.resourceName: a.reserved.DeepCopy()},
+	}
+}
+
+func (a *resourceAccounting) allocatedResource() NodeResource {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return NodeResource{
+		Resources: v1.ResourceList{a.resourceName: a._allocatedNoLock()},
+	}
+}
+
+func (a *resourceAccounting) availableResource() NodeResource {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return NodeResource{
+		Resources: v1.ResourceList{a.resourceName: a._availableNoLock()},
+	}
+}
+
+func (a *resourceAccounting) podResource(pod *v1.Pod) PodResource {
+	return PodResource{
+		Resources: v1.ResourceList{a.resourceName: PodResourceRequest(pod, a.resourceName, a.aliasNames...)},
+	}
+}
+
+func (a *resourceAccounting) _allocatedNoLock() resource.Quantity {
+	allocated := util.ResourceQuantity(0, a.resourceName)
+	for _, q := range a.allocations {
+		allocated.Add(q)
+	}
+	return allocated
+}
+
+// available resource is capacity minus reservation and allocations, it's not negative
+func (a *resourceAccounting) _availableNoLock() resource.Quantity {
+	available := a.capacity.DeepCopy()
+	available.Sub(a.reserved)
+	available.Sub(a._allocatedNoLock())
+	if available.Sign() < 0 {
+		available.Set(0)
+	}
+	return available
+}
+
+func (a *resourceAccounting) _dryRunAdmitNoLock(pod *v1.Pod) error {
+	request := PodResourceRequest(pod, a.resourceName, a.aliasNames...)
+	if request.Sign() == 0 {
+		return nil
+	}
+	available := a._availableNoLock()
+	// a pod already allocated is checked as if it's not allocated
+	if q, found := a.allocations[util.Name(pod)]; found {
+		available.Add(q)
+	}
+	if request.Cmp(available) > 0 {
+		return &InsufficientResourceError{ResourceName: a.resourceName, Requested: request, Available: available}
+	}
+	return nil
+}
+
+func (a *resourceAccounting) dryRunAdmit(pod *v1.Pod) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._dryRunAdmitNoLock(pod)
+}
+
+func (a *resourceAccounting) admit(pod *v1.Pod) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a._dryRunAdmitNoLock(pod); err != nil {
+		return err
+	}
+	a.allocations[util.Name(pod)] = PodResourceRequest(pod, a.resourceName, a.aliasNames...)
+	return nil
+}
+
+// allocate record pod request without admission, it's used for pods already running on node
+func (a *resourceAccounting) allocate(pod *v1.Pod) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.allocations[util.Name(pod)] = PodResourceRequest(pod, a.resourceName, a.aliasNames...)
+}
+
+func (a *resourceAccounting) deallocate(pod *v1.Pod) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.allocations, util.Name(pod))
+}
+
+// reservedQuantity sum reservations of a resource
+func reservedQuantity(resourceName v1.ResourceName, reservations ...v1.ResourceList) resource.Quantity {
+	reserved := util.ResourceQuantity(0, resourceName)
+	for _, r := range reservations {
+		if q, found := r[resourceName]; found {
+			reserved.Add(q)
+		}
+	}
+	return reserved
+}
@@ -17,74 +17,71 @@ limitations under the License.
 package resource
 
 import (
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 var _ ResoureManager = &VolumeManager{}
 
+// VolumeManager account storage allocated to pods, storage and ephemeral storage requests are counted together,
+// storage reserved for system and node agent are not allocatable
 type VolumeManager struct {
+	storage *resourceAccounting
 }
 
-func (*VolumeManager) UnmountPodVolume(*v1.Pod) error {
-	return nil
+func NewVolumeManager(nodeConfig config.NodeConfiguration) *VolumeManager {
+	reserved := reservedQuantity(v1.ResourceStorage, nodeConfig.SystemReserved, nodeConfig.NodeAgentReserved)
+	reserved.Add(reservedQuantity(v1.ResourceEphemeralStorage, nodeConfig.SystemReserved, nodeConfig.NodeAgentReserved))
+	return &VolumeManager{
+		storage: newResourceAccounting(v1.ResourceStorage, reserved, v1.ResourceEphemeralStorage),
+	}
 }
 
-func (*VolumeManager) WaitForAttachAndMount(*v1.Pod) error {
-	return nil
+// SetCapacity implements ResoureManager
+func (m *VolumeManager) SetCapacity(capacity v1.ResourceList) {
+	m.storage.setCapacity(capacity)
 }
 
 // GetReservedResource implements ResoureManager
-func (*VolumeManager) GetReservedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *VolumeManager) GetReservedResource() NodeResource {
+	return m.storage.reservedResource()
 }
 
 // GetAllocatedResource implements ResoureManager
-func (*VolumeManager) GetAllocatedResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *VolumeManager) GetAllocatedResource() NodeResource {
+	return m.storage.allocatedResource()
 }
 
 // GetAvailableResource implements ResoureManager
-func (*VolumeManager) GetAvailableResource() NodeResource {
-	return NodeResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
+func (m *VolumeManager) GetAvailableResource() NodeResource {
+	return m.storage.availableResource()
 }
 
 // GetPodResource implements ResoureManager
-func (*VolumeManager) GetPodResource(v1.Pod) PodResource {
-	return PodResource{
-		Resources: map[v1.ResourceName]resource.Quantity{},
-	}
-}
-
-// ReserveResource implements ResoureManager
-func (*VolumeManager) ReserveResource(NodeResource) bool {
-	panic("unimplemented")
+func (m *VolumeManager) GetPodResource(pod v1.Pod) PodResource {
+	return m.storage.podResource(&pod)
 }
 
 // DryRunAdmit implements ResoureManager
-func (*VolumeManager) DryRunAdmit(v1.Pod) error {
-	panic("unimplemented")
+func (m *VolumeManager) DryRunAdmit(pod v1.Pod) error {
+	return m.storage.dryRunAdmit(&pod)
 }
 
 // Admit implements ResoureManager
-func (*VolumeManager) Admit(v1.Pod) error {
-	panic("unimplemented")
+func (m *VolumeManager) Admit(pod v1.Pod) error {
+	return m.storage.admit(&pod)
 }
 
 // Allocate implements ResoureManager
-func (*VolumeManager) Allocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *VolumeManager) Allocate(pod v1.Pod) error {
+	m.storage.allocate(&pod)
+	return nil
 }
 
 // Deallocate implements ResoureManager
-func (*VolumeManager) Deallocate(v1.Pod) error {
-	panic("unimplemented")
+func (m *VolumeManager) Deallocate(pod v1.Pod) error {
+	m.storage.deallocate(&pod)
+	return nil
 }
 
 //
@@ -17,90 +17,553 @@ limitations under the License.
 package runtime
 
 import (
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
 )
 
+const (
+	FakeRuntimeName    = "fake"
+	FakeRuntimeVersion = "0.1.0"
+)
+
 var _ RuntimeService = &FakeRuntimeService{}
 
-type FakeRuntimeService struct{}
+type fakeSandbox struct {
+	sandbox *criv1.PodSandbox
+	ip      string
+}
+
+type fakeContainer struct {
+	config     *criv1.ContainerConfig
+	container  *criv1.Container
+	status     *criv1.ContainerStatus
+	hibernated bool
+	// container exit by itself when it has run this long, zero means it runs until stopped
+	lifetime time.Duration
+	exitCode int32
+}
+
+type fakeContainerLifetime struct {
+	lifetime time.Duration
+	exitCode int32
+}
+
+// FakeRuntimeService is a in memory RuntimeService which keep sandboxes and containers and their states like a container runtime,
+// tests script it to simulate container exits, runtime failures and latencies of runtime calls.
+// methods are identified by RuntimeService method name when inject errors and latencies, e.g. "CreateSandbox"
+type FakeRuntimeService struct {
+	mu         sync.Mutex
+	seq        int64
+	podCidrs   []string
+	sandboxes  map[string]*fakeSandbox
+	containers map[string]*fakeContainer
+	// errors returned by next calls of a method in order, a call without injected error proceed normally
+	errors    map[string][]error
+	latencies map[string]time.Duration
+	calls     map[string]int
+	// containers created with these names exit by themselves after running for a while
+	lifetimes map[string]fakeContainerLifetime
+	// ExecFunc simulate command executed in a running container, exec succeed with empty output if it's nil
+	ExecFunc func(containerID string, cmd []string) ([]byte, []byte, error)
+}
+
+// InjectError make next call of method fail with err, errors injected for a method are returned in order
+func (f *FakeRuntimeService) InjectError(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[method] = append(f.errors[method], err)
+}
+
+// SetLatency make every call of method take latency before it proceed
+func (f *FakeRuntimeService) SetLatency(method string, latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latencies[method] = latency
+}
+
+// SetContainerLifetime make containers created afterwards with this container name exit with exitCode after they run for lifetime
+func (f *FakeRuntimeService) SetContainerLifetime(containerName string, lifetime time.Duration, exitCode int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lifetimes[containerName] = fakeContainerLifetime{lifetime: lifetime, exitCode: exitCode}
+}
+
+// ExitContainer simulate a running container exit by itself with exitCode
+func (f *FakeRuntimeService) ExitContainer(containerID string, exitCode int32) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return err
+	}
+	if c.status.State != criv1.ContainerState_CONTAINER_RUNNING {
+		return fmt.Errorf("container %s is not running", containerID)
+	}
+	f.exitContainer(c, exitCode, time.Now())
+	return nil
+}
+
+// Calls return how many times a method has been called
+func (f *FakeRuntimeService) Calls(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+// IsHibernated tell if a container is hibernated
+func (f *FakeRuntimeService) IsHibernated(containerID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, found := f.containers[containerID]
+	return found && c.hibernated
+}
+
+// call record a method call, wait for method latency and return a injected error if there is one
+func (f *FakeRuntimeService) call(method string) error {
+	f.mu.Lock()
+	f.calls[method] += 1
+	latency := f.latencies[method]
+	var err error
+	if errs := f.errors[method]; len(errs) > 0 {
+		err = errs[0]
+		f.errors[method] = errs[1:]
+	}
+	f.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	return err
+}
 
-// HibernateContainer implements RuntimeService
-func (*FakeRuntimeService) HibernateContainer(containerID string) error {
-	panic("unimplemented")
+func (f *FakeRuntimeService) nextId(prefix string) string {
+	f.seq += 1
+	return fmt.Sprintf("%s-%016x", prefix, f.seq)
+}
+
+func (f *FakeRuntimeService) getSandbox(podSandboxID string) (*fakeSandbox, error) {
+	s, found := f.sandboxes[podSandboxID]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "pod sandbox %s not found", podSandboxID)
+	}
+	return s, nil
+}
+
+// getContainer find a container and bring its state up to date, a container whose lifetime passed has exited
+func (f *FakeRuntimeService) getContainer(containerID string) (*fakeContainer, error) {
+	c, found := f.containers[containerID]
+	if !found {
+		return nil, status.Errorf(codes.NotFound, "container %s not found", containerID)
+	}
+	if c.status.State == criv1.ContainerState_CONTAINER_RUNNING && c.lifetime > 0 {
+		if exitAt := time.Unix(0, c.status.StartedAt).Add(c.lifetime); !exitAt.After(time.Now()) {
+			f.exitContainer(c, c.exitCode, exitAt)
+		}
+	}
+	return c, nil
+}
+
+func (f *FakeRuntimeService) exitContainer(c *fakeContainer, exitCode int32, finishedAt time.Time) {
+	c.hibernated = false
+	c.status.State = criv1.ContainerState_CONTAINER_EXITED
+	c.status.FinishedAt = finishedAt.UnixNano()
+	c.status.ExitCode = exitCode
+	if exitCode == 0 {
+		c.status.Reason = "Completed"
+	} else {
+		c.status.Reason = "Error"
+	}
+	c.container.State = c.status.State
+}
+
+func (f *FakeRuntimeService) runtimePod(s *fakeSandbox, includeContainers bool) *Pod {
+	pod := &Pod{
+		Id:         s.sandbox.Id,
+		IPs:        []string{s.ip},
+		Sandbox:    copySandbox(s.sandbox),
+		Containers: map[string]*criv1.Container{},
+	}
+	if includeContainers {
+		for id := range f.containers {
+			c, _ := f.getContainer(id)
+			if c.container.PodSandboxId == s.sandbox.Id {
+				pod.Containers[c.container.GetMetadata().GetName()] = copyContainer(c.container)
+			}
+		}
+	}
+	return pod
+}
+
+// UpdatePodCidr implements RuntimeService
+func (f *FakeRuntimeService) UpdatePodCidr(podCidrs []string) error {
+	if err := f.call("UpdatePodCidr"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.podCidrs = podCidrs
+	return nil
+}
+
+// HibernateContainer implements RuntimeService, only a running container can be hibernated
+func (f *FakeRuntimeService) HibernateContainer(containerID string) error {
+	if err := f.call("HibernateContainer"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return err
+	}
+	if c.status.State != criv1.ContainerState_CONTAINER_RUNNING {
+		return fmt.Errorf("container %s is not running, can not hibernate", containerID)
+	}
+	c.hibernated = true
+	return nil
 }
 
 // WakeupContainer implements RuntimeService
-func (*FakeRuntimeService) WakeupContainer(containerID string) error {
-	panic("unimplemented")
+func (f *FakeRuntimeService) WakeupContainer(containerID string) error {
+	if err := f.call("WakeupContainer"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return err
+	}
+	c.hibernated = false
+	return nil
 }
 
-// StopContainer implements RuntimeService
-func (*FakeRuntimeService) StopContainer(containerID string, gracePeriod time.Duration) error {
-	panic("unimplemented")
+// StopContainer implements RuntimeService, stopping a missing container is not a error like remote runtime
+func (f *FakeRuntimeService) StopContainer(containerID string, gracePeriod time.Duration) error {
+	if err := f.call("StopContainer"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return nil
+	}
+	if c.status.State == criv1.ContainerState_CONTAINER_RUNNING {
+		f.exitContainer(c, 0, time.Now())
+	}
+	return nil
 }
 
 // GetPodSandbox implements RuntimeService
-func (*FakeRuntimeService) GetPodSandbox(podSandboxID string) (*criv1.PodSandbox, error) {
-	panic("unimplemented")
+func (f *FakeRuntimeService) GetPodSandbox(podSandboxID string) (*criv1.PodSandbox, error) {
+	if err := f.call("GetPodSandbox"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, found := f.sandboxes[podSandboxID]
+	if !found {
+		return nil, nil
+	}
+	return copySandbox(s.sandbox), nil
 }
 
-// GetPodStatus implements RuntimeService
-func (*FakeRuntimeService) GetPodStatus(podSandboxID string, containerIDs []string) (*PodStatus, error) {
-	panic("unimplemented")
+// GetPodStatus implements RuntimeService, it return nil if sandbox does not exist and skip missing containers
+func (f *FakeRuntimeService) GetPodStatus(podSandboxID string, containerIDs []string) (*PodStatus, error) {
+	if err := f.call("GetPodStatus"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.getSandbox(podSandboxID)
+	if err != nil {
+		return nil, nil
+	}
+	podStatus := &PodStatus{
+		SandboxStatus: &criv1.PodSandboxStatus{
+			Id:          s.sandbox.Id,
+			Metadata:    s.sandbox.Metadata,
+			State:       s.sandbox.State,
+			CreatedAt:   s.sandbox.CreatedAt,
+			Network:     &criv1.PodSandboxNetworkStatus{Ip: s.ip},
+			Labels:      s.sandbox.Labels,
+			Annotations: s.sandbox.Annotations,
+		},
+		ContainerStatuses: map[string]*criv1.ContainerStatus{},
+	}
+	for _, id := range containerIDs {
+		if c, err := f.getContainer(id); err == nil {
+			podStatus.ContainerStatuses[id] = copyContainerStatus(c.status)
+		}
+	}
+	return podStatus, nil
 }
 
-func (*FakeRuntimeService) ExecCommand(containerID string, cmd []string, timeout time.Duration) ([]byte, []byte, error) {
-	panic("unimplemented")
+// ExecCommand implements RuntimeService, command is executed by ExecFunc in a running container
+func (f *FakeRuntimeService) ExecCommand(containerID string, cmd []string, timeout time.Duration) ([]byte, []byte, error) {
+	if err := f.call("ExecCommand"); err != nil {
+		return nil, nil, err
+	}
+	f.mu.Lock()
+	c, err := f.getContainer(containerID)
+	if err == nil && (c.status.State != criv1.ContainerState_CONTAINER_RUNNING || c.hibernated) {
+		err = fmt.Errorf("container %s is not running", containerID)
+	}
+	exec := f.ExecFunc
+	f.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	if exec == nil {
+		return []byte{}, []byte{}, nil
+	}
+	return exec(containerID, cmd)
 }
 
-// CreateContainer implements RuntimeService
-func (*FakeRuntimeService) CreateContainer(podSandboxID string, containerConfig *criv1.ContainerConfig, podSandboxConfig *criv1.PodSandboxConfig) (*Container, error) {
-	panic("unimplemented")
+// CreateContainer implements RuntimeService, container is created in a ready sandbox and its name and attempt must be unique in sandbox
+func (f *FakeRuntimeService) CreateContainer(podSandboxID string, containerConfig *criv1.ContainerConfig, podSandboxConfig *criv1.PodSandboxConfig) (*Container, error) {
+	if err := f.call("CreateContainer"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.getSandbox(podSandboxID)
+	if err != nil {
+		return nil, err
+	}
+	if s.sandbox.State != criv1.PodSandboxState_SANDBOX_READY {
+		return nil, fmt.Errorf("pod sandbox %s is not ready", podSandboxID)
+	}
+	for _, v := range f.containers {
+		if v.container.PodSandboxId == podSandboxID &&
+			v.container.GetMetadata().GetName() == containerConfig.GetMetadata().GetName() &&
+			v.container.GetMetadata().GetAttempt() == containerConfig.GetMetadata().GetAttempt() {
+			return nil, status.Errorf(codes.AlreadyExists, "container %s already exist in pod sandbox %s", containerConfig.GetMetadata().GetName(), podSandboxID)
+		}
+	}
+
+	id := f.nextId("container")
+	now := time.Now().UnixNano()
+	container := &criv1.Container{
+		Id:           id,
+		PodSandboxId: podSandboxID,
+		Metadata:     containerConfig.GetMetadata(),
+		Image:        containerConfig.GetImage(),
+		ImageRef:     containerConfig.GetImage().GetImage(),
+		State:        criv1.ContainerState_CONTAINER_CREATED,
+		CreatedAt:    now,
+		Labels:       containerConfig.GetLabels(),
+		Annotations:  containerConfig.GetAnnotations(),
+	}
+	c := &fakeContainer{
+		config:    containerConfig,
+		container: container,
+		status: &criv1.ContainerStatus{
+			Id:          id,
+			Metadata:    containerConfig.GetMetadata(),
+			State:       criv1.ContainerState_CONTAINER_CREATED,
+			CreatedAt:   now,
+			Image:       containerConfig.GetImage(),
+			ImageRef:    containerConfig.GetImage().GetImage(),
+			Labels:      containerConfig.GetLabels(),
+			Annotations: containerConfig.GetAnnotations(),
+			Mounts:      containerConfig.GetMounts(),
+			LogPath:     containerConfig.GetLogPath(),
+		},
+	}
+	if lifetime, found := f.lifetimes[containerConfig.GetMetadata().GetName()]; found {
+		c.lifetime = lifetime.lifetime
+		c.exitCode = lifetime.exitCode
+	}
+	f.containers[id] = c
+
+	return &Container{
+		Id:              id,
+		ContainerConfig: containerConfig,
+		Container:       copyContainer(container),
+	}, nil
 }
 
-// CreateSandbox implements RuntimeService
-func (*FakeRuntimeService) CreateSandbox(sandboxConfig *criv1.PodSandboxConfig, runtimeClassName string) (*Pod, error) {
-	panic("unimplemented")
+// CreateSandbox implements RuntimeService, a sandbox get a ip and is ready after created
+func (f *FakeRuntimeService) CreateSandbox(sandboxConfig *criv1.PodSandboxConfig, runtimeClassName string) (*Pod, error) {
+	if err := f.call("CreateSandbox"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.nextId("sandbox")
+	s := &fakeSandbox{
+		sandbox: &criv1.PodSandbox{
+			Id:             id,
+			Metadata:       sandboxConfig.GetMetadata(),
+			State:          criv1.PodSandboxState_SANDBOX_READY,
+			CreatedAt:      time.Now().UnixNano(),
+			Labels:         sandboxConfig.GetLabels(),
+			Annotations:    sandboxConfig.GetAnnotations(),
+			RuntimeHandler: runtimeClassName,
+		},
+		ip: fmt.Sprintf("10.%d.%d.%d", (f.seq>>16)&0xff, (f.seq>>8)&0xff, f.seq&0xff),
+	}
+	f.sandboxes[id] = s
+
+	pod := f.runtimePod(s, false)
+	pod.SandboxConfig = sandboxConfig
+	return pod, nil
 }
 
 // GetCRIVersion implements RuntimeService
-func (*FakeRuntimeService) GetCRIVersion() CRIVersion {
-	panic("unimplemented")
+func (f *FakeRuntimeService) GetCRIVersion() CRIVersion {
+	return CRIVersion{
+		Version:           FakeRuntimeVersion,
+		RuntimeName:       FakeRuntimeName,
+		RuntimeVersion:    FakeRuntimeVersion,
+		RuntimeApiVersion: "v1",
+	}
 }
 
 // GetContainerStatus implements RuntimeService
-func (*FakeRuntimeService) GetContainerStatus(containerID string) (*ContainerStatus, error) {
-	panic("unimplemented")
+func (f *FakeRuntimeService) GetContainerStatus(containerID string) (*ContainerStatus, error) {
+	if err := f.call("GetContainerStatus"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerStatus{
+		RuntimeStatus: copyContainerStatus(c.status),
+	}, nil
 }
 
 // GetImageLabel implements RuntimeService
-func (*FakeRuntimeService) GetImageLabel() (string, error) {
-	panic("unimplemented")
+func (f *FakeRuntimeService) GetImageLabel() (string, error) {
+	if err := f.call("GetImageLabel"); err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// GetPods implements RuntimeService, pods are sorted by creation time
+func (f *FakeRuntimeService) GetPods(includeContainers bool) ([]*Pod, error) {
+	if err := f.call("GetPods"); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	pods := []*Pod{}
+	for _, s := range f.sandboxes {
+		pods = append(pods, f.runtimePod(s, includeContainers))
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Sandbox.CreatedAt < pods[j].Sandbox.CreatedAt
+	})
+	return pods, nil
+}
+
+// GetRuntimeStatus implements RuntimeService, fake runtime and its network are always ready
+func (f *FakeRuntimeService) GetRuntimeStatus() (*criv1.RuntimeStatus, error) {
+	if err := f.call("GetRuntimeStatus"); err != nil {
+		return nil, err
+	}
+	return &criv1.RuntimeStatus{
+		Conditions: []*criv1.RuntimeCondition{
+			{Type: criv1.RuntimeReady, Status: true},
+			{Type: criv1.NetworkReady, Status: true},
+		},
+	}, nil
+}
+
+// StartContainer implements RuntimeService, only a created container can be started
+func (f *FakeRuntimeService) StartContainer(containerID string) error {
+	if err := f.call("StartContainer"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return err
+	}
+	if c.status.State != criv1.ContainerState_CONTAINER_CREATED {
+		return fmt.Errorf("container %s is in state %s, can not start", containerID, c.status.State)
+	}
+	c.status.State = criv1.ContainerState_CONTAINER_RUNNING
+	c.status.StartedAt = time.Now().UnixNano()
+	c.container.State = c.status.State
+	return nil
+}
+
+// TerminateContainer implements RuntimeService, container is stopped immediately and removed
+func (f *FakeRuntimeService) TerminateContainer(containerID string) error {
+	if err := f.call("TerminateContainer"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c, err := f.getContainer(containerID)
+	if err != nil {
+		return nil
+	}
+	if c.status.State == criv1.ContainerState_CONTAINER_RUNNING {
+		f.exitContainer(c, 0, time.Now())
+	}
+	delete(f.containers, containerID)
+	return nil
 }
 
-// GetPods implements RuntimeService
-func (*FakeRuntimeService) GetPods(includeTerminated bool) ([]*Pod, error) {
-	panic("unimplemented")
+// TerminatePod implements RuntimeService, sandbox and all its containers are stopped and removed
+func (f *FakeRuntimeService) TerminatePod(podSandboxID string, containerIDs []string) error {
+	if err := f.call("TerminatePod"); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.getSandbox(podSandboxID); err != nil {
+		return nil
+	}
+	for id, c := range f.containers {
+		if c.container.PodSandboxId == podSandboxID {
+			delete(f.containers, id)
+		}
+	}
+	delete(f.sandboxes, podSandboxID)
+	return nil
 }
 
-// GetRuntimeStatus implements RuntimeService
-func (*FakeRuntimeService) GetRuntimeStatus() (*criv1.RuntimeStatus, error) {
-	panic("unimplemented")
+func copySandbox(sandbox *criv1.PodSandbox) *criv1.PodSandbox {
+	c := *sandbox
+	return &c
 }
 
-// StartContainer implements RuntimeService
-func (*FakeRuntimeService) StartContainer(containerID string) error {
-	panic("unimplemented")
+func copyContainer(container *criv1.Container) *criv1.Container {
+	c := *container
+	return &c
 }
 
-// TerminateContainer implements RuntimeService
-func (*FakeRuntimeService) TerminateContainer(containerID string) error {
-	panic("unimplemented")
+func copyContainerStatus(status *criv1.ContainerStatus) *criv1.ContainerStatus {
+	c := *status
+	return &c
 }
 
-// TerminatePod implements RuntimeService
-func (*FakeRuntimeService) TerminatePod(podSandboxID string, containerIds []string) error {
-	panic("unimplemented")
+func NewFakeRuntimeService() *FakeRuntimeService {
+	return &FakeRuntimeService{
+		mu:         sync.Mutex{},
+		seq:        0,
+		podCidrs:   []string{},
+		sandboxes:  map[string]*fakeSandbox{},
+		containers: map[string]*fakeContainer{},
+		errors:     map[string][]error{},
+		latencies:  map[string]time.Duration{},
+		calls:      map[string]int{},
+		lifetimes:  map[string]fakeContainerLifetime{},
+	}
 }
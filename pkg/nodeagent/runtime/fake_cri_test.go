@@ -0,0 +1,118 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package runtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+func newTestSandbox(t *testing.T, f *FakeRuntimeService) *Pod {
+	pod, err := f.CreateSandbox(&criv1.PodSandboxConfig{Metadata: &criv1.PodSandboxMetadata{Name: "pod", Namespace: "test"}}, "")
+	if err != nil {
+		t.Fatalf("expect sandbox created, got %v", err)
+	}
+	return pod
+}
+
+func newTestContainer(t *testing.T, f *FakeRuntimeService, pod *Pod, name string) *Container {
+	container, err := f.CreateContainer(pod.Id, &criv1.ContainerConfig{Metadata: &criv1.ContainerMetadata{Name: name}}, pod.SandboxConfig)
+	if err != nil {
+		t.Fatalf("expect container created, got %v", err)
+	}
+	return container
+}
+
+func TestFakeRuntimeContainerLifecycle(t *testing.T) {
+	f := NewFakeRuntimeService()
+	pod := newTestSandbox(t, f)
+	if len(pod.IPs) != 1 || pod.Sandbox.State != criv1.PodSandboxState_SANDBOX_READY {
+		t.Fatalf("expect a ready sandbox with ip, got %+v", pod)
+	}
+	container := newTestContainer(t, f, pod, "app")
+	if _, err := f.CreateContainer(pod.Id, container.ContainerConfig, pod.SandboxConfig); err == nil {
+		t.Errorf("expect container name conflict in sandbox")
+	}
+
+	if err := f.StartContainer(container.Id); err != nil {
+		t.Fatalf("expect container started, got %v", err)
+	}
+	if status, _ := f.GetContainerStatus(container.Id); !ContainerRunning(status) {
+		t.Errorf("expect container running, got %v", status.RuntimeStatus)
+	}
+	if err := f.HibernateContainer(container.Id); err != nil || !f.IsHibernated(container.Id) {
+		t.Errorf("expect container hibernated, got %v", err)
+	}
+	if _, _, err := f.ExecCommand(container.Id, []string{"ls"}, time.Second); err == nil {
+		t.Errorf("expect exec fail in hibernated container")
+	}
+	if err := f.WakeupContainer(container.Id); err != nil || f.IsHibernated(container.Id) {
+		t.Errorf("expect container woke up, got %v", err)
+	}
+
+	if err := f.ExitContainer(container.Id, 137); err != nil {
+		t.Fatalf("expect container exit, got %v", err)
+	}
+	if status, _ := f.GetContainerStatus(container.Id); !ContainerExitAbnormal(status) {
+		t.Errorf("expect container exit abnormally, got %v", status.RuntimeStatus)
+	}
+
+	pods, _ := f.GetPods(true)
+	if len(pods) != 1 || len(pods[0].Containers) != 1 || pods[0].Containers["app"].State != criv1.ContainerState_CONTAINER_EXITED {
+		t.Errorf("expect one pod with exited container, got %v", pods)
+	}
+	if err := f.TerminatePod(pod.Id, nil); err != nil {
+		t.Fatalf("expect pod terminated, got %v", err)
+	}
+	if status, err := f.GetPodStatus(pod.Id, []string{container.Id}); status != nil || err != nil {
+		t.Errorf("expect no status of terminated pod, got %v, %v", status, err)
+	}
+	if _, err := f.GetContainerStatus(container.Id); !util.NotFoundError(err) {
+		t.Errorf("expect container removed with pod, got %v", err)
+	}
+}
+
+func TestFakeRuntimeScript(t *testing.T) {
+	f := NewFakeRuntimeService()
+	f.InjectError("CreateSandbox", errors.New("no ip available"))
+	if _, err := f.CreateSandbox(&criv1.PodSandboxConfig{}, ""); err == nil {
+		t.Errorf("expect injected error")
+	}
+	pod := newTestSandbox(t, f)
+	if f.Calls("CreateSandbox") != 2 {
+		t.Errorf("expect two sandbox creation calls, got %d", f.Calls("CreateSandbox"))
+	}
+
+	f.SetLatency("StartContainer", 50*time.Millisecond)
+	f.SetContainerLifetime("job", 100*time.Millisecond, 0)
+	container := newTestContainer(t, f, pod, "job")
+	start := time.Now()
+	if err := f.StartContainer(container.Id); err != nil || time.Since(start) < 50*time.Millisecond {
+		t.Errorf("expect container start take latency, got %v in %v", err, time.Since(start))
+	}
+	if status, _ := f.GetContainerStatus(container.Id); !ContainerRunning(status) {
+		t.Errorf("expect container running before its lifetime, got %v", status.RuntimeStatus)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if status, _ := f.GetContainerStatus(container.Id); !ContainerExitNormal(status) {
+		t.Errorf("expect container exit normally after its lifetime, got %v", status.RuntimeStatus)
+	}
+}
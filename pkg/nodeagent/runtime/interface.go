@@ -31,6 +31,8 @@ const (
 type RuntimeService interface {
 	GetRuntimeStatus() (*criv1.RuntimeStatus, error)
 
+	UpdatePodCidr(podCidrs []string) error
+
 	GetPods(includeContainers bool) ([]*Pod, error)
 
 	GetPodSandbox(podSandboxID string) (*criv1.PodSandbox, error)
@@ -92,4 +94,10 @@ type PodStatus struct {
 // ContainerStatus represents the cri status of a container and fornax status.
 type ContainerStatus struct {
 	RuntimeStatus *criv1.ContainerStatus
+	// number of times container was restarted by node agent
+	RestartCount int32
+	// runtime status of container before it was restarted last time
+	LastTerminationStatus *criv1.ContainerStatus
+	// delay before next restart, it's doubled on every crash until it reach max backoff
+	RestartBackoff time.Duration
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	grpc_util "centaurusinfra.io/fornax-serverless/pkg/util"
@@ -79,6 +80,16 @@ func (r *remoteRuntimeManager) GetRuntimeStatus() (*criv1.RuntimeStatus, error)
 	return resp.GetStatus(), nil
 }
 
+// UpdatePodCidr implements cri.RuntimeService, it tell runtime network plugin which cidrs to allocate pod ips from
+func (r *remoteRuntimeManager) UpdatePodCidr(podCidrs []string) error {
+	klog.InfoS("Update runtime pod cidr", "PodCidrs", podCidrs)
+	return r.runtimeService.UpdateRuntimeConfig(&criv1.RuntimeConfig{
+		NetworkConfig: &criv1.NetworkConfig{
+			PodCidr: strings.Join(podCidrs, ","),
+		},
+	})
+}
+
 func (r *remoteRuntimeManager) CreateContainer(podSandboxID string, containerConfig *criv1.ContainerConfig, podSandboxConfig *criv1.PodSandboxConfig) (*Container, error) {
 	r.podConcurrency.Acquire(context.Background(), 1)
 	defer r.podConcurrency.Release(1)
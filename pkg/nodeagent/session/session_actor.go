@@ -72,11 +72,7 @@ func (a *SessionActor) OpenSession() error {
 // try to close a session with session service, if session already closed, send a session closed message again
 // if session service do not have this session, send closed message
 func (a *SessionActor) CloseSession() (err error) {
-	graceSeconds := DefaultCloseSessionGraceSeconds
-	if a.session.Session.Spec.CloseGracePeriodSeconds != nil {
-		graceSeconds = *a.session.Session.Spec.CloseGracePeriodSeconds
-	}
-	err = a.sessionService.CloseSession(a.pod, a.session, graceSeconds)
+	err = a.sessionService.CloseSession(a.pod, a.session, GracePeriodSeconds(a.session))
 	if err != nil && err == sessionservice.SessionNotFound {
 		a.notifySessionState(internal.SessionState{
 			SessionId:      a.session.Identifier,
@@ -87,6 +83,20 @@ func (a *SessionActor) CloseSession() (err error) {
 	return err
 }
 
+// try to evacuate a session with session service, session is expected to report evacuated state with checkpoint data,
+// if session service do not have this session, send evacuated message without checkpoint
+func (a *SessionActor) EvacuateSession() (err error) {
+	err = a.sessionService.EvacuateSession(a.pod, a.session, GracePeriodSeconds(a.session))
+	if err != nil && err == sessionservice.SessionNotFound {
+		a.notifySessionState(internal.SessionState{
+			SessionId:      a.session.Identifier,
+			SessionState:   types.SessionStateEvacuated,
+			ClientSessions: []types.ClientSession{},
+		})
+	}
+	return err
+}
+
 func (a *SessionActor) PingSession() error {
 	return a.sessionService.PingSession(a.pod, a.session, a.notifySessionState)
 }
@@ -95,3 +105,11 @@ func (a *SessionActor) PingSession() error {
 func (a *SessionActor) notifySessionState(state internal.SessionState) {
 	message.Send(nil, a.supervisor, state)
 }
+
+// GracePeriodSeconds return how long a session is given to close or evacuate itself
+func GracePeriodSeconds(session *types.FornaxSession) uint16 {
+	if session.Session.Spec.CloseGracePeriodSeconds != nil {
+		return *session.Session.Spec.CloseGracePeriodSeconds
+	}
+	return DefaultCloseSessionGraceSeconds
+}
@@ -29,6 +29,9 @@ import (
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"k8s.io/klog/v2"
 )
@@ -36,6 +39,8 @@ import (
 const (
 	DefaultSessionHeartBeatDuration             = 1 * time.Minute
 	DefaultDeadSessionHeartbeatMissingThreshold = 3
+	// grpc metadata key of pod session service token
+	PodTokenMetadataKey = "x-fornax-pod-token"
 )
 
 type sessionServer interface {
@@ -58,6 +63,9 @@ type SessionStateHeartbeat struct {
 
 var _ sessionServer = &GrpcSessionService{}
 
+// PodUIDFunc return uid of live pod of a pod id, empty if pod does not exist on node
+type PodUIDFunc func(podId string) string
+
 type GrpcSessionService struct {
 	mu sync.RWMutex
 	// session state callback and heartbeat map by session id
@@ -66,6 +74,12 @@ type GrpcSessionService struct {
 	// pod's get message connection by pod id
 	sessionClients map[string]*GetSessionMessageClient
 
+	// verify pod token presented by instance, pod identity is not checked if it's nil
+	authenticator *sessionservice.PodTokenAuthenticator
+
+	// find live pod uid to reject token of a deleted pod which had same pod id
+	podUID PodUIDFunc
+
 	UnimplementedSessionServiceServer
 }
 
@@ -134,6 +148,23 @@ func (g *GrpcSessionService) getSessions() []*SessionStateHeartbeat {
 	return sessions
 }
 
+// authenticatePod verify token in grpc metadata is issued for claimed pod, it return pod uid in token
+func (g *GrpcSessionService) authenticatePod(ctx context.Context, podId string) (string, error) {
+	if g.authenticator == nil {
+		return "", nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(PodTokenMetadataKey)
+	if len(tokens) == 0 {
+		return "", status.Error(codes.Unauthenticated, "pod token is required")
+	}
+	podUID, err := g.authenticator.VerifyToken(podId, tokens[0])
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	return podUID, nil
+}
+
 func (g *GrpcSessionService) checkAndCleanSessionHeartbeat() {
 	deadSession := []string{}
 	sessions := g.getSessions()
@@ -169,6 +200,16 @@ func (g *GrpcSessionService) checkAndCleanSessionHeartbeat() {
 func (g *GrpcSessionService) GetMessage(identifier *PodIdentifier, server SessionService_GetMessageServer) error {
 	var messageSeq int64 = 0
 	klog.InfoS("Received GetMessage stream connection from pod", "pod", identifier)
+	podUID, err := g.authenticatePod(server.Context(), identifier.GetPodId())
+	if err != nil {
+		klog.ErrorS(err, "Rejected GetMessage stream connection with invalid pod token", "pod", identifier.GetPodId())
+		return err
+	}
+	// only live pod can receive session commands, token of a deleted pod with same pod id is rejected
+	if g.authenticator != nil && g.podUID(identifier.GetPodId()) != podUID {
+		klog.InfoS("Rejected GetMessage stream connection with pod token not issued to live pod", "pod", identifier.GetPodId())
+		return status.Error(codes.PermissionDenied, "pod token is not issued to live pod")
+	}
 	ch := make(chan *SessionMessage, 10)
 	if err := g.enlistPod(identifier.GetPodId(), ch); err != nil {
 		close(ch)
@@ -199,6 +240,19 @@ func (g *GrpcSessionService) GetMessage(identifier *PodIdentifier, server Sessio
 
 func (g *GrpcSessionService) PutMessage(ctx context.Context, message *SessionMessage) (*empty.Empty, error) {
 	var err error
+	podId := message.GetSessionIdentifier().GetPodId()
+	podUID, err := g.authenticatePod(ctx, podId)
+	if err != nil {
+		klog.ErrorS(err, "Rejected PutMessage with invalid pod token", "pod", podId, "session", message.GetSessionIdentifier().GetIdentifier())
+		return nil, err
+	}
+	// a pod can only report state of sessions open on itself
+	if heartbeat := g.getSessionHeartbeat(message.GetSessionIdentifier().GetIdentifier()); heartbeat != nil && g.authenticator != nil {
+		if heartbeat.pod.Identifier != podId || string(heartbeat.pod.Pod.UID) != podUID {
+			klog.InfoS("Rejected PutMessage of session not open on pod", "pod", podId, "session", message.GetSessionIdentifier().GetIdentifier(), "sessionPod", heartbeat.pod.Identifier)
+			return nil, status.Error(codes.PermissionDenied, "session is not open on pod")
+		}
+	}
 	switch message.GetMessageType() {
 	case MessageType_SESSION_STATE:
 		msg := internal.SessionState{
@@ -216,9 +270,14 @@ func (g *GrpcSessionService) PutMessage(ctx context.Context, message *SessionMes
 			msg.SessionState = types.SessionStateReady
 		case SessionState_STATE_INITIALIZING:
 			msg.SessionState = types.SessionStateStarting
+		case SessionState_STATE_EVACUATING:
+			msg.SessionState = types.SessionStateEvacuating
+		case SessionState_STATE_EVACUATED:
+			msg.SessionState = types.SessionStateEvacuated
+			msg.CheckpointData = status.GetCheckpointData()
 		}
 		g.forwardSessionStateToPod(sessionId, msg)
-		if msg.SessionState == types.SessionStateClosed {
+		if msg.SessionState == types.SessionStateClosed || msg.SessionState == types.SessionStateEvacuated {
 			g.removeClosedSession(sessionId)
 		}
 	default:
@@ -263,6 +322,38 @@ func (g *GrpcSessionService) CloseSession(pod *types.FornaxPod, session *types.F
 	return nil
 }
 
+// EvacuateSession dispatch a SessionEvacuate event to pod, pod is expected to checkpoint session and report evacuated state with checkpoint data
+func (g *GrpcSessionService) EvacuateSession(pod *types.FornaxPod, session *types.FornaxSession, gracePeriodSeconds uint16) error {
+	podId := pod.Identifier
+	sessionId := session.Identifier
+	sessHeartbeat := g.getSessionHeartbeat(sessionId)
+	if sessHeartbeat == nil {
+		return sessionservice.SessionNotFound
+	}
+
+	messageType := MessageType_EVACUATE_SESSION
+	body := SessionMessage_EvacuateSession{
+		EvacuateSession: &EvacuateSession{
+			GracePeriodSeconds: int64(gracePeriodSeconds),
+		},
+	}
+	m := &SessionMessage{
+		SessionIdentifier: &SessionIdentifier{
+			PodId:      podId,
+			Identifier: sessionId,
+		},
+		MessageType: messageType,
+		MessageBody: &body,
+	}
+
+	err := g.sendGrpcMessageToPod(podId, m)
+	if err != nil {
+		klog.ErrorS(err, "Failed to dispatch evacuate session message to pod", "pod", podId, "session", sessionId)
+		return err
+	}
+	return nil
+}
+
 // OpenSession dispatch a SessionOpen event to pod
 func (g *GrpcSessionService) OpenSession(pod *types.FornaxPod, session *types.FornaxSession, stateCallbackFunc func(internal.SessionState)) error {
 	podId := pod.Identifier
@@ -272,7 +363,8 @@ func (g *GrpcSessionService) OpenSession(pod *types.FornaxPod, session *types.Fo
 	body := SessionMessage_OpenSession{
 		OpenSession: &OpenSession{
 			SessionConfiguration: &SessionConfiguration{
-				SessionData: []byte(sessionData),
+				SessionData:    []byte(sessionData),
+				CheckpointData: session.Session.Status.CheckpointData,
 			},
 		},
 	}
@@ -388,11 +480,14 @@ func (g *GrpcSessionService) delistPod(pod string) {
 	delete(g.sessionClients, pod)
 }
 
-func NewSessionService() *GrpcSessionService {
+// NewSessionService return a grpc session service, podUID is required if authenticator is provided
+func NewSessionService(authenticator *sessionservice.PodTokenAuthenticator, podUID PodUIDFunc) *GrpcSessionService {
 	return &GrpcSessionService{
 		mu:                                sync.RWMutex{},
 		sessionHeartbeats:                 map[string]*SessionStateHeartbeat{},
 		sessionClients:                    map[string]*GetSessionMessageClient{},
+		authenticator:                     authenticator,
+		podUID:                            podUID,
 		UnimplementedSessionServiceServer: UnimplementedSessionServiceServer{},
 	}
 }
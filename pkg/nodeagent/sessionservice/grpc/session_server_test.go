@@ -0,0 +1,150 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	internal "centaurusinfra.io/fornax-serverless/pkg/nodeagent/message"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/sessionservice"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeGetMessageServer is a GetMessage stream of a pod, other stream methods are not expected
+type fakeGetMessageServer struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeGetMessageServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *fakeGetMessageServer) Send(*SessionMessage) error {
+	return nil
+}
+
+func newTestSessionService(t *testing.T, livePods map[string]string) (*GrpcSessionService, *sessionservice.PodTokenAuthenticator) {
+	authenticator, err := sessionservice.NewPodTokenAuthenticator(filepath.Join(t.TempDir(), "token.key"))
+	if err != nil {
+		t.Fatalf("failed to create pod token authenticator, %v", err)
+	}
+	return NewSessionService(authenticator, func(podId string) string { return livePods[podId] }), authenticator
+}
+
+func tokenContext(ctx context.Context, token string) context.Context {
+	if len(token) == 0 {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(PodTokenMetadataKey, token))
+}
+
+func TestGetMessageAuthentication(t *testing.T) {
+	g, authenticator := newTestSessionService(t, map[string]string{"test/pod": "live-uid"})
+	tests := []struct {
+		name  string
+		podId string
+		token string
+		code  codes.Code
+	}{
+		{name: "no token", podId: "test/pod", code: codes.Unauthenticated},
+		{name: "invalid token", podId: "test/pod", token: "live-uid.invalid", code: codes.Unauthenticated},
+		{name: "token of other pod", podId: "test/pod", token: authenticator.IssueToken("test/other", "live-uid"), code: codes.Unauthenticated},
+		{name: "token of deleted pod with same id", podId: "test/pod", token: authenticator.IssueToken("test/pod", "old-uid"), code: codes.PermissionDenied},
+		{name: "token of pod not on node", podId: "test/gone", token: authenticator.IssueToken("test/gone", "gone-uid"), code: codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		err := g.GetMessage(&PodIdentifier{PodId: test.podId}, &fakeGetMessageServer{ctx: tokenContext(context.Background(), test.token)})
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expect %s, got %v", test.name, test.code, err)
+		}
+		if g.getSessionClient(test.podId) != nil {
+			t.Errorf("%s: expect rejected pod not enlisted", test.name)
+		}
+	}
+
+	// live pod connect until its stream is closed
+	ctx, cancel := context.WithCancel(tokenContext(context.Background(), authenticator.IssueToken("test/pod", "live-uid")))
+	done := make(chan error)
+	go func() {
+		done <- g.GetMessage(&PodIdentifier{PodId: "test/pod"}, &fakeGetMessageServer{ctx: ctx})
+	}()
+	for i := 0; i < 100 && g.getSessionClient("test/pod") == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if g.getSessionClient("test/pod") == nil {
+		t.Fatalf("expect live pod enlisted")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expect stream closed without error, got %v", err)
+	}
+	if g.getSessionClient("test/pod") != nil {
+		t.Errorf("expect pod delisted after stream closed")
+	}
+}
+
+func TestPutMessageAuthentication(t *testing.T) {
+	g, authenticator := newTestSessionService(t, map[string]string{"test/pod": "pod-uid", "test/other": "other-uid"})
+	pod := &types.FornaxPod{Identifier: "test/pod", Pod: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "pod", UID: "pod-uid"}}}
+	states := []internal.SessionState{}
+	g.createHeartBeat(pod, &types.FornaxSession{Identifier: "test/session"}, func(state internal.SessionState) { states = append(states, state) })
+
+	message := func(podId string) *SessionMessage {
+		return &SessionMessage{
+			SessionIdentifier: &SessionIdentifier{PodId: podId, Identifier: "test/session"},
+			MessageType:       MessageType_SESSION_STATE,
+			MessageBody:       &SessionMessage_SessionStatus{SessionStatus: &SessionStatus{SessionState: SessionState_STATE_OPEN}},
+		}
+	}
+	tests := []struct {
+		name  string
+		podId string
+		token string
+		code  codes.Code
+	}{
+		{name: "no token", podId: "test/pod", code: codes.Unauthenticated},
+		{name: "session open on other pod", podId: "test/other", token: authenticator.IssueToken("test/other", "other-uid"), code: codes.PermissionDenied},
+		{name: "token of deleted pod with same id", podId: "test/pod", token: authenticator.IssueToken("test/pod", "old-uid"), code: codes.PermissionDenied},
+	}
+	for _, test := range tests {
+		_, err := g.PutMessage(tokenContext(context.Background(), test.token), message(test.podId))
+		if status.Code(err) != test.code {
+			t.Errorf("%s: expect %s, got %v", test.name, test.code, err)
+		}
+	}
+	if len(states) != 0 {
+		t.Errorf("expect rejected session state not forwarded, got %v", states)
+	}
+
+	if _, err := g.PutMessage(tokenContext(context.Background(), authenticator.IssueToken("test/pod", "pod-uid")), message("test/pod")); err != nil {
+		t.Fatalf("expect session state of own pod accepted, got %v", err)
+	}
+	if len(states) != 1 || states[0].SessionState != types.SessionStateReady {
+		t.Errorf("expect session ready state forwarded, got %v", states)
+	}
+}
@@ -46,6 +46,7 @@ const (
 	MessageType_CLOSE_SESSION         MessageType = 102
 	MessageType_PING_SESSION          MessageType = 103
 	MessageType_SESSION_STATE         MessageType = 104
+	MessageType_EVACUATE_SESSION      MessageType = 105
 )
 
 // Enum value maps for MessageType.
@@ -57,6 +58,7 @@ var (
 		102: "CLOSE_SESSION",
 		103: "PING_SESSION",
 		104: "SESSION_STATE",
+		105: "EVACUATE_SESSION",
 	}
 	MessageType_value = map[string]int32{
 		"UNSPECIFIED":           0,
@@ -65,6 +67,7 @@ var (
 		"CLOSE_SESSION":         102,
 		"PING_SESSION":          103,
 		"SESSION_STATE":         104,
+		"EVACUATE_SESSION":      105,
 	}
 )
 
@@ -102,6 +105,8 @@ const (
 	SessionState_STATE_OPEN         SessionState = 101
 	SessionState_STATE_CLOSED       SessionState = 102
 	SessionState_STATE_CLOSING      SessionState = 103
+	SessionState_STATE_EVACUATING   SessionState = 104
+	SessionState_STATE_EVACUATED    SessionState = 105
 )
 
 // Enum value maps for SessionState.
@@ -111,12 +116,16 @@ var (
 		101: "STATE_OPEN",
 		102: "STATE_CLOSED",
 		103: "STATE_CLOSING",
+		104: "STATE_EVACUATING",
+		105: "STATE_EVACUATED",
 	}
 	SessionState_value = map[string]int32{
 		"STATE_INITIALIZING": 0,
 		"STATE_OPEN":         101,
 		"STATE_CLOSED":       102,
 		"STATE_CLOSING":      103,
+		"STATE_EVACUATING":   104,
+		"STATE_EVACUATED":    105,
 	}
 )
 
@@ -161,6 +170,7 @@ type SessionMessage struct {
 	//	*SessionMessage_CloseSession
 	//	*SessionMessage_PingSession
 	//	*SessionMessage_SessionStatus
+	//	*SessionMessage_EvacuateSession
 	MessageBody isSessionMessage_MessageBody `protobuf_oneof:"MessageBody"`
 }
 
@@ -259,6 +269,13 @@ func (x *SessionMessage) GetSessionStatus() *SessionStatus {
 	return nil
 }
 
+func (x *SessionMessage) GetEvacuateSession() *EvacuateSession {
+	if x, ok := x.GetMessageBody().(*SessionMessage_EvacuateSession); ok {
+		return x.EvacuateSession
+	}
+	return nil
+}
+
 type isSessionMessage_MessageBody interface {
 	isSessionMessage_MessageBody()
 }
@@ -283,6 +300,10 @@ type SessionMessage_SessionStatus struct {
 	SessionStatus *SessionStatus `protobuf:"bytes,104,opt,name=sessionStatus,proto3,oneof"`
 }
 
+type SessionMessage_EvacuateSession struct {
+	EvacuateSession *EvacuateSession `protobuf:"bytes,105,opt,name=evacuateSession,proto3,oneof"`
+}
+
 func (*SessionMessage_SessionConfiguration) isSessionMessage_MessageBody() {}
 
 func (*SessionMessage_OpenSession) isSessionMessage_MessageBody() {}
@@ -293,6 +314,8 @@ func (*SessionMessage_PingSession) isSessionMessage_MessageBody() {}
 
 func (*SessionMessage_SessionStatus) isSessionMessage_MessageBody() {}
 
+func (*SessionMessage_EvacuateSession) isSessionMessage_MessageBody() {}
+
 type PodIdentifier struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -401,7 +424,8 @@ type SessionConfiguration struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SessionData []byte `protobuf:"bytes,1,opt,name=sessionData,proto3" json:"sessionData,omitempty"` // a container specific blob
+	SessionData    []byte `protobuf:"bytes,1,opt,name=sessionData,proto3" json:"sessionData,omitempty"`       // a container specific blob
+	CheckpointData []byte `protobuf:"bytes,2,opt,name=checkpointData,proto3" json:"checkpointData,omitempty"` // checkpoint of a evacuated session, container restore session from it
 }
 
 func (x *SessionConfiguration) Reset() {
@@ -443,8 +467,15 @@ func (x *SessionConfiguration) GetSessionData() []byte {
 	return nil
 }
 
+func (x *SessionConfiguration) GetCheckpointData() []byte {
+	if x != nil {
+		return x.CheckpointData
+	}
+	return nil
+}
+
 // request container to initialize a session,
-//container send a session state message back to notify session is ready for client use
+// container send a session state message back to notify session is ready for client use
 type OpenSession struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -493,7 +524,7 @@ func (x *OpenSession) GetSessionConfiguration() *SessionConfiguration {
 }
 
 // close session and notify client to left, and container will close session after gracePeriodSeconds
-//container send a session state message back to notify session is closed
+// container send a session state message back to notify session is closed
 type CloseSession struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -541,8 +572,58 @@ func (x *CloseSession) GetGracePeriodSeconds() int64 {
 	return 0
 }
 
+// request container to checkpoint session and leave this instance, session is reopened on another instance with checkpoint,
+// container send a session state message with checkpoint back to notify session is evacuated,
+// session is reopened without checkpoint if container do not evacuate it in gracePeriodSeconds
+type EvacuateSession struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GracePeriodSeconds int64 `protobuf:"varint,1,opt,name=gracePeriodSeconds,proto3" json:"gracePeriodSeconds,omitempty"`
+}
+
+func (x *EvacuateSession) Reset() {
+	*x = EvacuateSession{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvacuateSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvacuateSession) ProtoMessage() {}
+
+func (x *EvacuateSession) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvacuateSession.ProtoReflect.Descriptor instead.
+func (*EvacuateSession) Descriptor() ([]byte, []int) {
+	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EvacuateSession) GetGracePeriodSeconds() int64 {
+	if x != nil {
+		return x.GracePeriodSeconds
+	}
+	return 0
+}
+
 // ping session and request container to report its status container send a session state message back,
-//if session do not reply ping request consecutively, session is considered as dead, and pod will be terminated
+// if session do not reply ping request consecutively, session is considered as dead, and pod will be terminated
 type PingSession struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -552,7 +633,7 @@ type PingSession struct {
 func (x *PingSession) Reset() {
 	*x = PingSession{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[6]
+		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -565,7 +646,7 @@ func (x *PingSession) String() string {
 func (*PingSession) ProtoMessage() {}
 
 func (x *PingSession) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[6]
+	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -578,7 +659,7 @@ func (x *PingSession) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingSession.ProtoReflect.Descriptor instead.
 func (*PingSession) Descriptor() ([]byte, []int) {
-	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{6}
+	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{7}
 }
 
 // container keep its internal state of clients are on this session, in long term it could be managed via ingress gateway
@@ -595,7 +676,7 @@ type ClientSession struct {
 func (x *ClientSession) Reset() {
 	*x = ClientSession{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[7]
+		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -608,7 +689,7 @@ func (x *ClientSession) String() string {
 func (*ClientSession) ProtoMessage() {}
 
 func (x *ClientSession) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[7]
+	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -621,7 +702,7 @@ func (x *ClientSession) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ClientSession.ProtoReflect.Descriptor instead.
 func (*ClientSession) Descriptor() ([]byte, []int) {
-	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{7}
+	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *ClientSession) GetClientIdentifier() string {
@@ -651,14 +732,15 @@ type SessionStatus struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SessionState  SessionState     `protobuf:"varint,1,opt,name=sessionState,proto3,enum=centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionState" json:"sessionState,omitempty"`
-	ClientSession []*ClientSession `protobuf:"bytes,2,rep,name=clientSession,proto3" json:"clientSession,omitempty"`
+	SessionState   SessionState     `protobuf:"varint,1,opt,name=sessionState,proto3,enum=centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionState" json:"sessionState,omitempty"`
+	ClientSession  []*ClientSession `protobuf:"bytes,2,rep,name=clientSession,proto3" json:"clientSession,omitempty"`
+	CheckpointData []byte           `protobuf:"bytes,3,opt,name=checkpointData,proto3" json:"checkpointData,omitempty"` // checkpoint of session when session is evacuated
 }
 
 func (x *SessionStatus) Reset() {
 	*x = SessionStatus{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[8]
+		mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -671,7 +753,7 @@ func (x *SessionStatus) String() string {
 func (*SessionStatus) ProtoMessage() {}
 
 func (x *SessionStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[8]
+	mi := &file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -684,7 +766,7 @@ func (x *SessionStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SessionStatus.ProtoReflect.Descriptor instead.
 func (*SessionStatus) Descriptor() ([]byte, []int) {
-	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{8}
+	return file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *SessionStatus) GetSessionState() SessionState {
@@ -701,6 +783,13 @@ func (x *SessionStatus) GetClientSession() []*ClientSession {
 	return nil
 }
 
+func (x *SessionStatus) GetCheckpointData() []byte {
+	if x != nil {
+		return x.CheckpointData
+	}
+	return nil
+}
+
 var File_pkg_nodeagent_sessionservice_grpc_session_service_proto protoreflect.FileDescriptor
 
 var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDesc = []byte{
@@ -714,8 +803,8 @@ var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDesc = []byt
 	0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
 	0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67,
 	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
-	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd8,
-	0x06, 0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xcc,
+	0x07, 0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
 	0x65, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x65, 0x6e,
 	0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x6d, 0x65,
 	0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12,
@@ -767,95 +856,116 @@ var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDesc = []byt
 	0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73,
 	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73,
 	0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x48, 0x00, 0x52, 0x0d, 0x73, 0x65, 0x73,
-	0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x42, 0x0d, 0x0a, 0x0b, 0x4d, 0x65,
-	0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x25, 0x0a, 0x0d, 0x50, 0x6f, 0x64,
-	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f,
-	0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64,
-	0x22, 0x49, 0x0a, 0x11, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x69,
-	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22, 0x38, 0x0a, 0x14, 0x53,
-	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61,
-	0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
-	0x6e, 0x44, 0x61, 0x74, 0x61, 0x22, 0x8e, 0x01, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x7f, 0x0a, 0x14, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x4b, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x72, 0x0a, 0x0f, 0x65, 0x76,
+	0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x69, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x46, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69,
 	0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f,
 	0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73,
-	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73,
-	0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x52, 0x14, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3e, 0x0a, 0x0c, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53,
-	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50,
-	0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x12, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53,
-	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x50, 0x69, 0x6e, 0x67, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0xab, 0x01, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74,
-	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e,
-	0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66,
-	0x69, 0x65, 0x72, 0x12, 0x36, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x4a, 0x6f, 0x69, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
-	0x70, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x36, 0x0a, 0x08, 0x74,
-	0x69, 0x6d, 0x65, 0x45, 0x78, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x45,
-	0x78, 0x69, 0x74, 0x22, 0xe4, 0x01, 0x0a, 0x0d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x67, 0x0a, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x43, 0x2e, 0x63, 0x65,
-	0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e,
-	0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61,
-	0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76,
-	0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65,
-	0x52, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x6a,
-	0x0a, 0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x44, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
-	0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78,
-	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73,
-	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x6c,
-	0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6c, 0x69,
-	0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x2a, 0x83, 0x01, 0x0a, 0x0b, 0x4d,
-	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e,
-	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x53,
-	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x55, 0x52, 0x41,
-	0x54, 0x49, 0x4f, 0x4e, 0x10, 0x64, 0x12, 0x10, 0x0a, 0x0c, 0x4f, 0x50, 0x45, 0x4e, 0x5f, 0x53,
-	0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x65, 0x12, 0x11, 0x0a, 0x0d, 0x43, 0x4c, 0x4f, 0x53,
-	0x45, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x66, 0x12, 0x10, 0x0a, 0x0c, 0x50,
-	0x49, 0x4e, 0x47, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x67, 0x12, 0x11, 0x0a,
-	0x0d, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x68,
-	0x2a, 0x5b, 0x0a, 0x0c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65,
-	0x12, 0x16, 0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x49, 0x4e, 0x49, 0x54, 0x49, 0x41,
-	0x4c, 0x49, 0x5a, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x54, 0x41, 0x54,
-	0x45, 0x5f, 0x4f, 0x50, 0x45, 0x4e, 0x10, 0x65, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x41, 0x54,
-	0x45, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x44, 0x10, 0x66, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54,
-	0x41, 0x54, 0x45, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x67, 0x32, 0x9b, 0x02,
-	0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x9b, 0x01, 0x0a, 0x0a, 0x67, 0x65, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
-	0x44, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x45, 0x76, 0x61, 0x63,
+	0x75, 0x61, 0x74, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0f, 0x65,
+	0x76, 0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x0d,
+	0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x25, 0x0a,
+	0x0d, 0x50, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x14,
+	0x0a, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70,
+	0x6f, 0x64, 0x49, 0x64, 0x22, 0x49, 0x0a, 0x11, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x6f, 0x64,
+	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x70, 0x6f, 0x64, 0x49, 0x64, 0x12,
+	0x1e, 0x0a, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x22,
+	0x60, 0x0a, 0x14, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0b, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x44, 0x61, 0x74, 0x61, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x22, 0x8e, 0x01, 0x0a, 0x0b, 0x4f, 0x70, 0x65, 0x6e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x7f, 0x0a, 0x14, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x4b, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61,
 	0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e,
 	0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
-	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x50, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74,
-	0x69, 0x66, 0x69, 0x65, 0x72, 0x1a, 0x45, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x14, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x3e, 0x0a, 0x0c, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f,
+	0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x12,
+	0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x22, 0x41, 0x0a, 0x0f, 0x45, 0x76, 0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x53, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2e, 0x0a, 0x12, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x12, 0x67, 0x72, 0x61, 0x63, 0x65, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x22, 0x0d, 0x0a, 0x0b, 0x50, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x22, 0xab, 0x01, 0x0a, 0x0d, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2a, 0x0a, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74,
+	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69,
+	0x65, 0x72, 0x12, 0x36, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x4a, 0x6f, 0x69, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x4a, 0x6f, 0x69, 0x6e, 0x12, 0x36, 0x0a, 0x08, 0x74, 0x69,
+	0x6d, 0x65, 0x45, 0x78, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x45, 0x78,
+	0x69, 0x74, 0x22, 0x8c, 0x02, 0x0a, 0x0d, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x67, 0x0a, 0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x43, 0x2e, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66,
+	0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67,
+	0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x0c, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x6a, 0x0a,
+	0x0d, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x44, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73,
+	0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63,
+	0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x43, 0x6c, 0x69,
+	0x65, 0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x68, 0x65,
+	0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x44, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0e, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x2a, 0x99, 0x01, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4f,
+	0x4e, 0x46, 0x49, 0x47, 0x55, 0x52, 0x41, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x64, 0x12, 0x10, 0x0a,
+	0x0c, 0x4f, 0x50, 0x45, 0x4e, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x65, 0x12,
+	0x11, 0x0a, 0x0d, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e,
+	0x10, 0x66, 0x12, 0x10, 0x0a, 0x0c, 0x50, 0x49, 0x4e, 0x47, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49,
+	0x4f, 0x4e, 0x10, 0x67, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f,
+	0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x68, 0x12, 0x14, 0x0a, 0x10, 0x45, 0x56, 0x41, 0x43, 0x55,
+	0x41, 0x54, 0x45, 0x5f, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x10, 0x69, 0x2a, 0x86, 0x01,
+	0x0a, 0x0c, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16,
+	0x0a, 0x12, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x49, 0x4e, 0x49, 0x54, 0x49, 0x41, 0x4c, 0x49,
+	0x5a, 0x49, 0x4e, 0x47, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f,
+	0x4f, 0x50, 0x45, 0x4e, 0x10, 0x65, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f,
+	0x43, 0x4c, 0x4f, 0x53, 0x45, 0x44, 0x10, 0x66, 0x12, 0x11, 0x0a, 0x0d, 0x53, 0x54, 0x41, 0x54,
+	0x45, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x49, 0x4e, 0x47, 0x10, 0x67, 0x12, 0x14, 0x0a, 0x10, 0x53,
+	0x54, 0x41, 0x54, 0x45, 0x5f, 0x45, 0x56, 0x41, 0x43, 0x55, 0x41, 0x54, 0x49, 0x4e, 0x47, 0x10,
+	0x68, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x45, 0x56, 0x41, 0x43, 0x55,
+	0x41, 0x54, 0x45, 0x44, 0x10, 0x69, 0x32, 0x9b, 0x02, 0x0a, 0x0e, 0x53, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x9b, 0x01, 0x0a, 0x0a, 0x67, 0x65,
+	0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x44, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61,
+	0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72,
+	0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e,
+	0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x2e, 0x50, 0x6f, 0x64, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x1a, 0x45,
+	0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e,
+	0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f,
+	0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x6b, 0x0a, 0x0a, 0x70, 0x75, 0x74, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x45, 0x2e, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
 	0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78,
 	0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73,
 	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65,
-	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x30, 0x01, 0x12, 0x6b,
-	0x0a, 0x0a, 0x70, 0x75, 0x74, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x45, 0x2e, 0x63,
-	0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f,
-	0x2e, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x63, 0x6f, 0x72, 0x65, 0x2e, 0x6e, 0x6f, 0x64, 0x65,
-	0x61, 0x67, 0x65, 0x6e, 0x74, 0x2e, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x47, 0x5a, 0x45, 0x63,
-	0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75, 0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f,
-	0x2f, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x6c, 0x65,
-	0x73, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74,
-	0x2f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f,
-	0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x42, 0x47, 0x5a, 0x45, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x75, 0x72, 0x75,
+	0x73, 0x69, 0x6e, 0x66, 0x72, 0x61, 0x2e, 0x69, 0x6f, 0x2f, 0x66, 0x6f, 0x72, 0x6e, 0x61, 0x78,
+	0x2d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x6c, 0x65, 0x73, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f,
+	0x6e, 0x6f, 0x64, 0x65, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x2f, 0x73, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -871,7 +981,7 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDescGZIP()
 }
 
 var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
 var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_goTypes = []interface{}{
 	(MessageType)(0),             // 0: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.MessageType
 	(SessionState)(0),            // 1: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionState
@@ -881,11 +991,12 @@ var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_goTypes = []int
 	(*SessionConfiguration)(nil), // 5: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionConfiguration
 	(*OpenSession)(nil),          // 6: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.OpenSession
 	(*CloseSession)(nil),         // 7: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.CloseSession
-	(*PingSession)(nil),          // 8: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PingSession
-	(*ClientSession)(nil),        // 9: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession
-	(*SessionStatus)(nil),        // 10: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus
-	(*timestamp.Timestamp)(nil),  // 11: google.protobuf.Timestamp
-	(*empty.Empty)(nil),          // 12: google.protobuf.Empty
+	(*EvacuateSession)(nil),      // 8: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.EvacuateSession
+	(*PingSession)(nil),          // 9: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PingSession
+	(*ClientSession)(nil),        // 10: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession
+	(*SessionStatus)(nil),        // 11: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus
+	(*timestamp.Timestamp)(nil),  // 12: google.protobuf.Timestamp
+	(*empty.Empty)(nil),          // 13: google.protobuf.Empty
 }
 var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_depIdxs = []int32{
 	4,  // 0: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.sessionIdentifier:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionIdentifier
@@ -893,22 +1004,23 @@ var file_pkg_nodeagent_sessionservice_grpc_session_service_proto_depIdxs = []int
 	5,  // 2: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.sessionConfiguration:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionConfiguration
 	6,  // 3: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.openSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.OpenSession
 	7,  // 4: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.closeSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.CloseSession
-	8,  // 5: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.pingSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PingSession
-	10, // 6: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.sessionStatus:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus
-	5,  // 7: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.OpenSession.sessionConfiguration:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionConfiguration
-	11, // 8: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession.timeJoin:type_name -> google.protobuf.Timestamp
-	11, // 9: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession.timeExit:type_name -> google.protobuf.Timestamp
-	1,  // 10: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus.sessionState:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionState
-	9,  // 11: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus.clientSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession
-	3,  // 12: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.getMessage:input_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PodIdentifier
-	2,  // 13: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.putMessage:input_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage
-	2,  // 14: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.getMessage:output_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage
-	12, // 15: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.putMessage:output_type -> google.protobuf.Empty
-	14, // [14:16] is the sub-list for method output_type
-	12, // [12:14] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	9,  // 5: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.pingSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PingSession
+	11, // 6: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.sessionStatus:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus
+	8,  // 7: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage.evacuateSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.EvacuateSession
+	5,  // 8: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.OpenSession.sessionConfiguration:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionConfiguration
+	12, // 9: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession.timeJoin:type_name -> google.protobuf.Timestamp
+	12, // 10: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession.timeExit:type_name -> google.protobuf.Timestamp
+	1,  // 11: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus.sessionState:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionState
+	10, // 12: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionStatus.clientSession:type_name -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.ClientSession
+	3,  // 13: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.getMessage:input_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.PodIdentifier
+	2,  // 14: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.putMessage:input_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage
+	2,  // 15: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.getMessage:output_type -> centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionMessage
+	13, // 16: centaurusinfra.io.fornaxcore.nodeagent.sessionservice.SessionService.putMessage:output_type -> google.protobuf.Empty
+	15, // [15:17] is the sub-list for method output_type
+	13, // [13:15] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() }
@@ -990,7 +1102,7 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() {
 			}
 		}
 		file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*PingSession); i {
+			switch v := v.(*EvacuateSession); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1002,7 +1114,7 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() {
 			}
 		}
 		file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ClientSession); i {
+			switch v := v.(*PingSession); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1014,6 +1126,18 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() {
 			}
 		}
 		file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ClientSession); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_nodeagent_sessionservice_grpc_session_service_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SessionStatus); i {
 			case 0:
 				return &v.state
@@ -1032,6 +1156,7 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() {
 		(*SessionMessage_CloseSession)(nil),
 		(*SessionMessage_PingSession)(nil),
 		(*SessionMessage_SessionStatus)(nil),
+		(*SessionMessage_EvacuateSession)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1039,7 +1164,7 @@ func file_pkg_nodeagent_sessionservice_grpc_session_service_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_nodeagent_sessionservice_grpc_session_service_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   9,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
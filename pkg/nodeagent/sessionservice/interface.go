@@ -34,4 +34,5 @@ type SessionService interface {
 	OpenSession(pod *types.FornaxPod, session *types.FornaxSession, stateCallbackFunc func(internal.SessionState)) error
 	CloseSession(pod *types.FornaxPod, session *types.FornaxSession, graceSeconds uint16) error
 	PingSession(pod *types.FornaxPod, session *types.FornaxSession, stateCallbackFunc func(internal.SessionState)) error
+	EvacuateSession(pod *types.FornaxPod, session *types.FornaxSession, graceSeconds uint16) error
 }
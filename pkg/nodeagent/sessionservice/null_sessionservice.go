@@ -42,6 +42,21 @@ func (f *NullSessionService) CloseSession(pod *types.FornaxPod, session *types.F
 	return nil
 }
 
+// EvacuateSession implements SessionService, null session has no state to checkpoint, it's evacuated immediately
+func (f *NullSessionService) EvacuateSession(pod *types.FornaxPod, session *types.FornaxSession, graceseconds uint16) error {
+	if c, found := f.stateCallbackFuncs[session.Identifier]; found {
+		c(internal.SessionState{
+			SessionId:      session.Identifier,
+			SessionState:   types.SessionStateEvacuated,
+			ClientSessions: []types.ClientSession{},
+		})
+		delete(f.stateCallbackFuncs, session.Identifier)
+	} else {
+		return SessionNotFound
+	}
+	return nil
+}
+
 // OpenSession implements SessionService
 func (f *NullSessionService) OpenSession(pod *types.FornaxPod, session *types.FornaxSession, stateCallbackFunc func(internal.SessionState)) error {
 	f.stateCallbackFuncs[session.Identifier] = stateCallbackFunc
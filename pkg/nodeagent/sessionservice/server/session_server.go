@@ -35,6 +35,11 @@ func (*sessionServer) CloseSession(pod *types.FornaxPod, session *types.FornaxSe
 	panic("unimplemented")
 }
 
+// EvacuateSession implements sessionservice.SessionService
+func (*sessionServer) EvacuateSession(pod *types.FornaxPod, session *types.FornaxSession, graceSeconds uint16) error {
+	panic("unimplemented")
+}
+
 // OpenSession implements sessionservice.SessionService
 func (*sessionServer) OpenSession(pod *types.FornaxPod, session *types.FornaxSession, stateCallbackFunc func(internal.SessionState)) error {
 	panic("unimplemented")
@@ -48,6 +53,6 @@ func (*sessionServer) PingSession(pod *types.FornaxPod, session *types.FornaxSes
 func NewSessionService() *sessionServer {
 	return &sessionServer{
 		nullService: &sessionservice.NullSessionService{},
-		grpcService: session_grpc.NewSessionService(),
+		grpcService: session_grpc.NewSessionService(nil, nil),
 	}
 }
@@ -0,0 +1,93 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sessionservice
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	podTokenKeySize = 32
+)
+
+var (
+	InvalidPodToken = errors.New("invalid pod session service token")
+)
+
+// PodTokenAuthenticator issue and verify pod session service tokens,
+// token is uid.signature, signature is hmac sha256 of pod id and pod uid signed by a node key,
+// node key is persisted, so tokens issued before node agent restart are still valid
+type PodTokenAuthenticator struct {
+	key []byte
+}
+
+// IssueToken return a token of pod, token is injected into pod containers and presented to session service by instance
+func (a *PodTokenAuthenticator) IssueToken(podId, podUID string) string {
+	return fmt.Sprintf("%s.%s", podUID, a.sign(podId, podUID))
+}
+
+// VerifyToken check token is issued for pod id, it return pod uid in token
+func (a *PodTokenAuthenticator) VerifyToken(podId, token string) (string, error) {
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return "", InvalidPodToken
+	}
+	podUID, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(a.sign(podId, podUID))) {
+		return "", InvalidPodToken
+	}
+	return podUID, nil
+}
+
+func (a *PodTokenAuthenticator) sign(podId, podUID string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(podId))
+	mac.Write([]byte{0})
+	mac.Write([]byte(podUID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// NewPodTokenAuthenticator load node key from keyFile, a random key is generated and saved if file does not exist
+func NewPodTokenAuthenticator(keyFile string) (*PodTokenAuthenticator, error) {
+	key, err := os.ReadFile(keyFile)
+	if err == nil && len(key) >= podTokenKeySize {
+		return &PodTokenAuthenticator{key: key}, nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key = make([]byte, podTokenKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyFile, key, 0600); err != nil {
+		return nil, err
+	}
+	return &PodTokenAuthenticator{key: key}, nil
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sessionservice
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPodToken(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "sessionservice", "token.key")
+	a, err := NewPodTokenAuthenticator(keyFile)
+	if err != nil {
+		t.Fatalf("failed to create pod token authenticator, %v", err)
+	}
+
+	token := a.IssueToken("test/pod", "pod-uid")
+	if uid, err := a.VerifyToken("test/pod", token); err != nil || uid != "pod-uid" {
+		t.Errorf("expect token verified with pod uid, got %s, %v", uid, err)
+	}
+	if _, err := a.VerifyToken("test/other", token); err == nil {
+		t.Errorf("expect token of other pod is rejected")
+	}
+	if _, err := a.VerifyToken("test/pod", "other-uid"+token[len("pod-uid"):]); err == nil {
+		t.Errorf("expect token with tampered uid is rejected")
+	}
+	if _, err := a.VerifyToken("test/pod", ""); err == nil {
+		t.Errorf("expect empty token is rejected")
+	}
+
+	// node key is reloaded after node agent restart
+	b, err := NewPodTokenAuthenticator(keyFile)
+	if err != nil {
+		t.Fatalf("failed to reload pod token authenticator, %v", err)
+	}
+	if _, err := b.VerifyToken("test/pod", token); err != nil {
+		t.Errorf("expect token issued before restart is valid, %v", err)
+	}
+
+	c, err := NewPodTokenAuthenticator(filepath.Join(t.TempDir(), "token.key"))
+	if err != nil {
+		t.Fatalf("failed to create pod token authenticator, %v", err)
+	}
+	if _, err := c.VerifyToken("test/pod", token); err == nil {
+		t.Errorf("expect token signed by other node is rejected")
+	}
+}
@@ -34,7 +34,7 @@ const (
 	PodStateCreated PodState = "Created"
 	// PosStateRunning is a state when startup and readiness probe passed
 	PodStateRunning PodState = "Running"
-	// PosStateEvacuating is a state preserved for use to evacuate session
+	// PosStateEvacuating is a state when fornax core require to evacuate sessions, pod is terminated after sessions are evacuated
 	PodStateEvacuating PodState = "Evacuating"
 	// PosStateTerminating is a state when fornax core require to terminate
 	PodStateTerminating PodState = "Terminating"
@@ -60,6 +60,7 @@ const (
 	ContainerStateRunning     ContainerState = "Running"
 	ContainerStateHibernated  ContainerState = "Hibernated"
 	ContainerStateStarted     ContainerState = "Started"
+	ContainerStateRestarting  ContainerState = "Restarting"
 )
 
 type FornaxContainer struct {
@@ -82,6 +83,8 @@ type FornaxPod struct {
 	Daemon                  bool                        `json:"daemon,omitempty"`
 	Pod                     *v1.Pod                     `json:"pod,omitempty"`
 	ConfigMap               *v1.ConfigMap               `json:"configMap,omitempty"`
+	Secrets                 []*v1.Secret                `json:"secrets,omitempty"`
+	ImagePullSecrets        []*v1.Secret                `json:"imagePullSecrets,omitempty"`
 	RuntimePod              *runtime.Pod                `json:"runtimePod,omitempty"`
 	Containers              map[string]*FornaxContainer `json:"containers"`
 	Sessions                map[string]*FornaxSession   `json:"sessions"`
@@ -97,6 +100,8 @@ const (
 	SessionStateClosed      SessionState = "Closed"
 	SessionStateClosing     SessionState = "Closing"
 	SessionStateNoHeartbeat SessionState = "NoHeartbeat"
+	SessionStateEvacuating  SessionState = "Evacuating"
+	SessionStateEvacuated   SessionState = "Evacuated"
 )
 
 type ClientSession struct {
@@ -117,7 +122,8 @@ func UniquePodName(pod *FornaxPod) string {
 
 func PodHasOpenSessions(pod *FornaxPod) bool {
 	for _, v := range pod.Sessions {
-		if v.Session.Status.SessionStatus != fornaxv1.SessionStatusClosed || len(v.ClientSessions) > 0 {
+		sessionLeft := v.Session.Status.SessionStatus == fornaxv1.SessionStatusClosed || v.Session.Status.SessionStatus == fornaxv1.SessionStatusEvacuated
+		if !sessionLeft || len(v.ClientSessions) > 0 {
 			return true
 		}
 	}
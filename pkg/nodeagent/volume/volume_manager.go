@@ -0,0 +1,502 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
+	"centaurusinfra.io/fornax-serverless/pkg/nodeagent/runtime"
+	v1 "k8s.io/api/core/v1"
+	criv1 "k8s.io/cri-api/pkg/apis/runtime/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/mount-utils"
+)
+
+const (
+	EmptyDirPluginName  = "empty-dir"
+	ConfigMapPluginName = "configmap"
+	SecretPluginName    = "secret"
+	ProjectedPluginName = "projected"
+
+	DefaultVolumeFileMode int32 = 0644
+)
+
+var (
+	ErrUnsupportedVolume  = errors.New("volume type is not supported")
+	ErrHostPathNotAllowed = errors.New("host path is not allowed by node")
+)
+
+// PodVolumeManager prepare pod volumes in pod volumes dir on host before pod containers are created,
+// and remove them when pod is cleaned up, supported volumes are emptyDir, hostPath allowed by node,
+// and configMap, secret and projected volumes using config data and secret data delivered with pod
+type PodVolumeManager struct {
+	rootPath         string
+	allowedHostPaths []string
+	mounter          mount.Interface
+}
+
+func NewPodVolumeManager(rootPath string, allowedHostPaths []string, mounter mount.Interface) *PodVolumeManager {
+	paths := []string{}
+	for _, v := range allowedHostPaths {
+		paths = append(paths, filepath.Clean(v))
+	}
+	return &PodVolumeManager{
+		rootPath:         rootPath,
+		allowedHostPaths: paths,
+		mounter:          mounter,
+	}
+}
+
+// SetupPodVolumes create host dirs and files of pod volumes, configMap and secrets delivered with pod are the only ones pod volumes can refer to
+func (m *PodVolumeManager) SetupPodVolumes(pod *v1.Pod, configMap *v1.ConfigMap, secrets []*v1.Secret) error {
+	for _, volume := range pod.Spec.Volumes {
+		if err := m.setupVolume(pod, &volume, configMap, secrets); err != nil {
+			return fmt.Errorf("failed to setup volume %s, %v", volume.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *PodVolumeManager) setupVolume(pod *v1.Pod, volume *v1.Volume, configMap *v1.ConfigMap, secrets []*v1.Secret) error {
+	hostPath, err := m.volumeHostPath(pod, volume)
+	if err != nil {
+		return err
+	}
+	switch {
+	case volume.EmptyDir != nil:
+		return m.setupEmptyDir(hostPath, volume.EmptyDir)
+	case volume.HostPath != nil:
+		return checkHostPathType(hostPath, volume.HostPath.Type)
+	case volume.ConfigMap != nil:
+		files, err := configMapVolumeFiles(volume.ConfigMap, configMap)
+		if err != nil {
+			return err
+		}
+		return writeVolumeFiles(hostPath, files)
+	case volume.Secret != nil:
+		files, err := secretVolumeFiles(volume.Secret, secrets)
+		if err != nil {
+			return err
+		}
+		return writeVolumeFiles(hostPath, files)
+	case volume.Projected != nil:
+		files, err := projectedVolumeFiles(volume.Projected, configMap, secrets)
+		if err != nil {
+			return err
+		}
+		return writeVolumeFiles(hostPath, files)
+	}
+	return ErrUnsupportedVolume
+}
+
+// volumeHostPath return host path of a pod volume
+func (m *PodVolumeManager) volumeHostPath(pod *v1.Pod, volume *v1.Volume) (string, error) {
+	switch {
+	case volume.EmptyDir != nil:
+		return config.GetPodVolumeDir(m.rootPath, pod.UID, EmptyDirPluginName, volume.Name), nil
+	case volume.HostPath != nil:
+		return m.allowedHostPath(volume.HostPath.Path)
+	case volume.ConfigMap != nil:
+		return config.GetPodVolumeDir(m.rootPath, pod.UID, ConfigMapPluginName, volume.Name), nil
+	case volume.Secret != nil:
+		return config.GetPodVolumeDir(m.rootPath, pod.UID, SecretPluginName, volume.Name), nil
+	case volume.Projected != nil:
+		return config.GetPodVolumeDir(m.rootPath, pod.UID, ProjectedPluginName, volume.Name), nil
+	}
+	return "", ErrUnsupportedVolume
+}
+
+// allowedHostPath check host path is under one of node allowed host paths and return its symlink resolved path,
+// resolved path is checked again against resolved allowed path, a symlink under allowed path can not escape it
+func (m *PodVolumeManager) allowedHostPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("host path %s is not a absolute path", path)
+	}
+	path = filepath.Clean(path)
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", err
+	}
+	for _, allowed := range m.allowedHostPaths {
+		if !isSubPath(allowed, path) {
+			continue
+		}
+		resolvedAllowed, err := resolvePath(allowed)
+		if err != nil {
+			return "", err
+		}
+		if isSubPath(resolvedAllowed, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", ErrHostPathNotAllowed
+}
+
+// resolvePath evaluate symlinks of a absolute path, part of path which does not exist yet is kept as it is
+func resolvePath(path string) (string, error) {
+	rest := ""
+	for p := path; ; p = filepath.Dir(p) {
+		resolved, err := filepath.EvalSymlinks(p)
+		if err == nil {
+			return filepath.Join(resolved, rest), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if p == filepath.Dir(p) {
+			return path, nil
+		}
+		rest = filepath.Join(filepath.Base(p), rest)
+	}
+}
+
+// isSubPath return true if path is root itself or under root
+func isSubPath(root, path string) bool {
+	return path == root || root == "/" || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+func (m *PodVolumeManager) setupEmptyDir(hostPath string, emptyDir *v1.EmptyDirVolumeSource) error {
+	if err := os.MkdirAll(hostPath, 0777); err != nil {
+		return err
+	}
+	// mkdir is affected by umask, make sure containers running as any user can write it
+	if err := os.Chmod(hostPath, 0777); err != nil {
+		return err
+	}
+	switch emptyDir.Medium {
+	case v1.StorageMediumDefault:
+		return nil
+	case v1.StorageMediumMemory:
+		notMnt, err := m.mounter.IsLikelyNotMountPoint(hostPath)
+		if err != nil {
+			return err
+		}
+		if !notMnt {
+			// already mounted when pod was created before node agent restart
+			return nil
+		}
+		options := []string{}
+		if emptyDir.SizeLimit != nil && !emptyDir.SizeLimit.IsZero() {
+			options = append(options, fmt.Sprintf("size=%d", emptyDir.SizeLimit.Value()))
+		}
+		return m.mounter.MountSensitiveWithoutSystemd("tmpfs", hostPath, "tmpfs", options, nil)
+	}
+	return fmt.Errorf("emptyDir medium %s is not supported", emptyDir.Medium)
+}
+
+func checkHostPathType(path string, pathType *v1.HostPathType) error {
+	if pathType == nil {
+		return nil
+	}
+	info, statErr := os.Stat(path)
+	switch *pathType {
+	case v1.HostPathUnset:
+		return nil
+	case v1.HostPathDirectoryOrCreate:
+		if os.IsNotExist(statErr) {
+			return os.MkdirAll(path, 0755)
+		}
+		if statErr == nil && !info.IsDir() {
+			return fmt.Errorf("host path %s is not a directory", path)
+		}
+		return statErr
+	case v1.HostPathFileOrCreate:
+		if os.IsNotExist(statErr) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_RDONLY, 0644)
+			if err != nil {
+				return err
+			}
+			return f.Close()
+		}
+		if statErr == nil && !info.Mode().IsRegular() {
+			return fmt.Errorf("host path %s is not a file", path)
+		}
+		return statErr
+	}
+
+	if statErr != nil {
+		return statErr
+	}
+	var expected bool
+	switch *pathType {
+	case v1.HostPathDirectory:
+		expected = info.IsDir()
+	case v1.HostPathFile:
+		expected = info.Mode().IsRegular()
+	case v1.HostPathSocket:
+		expected = info.Mode()&os.ModeSocket != 0
+	case v1.HostPathCharDev:
+		expected = info.Mode()&os.ModeCharDevice != 0
+	case v1.HostPathBlockDev:
+		expected = info.Mode()&os.ModeDevice != 0 && info.Mode()&os.ModeCharDevice == 0
+	default:
+		return fmt.Errorf("host path type %s is not supported", *pathType)
+	}
+	if !expected {
+		return fmt.Errorf("host path %s is not a %s", path, *pathType)
+	}
+	return nil
+}
+
+// volumeFile is a file written into a configMap, secret or projected volume
+type volumeFile struct {
+	data []byte
+	mode int32
+}
+
+func configMapVolumeFiles(source *v1.ConfigMapVolumeSource, configMap *v1.ConfigMap) (map[string]volumeFile, error) {
+	files := map[string]volumeFile{}
+	err := addConfigMapFiles(files, source.Name, source.Items, source.Optional, volumeFileMode(source.DefaultMode), configMap)
+	return files, err
+}
+
+func secretVolumeFiles(source *v1.SecretVolumeSource, secrets []*v1.Secret) (map[string]volumeFile, error) {
+	files := map[string]volumeFile{}
+	err := addSecretFiles(files, source.SecretName, source.Items, source.Optional, volumeFileMode(source.DefaultMode), secrets)
+	return files, err
+}
+
+func projectedVolumeFiles(source *v1.ProjectedVolumeSource, configMap *v1.ConfigMap, secrets []*v1.Secret) (map[string]volumeFile, error) {
+	files := map[string]volumeFile{}
+	mode := volumeFileMode(source.DefaultMode)
+	for _, v := range source.Sources {
+		var err error
+		switch {
+		case v.ConfigMap != nil:
+			err = addConfigMapFiles(files, v.ConfigMap.Name, v.ConfigMap.Items, v.ConfigMap.Optional, mode, configMap)
+		case v.Secret != nil:
+			err = addSecretFiles(files, v.Secret.Name, v.Secret.Items, v.Secret.Optional, mode, secrets)
+		default:
+			err = ErrUnsupportedVolume
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func addConfigMapFiles(files map[string]volumeFile, name string, items []v1.KeyToPath, optional *bool, mode int32, configMap *v1.ConfigMap) error {
+	if configMap == nil || configMap.Name != name {
+		if optional != nil && *optional {
+			return nil
+		}
+		return fmt.Errorf("config map %s not found", name)
+	}
+	data := map[string][]byte{}
+	for k, v := range configMap.Data {
+		data[k] = []byte(v)
+	}
+	for k, v := range configMap.BinaryData {
+		data[k] = v
+	}
+	return addKeyFiles(files, data, items, optional, mode)
+}
+
+func addSecretFiles(files map[string]volumeFile, name string, items []v1.KeyToPath, optional *bool, mode int32, secrets []*v1.Secret) error {
+	for _, secret := range secrets {
+		if secret != nil && secret.Name == name {
+			return addKeyFiles(files, secret.Data, items, optional, mode)
+		}
+	}
+	if optional != nil && *optional {
+		return nil
+	}
+	return fmt.Errorf("secret %s not found", name)
+}
+
+// addKeyFiles add a file for every key, or only keys in items using item path and mode if items are provided
+func addKeyFiles(files map[string]volumeFile, data map[string][]byte, items []v1.KeyToPath, optional *bool, mode int32) error {
+	if len(items) == 0 {
+		for k, v := range data {
+			files[k] = volumeFile{data: v, mode: mode}
+		}
+		return nil
+	}
+	for _, item := range items {
+		v, found := data[item.Key]
+		if !found {
+			if optional != nil && *optional {
+				continue
+			}
+			return fmt.Errorf("key %s not found", item.Key)
+		}
+		itemMode := mode
+		if item.Mode != nil {
+			itemMode = *item.Mode
+		}
+		files[item.Path] = volumeFile{data: v, mode: itemMode}
+	}
+	return nil
+}
+
+func volumeFileMode(mode *int32) int32 {
+	if mode == nil {
+		return DefaultVolumeFileMode
+	}
+	return *mode
+}
+
+// writeVolumeFiles write files into volume dir, file path must be a relative path inside volume dir
+func writeVolumeFiles(dir string, files map[string]volumeFile) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for path, file := range files {
+		if err := validateRelativePath(path); err != nil {
+			return err
+		}
+		fullPath := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(fullPath, file.data, os.FileMode(file.mode)); err != nil {
+			return err
+		}
+		// WriteFile mode is affected by umask, set it explicitly
+		if err := os.Chmod(fullPath, os.FileMode(file.mode)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRelativePath make sure a path does not escape the dir it is joined to
+func validateRelativePath(path string) error {
+	if len(path) == 0 || filepath.IsAbs(path) {
+		return fmt.Errorf("path %s must be a relative path", path)
+	}
+	for _, v := range strings.Split(filepath.ToSlash(path), "/") {
+		if v == ".." {
+			return fmt.Errorf("path %s must not contain '..'", path)
+		}
+	}
+	return nil
+}
+
+// GetContainerMounts build container mounts from container volume mounts using host paths of pod volumes,
+// configMap, secret and projected volumes are always mounted read only
+func (m *PodVolumeManager) GetContainerMounts(pod *v1.Pod, container *v1.Container) ([]runtime.Mount, error) {
+	volumes := map[string]*v1.Volume{}
+	for i := range pod.Spec.Volumes {
+		volumes[pod.Spec.Volumes[i].Name] = &pod.Spec.Volumes[i]
+	}
+
+	mounts := []runtime.Mount{}
+	for _, volumeMount := range container.VolumeMounts {
+		volume, found := volumes[volumeMount.Name]
+		if !found {
+			return nil, fmt.Errorf("volume %s of container %s is not found in pod volumes", volumeMount.Name, container.Name)
+		}
+		hostPath, err := m.volumeHostPath(pod, volume)
+		if err != nil {
+			return nil, err
+		}
+		if len(volumeMount.SubPath) > 0 {
+			if err := validateRelativePath(volumeMount.SubPath); err != nil {
+				return nil, err
+			}
+			volumePath := hostPath
+			hostPath = filepath.Join(hostPath, volumeMount.SubPath)
+			if volume.EmptyDir != nil {
+				if err := os.MkdirAll(hostPath, 0777); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := os.Stat(hostPath); err != nil {
+				return nil, fmt.Errorf("sub path %s of volume %s is not found, %v", volumeMount.SubPath, volume.Name, err)
+			}
+			// container can create symlink in a writable volume, sub path must still be inside volume after resolving symlinks
+			resolvedVolume, err := filepath.EvalSymlinks(volumePath)
+			if err != nil {
+				return nil, err
+			}
+			if hostPath, err = filepath.EvalSymlinks(hostPath); err != nil {
+				return nil, err
+			}
+			if !isSubPath(resolvedVolume, hostPath) {
+				return nil, fmt.Errorf("sub path %s of volume %s is outside of volume", volumeMount.SubPath, volume.Name)
+			}
+		}
+		propagation, err := translateMountPropagation(container, volumeMount.MountPropagation)
+		if err != nil {
+			return nil, err
+		}
+		readOnly := volumeMount.ReadOnly || volume.ConfigMap != nil || volume.Secret != nil || volume.Projected != nil
+		mounts = append(mounts, runtime.Mount{
+			Name:           volumeMount.Name,
+			ContainerPath:  volumeMount.MountPath,
+			HostPath:       hostPath,
+			ReadOnly:       readOnly,
+			SELinuxRelabel: volume.HostPath == nil,
+			Propagation:    propagation,
+		})
+	}
+	return mounts, nil
+}
+
+func translateMountPropagation(container *v1.Container, mode *v1.MountPropagationMode) (criv1.MountPropagation, error) {
+	if mode == nil {
+		return criv1.MountPropagation_PROPAGATION_PRIVATE, nil
+	}
+	switch *mode {
+	case v1.MountPropagationNone:
+		return criv1.MountPropagation_PROPAGATION_PRIVATE, nil
+	case v1.MountPropagationHostToContainer:
+		return criv1.MountPropagation_PROPAGATION_HOST_TO_CONTAINER, nil
+	case v1.MountPropagationBidirectional:
+		privileged := container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged
+		if !privileged {
+			return criv1.MountPropagation_PROPAGATION_PRIVATE, fmt.Errorf("bidirectional mount propagation is only allowed for privileged container %s", container.Name)
+		}
+		return criv1.MountPropagation_PROPAGATION_BIDIRECTIONAL, nil
+	}
+	return criv1.MountPropagation_PROPAGATION_PRIVATE, fmt.Errorf("mount propagation %s is not supported", *mode)
+}
+
+// CleanupPodVolumes unmount memory backed emptyDir volumes and remove pod volumes dir,
+// host path volumes are never touched
+func (m *PodVolumeManager) CleanupPodVolumes(pod *v1.Pod) error {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir == nil || volume.EmptyDir.Medium != v1.StorageMediumMemory {
+			continue
+		}
+		hostPath := config.GetPodVolumeDir(m.rootPath, pod.UID, EmptyDirPluginName, volume.Name)
+		if _, err := os.Stat(hostPath); os.IsNotExist(err) {
+			continue
+		}
+		notMnt, err := m.mounter.IsLikelyNotMountPoint(hostPath)
+		if err != nil {
+			return err
+		}
+		if !notMnt {
+			klog.InfoS("Unmount memory emptyDir volume", "volume", volume.Name, "path", hostPath)
+			if err := m.mounter.Unmount(hostPath); err != nil {
+				return err
+			}
+		}
+	}
+	return os.RemoveAll(config.GetPodVolumesDir(m.rootPath, pod.UID))
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volume
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/mount-utils"
+)
+
+func newTestPod(volumes []v1.Volume, mounts []v1.VolumeMount) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test", UID: "pod-uid"},
+		Spec: v1.PodSpec{
+			Volumes:    volumes,
+			Containers: []v1.Container{{Name: "app", VolumeMounts: mounts}},
+		},
+	}
+}
+
+func TestSetupAndCleanupPodVolumes(t *testing.T) {
+	rootPath := t.TempDir()
+	hostDir := t.TempDir()
+	m := NewPodVolumeManager(rootPath, []string{hostDir}, mount.NewFakeMounter(nil))
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app"}, Data: map[string]string{"a.conf": "a", "b.conf": "b"}}
+	secrets := []*v1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "db"}, Data: map[string][]byte{"password": []byte("db-secret")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "api"}, Data: map[string][]byte{"token": []byte("secret")}},
+	}
+	secretMode := int32(0400)
+	pod := newTestPod([]v1.Volume{
+		{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+		{Name: "host", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: filepath.Join(hostDir, "data")}}},
+		{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{
+			LocalObjectReference: v1.LocalObjectReference{Name: "app"},
+			Items:                []v1.KeyToPath{{Key: "a.conf", Path: "conf/a.conf"}},
+		}}},
+		{Name: "all", VolumeSource: v1.VolumeSource{Projected: &v1.ProjectedVolumeSource{Sources: []v1.VolumeProjection{
+			{ConfigMap: &v1.ConfigMapProjection{LocalObjectReference: v1.LocalObjectReference{Name: "app"}}},
+			{Secret: &v1.SecretProjection{LocalObjectReference: v1.LocalObjectReference{Name: "api"}, Items: []v1.KeyToPath{{Key: "token", Path: "token", Mode: &secretMode}}}},
+		}}}},
+		{Name: "db", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "db"}}},
+	}, []v1.VolumeMount{
+		{Name: "scratch", MountPath: "/tmp/scratch", SubPath: "work"},
+		{Name: "host", MountPath: "/data"},
+		{Name: "config", MountPath: "/etc/app"},
+		{Name: "all", MountPath: "/etc/all"},
+		{Name: "db", MountPath: "/etc/db"},
+	})
+	hostPathType := v1.HostPathDirectoryOrCreate
+	pod.Spec.Volumes[1].HostPath.Type = &hostPathType
+
+	if err := m.SetupPodVolumes(pod, configMap, secrets); err != nil {
+		t.Fatalf("failed to setup pod volumes, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hostDir, "data")); err != nil {
+		t.Errorf("expect host path created, %v", err)
+	}
+	configDir := filepath.Join(rootPath, "pods", "pod-uid", "volumes", ConfigMapPluginName, "config")
+	if data, err := os.ReadFile(filepath.Join(configDir, "conf", "a.conf")); err != nil || string(data) != "a" {
+		t.Errorf("expect config item written, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(configDir, "b.conf")); !os.IsNotExist(err) {
+		t.Errorf("expect key not in items is not written, %v", err)
+	}
+	projectedDir := filepath.Join(rootPath, "pods", "pod-uid", "volumes", ProjectedPluginName, "all")
+	if info, err := os.Stat(filepath.Join(projectedDir, "token")); err != nil || info.Mode().Perm() != 0400 {
+		t.Errorf("expect secret written with mode 0400, got %v, %v", info, err)
+	}
+	secretDir := filepath.Join(rootPath, "pods", "pod-uid", "volumes", SecretPluginName, "db")
+	if data, err := os.ReadFile(filepath.Join(secretDir, "password")); err != nil || string(data) != "db-secret" {
+		t.Errorf("expect secret volume written from referred secret, got %q, %v", data, err)
+	}
+
+	mounts, err := m.GetContainerMounts(pod, &pod.Spec.Containers[0])
+	if err != nil {
+		t.Fatalf("failed to get container mounts, %v", err)
+	}
+	if len(mounts) != 5 {
+		t.Fatalf("expect 5 mounts, got %v", mounts)
+	}
+	if mounts[0].HostPath != filepath.Join(rootPath, "pods", "pod-uid", "volumes", EmptyDirPluginName, "scratch", "work") || mounts[0].ReadOnly {
+		t.Errorf("unexpected emptyDir sub path mount %v", mounts[0])
+	}
+	if mounts[1].HostPath != filepath.Join(hostDir, "data") {
+		t.Errorf("unexpected host path mount %v", mounts[1])
+	}
+	if !mounts[2].ReadOnly || !mounts[3].ReadOnly || !mounts[4].ReadOnly {
+		t.Errorf("expect config volumes mounted read only, got %v", mounts)
+	}
+
+	if err := m.CleanupPodVolumes(pod); err != nil {
+		t.Fatalf("failed to cleanup pod volumes, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(rootPath, "pods", "pod-uid", "volumes")); !os.IsNotExist(err) {
+		t.Errorf("expect pod volumes dir removed, %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(hostDir, "data")); err != nil {
+		t.Errorf("expect host path kept after cleanup, %v", err)
+	}
+}
+
+func TestRejectInvalidPodVolumes(t *testing.T) {
+	rootPath := t.TempDir()
+	m := NewPodVolumeManager(rootPath, []string{"/var/lib/allowed"}, mount.NewFakeMounter(nil))
+
+	pod := newTestPod([]v1.Volume{
+		{Name: "host", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: "/var/lib/allowed/../../etc"}}},
+	}, nil)
+	if err := m.SetupPodVolumes(pod, nil, nil); err == nil {
+		t.Errorf("expect host path outside allowed paths is rejected")
+	}
+
+	pod = newTestPod([]v1.Volume{
+		{Name: "nfs", VolumeSource: v1.VolumeSource{NFS: &v1.NFSVolumeSource{Server: "nfs", Path: "/"}}},
+	}, nil)
+	if err := m.SetupPodVolumes(pod, nil, nil); err == nil {
+		t.Errorf("expect unsupported volume is rejected")
+	}
+
+	pod = newTestPod([]v1.Volume{
+		{Name: "config", VolumeSource: v1.VolumeSource{ConfigMap: &v1.ConfigMapVolumeSource{LocalObjectReference: v1.LocalObjectReference{Name: "other"}}}},
+	}, nil)
+	if err := m.SetupPodVolumes(pod, &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app"}}, nil); err == nil {
+		t.Errorf("expect config map not delivered with pod is rejected")
+	}
+
+	pod = newTestPod([]v1.Volume{
+		{Name: "secret", VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: "other"}}},
+	}, nil)
+	if err := m.SetupPodVolumes(pod, nil, []*v1.Secret{{ObjectMeta: metav1.ObjectMeta{Name: "app"}}}); err == nil {
+		t.Errorf("expect secret not delivered with pod is rejected")
+	}
+
+	pod = newTestPod([]v1.Volume{
+		{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+	}, []v1.VolumeMount{{Name: "scratch", MountPath: "/tmp", SubPath: "../../escape"}})
+	if err := m.SetupPodVolumes(pod, nil, nil); err != nil {
+		t.Fatalf("failed to setup pod volumes, %v", err)
+	}
+	if _, err := m.GetContainerMounts(pod, &pod.Spec.Containers[0]); err == nil {
+		t.Errorf("expect sub path escaping volume is rejected")
+	}
+}
+
+func TestRejectSymlinkEscape(t *testing.T) {
+	rootPath := t.TempDir()
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	m := NewPodVolumeManager(rootPath, []string{allowed}, mount.NewFakeMounter(nil))
+
+	if err := os.Symlink(outside, filepath.Join(allowed, "link")); err != nil {
+		t.Fatalf("failed to create symlink, %v", err)
+	}
+	for _, path := range []string{filepath.Join(allowed, "link"), filepath.Join(allowed, "link", "data")} {
+		if _, err := m.allowedHostPath(path); err != ErrHostPathNotAllowed {
+			t.Errorf("expect host path %s through symlink outside allowed path is rejected, got %v", path, err)
+		}
+	}
+
+	// symlink staying inside allowed path is resolved
+	if err := os.Mkdir(filepath.Join(allowed, "data"), 0755); err != nil {
+		t.Fatalf("failed to create dir, %v", err)
+	}
+	if err := os.Symlink(filepath.Join(allowed, "data"), filepath.Join(allowed, "inside")); err != nil {
+		t.Fatalf("failed to create symlink, %v", err)
+	}
+	if path, err := m.allowedHostPath(filepath.Join(allowed, "inside")); err != nil || path != filepath.Join(allowed, "data") {
+		t.Errorf("expect symlink inside allowed path resolved, got %s, %v", path, err)
+	}
+
+	// a symlink created in emptyDir by container can not be used as sub path to mount host dir
+	pod := newTestPod([]v1.Volume{
+		{Name: "scratch", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+	}, []v1.VolumeMount{{Name: "scratch", MountPath: "/tmp", SubPath: "escape"}})
+	if err := m.SetupPodVolumes(pod, nil, nil); err != nil {
+		t.Fatalf("failed to setup pod volumes, %v", err)
+	}
+	volumeDir := filepath.Join(rootPath, "pods", "pod-uid", "volumes", EmptyDirPluginName, "scratch")
+	if err := os.Symlink(outside, filepath.Join(volumeDir, "escape")); err != nil {
+		t.Fatalf("failed to create symlink, %v", err)
+	}
+	if _, err := m.GetContainerMounts(pod, &pod.Spec.Containers[0]); err == nil {
+		t.Errorf("expect sub path symlink escaping volume is rejected")
+	}
+}
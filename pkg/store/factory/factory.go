@@ -65,6 +65,9 @@ func (f *FornaxRestOptionsFactory) GetRESTOptions(resource schema.GroupResource)
 	return options, nil
 }
 
+// max number of retries when a node is updated by others while it's being updated
+const fornaxNodeUpdateRetries = 5
+
 var (
 	RegisteredBackEndStorage = map[string]storage.Store{}
 )
@@ -99,6 +102,18 @@ func NewFornaxPodStorage(ctx context.Context) *inmemory.MemoryStore {
 	return newFornaxStorage(ctx, fornaxk8sv1.FornaxPodGrv.GroupResource(), fornaxk8sv1.FornaxPodGrvKey, nil, nil)
 }
 
+func NewFornaxSecretStorage(ctx context.Context) *inmemory.MemoryStore {
+	return newFornaxStorage(ctx, fornaxk8sv1.FornaxSecretGrv.GroupResource(), fornaxk8sv1.FornaxSecretGrvKey, nil, nil)
+}
+
+func NewFornaxEventStorage(ctx context.Context) *inmemory.MemoryStore {
+	return newFornaxStorage(ctx, fornaxk8sv1.FornaxEventGrv.GroupResource(), fornaxk8sv1.FornaxEventGrvKey, nil, nil)
+}
+
+func NewFornaxIngressEndpointStorage(ctx context.Context) *inmemory.MemoryStore {
+	return newFornaxStorage(ctx, fornaxv1.IngressEndpointGrv.GroupResource(), fornaxv1.IngressEndpointGrvKey, nil, nil)
+}
+
 func NewFornaxApplicationStatusStorage(ctx context.Context) *inmemory.MemoryStore {
 	return newFornaxStorage(ctx, fornaxv1.ApplicationGrv.GroupResource(), fornaxv1.ApplicationGrvKey, nil, nil)
 }
@@ -327,6 +342,19 @@ func GetFornaxNodeCache(store fornaxstore.ApiStorageInterface, nodeName string)
 	return out, nil
 }
 
+func ListFornaxNodes(ctx context.Context, store fornaxstore.ApiStorageInterface) ([]corev1.Node, error) {
+	out := &corev1.NodeList{}
+	err := store.GetList(ctx, fornaxk8sv1.FornaxNodeGrvKey, apistorage.ListOptions{
+		ResourceVersion: "0",
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	}, out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
 func CreateFornaxNode(ctx context.Context, store fornaxstore.ApiStorageInterface, node *corev1.Node) (*corev1.Node, error) {
 	out := &corev1.Node{}
 	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxNodeGrvKey, util.Name(node))
@@ -346,3 +374,190 @@ func UpdateFornaxNode(ctx context.Context, store fornaxstore.ApiStorageInterface
 	}
 	return out, nil
 }
+
+// UpdateFornaxNodeWithFunc apply updateFunc on a copy of latest node in store and save it, so, concurrent updates of different fields of a node
+// do not overwrite each other, e.g. node manager update node status while grpc server save unacked node messages, update is retried if node
+// is changed between reading and saving it
+func UpdateFornaxNodeWithFunc(ctx context.Context, store fornaxstore.ApiStorageInterface, nodeName string, updateFunc func(node *corev1.Node) error) (*corev1.Node, error) {
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxNodeGrvKey, nodeName)
+	tryUpdate := func(existing runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		existingNode, ok := existing.(*corev1.Node)
+		if !ok {
+			return nil, nil, fmt.Errorf("object of %s is not a node", key)
+		}
+		node := existingNode.DeepCopy()
+		if err := updateFunc(node); err != nil {
+			return nil, nil, err
+		}
+		return node, nil, nil
+	}
+	var err error
+	for i := 0; i < fornaxNodeUpdateRetries; i++ {
+		out := &corev1.Node{}
+		err = store.GuaranteedUpdate(ctx, key, out, false, nil, tryUpdate, nil)
+		if err == nil {
+			return out, nil
+		}
+		if !apistorage.IsTooLargeResourceVersion(err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func DeleteFornaxNode(ctx context.Context, store fornaxstore.ApiStorageInterface, nodeName string) (*corev1.Node, error) {
+	out := &corev1.Node{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxNodeGrvKey, nodeName)
+	err := store.Delete(ctx, key, out, nil, nil, nil)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func GetFornaxSecretCache(store fornaxstore.ApiStorageInterface, secretName string) (*corev1.Secret, error) {
+	out := &corev1.Secret{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxSecretGrvKey, secretName)
+	err := store.Get(context.Background(), key, apistorage.GetOptions{IgnoreNotFound: false}, out)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func GetFornaxEventCache(store fornaxstore.ApiStorageInterface, eventName string) (*corev1.Event, error) {
+	out := &corev1.Event{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxEventGrvKey, eventName)
+	err := store.Get(context.Background(), key, apistorage.GetOptions{IgnoreNotFound: false}, out)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func ListFornaxEvents(ctx context.Context, store fornaxstore.ApiStorageInterface) ([]corev1.Event, error) {
+	out := &corev1.EventList{}
+	err := store.GetList(ctx, fornaxk8sv1.FornaxEventGrvKey, apistorage.ListOptions{
+		ResourceVersion: "0",
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	}, out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+func CreateFornaxEvent(ctx context.Context, store fornaxstore.ApiStorageInterface, event *corev1.Event) (*corev1.Event, error) {
+	out := &corev1.Event{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxEventGrvKey, util.Name(event))
+	err := store.Create(ctx, key, event, out, uint64(0))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func UpdateFornaxEvent(ctx context.Context, store fornaxstore.ApiStorageInterface, event *corev1.Event) (*corev1.Event, error) {
+	out := &corev1.Event{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxEventGrvKey, util.Name(event))
+	err := store.EnsureUpdateAndDelete(ctx, key, true, nil, event, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func DeleteFornaxEvent(ctx context.Context, store fornaxstore.ApiStorageInterface, eventName string) (*corev1.Event, error) {
+	out := &corev1.Event{}
+	key := fmt.Sprintf("%s/%s", fornaxk8sv1.FornaxEventGrvKey, eventName)
+	err := store.Delete(ctx, key, out, nil, nil, nil)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func GetIngressEndpointCache(store fornaxstore.ApiStorageInterface, endpointName string) (*fornaxv1.IngressEndpoint, error) {
+	out := &fornaxv1.IngressEndpoint{}
+	key := fmt.Sprintf("%s/%s", fornaxv1.IngressEndpointGrvKey, endpointName)
+	err := store.Get(context.Background(), key, apistorage.GetOptions{IgnoreNotFound: false}, out)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+func ListApplicationSessions(ctx context.Context, store fornaxstore.ApiStorageInterface) ([]fornaxv1.ApplicationSession, error) {
+	out := &fornaxv1.ApplicationSessionList{}
+	err := store.GetList(ctx, fornaxv1.ApplicationSessionGrvKey, apistorage.ListOptions{
+		ResourceVersion: "0",
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	}, out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+func ListIngressEndpoints(ctx context.Context, store fornaxstore.ApiStorageInterface) ([]fornaxv1.IngressEndpoint, error) {
+	out := &fornaxv1.IngressEndpointList{}
+	err := store.GetList(ctx, fornaxv1.IngressEndpointGrvKey, apistorage.ListOptions{
+		ResourceVersion: "0",
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	}, out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Items, nil
+}
+
+func CreateIngressEndpoint(ctx context.Context, store fornaxstore.ApiStorageInterface, endpoint *fornaxv1.IngressEndpoint) (*fornaxv1.IngressEndpoint, error) {
+	out := &fornaxv1.IngressEndpoint{}
+	key := fmt.Sprintf("%s/%s", fornaxv1.IngressEndpointGrvKey, util.Name(endpoint))
+	err := store.Create(ctx, key, endpoint, out, uint64(0))
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func UpdateIngressEndpoint(ctx context.Context, store fornaxstore.ApiStorageInterface, endpoint *fornaxv1.IngressEndpoint) (*fornaxv1.IngressEndpoint, error) {
+	out := &fornaxv1.IngressEndpoint{}
+	key := fmt.Sprintf("%s/%s", fornaxv1.IngressEndpointGrvKey, util.Name(endpoint))
+	err := store.EnsureUpdateAndDelete(ctx, key, true, nil, endpoint, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func DeleteIngressEndpoint(ctx context.Context, store fornaxstore.ApiStorageInterface, endpointName string) (*fornaxv1.IngressEndpoint, error) {
+	out := &fornaxv1.IngressEndpoint{}
+	key := fmt.Sprintf("%s/%s", fornaxv1.IngressEndpointGrvKey, endpointName)
+	err := store.Delete(ctx, key, out, nil, nil, nil)
+	if err != nil {
+		if fornaxstore.IsObjectNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package factory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+	"k8s.io/klog/v2"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/store/inmemory"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage/etcd"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage/journal"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage/sqlite"
+)
+
+// newPersistentBackendStore create a sqlite store with a write ahead journal in dir for a resource,
+// each resource use its own sqlite db file to avoid lock contention between resources
+func newPersistentBackendStore(dir string, groupResource schema.GroupResource) (storage.Store, error) {
+	name := strings.ReplaceAll(groupResource.String(), ".", "_")
+	backend, err := sqlite.NewSqliteStore(name, &sqlite.SQLiteStoreOptions{
+		ConnUrl: filepath.Join(dir, fmt.Sprintf("%s.db", name)),
+	}, inmemory.JsonToPersistedObject, inmemory.JsonFromPersistedObject)
+	if err != nil {
+		return nil, err
+	}
+	return journal.NewJournaledStore(filepath.Join(dir, fmt.Sprintf("%s.journal", name)), backend, inmemory.JsonToPersistedObject, inmemory.JsonFromPersistedObject)
+}
+
+// persistentResource is a fornax resource whose memory store is saved in backend store,
+// mergeFunc merge a persisted object into existing object when memory store is synced from backend store
+type persistentResource struct {
+	groupResource schema.GroupResource
+	newStore      func(ctx context.Context) *inmemory.MemoryStore
+	newFunc       func() runtime.Object
+	mergeFunc     func(from runtime.Object, to runtime.Object) error
+}
+
+// persistentResources are application status, application session, ingress endpoint, secret and node,
+// nodes are persisted to keep pod cidrs assigned to nodes, node state and pods are rebuilt from node agents' full sync
+var persistentResources = []persistentResource{
+	{
+		groupResource: fornaxv1.ApplicationGrv.GroupResource(),
+		newStore:      NewFornaxApplicationStatusStorage,
+		newFunc:       func() runtime.Object { return &fornaxv1.Application{} },
+		// application spec is loaded from api server storage, only status is merged
+		mergeFunc: applicationStatusAndRevisionMerge,
+	},
+	{
+		groupResource: fornaxv1.ApplicationSessionGrv.GroupResource(),
+		newStore:      NewFornaxApplicationSessionStorage,
+		newFunc:       func() runtime.Object { return &fornaxv1.ApplicationSession{} },
+	},
+	{
+		groupResource: fornaxv1.IngressEndpointGrv.GroupResource(),
+		newStore:      NewFornaxIngressEndpointStorage,
+		newFunc:       func() runtime.Object { return &fornaxv1.IngressEndpoint{} },
+	},
+	{
+		groupResource: fornaxk8sv1.FornaxSecretGrv.GroupResource(),
+		newStore:      NewFornaxSecretStorage,
+		newFunc:       func() runtime.Object { return &corev1.Secret{} },
+	},
+	{
+		groupResource: fornaxk8sv1.FornaxNodeGrv.GroupResource(),
+		newStore:      NewFornaxNodeStorage,
+		newFunc:       func() runtime.Object { return &corev1.Node{} },
+	},
+}
+
+// InitFornaxPersistentStorage restore application status, application session, ingress endpoint, secret and node memory stores from sqlite stores in dir,
+// and save following changes of them into sqlite stores, it should be called before any fornax store is used
+func InitFornaxPersistentStorage(ctx context.Context, dir string) error {
+	newBackend := NewPersistentBackendStoreFunc(dir)
+	for _, v := range persistentResources {
+		backend, err := newBackend(v.groupResource)
+		if err != nil {
+			return fmt.Errorf("failed to open persistent store for %s, cause %v", v.groupResource, err)
+		}
+		if err := v.newStore(ctx).RestoreFromBackend(backend, v.newFunc); err != nil {
+			return fmt.Errorf("failed to restore %s from persistent store, cause %v", v.groupResource, err)
+		}
+	}
+	klog.InfoS("Fornax persistent storage initialized", "dir", dir)
+	return nil
+}
+
+// SyncFornaxSharedStorage load application status, application session, ingress endpoint, secret and node memory stores from backend stores shared by fornaxcore replicas,
+// and save following changes of them into shared stores, it's called when a standby fornaxcore become primary and before its managers start,
+// stores is keyed by group resource, it's used instead of fornax stores of this process if provided
+func SyncFornaxSharedStorage(ctx context.Context, stores map[schema.GroupResource]*inmemory.MemoryStore, newBackend func(schema.GroupResource) (storage.Store, error)) error {
+	for _, v := range persistentResources {
+		store := v.newStore(ctx)
+		if stores != nil {
+			if store = stores[v.groupResource]; store == nil {
+				continue
+			}
+		}
+		backend, err := newBackend(v.groupResource)
+		if err != nil {
+			return fmt.Errorf("failed to open shared store for %s, cause %v", v.groupResource, err)
+		}
+		if err := store.SyncFromBackend(ctx, backend, v.newFunc, v.mergeFunc); err != nil {
+			return fmt.Errorf("failed to sync %s from shared store, cause %v", v.groupResource, err)
+		}
+	}
+	klog.InfoS("Fornax shared storage synced")
+	return nil
+}
+
+// NewPersistentBackendStoreFunc return a func which create sqlite stores in dir for resources
+func NewPersistentBackendStoreFunc(dir string) func(schema.GroupResource) (storage.Store, error) {
+	return func(groupResource schema.GroupResource) (storage.Store, error) {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		return newPersistentBackendStore(dir, groupResource)
+	}
+}
+
+// NewEtcdBackendStoreFunc return a func which create stores for resources in etcd of api server storage,
+// objects of a resource are saved under prefix of storage config with fornaxcore and resource name
+func NewEtcdBackendStoreFunc(storageConfig *storagebackend.ConfigForResource) (func(schema.GroupResource) (storage.Store, error), error) {
+	transport := storageConfig.Transport
+	client, err := etcd.NewEtcdClient(transport.ServerList, transport.CertFile, transport.KeyFile, transport.TrustedCAFile)
+	if err != nil {
+		return nil, err
+	}
+	return func(groupResource schema.GroupResource) (storage.Store, error) {
+		prefix := path.Join("/", storageConfig.Prefix, "fornaxcore", groupResource.String())
+		return etcd.NewEtcdStore(client, prefix, inmemory.JsonToPersistedObject, inmemory.JsonFromPersistedObject)
+	}, nil
+}
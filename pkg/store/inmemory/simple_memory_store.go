@@ -31,6 +31,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"centaurusinfra.io/fornax-serverless/pkg/store"
+	fornaxstorage "centaurusinfra.io/fornax-serverless/pkg/store/storage"
 	"centaurusinfra.io/fornax-serverless/pkg/util"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -51,7 +52,9 @@ type MemoryStore struct {
 	revSortedObjList *objList
 	groupResource    schema.GroupResource
 	grvKeyPrefix     string
+	watchmu          sync.Mutex
 	watchers         []*memoryStoreWatcher
+	backend          fornaxstorage.Store
 
 	keyFunc      func(obj runtime.Object) (string, error)
 	newFunc      func() runtime.Object
@@ -199,8 +202,9 @@ func (ms *MemoryStore) Create(ctx context.Context, key string, obj runtime.Objec
 		if err != nil {
 			return err
 		}
-		ms.revSortedObjList.objs[index] = objWi
+		ms.setObjSlot(0, index, objWi)
 		outVal.Set(reflect.ValueOf(newObj).Elem())
+		ms.persistObject(key, newObj, rev, false)
 
 		event := &objEvent{
 			key:       key,
@@ -275,9 +279,9 @@ func (ms *MemoryStore) Delete(ctx context.Context, key string, out runtime.Objec
 		if err != nil {
 			return err
 		}
-		ms.revSortedObjList.objs[existingObj.index] = nil
-		ms.revSortedObjList.objs[index] = deletedObjWi
+		ms.setObjSlot(existingObj.index, index, deletedObjWi)
 		outVal.Set(reflect.ValueOf(currObj).Elem())
+		ms.persistObject(key, deletedObj, rev, true)
 
 		event := &objEvent{
 			key:       key,
@@ -558,9 +562,9 @@ func (ms *MemoryStore) GuaranteedUpdate(ctx context.Context, key string, out run
 		if err != nil {
 			return err
 		}
-		ms.revSortedObjList.objs[curObjWi.index] = nil
-		ms.revSortedObjList.objs[newObjWi.index] = newObjWi
+		ms.setObjSlot(curObjWi.index, newObjWi.index, newObjWi)
 		outVal.Set(reflect.ValueOf(ret).Elem())
+		ms.persistObject(key, ret, rev, false)
 
 		event := &objEvent{
 			key:       key,
@@ -654,9 +658,9 @@ func (ms *MemoryStore) CreateOrReplace(ctx context.Context, key string, objToCre
 		if err != nil {
 			return err
 		}
-		ms.revSortedObjList.objs[curObjWi.index] = nil
-		ms.revSortedObjList.objs[index] = newObjWi
+		ms.setObjSlot(curObjWi.index, index, newObjWi)
 		outVal.Set(reflect.ValueOf(newObj).Elem())
+		ms.persistObject(key, newObj, rev, false)
 		event := &objEvent{
 			key:       key,
 			obj:       newObj.DeepCopyObject(),
@@ -708,11 +712,15 @@ func (ms *MemoryStore) watch(ctx context.Context, key string, opts apistorage.Li
 
 	// start to watch new events
 	watcher := NewMemoryStoreWatcher(ctx, key, opts)
+	ms.watchmu.Lock()
 	ms.watchers = append(ms.watchers, watcher)
+	ms.watchmu.Unlock()
 
 	objEvents := []*objEvent{}
 	if rev > 1 {
+		ms.revmu.RLock()
 		objEvents, err = ms.getObjEventsAfterRev(key, rev, opts)
+		ms.revmu.RUnlock()
 		// find all obj event which are greater than passed rev and call watcher to run with these existing events
 		if err != nil {
 			return nil, err
@@ -788,24 +796,40 @@ func (ms *MemoryStore) getObjEventsAfterRev(key string, rev uint64, opts apistor
 }
 
 func (ms *MemoryStore) binarySearchInObjList(rv uint64) uint64 {
+	// only search occupied slots, nil slots are left by updated or deleted objects, use next object's revision for a nil slot,
+	// so, search function is still monotonic
+	lastIndex := atomic.LoadUint64(&ms.revSortedObjList.lastObjIndex)
 	f := func(i int) bool {
-		obj := ms.revSortedObjList.objs[(i)%ms.revSortedObjList.Len()]
-		if obj == nil {
-			return false
+		for j := uint64(i); j <= lastIndex; j++ {
+			if obj := ms.revSortedObjList.objs[j]; obj != nil {
+				objRV, _ := store.GetObjectResourceVersion(obj.obj)
+				return objRV >= rv
+			}
 		}
-		objRV, _ := store.GetObjectResourceVersion(obj.obj)
-		return objRV >= rv
+		return true
 	}
-	index := uint64(sort.Search(ms.revSortedObjList.Len(), f))
+	index := uint64(sort.Search(int(lastIndex)+1, f))
 	return index
 }
 
+// setObjSlot put a object into its reserved slot in sorted revision list and clear slot of its previous revision if oldIndex is not 0,
+// list and watch read list with revmu read lock, slots are updated with revmu lock to make a object move atomically
+func (ms *MemoryStore) setObjSlot(oldIndex, index uint64, objWi *objWithIndex) {
+	ms.revmu.Lock()
+	defer ms.revmu.Unlock()
+	if oldIndex != 0 {
+		ms.revSortedObjList.objs[oldIndex] = nil
+	}
+	ms.revSortedObjList.objs[index] = objWi
+}
+
 // send objEvent to watchers and remove watcher who has failure to receive event
 func (ms *MemoryStore) sendEvent(event *objEvent) {
+	ms.watchmu.Lock()
+	defer ms.watchmu.Unlock()
 	watchers := []*memoryStoreWatcher{}
 	for _, v := range ms.watchers {
-		if !v.stopped {
-			v.incomingChan <- event
+		if v.receive(event) {
 			watchers = append(watchers, v)
 		}
 	}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"centaurusinfra.io/fornax-serverless/pkg/store"
+	fornaxstorage "centaurusinfra.io/fornax-serverless/pkg/store/storage"
+	"k8s.io/apimachinery/pkg/runtime"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/klog/v2"
+)
+
+// PersistedObject is saved in backend store for every key of memory store, a deleted object is saved as a tombstone,
+// so, a watcher resuming from a persisted revision still get delete event after memory store is restored
+type PersistedObject struct {
+	Key      string          `json:"key"`
+	Revision uint64          `json:"revision"`
+	Deleted  bool            `json:"deleted,omitempty"`
+	Object   json.RawMessage `json:"object"`
+}
+
+func JsonToPersistedObject(text []byte) (interface{}, error) {
+	res := &PersistedObject{}
+	if err := json.Unmarshal(text, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func JsonFromPersistedObject(obj interface{}) ([]byte, error) {
+	if _, ok := obj.(*PersistedObject); !ok {
+		return nil, fornaxstorage.InvalidObjectType
+	}
+	return json.Marshal(obj)
+}
+
+// persistObject save object change into backend store, memory store is source of truth while fornaxcore is running,
+// backend store is only used to restore memory store when fornaxcore restart, so, failure is logged and ignored,
+// journal in front of backend store group commit concurrent changes, so, they share one disk sync
+func (ms *MemoryStore) persistObject(key string, obj runtime.Object, rev uint64, deleted bool) {
+	if ms.backend == nil {
+		return
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to marshal object for backend store", "key", key)
+		return
+	}
+	pobj := &PersistedObject{
+		Key:      key,
+		Revision: rev,
+		Deleted:  deleted,
+		Object:   data,
+	}
+	if err := ms.backend.PutObject(key, pobj, int64(rev)); err != nil {
+		klog.ErrorS(err, "Failed to save object into backend store", "key", key, "rev", rev)
+	}
+}
+
+// RestoreFromBackend rebuild memory store using objects persisted in backend store with their resource versions,
+// objects are put in revSortedObjList in revision order, so, watchers can resume from a persisted revision,
+// global memory revision is moved forward to latest persisted revision to make sure revision never go back,
+// tombstones are loaded as deleted objects and removed from backend store,
+// memory store save following changes into backend store after it's restored
+func (ms *MemoryStore) RestoreFromBackend(backend fornaxstorage.Store, newFunc func() runtime.Object) error {
+	if newFunc == nil {
+		newFunc = ms.newFunc
+	}
+	if newFunc == nil {
+		return errors.New("newFunc is not provided to restore memory store")
+	}
+
+	objs, err := backend.ListObject()
+	if err != nil {
+		return err
+	}
+	pobjs := []*PersistedObject{}
+	for _, v := range objs {
+		if pobj, ok := v.(*PersistedObject); ok {
+			pobjs = append(pobjs, pobj)
+		} else {
+			return fornaxstorage.InvalidObjectType
+		}
+	}
+	sort.Slice(pobjs, func(i, j int) bool {
+		return pobjs[i].Revision < pobjs[j].Revision
+	})
+
+	ms.revmu.Lock()
+	defer ms.revmu.Unlock()
+	maxRev := uint64(0)
+	numOfTombstones := 0
+	for _, pobj := range pobjs {
+		obj := newFunc()
+		if err := json.Unmarshal(pobj.Object, obj); err != nil {
+			klog.ErrorS(err, "Ignore a malformed object in backend store", "key", pobj.Key)
+			continue
+		}
+		if err := store.SetObjectResourceVersion(obj, pobj.Revision); err != nil {
+			return err
+		}
+
+		index := atomic.AddUint64(&ms.revSortedObjList.lastObjIndex, 1)
+		if uint64(ms.revSortedObjList.Len()) < index+DefaultObjRevListGrowThreashold {
+			ms.revSortedObjList.grow(DefaultObjRevListGrowThreashold)
+		}
+		objWi := &objWithIndex{
+			key:     pobj.Key,
+			obj:     obj,
+			index:   index,
+			deleted: pobj.Deleted,
+		}
+		if !pobj.Deleted {
+			if err := ms.kvs.put(strings.Split(pobj.Key, "/"), objWi, pobj.Revision); err != nil {
+				return err
+			}
+		} else {
+			numOfTombstones += 1
+			if err := backend.DelObject(pobj.Key); err != nil {
+				klog.ErrorS(err, "Failed to remove tombstone from backend store", "key", pobj.Key)
+			}
+		}
+		ms.revSortedObjList.objs[index] = objWi
+		if pobj.Revision > maxRev {
+			maxRev = pobj.Revision
+		}
+	}
+
+	moveMemoryRevForward(maxRev)
+	ms.backend = backend
+	klog.InfoS("Restored memory store from backend store", "resource", ms.groupResource, "objects", len(pobjs)-numOfTombstones, "tombstones", numOfTombstones, "revision", maxRev)
+	return nil
+}
+
+// SyncFromBackend load objects persisted in backend store into a memory store which is already in use, like a standby fornaxcore become primary,
+// objects are created or updated with new revisions, so, watchers get events of them, mergeFunc merge a persisted object into existing object,
+// existing object is replaced by persisted object if mergeFunc is nil, memory store save following changes into backend store after it's synced
+func (ms *MemoryStore) SyncFromBackend(ctx context.Context, backend fornaxstorage.Store, newFunc func() runtime.Object, mergeFunc func(from runtime.Object, to runtime.Object) error) error {
+	if newFunc == nil {
+		newFunc = ms.newFunc
+	}
+	if newFunc == nil {
+		return errors.New("newFunc is not provided to sync memory store")
+	}
+
+	objs, err := backend.ListObject()
+	if err != nil {
+		return err
+	}
+	pobjs := []*PersistedObject{}
+	for _, v := range objs {
+		if pobj, ok := v.(*PersistedObject); ok {
+			pobjs = append(pobjs, pobj)
+		} else {
+			return fornaxstorage.InvalidObjectType
+		}
+	}
+
+	// revision of objects saved by other fornaxcore may be larger than this one, move forward to make sure backend store accept following changes
+	maxRev := uint64(0)
+	for _, pobj := range pobjs {
+		if pobj.Revision > maxRev {
+			maxRev = pobj.Revision
+		}
+	}
+	moveMemoryRevForward(maxRev)
+	ms.backend = backend
+
+	numOfObjs := 0
+	for _, pobj := range pobjs {
+		if pobj.Deleted {
+			if err := backend.DelObject(pobj.Key); err != nil {
+				klog.ErrorS(err, "Failed to remove tombstone from backend store", "key", pobj.Key)
+			}
+			continue
+		}
+		obj := newFunc()
+		if err := json.Unmarshal(pobj.Object, obj); err != nil {
+			klog.ErrorS(err, "Ignore a malformed object in backend store", "key", pobj.Key)
+			continue
+		}
+		out := newFunc()
+		if curObjWi := ms.kvs.get(strings.Split(pobj.Key, "/")); curObjWi == nil {
+			err = ms.Create(ctx, pobj.Key, obj, out, 0)
+		} else {
+			err = ms.GuaranteedUpdate(ctx, pobj.Key, out, false, nil, func(input runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+				if mergeFunc == nil {
+					return obj, nil, nil
+				}
+				updated := input.DeepCopyObject()
+				if err := mergeFunc(obj, updated); err != nil {
+					return nil, nil, err
+				}
+				return updated, nil, nil
+			}, nil)
+		}
+		if err != nil {
+			return err
+		}
+		numOfObjs += 1
+	}
+	klog.InfoS("Synced memory store from backend store", "resource", ms.groupResource, "objects", numOfObjs)
+	return nil
+}
+
+// moveMemoryRevForward make sure global memory revision is not less than rev
+func moveMemoryRevForward(rev uint64) {
+	for {
+		currRev := atomic.LoadUint64(&_MemoryRev)
+		if rev <= currRev || atomic.CompareAndSwapUint64(&_MemoryRev, currRev, rev) {
+			break
+		}
+	}
+}
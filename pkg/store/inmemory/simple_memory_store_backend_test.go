@@ -0,0 +1,228 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage/journal"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage/sqlite"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	apistorage "k8s.io/apiserver/pkg/storage"
+)
+
+func newTestSession(name string) *fornaxv1.ApplicationSession {
+	return &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Spec: fornaxv1.ApplicationSessionSpec{ApplicationName: "test/app"},
+	}
+}
+
+func openTestBackend(t *testing.T, dir string) interface {
+	ListObject() ([]interface{}, error)
+	DelObject(key string) error
+	GetObject(key string) (interface{}, error)
+	PutObject(key string, obj interface{}, revision int64) error
+	Close() error
+} {
+	backend, err := sqlite.NewSqliteStore("sessions", &sqlite.SQLiteStoreOptions{ConnUrl: filepath.Join(dir, "sessions.db")}, JsonToPersistedObject, JsonFromPersistedObject)
+	if err != nil {
+		t.Fatalf("failed to open sqlite store, %v", err)
+	}
+	store, err := journal.NewJournaledStore(filepath.Join(dir, "sessions.journal"), backend, JsonToPersistedObject, JsonFromPersistedObject)
+	if err != nil {
+		t.Fatalf("failed to open journal, %v", err)
+	}
+	return store
+}
+
+func TestRestoreFromBackend(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	newFunc := func() runtime.Object { return &fornaxv1.ApplicationSession{} }
+	gr := fornaxv1.ApplicationSessionGrv.GroupResource()
+	keyOf := func(name string) string { return fmt.Sprintf("%s/test/%s", fornaxv1.ApplicationSessionGrvKey, name) }
+
+	backend := openTestBackend(t, dir)
+	ms := NewMemoryStore(ctx, gr, fornaxv1.ApplicationSessionGrvKey, newFunc, nil)
+	if err := ms.RestoreFromBackend(backend, nil); err != nil {
+		t.Fatalf("failed to restore empty store, %v", err)
+	}
+
+	created := map[string]*fornaxv1.ApplicationSession{}
+	for _, name := range []string{"s1", "s2", "s3"} {
+		out := &fornaxv1.ApplicationSession{}
+		if err := ms.Create(ctx, keyOf(name), newTestSession(name), out, 0); err != nil {
+			t.Fatalf("failed to create session, %v", err)
+		}
+		created[name] = out
+	}
+	// remember a revision which a watcher has seen, s2 is updated and s3 is deleted after it
+	watchedRV := created["s3"].ResourceVersion
+	updated := &fornaxv1.ApplicationSession{}
+	session := created["s2"].DeepCopy()
+	session.Status.SessionStatus = fornaxv1.SessionStatusAvailable
+	if err := ms.GuaranteedUpdate(ctx, keyOf("s2"), updated, false, nil, func(input runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		return session, nil, nil
+	}, nil); err != nil {
+		t.Fatalf("failed to update session, %v", err)
+	}
+	if err := ms.Delete(ctx, keyOf("s3"), &fornaxv1.ApplicationSession{}, nil, nil, nil); err != nil {
+		t.Fatalf("failed to delete session, %v", err)
+	}
+	ms.Stop()
+	backend.Close()
+
+	// restart with a new memory store using same backend files
+	backend = openTestBackend(t, dir)
+	defer backend.Close()
+	restored := NewMemoryStore(ctx, gr, fornaxv1.ApplicationSessionGrvKey, newFunc, nil)
+	defer restored.Stop()
+	if err := restored.RestoreFromBackend(backend, nil); err != nil {
+		t.Fatalf("failed to restore store, %v", err)
+	}
+
+	out := &fornaxv1.ApplicationSession{}
+	if err := restored.Get(ctx, keyOf("s1"), apistorage.GetOptions{}, out); err != nil {
+		t.Fatalf("failed to get restored session, %v", err)
+	}
+	if out.ResourceVersion != created["s1"].ResourceVersion {
+		t.Errorf("expect restored resource version %s, got %s", created["s1"].ResourceVersion, out.ResourceVersion)
+	}
+	if err := restored.Get(ctx, keyOf("s2"), apistorage.GetOptions{}, out); err != nil {
+		t.Fatalf("failed to get restored session, %v", err)
+	}
+	if out.ResourceVersion != updated.ResourceVersion || out.Status.SessionStatus != fornaxv1.SessionStatusAvailable {
+		t.Errorf("expect restored updated session %s/%s, got %s/%s", updated.ResourceVersion, fornaxv1.SessionStatusAvailable, out.ResourceVersion, out.Status.SessionStatus)
+	}
+	if err := restored.Get(ctx, keyOf("s3"), apistorage.GetOptions{}, out); !apistorage.IsNotFound(err) {
+		t.Errorf("expect deleted session is not restored, got %v", err)
+	}
+
+	// watcher resume from persisted revision get update and delete events
+	w, err := restored.Watch(ctx, fornaxv1.ApplicationSessionGrvKey, apistorage.ListOptions{ResourceVersion: watchedRV, Recursive: true, Predicate: apistorage.Everything})
+	if err != nil {
+		t.Fatalf("failed to watch restored store, %v", err)
+	}
+	defer w.Stop()
+	events := map[string]watch.EventType{}
+	timeout := time.After(5 * time.Second)
+	for len(events) < 2 {
+		select {
+		case e := <-w.ResultChan():
+			events[e.Object.(*fornaxv1.ApplicationSession).Name] = e.Type
+		case <-timeout:
+			t.Fatalf("expect events of s2 and s3, got %v", events)
+		}
+	}
+	if events["s2"] != watch.Added || events["s3"] != watch.Deleted {
+		t.Errorf("expect s2 added and s3 deleted events, got %v", events)
+	}
+
+	// new revision must be larger than restored revisions
+	if err := restored.Create(ctx, keyOf("s4"), newTestSession("s4"), out, 0); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+	if out.ResourceVersion <= updated.ResourceVersion {
+		t.Errorf("expect new resource version larger than %s, got %s", updated.ResourceVersion, out.ResourceVersion)
+	}
+}
+
+func TestSyncFromBackend(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	newFunc := func() runtime.Object { return &fornaxv1.ApplicationSession{} }
+	gr := fornaxv1.ApplicationSessionGrv.GroupResource()
+	keyOf := func(name string) string { return fmt.Sprintf("%s/test/%s", fornaxv1.ApplicationSessionGrvKey, name) }
+
+	// primary save sessions into shared backend
+	backend := openTestBackend(t, dir)
+	defer backend.Close()
+	primary := NewMemoryStore(ctx, gr, fornaxv1.ApplicationSessionGrvKey, newFunc, nil)
+	defer primary.Stop()
+	if err := primary.RestoreFromBackend(backend, nil); err != nil {
+		t.Fatalf("failed to restore empty store, %v", err)
+	}
+	for _, name := range []string{"sync1", "sync2", "sync3"} {
+		session := newTestSession(name)
+		session.Status.SessionStatus = fornaxv1.SessionStatusAvailable
+		if err := primary.Create(ctx, keyOf(name), session, &fornaxv1.ApplicationSession{}, 0); err != nil {
+			t.Fatalf("failed to create session, %v", err)
+		}
+	}
+	if err := primary.Delete(ctx, keyOf("sync3"), &fornaxv1.ApplicationSession{}, nil, nil, nil); err != nil {
+		t.Fatalf("failed to delete session, %v", err)
+	}
+
+	// standby store already has a object of sync2 and is watched
+	standby := NewMemoryStore(ctx, gr, fornaxv1.ApplicationSessionGrvKey, newFunc, nil)
+	defer standby.Stop()
+	if err := standby.Create(ctx, keyOf("sync2"), newTestSession("sync2"), &fornaxv1.ApplicationSession{}, 0); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+	w, err := standby.Watch(ctx, fornaxv1.ApplicationSessionGrvKey, apistorage.ListOptions{ResourceVersion: "0", Recursive: true, Predicate: apistorage.Everything})
+	if err != nil {
+		t.Fatalf("failed to watch standby store, %v", err)
+	}
+	defer w.Stop()
+	mergeStatus := func(from runtime.Object, to runtime.Object) error {
+		to.(*fornaxv1.ApplicationSession).Status = from.(*fornaxv1.ApplicationSession).Status
+		return nil
+	}
+	if err := standby.SyncFromBackend(ctx, backend, nil, mergeStatus); err != nil {
+		t.Fatalf("failed to sync standby store, %v", err)
+	}
+
+	// watcher get synced sessions, deleted session is not synced
+	events := map[string]*fornaxv1.ApplicationSession{}
+	timeout := time.After(5 * time.Second)
+	for len(events) < 2 || events["sync2"].Status.SessionStatus != fornaxv1.SessionStatusAvailable {
+		select {
+		case e := <-w.ResultChan():
+			session := e.Object.(*fornaxv1.ApplicationSession)
+			events[session.Name] = session
+		case <-timeout:
+			t.Fatalf("expect events of synced sync1 and sync2, got %v", events)
+		}
+	}
+	out := &fornaxv1.ApplicationSession{}
+	if err := standby.Get(ctx, keyOf("sync1"), apistorage.GetOptions{}, out); err != nil || out.Status.SessionStatus != fornaxv1.SessionStatusAvailable {
+		t.Errorf("expect sync1 synced from backend, got %v, %v", out.Status.SessionStatus, err)
+	}
+	if err := standby.Get(ctx, keyOf("sync3"), apistorage.GetOptions{}, out); !apistorage.IsNotFound(err) {
+		t.Errorf("expect deleted session is not synced, got %v", err)
+	}
+
+	// following changes are saved into backend store
+	if err := standby.Delete(ctx, keyOf("sync1"), &fornaxv1.ApplicationSession{}, nil, nil, nil); err != nil {
+		t.Fatalf("failed to delete session, %v", err)
+	}
+	obj, err := backend.GetObject(keyOf("sync1"))
+	if err != nil || !obj.(*PersistedObject).Deleted {
+		t.Errorf("expect deleted sync1 saved in backend as tombstone, got %v, %v", obj, err)
+	}
+}
@@ -19,20 +19,24 @@ package inmemory
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"centaurusinfra.io/fornax-serverless/pkg/store"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/storage"
 	apistorage "k8s.io/apiserver/pkg/storage"
-	"k8s.io/klog/v2"
 )
 
+// memoryStoreWatcher receive events from memory store and send them to its consumer,
+// run go routine is the only one sending to and closing outgoing channels, incoming channel is never closed,
+// memory store stop sending events into it after done channel is closed when run go routine exit
 type memoryStoreWatcher struct {
 	ctx                    context.Context
-	stopped                bool
 	recursive              bool
-	stopChannel            chan bool
+	stopOnce               sync.Once
+	stopChannel            chan struct{}
+	done                   chan struct{}
 	incomingChan           chan *objEvent
 	outgoingChan           chan watch.Event
 	outgoingChanWithOldObj chan store.WatchEventWithOldObj
@@ -46,11 +50,11 @@ func NewMemoryStoreWatcher(ctx context.Context, key string, opts storage.ListOpt
 	}
 	watcher := &memoryStoreWatcher{
 		ctx:                    ctx,
-		stopped:                false,
 		keyPrefix:              key,
 		recursive:              opts.Recursive,
 		predicate:              opts.Predicate,
-		stopChannel:            make(chan bool, 1),
+		stopChannel:            make(chan struct{}),
+		done:                   make(chan struct{}),
 		incomingChan:           make(chan *objEvent, 500),
 		outgoingChan:           make(chan watch.Event, 500),
 		outgoingChanWithOldObj: make(chan store.WatchEventWithOldObj, 500),
@@ -78,60 +82,68 @@ func (wc *memoryStoreWatcher) acceptAll() bool {
 // pasted objEvents should be already sorted according to event's rev
 func (wc *memoryStoreWatcher) run(rev uint64, existingObjEvents []*objEvent, eventWithOldObj bool) {
 	defer func() {
-		if x := recover(); x != nil {
-			klog.InfoS("Watcher stopped", "cause", x)
-		}
+		close(wc.done)
+		close(wc.outgoingChan)
+		close(wc.outgoingChanWithOldObj)
 	}()
 	startingRev := rev
 	for _, event := range existingObjEvents {
-		wcEvent := wc.transformToWatchEvent(event)
-		if wcEvent != nil {
-			if eventWithOldObj {
-				if e := wc.transformToWatchEventWithOldObj(wcEvent, event.oldObj); e != nil {
-					wc.outgoingChanWithOldObj <- *e
-				}
-			} else {
-				wc.outgoingChan <- *wcEvent
-			}
+		if !wc.send(event, eventWithOldObj) {
+			return
 		}
 	}
 
-	go func() {
-		defer func() {
-			wc.stopped = true
-			close(wc.incomingChan)
-			close(wc.outgoingChan)
-			close(wc.outgoingChanWithOldObj)
-		}()
-		for {
-			select {
-			case <-wc.ctx.Done():
-				return
-			case <-wc.stopChannel:
+	for {
+		select {
+		case <-wc.ctx.Done():
+			return
+		case <-wc.stopChannel:
+			return
+		case event := <-wc.incomingChan:
+			if event.rev > startingRev && !wc.send(event, eventWithOldObj) {
 				return
 			}
 		}
-	}()
+	}
+}
 
-	for {
+// send transform a event and send it to consumer, it return false if watcher is stopped when it's waiting for consumer
+func (wc *memoryStoreWatcher) send(event *objEvent, eventWithOldObj bool) bool {
+	wcEvent := wc.transformToWatchEvent(event)
+	if wcEvent == nil {
+		return true
+	}
+	if eventWithOldObj {
+		e := wc.transformToWatchEventWithOldObj(wcEvent, event.oldObj)
+		if e == nil {
+			return true
+		}
 		select {
-		case event, ok := <-wc.incomingChan:
-			if !ok {
-				return
-			}
-			if event.rev > startingRev {
-				wcEvent := wc.transformToWatchEvent(event)
-				if wcEvent != nil {
-					if eventWithOldObj {
-						if e := wc.transformToWatchEventWithOldObj(wcEvent, event.oldObj); e != nil {
-							wc.outgoingChanWithOldObj <- *e
-						}
-					} else {
-						wc.outgoingChan <- *wcEvent
-					}
-				}
-			}
+		case wc.outgoingChanWithOldObj <- *e:
+		case <-wc.ctx.Done():
+			return false
+		case <-wc.stopChannel:
+			return false
 		}
+	} else {
+		select {
+		case wc.outgoingChan <- *wcEvent:
+		case <-wc.ctx.Done():
+			return false
+		case <-wc.stopChannel:
+			return false
+		}
+	}
+	return true
+}
+
+// receive put a event into incoming channel, it return false if watcher has stopped
+func (wc *memoryStoreWatcher) receive(event *objEvent) bool {
+	select {
+	case <-wc.done:
+		return false
+	case wc.incomingChan <- event:
+		return true
 	}
 }
 
@@ -147,7 +159,9 @@ func (wc *memoryStoreWatcher) ResultChanWithPrevobj() <-chan store.WatchEventWit
 
 // Stop implements watch.Interface
 func (wc *memoryStoreWatcher) Stop() {
-	wc.stopChannel <- true
+	wc.stopOnce.Do(func() {
+		close(wc.stopChannel)
+	})
 }
 
 func (wc *memoryStoreWatcher) transformToWatchEvent(e *objEvent) (res *watch.Event) {
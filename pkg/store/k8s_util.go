@@ -42,9 +42,12 @@ type continueToken struct {
 
 // AppendListItem decodes and appends the object (if it passes filter) to v, which must be a slice.
 func AppendListItem(v reflect.Value, obj runtime.Object, rev uint64, pred apistorage.SelectionPredicate) error {
-	// being unable to set the version does not prevent the object from being extracted
-	if err := SetObjectResourceVersion(obj, rev); err != nil {
-		return err
+	// being unable to set the version does not prevent the object from being extracted,
+	// obj could be a object in memory store read by other goroutines, do not write it if it already has this version
+	if objRev, err := GetObjectResourceVersion(obj); err != nil || objRev != rev {
+		if err := SetObjectResourceVersion(obj, rev); err != nil {
+			return err
+		}
 	}
 	if matched, err := pred.Matches(obj); err == nil && matched {
 		v.Set(reflect.Append(v, reflect.ValueOf(obj).Elem()))
@@ -20,7 +20,11 @@ package store
 
 import (
 	"context"
+	"errors"
 
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -46,12 +50,72 @@ func FornaxReadonlyResourceHandler(obj resource.Object) brest.ResourceHandlerPro
 	}
 }
 
+// FornaxResourceHandler provide a rest storage which allow clients to create, update and delete a fornax resource
+func FornaxResourceHandler(obj resource.Object) brest.ResourceHandlerProvider {
+	return func(scheme *runtime.Scheme, optsGetter generic.RESTOptionsGetter) (rest.Storage, error) {
+		gvr := obj.GetGroupVersionResource()
+		s := &brest.DefaultStrategy{
+			Object:         obj,
+			ObjectTyper:    scheme,
+			TableConvertor: rest.NewDefaultTableConvertor(gvr.GroupResource()),
+		}
+		return newStore(scheme, obj.New, obj.NewList, gvr, s, optsGetter, nil)
+	}
+}
+
+// FornaxAnnotatableResourceHandler provide a rest storage which allow clients to read a fornax resource and only change its annotations,
+// it's used by resources owned by fornaxcore, and clients use annotations to ask fornaxcore to take action on them, e.g. evacuate a node
+func FornaxAnnotatableResourceHandler(obj resource.Object) brest.ResourceHandlerProvider {
+	return func(scheme *runtime.Scheme, optsGetter generic.RESTOptionsGetter) (rest.Storage, error) {
+		gvr := obj.GetGroupVersionResource()
+		s := &brest.DefaultStrategy{
+			Object:         obj,
+			ObjectTyper:    scheme,
+			TableConvertor: rest.NewDefaultTableConvertor(gvr.GroupResource()),
+		}
+		store, err := newGenericStore(scheme, obj.New, obj.NewList, gvr, s, optsGetter, nil)
+		if err != nil {
+			return nil, err
+		}
+		fstore := &fornaxAnnotatableStore{
+			fornaxReadOnlyStore: fornaxReadOnlyStore{
+				backendStore: store,
+			},
+		}
+		return fstore, nil
+	}
+}
+
 // newReadonlyStore returns a RESTStorage object that will work against API services.
 func newReadonlyStore(
 	scheme *runtime.Scheme,
 	single, list func() runtime.Object,
 	gvr schema.GroupVersionResource,
 	s brest.Strategy, optsGetter generic.RESTOptionsGetter, fn brest.StoreFn) (rest.Storage, error) {
+	store, err := newGenericStore(scheme, single, list, gvr, s, optsGetter, fn)
+	if err != nil {
+		return nil, err
+	}
+	fstore := &fornaxReadOnlyStore{
+		backendStore: store,
+	}
+	return fstore, nil
+}
+
+// newStore returns a RESTStorage object that will work against API services, and allow write.
+func newStore(
+	scheme *runtime.Scheme,
+	single, list func() runtime.Object,
+	gvr schema.GroupVersionResource,
+	s brest.Strategy, optsGetter generic.RESTOptionsGetter, fn brest.StoreFn) (rest.Storage, error) {
+	return newGenericStore(scheme, single, list, gvr, s, optsGetter, fn)
+}
+
+func newGenericStore(
+	scheme *runtime.Scheme,
+	single, list func() runtime.Object,
+	gvr schema.GroupVersionResource,
+	s brest.Strategy, optsGetter generic.RESTOptionsGetter, fn brest.StoreFn) (*genericregistry.Store, error) {
 	store := &genericregistry.Store{
 		NewFunc:                  single,
 		NewListFunc:              list,
@@ -71,10 +135,7 @@ func newReadonlyStore(
 	if err := store.CompleteWithOptions(options); err != nil {
 		return nil, err
 	}
-	fstore := &fornaxReadOnlyStore{
-		backendStore: store,
-	}
-	return fstore, nil
+	return store, nil
 }
 
 var _ readOnlyStore = &fornaxReadOnlyStore{}
@@ -126,3 +187,62 @@ func (r *fornaxReadOnlyStore) Watch(ctx context.Context, options *metainternalve
 func (r *fornaxReadOnlyStore) New() runtime.Object {
 	return r.backendStore.New()
 }
+
+var _ rest.Updater = &fornaxAnnotatableStore{}
+
+type fornaxAnnotatableStore struct {
+	fornaxReadOnlyStore
+}
+
+// Update implements rest.Updater, only annotations of object can be changed, update with other changes is forbidden
+func (r *fornaxAnnotatableStore) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	objInfo = &annotationsOnlyObjectInfo{resource: r.backendStore.DefaultQualifiedResource, objInfo: objInfo}
+	return r.backendStore.Update(ctx, name, objInfo, createValidation, updateValidation, false, options)
+}
+
+// annotationsOnlyObjectInfo apply annotations and resource version of a updated object on a copy of old object,
+// it reject updated object if it has other changes
+type annotationsOnlyObjectInfo struct {
+	resource schema.GroupResource
+	objInfo  rest.UpdatedObjectInfo
+}
+
+// Preconditions implements rest.UpdatedObjectInfo
+func (i *annotationsOnlyObjectInfo) Preconditions() *metav1.Preconditions {
+	return i.objInfo.Preconditions()
+}
+
+// UpdatedObject implements rest.UpdatedObjectInfo
+func (i *annotationsOnlyObjectInfo) UpdatedObject(ctx context.Context, oldObj runtime.Object) (runtime.Object, error) {
+	newObj, err := i.objInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		return nil, err
+	}
+	newMeta, err := meta.Accessor(newObj)
+	if err != nil {
+		return nil, err
+	}
+	obj := oldObj.DeepCopyObject()
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	// compare updated object with old object without annotations, resource version and managed fields
+	compared := newObj.DeepCopyObject()
+	comparedMeta, err := meta.Accessor(compared)
+	if err != nil {
+		return nil, err
+	}
+	compared.GetObjectKind().SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	comparedMeta.SetAnnotations(objMeta.GetAnnotations())
+	comparedMeta.SetResourceVersion(objMeta.GetResourceVersion())
+	comparedMeta.SetManagedFields(objMeta.GetManagedFields())
+	if !apiequality.Semantic.DeepEqual(compared, obj) {
+		return nil, apierrors.NewForbidden(i.resource, objMeta.GetName(), errors.New("only annotations can be changed"))
+	}
+
+	objMeta.SetAnnotations(newMeta.GetAnnotations())
+	objMeta.SetResourceVersion(newMeta.GetResourceVersion())
+	return obj, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package store
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+func TestAnnotationsOnlyObjectInfo(t *testing.T) {
+	oldNode := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "node", Name: "node1", ResourceVersion: "10", Labels: map[string]string{"zone": "a"}},
+		Status:     v1.NodeStatus{Phase: v1.NodeRunning},
+	}
+	tests := []struct {
+		name      string
+		update    func(node *v1.Node)
+		forbidden bool
+	}{
+		{
+			name: "add annotation",
+			update: func(node *v1.Node) {
+				node.Annotations = map[string]string{"evacuate": "true"}
+			},
+		},
+		{
+			name: "annotation with managed fields and type meta",
+			update: func(node *v1.Node) {
+				node.Annotations = map[string]string{"evacuate": "true"}
+				node.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+				node.APIVersion, node.Kind = "k8s.io/v1", "Node"
+			},
+		},
+		{
+			name: "change label",
+			update: func(node *v1.Node) {
+				node.Labels = map[string]string{"zone": "b"}
+			},
+			forbidden: true,
+		},
+		{
+			name: "change spec with annotation",
+			update: func(node *v1.Node) {
+				node.Annotations = map[string]string{"evacuate": "true"}
+				node.Spec.Unschedulable = true
+			},
+			forbidden: true,
+		},
+		{
+			name: "change status",
+			update: func(node *v1.Node) {
+				node.Status.Phase = v1.NodeTerminated
+			},
+			forbidden: true,
+		},
+	}
+	for _, test := range tests {
+		newNode := oldNode.DeepCopy()
+		newNode.ResourceVersion = "11"
+		test.update(newNode)
+		objInfo := &annotationsOnlyObjectInfo{
+			resource: schema.GroupResource{Group: "k8s.io", Resource: "nodes"},
+			objInfo:  rest.DefaultUpdatedObjectInfo(newNode),
+		}
+		obj, err := objInfo.UpdatedObject(context.Background(), oldNode.DeepCopy())
+		if test.forbidden {
+			if !apierrors.IsForbidden(err) {
+				t.Errorf("%s: expect update forbidden, got %v", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: expect update allowed, got %v", test.name, err)
+			continue
+		}
+		node := obj.(*v1.Node)
+		if node.Annotations["evacuate"] != "true" || node.ResourceVersion != "11" {
+			t.Errorf("%s: expect annotations and resource version updated, got %v, %s", test.name, node.Annotations, node.ResourceVersion)
+		}
+		if node.Labels["zone"] != "a" || node.Status.Phase != v1.NodeRunning {
+			t.Errorf("%s: expect other fields kept, got %v", test.name, node)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package etcd
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	DefaultDialTimeout    = 10 * time.Second
+	DefaultRequestTimeout = 10 * time.Second
+)
+
+// etcdStore save objects under a key prefix in etcd, it's shared by all fornaxcore replicas using same etcd
+type etcdStore struct {
+	client             *clientv3.Client
+	prefix             string
+	TextToObjectFunc   storage.TextToObjectFunc
+	TextFromObjectFunc storage.TextFromObjectFunc
+}
+
+func (s *etcdStore) ListObject() ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	resp, err := s.client.Get(ctx, s.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []interface{}{}
+	for _, kv := range resp.Kvs {
+		obj, err := s.TextToObjectFunc(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+func (s *etcdStore) DelObject(identifier string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	_, err := s.client.Delete(ctx, s.prefix+identifier)
+	return err
+}
+
+func (s *etcdStore) GetObject(identifier string) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	resp, err := s.client.Get(ctx, s.prefix+identifier)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, storage.ObjectNotFound
+	}
+	return s.TextToObjectFunc(resp.Kvs[0].Value)
+}
+
+// PutObject save object text as value of key, revision is kept in object text, etcd revision is not related to it
+func (s *etcdStore) PutObject(identifier string, obj interface{}, revision int64) error {
+	text, err := s.TextFromObjectFunc(obj)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultRequestTimeout)
+	defer cancel()
+	_, err = s.client.Put(ctx, s.prefix+identifier, string(text))
+	return err
+}
+
+// NewEtcdClient connect etcd servers, tls is used if cert, key or ca file is provided
+func NewEtcdClient(servers []string, certFile, keyFile, caFile string) (*clientv3.Client, error) {
+	if len(servers) == 0 {
+		return nil, errors.New("etcd servers are not provided")
+	}
+	config := clientv3.Config{
+		Endpoints:   servers,
+		DialTimeout: DefaultDialTimeout,
+	}
+	if len(certFile) > 0 || len(keyFile) > 0 || len(caFile) > 0 {
+		tlsInfo := transport.TLSInfo{
+			CertFile:      certFile,
+			KeyFile:       keyFile,
+			TrustedCAFile: caFile,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, err
+		}
+		config.TLS = tlsConfig
+	}
+	return clientv3.New(config)
+}
+
+// NewEtcdStore return a store saving objects under prefix in etcd, prefix is ended with a slash if it's not
+func NewEtcdStore(client *clientv3.Client, prefix string, toObjectFunc storage.TextToObjectFunc, fromObjectFunc storage.TextFromObjectFunc) (*etcdStore, error) {
+	if toObjectFunc == nil {
+		return nil, errors.New("TextToObject func is not provided to NewEtcdStore")
+	}
+	if fromObjectFunc == nil {
+		return nil, errors.New("TextFromObject func is not provided to NewEtcdStore")
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		prefix = prefix + "/"
+	}
+	return &etcdStore{
+		client:             client,
+		prefix:             prefix,
+		TextToObjectFunc:   toObjectFunc,
+		TextFromObjectFunc: fromObjectFunc,
+	}, nil
+}
@@ -0,0 +1,351 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+	"k8s.io/klog/v2"
+)
+
+const (
+	JournalOpPut = "put"
+	JournalOpDel = "del"
+)
+
+type journalRecord struct {
+	Op       string `json:"op"`
+	Key      string `json:"key"`
+	Revision int64  `json:"rev,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+}
+
+// journaledStore is a write ahead journal in front of a slow backend store, e.g. sqlite,
+// every change is appended to journal file and synced to disk before it is applied to backend store in a go routine,
+// concurrent changes are group committed, they are written into journal file together and share one sync,
+// journal is replayed into backend store when it's opened and truncated when all changes have been applied to backend store
+type journaledStore struct {
+	mu                 sync.Mutex
+	appended           *sync.Cond
+	applied            *sync.Cond
+	path               string
+	file               *os.File
+	backend            storage.Store
+	committing         bool
+	batch              []*journalRecord
+	batchData          []byte
+	batchWaiters       []chan error
+	records            []*journalRecord
+	pending            int
+	failed             []*journalRecord
+	TextToObjectFunc   storage.TextToObjectFunc
+	TextFromObjectFunc storage.TextFromObjectFunc
+}
+
+// ListObject implements storage.Store, it wait for all journal records are applied into backend store
+func (s *journaledStore) ListObject() ([]interface{}, error) {
+	s.Flush()
+	return s.backend.ListObject()
+}
+
+// GetObject implements storage.Store, it wait for all journal records are applied into backend store
+func (s *journaledStore) GetObject(key string) (interface{}, error) {
+	s.Flush()
+	return s.backend.GetObject(key)
+}
+
+// DelObject implements storage.Store
+func (s *journaledStore) DelObject(key string) error {
+	return s.append(&journalRecord{Op: JournalOpDel, Key: key})
+}
+
+// PutObject implements storage.Store
+func (s *journaledStore) PutObject(key string, obj interface{}, revision int64) error {
+	data, err := s.TextFromObjectFunc(obj)
+	if err != nil {
+		return err
+	}
+	return s.append(&journalRecord{Op: JournalOpPut, Key: key, Revision: revision, Data: data})
+}
+
+// Flush block until all journal records are applied into backend store
+func (s *journaledStore) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.committing || s.pending > 0 {
+		s.applied.Wait()
+	}
+}
+
+// append add record into current batch and wait for batch is synced to journal file,
+// the first caller finding no commit in progress commit batches until there is no more record added by others
+func (s *journaledStore) append(record *journalRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	done := make(chan error, 1)
+	s.mu.Lock()
+	if s.file == nil {
+		s.mu.Unlock()
+		return errors.New("journal is closed")
+	}
+	s.batch = append(s.batch, record)
+	s.batchData = append(s.batchData, line...)
+	s.batchWaiters = append(s.batchWaiters, done)
+	if !s.committing {
+		s.committing = true
+		s.commit()
+		s.committing = false
+		s.applied.Broadcast()
+	}
+	s.mu.Unlock()
+	return <-done
+}
+
+// commit must be called with lock held, it release lock while writing batch into journal file,
+// committed records are handed to run go routine to apply into backend store
+func (s *journaledStore) commit() {
+	for len(s.batch) > 0 {
+		batch, data, waiters := s.batch, s.batchData, s.batchWaiters
+		s.batch, s.batchData, s.batchWaiters = []*journalRecord{}, []byte{}, []chan error{}
+		file := s.file
+		s.mu.Unlock()
+
+		err := writeAndSync(file, data)
+
+		s.mu.Lock()
+		if err == nil {
+			s.pending += len(batch)
+			s.records = append(s.records, batch...)
+			s.appended.Signal()
+		}
+		for _, v := range waiters {
+			v <- err
+		}
+	}
+}
+
+func (s *journaledStore) applyRecord(record *journalRecord) error {
+	switch record.Op {
+	case JournalOpPut:
+		obj, err := s.TextToObjectFunc(record.Data)
+		if err != nil {
+			return err
+		}
+		return s.backend.PutObject(record.Key, obj, record.Revision)
+	case JournalOpDel:
+		err := s.backend.DelObject(record.Key)
+		if err == storage.ObjectNotFound {
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown journal op %s", record.Op)
+	}
+}
+
+// run apply journal records into backend store in order, and truncate journal when there is no pending record
+func (s *journaledStore) run() {
+	for {
+		s.mu.Lock()
+		for len(s.records) == 0 && s.file != nil {
+			s.appended.Wait()
+		}
+		if len(s.records) == 0 {
+			// journal closed
+			s.mu.Unlock()
+			return
+		}
+		// records failed to apply last time are retried before new records to keep changes in order
+		records := append(append([]*journalRecord{}, s.failed...), s.records...)
+		numOfNewRecords := len(s.records)
+		s.records = []*journalRecord{}
+		s.mu.Unlock()
+
+		failed := s.applyRecords(records)
+
+		s.mu.Lock()
+		s.pending -= numOfNewRecords
+		s.failed = failed
+		if s.pending == 0 {
+			if err := s.truncate(); err != nil {
+				klog.ErrorS(err, "Failed to truncate journal", "journal", s.path)
+			}
+			s.applied.Broadcast()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// writeAndSync write data at end of journal file and sync it, a partially written data is cut off,
+// so, it does not break next record written after it
+func writeAndSync(file *os.File, data []byte) error {
+	if file == nil {
+		return errors.New("journal is closed")
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		if terr := file.Truncate(offset); terr != nil {
+			klog.ErrorS(terr, "Failed to cut off partially written journal records", "journal", file.Name())
+		}
+		file.Seek(offset, io.SeekStart)
+	}
+	return err
+}
+
+// applyRecords apply records in order and return records failed to apply,
+// a failed record is dropped when a later record of same key is applied, as it's overwritten by later record
+func (s *journaledStore) applyRecords(records []*journalRecord) []*journalRecord {
+	lastApplied := map[string]int{}
+	failedIndexes := []int{}
+	for i, record := range records {
+		if err := s.applyRecord(record); err != nil {
+			klog.ErrorS(err, "Failed to apply journal record to backend store, it will be retried with next change or replayed when journal is reopened", "journal", s.path, "key", record.Key)
+			failedIndexes = append(failedIndexes, i)
+		} else {
+			lastApplied[record.Key] = i
+		}
+	}
+
+	failed := []*journalRecord{}
+	for _, i := range failedIndexes {
+		if j, found := lastApplied[records[i].Key]; found && j > i {
+			continue
+		}
+		failed = append(failed, records[i])
+	}
+	return failed
+}
+
+// truncate must be called with lock held, it's only called when all records have been applied to backend store,
+// if a record failed to apply, keep journal for retry and replay on next open, or a batch is being written into journal
+func (s *journaledStore) truncate() error {
+	if s.file == nil || len(s.failed) > 0 || s.committing {
+		return nil
+	}
+	if err := s.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := s.file.Seek(0, 0)
+	return err
+}
+
+// replay apply journal records left by last run into backend store, a torn record at end of journal is ignored
+func (s *journaledStore) replay() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	num := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		record := &journalRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			klog.Warningf("Ignore a broken record in journal %s, %v", s.path, err)
+			continue
+		}
+		if err := s.applyRecord(record); err != nil {
+			return err
+		}
+		num += 1
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	klog.InfoS("Replayed journal into backend store", "journal", s.path, "records", num)
+	return nil
+}
+
+// Close wait for pending records are applied and close journal
+func (s *journaledStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for s.committing || s.pending > 0 {
+		s.applied.Wait()
+	}
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	s.appended.Broadcast()
+	return err
+}
+
+// NewJournaledStore open journal at path, replay records in journal into backend store and truncate it,
+// toObjectFunc and fromObjectFunc should be same functions used by backend store
+func NewJournaledStore(path string, backend storage.Store, toObjectFunc storage.TextToObjectFunc, fromObjectFunc storage.TextFromObjectFunc) (*journaledStore, error) {
+	if toObjectFunc == nil {
+		return nil, errors.New("TextToObject func is not provided to NewJournaledStore")
+	}
+	if fromObjectFunc == nil {
+		return nil, errors.New("TextFromObject func is not provided to NewJournaledStore")
+	}
+	s := &journaledStore{
+		mu:                 sync.Mutex{},
+		path:               path,
+		backend:            backend,
+		batch:              []*journalRecord{},
+		batchData:          []byte{},
+		batchWaiters:       []chan error{},
+		records:            []*journalRecord{},
+		pending:            0,
+		failed:             []*journalRecord{},
+		TextToObjectFunc:   toObjectFunc,
+		TextFromObjectFunc: fromObjectFunc,
+	}
+	s.appended = sync.NewCond(&s.mu)
+	s.applied = sync.NewCond(&s.mu)
+
+	if err := s.replay(); err != nil {
+		return nil, fmt.Errorf("failed to replay journal %s, cause %v", path, err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	if err := s.truncate(); err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+var _ storage.Store = &journaledStore{}
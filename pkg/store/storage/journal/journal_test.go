@@ -0,0 +1,224 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+)
+
+// fakeBackend keep objects in a map, it fail to put objects with a revision in failRevisions
+type fakeBackend struct {
+	mu            sync.Mutex
+	objs          map[string]string
+	failRevisions map[int64]bool
+}
+
+func (b *fakeBackend) ListObject() ([]interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	objs := []interface{}{}
+	for _, v := range b.objs {
+		objs = append(objs, v)
+	}
+	return objs, nil
+}
+
+func (b *fakeBackend) GetObject(key string) (interface{}, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if obj, found := b.objs[key]; found {
+		return obj, nil
+	}
+	return nil, storage.ObjectNotFound
+}
+
+func (b *fakeBackend) DelObject(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, found := b.objs[key]; !found {
+		return storage.ObjectNotFound
+	}
+	delete(b.objs, key)
+	return nil
+}
+
+func (b *fakeBackend) PutObject(key string, obj interface{}, revision int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failRevisions[revision] {
+		return fmt.Errorf("failed to put %s at revision %d", key, revision)
+	}
+	b.objs[key] = obj.(string)
+	return nil
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{objs: map[string]string{}, failRevisions: map[int64]bool{}}
+}
+
+func textToString(text []byte) (interface{}, error) {
+	return string(text), nil
+}
+
+func textFromString(obj interface{}) ([]byte, error) {
+	return []byte(obj.(string)), nil
+}
+
+func journalSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat journal, %v", err)
+	}
+	return info.Size()
+}
+
+func TestJournalReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.journal")
+	backend := newFakeBackend()
+	backend.objs["deleted"] = "v0"
+
+	// journal left by last run, it end with a torn record written when process crashed
+	data := []byte{}
+	for _, record := range []*journalRecord{
+		{Op: JournalOpPut, Key: "a", Revision: 1, Data: []byte("v1")},
+		{Op: JournalOpPut, Key: "b", Revision: 2, Data: []byte("v2")},
+		{Op: JournalOpPut, Key: "a", Revision: 3, Data: []byte("v3")},
+		{Op: JournalOpDel, Key: "deleted"},
+		{Op: JournalOpDel, Key: "notexist"},
+	} {
+		line, _ := json.Marshal(record)
+		data = append(append(data, line...), '\n')
+	}
+	data = append(data, []byte(`{"op":"put","key":"c","rev":4,"da`)...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write journal, %v", err)
+	}
+
+	s, err := NewJournaledStore(path, backend, textToString, textFromString)
+	if err != nil {
+		t.Fatalf("failed to open journal, %v", err)
+	}
+	defer s.Close()
+	if len(backend.objs) != 2 || backend.objs["a"] != "v3" || backend.objs["b"] != "v2" {
+		t.Errorf("expect journal replayed in order and torn record ignored, got %v", backend.objs)
+	}
+	if size := journalSize(t, path); size != 0 {
+		t.Errorf("expect journal truncated after replay, got %d bytes", size)
+	}
+}
+
+func TestJournalGroupCommitAndTruncate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.journal")
+	backend := newFakeBackend()
+	s, err := NewJournaledStore(path, backend, textToString, textFromString)
+	if err != nil {
+		t.Fatalf("failed to open journal, %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.PutObject(fmt.Sprintf("key%d", i), fmt.Sprintf("v%d", i), int64(i+1)); err != nil {
+				t.Errorf("failed to put object, %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := s.DelObject("key0"); err != nil {
+		t.Fatalf("failed to delete object, %v", err)
+	}
+	objs, err := s.ListObject()
+	if err != nil || len(objs) != 99 {
+		t.Errorf("expect all committed changes applied, got %d objects, %v", len(objs), err)
+	}
+	if obj, err := s.GetObject("key99"); err != nil || obj != "v99" {
+		t.Errorf("expect key99 applied, got %v, %v", obj, err)
+	}
+	if size := journalSize(t, path); size != 0 {
+		t.Errorf("expect journal truncated when all changes applied, got %d bytes", size)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close journal, %v", err)
+	}
+	if err := s.PutObject("key100", "v100", 101); err == nil {
+		t.Errorf("expect put into closed journal failed")
+	}
+}
+
+func TestJournalRetryFailedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.journal")
+	backend := newFakeBackend()
+	backend.failRevisions[1] = true
+	s, err := NewJournaledStore(path, backend, textToString, textFromString)
+	if err != nil {
+		t.Fatalf("failed to open journal, %v", err)
+	}
+	defer s.Close()
+
+	// failed record is kept in journal
+	if err := s.PutObject("a", "v1", 1); err != nil {
+		t.Fatalf("failed to put object, %v", err)
+	}
+	s.Flush()
+	if _, found := backend.objs["a"]; found || len(s.failed) != 1 || journalSize(t, path) == 0 {
+		t.Fatalf("expect failed record kept in journal, got %v, %d failed records", backend.objs, len(s.failed))
+	}
+
+	// failed record is retried with next change, journal is truncated once it's applied
+	backend.mu.Lock()
+	backend.failRevisions[1] = false
+	backend.mu.Unlock()
+	if err := s.PutObject("b", "v2", 2); err != nil {
+		t.Fatalf("failed to put object, %v", err)
+	}
+	s.Flush()
+	if backend.objs["a"] != "v1" || backend.objs["b"] != "v2" || len(s.failed) != 0 {
+		t.Errorf("expect failed record retried, got %v, %d failed records", backend.objs, len(s.failed))
+	}
+	if size := journalSize(t, path); size != 0 {
+		t.Errorf("expect journal truncated after failed record applied, got %d bytes", size)
+	}
+
+	// failed record is dropped when a later change of same key is applied
+	backend.mu.Lock()
+	backend.failRevisions[3] = true
+	backend.mu.Unlock()
+	if err := s.PutObject("a", "v3", 3); err != nil {
+		t.Fatalf("failed to put object, %v", err)
+	}
+	s.Flush()
+	if err := s.PutObject("a", "v4", 4); err != nil {
+		t.Fatalf("failed to put object, %v", err)
+	}
+	s.Flush()
+	if backend.objs["a"] != "v4" || len(s.failed) != 0 {
+		t.Errorf("expect failed record overwritten by later change, got %v, %d failed records", backend.objs, len(s.failed))
+	}
+	if size := journalSize(t, path); size != 0 {
+		t.Errorf("expect journal truncated when failed record is overwritten, got %d bytes", size)
+	}
+}
@@ -17,16 +17,81 @@ limitations under the License.
 package util
 
 import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
 	"time"
 
 	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 const (
 	DefaultApplicationPodBurst                       = 2
 	DefaultApplicationSesionDeleteGracePeriodSeconds = int64(5)
+	DefaultApplicationSessionsPerInstance            = 1
+	DefaultApplicationRevisionHistoryLimit           = 10
+)
+
+var (
+	DefaultApplicationMaxSurge       = intstr.FromString("25%")
+	DefaultApplicationMaxUnavailable = intstr.FromString("25%")
 )
 
+// ApplicationTemplateHash return hash of application template, instances of a revision are labeled with it
+func ApplicationTemplateHash(app *fornaxv1.Application) string {
+	data, _ := json.Marshal(app.Spec.Template())
+	hasher := fnv.New64a()
+	hasher.Write(data)
+	return fmt.Sprintf("%x", hasher.Sum64())
+}
+
+// PodApplicationRevision return template hash of application revision which pod is created from
+func PodApplicationRevision(pod *v1.Pod) string {
+	return pod.GetLabels()[fornaxv1.LabelFornaxCoreApplicationRevision]
+}
+
+func ApplicationRevisionHistoryLimit(app *fornaxv1.Application) int {
+	if app.Spec.RevisionHistoryLimit == nil {
+		return DefaultApplicationRevisionHistoryLimit
+	}
+	return int(*app.Spec.RevisionHistoryLimit)
+}
+
+// ApplicationMaxSurgeAndUnavailable resolve update strategy against desired idle instances, surge is rounded up and unavailable is rounded down,
+// unavailable is set to 1 if both are 0, otherwise old instances can never be replaced
+func ApplicationMaxSurgeAndUnavailable(app *fornaxv1.Application, desired int) (int, int) {
+	maxSurge, maxUnavailable := &DefaultApplicationMaxSurge, &DefaultApplicationMaxUnavailable
+	if app.Spec.UpdateStrategy.MaxSurge != nil {
+		maxSurge = app.Spec.UpdateStrategy.MaxSurge
+	}
+	if app.Spec.UpdateStrategy.MaxUnavailable != nil {
+		maxUnavailable = app.Spec.UpdateStrategy.MaxUnavailable
+	}
+	surge, err := intstr.GetScaledValueFromIntOrPercent(maxSurge, desired, true)
+	if err != nil || surge < 0 {
+		surge = 0
+	}
+	unavailable, err := intstr.GetScaledValueFromIntOrPercent(maxUnavailable, desired, false)
+	if err != nil || unavailable < 0 {
+		unavailable = 0
+	}
+	if surge == 0 && unavailable == 0 {
+		unavailable = 1
+	}
+	return surge, unavailable
+}
+
+// ApplicationSessionsPerInstance return max number of concurrent sessions on one application instance
+func ApplicationSessionsPerInstance(app *fornaxv1.Application) int {
+	if app.Spec.SessionsPerInstance <= 0 {
+		return DefaultApplicationSessionsPerInstance
+	}
+	return int(app.Spec.SessionsPerInstance)
+}
+
 func ApplicationScalingBurst(app *fornaxv1.Application) int {
 	if app.Spec.ScalingPolicy.Burst == 0 {
 		return DefaultApplicationPodBurst
@@ -38,6 +103,7 @@ func SessionIsOpen(session *fornaxv1.ApplicationSession) bool {
 	return session.Status.SessionStatus != fornaxv1.SessionStatusUnspecified &&
 		session.Status.SessionStatus != fornaxv1.SessionStatusPending &&
 		session.Status.SessionStatus != fornaxv1.SessionStatusClosed &&
+		session.Status.SessionStatus != fornaxv1.SessionStatusEvacuated &&
 		session.Status.SessionStatus != fornaxv1.SessionStatusTimeout
 }
 
@@ -49,6 +115,14 @@ func SessionIsClosing(session *fornaxv1.ApplicationSession) bool {
 	return session.Status.SessionStatus == fornaxv1.SessionStatusClosing
 }
 
+func SessionIsEvacuating(session *fornaxv1.ApplicationSession) bool {
+	return session.Status.SessionStatus == fornaxv1.SessionStatusEvacuating
+}
+
+func SessionIsEvacuated(session *fornaxv1.ApplicationSession) bool {
+	return session.Status.SessionStatus == fornaxv1.SessionStatusEvacuated
+}
+
 func SessionIsPending(session *fornaxv1.ApplicationSession) bool {
 	return session.DeletionTimestamp == nil && (session.Status.SessionStatus == fornaxv1.SessionStatusPending || session.Status.SessionStatus == fornaxv1.SessionStatusUnspecified)
 }
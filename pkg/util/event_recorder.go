@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/tools/reference"
+	"k8s.io/klog/v2"
+)
+
+// events of cluster scoped resources like node are put in default namespace
+const DefaultEventNamespace = metav1.NamespaceDefault
+
+var eventScheme = runtime.NewScheme()
+
+func init() {
+	clientgoscheme.AddToScheme(eventScheme)
+	fornaxv1.AddToScheme(eventScheme)
+}
+
+// EventSink receive events built by EventRecorder, e.g. save them in store or send them to fornaxcore
+type EventSink func(event *v1.Event)
+
+func NewEventRecorder(source v1.EventSource, sink EventSink) record.EventRecorder {
+	return &EventRecorder{
+		source: source,
+		sink:   sink,
+	}
+}
+
+var _ record.EventRecorder = &EventRecorder{}
+
+// EventRecorder build k8s events of fornax and k8s objects and put them into a event sink
+type EventRecorder struct {
+	source v1.EventSource
+	sink   EventSink
+}
+
+// AnnotatedEventf implements record.EventRecorder
+func (r *EventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype string, reason string, messageFmt string, args ...interface{}) {
+	r.generateEvent(object, annotations, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// Event implements record.EventRecorder
+func (r *EventRecorder) Event(object runtime.Object, eventtype string, reason string, message string) {
+	r.generateEvent(object, nil, eventtype, reason, message)
+}
+
+// Eventf implements record.EventRecorder
+func (r *EventRecorder) Eventf(object runtime.Object, eventtype string, reason string, messageFmt string, args ...interface{}) {
+	r.generateEvent(object, nil, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *EventRecorder) generateEvent(object runtime.Object, annotations map[string]string, eventtype, reason, message string) {
+	event, err := NewEvent(r.source, object, annotations, eventtype, reason, message)
+	if err != nil {
+		klog.ErrorS(err, "Could not construct event", "reason", reason, "message", message)
+		return
+	}
+	r.sink(event)
+}
+
+// NewEvent build a event of a object, event name is derived from object, type, reason and message,
+// so, same event of a object repeated again use same name and can be aggregated into one event with a count
+func NewEvent(source v1.EventSource, object runtime.Object, annotations map[string]string, eventtype, reason, message string) (*v1.Event, error) {
+	ref, err := reference.GetReference(eventScheme, object)
+	if err != nil {
+		return nil, err
+	}
+	namespace := ref.Namespace
+	if len(namespace) == 0 {
+		namespace = DefaultEventNamespace
+	}
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("%s/%s/%s/%s/%s/%s", ref.Kind, ref.Name, ref.UID, eventtype, reason, message)))
+	t := metav1.Now()
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s.%x", ref.Name, h.Sum32()),
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		InvolvedObject:      *ref,
+		Reason:              reason,
+		Message:             message,
+		FirstTimestamp:      t,
+		LastTimestamp:       t,
+		Count:               1,
+		Type:                eventtype,
+		Source:              source,
+		ReportingController: source.Component,
+		ReportingInstance:   source.Host,
+	}, nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNewEvent(t *testing.T) {
+	source := v1.EventSource{Component: "test", Host: "host"}
+	session := &fornaxv1.ApplicationSession{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session", UID: "uid"}}
+	event, err := NewEvent(source, session, map[string]string{"key": "value"}, v1.EventTypeWarning, "Timeout", "session open timeout")
+	if err != nil {
+		t.Fatalf("failed to build event, %v", err)
+	}
+	ref := event.InvolvedObject
+	if ref.Kind != "ApplicationSession" || ref.Namespace != "test" || ref.Name != "session" || ref.UID != "uid" {
+		t.Errorf("expect event involve session, got %v", ref)
+	}
+	if event.Namespace != "test" || event.Count != 1 || event.ReportingController != "test" || event.ReportingInstance != "host" || event.Annotations["key"] != "value" {
+		t.Errorf("expect event in session namespace reported by source, got %v", event)
+	}
+	if !event.FirstTimestamp.Equal(&event.LastTimestamp) {
+		t.Errorf("expect first and last timestamp same, got %v and %v", event.FirstTimestamp, event.LastTimestamp)
+	}
+
+	tests := []struct {
+		name      string
+		object    runtime.Object
+		eventtype string
+		reason    string
+		message   string
+		sameName  bool
+	}{
+		{name: "same event repeated", object: session, eventtype: v1.EventTypeWarning, reason: "Timeout", message: "session open timeout", sameName: true},
+		{name: "different type", object: session, eventtype: v1.EventTypeNormal, reason: "Timeout", message: "session open timeout"},
+		{name: "different reason", object: session, eventtype: v1.EventTypeWarning, reason: "Closed", message: "session open timeout"},
+		{name: "different message", object: session, eventtype: v1.EventTypeWarning, reason: "Timeout", message: "session close timeout"},
+		{
+			name:      "recreated object with same name",
+			object:    &fornaxv1.ApplicationSession{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session", UID: "new-uid"}},
+			eventtype: v1.EventTypeWarning,
+			reason:    "Timeout",
+			message:   "session open timeout",
+		},
+	}
+	for _, test := range tests {
+		e, err := NewEvent(source, test.object, nil, test.eventtype, test.reason, test.message)
+		if err != nil {
+			t.Fatalf("%s: failed to build event, %v", test.name, err)
+		}
+		if sameName := e.Name == event.Name; sameName != test.sameName {
+			t.Errorf("%s: expect same event name %v, got %s and %s", test.name, test.sameName, e.Name, event.Name)
+		}
+	}
+
+	// events of cluster scoped object are in default namespace
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+	if e, err := NewEvent(source, node, nil, v1.EventTypeNormal, "Disconnected", "node disconnected"); err != nil || e.Namespace != DefaultEventNamespace {
+		t.Errorf("expect node event in default namespace, got %v, %v", e, err)
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	events := []*v1.Event{}
+	recorder := NewEventRecorder(v1.EventSource{Component: "test"}, func(event *v1.Event) {
+		events = append(events, event)
+	})
+	session := &fornaxv1.ApplicationSession{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "session"}}
+	recorder.Eventf(session, v1.EventTypeWarning, "Timeout", "session %s open timeout", "test/session")
+	recorder.Event(session, v1.EventTypeNormal, "Closed", "session closed")
+	if len(events) != 2 || events[0].Message != "session test/session open timeout" || events[1].Reason != "Closed" {
+		t.Errorf("expect events put into sink, got %v", events)
+	}
+
+	// object not known by event scheme is not recorded
+	recorder.Event(&runtime.Unknown{}, v1.EventTypeNormal, "Unknown", "unknown object")
+	if len(events) != 2 {
+		t.Errorf("expect event of unknown object dropped, got %d events", len(events))
+	}
+}
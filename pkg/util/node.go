@@ -17,6 +17,8 @@ limitations under the License.
 package util
 
 import (
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+
 	v1 "k8s.io/api/core/v1"
 )
 
@@ -38,3 +40,15 @@ func IsNodeCondtionReady(v1node *v1.Node) bool {
 func IsNodeRunning(v1node *v1.Node) bool {
 	return v1node.Status.Phase == v1.NodeRunning
 }
+
+// IsNodeSchedulable return true if node is running and not marked unschedulable, e.g. node is being evacuated
+func IsNodeSchedulable(v1node *v1.Node) bool {
+	return IsNodeRunning(v1node) && !v1node.Spec.Unschedulable
+}
+
+func NodeHasEvacuateAnnotation(v1node *v1.Node) bool {
+	if _, found := v1node.GetAnnotations()[fornaxv1.AnnotationFornaxCoreEvacuate]; found {
+		return true
+	}
+	return false
+}
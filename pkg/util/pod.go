@@ -190,6 +190,21 @@ func MergePod(fromPod, toPod *v1.Pod) {
 	}
 }
 
+// PodHostPortEndPoints return host ip and port of each pod container port, in order of containers and their ports
+func PodHostPortEndPoints(pod *v1.Pod) []fornaxv1.AccessEndPoint {
+	endpoints := []fornaxv1.AccessEndPoint{}
+	for _, cont := range pod.Spec.Containers {
+		for _, port := range cont.Ports {
+			endpoints = append(endpoints, fornaxv1.AccessEndPoint{
+				Protocol:  port.Protocol,
+				IPAddress: port.HostIP,
+				Port:      port.HostPort,
+			})
+		}
+	}
+	return endpoints
+}
+
 func PodIsRunning(pod *v1.Pod) bool {
 	return pod.Status.Phase == v1.PodRunning
 }
@@ -237,6 +252,30 @@ func PodHasSessionServiceAnnotation(pod *v1.Pod) bool {
 	return false
 }
 
+// PodHasEvacuateAnnotation return true if pod is requested to evacuate its sessions and terminate
+func PodHasEvacuateAnnotation(pod *v1.Pod) bool {
+	if _, found := pod.GetAnnotations()[fornaxv1.AnnotationFornaxCoreEvacuate]; found {
+		return true
+	}
+	return false
+}
+
+// PodHasSessionPendingAnnotation return true if pod is created for a pending session, not for idle buffer
+func PodHasSessionPendingAnnotation(pod *v1.Pod) bool {
+	if _, found := pod.GetAnnotations()[fornaxv1.AnnotationFornaxCoreSessionPendingPod]; found {
+		return true
+	}
+	return false
+}
+
+// PodPriority return pod spec priority, 0 if it's not set
+func PodPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
 func GetPodSessionNames(pod *v1.Pod) []string {
 	if label, found := pod.GetLabels()[fornaxv1.LabelFornaxCoreApplicationSession]; found {
 		return strings.Split(label, ",")
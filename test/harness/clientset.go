@@ -0,0 +1,285 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"context"
+	"fmt"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/client/clientset/versioned/fake"
+	fornaxstore "centaurusinfra.io/fornax-serverless/pkg/store"
+	"centaurusinfra.io/fornax-serverless/pkg/store/inmemory"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	"github.com/google/uuid"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/watch"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	storeerr "k8s.io/apiserver/pkg/storage/errors"
+	"k8s.io/client-go/testing"
+	"sigs.k8s.io/apiserver-runtime/pkg/builder/resource"
+	"sigs.k8s.io/apiserver-runtime/pkg/builder/resource/resourcestrategy"
+)
+
+// resourceStorage serve a resource of fake clientset from fornaxcore in memory store,
+// it mimic api server registry, objects are validated, status is only updated through status subresource,
+// object with finalizers is marked deleting and removed after finalizers are cleared
+type resourceStorage struct {
+	ctx           context.Context
+	store         *inmemory.MemoryStore
+	groupResource schema.GroupResource
+	groupKind     schema.GroupKind
+	grvKey        string
+	newFunc       func() resource.Object
+	newListFunc   func() runtime.Object
+}
+
+func (s *resourceStorage) key(namespace, name string) string {
+	if len(name) == 0 {
+		if len(namespace) == 0 {
+			return s.grvKey
+		}
+		return fmt.Sprintf("%s/%s", s.grvKey, namespace)
+	}
+	return fmt.Sprintf("%s/%s/%s", s.grvKey, namespace, name)
+}
+
+func (s *resourceStorage) react(action testing.Action) (bool, runtime.Object, error) {
+	ns := action.GetNamespace()
+	switch action.GetVerb() {
+	case "get":
+		obj, err := s.get(ns, action.(testing.GetAction).GetName())
+		return true, obj, err
+	case "list":
+		obj, err := s.list(ns, action.(testing.ListAction).GetListRestrictions().Labels)
+		return true, obj, err
+	case "create":
+		obj, err := s.create(ns, action.(testing.CreateAction).GetObject())
+		return true, obj, err
+	case "update":
+		obj, err := s.update(ns, action.(testing.UpdateAction).GetObject(), action.GetSubresource())
+		return true, obj, err
+	case "delete":
+		return true, nil, s.delete(ns, action.(testing.DeleteAction).GetName())
+	}
+	return false, nil, nil
+}
+
+func (s *resourceStorage) reactWatch(action testing.Action) (bool, watch.Interface, error) {
+	restrictions := action.(testing.WatchAction).GetWatchRestrictions()
+	w, err := s.store.Watch(s.ctx, s.key(action.GetNamespace(), ""), apistorage.ListOptions{
+		ResourceVersion: restrictions.ResourceVersion,
+		Predicate:       apistorage.Everything,
+		Recursive:       true,
+	})
+	if err != nil {
+		return true, nil, err
+	}
+	selector := restrictions.Labels
+	if selector == nil || selector.Empty() {
+		return true, w, nil
+	}
+	return true, watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		objMeta, err := meta.Accessor(in.Object)
+		return in, err == nil && selector.Matches(labels.Set(objMeta.GetLabels()))
+	}), nil
+}
+
+func (s *resourceStorage) get(namespace, name string) (runtime.Object, error) {
+	out := s.newFunc()
+	if err := s.store.Get(s.ctx, s.key(namespace, name), apistorage.GetOptions{}, out); err != nil {
+		return nil, storeerr.InterpretGetError(err, s.groupResource, name)
+	}
+	// memory store return object shared with its cache
+	return out.DeepCopyObject(), nil
+}
+
+func (s *resourceStorage) list(namespace string, selector labels.Selector) (runtime.Object, error) {
+	out := s.newListFunc()
+	if err := s.store.GetList(s.ctx, s.key(namespace, ""), apistorage.ListOptions{Predicate: apistorage.Everything, Recursive: true}, out); err != nil {
+		return nil, storeerr.InterpretListError(err, s.groupResource)
+	}
+	items, err := meta.ExtractList(out)
+	if err != nil {
+		return nil, err
+	}
+	selected := []runtime.Object{}
+	for _, v := range items {
+		objMeta, err := meta.Accessor(v)
+		if err != nil {
+			return nil, err
+		}
+		if selector == nil || selector.Matches(labels.Set(objMeta.GetLabels())) {
+			selected = append(selected, v.DeepCopyObject())
+		}
+	}
+	if err := meta.SetList(out, selected); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (s *resourceStorage) create(namespace string, obj runtime.Object) (runtime.Object, error) {
+	newObj := obj.DeepCopyObject().(resource.Object)
+	objMeta := newObj.GetObjectMeta()
+	if len(objMeta.Name) == 0 && len(objMeta.GenerateName) > 0 {
+		objMeta.Name = objMeta.GenerateName + utilrand.String(5)
+	}
+	if len(objMeta.Name) == 0 {
+		return nil, apierrors.NewBadRequest("name or generateName is required")
+	}
+	objMeta.Namespace = namespace
+	objMeta.UID = types.UID(uuid.New().String())
+	objMeta.CreationTimestamp = metav1.Now()
+	objMeta.DeletionTimestamp = nil
+	objMeta.Generation = 1
+	if v, ok := newObj.(resourcestrategy.PrepareForCreater); ok {
+		v.PrepareForCreate(s.ctx)
+	}
+	if v, ok := newObj.(resourcestrategy.Validater); ok {
+		if errs := v.Validate(s.ctx); len(errs) > 0 {
+			return nil, apierrors.NewInvalid(s.groupKind, objMeta.Name, errs)
+		}
+	}
+
+	out := s.newFunc()
+	if err := s.store.Create(s.ctx, s.key(namespace, objMeta.Name), newObj, out, 0); err != nil {
+		return nil, storeerr.InterpretCreateError(err, s.groupResource, objMeta.Name)
+	}
+	return out.DeepCopyObject(), nil
+}
+
+func (s *resourceStorage) update(namespace string, obj runtime.Object, subresource string) (runtime.Object, error) {
+	updating := obj.DeepCopyObject().(resource.Object)
+	name := updating.GetObjectMeta().Name
+	key := s.key(namespace, name)
+	out := s.newFunc()
+	tryUpdate := func(existing runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		existingObj := existing.(resource.Object)
+		rv := updating.GetObjectMeta().ResourceVersion
+		if len(rv) > 0 && rv != existingObj.GetObjectMeta().ResourceVersion {
+			return nil, nil, apierrors.NewConflict(s.groupResource, name, fmt.Errorf("object has been modified, get it and update again"))
+		}
+
+		var updated resource.Object
+		if subresource == "status" {
+			updated = existingObj.DeepCopyObject().(resource.Object)
+			if v, ok := updating.(resource.ObjectWithStatusSubResource); ok {
+				v.GetStatus().CopyTo(updated.(resource.ObjectWithStatusSubResource))
+			}
+			return updated, nil, nil
+		}
+
+		updated = updating.DeepCopyObject().(resource.Object)
+		if v, ok := existingObj.(resource.ObjectWithStatusSubResource); ok {
+			v.GetStatus().CopyTo(updated.(resource.ObjectWithStatusSubResource))
+		}
+		updatedMeta, existingMeta := updated.GetObjectMeta(), existingObj.GetObjectMeta()
+		updatedMeta.Namespace = existingMeta.Namespace
+		updatedMeta.UID = existingMeta.UID
+		updatedMeta.CreationTimestamp = existingMeta.CreationTimestamp
+		updatedMeta.DeletionTimestamp = existingMeta.DeletionTimestamp
+		updatedMeta.DeletionGracePeriodSeconds = existingMeta.DeletionGracePeriodSeconds
+		if v, ok := updated.(resourcestrategy.PrepareForUpdater); ok {
+			v.PrepareForUpdate(s.ctx, existingObj)
+		}
+		if v, ok := updated.(resourcestrategy.ValidateUpdater); ok {
+			if errs := v.ValidateUpdate(s.ctx, existingObj); len(errs) > 0 {
+				return nil, nil, apierrors.NewInvalid(s.groupKind, name, errs)
+			}
+		}
+		return updated, nil, nil
+	}
+	if err := s.store.GuaranteedUpdate(s.ctx, key, out, false, nil, tryUpdate, nil); err != nil {
+		return nil, storeerr.InterpretUpdateError(err, s.groupResource, name)
+	}
+	// deleting object is removed when its last finalizer is removed
+	if fornaxstore.ShouldDeleteSpec(out) {
+		if err := s.store.Delete(s.ctx, key, s.newFunc(), nil, nil, nil); err != nil {
+			return nil, storeerr.InterpretDeleteError(err, s.groupResource, name)
+		}
+	}
+	return out.DeepCopyObject(), nil
+}
+
+func (s *resourceStorage) delete(namespace, name string) error {
+	key := s.key(namespace, name)
+	existing, err := s.get(namespace, name)
+	if err != nil {
+		return err
+	}
+	existingMeta := existing.(resource.Object).GetObjectMeta()
+	if len(existingMeta.Finalizers) == 0 {
+		if err := s.store.Delete(s.ctx, key, s.newFunc(), nil, nil, nil); err != nil {
+			return storeerr.InterpretDeleteError(err, s.groupResource, name)
+		}
+		return nil
+	}
+	if existingMeta.DeletionTimestamp != nil {
+		return nil
+	}
+
+	// object with finalizers is marked deleting, fornaxcore managers remove finalizers after cleanup
+	tryUpdate := func(existing runtime.Object, res apistorage.ResponseMeta) (runtime.Object, *uint64, error) {
+		deleting := existing.DeepCopyObject().(resource.Object)
+		deleting.GetObjectMeta().DeletionTimestamp = util.NewCurrentMetaTime()
+		deleting.GetObjectMeta().DeletionGracePeriodSeconds = new(int64)
+		return deleting, nil, nil
+	}
+	if err := s.store.GuaranteedUpdate(s.ctx, key, s.newFunc(), false, nil, tryUpdate, nil); err != nil {
+		return storeerr.InterpretDeleteError(err, s.groupResource, name)
+	}
+	return nil
+}
+
+// newFakeClientset return a generated fake clientset whose applications and application sessions are served by fornaxcore memory stores
+func newFakeClientset(ctx context.Context, appStore, sessionStore *inmemory.MemoryStore) *fake.Clientset {
+	client := fake.NewSimpleClientset()
+	storages := map[string]*resourceStorage{
+		fornaxv1.ApplicationGrv.Resource: {
+			ctx:           ctx,
+			store:         appStore,
+			groupResource: fornaxv1.ApplicationGrv.GroupResource(),
+			groupKind:     fornaxv1.ApplicationKind.GroupKind(),
+			grvKey:        fornaxv1.ApplicationGrvKey,
+			newFunc:       func() resource.Object { return &fornaxv1.Application{} },
+			newListFunc:   func() runtime.Object { return &fornaxv1.ApplicationList{} },
+		},
+		fornaxv1.ApplicationSessionGrv.Resource: {
+			ctx:           ctx,
+			store:         sessionStore,
+			groupResource: fornaxv1.ApplicationSessionGrv.GroupResource(),
+			groupKind:     fornaxv1.ApplicationSessionKind.GroupKind(),
+			grvKey:        fornaxv1.ApplicationSessionGrvKey,
+			newFunc:       func() resource.Object { return &fornaxv1.ApplicationSession{} },
+			newListFunc:   func() runtime.Object { return &fornaxv1.ApplicationSessionList{} },
+		},
+	}
+	for k, v := range storages {
+		client.PrependReactor("*", k, v.react)
+		client.PrependWatchReactor(k, v.reactWatch)
+	}
+	return client
+}
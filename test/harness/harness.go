@@ -0,0 +1,429 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"centaurusinfra.io/fornax-serverless/cmd/simulation/node/app/snode"
+	"centaurusinfra.io/fornax-serverless/cmd/simulation/node/config"
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	fornaxk8sv1 "centaurusinfra.io/fornax-serverless/pkg/apis/k8s/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/client/clientset/versioned"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/application"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/event"
+	grpc_server "centaurusinfra.io/fornax-serverless/pkg/fornaxcore/grpc/server"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/node"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/nodemonitor"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/pod"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/podscheduler"
+	"centaurusinfra.io/fornax-serverless/pkg/fornaxcore/session"
+	nconfig "centaurusinfra.io/fornax-serverless/pkg/nodeagent/config"
+	"centaurusinfra.io/fornax-serverless/pkg/store/factory"
+	"centaurusinfra.io/fornax-serverless/pkg/store/inmemory"
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	apistorage "k8s.io/apiserver/pkg/storage"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultNumOfNode           = 2
+	DefaultNumOfFornaxCore     = 1
+	DefaultPodCreateLatency    = 10 * time.Millisecond
+	DefaultPodTerminateLatency = 10 * time.Millisecond
+	DefaultPollInterval        = 50 * time.Millisecond
+
+	bufconnSize = 1024 * 1024
+	// simulated nodes dial fornaxcores through bufconn listeners, address is only used as fornaxcore identity
+	fornaxCoreAddressFormat = "bufconn-%d:18001"
+)
+
+type HarnessConfiguration struct {
+	NumOfNode           int
+	NodeNamePrefix      string
+	PodCreateLatency    time.Duration
+	PodTerminateLatency time.Duration
+	SchedulePolicy      *podscheduler.SchedulePolicy
+	// first FornaxCore is primary, others are standbys sharing stores with primary
+	NumOfFornaxCore int
+}
+
+func DefaultHarnessConfiguration() *HarnessConfiguration {
+	return &HarnessConfiguration{
+		NumOfNode:           DefaultNumOfNode,
+		NodeNamePrefix:      "harness-node",
+		PodCreateLatency:    DefaultPodCreateLatency,
+		PodTerminateLatency: DefaultPodTerminateLatency,
+		SchedulePolicy: &podscheduler.SchedulePolicy{
+			NumOfEvaluatedNodes: 100,
+			BackoffDuration:     1 * time.Second,
+			NodeSortingMethod:   podscheduler.NodeSortingMethodMoreMemory,
+		},
+		NumOfFornaxCore: DefaultNumOfFornaxCore,
+	}
+}
+
+// fornaxCore is a FornaxCore replica run by harness, it has its own memory stores, managers and grpc server like a FornaxCore process,
+// it serve a clientset like api server of a FornaxCore, replicas share stores through backend stores of harness like replicas sharing etcd
+type fornaxCore struct {
+	address      string
+	ctx          context.Context
+	cancel       context.CancelFunc
+	listener     *bufconn.Listener
+	client       versioned.Interface
+	nodeStore    *inmemory.MemoryStore
+	podStore     *inmemory.MemoryStore
+	appStore     *inmemory.MemoryStore
+	sessionStore *inmemory.MemoryStore
+	secretStore  *inmemory.MemoryStore
+	startPrimary func() error
+}
+
+// Harness run FornaxCore managers with fresh in memory stores and simulated nodes in process,
+// nodes talk with FornaxCore grpc servers over in memory connections,
+// applications and sessions are created, updated and deleted through a generated clientset served by primary FornaxCore stores
+type Harness struct {
+	config      *HarnessConfiguration
+	ctx         context.Context
+	cancel      context.CancelFunc
+	fornaxCores []*fornaxCore
+	primary     int
+	// backend stores shared by FornaxCore replicas, keyed by group resource
+	sharedStores map[schema.GroupResource]storage.Store
+	nodes        []*snode.SimulationNodeActor
+}
+
+// Client return a clientset of primary FornaxCore to create applications and sessions
+func (h *Harness) Client() versioned.Interface {
+	return h.fornaxCores[h.primary].client
+}
+
+// Start start FornaxCore managers and grpc servers, first FornaxCore become primary and others are standbys, and start nodes,
+// it return after all nodes are registered and ready
+func (h *Harness) Start(timeout time.Duration) error {
+	for _, v := range h.fornaxCores {
+		if err := h.startFornaxCore(v); err != nil {
+			return err
+		}
+	}
+	if err := h.fornaxCores[h.primary].startPrimary(); err != nil {
+		return err
+	}
+
+	if err := h.startNodes(timeout); err != nil {
+		return err
+	}
+	return h.waitForNodesRunning(timeout)
+}
+
+// Failover stop primary FornaxCore like it crashed, and make next FornaxCore primary,
+// new primary load stores shared by replicas and ask nodes to full sync, pods are rebuilt in new primary when nodes full sync
+func (h *Harness) Failover(timeout time.Duration) error {
+	if len(h.fornaxCores) < 2 {
+		return fmt.Errorf("harness does not have standby FornaxCore to fail over")
+	}
+	old := h.fornaxCores[h.primary]
+	old.cancel()
+	old.listener.Close()
+
+	h.primary = (h.primary + 1) % len(h.fornaxCores)
+	if err := h.fornaxCores[h.primary].startPrimary(); err != nil {
+		return err
+	}
+	return h.waitForNodesRunning(timeout)
+}
+
+// startFornaxCore create managers of a FornaxCore and start its grpc server as standby, managers are run by startPrimary
+func (h *Harness) startFornaxCore(fc *fornaxCore) error {
+	eventStore := newMemoryStore(fc.ctx, fornaxk8sv1.FornaxEventGrv.GroupResource(), fornaxk8sv1.FornaxEventGrvKey)
+	eventManager := event.NewEventManager(fc.ctx, eventStore)
+	eventManager.Run()
+	grpcServer := grpc_server.NewGrpcServer()
+	podManager := pod.NewPodManager(fc.ctx, fc.podStore, grpcServer)
+	sessionManager := session.NewSessionManager(fc.ctx, grpcServer, fc.sessionStore)
+	nodePodCidrManager, err := node.NewPodCidrManager(&node.NodeCidrConfig{
+		ClusterCidrs:         []string{node.DefaultClusterCidr},
+		NodeCidrMaskSizeIPv4: node.DefaultNodeCidrMaskSizeIPv4,
+		NodeCidrMaskSizeIPv6: node.DefaultNodeCidrMaskSizeIPv6,
+	})
+	if err != nil {
+		return err
+	}
+	nodeDaemonManager, err := node.NewNodeDaemonManager("")
+	if err != nil {
+		return err
+	}
+	nodeManager := node.NewNodeManager(fc.ctx, fc.nodeStore, grpcServer, podManager, sessionManager, nodePodCidrManager, nodeDaemonManager, eventManager.NewRecorder("fornax-node-manager"))
+	podScheduler := podscheduler.NewPodScheduler(fc.ctx, grpcServer, nodeManager, podManager, h.config.SchedulePolicy, eventManager.NewRecorder("fornax-scheduler"))
+	appManager := application.NewApplicationManager(fc.ctx, podManager, sessionManager, fc.appStore, fc.secretStore, eventManager.NewRecorder("fornax-application-manager"))
+	grpcServer.SetPodConfigProvider(appManager)
+	podScheduler.SetPodSessionProvider(appManager)
+	nodeManager.SetPodSessionReconciler(appManager)
+	grpcServer.SetNodeStore(fc.nodeStore)
+
+	grpcServer.SetStandby(true)
+	if err := grpcServer.ServeGrpcServer(fc.ctx, nodemonitor.NewNodeMonitor(nodeManager, eventManager), fc.listener, nil); err != nil {
+		return err
+	}
+	fc.startPrimary = func() error {
+		if len(h.fornaxCores) > 1 {
+			// load state saved by previous primary before managers start
+			err := factory.SyncFornaxSharedStorage(fc.ctx, map[schema.GroupResource]*inmemory.MemoryStore{
+				fornaxv1.ApplicationGrv.GroupResource():        fc.appStore,
+				fornaxv1.ApplicationSessionGrv.GroupResource(): fc.sessionStore,
+				fornaxk8sv1.FornaxSecretGrv.GroupResource():    fc.secretStore,
+				fornaxk8sv1.FornaxNodeGrv.GroupResource():      fc.nodeStore,
+			}, h.sharedStore)
+			if err != nil {
+				return err
+			}
+		}
+		podScheduler.Run()
+		podManager.Run(podScheduler)
+		nodeManager.Run()
+		appManager.Run(fc.ctx)
+		grpcServer.SetStandby(false)
+		return nil
+	}
+	return nil
+}
+
+func (h *Harness) startNodes(timeout time.Duration) error {
+	nodeConfig, err := nconfig.DefaultNodeConfiguration()
+	if err != nil {
+		return err
+	}
+	nodeConfig.NodeIP = "127.0.0.1"
+	fornaxCoreUrls := []string{}
+	listeners := map[string]*bufconn.Listener{}
+	for _, v := range h.fornaxCores {
+		fornaxCoreUrls = append(fornaxCoreUrls, v.address)
+		listeners[v.address] = v.listener
+	}
+	dialer := func(ctx context.Context, endpoint string) (net.Conn, error) {
+		listener, found := listeners[endpoint]
+		if !found {
+			return nil, fmt.Errorf("unknown fornaxcore endpoint %s", endpoint)
+		}
+		return listener.DialContext(ctx)
+	}
+	errCh := make(chan error, h.config.NumOfNode)
+	for i := 0; i < h.config.NumOfNode; i++ {
+		hostName := fmt.Sprintf("%s-%d", h.config.NodeNamePrefix, i)
+		nodeActor, err := snode.NewNodeActor(nodeConfig.NodeIP, hostName, &config.SimulationNodeConfiguration{
+			NodeConfig:          *nodeConfig,
+			NodeIP:              nodeConfig.NodeIP,
+			FornaxCoreUrls:      fornaxCoreUrls,
+			NumOfNode:           1,
+			PodConcurrency:      5,
+			NodeNamePrefix:      h.config.NodeNamePrefix,
+			PodCreateLatency:    h.config.PodCreateLatency,
+			PodTerminateLatency: h.config.PodTerminateLatency,
+			FornaxCoreDialer:    dialer,
+		})
+		if err != nil {
+			return err
+		}
+		h.nodes = append(h.nodes, nodeActor)
+		go func() {
+			// node actor start return after node is registered
+			errCh <- nodeActor.Start()
+		}()
+	}
+
+	deadline := time.After(timeout)
+	for range h.nodes {
+		select {
+		case err := <-errCh:
+			if err != nil {
+				return err
+			}
+		case <-deadline:
+			return fmt.Errorf("nodes are not registered in %s", timeout)
+		}
+	}
+	return nil
+}
+
+// waitForNodesRunning wait until all nodes are running in primary FornaxCore
+func (h *Harness) waitForNodesRunning(timeout time.Duration) error {
+	nodeStore := h.fornaxCores[h.primary].nodeStore
+	return h.WaitFor(timeout, func() (bool, error) {
+		nodes := &v1.NodeList{}
+		if err := nodeStore.GetList(h.ctx, fornaxk8sv1.FornaxNodeGrvKey, apistorage.ListOptions{Predicate: apistorage.Everything, Recursive: true}, nodes); err != nil {
+			return false, err
+		}
+		running := 0
+		for i := range nodes.Items {
+			if util.IsNodeRunning(&nodes.Items[i]) {
+				running += 1
+			}
+		}
+		return running == len(h.nodes), nil
+	})
+}
+
+// sharedStore return backend store of a resource shared by FornaxCore replicas
+func (h *Harness) sharedStore(groupResource schema.GroupResource) (storage.Store, error) {
+	backend, found := h.sharedStores[groupResource]
+	if !found {
+		return nil, fmt.Errorf("resource %s is not shared by FornaxCore replicas", groupResource)
+	}
+	return backend, nil
+}
+
+// Stop stop nodes and FornaxCore managers
+func (h *Harness) Stop() {
+	for _, v := range h.nodes {
+		if err := v.Stop(); err != nil {
+			klog.ErrorS(err, "Failed to stop simulation node")
+		}
+	}
+	// stores and managers exit when context is canceled
+	h.cancel()
+	for _, v := range h.fornaxCores {
+		v.listener.Close()
+	}
+}
+
+// WaitFor poll condition until it's true or timeout
+func (h *Harness) WaitFor(timeout time.Duration, condition func() (bool, error)) error {
+	return wait.PollImmediate(DefaultPollInterval, timeout, condition)
+}
+
+// WaitForApplication wait until application in store satisfy condition, and return it
+func (h *Harness) WaitForApplication(namespace, name string, timeout time.Duration, condition func(*fornaxv1.Application) bool) (*fornaxv1.Application, error) {
+	var application *fornaxv1.Application
+	err := h.WaitFor(timeout, func() (bool, error) {
+		obj, err := h.get(h.fornaxCores[h.primary].appStore, fmt.Sprintf("%s/%s/%s", fornaxv1.ApplicationGrvKey, namespace, name), &fornaxv1.Application{})
+		if err != nil || obj == nil {
+			return false, err
+		}
+		application = obj.(*fornaxv1.Application)
+		return condition(application), nil
+	})
+	if err != nil {
+		return application, fmt.Errorf("application %s/%s does not converge, last: %v, %v", namespace, name, application, err)
+	}
+	return application, nil
+}
+
+// WaitForSession wait until session in store satisfy condition, and return it, session is nil if it's deleted
+func (h *Harness) WaitForSession(namespace, name string, timeout time.Duration, condition func(*fornaxv1.ApplicationSession) bool) (*fornaxv1.ApplicationSession, error) {
+	var session *fornaxv1.ApplicationSession
+	err := h.WaitFor(timeout, func() (bool, error) {
+		obj, err := h.get(h.fornaxCores[h.primary].sessionStore, fmt.Sprintf("%s/%s/%s", fornaxv1.ApplicationSessionGrvKey, namespace, name), &fornaxv1.ApplicationSession{})
+		if err != nil {
+			return false, err
+		}
+		session = nil
+		if obj != nil {
+			session = obj.(*fornaxv1.ApplicationSession)
+		}
+		return condition(session), nil
+	})
+	if err != nil {
+		return session, fmt.Errorf("session %s/%s does not converge, last: %v, %v", namespace, name, session, err)
+	}
+	return session, nil
+}
+
+// ApplicationPods return pods of a application which are not deleted from FornaxCore
+func (h *Harness) ApplicationPods(namespace, name string) ([]*v1.Pod, error) {
+	pods := &v1.PodList{}
+	if err := h.fornaxCores[h.primary].podStore.GetList(h.ctx, fornaxk8sv1.FornaxPodGrvKey, apistorage.ListOptions{Predicate: apistorage.Everything, Recursive: true}, pods); err != nil {
+		return nil, err
+	}
+	appPods := []*v1.Pod{}
+	for i := range pods.Items {
+		if pods.Items[i].GetLabels()[fornaxv1.LabelFornaxCoreApplication] == fmt.Sprintf("%s/%s", namespace, name) {
+			appPods = append(appPods, pods.Items[i].DeepCopy())
+		}
+	}
+	return appPods, nil
+}
+
+// get return a copy of object in store, or nil if it does not exist
+func (h *Harness) get(store *inmemory.MemoryStore, key string, out runtime.Object) (runtime.Object, error) {
+	if err := store.Get(h.ctx, key, apistorage.GetOptions{IgnoreNotFound: true}, out); err != nil {
+		return nil, err
+	}
+	objMeta, err := meta.Accessor(out)
+	if err != nil {
+		return nil, err
+	}
+	if len(objMeta.GetName()) == 0 {
+		return nil, nil
+	}
+	return out.DeepCopyObject(), nil
+}
+
+func newMemoryStore(ctx context.Context, groupResource schema.GroupResource, grvKey string) *inmemory.MemoryStore {
+	return inmemory.NewMemoryStore(ctx, groupResource, grvKey, nil, nil)
+}
+
+// newFornaxCore create stores and clientset of a FornaxCore replica, replica is stopped when harness context is canceled
+func newFornaxCore(ctx context.Context, address string) *fornaxCore {
+	ctx, cancel := context.WithCancel(ctx)
+	fc := &fornaxCore{
+		address:      address,
+		ctx:          ctx,
+		cancel:       cancel,
+		listener:     bufconn.Listen(bufconnSize),
+		nodeStore:    newMemoryStore(ctx, fornaxk8sv1.FornaxNodeGrv.GroupResource(), fornaxk8sv1.FornaxNodeGrvKey),
+		podStore:     newMemoryStore(ctx, fornaxk8sv1.FornaxPodGrv.GroupResource(), fornaxk8sv1.FornaxPodGrvKey),
+		appStore:     newMemoryStore(ctx, fornaxv1.ApplicationGrv.GroupResource(), fornaxv1.ApplicationGrvKey),
+		sessionStore: newMemoryStore(ctx, fornaxv1.ApplicationSessionGrv.GroupResource(), fornaxv1.ApplicationSessionGrvKey),
+		secretStore:  newMemoryStore(ctx, fornaxk8sv1.FornaxSecretGrv.GroupResource(), fornaxk8sv1.FornaxSecretGrvKey),
+	}
+	fc.client = newFakeClientset(ctx, fc.appStore, fc.sessionStore)
+	return fc
+}
+
+// NewHarness create FornaxCore replicas of a harness, stores are not shared with other harness
+func NewHarness(config *HarnessConfiguration) *Harness {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Harness{
+		config:      config,
+		ctx:         ctx,
+		cancel:      cancel,
+		fornaxCores: []*fornaxCore{},
+		primary:     0,
+		sharedStores: map[schema.GroupResource]storage.Store{
+			fornaxv1.ApplicationGrv.GroupResource():        newSharedStore(),
+			fornaxv1.ApplicationSessionGrv.GroupResource(): newSharedStore(),
+			fornaxk8sv1.FornaxSecretGrv.GroupResource():    newSharedStore(),
+			fornaxk8sv1.FornaxNodeGrv.GroupResource():      newSharedStore(),
+		},
+		nodes: []*snode.SimulationNodeActor{},
+	}
+	for i := 0; i < config.NumOfFornaxCore || i == 0; i++ {
+		h.fornaxCores = append(h.fornaxCores, newFornaxCore(ctx, fmt.Sprintf(fornaxCoreAddressFormat, i)))
+	}
+	return h
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	fornaxv1 "centaurusinfra.io/fornax-serverless/pkg/apis/core/v1"
+	"centaurusinfra.io/fornax-serverless/pkg/util"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testNamespace = "harness"
+	testTimeout   = 30 * time.Second
+)
+
+func startHarness(t *testing.T) *Harness {
+	h := NewHarness(DefaultHarnessConfiguration())
+	if err := h.Start(testTimeout); err != nil {
+		h.Stop()
+		t.Fatalf("failed to start harness, %v", err)
+	}
+	t.Cleanup(h.Stop)
+	return h
+}
+
+func newTestApplication(name string, minimumInstance uint32) *fornaxv1.Application {
+	return &fornaxv1.Application{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: name},
+		Spec: fornaxv1.ApplicationSpec{
+			Containers: []v1.Container{{
+				Name:  "echoserver",
+				Image: "centaurusinfra.io/fornax-serverless/echoserver:v0.1.0",
+				Ports: []v1.ContainerPort{{Name: "echoserver", ContainerPort: 80}},
+				Resources: v1.ResourceRequirements{
+					Requests: map[v1.ResourceName]resource.Quantity{
+						v1.ResourceMemory: util.ResourceQuantity(50*1024*1024, v1.ResourceMemory),
+						v1.ResourceCPU:    util.ResourceQuantity(0.01*1000, v1.ResourceCPU),
+					},
+				},
+			}},
+			UsingNodeSessionService: true,
+			ScalingPolicy: fornaxv1.ScalingPolicy{
+				MinimumInstance:         minimumInstance,
+				MaximumInstance:         10,
+				Burst:                   10,
+				ScalingPolicyType:       fornaxv1.ScalingPolicyTypeIdleSessionNum,
+				IdleSessionNumThreshold: &fornaxv1.IdelSessionNumThreshold{HighWaterMark: 0, LowWaterMark: 0},
+			},
+		},
+	}
+}
+
+func idleInstances(num int32) func(*fornaxv1.Application) bool {
+	return func(app *fornaxv1.Application) bool {
+		return app.Status.IdleInstances == num && app.Status.TotalInstances == num && app.Status.PendingInstances == 0
+	}
+}
+
+func TestApplicationScaling(t *testing.T) {
+	h := startHarness(t)
+	appClient := h.Client().CoreV1().Applications(testNamespace)
+
+	_, err := appClient.Create(context.Background(), newTestApplication("scaling", 2), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create application, %v", err)
+	}
+	if _, err := h.WaitForApplication(testNamespace, "scaling", testTimeout, idleInstances(2)); err != nil {
+		t.Fatal(err)
+	}
+	pods, err := h.ApplicationPods(testNamespace, "scaling")
+	if err != nil || len(pods) != 2 {
+		t.Fatalf("expect 2 application pods, got %d, %v", len(pods), err)
+	}
+
+	// scale up and down by changing minimum instance
+	for _, minimumInstance := range []uint32{4, 1} {
+		app, err := appClient.Get(context.Background(), "scaling", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("failed to get application, %v", err)
+		}
+		app.Spec.ScalingPolicy.MinimumInstance = minimumInstance
+		if _, err := appClient.Update(context.Background(), app, metav1.UpdateOptions{}); err != nil {
+			t.Fatalf("failed to update application, %v", err)
+		}
+		if _, err := h.WaitForApplication(testNamespace, "scaling", testTimeout, idleInstances(int32(minimumInstance))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// application is removed after its pods are deleted
+	if err := appClient.Delete(context.Background(), "scaling", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete application, %v", err)
+	}
+	err = h.WaitFor(testTimeout, func() (bool, error) {
+		_, err := appClient.Get(context.Background(), "scaling", metav1.GetOptions{})
+		return apierrors.IsNotFound(err), nil
+	})
+	if err != nil {
+		t.Fatalf("application is not deleted, %v", err)
+	}
+	pods, err = h.ApplicationPods(testNamespace, "scaling")
+	if err != nil || len(pods) != 0 {
+		t.Fatalf("expect application pods deleted, got %d, %v", len(pods), err)
+	}
+}
+
+func TestApplicationValidation(t *testing.T) {
+	h := NewHarness(DefaultHarnessConfiguration())
+	defer h.Stop()
+	app := newTestApplication("invalid", 0)
+	app.Spec.Containers = nil
+	_, err := h.Client().CoreV1().Applications(testNamespace).Create(context.Background(), app, metav1.CreateOptions{})
+	if !apierrors.IsInvalid(err) {
+		t.Fatalf("expect application without container is invalid, got %v", err)
+	}
+}
+
+func TestSessionLifecycle(t *testing.T) {
+	h := startHarness(t)
+	appClient := h.Client().CoreV1().Applications(testNamespace)
+	sessionClient := h.Client().CoreV1().ApplicationSessions(testNamespace)
+
+	if _, err := appClient.Create(context.Background(), newTestApplication("echo", 0), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create application, %v", err)
+	}
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "echo-session"},
+		Spec: fornaxv1.ApplicationSessionSpec{
+			ApplicationName:    "echo",
+			SessionData:        "session-data",
+			OpenTimeoutSeconds: 10,
+		},
+	}
+	if _, err := sessionClient.Create(context.Background(), session, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+
+	// a instance is created for pending session and session is opened on it
+	session, err := h.WaitForSession(testNamespace, "echo-session", testTimeout, func(s *fornaxv1.ApplicationSession) bool {
+		return s != nil && s.Status.SessionStatus == fornaxv1.SessionStatusAvailable
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.Status.PodReference == nil {
+		t.Fatalf("expect available session has pod reference")
+	}
+	if _, err := h.WaitForApplication(testNamespace, "echo", testTimeout, func(app *fornaxv1.Application) bool {
+		return app.Status.AllocatedInstances == 1
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// session is closed on instance and removed
+	if err := sessionClient.Delete(context.Background(), "echo-session", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("failed to delete session, %v", err)
+	}
+	if _, err := h.WaitForSession(testNamespace, "echo-session", testTimeout, func(s *fornaxv1.ApplicationSession) bool {
+		return s == nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WaitForApplication(testNamespace, "echo", testTimeout, func(app *fornaxv1.Application) bool {
+		return app.Status.AllocatedInstances == 0
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	config := DefaultHarnessConfiguration()
+	config.NumOfFornaxCore = 2
+	h := NewHarness(config)
+	if err := h.Start(testTimeout); err != nil {
+		h.Stop()
+		t.Fatalf("failed to start harness, %v", err)
+	}
+	t.Cleanup(h.Stop)
+
+	if _, err := h.Client().CoreV1().Applications(testNamespace).Create(context.Background(), newTestApplication("failover", 2), metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create application, %v", err)
+	}
+	session := &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "failover-session"},
+		Spec: fornaxv1.ApplicationSessionSpec{
+			ApplicationName:    "failover",
+			SessionData:        "session-data",
+			OpenTimeoutSeconds: 10,
+		},
+	}
+	if _, err := h.Client().CoreV1().ApplicationSessions(testNamespace).Create(context.Background(), session, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+	session, err := h.WaitForSession(testNamespace, "failover-session", testTimeout, func(s *fornaxv1.ApplicationSession) bool {
+		return s != nil && s.Status.SessionStatus == fornaxv1.SessionStatusAvailable
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WaitForApplication(testNamespace, "failover", testTimeout, func(app *fornaxv1.Application) bool {
+		return app.Status.TotalInstances == 2 && app.Status.AllocatedInstances == 1 && app.Status.PendingInstances == 0
+	}); err != nil {
+		t.Fatal(err)
+	}
+	pods, err := h.ApplicationPods(testNamespace, "failover")
+	if err != nil || len(pods) != 2 {
+		t.Fatalf("expect 2 application pods, got %d, %v", len(pods), err)
+	}
+
+	// standby become primary, application keep its pods and session
+	if err := h.Failover(testTimeout); err != nil {
+		t.Fatalf("failed to fail over, %v", err)
+	}
+	oldPodNames := map[string]bool{}
+	for _, v := range pods {
+		oldPodNames[util.Name(v)] = true
+	}
+	if err := h.WaitFor(testTimeout, func() (bool, error) {
+		newPods, err := h.ApplicationPods(testNamespace, "failover")
+		return len(newPods) >= len(pods), err
+	}); err != nil {
+		t.Fatalf("application pods are not synced from nodes after failover, %v", err)
+	}
+	if _, err := h.WaitForSession(testNamespace, "failover-session", testTimeout, func(s *fornaxv1.ApplicationSession) bool {
+		return s != nil && s.Status.SessionStatus == fornaxv1.SessionStatusAvailable && s.Status.PodReference != nil && s.Status.PodReference.Name == session.Status.PodReference.Name
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.WaitForApplication(testNamespace, "failover", testTimeout, func(app *fornaxv1.Application) bool {
+		return app.Status.TotalInstances == 2 && app.Status.AllocatedInstances == 1 && app.Status.PendingInstances == 0
+	}); err != nil {
+		t.Fatal(err)
+	}
+	newPods, err := h.ApplicationPods(testNamespace, "failover")
+	if err != nil {
+		t.Fatalf("failed to list application pods, %v", err)
+	}
+	for _, v := range newPods {
+		if !oldPodNames[util.Name(v)] || v.DeletionTimestamp != nil {
+			t.Errorf("expect application keep pods %v after failover, got pod %s deleting: %v", oldPodNames, util.Name(v), v.DeletionTimestamp != nil)
+		}
+	}
+	if len(newPods) != len(pods) {
+		t.Errorf("expect %d application pods after failover, got %d", len(pods), len(newPods))
+	}
+
+	// new primary serve sessions
+	session = &fornaxv1.ApplicationSession{
+		ObjectMeta: metav1.ObjectMeta{Namespace: testNamespace, Name: "failover-session-2"},
+		Spec: fornaxv1.ApplicationSessionSpec{
+			ApplicationName:    "failover",
+			SessionData:        "session-data",
+			OpenTimeoutSeconds: 10,
+		},
+	}
+	if _, err := h.Client().CoreV1().ApplicationSessions(testNamespace).Create(context.Background(), session, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create session, %v", err)
+	}
+	if _, err := h.WaitForSession(testNamespace, "failover-session-2", testTimeout, func(s *fornaxv1.ApplicationSession) bool {
+		return s != nil && s.Status.SessionStatus == fornaxv1.SessionStatusAvailable
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
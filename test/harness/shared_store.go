@@ -0,0 +1,67 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package harness
+
+import (
+	"sync"
+
+	"centaurusinfra.io/fornax-serverless/pkg/store/storage"
+)
+
+// sharedStore keep objects in memory for FornaxCore replicas of a harness, it works like etcd shared by FornaxCore processes
+type sharedStore struct {
+	mu   sync.Mutex
+	objs map[string]interface{}
+}
+
+func (s *sharedStore) ListObject() ([]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	objs := []interface{}{}
+	for _, v := range s.objs {
+		objs = append(objs, v)
+	}
+	return objs, nil
+}
+
+func (s *sharedStore) DelObject(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objs, key)
+	return nil
+}
+
+func (s *sharedStore) GetObject(key string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, found := s.objs[key]
+	if !found {
+		return nil, storage.ObjectNotFound
+	}
+	return obj, nil
+}
+
+func (s *sharedStore) PutObject(key string, obj interface{}, revision int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objs[key] = obj
+	return nil
+}
+
+func newSharedStore() *sharedStore {
+	return &sharedStore{objs: map[string]interface{}{}}
+}